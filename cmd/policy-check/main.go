@@ -0,0 +1,75 @@
+// Command policy-check menjalankan suite uji kebijakan otorisasi sebelum
+// kebijakan dipublikasikan ke Consul KV.
+//
+//	go run ./cmd/policy-check [-policies kebijakan.json] suite.json...
+//
+// Jika -policies diberikan, dokumen tersebut (format yang sama dengan nilai di
+// Consul) dipakai untuk semua suite, menggantikan kebijakan di dalam suite.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+)
+
+func main() {
+	policiesPath := flag.String("policies", "", "dokumen kebijakan JSON yang akan diuji (opsional)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "penggunaan: policy-check [-policies kebijakan.json] suite.json...")
+		os.Exit(2)
+	}
+
+	var override []policy.Policy
+	if *policiesPath != "" {
+		data, err := os.ReadFile(*policiesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gagal membaca %s: %v\n", *policiesPath, err)
+			os.Exit(2)
+		}
+		override, err = policy.ParseDocument(data)
+		if err == nil {
+			err = policy.Validate(override)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *policiesPath, err)
+			os.Exit(1)
+		}
+	}
+
+	failed := 0
+	for _, path := range flag.Args() {
+		suite, err := policy.LoadSuite(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if override != nil {
+			suite.Policies = override
+		}
+		results, err := policy.RunSuite(context.Background(), suite)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed++
+			continue
+		}
+		for _, result := range results {
+			if result.Passed {
+				fmt.Printf("PASS  %s: %s (%s)\n", path, result.Case.Name, result.Decision.Policy)
+				continue
+			}
+			failed++
+			fmt.Printf("FAIL  %s: %s: %s\n", path, result.Case.Name, result.Message)
+			for _, evalErr := range result.Decision.Errors {
+				fmt.Printf("      %v\n", evalErr)
+			}
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,27 +1,112 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	commonconfig "github.com/Lumina-Enterprise-Solutions/prism-common-libs/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
 )
 
 type S3Config struct {
-	Region       string
-	Endpoint     string
-	AccessKey    string
-	SecretKey    string
-	Bucket       string
-	UsePathStyle bool
+	Region            string
+	Endpoint          string
+	AccessKey         string
+	SecretKey         string
+	Bucket            string
+	UsePathStyle      bool
+	PartSizeMB        int
+	UploadConcurrency int
+	ChecksumAlgorithm string
+	Encryption        string
+	KMSKeyID          string
+	SSECustomerKey    string
 }
 
+// SFTPConfig berisi kredensial SFTP dari Vault serta pengaturan koneksi dari Consul.
+type SFTPConfig struct {
+	Host           string
+	Port           int
+	User           string
+	Password       string
+	PrivateKey     string
+	HostKey        string
+	BasePath       string
+	MaxConnections int
+	MaxRetries     int
+}
+
+// AzureConfig berisi kredensial Azure Blob dari Vault. Endpoint (mis. Azurite)
+// diambil dari Consul.
+type AzureConfig struct {
+	AccountName string
+	AccountKey  string
+	SASToken    string
+	Container   string
+	Endpoint    string
+}
+
+// GCSConfig berisi kredensial Google Cloud Storage dari Vault. Endpoint (mis.
+// fake-gcs-server) diambil dari Consul.
+type GCSConfig struct {
+	Bucket          string
+	CredentialsJSON string
+	Endpoint        string
+}
+
+// LocalConfig berisi pengaturan storage disk lokal dari Consul.
+type LocalConfig struct {
+	BasePath   string
+	FileMode   os.FileMode
+	DirMode    os.FileMode
+	ShardDepth int
+}
+
+// StorageSecrets berisi kredensial semua backend penyimpanan yang dibaca dari Vault.
+type StorageSecrets struct {
+	S3    S3Config
+	SFTP  SFTPConfig
+	Azure AzureConfig
+	GCS   GCSConfig
+}
+
+// ServiceName adalah nama layanan di Consul dan telemetri.
+const ServiceName = "prism-file-service"
+
+// KeyPrefix adalah prefix key Consul KV milik layanan ini.
+const KeyPrefix = "config/" + ServiceName
+
+const (
+	// defaultSearchMaxTextKB adalah batas bawaan teks isi file yang diindeks.
+	defaultSearchMaxTextKB = 256
+	// maxSearchTextKB menjaga tsvector nama dan teks (dua konfigurasi bahasa)
+	// di bawah batas 1 MB milik Postgres.
+	maxSearchTextKB = 512
+	// DefaultJobConcurrency adalah jumlah job paralel per tipe yang tidak
+	// diatur lewat job_concurrency.
+	DefaultJobConcurrency = 2
+	// defaultRenderMaxDimension adalah batas bawaan lebar/tinggi hasil render.
+	defaultRenderMaxDimension = 2048
+	// maxRenderDimension membatasi render_max_dimension agar satu render tidak
+	// memakan memori berlebihan.
+	maxRenderDimension = 8192
+)
+
+// storageBackends adalah nilai storage_backend yang dikenali.
+var storageBackends = map[string]bool{"local": true, "s3": true, "sftp": true, "azure": true, "gcs": true}
+
 type Config struct {
 	ServiceName         string
 	Port                int
+	GRPCPort            int
+	S3GatewayPort       int
 	MaxFileSizeBytes    int64
 	AllowedMimeTypesMap map[string]bool
 	VaultAddr           string
@@ -29,17 +114,95 @@ type Config struct {
 	JaegerEndpoint      string
 	StorageBackend      string
 	S3Config            S3Config
+	SFTPConfig          SFTPConfig
+	AzureConfig         AzureConfig
+	GCSConfig           GCSConfig
+	LocalConfig         LocalConfig
+	// ExtraChecksums adalah checksum yang dihitung saat unggah selain SHA-256
+	// ("md5", "crc32c").
+	ExtraChecksums []string
+	// VerifyChecksumOnRead memverifikasi SHA-256 konten saat file diunduh.
+	VerifyChecksumOnRead bool
+	// ScrubInterval adalah jeda antar putaran scrubber; 0 menonaktifkannya.
+	ScrubInterval  time.Duration
+	ScrubBatchSize int
+	// ScrubMaxAge adalah selang minimal sebelum file yang sama diperiksa ulang.
+	ScrubMaxAge time.Duration
+	// UploadPolicies dievaluasi berurutan saat unggah; unggahan yang tidak
+	// cocok dengan kebijakan mana pun memakai MaxFileSizeBytes dan
+	// AllowedMimeTypesMap.
+	UploadPolicies []uploadpolicy.Policy
+	// ContentValidation mengatur pemeriksaan isi file (dekode gambar,
+	// struktur PDF, makro Office, kecocokan ekstensi) setelah deteksi MIME.
+	ContentValidation validation.Options
+	// SanitizeUploads membersihkan metadata file (EXIF, properti dokumen) saat
+	// unggah jika kebijakan upload yang cocok tidak menentukan sebaliknya.
+	SanitizeUploads bool
+	// SearchMaxTextBytes membatasi teks isi file yang diekstrak untuk
+	// pencarian full-text; 0 menonaktifkan ekstraksi sehingga hanya nama file
+	// yang dapat dicari.
+	SearchMaxTextBytes int
+	// JobsEnabled menjalankan pekerjaan pasca-unggah (ekstraksi teks) lewat
+	// antrean job di Postgres; jika false pekerjaan tersebut dilakukan
+	// langsung saat unggah. Pengaturan Job* hanya berlaku saat startup.
+	JobsEnabled     bool
+	JobPollInterval time.Duration
+	// JobMaxAttempts adalah jumlah percobaan sebelum job dinyatakan gagal.
+	JobMaxAttempts int
+	// JobRetryDelay adalah jeda sebelum percobaan kedua; berlipat dua setiap
+	// percobaan berikutnya.
+	JobRetryDelay time.Duration
+	JobTimeout    time.Duration
+	// JobShutdownTimeout adalah batas waktu menunggu job yang sedang berjalan
+	// saat shutdown, terpisah dari batas waktu mematikan server; job yang
+	// belum selesai dikembalikan ke antrean.
+	JobShutdownTimeout time.Duration
+	// JobConcurrency adalah jumlah job paralel per tipe job; tipe yang tidak
+	// disebut memakai DefaultJobConcurrency.
+	JobConcurrency map[string]int
+	// RenderMaxDimension membatasi lebar dan tinggi gambar hasil
+	// GET /files/:id/render.
+	RenderMaxDimension int
+	// RenderURLTTL adalah masa berlaku URL render bertanda tangan.
+	RenderURLTTL time.Duration
+	// MetricsSampleInterval adalah jeda pembaruan metrik pemakaian storage
+	// dan kedalaman antrean job dari database; 0 menonaktifkannya.
+	MetricsSampleInterval time.Duration
+	// TrustedProxies adalah IP atau CIDR proxy yang header X-Forwarded-For
+	// dan X-Real-IP-nya dipercaya untuk menentukan IP klien. Kosong berarti
+	// IP klien selalu alamat koneksi. Hanya berlaku saat startup.
+	TrustedProxies []string
 }
 
-// FIX: Load sekarang menerima S3Config sebagai parameter
-func Load(s3Config S3Config) *Config {
+// source adalah asal nilai konfigurasi: commonconfig.Loader saat startup,
+// atau snapshot Consul KV milik Watcher saat konfigurasi dimuat ulang.
+type source interface {
+	Get(key string, defaultValue string) string
+	GetInt(key string, defaultValue int) int
+}
+
+// Load menerima kredensial storage dari Vault dan melengkapinya dengan pengaturan dari Consul.
+func Load(secrets StorageSecrets) *Config {
 	loader, err := commonconfig.NewLoader()
 	if err != nil {
 		log.Fatalf("Gagal membuat config loader untuk file-service: %v", err)
 	}
+	cfg, err := build(loader, secrets)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		log.Fatalf("Konfigurasi file-service tidak valid: %v", err)
+	}
+	return cfg
+}
 
-	serviceName := "prism-file-service"
-	pathPrefix := fmt.Sprintf("config/%s", serviceName)
+// build menyusun Config dari nilai di loader. Nilai yang tidak dapat dibaca
+// diganti default oleh loader; batasan antar nilai diperiksa oleh Validate.
+// Error hanya dikembalikan untuk dokumen kebijakan upload yang tidak valid.
+func build(loader source, secrets StorageSecrets) (*Config, error) {
+	serviceName := ServiceName
+	pathPrefix := KeyPrefix
 
 	maxSizeMB := loader.GetInt(fmt.Sprintf("%s/max_size_mb", pathPrefix), 10)
 	maxSizeBytes := int64(maxSizeMB) * 1024 * 1024
@@ -48,7 +211,9 @@ func Load(s3Config S3Config) *Config {
 	allowedTypesList := strings.Split(allowedTypesStr, ",")
 	allowedTypesMap := make(map[string]bool)
 	for _, t := range allowedTypesList {
-		allowedTypesMap[strings.TrimSpace(t)] = true
+		if t = strings.TrimSpace(t); t != "" {
+			allowedTypesMap[t] = true
+		}
 	}
 
 	storageBackend := loader.Get(fmt.Sprintf("%s/storage_backend", pathPrefix), "local")
@@ -58,14 +223,93 @@ func Load(s3Config S3Config) *Config {
 	s3UsePathStyle, _ := strconv.ParseBool(s3UsePathStyleStr)
 
 	// FIX: Set S3Config dari parameter, jangan dari env var lagi
-	finalS3Config := s3Config
+	finalS3Config := secrets.S3
 	finalS3Config.UsePathStyle = s3UsePathStyle
+	finalS3Config.PartSizeMB = loader.GetInt(fmt.Sprintf("%s/s3_part_size_mb", pathPrefix), 8)
+	finalS3Config.UploadConcurrency = loader.GetInt(fmt.Sprintf("%s/s3_upload_concurrency", pathPrefix), 4)
+	finalS3Config.ChecksumAlgorithm = loader.Get(fmt.Sprintf("%s/s3_checksum_algorithm", pathPrefix), "SHA256")
+	finalS3Config.Encryption = loader.Get(fmt.Sprintf("%s/s3_encryption", pathPrefix), "")
+	finalS3Config.KMSKeyID = loader.Get(fmt.Sprintf("%s/s3_kms_key_id", pathPrefix), "")
+
+	finalSFTPConfig := secrets.SFTP
+	finalSFTPConfig.BasePath = loader.Get(fmt.Sprintf("%s/sftp_base_path", pathPrefix), "/prism")
+	finalSFTPConfig.MaxConnections = loader.GetInt(fmt.Sprintf("%s/sftp_max_connections", pathPrefix), 4)
+	finalSFTPConfig.MaxRetries = loader.GetInt(fmt.Sprintf("%s/sftp_max_retries", pathPrefix), 3)
+
+	finalAzureConfig := secrets.Azure
+	finalAzureConfig.Endpoint = loader.Get(fmt.Sprintf("%s/azure_endpoint", pathPrefix), "")
+	finalGCSConfig := secrets.GCS
+	finalGCSConfig.Endpoint = loader.Get(fmt.Sprintf("%s/gcs_endpoint", pathPrefix), "")
+
+	localConfig := LocalConfig{
+		BasePath:   loader.Get(fmt.Sprintf("%s/local_base_path", pathPrefix), "/storage"),
+		FileMode:   parseFileMode(loader.Get(fmt.Sprintf("%s/local_file_mode", pathPrefix), "0640"), 0o640),
+		DirMode:    parseFileMode(loader.Get(fmt.Sprintf("%s/local_dir_mode", pathPrefix), "0750"), 0o750),
+		ShardDepth: loader.GetInt(fmt.Sprintf("%s/local_shard_depth", pathPrefix), 2),
+	}
+
+	var extraChecksums []string
+	for _, algorithm := range strings.Split(loader.Get(fmt.Sprintf("%s/extra_checksums", pathPrefix), ""), ",") {
+		switch algorithm = strings.ToLower(strings.TrimSpace(algorithm)); algorithm {
+		case "":
+		case "md5", "crc32c":
+			extraChecksums = append(extraChecksums, algorithm)
+		default:
+			log.Printf("Algoritma checksum '%s' tidak didukung dan diabaikan", algorithm)
+		}
+	}
+	verifyOnRead, _ := strconv.ParseBool(loader.Get(fmt.Sprintf("%s/verify_checksum_on_read", pathPrefix), "false"))
+
+	var uploadPolicies []uploadpolicy.Policy
+	if document := strings.TrimSpace(loader.Get(fmt.Sprintf("%s/upload_policies", pathPrefix), "")); document != "" {
+		policies, err := uploadpolicy.ParseDocument([]byte(document))
+		if err != nil {
+			return nil, fmt.Errorf("upload_policies: %w", err)
+		}
+		uploadPolicies = policies
+	}
+
+	defaults := validation.DefaultOptions()
+	contentValidation := validation.Options{
+		MaxImagePixels:          int64(loader.GetInt(fmt.Sprintf("%s/image_max_pixels", pathPrefix), int(defaults.MaxImagePixels))),
+		MaxImageDimension:       loader.GetInt(fmt.Sprintf("%s/image_max_dimension", pathPrefix), defaults.MaxImageDimension),
+		RejectImageTrailingData: getBool(loader, fmt.Sprintf("%s/image_reject_trailing_data", pathPrefix), defaults.RejectImageTrailingData),
+		RejectJPEGTrailingData:  getBool(loader, fmt.Sprintf("%s/image_reject_jpeg_trailing_data", pathPrefix), defaults.RejectJPEGTrailingData),
+		RejectPDFJavaScript:     getBool(loader, fmt.Sprintf("%s/pdf_reject_javascript", pathPrefix), defaults.RejectPDFJavaScript),
+		RejectPDFLaunchActions:  getBool(loader, fmt.Sprintf("%s/pdf_reject_launch_actions", pathPrefix), defaults.RejectPDFLaunchActions),
+		RejectPDFEncryption:     getBool(loader, fmt.Sprintf("%s/pdf_reject_encrypted", pathPrefix), defaults.RejectPDFEncryption),
+		RejectOfficeMacros:      getBool(loader, fmt.Sprintf("%s/office_reject_macros", pathPrefix), defaults.RejectOfficeMacros),
+		RejectExtensionMismatch: getBool(loader, fmt.Sprintf("%s/reject_extension_mismatch", pathPrefix), defaults.RejectExtensionMismatch),
+	}
+
+	jobConcurrency := map[string]int{}
+	for _, entry := range strings.Split(loader.Get(fmt.Sprintf("%s/job_concurrency", pathPrefix), ""), ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		jobType, value, found := strings.Cut(entry, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || err != nil {
+			log.Printf("Entri job_concurrency '%s' tidak valid dan diabaikan", entry)
+			continue
+		}
+		jobConcurrency[strings.TrimSpace(jobType)] = n
+	}
+
+	var trustedProxies []string
+	for _, proxy := range strings.Split(loader.Get(fmt.Sprintf("%s/trusted_proxies", pathPrefix), ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
 
 	log.Printf("Konfigurasi File-Service dimuat: MaxSize=%dMB, StorageBackend=%s", maxSizeMB, storageBackend)
 
 	return &Config{
 		ServiceName:         serviceName,
 		Port:                loader.GetInt(fmt.Sprintf("%s/port", serviceName), 8080),
+		GRPCPort:            loader.GetInt(fmt.Sprintf("%s/grpc_port", pathPrefix), 9090),
+		S3GatewayPort:       loader.GetInt(fmt.Sprintf("%s/s3_gateway_port", pathPrefix), 9000),
 		MaxFileSizeBytes:    maxSizeBytes,
 		AllowedMimeTypesMap: allowedTypesMap,
 		VaultAddr:           os.Getenv("VAULT_ADDR"),
@@ -73,5 +317,173 @@ func Load(s3Config S3Config) *Config {
 		JaegerEndpoint:      loader.Get("config/global/jaeger_endpoint", "jaeger:4317"),
 		StorageBackend:      storageBackend,
 		S3Config:            finalS3Config, // Gunakan struct yang sudah diisi
+		SFTPConfig:          finalSFTPConfig,
+		AzureConfig:         finalAzureConfig,
+		GCSConfig:           finalGCSConfig,
+		LocalConfig:         localConfig,
+
+		ExtraChecksums:       extraChecksums,
+		VerifyChecksumOnRead: verifyOnRead,
+		ScrubInterval:        time.Duration(loader.GetInt(fmt.Sprintf("%s/scrub_interval_minutes", pathPrefix), 60)) * time.Minute,
+		ScrubBatchSize:       loader.GetInt(fmt.Sprintf("%s/scrub_batch_size", pathPrefix), 100),
+		ScrubMaxAge:          time.Duration(loader.GetInt(fmt.Sprintf("%s/scrub_max_age_hours", pathPrefix), 168)) * time.Hour,
+		UploadPolicies:       uploadPolicies,
+		ContentValidation:    contentValidation,
+		SanitizeUploads:      getBool(loader, fmt.Sprintf("%s/sanitize_uploads", pathPrefix), false),
+		SearchMaxTextBytes:   loader.GetInt(fmt.Sprintf("%s/search_max_text_kb", pathPrefix), defaultSearchMaxTextKB) * 1024,
+		JobsEnabled:          getBool(loader, fmt.Sprintf("%s/jobs_enabled", pathPrefix), true),
+		JobPollInterval:      time.Duration(loader.GetInt(fmt.Sprintf("%s/job_poll_interval_seconds", pathPrefix), 2)) * time.Second,
+		JobMaxAttempts:       loader.GetInt(fmt.Sprintf("%s/job_max_attempts", pathPrefix), 5),
+		JobRetryDelay:        time.Duration(loader.GetInt(fmt.Sprintf("%s/job_retry_delay_seconds", pathPrefix), 30)) * time.Second,
+		JobTimeout:           time.Duration(loader.GetInt(fmt.Sprintf("%s/job_timeout_seconds", pathPrefix), 300)) * time.Second,
+		JobShutdownTimeout:   time.Duration(loader.GetInt(fmt.Sprintf("%s/job_shutdown_timeout_seconds", pathPrefix), 20)) * time.Second,
+		JobConcurrency:       jobConcurrency,
+		RenderMaxDimension:   loader.GetInt(fmt.Sprintf("%s/render_max_dimension", pathPrefix), defaultRenderMaxDimension),
+		RenderURLTTL:         time.Duration(loader.GetInt(fmt.Sprintf("%s/render_url_ttl_minutes", pathPrefix), 60)) * time.Minute,
+
+		MetricsSampleInterval: time.Duration(loader.GetInt(fmt.Sprintf("%s/metrics_sample_interval_seconds", pathPrefix), 30)) * time.Second,
+		TrustedProxies:        trustedProxies,
+	}, nil
+}
+
+// Validate memeriksa batasan konfigurasi yang tidak dapat ditangani dengan
+// nilai default, mis. batas ukuran nol atau backend yang tidak dikenal.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.MaxFileSizeBytes <= 0 {
+		errs = append(errs, errors.New("max_size_mb harus lebih dari 0"))
+	}
+	if len(c.AllowedMimeTypesMap) == 0 {
+		errs = append(errs, errors.New("allowed_mime_types tidak boleh kosong"))
+	}
+	if !storageBackends[c.StorageBackend] {
+		errs = append(errs, fmt.Errorf("storage_backend tidak dikenal: %q", c.StorageBackend))
+	}
+	if c.GRPCPort <= 0 || c.GRPCPort > 65535 {
+		errs = append(errs, fmt.Errorf("grpc_port tidak valid: %d", c.GRPCPort))
+	}
+	if c.S3GatewayPort <= 0 || c.S3GatewayPort > 65535 {
+		errs = append(errs, fmt.Errorf("s3_gateway_port tidak valid: %d", c.S3GatewayPort))
+	}
+	if c.LocalConfig.ShardDepth < 0 || c.LocalConfig.ShardDepth > 4 {
+		errs = append(errs, fmt.Errorf("local_shard_depth harus 0-4, bukan %d", c.LocalConfig.ShardDepth))
+	}
+	if c.StorageBackend == "s3" && c.S3Config.PartSizeMB < 5 {
+		errs = append(errs, fmt.Errorf("s3_part_size_mb minimal 5, bukan %d", c.S3Config.PartSizeMB))
+	}
+	if c.ScrubInterval < 0 || c.ScrubMaxAge < 0 {
+		errs = append(errs, errors.New("scrub_interval_minutes dan scrub_max_age_hours tidak boleh negatif"))
+	}
+	if c.ScrubInterval > 0 && c.ScrubBatchSize <= 0 {
+		errs = append(errs, errors.New("scrub_batch_size harus lebih dari 0 jika scrubber aktif"))
+	}
+	if c.ContentValidation.MaxImagePixels < 0 || c.ContentValidation.MaxImageDimension < 0 {
+		errs = append(errs, errors.New("image_max_pixels dan image_max_dimension tidak boleh negatif"))
+	}
+	if c.SearchMaxTextBytes < 0 || c.SearchMaxTextBytes > maxSearchTextKB*1024 {
+		errs = append(errs, fmt.Errorf("search_max_text_kb harus 0-%d", maxSearchTextKB))
+	}
+	if c.JobsEnabled {
+		if c.JobPollInterval <= 0 || c.JobRetryDelay <= 0 || c.JobTimeout <= 0 || c.JobShutdownTimeout <= 0 {
+			errs = append(errs, errors.New("job_poll_interval_seconds, job_retry_delay_seconds, job_timeout_seconds dan job_shutdown_timeout_seconds harus lebih dari 0"))
+		}
+		if c.JobMaxAttempts <= 0 {
+			errs = append(errs, errors.New("job_max_attempts harus lebih dari 0"))
+		}
+		for jobType, n := range c.JobConcurrency {
+			if n <= 0 {
+				errs = append(errs, fmt.Errorf("job_concurrency untuk %s harus lebih dari 0", jobType))
+			}
+		}
+	}
+	if c.RenderMaxDimension <= 0 || c.RenderMaxDimension > maxRenderDimension {
+		errs = append(errs, fmt.Errorf("render_max_dimension harus 1-%d", maxRenderDimension))
+	}
+	if c.RenderURLTTL <= 0 {
+		errs = append(errs, errors.New("render_url_ttl_minutes harus lebih dari 0"))
+	}
+	if c.MetricsSampleInterval < 0 {
+		errs = append(errs, errors.New("metrics_sample_interval_seconds tidak boleh negatif"))
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("trusted_proxies: %q bukan IP atau CIDR", proxy))
+		}
+	}
+	if err := uploadpolicy.Validate(c.UploadPolicies); err != nil {
+		errs = append(errs, fmt.Errorf("upload_policies: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// UploadSizeLimit mengembalikan ukuran unggahan terbesar yang mungkin
+// diizinkan, yaitu MaxFileSizeBytes atau batas kebijakan upload yang lebih
+// besar. Dipakai untuk membatasi penerimaan stream sebelum kebijakan dipilih.
+func (c *Config) UploadSizeLimit() int64 {
+	limit := c.MaxFileSizeBytes
+	for i := range c.UploadPolicies {
+		limit = max(limit, c.UploadPolicies[i].MaxSizeBytes(c.MaxFileSizeBytes))
+	}
+	return limit
+}
+
+// JobConcurrencyFor mengembalikan jumlah job paralel untuk jobType.
+func (c *Config) JobConcurrencyFor(jobType string) int {
+	if n, ok := c.JobConcurrency[jobType]; ok {
+		return n
+	}
+	return DefaultJobConcurrency
+}
+
+// parseFileMode membaca izin file dalam notasi oktal (mis. "0640"). Nilai yang
+// tidak valid dicatat dan diganti fallback.
+func parseFileMode(value string, fallback os.FileMode) os.FileMode {
+	mode, err := strconv.ParseUint(strings.TrimSpace(value), 8, 32)
+	if err != nil || mode > 0o777 {
+		log.Printf("Izin file '%s' tidak valid, memakai %#o", value, fallback)
+		return fallback
+	}
+	return os.FileMode(mode)
+}
+
+// getBool membaca nilai boolean dari loader. Nilai yang tidak valid dicatat
+// dan diganti fallback.
+func getBool(loader source, key string, fallback bool) bool {
+	value := loader.Get(key, strconv.FormatBool(fallback))
+	parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		log.Printf("Nilai boolean '%s' untuk %s tidak valid, memakai %t", value, key, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// Dev mengembalikan konfigurasi mode dev yang berdiri sendiri: tanpa Vault
+// maupun Consul, storage di memori, dan batas yang sama dengan nilai bawaan Load.
+func Dev() *Config {
+	allowedTypesMap := map[string]bool{}
+	for _, t := range []string{"image/jpeg", "image/png", "application/pdf", "text/plain"} {
+		allowedTypesMap[t] = true
+	}
+	return &Config{
+		ServiceName:         ServiceName,
+		Port:                8080,
+		GRPCPort:            9090,
+		S3GatewayPort:       9000,
+		MaxFileSizeBytes:    10 * 1024 * 1024,
+		AllowedMimeTypesMap: allowedTypesMap,
+		StorageBackend:      "memory",
+		ContentValidation:   validation.DefaultOptions(),
+		SearchMaxTextBytes:  defaultSearchMaxTextKB * 1024,
+		JobsEnabled:         true,
+		JobPollInterval:     time.Second,
+		JobMaxAttempts:      5,
+		JobRetryDelay:       30 * time.Second,
+		JobTimeout:          5 * time.Minute,
+		JobShutdownTimeout:  20 * time.Second,
+		RenderMaxDimension:  defaultRenderMaxDimension,
+		RenderURLTTL:        time.Hour,
+
+		MetricsSampleInterval: 30 * time.Second,
 	}
 }
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_Defaults(t *testing.T) {
+	cfg, err := build(&kvSource{}, StorageSecrets{S3: S3Config{Bucket: "prism"}})
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, int64(10*1024*1024), cfg.MaxFileSizeBytes)
+	assert.Equal(t, map[string]bool{"image/jpeg": true, "image/png": true, "application/pdf": true}, cfg.AllowedMimeTypesMap)
+	assert.Equal(t, "local", cfg.StorageBackend)
+	assert.Equal(t, "prism", cfg.S3Config.Bucket, "Kredensial dari Vault harus dipertahankan")
+	assert.Equal(t, 2, cfg.LocalConfig.ShardDepth)
+	assert.Equal(t, time.Hour, cfg.ScrubInterval)
+	assert.Empty(t, cfg.UploadPolicies)
+	assert.Equal(t, validation.DefaultOptions(), cfg.ContentValidation)
+	assert.False(t, cfg.SanitizeUploads)
+	assert.Equal(t, 256*1024, cfg.SearchMaxTextBytes)
+	assert.True(t, cfg.JobsEnabled)
+	assert.Equal(t, 2*time.Second, cfg.JobPollInterval)
+	assert.Equal(t, 5, cfg.JobMaxAttempts)
+	assert.Equal(t, 20*time.Second, cfg.JobShutdownTimeout)
+	assert.Equal(t, DefaultJobConcurrency, cfg.JobConcurrencyFor("extract_text"))
+	assert.Equal(t, 2048, cfg.RenderMaxDimension)
+	assert.Equal(t, time.Hour, cfg.RenderURLTTL)
+	assert.Equal(t, 30*time.Second, cfg.MetricsSampleInterval)
+	assert.Empty(t, cfg.TrustedProxies, "Tanpa konfigurasi tidak ada proxy yang dipercaya")
+}
+
+func TestBuild_JobConcurrency(t *testing.T) {
+	cfg, err := build(&kvSource{values: map[string]string{
+		KeyPrefix + "/job_concurrency": "extract_text=4, thumbnail = 1, rusak, x=abc",
+	}}, StorageSecrets{})
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, map[string]int{"extract_text": 4, "thumbnail": 1}, cfg.JobConcurrency)
+	assert.Equal(t, 4, cfg.JobConcurrencyFor("extract_text"))
+	assert.Equal(t, DefaultJobConcurrency, cfg.JobConcurrencyFor("lainnya"))
+}
+
+func TestBuild_ContentValidation(t *testing.T) {
+	cfg, err := build(&kvSource{values: map[string]string{
+		KeyPrefix + "/image_max_pixels":          "1000000",
+		KeyPrefix + "/pdf_reject_encrypted":      "true",
+		KeyPrefix + "/office_reject_macros":      "false",
+		KeyPrefix + "/reject_extension_mismatch": "bukan-boolean",
+		KeyPrefix + "/sanitize_uploads":          "true",
+	}}, StorageSecrets{})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1000000), cfg.ContentValidation.MaxImagePixels)
+	assert.True(t, cfg.ContentValidation.RejectPDFEncryption)
+	assert.False(t, cfg.ContentValidation.RejectOfficeMacros)
+	assert.True(t, cfg.ContentValidation.RejectExtensionMismatch, "Nilai tidak valid diganti default")
+	assert.True(t, cfg.SanitizeUploads)
+}
+
+// defaultConfig menyusun konfigurasi dari nilai bawaan.
+func defaultConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := build(&kvSource{}, StorageSecrets{})
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		values        map[string]string
+		expectedError string
+	}{
+		{name: "Valid overrides", values: map[string]string{KeyPrefix + "/max_size_mb": "25", KeyPrefix + "/allowed_mime_types": "text/plain, image/png"}},
+		{name: "Zero size limit", values: map[string]string{KeyPrefix + "/max_size_mb": "0"}, expectedError: "max_size_mb"},
+		{name: "Empty MIME list", values: map[string]string{KeyPrefix + "/allowed_mime_types": " , "}, expectedError: "allowed_mime_types"},
+		{name: "Unknown backend", values: map[string]string{KeyPrefix + "/storage_backend": "ftp"}, expectedError: "storage_backend"},
+		{name: "Shard depth out of range", values: map[string]string{KeyPrefix + "/local_shard_depth": "9"}, expectedError: "local_shard_depth"},
+		{name: "S3 part too small", values: map[string]string{KeyPrefix + "/storage_backend": "s3", KeyPrefix + "/s3_part_size_mb": "1"}, expectedError: "s3_part_size_mb"},
+		{name: "Invalid port", values: map[string]string{KeyPrefix + "/grpc_port": "70000"}, expectedError: "grpc_port"},
+		{name: "Scrubber without batch", values: map[string]string{KeyPrefix + "/scrub_batch_size": "0"}, expectedError: "scrub_batch_size"},
+		{name: "Negative image limit", values: map[string]string{KeyPrefix + "/image_max_dimension": "-1"}, expectedError: "image_max_dimension"},
+		{name: "Search text limit disabled", values: map[string]string{KeyPrefix + "/search_max_text_kb": "0"}},
+		{name: "Search text limit too large", values: map[string]string{KeyPrefix + "/search_max_text_kb": "2048"}, expectedError: "search_max_text_kb"},
+		{name: "Zero job concurrency", values: map[string]string{KeyPrefix + "/job_concurrency": "extract_text=0"}, expectedError: "job_concurrency"},
+		{name: "Zero job shutdown timeout", values: map[string]string{KeyPrefix + "/job_shutdown_timeout_seconds": "0"}, expectedError: "job_shutdown_timeout_seconds"},
+		{name: "Zero job attempts", values: map[string]string{KeyPrefix + "/job_max_attempts": "0"}, expectedError: "job_max_attempts"},
+		{name: "Job settings ignored when disabled", values: map[string]string{KeyPrefix + "/jobs_enabled": "false", KeyPrefix + "/job_max_attempts": "0"}},
+		{name: "Render dimension too large", values: map[string]string{KeyPrefix + "/render_max_dimension": "10000"}, expectedError: "render_max_dimension"},
+		{name: "Zero render URL TTL", values: map[string]string{KeyPrefix + "/render_url_ttl_minutes": "0"}, expectedError: "render_url_ttl_minutes"},
+		{name: "Metrics sampler disabled", values: map[string]string{KeyPrefix + "/metrics_sample_interval_seconds": "0"}},
+		{name: "Negative metrics sample interval", values: map[string]string{KeyPrefix + "/metrics_sample_interval_seconds": "-5"}, expectedError: "metrics_sample_interval_seconds"},
+		{name: "Valid trusted proxies", values: map[string]string{KeyPrefix + "/trusted_proxies": "10.0.0.0/8, 192.168.1.10"}},
+		{name: "Invalid trusted proxy", values: map[string]string{KeyPrefix + "/trusted_proxies": "10.0.0.0/8,proxy.internal"}, expectedError: "trusted_proxies"},
+		{name: "Valid upload policies", values: map[string]string{KeyPrefix + "/upload_policies": `{"policies":[{"name":"hr-scan","match":{"purposes":["hr-scan"]},"max_size_mb":50,"allowed_extensions":["PDF"]}]}`}},
+		{name: "Malformed upload policies", values: map[string]string{KeyPrefix + "/upload_policies": `{"policies":`}, expectedError: "upload_policies"},
+		{name: "Duplicate upload policy names", values: map[string]string{KeyPrefix + "/upload_policies": `{"policies":[{"name":"a"},{"name":"a"}]}`}, expectedError: "lebih dari sekali"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := build(&kvSource{values: tc.values}, StorageSecrets{})
+			if err == nil {
+				err = cfg.Validate()
+			}
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestConfig_UploadSizeLimit(t *testing.T) {
+	cfg, err := build(&kvSource{values: map[string]string{
+		KeyPrefix + "/upload_policies": `{"policies":[{"name":"avatar","max_size_mb":2},{"name":"hr-scan","max_size_mb":50},{"name":"docs"}]}`,
+	}}, StorageSecrets{})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(50*1024*1024), cfg.UploadSizeLimit())
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// ErrInvalidConfig dikembalikan saat pembaruan konfigurasi dari Consul ditolak.
+var ErrInvalidConfig = errors.New("konfigurasi tidak valid")
+
+// KV adalah bagian dari *consulapi.KV yang dipakai Watcher.
+type KV interface {
+	List(prefix string, q *consulapi.QueryOptions) (consulapi.KVPairs, *consulapi.QueryMeta, error)
+}
+
+// Subscriber dipanggil setiap kali konfigurasi baru diterapkan, berurutan
+// dan tidak pernah bersamaan. previous dan next tidak boleh diubah.
+type Subscriber func(previous, next *Config)
+
+// Snapshot adalah konfigurasi yang sedang berlaku beserta versinya.
+type Snapshot struct {
+	Config *Config
+	// Version naik satu setiap konfigurasi baru diterapkan; konfigurasi awal
+	// dari Load bernilai 1.
+	Version uint64
+	// ConsulIndex adalah index Consul tempat konfigurasi dibaca, atau 0 untuk
+	// konfigurasi awal.
+	ConsulIndex uint64
+	AppliedAt   time.Time
+}
+
+// Rejection mencatat pembaruan konfigurasi terakhir yang ditolak.
+type Rejection struct {
+	ConsulIndex uint64
+	At          time.Time
+	Err         error
+}
+
+// Watcher memantau key Consul KV di bawah KeyPrefix dengan blocking query dan
+// mengganti snapshot konfigurasi secara atomik. Pembaruan yang tidak valid
+// ditolak sehingga konfigurasi terakhir yang valid tetap berlaku.
+type Watcher struct {
+	kv      KV
+	secrets StorageSecrets
+
+	current  atomic.Pointer[Snapshot]
+	rejected atomic.Pointer[Rejection]
+
+	// mu menjaga urutan penerapan snapshot dan pemanggilan subscriber.
+	mu          sync.Mutex
+	subscribers []Subscriber
+
+	waitTime time.Duration
+	retry    time.Duration
+	now      func() time.Time
+}
+
+// NewWatcher membuat Watcher dengan initial sebagai snapshot versi 1. kv boleh
+// nil jika konfigurasi tidak pernah dimuat ulang, mis. pada mode dev.
+func NewWatcher(kv KV, secrets StorageSecrets, initial *Config) *Watcher {
+	w := &Watcher{
+		kv:       kv,
+		secrets:  secrets,
+		waitTime: 5 * time.Minute,
+		retry:    5 * time.Second,
+		now:      time.Now,
+	}
+	w.current.Store(&Snapshot{Config: initial, Version: 1, AppliedAt: w.now()})
+	return w
+}
+
+// Current mengembalikan konfigurasi yang sedang berlaku.
+func (w *Watcher) Current() *Config {
+	return w.current.Load().Config
+}
+
+// Snapshot mengembalikan konfigurasi yang sedang berlaku beserta versinya.
+func (w *Watcher) Snapshot() Snapshot {
+	return *w.current.Load()
+}
+
+// LastRejection mengembalikan pembaruan terakhir yang ditolak, atau nil.
+func (w *Watcher) LastRejection() *Rejection {
+	return w.rejected.Load()
+}
+
+// Subscribe mendaftarkan fn untuk dipanggil pada setiap pembaruan berikutnya.
+func (w *Watcher) Subscribe(fn Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Watch memuat ulang konfigurasi sampai ctx dibatalkan. lastIndex 0 membuat
+// pembacaan pertama langsung dijalankan tanpa menunggu perubahan. Watch
+// langsung kembali jika Watcher dibuat tanpa KV.
+func (w *Watcher) Watch(ctx context.Context, lastIndex uint64) {
+	if w.kv == nil {
+		return
+	}
+	for ctx.Err() == nil {
+		index, err := w.fetch(ctx, lastIndex)
+		if err != nil && !errors.Is(err, ErrInvalidConfig) {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Gagal memuat ulang konfigurasi dari Consul (%s): %v", KeyPrefix, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.retry):
+			}
+		}
+		// Index yang mundur berarti Consul di-reset; mulai ulang dari awal.
+		if index < lastIndex {
+			index = 0
+		}
+		lastIndex = index
+	}
+}
+
+func (w *Watcher) fetch(ctx context.Context, waitIndex uint64) (uint64, error) {
+	opts := (&consulapi.QueryOptions{WaitIndex: waitIndex, WaitTime: w.waitTime}).WithContext(ctx)
+	pairs, meta, err := w.kv.List(KeyPrefix+"/", opts)
+	if err != nil {
+		return waitIndex, err
+	}
+	index := meta.LastIndex
+	if waitIndex != 0 && index == waitIndex {
+		// Blocking query habis waktu tanpa perubahan.
+		return index, nil
+	}
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		values[pair.Key] = string(pair.Value)
+	}
+	return index, w.apply(values, index)
+}
+
+// apply menyusun dan memvalidasi konfigurasi dari values, lalu menerapkannya
+// jika berbeda dari konfigurasi yang sedang berlaku.
+func (w *Watcher) apply(values map[string]string, index uint64) error {
+	src := &kvSource{values: values}
+	next, err := build(src, w.secrets)
+	if err == nil {
+		err = next.Validate()
+	}
+	err = errors.Join(append(src.errs, err)...)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		w.rejected.Store(&Rejection{ConsulIndex: index, At: w.now(), Err: err})
+		log.Printf("Pembaruan konfigurasi di index %d ditolak, konfigurasi sebelumnya tetap berlaku: %v", index, err)
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	previous := w.current.Load()
+	if changed := keepRestartOnly(previous.Config, next); len(changed) > 0 {
+		log.Printf("Perubahan konfigurasi %s baru berlaku setelah restart", strings.Join(changed, ", "))
+	}
+	if reflect.DeepEqual(previous.Config, next) {
+		return nil
+	}
+
+	w.current.Store(&Snapshot{Config: next, Version: previous.Version + 1, ConsulIndex: index, AppliedAt: w.now()})
+	log.Printf("Konfigurasi versi %d dari index %d diterapkan", previous.Version+1, index)
+	for _, fn := range w.subscribers {
+		fn(previous.Config, next)
+	}
+	return nil
+}
+
+// keepRestartOnly menyalin nilai yang hanya dibaca saat startup dari previous
+// ke next, dan mengembalikan nama key Consul yang perubahannya tertunda.
+func keepRestartOnly(previous, next *Config) []string {
+	// Nilai dari env var atau di luar KeyPrefix tidak ikut dipantau.
+	next.ServiceName = previous.ServiceName
+	next.Port = previous.Port
+	next.VaultAddr = previous.VaultAddr
+	next.VaultToken = previous.VaultToken
+	next.JaegerEndpoint = previous.JaegerEndpoint
+
+	var changed []string
+	if next.GRPCPort != previous.GRPCPort {
+		changed = append(changed, "grpc_port")
+		next.GRPCPort = previous.GRPCPort
+	}
+	if next.S3GatewayPort != previous.S3GatewayPort {
+		changed = append(changed, "s3_gateway_port")
+		next.S3GatewayPort = previous.S3GatewayPort
+	}
+	if next.ScrubInterval != previous.ScrubInterval || next.ScrubBatchSize != previous.ScrubBatchSize || next.ScrubMaxAge != previous.ScrubMaxAge {
+		changed = append(changed, "scrub_*")
+		next.ScrubInterval, next.ScrubBatchSize, next.ScrubMaxAge = previous.ScrubInterval, previous.ScrubBatchSize, previous.ScrubMaxAge
+	}
+	if next.JobsEnabled != previous.JobsEnabled || next.JobPollInterval != previous.JobPollInterval ||
+		next.JobMaxAttempts != previous.JobMaxAttempts || next.JobRetryDelay != previous.JobRetryDelay ||
+		next.JobTimeout != previous.JobTimeout || next.JobShutdownTimeout != previous.JobShutdownTimeout ||
+		!maps.Equal(next.JobConcurrency, previous.JobConcurrency) {
+		changed = append(changed, "job_*")
+		next.JobsEnabled, next.JobPollInterval, next.JobMaxAttempts = previous.JobsEnabled, previous.JobPollInterval, previous.JobMaxAttempts
+		next.JobRetryDelay, next.JobTimeout, next.JobConcurrency = previous.JobRetryDelay, previous.JobTimeout, previous.JobConcurrency
+		next.JobShutdownTimeout = previous.JobShutdownTimeout
+	}
+	// StoragePath setiap file menunjuk ke backend dan tata letak tempat file itu
+	// ditulis. Mengganti jenis backend, direktori dasar, atau shard depth saat
+	// berjalan membuat file lama tidak dapat dibaca, sehingga hanya kredensial
+	// dan endpoint yang diganti tanpa restart.
+	if next.StorageBackend != previous.StorageBackend || next.LocalConfig.BasePath != previous.LocalConfig.BasePath ||
+		next.LocalConfig.ShardDepth != previous.LocalConfig.ShardDepth || next.SFTPConfig.BasePath != previous.SFTPConfig.BasePath {
+		changed = append(changed, "storage_backend, local_base_path, local_shard_depth, sftp_base_path")
+		next.StorageBackend = previous.StorageBackend
+		next.LocalConfig.BasePath, next.LocalConfig.ShardDepth = previous.LocalConfig.BasePath, previous.LocalConfig.ShardDepth
+		next.SFTPConfig.BasePath = previous.SFTPConfig.BasePath
+	}
+	// Sampler metrik pemakaian memakai ticker yang dibuat saat startup.
+	if next.MetricsSampleInterval != previous.MetricsSampleInterval {
+		changed = append(changed, "metrics_sample_interval_seconds")
+		next.MetricsSampleInterval = previous.MetricsSampleInterval
+	}
+	// Daftar proxy tepercaya hanya dipasang ke router Gin saat startup.
+	if !slices.Equal(next.TrustedProxies, previous.TrustedProxies) {
+		changed = append(changed, "trusted_proxies")
+		next.TrustedProxies = previous.TrustedProxies
+	}
+	return changed
+}
+
+// kvSource membaca nilai dari snapshot Consul KV dengan prioritas env var
+// yang sama seperti commonconfig.Loader. Berbeda dengan Loader, angka yang
+// tidak valid dicatat sebagai error agar pembaruan ditolak.
+type kvSource struct {
+	values map[string]string
+	errs   []error
+}
+
+func (s *kvSource) Get(key string, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	if value, ok := s.values[key]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (s *kvSource) GetInt(key string, defaultValue int) int {
+	value := s.Get(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s bukan bilangan bulat: %q", key, value))
+		return defaultValue
+	}
+	return n
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKV struct {
+	mu     sync.Mutex
+	values map[string]string
+	index  uint64
+}
+
+func (f *fakeKV) List(prefix string, q *consulapi.QueryOptions) (consulapi.KVPairs, *consulapi.QueryMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var pairs consulapi.KVPairs
+	for key, value := range f.values {
+		pairs = append(pairs, &consulapi.KVPair{Key: key, Value: []byte(value)})
+	}
+	return pairs, &consulapi.QueryMeta{LastIndex: f.index}, nil
+}
+
+func (f *fakeKV) set(index uint64, values map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values = values
+	f.index = index
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	kv := &fakeKV{}
+	initial := defaultConfig(t)
+	initial.Port = 8080
+	watcher := NewWatcher(kv, StorageSecrets{}, initial)
+
+	var notified []*Config
+	watcher.Subscribe(func(previous, next *Config) {
+		assert.NotSame(t, previous, next)
+		notified = append(notified, next)
+	})
+
+	t.Run("Initial snapshot", func(t *testing.T) {
+		snapshot := watcher.Snapshot()
+		assert.Equal(t, uint64(1), snapshot.Version)
+		assert.Same(t, initial, snapshot.Config)
+		assert.Nil(t, watcher.LastRejection())
+	})
+
+	t.Run("Unchanged values do not create a new version", func(t *testing.T) {
+		kv.set(3, map[string]string{KeyPrefix + "/authorization_policies": `{"policies":[]}`})
+		index, err := watcher.fetch(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), index)
+		assert.Equal(t, uint64(1), watcher.Snapshot().Version)
+		assert.Empty(t, notified)
+	})
+
+	t.Run("Changed values are applied and subscribers notified", func(t *testing.T) {
+		kv.set(5, map[string]string{KeyPrefix + "/max_size_mb": "20", KeyPrefix + "/grpc_port": "9191", KeyPrefix + "/job_concurrency": "extract_text=8"})
+		_, err := watcher.fetch(ctx, 3)
+		require.NoError(t, err)
+
+		snapshot := watcher.Snapshot()
+		assert.Equal(t, uint64(2), snapshot.Version)
+		assert.Equal(t, uint64(5), snapshot.ConsulIndex)
+		assert.Equal(t, int64(20*1024*1024), watcher.Current().MaxFileSizeBytes)
+		assert.Equal(t, 9090, watcher.Current().GRPCPort, "Port gRPC baru berlaku setelah restart")
+		assert.Equal(t, DefaultJobConcurrency, watcher.Current().JobConcurrencyFor("extract_text"), "Worker job baru berubah setelah restart")
+		assert.Equal(t, 8080, watcher.Current().Port, "Nilai di luar prefix dipertahankan")
+		require.Len(t, notified, 1)
+		assert.Same(t, watcher.Current(), notified[0])
+	})
+
+	t.Run("Invalid update is rejected and last good config kept", func(t *testing.T) {
+		kv.set(6, map[string]string{KeyPrefix + "/max_size_mb": "banyak"})
+		index, err := watcher.fetch(ctx, 5)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Equal(t, uint64(6), index, "index tetap maju agar nilai yang sama tidak dimuat berulang")
+
+		assert.Equal(t, uint64(2), watcher.Snapshot().Version)
+		assert.Equal(t, int64(20*1024*1024), watcher.Current().MaxFileSizeBytes)
+		rejection := watcher.LastRejection()
+		require.NotNil(t, rejection)
+		assert.Equal(t, uint64(6), rejection.ConsulIndex)
+		assert.ErrorContains(t, rejection.Err, "max_size_mb")
+		assert.Len(t, notified, 1)
+	})
+
+	t.Run("Timed out blocking query changes nothing", func(t *testing.T) {
+		index, err := watcher.fetch(ctx, 6)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(6), index)
+		assert.Equal(t, uint64(2), watcher.Snapshot().Version)
+	})
+
+	t.Run("Malformed upload policies are rejected", func(t *testing.T) {
+		kv.set(7, map[string]string{KeyPrefix + "/max_size_mb": "20", KeyPrefix + "/upload_policies": `{"policies":[{"name":"default"}]}`})
+		_, err := watcher.fetch(ctx, 6)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorContains(t, err, "upload_policies")
+		assert.Equal(t, uint64(2), watcher.Snapshot().Version)
+		assert.Empty(t, watcher.Current().UploadPolicies)
+	})
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	kv := &fakeKV{index: 1, values: map[string]string{KeyPrefix + "/max_size_mb": "1"}}
+	watcher := NewWatcher(kv, StorageSecrets{}, defaultConfig(t))
+	watcher.waitTime = time.Millisecond
+	applied := make(chan *Config, 1)
+	watcher.Subscribe(func(_, next *Config) { applied <- next })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Watch(ctx, 0)
+		close(done)
+	}()
+
+	select {
+	case cfg := <-applied:
+		assert.Equal(t, int64(1024*1024), cfg.MaxFileSizeBytes)
+	case <-time.After(5 * time.Second):
+		t.Fatal("konfigurasi dari Consul tidak diterapkan")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch tidak berhenti setelah context dibatalkan")
+	}
+
+	assert.NotPanics(t, func() { NewWatcher(nil, StorageSecrets{}, Dev()).Watch(context.Background(), 0) })
+}
+
+func TestKeepRestartOnly(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mutate   func(c *Config)
+		expected []string
+	}{
+		{name: "Hot-reloadable change", mutate: func(c *Config) { c.MaxFileSizeBytes = 1 }},
+		{name: "Job shutdown timeout", mutate: func(c *Config) { c.JobShutdownTimeout = time.Minute }, expected: []string{"job_*"}},
+		{name: "Metrics sample interval", mutate: func(c *Config) { c.MetricsSampleInterval = time.Minute }, expected: []string{"metrics_sample_interval_seconds"}},
+		{name: "Storage backend type", mutate: func(c *Config) { c.StorageBackend = "s3" }, expected: []string{"storage_backend, local_base_path, local_shard_depth, sftp_base_path"}},
+		{name: "Local shard depth", mutate: func(c *Config) { c.LocalConfig.ShardDepth = 4 }, expected: []string{"storage_backend, local_base_path, local_shard_depth, sftp_base_path"}},
+		{name: "Trusted proxies", mutate: func(c *Config) { c.TrustedProxies = []string{"10.0.0.0/8"} }, expected: []string{"trusted_proxies"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			previous := defaultConfig(t)
+			next := *previous
+			tc.mutate(&next)
+
+			changed := keepRestartOnly(previous, &next)
+			assert.Equal(t, tc.expected, changed)
+			if len(tc.expected) > 0 {
+				assert.Equal(t, previous, &next, "Nilai restart-only harus tetap memakai nilai startup")
+			}
+		})
+	}
+}
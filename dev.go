@@ -0,0 +1,99 @@
+//go:build dev
+
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/enhanced_logger"
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/metrics"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/tracing"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// devJWTSecret adalah secret JWT statis untuk mode dev. Token yang
+// ditandatangani dengannya tidak boleh diterima di lingkungan lain.
+const devJWTSecret = "prism-dev-secret"
+
+// runDevMode menjalankan service tanpa Vault, Consul, Postgres, maupun Redis
+// eksternal: semua data disimpan di memori dan hilang saat proses berhenti.
+// Redis diganti miniredis di dalam proses agar middleware JWT, autentikasi
+// gRPC, dan denylist token tetap berjalan apa adanya. File ini hanya ikut
+// dikompilasi dengan tag build "dev", sehingga binary produksi tidak memuat
+// miniredis maupun secret JWT statis.
+func runDevMode(serviceLogger zerolog.Logger) {
+	serviceLogger.Warn().Msg("!!! MODE DEV AKTIF: data hanya di memori, Redis tiruan di dalam proses, dan secret JWT statis. JANGAN dipakai di produksi !!!")
+	if os.Getenv("JWT_SECRET_KEY") == "" {
+		if err := os.Setenv("JWT_SECRET_KEY", devJWTSecret); err != nil {
+			serviceLogger.Fatal().Err(err).Msg("Gagal set env var JWT_SECRET_KEY")
+		}
+	}
+
+	redisServer, err := miniredis.Run()
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msg("Gagal menjalankan Redis di memori untuk mode dev")
+	}
+	defer redisServer.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			serviceLogger.Error().Err(err).Msg("Gagal menutup koneksi Redis dengan benar")
+		}
+	}()
+
+	cfg := fileserviceconfig.Dev()
+	accessRuleRepo := repository.NewMemoryAccessRuleRepository()
+	fileRepo := repository.NewMemoryFileRepository(accessRuleRepo)
+	deps := dependencies{
+		fileRepo:       tracing.NewFileRepository(metrics.NewFileRepository(fileRepo)),
+		accessRuleRepo: accessRuleRepo,
+		s3Repo:         repository.NewMemoryS3Repository(fileRepo),
+		davRepo:        repository.NewMemoryDAVCollectionRepository(),
+		integrityRepo:  fileRepo,
+		searchRepo:     fileRepo,
+		jobRepo:        repository.NewMemoryJobRepository(),
+		renditionRepo:  fileRepo,
+		usageRepo:      fileRepo,
+		fileStorage:    tracing.NewStorage(metrics.NewStorage(storage.NewMemoryStorage(), cfg.StorageBackend), cfg.StorageBackend),
+		redisClient:    redisClient,
+	}
+
+	enhanced_logger.LogStartup(cfg.ServiceName, cfg.Port, map[string]interface{}{
+		"mode":            "dev",
+		"storage_backend": cfg.StorageBackend,
+		"grpc_port":       cfg.GRPCPort,
+		"s3_gateway_port": cfg.S3GatewayPort,
+	})
+
+	token, err := devAdminToken(os.Getenv("JWT_SECRET_KEY"))
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msg("Gagal membuat token admin mode dev")
+	}
+	serviceLogger.Info().Str("token", token).Msg("Token admin mode dev (berlaku 24 jam)")
+
+	// Tanpa Consul, konfigurasi mode dev tidak pernah dimuat ulang.
+	serve(fileserviceconfig.NewWatcher(nil, fileserviceconfig.StorageSecrets{}, cfg), deps, policy.NewDefaultEngine(), serviceLogger)
+}
+
+// devAdminToken membuat JWT berperan admin agar endpoint dapat langsung
+// dicoba tanpa user-service.
+func devAdminToken(secret string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":  "dev-admin",
+		"role": "admin",
+		"jti":  uuid.NewString(),
+		"iat":  now.Unix(),
+		"exp":  now.Add(24 * time.Hour).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
@@ -0,0 +1,12 @@
+//go:build !dev
+
+package main
+
+import "github.com/rs/zerolog"
+
+// runDevMode pada build produksi menolak berjalan alih-alih diam-diam memakai
+// konfigurasi produksi: mode dev hanya tersedia pada binary yang dibangun
+// dengan tag build "dev".
+func runDevMode(serviceLogger zerolog.Logger) {
+	serviceLogger.Fatal().Msg("Mode dev diminta (argumen dev atau PRISM_DEV_MODE) tetapi binary ini dibangun tanpa tag build \"dev\"; jalankan `go run -tags dev . dev`")
+}
@@ -0,0 +1,277 @@
+package dav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/webdav"
+)
+
+// fileInfo mengimplementasikan os.FileInfo serta webdav.ETager dan
+// webdav.ContentTyper agar PROPFIND tidak perlu membuka isi file.
+type fileInfo struct {
+	name     string
+	size     int64
+	modTime  time.Time
+	dir      bool
+	metadata *model.FileMetadata
+}
+
+func newFileInfo(metadata *model.FileMetadata) *fileInfo {
+	return &fileInfo{name: metadata.OriginalName, size: metadata.SizeBytes, modTime: metadata.CreatedAt, metadata: metadata}
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.dir }
+func (i *fileInfo) Sys() any           { return nil }
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+func (i *fileInfo) ETag(ctx context.Context) (string, error) {
+	if i.metadata == nil {
+		return "", webdav.ErrNotImplemented
+	}
+	return fmt.Sprintf(`"%s-%d"`, i.metadata.ID, i.metadata.Version), nil
+}
+
+func (i *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if i.metadata == nil {
+		return "", webdav.ErrNotImplemented
+	}
+	return i.metadata.MimeType, nil
+}
+
+// readFile membaca isi file dari storage secara malas. Seek hanya menggeser
+// posisi; reader dibuka ulang jika posisi baca berubah.
+type readFile struct {
+	fs        *FileSystem
+	ctx       context.Context
+	info      *fileInfo
+	source    *model.FileMetadata
+	offset    int64
+	reader    io.ReadCloser
+	readerPos int64
+}
+
+func (f *readFile) Read(p []byte) (int, error) {
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	if f.reader == nil || f.readerPos != f.offset {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.reader.Read(p)
+	f.offset += int64(n)
+	f.readerPos += int64(n)
+	return n, err
+}
+
+// open memeriksa akses baca lewat GetFileMetadata (sekali per file yang
+// dibuka), lalu membuka reader di posisi saat ini.
+func (f *readFile) open() error {
+	if f.source == nil {
+		claims, _ := claimsFrom(f.ctx)
+		metadata, err := f.fs.files.GetFileMetadata(f.ctx, f.info.metadata.ID, claims)
+		if err != nil {
+			return f.fs.fail(f.ctx, err)
+		}
+		f.source = metadata
+	}
+	if err := f.closeReader(); err != nil {
+		return err
+	}
+	reader, err := f.fs.files.GetFileReader(f.ctx, f.source)
+	if err != nil {
+		return fmt.Errorf("gagal membuka file dari storage: %w", err)
+	}
+	if err := storage.Skip(reader, f.offset); err != nil {
+		_ = reader.Close()
+		return fmt.Errorf("gagal melompat ke posisi %d: %w", f.offset, err)
+	}
+	f.reader, f.readerPos = reader, f.offset
+	return nil
+}
+
+func (f *readFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	default:
+		return 0, os.ErrInvalid
+	}
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *readFile) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, &os.PathError{Op: "readdir", Path: f.info.name, Err: errors.New("bukan koleksi")}
+}
+
+func (f *readFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *readFile) Write(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.info.name, Err: os.ErrPermission}
+}
+
+func (f *readFile) Close() error { return f.closeReader() }
+
+func (f *readFile) closeReader() error {
+	if f.reader == nil {
+		return nil
+	}
+	err := f.reader.Close()
+	f.reader = nil
+	return err
+}
+
+// writeFile menampung isi PUT di file sementara dan mengunggahnya saat Close.
+type writeFile struct {
+	fs       *FileSystem
+	ctx      context.Context
+	parent   string
+	base     string
+	existing *model.FileMetadata
+	tmp      *os.File
+	size     int64
+}
+
+func (f *writeFile) Write(p []byte) (int, error) {
+	n, err := f.tmp.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *writeFile) Close() error {
+	defer func() {
+		_ = f.tmp.Close()
+		if err := os.Remove(f.tmp.Name()); err != nil {
+			log.Warn().Err(err).Str("path", f.tmp.Name()).Msg("Gagal menghapus file sementara WebDAV")
+		}
+	}()
+	if _, err := f.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	var tags []string
+	switch {
+	case f.existing != nil:
+		tags = f.existing.Tags
+	case f.parent != "":
+		tags = []string{f.parent}
+	}
+	claims, userID := claimsFrom(f.ctx)
+	defer stateFrom(f.ctx).invalidate()
+	role, _ := claims["role"].(string)
+	ctx := uploadpolicy.WithRequest(f.ctx, uploadpolicy.Request{Role: role})
+	if _, err := f.fs.files.UploadStream(ctx, userID, f.base, f.tmp, tags); err != nil {
+		return f.fs.fail(f.ctx, err)
+	}
+	if f.existing != nil {
+		if err := f.fs.files.DeleteFile(f.ctx, f.existing.ID, claims); err != nil {
+			log.Warn().Err(err).Str("file_id", f.existing.ID).Msg("Gagal menghapus versi lama setelah file WebDAV ditimpa")
+		}
+	}
+	return nil
+}
+
+func (f *writeFile) Stat() (fs.FileInfo, error) {
+	return &fileInfo{name: f.base, size: f.size, modTime: time.Now()}, nil
+}
+
+func (f *writeFile) Read(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "read", Path: f.base, Err: os.ErrPermission}
+}
+
+func (f *writeFile) Seek(offset int64, whence int) (int64, error) {
+	return f.tmp.Seek(offset, whence)
+}
+
+func (f *writeFile) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, &os.PathError{Op: "readdir", Path: f.base, Err: errors.New("bukan koleksi")}
+}
+
+// dirFile adalah koleksi yang dibuka untuk dibaca (PROPFIND, COPY).
+type dirFile struct {
+	fs       *FileSystem
+	ctx      context.Context
+	tag      string
+	info     *fileInfo
+	children []fs.FileInfo
+	pos      int
+	loaded   bool
+}
+
+func (d *dirFile) Readdir(count int) ([]fs.FileInfo, error) {
+	if !d.loaded {
+		l, err := d.fs.list(d.ctx, d.tag)
+		if err != nil {
+			return nil, err
+		}
+		for name, modTime := range l.collections {
+			d.children = append(d.children, &fileInfo{name: name, dir: true, modTime: modTime})
+		}
+		for name, metadata := range l.files {
+			if _, shadowed := l.collections[name]; !shadowed {
+				d.children = append(d.children, newFileInfo(metadata))
+			}
+		}
+		sort.Slice(d.children, func(i, j int) bool { return d.children[i].Name() < d.children[j].Name() })
+		d.loaded = true
+	}
+
+	remaining := d.children[d.pos:]
+	if count <= 0 {
+		d.pos = len(d.children)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n := min(count, len(remaining))
+	d.pos += n
+	return remaining[:n], nil
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dirFile) Close() error               { return nil }
+
+func (d *dirFile) Read(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "read", Path: d.info.name, Err: errors.New("koleksi tidak memiliki isi")}
+}
+
+func (d *dirFile) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		d.pos = 0
+		return 0, nil
+	}
+	return 0, os.ErrInvalid
+}
+
+func (d *dirFile) Write(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: d.info.name, Err: os.ErrPermission}
+}
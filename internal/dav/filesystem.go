@@ -0,0 +1,427 @@
+// Package dav menyajikan file Prism sebagai drive WebDAV. Koleksi dipetakan ke
+// tag hierarkis: file dengan tag "keuangan/pajak" muncul di
+// /keuangan/pajak/<nama asli>, sedangkan file tanpa tag muncul di root. File
+// dengan beberapa tag muncul di setiap koleksi yang sesuai. Semua operasi
+// diteruskan ke FileService sehingga validasi dan kebijakan otorisasi tetap
+// berlaku.
+package dav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/webdav"
+)
+
+// maxListing membatasi jumlah file yang boleh dibaca pemanggil dalam satu
+// koleksi. Berupa var agar dapat diperkecil di test.
+var maxListing = 10000
+
+// ErrCollectionTooLarge dikembalikan saat DELETE atau MOVE koleksi memuat
+// lebih dari maxListing file. Operasi ditolak seluruhnya alih-alih hanya
+// memproses sebagian pohon.
+var ErrCollectionTooLarge = errors.New("koleksi melebihi batas listing")
+
+type claimsKey struct{}
+
+// WithClaims menyimpan klaim pemanggil di context; FileSystem memakainya untuk
+// setiap panggilan ke FileService.
+func WithClaims(ctx context.Context, claims jwt.MapClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func claimsFrom(ctx context.Context) (jwt.MapClaims, string) {
+	claims, _ := ctx.Value(claimsKey{}).(jwt.MapClaims)
+	userID, _ := claims["sub"].(string)
+	return claims, userID
+}
+
+// FileSystem mengimplementasikan webdav.FileSystem di atas FileService.
+type FileSystem struct {
+	files       service.FileService
+	collections repository.DAVCollectionRepository
+}
+
+var _ webdav.FileSystem = (*FileSystem)(nil)
+
+func NewFileSystem(files service.FileService, collections repository.DAVCollectionRepository) *FileSystem {
+	return &FileSystem{files: files, collections: collections}
+}
+
+// listing adalah isi langsung sebuah koleksi.
+type listing struct {
+	exists bool
+	// files berisi file terbaru untuk setiap nama; file lama dengan nama sama
+	// di koleksi yang sama tidak ditampilkan.
+	files map[string]*model.FileMetadata
+	// collections berisi sub-koleksi beserta waktu file terbarunya.
+	collections map[string]time.Time
+}
+
+func (fs *FileSystem) list(ctx context.Context, tag string) (*listing, error) {
+	state := stateFrom(ctx)
+	if cached, ok := state.listings[tag]; ok {
+		return cached, nil
+	}
+
+	claims, userID := claimsFrom(ctx)
+	files, err := fs.files.ListFiles(ctx, repository.FileFilter{TagPrefix: tag, Limit: maxListing}, claims)
+	if err != nil {
+		return nil, fs.fail(ctx, err)
+	}
+	if len(files) == maxListing {
+		log.Warn().Ctx(ctx).Str("collection", tag).Int("limit", maxListing).Msg("Isi koleksi WebDAV dipotong pada batas listing")
+	}
+
+	l := &listing{exists: tag == "", files: map[string]*model.FileMetadata{}, collections: map[string]time.Time{}}
+	for _, metadata := range files {
+		direct := tag == "" && len(metadata.Tags) == 0
+		for _, fileTag := range metadata.Tags {
+			if fileTag == tag {
+				direct, l.exists = true, true
+				continue
+			}
+			if child, ok := childOf(tag, fileTag); ok {
+				l.exists = true
+				if metadata.CreatedAt.After(l.collections[child]) {
+					l.collections[child] = metadata.CreatedAt
+				}
+			}
+		}
+		// ListFiles terurut dari yang terbaru, jadi file pertama yang menang.
+		if _, seen := l.files[metadata.OriginalName]; direct && !seen {
+			l.files[metadata.OriginalName] = metadata
+		}
+	}
+
+	paths, err := fs.collections.ListCollections(ctx, userID, tag)
+	if err != nil {
+		return nil, fs.fail(ctx, err)
+	}
+	for _, path := range paths {
+		if path == tag {
+			l.exists = true
+			continue
+		}
+		if child, ok := childOf(tag, path); ok {
+			l.exists = true
+			if _, seen := l.collections[child]; !seen {
+				l.collections[child] = time.Time{}
+			}
+		}
+	}
+
+	state.listings[tag] = l
+	return l, nil
+}
+
+// childOf mengembalikan segmen tag tepat di bawah parent, misalnya
+// childOf("keuangan", "keuangan/pajak/2025") = "pajak".
+func childOf(parent, tag string) (string, bool) {
+	rest := tag
+	if parent != "" {
+		var ok bool
+		if rest, ok = strings.CutPrefix(tag, parent+"/"); !ok {
+			return "", false
+		}
+	}
+	child, _, _ := strings.Cut(rest, "/")
+	return child, child != ""
+}
+
+// splitPath memecah path WebDAV menjadi tag koleksi induk dan nama entri.
+func splitPath(name string) (parent, base string) {
+	name = strings.Trim(name, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+func (fs *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	info, err := fs.stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (fs *FileSystem) stat(ctx context.Context, name string) (*fileInfo, error) {
+	parent, base := splitPath(name)
+	if base == "" {
+		return &fileInfo{name: "/", dir: true}, nil
+	}
+	l, err := fs.list(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	if modTime, ok := l.collections[base]; ok {
+		return &fileInfo{name: base, dir: true, modTime: modTime}, nil
+	}
+	if metadata, ok := l.files[base]; ok {
+		return newFileInfo(metadata), nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+func (fs *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return fs.create(ctx, name, flag)
+	}
+	info, err := fs.stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.dir {
+		return &dirFile{fs: fs, ctx: ctx, tag: strings.Trim(name, "/"), info: info}, nil
+	}
+	return &readFile{fs: fs, ctx: ctx, info: info}, nil
+}
+
+// create membuka file untuk ditulis. Isi ditampung sampai Close, lalu diunggah
+// lewat FileService; file lama dengan nama yang sama diganti dan tag-nya
+// dipertahankan.
+func (fs *FileSystem) create(ctx context.Context, name string, flag int) (webdav.File, error) {
+	parent, base := splitPath(name)
+	if base == "" {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrInvalid}
+	}
+	l, err := fs.list(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	if !l.exists {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	if _, isDir := l.collections[base]; isDir {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	}
+	existing := l.files[base]
+	if existing != nil && flag&os.O_EXCL != 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	}
+	if existing != nil && !canModify(ctx, existing) {
+		return nil, fs.fail(ctx, service.ErrAccessDenied)
+	}
+
+	tmp, err := os.CreateTemp("", "prism-dav-*")
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat file sementara: %w", err)
+	}
+	return &writeFile{fs: fs, ctx: ctx, parent: parent, base: base, existing: existing, tmp: tmp}, nil
+}
+
+func (fs *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	parent, base := splitPath(name)
+	if base == "" {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+	path := strings.Trim(name, "/")
+	if err := service.ValidateTag(path); err != nil {
+		return fs.fail(ctx, err)
+	}
+	l, err := fs.list(ctx, parent)
+	if err != nil {
+		return err
+	}
+	if !l.exists {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrNotExist}
+	}
+	_, isDir := l.collections[base]
+	_, isFile := l.files[base]
+	if isDir || isFile {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+
+	_, userID := claimsFrom(ctx)
+	defer stateFrom(ctx).invalidate()
+	if err := fs.collections.CreateCollection(ctx, userID, path); err != nil {
+		return fs.fail(ctx, err)
+	}
+	return nil
+}
+
+// RemoveAll menghapus file dari sebuah koleksi. File yang masih memiliki tag
+// lain hanya dilepas dari koleksi tersebut; file tanpa tag tersisa dihapus.
+func (fs *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	parent, base := splitPath(name)
+	if base == "" {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrPermission}
+	}
+	info, err := fs.stat(ctx, name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer stateFrom(ctx).invalidate()
+	claims, userID := claimsFrom(ctx)
+
+	if !info.dir {
+		if parent != "" && len(info.metadata.Tags) > 1 {
+			_, err = fs.files.RemoveFileTag(ctx, info.metadata.ID, parent, info.metadata.Version, claims)
+		} else {
+			err = fs.files.DeleteFile(ctx, info.metadata.ID, claims)
+		}
+		if err != nil {
+			return fs.fail(ctx, err)
+		}
+		return nil
+	}
+
+	tag := strings.Trim(name, "/")
+	files, err := fs.collectionTree(ctx, tag, claims)
+	if err != nil {
+		return err
+	}
+	for _, metadata := range files {
+		remaining := make([]string, 0, len(metadata.Tags))
+		for _, fileTag := range metadata.Tags {
+			if !inTree(tag, fileTag) {
+				remaining = append(remaining, fileTag)
+			}
+		}
+		if len(remaining) == 0 {
+			err = fs.files.DeleteFile(ctx, metadata.ID, claims)
+		} else {
+			_, err = fs.files.UpdateFileMetadata(ctx, metadata.ID, repository.MetadataUpdate{Tags: &remaining}, metadata.Version, claims)
+		}
+		if err != nil {
+			return fs.fail(ctx, err)
+		}
+	}
+	if err := fs.collections.DeleteCollections(ctx, userID, tag); err != nil {
+		return fs.fail(ctx, err)
+	}
+	return nil
+}
+
+// Rename memindahkan file antar koleksi (mengganti tag koleksi asal dengan
+// tag koleksi tujuan) dan/atau mengganti nama aslinya. Memindahkan koleksi
+// mengganti prefix tag semua file di dalamnya.
+func (fs *FileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	oldParent, oldBase := splitPath(oldName)
+	newParent, newBase := splitPath(newName)
+	if oldBase == "" || newBase == "" {
+		return &os.PathError{Op: "rename", Path: oldName, Err: os.ErrPermission}
+	}
+	info, err := fs.stat(ctx, oldName)
+	if err != nil {
+		return err
+	}
+	l, err := fs.list(ctx, newParent)
+	if err != nil {
+		return err
+	}
+	if !l.exists {
+		return &os.PathError{Op: "rename", Path: newName, Err: os.ErrNotExist}
+	}
+	defer stateFrom(ctx).invalidate()
+	claims, userID := claimsFrom(ctx)
+
+	if !info.dir {
+		update := repository.MetadataUpdate{}
+		if newBase != info.metadata.OriginalName {
+			update.OriginalName = &newBase
+		}
+		if oldParent != newParent {
+			tags := retag(info.metadata.Tags, oldParent, newParent)
+			update.Tags = &tags
+		}
+		if _, err := fs.files.UpdateFileMetadata(ctx, info.metadata.ID, update, info.metadata.Version, claims); err != nil {
+			return fs.fail(ctx, err)
+		}
+		return nil
+	}
+
+	oldTag, newTag := strings.Trim(oldName, "/"), strings.Trim(newName, "/")
+	if err := service.ValidateTag(newTag); err != nil {
+		return fs.fail(ctx, err)
+	}
+	files, err := fs.collectionTree(ctx, oldTag, claims)
+	if err != nil {
+		return err
+	}
+	for _, metadata := range files {
+		tags := make([]string, len(metadata.Tags))
+		for i, fileTag := range metadata.Tags {
+			tags[i] = fileTag
+			if inTree(oldTag, fileTag) {
+				tags[i] = newTag + strings.TrimPrefix(fileTag, oldTag)
+			}
+		}
+		if _, err := fs.files.UpdateFileMetadata(ctx, metadata.ID, repository.MetadataUpdate{Tags: &tags}, metadata.Version, claims); err != nil {
+			return fs.fail(ctx, err)
+		}
+	}
+	if err := fs.collections.RenameCollections(ctx, userID, oldTag, newTag); err != nil {
+		return fs.fail(ctx, err)
+	}
+	return nil
+}
+
+// collectionTree mengembalikan semua file dalam pohon koleksi tag yang boleh
+// dibaca pemanggil. Pohon yang melebihi maxListing ditolak dengan
+// ErrCollectionTooLarge sebelum ada file yang diubah.
+func (fs *FileSystem) collectionTree(ctx context.Context, tag string, claims jwt.MapClaims) ([]*model.FileMetadata, error) {
+	files, err := fs.files.ListFiles(ctx, repository.FileFilter{TagPrefix: tag, Limit: maxListing + 1}, claims)
+	if err != nil {
+		return nil, fs.fail(ctx, err)
+	}
+	if len(files) > maxListing {
+		return nil, fs.fail(ctx, fmt.Errorf("%w: %s", ErrCollectionTooLarge, tag))
+	}
+	return files, nil
+}
+
+// inTree melaporkan apakah tag sama dengan root atau berada di bawahnya.
+func inTree(root, tag string) bool {
+	return tag == root || strings.HasPrefix(tag, root+"/")
+}
+
+// retag mengganti tag koleksi asal dengan tag koleksi tujuan; string kosong
+// berarti root (tanpa tag).
+func retag(tags []string, from, to string) []string {
+	result := make([]string, 0, len(tags)+1)
+	for _, tag := range tags {
+		if tag != from {
+			result = append(result, tag)
+		}
+	}
+	if to != "" {
+		result = append(result, to)
+	}
+	return result
+}
+
+// canModify mengikuti aturan FileService untuk menghapus atau mengubah file:
+// hanya pemilik atau admin.
+func canModify(ctx context.Context, metadata *model.FileMetadata) bool {
+	claims, userID := claimsFrom(ctx)
+	role, _ := claims["role"].(string)
+	return role == "admin" || (metadata.OwnerUserID != nil && *metadata.OwnerUserID == userID)
+}
+
+// fail mencatat error layanan agar Handler dapat memilih status HTTP yang
+// tepat, lalu menerjemahkannya ke error os yang dipahami paket webdav.
+func (fs *FileSystem) fail(ctx context.Context, err error) error {
+	stateFrom(ctx).err = err
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		return fmt.Errorf("%w: %w", os.ErrPermission, err)
+	case errors.Is(err, repository.ErrNotFound):
+		return fmt.Errorf("%w: %w", os.ErrNotExist, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,303 @@
+package dav
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnv struct {
+	server *httptest.Server
+	files  *repository.MemoryFileRepository
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	files := repository.NewMemoryFileRepository(nil)
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    64,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true},
+	}
+	fileService := service.NewFileService(files, storage.NewMemoryStorage(), cfg, nil, nil, nil)
+	h := NewHandler("/files/dav", NewFileSystem(fileService, repository.NewMemoryDAVCollectionRepository()))
+
+	// Pengguna diambil dari header X-Test-User ("id:role"), menggantikan
+	// middleware autentikasi.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, role, _ := strings.Cut(r.Header.Get("X-Test-User"), ":")
+		h.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), jwt.MapClaims{"sub": userID, "role": role})))
+	}))
+	t.Cleanup(server.Close)
+	return &testEnv{server: server, files: files}
+}
+
+func (e *testEnv) do(t *testing.T, user, method, path, body string, headers ...string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, e.server.URL+"/files/dav"+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Test-User", user)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+// propfind mengembalikan href anggota koleksi (tanpa koleksi itu sendiri).
+func (e *testEnv) propfind(t *testing.T, user, path string) []string {
+	t.Helper()
+	status, body := e.do(t, user, "PROPFIND", path, "", "Depth", "1")
+	require.Equal(t, http.StatusMultiStatus, status, body)
+
+	var multistatus struct {
+		Responses []struct {
+			Href string `xml:"href"`
+		} `xml:"response"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(body), &multistatus))
+	var hrefs []string
+	for _, response := range multistatus.Responses {
+		if href := strings.TrimPrefix(response.Href, "/files/dav"); strings.TrimSuffix(href, "/") != strings.TrimSuffix(path, "/") {
+			hrefs = append(hrefs, href)
+		}
+	}
+	sort.Strings(hrefs)
+	return hrefs
+}
+
+const (
+	alice = "user-1:user"
+	bob   = "user-2:user"
+	admin = "admin-1:admin"
+)
+
+func TestWebDAV_CollectionsMapToTags(t *testing.T) {
+	env := newTestEnv(t)
+
+	status, _ := env.do(t, alice, "MKCOL", "/keuangan", "")
+	require.Equal(t, http.StatusCreated, status)
+	status, _ = env.do(t, alice, "MKCOL", "/keuangan/pajak", "")
+	require.Equal(t, http.StatusCreated, status)
+	status, _ = env.do(t, alice, "MKCOL", "/tidak/ada", "")
+	assert.Equal(t, http.StatusConflict, status, "induk koleksi harus ada")
+
+	status, _ = env.do(t, alice, http.MethodPut, "/keuangan/pajak/spt.txt", "laporan pajak")
+	require.Equal(t, http.StatusCreated, status)
+	status, _ = env.do(t, alice, http.MethodPut, "/catatan.txt", "catatan bebas")
+	require.Equal(t, http.StatusCreated, status)
+
+	files, err := env.files.List(context.Background(), repository.FileFilter{})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, metadata := range files {
+		switch metadata.OriginalName {
+		case "spt.txt":
+			assert.Equal(t, []string{"keuangan/pajak"}, metadata.Tags)
+		case "catatan.txt":
+			assert.Empty(t, metadata.Tags)
+		}
+	}
+
+	assert.Equal(t, []string{"/catatan.txt", "/keuangan/"}, env.propfind(t, alice, "/"))
+	assert.Equal(t, []string{"/keuangan/pajak/spt.txt"}, env.propfind(t, alice, "/keuangan/pajak/"))
+
+	status, body := env.do(t, alice, http.MethodGet, "/keuangan/pajak/spt.txt", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "laporan pajak", body)
+
+	status, body = env.do(t, alice, http.MethodGet, "/keuangan/pajak/spt.txt", "", "Range", "bytes=8-")
+	assert.Equal(t, http.StatusPartialContent, status)
+	assert.Equal(t, "pajak", body)
+}
+
+func TestWebDAV_AccessControl(t *testing.T) {
+	env := newTestEnv(t)
+	status, _ := env.do(t, alice, http.MethodPut, "/rahasia.txt", "milik alice")
+	require.Equal(t, http.StatusCreated, status)
+
+	assert.Empty(t, env.propfind(t, bob, "/"), "file yang tidak boleh dibaca tidak ditampilkan")
+	status, _ = env.do(t, bob, http.MethodGet, "/rahasia.txt", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	assert.Equal(t, []string{"/rahasia.txt"}, env.propfind(t, admin, "/"))
+	status, body := env.do(t, admin, http.MethodGet, "/rahasia.txt", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "milik alice", body)
+}
+
+func TestWebDAV_UploadValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	status, _ := env.do(t, alice, http.MethodPut, "/gambar.png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+
+	status, _ = env.do(t, alice, http.MethodPut, "/besar.txt", strings.Repeat("a", 65))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+
+	status, _ = env.do(t, alice, "MKCOL", "/a,b", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	files, err := env.files.List(context.Background(), repository.FileFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestWebDAV_OverwriteMoveCopyDelete(t *testing.T) {
+	env := newTestEnv(t)
+	for _, col := range []string{"/arsip", "/kerja"} {
+		status, _ := env.do(t, alice, "MKCOL", col, "")
+		require.Equal(t, http.StatusCreated, status)
+	}
+	status, _ := env.do(t, alice, http.MethodPut, "/kerja/draf.txt", "versi 1")
+	require.Equal(t, http.StatusCreated, status)
+
+	t.Run("Overwrite keeps tags and replaces content", func(t *testing.T) {
+		status, _ := env.do(t, alice, http.MethodPut, "/kerja/draf.txt", "versi 2")
+		assert.Equal(t, http.StatusCreated, status)
+		_, body := env.do(t, alice, http.MethodGet, "/kerja/draf.txt", "")
+		assert.Equal(t, "versi 2", body)
+		files, _ := env.files.List(context.Background(), repository.FileFilter{})
+		assert.Len(t, files, 1, "versi lama harus dihapus")
+	})
+
+	t.Run("Other users cannot overwrite", func(t *testing.T) {
+		status, _ := env.do(t, admin, "MKCOL", "/kerja", "")
+		require.Equal(t, http.StatusMethodNotAllowed, status, "koleksi sudah ada")
+		status, _ = env.do(t, bob, http.MethodPut, "/kerja/draf.txt", "ditimpa")
+		assert.Equal(t, http.StatusConflict, status, "bob tidak melihat koleksi kerja")
+	})
+
+	t.Run("Move renames and retags", func(t *testing.T) {
+		status, _ := env.do(t, alice, "MOVE", "/kerja/draf.txt", "", "Destination", env.server.URL+"/files/dav/arsip/final.txt")
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, []string{"/arsip/final.txt"}, env.propfind(t, alice, "/arsip/"))
+		assert.Empty(t, env.propfind(t, alice, "/kerja/"))
+	})
+
+	t.Run("Copy creates a new file", func(t *testing.T) {
+		status, _ := env.do(t, alice, "COPY", "/arsip/final.txt", "", "Destination", env.server.URL+"/files/dav/kerja/salinan.txt")
+		assert.Equal(t, http.StatusCreated, status)
+		_, body := env.do(t, alice, http.MethodGet, "/kerja/salinan.txt", "")
+		assert.Equal(t, "versi 2", body)
+	})
+
+	t.Run("Move collection rewrites tag prefix", func(t *testing.T) {
+		status, _ := env.do(t, alice, "MOVE", "/arsip", "", "Destination", env.server.URL+"/files/dav/kerja/arsip-2025")
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, []string{"/kerja/arsip-2025/final.txt"}, env.propfind(t, alice, "/kerja/arsip-2025/"))
+	})
+
+	t.Run("Delete from one collection keeps other tags", func(t *testing.T) {
+		files, _ := env.files.List(context.Background(), repository.FileFilter{TagPrefix: "kerja/arsip-2025"})
+		require.Len(t, files, 1)
+		require.NoError(t, env.files.AddTag(context.Background(), files[0].ID, "penting", 0, "user-1"))
+
+		status, _ := env.do(t, alice, http.MethodDelete, "/kerja/arsip-2025/final.txt", "")
+		assert.Equal(t, http.StatusNoContent, status)
+		assert.Equal(t, []string{"/penting/final.txt"}, env.propfind(t, alice, "/penting/"))
+
+		status, _ = env.do(t, alice, http.MethodDelete, "/penting", "")
+		assert.Equal(t, http.StatusNoContent, status)
+		remaining, _ := env.files.List(context.Background(), repository.FileFilter{})
+		require.Len(t, remaining, 1)
+		assert.Equal(t, "salinan.txt", remaining[0].OriginalName)
+	})
+}
+
+func TestWebDAV_ListingLimit(t *testing.T) {
+	previous := maxListing
+	maxListing = 2
+	t.Cleanup(func() { maxListing = previous })
+
+	env := newTestEnv(t)
+	status, _ := env.do(t, alice, "MKCOL", "/keuangan", "")
+	require.Equal(t, http.StatusCreated, status)
+	status, _ = env.do(t, alice, http.MethodPut, "/keuangan/a.txt", "a")
+	require.Equal(t, http.StatusCreated, status)
+	for _, name := range []string{"/b1.txt", "/b2.txt", "/b3.txt"} {
+		status, _ = env.do(t, bob, http.MethodPut, name, "milik bob")
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	t.Run("Files of other users do not use up the limit", func(t *testing.T) {
+		assert.Equal(t, []string{"/keuangan/"}, env.propfind(t, alice, "/"))
+	})
+
+	t.Run("Oversized collection is not moved partially", func(t *testing.T) {
+		for _, name := range []string{"/keuangan/b.txt", "/keuangan/c.txt"} {
+			status, _ := env.do(t, alice, http.MethodPut, name, "x")
+			require.Equal(t, http.StatusCreated, status)
+		}
+
+		status, _ := env.do(t, alice, "MOVE", "/keuangan", "", "Destination", env.server.URL+"/files/dav/arsip")
+		assert.Equal(t, http.StatusInsufficientStorage, status)
+		status, _ = env.do(t, alice, http.MethodDelete, "/keuangan", "")
+		assert.Equal(t, http.StatusInsufficientStorage, status)
+
+		files, err := env.files.List(context.Background(), repository.FileFilter{TagPrefix: "keuangan"})
+		require.NoError(t, err)
+		assert.Len(t, files, 3)
+		for _, metadata := range files {
+			assert.Equal(t, []string{"keuangan"}, metadata.Tags)
+		}
+	})
+}
+
+func TestWebDAV_Locking(t *testing.T) {
+	env := newTestEnv(t)
+	status, _ := env.do(t, alice, http.MethodPut, "/dokumen.txt", "isi")
+	require.Equal(t, http.StatusCreated, status)
+
+	lockBody := `<?xml version="1.0" encoding="utf-8"?><D:lockinfo xmlns:D="DAV:"><D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype></D:lockinfo>`
+	req, err := http.NewRequest("LOCK", env.server.URL+"/files/dav/dokumen.txt", strings.NewReader(lockBody))
+	require.NoError(t, err)
+	req.Header.Set("X-Test-User", alice)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get("Lock-Token")
+	require.NotEmpty(t, token)
+
+	status, _ = env.do(t, alice, http.MethodPut, "/dokumen.txt", "tanpa token")
+	assert.Equal(t, http.StatusLocked, status)
+	status, _ = env.do(t, alice, http.MethodPut, "/dokumen.txt", "dengan token", "If", "("+token+")")
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, _ = env.do(t, alice, "UNLOCK", "/dokumen.txt", "", "Lock-Token", token)
+	assert.Equal(t, http.StatusNoContent, status)
+}
+
+func TestChildOf(t *testing.T) {
+	for _, tc := range []struct {
+		parent, tag, child string
+		ok                 bool
+	}{
+		{"", "keuangan/pajak", "keuangan", true},
+		{"keuangan", "keuangan/pajak/2025", "pajak", true},
+		{"keuangan", "keuangan", "", false},
+		{"keu", "keuangan/pajak", "", false},
+		{"", "", "", false},
+	} {
+		child, ok := childOf(tc.parent, tc.tag)
+		assert.Equal(t, tc.ok, ok, "%s %s", tc.parent, tc.tag)
+		assert.Equal(t, tc.child, child, "%s %s", tc.parent, tc.tag)
+	}
+}
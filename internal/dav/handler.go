@@ -0,0 +1,122 @@
+package dav
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/webdav"
+)
+
+// Methods adalah method HTTP yang dilayani Handler.
+var Methods = []string{
+	http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK",
+}
+
+// requestState menyimpan cache listing dan error layanan terakhir selama satu
+// request WebDAV.
+type requestState struct {
+	err      error
+	listings map[string]*listing
+}
+
+type stateKey struct{}
+
+func stateFrom(ctx context.Context) *requestState {
+	if state, ok := ctx.Value(stateKey{}).(*requestState); ok {
+		return state
+	}
+	return &requestState{listings: map[string]*listing{}}
+}
+
+// invalidate membuang cache listing setelah operasi yang mengubah isi koleksi.
+func (s *requestState) invalidate() {
+	clear(s.listings)
+}
+
+// Handler adalah http.Handler WebDAV. Lock disimpan di memori sehingga hanya
+// berlaku dalam satu instance.
+type Handler struct {
+	dav *webdav.Handler
+}
+
+// NewHandler membuat Handler untuk request di bawah prefix (mis. "/files/dav").
+// Klaim pemanggil harus sudah dipasang di context dengan WithClaims.
+func NewHandler(prefix string, fs *FileSystem) *Handler {
+	return &Handler{dav: &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: fs,
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				log.Warn().Err(err).Str("method", r.Method).Str("path", r.URL.Path).Msg("Request WebDAV gagal")
+			}
+		},
+	}}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	state := &requestState{listings: map[string]*listing{}}
+	ctx := context.WithValue(r.Context(), stateKey{}, state)
+	h.dav.ServeHTTP(&statusWriter{ResponseWriter: w, state: state}, r.WithContext(ctx))
+}
+
+// statusWriter mengganti status generik dari paket webdav (mis. 405 untuk
+// semua kegagalan PUT) dengan status yang sesuai error FileService.
+type statusWriter struct {
+	http.ResponseWriter
+	state      *requestState
+	overridden bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest && w.state.err != nil {
+		if status := statusFor(w.state.err); status != 0 {
+			w.overridden = true
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.ResponseWriter.WriteHeader(status)
+			_, _ = w.ResponseWriter.Write([]byte(w.state.err.Error()))
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.overridden {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func statusFor(err error) int {
+	if validationErr, ok := validation.AsError(err); ok {
+		switch validationErr.Code {
+		case validation.CodeFileTooLarge:
+			return http.StatusRequestEntityTooLarge
+		case validation.CodeMimeTypeNotAllowed:
+			return http.StatusUnsupportedMediaType
+		default:
+			return http.StatusBadRequest
+		}
+	}
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrVersionConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidMetadata):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrCollectionTooLarge):
+		return http.StatusInsufficientStorage
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,128 @@
+// Package fulltext mengekstrak teks dari isi file (PDF, teks biasa, CSV dan
+// dokumen OOXML) untuk pencarian full-text, serta mengindeks ulang file yang
+// sudah tersimpan.
+package fulltext
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// ErrUnsupported dikembalikan untuk tipe file yang tidak memiliki extractor.
+var ErrUnsupported = errors.New("tipe file tidak didukung untuk ekstraksi teks")
+
+// maxInflateBytes membatasi total data terkompresi (stream PDF, entri ZIP)
+// yang didekompresi per file agar bom kompresi tidak menghabiskan memori atau
+// CPU. Teks yang ditemukan sebelum batas tetap dipakai.
+const maxInflateBytes = 64 << 20
+
+// extractor menulis teks dari content ke w.
+type extractor func(w *textWriter, content io.ReaderAt, size int64) error
+
+// extractors dipilih berdasarkan tipe MIME hasil deteksi atau leluhurnya.
+// text/csv dan tipe teks lain turunan text/plain ikut tertangani.
+var extractors = []struct {
+	mimeType string
+	extract  extractor
+}{
+	{"application/pdf", extractPDF},
+	{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", extractOOXML},
+	{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", extractOOXML},
+	{"application/vnd.openxmlformats-officedocument.presentationml.presentation", extractOOXML},
+	{"text/plain", extractPlainText},
+}
+
+// Supports melaporkan apakah teks dapat diekstrak dari file bertipe detected.
+func Supports(detected *mimetype.MIME) bool {
+	return extractorFor(detected) != nil
+}
+
+// Extract mengembalikan teks dari content (size byte dari offset 0) dengan
+// spasi yang dinormalisasi, dipotong pada limit byte. Teks yang sudah
+// ditemukan tetap dikembalikan bersama error jika ekstraksi gagal di tengah
+// jalan.
+func Extract(content io.ReaderAt, size int64, detected *mimetype.MIME, limit int) (string, error) {
+	extract := extractorFor(detected)
+	if extract == nil {
+		return "", ErrUnsupported
+	}
+	w := &textWriter{limit: limit}
+	err := extract(w, content, size)
+	if errors.Is(err, errLimitReached) {
+		err = nil
+	}
+	return w.String(), err
+}
+
+func extractorFor(detected *mimetype.MIME) extractor {
+	for m := detected; m != nil; m = m.Parent() {
+		for _, e := range extractors {
+			if m.Is(e.mimeType) {
+				return e.extract
+			}
+		}
+	}
+	return nil
+}
+
+func extractPlainText(w *textWriter, content io.ReaderAt, size int64) error {
+	data, err := io.ReadAll(io.LimitReader(io.NewSectionReader(content, 0, size), int64(w.limit)))
+	if err != nil {
+		return err
+	}
+	return w.WriteString(string(data))
+}
+
+// errLimitReached menghentikan extractor saat textWriter sudah penuh.
+var errLimitReached = errors.New("batas teks tercapai")
+
+// textWriter mengumpulkan teks hingga limit byte. Karakter kontrol (termasuk
+// NUL yang tidak dapat disimpan Postgres), karakter Private Use (dipakai
+// sebagai penanda snippet pencarian) dan spasi beruntun diringkas menjadi satu
+// spasi, dan byte UTF-8 yang tidak valid dibuang.
+type textWriter struct {
+	buf     strings.Builder
+	limit   int
+	pending bool
+}
+
+// WriteString menambahkan s dan mengembalikan errLimitReached jika limit
+// tercapai.
+func (w *textWriter) WriteString(s string) error {
+	for _, r := range s {
+		if r == utf8.RuneError {
+			continue
+		}
+		if unicode.IsSpace(r) || unicode.IsControl(r) || unicode.Is(unicode.Co, r) {
+			w.pending = w.buf.Len() > 0
+			continue
+		}
+		need := utf8.RuneLen(r)
+		if w.pending {
+			need++
+		}
+		if w.buf.Len()+need > w.limit {
+			return errLimitReached
+		}
+		if w.pending {
+			w.buf.WriteByte(' ')
+			w.pending = false
+		}
+		w.buf.WriteRune(r)
+	}
+	return nil
+}
+
+// Break memisahkan teks berikutnya dari teks sebelumnya dengan spasi.
+func (w *textWriter) Break() {
+	w.pending = w.buf.Len() > 0
+}
+
+func (w *textWriter) String() string {
+	return w.buf.String()
+}
@@ -0,0 +1,62 @@
+package fulltext
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func extractBytes(t *testing.T, content []byte, limit int) string {
+	t.Helper()
+	text, err := Extract(bytes.NewReader(content), int64(len(content)), mimetype.Detect(content), limit)
+	require.NoError(t, err)
+	return text
+}
+
+func TestExtract_PlainText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		limit    int
+		expected string
+	}{
+		{name: "Whitespace is collapsed", content: "Laporan\tanggaran\r\n\n  tahunan ", limit: 1024, expected: "Laporan anggaran tahunan"},
+		{name: "CSV", content: "nama,jumlah\nsemen,100\npasir,250\n", limit: 1024, expected: "nama,jumlah semen,100 pasir,250"},
+		{name: "Cut at limit without splitting runes", content: "naïve résumé", limit: 3, expected: "na"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, extractBytes(t, []byte(tc.content), tc.limit))
+		})
+	}
+}
+
+func TestExtract_Unsupported(t *testing.T) {
+	content := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 32))
+	detected := mimetype.Detect(content)
+	assert.False(t, Supports(detected))
+	_, err := Extract(bytes.NewReader(content), int64(len(content)), detected, 1024)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestTextWriter_Normalizes(t *testing.T) {
+	w := &textWriter{limit: 1024}
+	require.NoError(t, w.WriteString("  kontrak\x00rahasia\ue000tanda \xff"))
+	w.Break()
+	w.Break()
+	require.NoError(t, w.WriteString("akhir"))
+	assert.Equal(t, "kontrak rahasia tanda akhir", w.String())
+}
+
+func TestTextWriter_Limit(t *testing.T) {
+	w := &textWriter{limit: 10}
+	require.NoError(t, w.WriteString("satu"))
+	w.Break()
+	assert.ErrorIs(t, w.WriteString("duabelas"), errLimitReached)
+	assert.Equal(t, "satu duabe", w.String())
+}
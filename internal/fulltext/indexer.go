@@ -0,0 +1,137 @@
+package fulltext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/rs/zerolog/log"
+)
+
+// IndexResult merangkum satu kali pengindeksan ulang.
+type IndexResult struct {
+	// Indexed adalah file yang teksnya berhasil diekstrak dan disimpan.
+	Indexed int
+	// Unsupported adalah file yang tipenya tidak memiliki extractor; teksnya
+	// dikosongkan agar hanya nama file yang dapat dicari.
+	Unsupported int
+	// Failed adalah file yang gagal dibaca dari storage atau diekstrak.
+	Failed int
+}
+
+// Indexer mengekstrak ulang teks file yang sudah tersimpan, mis. setelah
+// migrasi pencarian atau setelah extractor diperbaiki.
+type Indexer struct {
+	repo         repository.SearchRepository
+	storage      storage.Storage
+	maxTextBytes int
+	batchSize    int
+	now          func() time.Time
+}
+
+func NewIndexer(repo repository.SearchRepository, storage storage.Storage, maxTextBytes, batchSize int) *Indexer {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &Indexer{repo: repo, storage: storage, maxTextBytes: maxTextBytes, batchSize: batchSize, now: time.Now}
+}
+
+// Reindex memproses semua file aktif secara berurutan berdasarkan ID, atau
+// hanya file yang belum pernah diindeks jika onlyMissing. Kegagalan per file
+// dicatat dan dilewati; error hanya dikembalikan jika repository gagal atau
+// ctx dibatalkan.
+func (i *Indexer) Reindex(ctx context.Context, onlyMissing bool) (IndexResult, error) {
+	var result IndexResult
+	afterID := ""
+	for {
+		files, err := i.repo.ListForIndex(ctx, afterID, onlyMissing, i.batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(files) == 0 {
+			return result, nil
+		}
+		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			afterID = file.ID
+			err := i.IndexFile(ctx, file)
+			var extractErr *extractError
+			switch {
+			case errors.As(err, &extractErr):
+				log.Warn().Err(err).Str("file_id", file.ID).Msg("Gagal mengekstrak teks file, dilewati")
+				result.Failed++
+			case errors.Is(err, ErrUnsupported):
+				result.Unsupported++
+			case err != nil:
+				return result, err
+			default:
+				result.Indexed++
+			}
+		}
+	}
+}
+
+// extractError membungkus kegagalan membaca atau mengekstrak satu file,
+// untuk membedakannya dari kegagalan repository.
+type extractError struct{ err error }
+
+func (e *extractError) Error() string { return e.err.Error() }
+func (e *extractError) Unwrap() error { return e.err }
+
+// IndexFile mengekstrak teks satu file lalu menyimpannya. File bertipe tanpa
+// extractor dicatat dengan teks kosong dan ErrUnsupported dikembalikan
+// setelah pencatatan berhasil. File yang sudah dihapus dilewati tanpa error.
+func (i *Indexer) IndexFile(ctx context.Context, file *model.FileMetadata) error {
+	text, err := i.extract(ctx, file)
+	unsupported := errors.Is(err, ErrUnsupported)
+	if err != nil && !unsupported {
+		return &extractError{err: err}
+	}
+	if err := i.repo.RecordContent(ctx, file.ID, text, i.now()); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if unsupported {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+// extract membaca konten file ke file sementara (extractor membutuhkan akses
+// acak) lalu mengekstrak teksnya sesuai tipe hasil deteksi.
+func (i *Indexer) extract(ctx context.Context, file *model.FileMetadata) (string, error) {
+	reader, err := i.storage.Get(ctx, file.StoragePath)
+	if err != nil {
+		return "", fmt.Errorf("gagal membaca file dari storage: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	tmp, err := os.CreateTemp("", "prism-index-*")
+	if err != nil {
+		return "", fmt.Errorf("gagal membuat file sementara: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		if removeErr := os.Remove(tmp.Name()); removeErr != nil {
+			log.Warn().Err(removeErr).Str("path", tmp.Name()).Msg("Gagal menghapus file sementara pengindeksan")
+		}
+	}()
+	size, err := io.Copy(tmp, reader)
+	if err != nil {
+		return "", fmt.Errorf("gagal membaca file dari storage: %w", err)
+	}
+
+	detected, err := mimetype.DetectReader(io.NewSectionReader(tmp, 0, size))
+	if err != nil {
+		return "", err
+	}
+	return Extract(tmp, size, detected, i.maxTextBytes)
+}
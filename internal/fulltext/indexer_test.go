@@ -0,0 +1,53 @@
+package fulltext
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexer_Reindex(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	store := storage.NewMemoryStorage()
+
+	files := []*model.FileMetadata{
+		{ID: "a-catatan", OriginalName: "catatan.txt", StoragePath: "a.txt"},
+		{ID: "b-gambar", OriginalName: "foto.png", StoragePath: "b.png"},
+		{ID: "c-hilang", OriginalName: "hilang.txt", StoragePath: "c.txt"},
+	}
+	for _, file := range files {
+		require.NoError(t, repo.Create(ctx, file, nil))
+	}
+	require.NoError(t, store.Save(ctx, "a.txt", strings.NewReader("Notulen rapat anggaran")))
+	require.NoError(t, store.Save(ctx, "b.png", strings.NewReader("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")))
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	indexer := NewIndexer(repo, store, 1024, 1)
+	indexer.now = func() time.Time { return now }
+
+	result, err := indexer.Reindex(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, IndexResult{Indexed: 1, Unsupported: 1, Failed: 1}, result)
+
+	hits, err := repo.Search(ctx, repository.SearchQuery{Text: "anggaran", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "a-catatan", hits[0].ID)
+
+	indexed, err := repo.GetByID(ctx, "b-gambar")
+	require.NoError(t, err)
+	require.NotNil(t, indexed.IndexedAt)
+	assert.True(t, indexed.IndexedAt.Equal(now))
+
+	result, err = indexer.Reindex(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, IndexResult{Failed: 1}, result, "Hanya file yang gagal diproses ulang dengan -missing")
+}
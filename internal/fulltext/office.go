@@ -0,0 +1,100 @@
+package fulltext
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/ooxml"
+)
+
+// ooxmlTextParts adalah bagian dokumen DOCX/XLSX/PPTX yang memuat teks yang
+// dilihat pengguna. Properti dokumen (docProps) sengaja tidak diindeks.
+var ooxmlTextParts = []string{
+	"word/document.xml", "word/header*.xml", "word/footer*.xml", "word/footnotes.xml", "word/endnotes.xml",
+	"xl/sharedStrings.xml", "xl/worksheets/sheet*.xml",
+	"ppt/slides/slide*.xml", "ppt/notesSlides/notesSlide*.xml",
+}
+
+// extractOOXML menulis teks dari elemen <t> (w:t, a:t dan t pada shared
+// strings atau inline string) setiap bagian teks, dengan batas paragraf, sel
+// dan tab sebagai pemisah kata.
+func extractOOXML(w *textWriter, content io.ReaderAt, size int64) error {
+	pkg, err := ooxml.Open(content, size)
+	if err != nil {
+		return err
+	}
+	budget := int64(maxInflateBytes)
+	return pkg.Walk(func(name string, part *zip.File) error {
+		if budget <= 0 || !isOOXMLTextPart(name) {
+			return nil
+		}
+		rc, err := part.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		limited := &io.LimitedReader{R: rc, N: budget}
+		err = extractXMLText(w, limited)
+		budget = limited.N
+		w.Break()
+		return err
+	})
+}
+
+// isOOXMLTextPart mencocokkan nama bagian hasil ooxml.PartName (huruf kecil)
+// dengan ooxmlTextParts.
+func isOOXMLTextPart(name string) bool {
+	for _, pattern := range ooxmlTextParts {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// extractXMLText menulis isi elemen bernama lokal "t". Elemen p, si, c dan
+// tab menjadi pemisah kata. XML yang terpotong oleh batas dekompresi
+// diperlakukan sebagai akhir bagian.
+func extractXMLText(w *textWriter, r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return nil
+			}
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "t":
+				depth++
+			case "tab", "br":
+				w.Break()
+			}
+		case xml.EndElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "t":
+				depth--
+			case "p", "si", "c":
+				w.Break()
+			}
+		case xml.CharData:
+			if depth > 0 {
+				if err := w.WriteString(string(t)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
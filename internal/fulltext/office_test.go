@@ -0,0 +1,78 @@
+package fulltext
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ooxmlFile(t *testing.T, entries ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		f, err := w.Create(e[0])
+		require.NoError(t, err)
+		_, err = f.Write([]byte(e[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestExtractOOXML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		entries  [][2]string
+		expected string
+	}{
+		{
+			name: "DOCX",
+			entries: [][2]string{
+				{"[Content_Types].xml", `<Types><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`},
+				{"word/document.xml", `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>Perjan</w:t></w:r><w:r><w:t>jian</w:t></w:r><w:r><w:tab/><w:t>kerja</w:t></w:r></w:p><w:p><w:r><w:t xml:space="preserve">sama </w:t></w:r></w:p></w:body></w:document>`},
+				{"word/footer1.xml", `<w:ftr xmlns:w="w"><w:p><w:r><w:t>Halaman kaki</w:t></w:r></w:p></w:ftr>`},
+				{"docProps/core.xml", `<cp:coreProperties><dc:creator>Penulis rahasia</dc:creator></cp:coreProperties>`},
+			},
+			expected: "Perjanjian kerja sama Halaman kaki",
+		},
+		{
+			name: "XLSX",
+			entries: [][2]string{
+				{"[Content_Types].xml", `<Types><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/></Types>`},
+				{"xl/sharedStrings.xml", `<sst><si><t>Anggaran</t></si><si><r><t>Real</t></r><r><t>isasi</t></r></si></sst>`},
+				{"xl/worksheets/sheet1.xml", `<worksheet><sheetData><row><c t="s"><v>0</v></c><c t="inlineStr"><is><t>Catatan</t></is></c><c><v>12345</v></c></row></sheetData></worksheet>`},
+			},
+			expected: "Anggaran Realisasi Catatan",
+		},
+		{
+			name: "PPTX",
+			entries: [][2]string{
+				{"[Content_Types].xml", `<Types><Override PartName="/ppt/presentation.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.presentation.main+xml"/></Types>`},
+				{"ppt/slides/slide1.xml", `<p:sld><a:p><a:r><a:t>Rencana</a:t></a:r></a:p><a:p><a:r><a:t>strategis</a:t></a:r></a:p></p:sld>`},
+			},
+			expected: "Rencana strategis",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, extractBytes(t, ooxmlFile(t, tc.entries...), 1024))
+		})
+	}
+}
+
+func TestExtractOOXML_Malformed(t *testing.T) {
+	content := []byte("PK\x03\x04bukan arsip")
+	assert.Error(t, extractOOXML(&textWriter{limit: 1024}, bytes.NewReader(content), int64(len(content))))
+}
+
+func TestExtractOOXML_PartNameCase(t *testing.T) {
+	content := ooxmlFile(t, [2]string{"Word/Document.xml", `<w:document><w:p><w:r><w:t>Nota dinas</w:t></w:r></w:p></w:document>`})
+	w := &textWriter{limit: 1024}
+	require.NoError(t, extractOOXML(w, bytes.NewReader(content), int64(len(content))))
+	assert.Equal(t, "Nota dinas", w.String())
+}
@@ -0,0 +1,172 @@
+package fulltext
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+)
+
+// extractPDF menulis teks dari operator penampil teks (Tj, TJ, ' dan ") di
+// semua stream PDF yang dibaca dengan pdf.Lexer. Stream berformat zlib
+// (FlateDecode) didekompresi selama budget masih ada; stream lain dipindai
+// apa adanya. Teks dari font CID tanpa pengodean Unicode (glyph ID) tidak
+// dapat dibaca dan dilewati. PDF yang terpotong diperlakukan sebagai akhir
+// dokumen.
+func extractPDF(w *textWriter, content io.ReaderAt, size int64) error {
+	lexer := pdf.NewLexer(io.NewSectionReader(content, 0, size))
+	budget := int64(maxInflateBytes)
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF || errors.Is(err, pdf.ErrMalformed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if token.Kind != pdf.TokenStream || budget <= 0 {
+			continue
+		}
+		stream, _ := pdf.Inflate(lexer.StreamData())
+		limited := &io.LimitedReader{R: stream, N: budget}
+		// Data yang rusak di tengah stream tetap dipindai sampai titik itu.
+		data, _ := io.ReadAll(limited)
+		budget = limited.N
+		if err := extractContentText(w, data); err != nil {
+			return err
+		}
+	}
+}
+
+// pdfTextItem adalah operand yang relevan untuk TJ: string atau penyesuaian
+// jarak dalam seperseribu ukuran font.
+type pdfTextItem struct {
+	text     []byte
+	isString bool
+	adjust   float64
+}
+
+// tjWordGap adalah penyesuaian TJ (ke kanan, bernilai negatif) yang cukup
+// lebar untuk dianggap spasi antar kata.
+const tjWordGap = -200
+
+// extractContentText memindai operator content stream. Hanya string yang
+// langsung menjadi operand operator penampil teks yang ditulis ke w.
+func extractContentText(w *textWriter, data []byte) error {
+	var (
+		last    []byte
+		hasLast bool
+		array   []pdfTextItem
+		inArray bool
+		tj      []pdfTextItem
+		hasTJ   bool
+	)
+	lexer := pdf.NewLexer(bytes.NewReader(data))
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF || errors.Is(err, pdf.ErrMalformed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch token.Kind {
+		case pdf.TokenString:
+			if inArray {
+				array = append(array, pdfTextItem{text: token.Value, isString: true})
+			} else {
+				last, hasLast = token.Value, true
+			}
+		case pdf.TokenDelimiter:
+			switch token.Text {
+			case "[":
+				inArray, array = true, nil
+			case "]":
+				if inArray {
+					tj, hasTJ = array, true
+				}
+				inArray = false
+			}
+		}
+		if token.Kind != pdf.TokenKeyword {
+			continue
+		}
+
+		if number, err := strconv.ParseFloat(token.Text, 64); err == nil {
+			if inArray {
+				array = append(array, pdfTextItem{adjust: number})
+			}
+			continue
+		}
+		switch token.Text {
+		case "Tj", "'", "\"":
+			if hasLast {
+				if err := w.WriteString(decodePDFString(last)); err != nil {
+					return err
+				}
+			}
+			if token.Text != "Tj" {
+				w.Break()
+			}
+		case "TJ":
+			if hasTJ {
+				if err := writeTJ(w, tj); err != nil {
+					return err
+				}
+			}
+		case "Td", "TD", "T*", "ET":
+			w.Break()
+		case "ID":
+			if err := lexer.SkipInlineImage(); err != nil {
+				return err
+			}
+		}
+		last, hasLast, tj, hasTJ = nil, false, nil, false
+	}
+}
+
+func writeTJ(w *textWriter, items []pdfTextItem) error {
+	for _, item := range items {
+		if !item.isString {
+			if item.adjust <= tjWordGap {
+				w.Break()
+			}
+			continue
+		}
+		if err := w.WriteString(decodePDFString(item.text)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodePDFString mengubah string teks PDF menjadi UTF-8. String dengan BOM
+// UTF-16BE didekode sebagai UTF-16; selain itu tiap byte dianggap Latin-1
+// (mendekati WinAnsiEncoding). String yang sebagian besar berisi byte kontrol
+// adalah glyph ID font CID dan dibuang.
+func decodePDFString(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	control := 0
+	for _, b := range s {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' {
+			control++
+		}
+	}
+	if control*2 > len(s) {
+		return ""
+	}
+	runes := make([]rune, len(s))
+	for i, b := range s {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,57 @@
+package fulltext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pdfWithContent menyusun PDF satu halaman dengan content stream yang
+// dikompresi jika compress bernilai true.
+func pdfWithContent(t *testing.T, content string, compress bool) []byte {
+	t.Helper()
+	data, filter := []byte(content), ""
+	if compress {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		_, err := zw.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		data, filter = buf.Bytes(), " /Filter /FlateDecode"
+	}
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.7\n")
+	pdf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	pdf.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	pdf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Title (Judul metadata) >>\nendobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj\n<< /Length %d%s >>\nstream\n", len(data), filter)
+	pdf.Write(data)
+	pdf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n9\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func TestExtractPDF(t *testing.T) {
+	content := "BT /F1 12 Tf 72 712 Td (Laporan Anggaran) Tj 0 -14 Td " +
+		"[(Tahun)-250(2025)] TJ T* (kurung \\(dalam\\) dan \\351) Tj " +
+		"<FEFF0052006100680061007300690061> Tj " +
+		"<0001000200030004> Tj ET " +
+		"q BI /W 1 /H 1 /BPC 8 /CS /G ID \x00(Tersembunyi)Tj EI Q"
+
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compressed %t", compress), func(t *testing.T) {
+			text := extractBytes(t, pdfWithContent(t, content, compress), 1024)
+			assert.Equal(t, "Laporan Anggaran Tahun 2025 kurung (dalam) dan éRahasia", text)
+			assert.NotContains(t, text, "Judul metadata", "String di luar content stream tidak diindeks")
+			assert.NotContains(t, text, "Tersembunyi", "Data gambar inline dilewati")
+		})
+	}
+}
+
+func TestExtractPDF_Limit(t *testing.T) {
+	text := extractBytes(t, pdfWithContent(t, "BT (satu dua tiga empat) Tj ET", true), 8)
+	assert.Equal(t, "satu dua", text)
+}
@@ -0,0 +1,148 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type claimsContextKey struct{}
+
+// healthServicePrefix adalah awalan metode grpc.health.v1.Health. Probe
+// health dari orkestrator tidak membawa token, sehingga tidak diautentikasi.
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// ClaimsFromContext mengambil klaim JWT yang dipasang oleh interceptor auth.
+func ClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(jwt.MapClaims)
+	return claims, ok
+}
+
+// Authenticator memvalidasi token JWT dari metadata "authorization" dengan
+// aturan yang sama seperti auth.JWTMiddleware: HMAC dengan JWT_SECRET_KEY,
+// klaim jti wajib dan tidak ada di denylist Redis, serta klaim sub wajib.
+type Authenticator struct {
+	redisClient *redis.Client
+}
+
+func NewAuthenticator(redisClient *redis.Client) *Authenticator {
+	return &Authenticator{redisClient: redisClient}
+}
+
+// UnaryInterceptor mengautentikasi setiap RPC unary kecuali health check.
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor mengautentikasi setiap RPC streaming kecuali health
+// check (Health/Watch).
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "Authorization metadata required")
+	}
+	tokenString := strings.TrimPrefix(values[0], "Bearer ")
+	if tokenString == values[0] {
+		return nil, status.Error(codes.Unauthenticated, "Invalid authorization format, must be Bearer token")
+	}
+
+	secretKey := os.Getenv("JWT_SECRET_KEY")
+	if secretKey == "" {
+		return nil, status.Error(codes.Internal, "JWT secret key not configured")
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Invalid token: %v", err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, status.Error(codes.Unauthenticated, "Invalid token or claims")
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Token missing JTI claim")
+	}
+	if _, err := a.redisClient.Get(ctx, jti).Result(); !errors.Is(err, redis.Nil) {
+		if err != nil {
+			log.Error().Err(err).Msg("Gagal memeriksa denylist token di Redis")
+			return nil, status.Error(codes.Internal, "Could not verify token with store")
+		}
+		return nil, status.Error(codes.Unauthenticated, "Token has been revoked")
+	}
+	if _, ok := claims["sub"].(string); !ok {
+		return nil, status.Error(codes.Unauthenticated, "User ID (sub) not found in token claims")
+	}
+
+	ctx = context.WithValue(ctx, claimsContextKey{}, claims)
+	return policy.WithRequest(ctx, policy.Request{IP: peerIP(ctx), Time: time.Now()}), nil
+}
+
+// isPublicMethod melaporkan apakah metode gRPC boleh dipanggil tanpa token.
+func isPublicMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, healthServicePrefix)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
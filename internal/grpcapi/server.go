@@ -0,0 +1,274 @@
+// Package grpcapi menyediakan API gRPC prism.file.v1.FileService yang memakai
+// implementasi service.FileService yang sama dengan endpoint REST.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	filev1 "github.com/Lumina-Enterprise-Solutions/prism-file-service/gen/go/prism/file/v1"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/integrity"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	grpcmetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// downloadChunkSize adalah ukuran maksimum isi file per pesan DownloadFile.
+	downloadChunkSize = 64 * 1024
+	// maxListPageSize adalah batas jumlah file per ListFiles.
+	maxListPageSize = 1000
+)
+
+type Server struct {
+	filev1.UnimplementedFileServiceServer
+	fileService service.FileService
+}
+
+func NewServer(fs service.FileService) *Server {
+	return &Server{fileService: fs}
+}
+
+func (s *Server) UploadFile(stream filev1.FileService_UploadFileServer) error {
+	ctx := stream.Context()
+	claims, err := claimsFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.InvalidArgument, "stream upload kosong")
+		}
+		return err
+	}
+	info := first.GetInfo()
+	if info == nil {
+		return status.Error(codes.InvalidArgument, "pesan pertama harus berisi info file")
+	}
+	if info.GetFileName() == "" {
+		return status.Error(codes.InvalidArgument, "file_name wajib diisi")
+	}
+
+	userID, _ := claims["sub"].(string)
+	role, _ := claims["role"].(string)
+	ctx = uploadpolicy.WithRequest(ctx, uploadpolicy.Request{Role: role})
+	metadata, err := s.fileService.UploadStream(ctx, userID, info.GetFileName(), &uploadReader{stream: stream}, info.GetTags())
+	if err != nil {
+		return toStatus(err, "Gagal mengunggah file via gRPC")
+	}
+	return stream.SendAndClose(&filev1.UploadFileResponse{File: toProto(metadata)})
+}
+
+func (s *Server) DownloadFile(req *filev1.DownloadFileRequest, stream filev1.FileService_DownloadFileServer) error {
+	ctx := stream.Context()
+	claims, err := claimsFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := s.fileService.GetFileMetadata(ctx, req.GetId(), claims)
+	if err != nil {
+		return toStatus(err, "Gagal mengambil metadata file via gRPC")
+	}
+
+	offset, length := req.GetOffset(), req.GetLength()
+	if offset < 0 || length < 0 || offset > metadata.SizeBytes {
+		return status.Errorf(codes.OutOfRange, "rentang %d+%d di luar ukuran file %d", offset, length, metadata.SizeBytes)
+	}
+	if length == 0 || offset+length > metadata.SizeBytes {
+		length = metadata.SizeBytes - offset
+	}
+
+	// Checksum hanya dapat diverifikasi jika seluruh file dibaca.
+	var reader io.ReadCloser
+	if offset == 0 && length == metadata.SizeBytes {
+		reader, err = s.fileService.OpenFile(ctx, metadata)
+	} else {
+		reader, err = s.fileService.GetFileReader(ctx, metadata)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		log.Error().Err(err).Str("file_id", metadata.ID).Str("storage_path", metadata.StoragePath).Msg("File ada di metadata tapi tidak ditemukan di storage")
+		return status.Error(codes.NotFound, "file tidak ditemukan di penyimpanan")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("file_id", metadata.ID).Str("storage_path", metadata.StoragePath).Msg("Gagal membuka file dari storage")
+		return status.Error(codes.Internal, "gagal membaca file dari penyimpanan")
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Warn().Err(err).Str("file_id", metadata.ID).Msg("Gagal menutup file reader setelah download")
+		}
+	}()
+
+	if err := storage.Skip(reader, offset); err != nil {
+		log.Error().Err(err).Str("file_id", metadata.ID).Msg("Gagal melompati awal rentang download")
+		return status.Error(codes.Internal, "gagal membaca file")
+	}
+
+	if metadata.ChecksumSHA256 != "" {
+		if err := stream.SetHeader(grpcmetadata.Pairs("digest", integrity.DigestHeader(metadata.ChecksumSHA256))); err != nil {
+			return err
+		}
+	}
+	if err := stream.Send(&filev1.DownloadFileResponse{Data: &filev1.DownloadFileResponse_Info{Info: &filev1.DownloadFileInfo{
+		File:   toProto(metadata),
+		Offset: offset,
+		Length: length,
+	}}}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, downloadChunkSize)
+	remaining := io.LimitReader(reader, length)
+	for {
+		n, err := remaining.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&filev1.DownloadFileResponse{Data: &filev1.DownloadFileResponse_Chunk{Chunk: buf[:n]}}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if errors.Is(err, integrity.ErrChecksumMismatch) {
+			log.Error().Err(err).Str("file_id", metadata.ID).Str("storage_path", metadata.StoragePath).Msg("Konten file rusak, unduhan gRPC dibatalkan")
+			return status.Error(codes.DataLoss, "checksum konten file tidak cocok")
+		}
+		if err != nil {
+			log.Error().Err(err).Str("file_id", metadata.ID).Msg("Gagal mengirim file ke klien gRPC")
+			return status.Error(codes.Internal, "gagal membaca file")
+		}
+	}
+}
+
+func (s *Server) GetFileMetadata(ctx context.Context, req *filev1.GetFileMetadataRequest) (*filev1.GetFileMetadataResponse, error) {
+	claims, err := claimsFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := s.fileService.GetFileMetadata(ctx, req.GetId(), claims)
+	if err != nil {
+		return nil, toStatus(err, "Gagal mengambil metadata file via gRPC")
+	}
+	return &filev1.GetFileMetadataResponse{File: toProto(metadata)}, nil
+}
+
+func (s *Server) ListFiles(ctx context.Context, req *filev1.ListFilesRequest) (*filev1.ListFilesResponse, error) {
+	claims, err := claimsFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.GetPageSize())
+	if limit <= 0 || limit > maxListPageSize {
+		limit = maxListPageSize
+	}
+	files, err := s.fileService.ListFiles(ctx, repository.FileFilter{IDs: req.GetIds(), Tags: req.GetTags(), Limit: limit}, claims)
+	if err != nil {
+		return nil, toStatus(err, "Gagal mengambil daftar file via gRPC")
+	}
+	resp := &filev1.ListFilesResponse{Files: make([]*filev1.FileMetadata, 0, len(files))}
+	for _, metadata := range files {
+		resp.Files = append(resp.Files, toProto(metadata))
+	}
+	return resp, nil
+}
+
+func (s *Server) DeleteFile(ctx context.Context, req *filev1.DeleteFileRequest) (*filev1.DeleteFileResponse, error) {
+	claims, err := claimsFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fileService.DeleteFile(ctx, req.GetId(), claims); err != nil {
+		return nil, toStatus(err, "Gagal menghapus file via gRPC")
+	}
+	return &filev1.DeleteFileResponse{}, nil
+}
+
+func claimsFrom(ctx context.Context) (jwt.MapClaims, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "claims tidak ditemukan")
+	}
+	return claims, nil
+}
+
+// toStatus memetakan error service ke kode gRPC yang setara dengan status HTTP
+// pada handler REST.
+func toStatus(err error, logMsg string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return status.Error(codes.NotFound, "file tidak ditemukan")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case isValidationError(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		log.Error().Err(err).Msg(logMsg)
+		return status.Error(codes.Internal, "terjadi kesalahan internal")
+	}
+}
+
+// isValidationError menandai error yang disebabkan oleh input klien, sama
+// dengan pemeriksaan pada handler REST.
+func isValidationError(err error) bool {
+	if _, ok := validation.AsError(err); ok {
+		return true
+	}
+	return errors.Is(err, service.ErrInvalidMetadata)
+}
+
+func toProto(metadata *model.FileMetadata) *filev1.FileMetadata {
+	owner := ""
+	if metadata.OwnerUserID != nil {
+		owner = *metadata.OwnerUserID
+	}
+	return &filev1.FileMetadata{
+		Id:           metadata.ID,
+		OriginalName: metadata.OriginalName,
+		MimeType:     metadata.MimeType,
+		SizeBytes:    metadata.SizeBytes,
+		OwnerUserId:  owner,
+		Tags:         metadata.Tags,
+		CreatedAt:    timestamppb.New(metadata.CreatedAt),
+		Version:      metadata.Version,
+	}
+}
+
+// uploadReader menyajikan potongan isi file dari stream UploadFile sebagai io.Reader.
+type uploadReader struct {
+	stream filev1.FileService_UploadFileServer
+	buf    []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if msg.GetInfo() != nil {
+			return 0, status.Error(codes.InvalidArgument, "info file hanya boleh dikirim pada pesan pertama")
+		}
+		r.buf = msg.GetChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
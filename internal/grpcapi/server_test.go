@@ -0,0 +1,457 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	filev1 "github.com/Lumina-Enterprise-Solutions/prism-file-service/gen/go/prism/file/v1"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/integrity"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "test-secret"
+
+// MockFileService hanya mengimplementasikan metode yang dipakai server gRPC;
+// metode lain memanggil interface nil dan akan panic jika tersentuh.
+type MockFileService struct {
+	service.FileService
+	mock.Mock
+}
+
+func (m *MockFileService) UploadStream(ctx context.Context, ownerID, filename string, content io.Reader, tags []string) (*model.FileMetadata, error) {
+	args := m.Called(ctx, ownerID, filename, content, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileMetadata), args.Error(1)
+}
+
+func (m *MockFileService) GetFileMetadata(ctx context.Context, fileID string, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	args := m.Called(ctx, fileID, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileMetadata), args.Error(1)
+}
+
+func (m *MockFileService) GetFileReader(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error) {
+	args := m.Called(ctx, metadata)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileService) OpenFile(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error) {
+	args := m.Called(ctx, metadata)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileService) SignedDownloadURL(ctx context.Context, metadata *model.FileMetadata) (string, time.Time, error) {
+	args := m.Called(ctx, metadata)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockFileService) ListFiles(ctx context.Context, filter repository.FileFilter, claims jwt.MapClaims) ([]*model.FileMetadata, error) {
+	args := m.Called(ctx, filter, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.FileMetadata), args.Error(1)
+}
+
+func (m *MockFileService) DeleteFile(ctx context.Context, fileID string, claims jwt.MapClaims) error {
+	args := m.Called(ctx, fileID, claims)
+	return args.Error(0)
+}
+
+type testEnv struct {
+	client filev1.FileServiceClient
+	health healthpb.HealthClient
+	svc    *MockFileService
+	redis  *miniredis.Miniredis
+}
+
+// newTestEnv menjalankan server gRPC lengkap dengan interceptor auth di atas
+// bufconn sehingga tes tidak membuka port jaringan.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	svc := new(MockFileService)
+	authenticator := NewAuthenticator(redisClient)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authenticator.UnaryInterceptor()),
+		grpc.StreamInterceptor(authenticator.StreamInterceptor()),
+	)
+	filev1.RegisterFileServiceServer(server, NewServer(svc))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testEnv{client: filev1.NewFileServiceClient(conn), health: healthpb.NewHealthClient(conn), svc: svc, redis: mr}
+}
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func userClaims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "user-1", "role": "user", "jti": "jti-1", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func claimsMatcher(sub string) interface{} {
+	return mock.MatchedBy(func(claims jwt.MapClaims) bool { return claims["sub"] == sub })
+}
+
+func TestAuthentication(t *testing.T) {
+	env := newTestEnv(t)
+	validToken := signToken(t, userClaims())
+
+	otherSecret, err := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims()).SignedString([]byte("other"))
+	require.NoError(t, err)
+	withoutJTI := userClaims()
+	delete(withoutJTI, "jti")
+
+	testCases := []struct {
+		name  string
+		ctx   context.Context
+		setup func()
+	}{
+		{name: "Missing token", ctx: context.Background()},
+		{name: "Not a bearer token", ctx: metadata.AppendToOutgoingContext(context.Background(), "authorization", validToken)},
+		{name: "Wrong signature", ctx: withToken(otherSecret)},
+		{name: "Missing JTI", ctx: withToken(signToken(t, withoutJTI))},
+		{name: "Revoked token", ctx: withToken(validToken), setup: func() { require.NoError(t, env.redis.Set("jti-1", "revoked")) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.setup != nil {
+				tc.setup()
+				defer env.redis.FlushAll()
+			}
+			_, err := env.client.GetFileMetadata(tc.ctx, &filev1.GetFileMetadataRequest{Id: "file-1"})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+			stream, err := env.client.DownloadFile(tc.ctx, &filev1.DownloadFileRequest{Id: "file-1"})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+	env.svc.AssertNotCalled(t, "GetFileMetadata", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHealthCheckWithoutToken(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, err := env.health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	watch, err := env.health.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	update, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, update.Status)
+
+	_, err = env.client.GetFileMetadata(context.Background(), &filev1.GetFileMetadataRequest{Id: "file-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "Metode lain tetap memerlukan token")
+}
+
+func TestUploadFile(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := withToken(signToken(t, userClaims()))
+	content := bytes.Repeat([]byte("prism"), 30000)
+
+	var received []byte
+	env.svc.On("UploadStream", mock.Anything, "user-1", "laporan.pdf", mock.Anything, []string{"keuangan"}).
+		Run(func(args mock.Arguments) {
+			var err error
+			received, err = io.ReadAll(args.Get(3).(io.Reader))
+			require.NoError(t, err)
+		}).
+		Return(&model.FileMetadata{ID: "file-1", OriginalName: "laporan.pdf", SizeBytes: int64(len(content)), Version: 1}, nil).Once()
+
+	stream, err := env.client.UploadFile(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&filev1.UploadFileRequest{Data: &filev1.UploadFileRequest_Info{Info: &filev1.UploadFileInfo{FileName: "laporan.pdf", Tags: []string{"keuangan"}}}}))
+	for chunk := range chunks(content, 32*1024) {
+		require.NoError(t, stream.Send(&filev1.UploadFileRequest{Data: &filev1.UploadFileRequest_Chunk{Chunk: chunk}}))
+	}
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "file-1", resp.GetFile().GetId())
+	assert.Equal(t, content, received)
+	env.svc.AssertExpectations(t)
+}
+
+func TestUploadFile_Errors(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := withToken(signToken(t, userClaims()))
+
+	t.Run("First message must be info", func(t *testing.T) {
+		stream, err := env.client.UploadFile(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&filev1.UploadFileRequest{Data: &filev1.UploadFileRequest_Chunk{Chunk: []byte("x")}}))
+		_, err = stream.CloseAndRecv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Validation error maps to InvalidArgument", func(t *testing.T) {
+		env.svc.On("UploadStream", mock.Anything, "user-1", "virus.exe", mock.Anything, []string(nil)).
+			Return(nil, validation.Errorf(validation.CodeMimeTypeNotAllowed, "mime type 'application/x-msdownload' is not allowed")).Once()
+
+		stream, err := env.client.UploadFile(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&filev1.UploadFileRequest{Data: &filev1.UploadFileRequest_Info{Info: &filev1.UploadFileInfo{FileName: "virus.exe"}}}))
+		_, err = stream.CloseAndRecv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestDownloadFile(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := withToken(signToken(t, userClaims()))
+	content := strings.Repeat("0123456789", 20000)
+	file := &model.FileMetadata{ID: "file-1", StoragePath: "file-1.txt", SizeBytes: int64(len(content)), ChecksumSHA256: strings.Repeat("ab", 32)}
+
+	testCases := []struct {
+		name     string
+		offset   int64
+		length   int64
+		expected string
+		// whole berarti seluruh file dibaca sehingga checksum ikut diverifikasi.
+		whole bool
+	}{
+		{name: "Whole file", expected: content, whole: true},
+		{name: "Range in the middle", offset: 5, length: 10, expected: content[5:15]},
+		{name: "Range past the end is truncated", offset: int64(len(content)) - 3, length: 100, expected: content[len(content)-3:]},
+		{name: "Offset at the end returns nothing", offset: int64(len(content)), expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env.svc.On("GetFileMetadata", mock.Anything, "file-1", claimsMatcher("user-1")).Return(file, nil).Once()
+			if tc.whole {
+				env.svc.On("OpenFile", mock.Anything, file).Return(io.NopCloser(strings.NewReader(content)), nil).Once()
+			} else {
+				env.svc.On("GetFileReader", mock.Anything, file).Return(io.NopCloser(strings.NewReader(content)), nil).Once()
+			}
+
+			stream, err := env.client.DownloadFile(ctx, &filev1.DownloadFileRequest{Id: "file-1", Offset: tc.offset, Length: tc.length})
+			require.NoError(t, err)
+			header, err := stream.Header()
+			require.NoError(t, err)
+			assert.Equal(t, []string{integrity.DigestHeader(file.ChecksumSHA256)}, header.Get("digest"))
+
+			first, err := stream.Recv()
+			require.NoError(t, err)
+			require.NotNil(t, first.GetInfo())
+			assert.Equal(t, tc.offset, first.GetInfo().GetOffset())
+			assert.Equal(t, int64(len(tc.expected)), first.GetInfo().GetLength())
+
+			var got bytes.Buffer
+			for {
+				msg, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				assert.LessOrEqual(t, len(msg.GetChunk()), downloadChunkSize)
+				got.Write(msg.GetChunk())
+			}
+			assert.Equal(t, tc.expected, got.String())
+		})
+	}
+	env.svc.AssertExpectations(t)
+}
+
+func TestDownloadFile_Errors(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := withToken(signToken(t, userClaims()))
+
+	testCases := []struct {
+		name     string
+		request  *filev1.DownloadFileRequest
+		setup    func()
+		expected codes.Code
+	}{
+		{
+			name:    "Access denied",
+			request: &filev1.DownloadFileRequest{Id: "file-1"},
+			setup: func() {
+				env.svc.On("GetFileMetadata", mock.Anything, "file-1", mock.Anything).Return(nil, service.ErrAccessDenied).Once()
+			},
+			expected: codes.PermissionDenied,
+		},
+		{
+			name:    "Not found",
+			request: &filev1.DownloadFileRequest{Id: "file-2"},
+			setup: func() {
+				env.svc.On("GetFileMetadata", mock.Anything, "file-2", mock.Anything).Return(nil, repository.ErrNotFound).Once()
+			},
+			expected: codes.NotFound,
+		},
+		{
+			name:    "Offset beyond file size",
+			request: &filev1.DownloadFileRequest{Id: "file-3", Offset: 11},
+			setup: func() {
+				env.svc.On("GetFileMetadata", mock.Anything, "file-3", mock.Anything).Return(&model.FileMetadata{ID: "file-3", SizeBytes: 10}, nil).Once()
+			},
+			expected: codes.OutOfRange,
+		},
+		{
+			name:    "Content missing from storage",
+			request: &filev1.DownloadFileRequest{Id: "file-4"},
+			setup: func() {
+				env.svc.On("GetFileMetadata", mock.Anything, "file-4", mock.Anything).Return(&model.FileMetadata{ID: "file-4", StoragePath: "file-4.txt", SizeBytes: 10}, nil).Once()
+				env.svc.On("OpenFile", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: file-4.txt", storage.ErrNotFound)).Once()
+			},
+			expected: codes.NotFound,
+		},
+		{
+			name:    "Checksum mismatch",
+			request: &filev1.DownloadFileRequest{Id: "file-5"},
+			setup: func() {
+				env.svc.On("GetFileMetadata", mock.Anything, "file-5", mock.Anything).Return(&model.FileMetadata{ID: "file-5", StoragePath: "file-5.txt", SizeBytes: 10}, nil).Once()
+				env.svc.On("OpenFile", mock.Anything, mock.Anything).Return(io.NopCloser(iotest.ErrReader(integrity.ErrChecksumMismatch)), nil).Once()
+			},
+			expected: codes.DataLoss,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setup()
+			stream, err := env.client.DownloadFile(ctx, tc.request)
+			require.NoError(t, err)
+			for err == nil {
+				_, err = stream.Recv()
+			}
+			assert.Equal(t, tc.expected, status.Code(err))
+		})
+	}
+	env.svc.AssertExpectations(t)
+}
+
+func TestGetFileMetadata(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := withToken(signToken(t, userClaims()))
+	owner := "user-1"
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env.svc.On("GetFileMetadata", mock.Anything, "file-1", claimsMatcher("user-1")).Return(&model.FileMetadata{
+		ID: "file-1", OriginalName: "a.pdf", MimeType: "application/pdf", SizeBytes: 42,
+		OwnerUserID: &owner, Tags: []string{"keuangan"}, CreatedAt: createdAt, Version: 3,
+	}, nil).Once()
+
+	resp, err := env.client.GetFileMetadata(ctx, &filev1.GetFileMetadataRequest{Id: "file-1"})
+	require.NoError(t, err)
+
+	file := resp.GetFile()
+	assert.Equal(t, "a.pdf", file.GetOriginalName())
+	assert.Equal(t, "user-1", file.GetOwnerUserId())
+	assert.Equal(t, []string{"keuangan"}, file.GetTags())
+	assert.Equal(t, createdAt, file.GetCreatedAt().AsTime())
+	assert.Equal(t, int64(3), file.GetVersion())
+}
+
+func TestListFiles(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := withToken(signToken(t, userClaims()))
+
+	env.svc.On("ListFiles", mock.Anything, repository.FileFilter{Tags: []string{"keuangan"}, Limit: maxListPageSize}, claimsMatcher("user-1")).
+		Return([]*model.FileMetadata{{ID: "file-1"}, {ID: "file-2"}}, nil).Once()
+	env.svc.On("ListFiles", mock.Anything, repository.FileFilter{IDs: []string{"file-1"}, Limit: 5}, claimsMatcher("user-1")).
+		Return([]*model.FileMetadata{{ID: "file-1"}}, nil).Once()
+
+	resp, err := env.client.ListFiles(ctx, &filev1.ListFilesRequest{Tags: []string{"keuangan"}, PageSize: 5000})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetFiles(), 2)
+
+	resp, err = env.client.ListFiles(ctx, &filev1.ListFilesRequest{Ids: []string{"file-1"}, PageSize: 5})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetFiles(), 1)
+	env.svc.AssertExpectations(t)
+}
+
+func TestDeleteFile(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := withToken(signToken(t, userClaims()))
+
+	env.svc.On("DeleteFile", mock.Anything, "file-1", claimsMatcher("user-1")).Return(nil).Once()
+	env.svc.On("DeleteFile", mock.Anything, "file-2", claimsMatcher("user-1")).Return(service.ErrAccessDenied).Once()
+	env.svc.On("DeleteFile", mock.Anything, "file-3", claimsMatcher("user-1")).Return(errors.New("db down")).Once()
+
+	_, err := env.client.DeleteFile(ctx, &filev1.DeleteFileRequest{Id: "file-1"})
+	assert.NoError(t, err)
+	_, err = env.client.DeleteFile(ctx, &filev1.DeleteFileRequest{Id: "file-2"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = env.client.DeleteFile(ctx, &filev1.DeleteFileRequest{Id: "file-3"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, status.Convert(err).Message(), "db down", "detail error internal tidak boleh bocor ke klien")
+	env.svc.AssertExpectations(t)
+}
+
+func chunks(data []byte, size int) func(yield func([]byte) bool) {
+	return func(yield func([]byte) bool) {
+		for len(data) > 0 {
+			n := min(size, len(data))
+			if !yield(data[:n]) {
+				return
+			}
+			data = data[n:]
+		}
+	}
+}
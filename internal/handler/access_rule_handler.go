@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	commonjwt "github.com/Lumina-Enterprise-Solutions/prism-common-libs/auth"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type AccessRuleHandler struct {
+	ruleService service.AccessRuleService
+}
+
+func NewAccessRuleHandler(rs service.AccessRuleService) *AccessRuleHandler {
+	return &AccessRuleHandler{ruleService: rs}
+}
+
+// RequireRole hanya meneruskan permintaan jika klaim "role" pada JWT sama
+// dengan role. Harus dipasang setelah auth.JWTMiddleware.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := claimsFromContext(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if userRole, _ := claims["role"].(string); userRole != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": "endpoint ini memerlukan peran " + role})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (h *AccessRuleHandler) ListRules(c *gin.Context) {
+	rules, err := h.ruleService.ListRules(c.Request.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Gagal memuat aturan akses")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memuat aturan akses"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+func (h *AccessRuleHandler) CreateRule(c *gin.Context) {
+	var rule model.AccessRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body permintaan tidak valid", "details": err.Error()})
+		return
+	}
+	actorID, _ := commonjwt.GetUserID(c)
+
+	if err := h.ruleService.CreateRule(c.Request.Context(), rule, actorID); err != nil {
+		respondAccessRuleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteRule menerima aturan lewat query (?tag=...&role=...) karena pola tag
+// dapat mengandung "/".
+func (h *AccessRuleHandler) DeleteRule(c *gin.Context) {
+	rule := model.AccessRule{TagName: c.Query("tag"), RoleName: c.Query("role")}
+	actorID, _ := commonjwt.GetUserID(c)
+
+	if err := h.ruleService.DeleteRule(c.Request.Context(), rule, actorID); err != nil {
+		respondAccessRuleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ExplainAccess menjelaskan siapa yang dapat mengakses file; tambahkan
+// ?role=<peran> untuk mengevaluasi satu peran tertentu.
+func (h *AccessRuleHandler) ExplainAccess(c *gin.Context) {
+	explanation, err := h.ruleService.ExplainAccess(c.Request.Context(), c.Param("id"), c.Query("role"))
+	if err != nil {
+		respondAccessRuleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, explanation)
+}
+
+func respondAccessRuleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAccessRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+	case errors.Is(err, repository.ErrRuleExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Aturan akses sudah ada"})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data tidak ditemukan"})
+	default:
+		log.Error().Err(err).Msg("Gagal memproses aturan akses")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses aturan akses"})
+	}
+}
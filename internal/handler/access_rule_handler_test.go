@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccessRuleService struct {
+	mock.Mock
+}
+
+func (m *MockAccessRuleService) ListRules(ctx context.Context) ([]model.AccessRule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AccessRule), args.Error(1)
+}
+func (m *MockAccessRuleService) CreateRule(ctx context.Context, rule model.AccessRule, actorID string) error {
+	return m.Called(ctx, rule, actorID).Error(0)
+}
+func (m *MockAccessRuleService) DeleteRule(ctx context.Context, rule model.AccessRule, actorID string) error {
+	return m.Called(ctx, rule, actorID).Error(0)
+}
+func (m *MockAccessRuleService) ExplainAccess(ctx context.Context, fileID string, role string) (*service.AccessExplanation, error) {
+	args := m.Called(ctx, fileID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.AccessExplanation), args.Error(1)
+}
+
+func TestAccessRuleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authAs := func(role string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("user_id", "user-"+role)
+			c.Set("claims", jwt.MapClaims{"sub": "user-" + role, "role": role})
+			c.Next()
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		role               string
+		method             string
+		path               string
+		body               string
+		setupMock          func(mockService *MockAccessRuleService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "Failure - Non-admin is rejected",
+			role:               "finance",
+			method:             http.MethodGet,
+			path:               "/admin/access-rules",
+			setupMock:          func(mockService *MockAccessRuleService) {},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:   "Success - Admin lists rules",
+			role:   "admin",
+			method: http.MethodGet,
+			path:   "/admin/access-rules",
+			setupMock: func(mockService *MockAccessRuleService) {
+				mockService.On("ListRules", mock.Anything).Return([]model.AccessRule{{TagName: "keuangan/*", RoleName: "finance"}}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"tag_name":"keuangan/*"`,
+		},
+		{
+			name:   "Failure - Duplicate rule",
+			role:   "admin",
+			method: http.MethodPost,
+			path:   "/admin/access-rules",
+			body:   `{"tag_name":"hr","role_name":"hr"}`,
+			setupMock: func(mockService *MockAccessRuleService) {
+				mockService.On("CreateRule", mock.Anything, model.AccessRule{TagName: "hr", RoleName: "hr"}, "user-admin").Return(repository.ErrRuleExists).Once()
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name:   "Success - Delete rule by query",
+			role:   "admin",
+			method: http.MethodDelete,
+			path:   "/admin/access-rules?tag=keuangan/*&role=finance",
+			setupMock: func(mockService *MockAccessRuleService) {
+				mockService.On("DeleteRule", mock.Anything, model.AccessRule{TagName: "keuangan/*", RoleName: "finance"}, "user-admin").Return(nil).Once()
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:   "Success - Explain for a role",
+			role:   "admin",
+			method: http.MethodGet,
+			path:   "/admin/access-rules/explain/file-1?role=finance",
+			setupMock: func(mockService *MockAccessRuleService) {
+				allowed := true
+				mockService.On("ExplainAccess", mock.Anything, "file-1", "finance").Return(&service.AccessExplanation{FileID: "file-1", Role: "finance", Allowed: &allowed}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"allowed":true`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockAccessRuleService)
+			tc.setupMock(mockService)
+			h := NewAccessRuleHandler(mockService)
+
+			router := gin.New()
+			admin := router.Group("/admin", authAs(tc.role), RequireRole("admin"))
+			admin.GET("/access-rules", h.ListRules)
+			admin.POST("/access-rules", h.CreateRule)
+			admin.DELETE("/access-rules", h.DeleteRule)
+			admin.GET("/access-rules/explain/:id", h.ExplainAccess)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
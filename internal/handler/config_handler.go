@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigStatus adalah sumber konfigurasi aktif, dipenuhi oleh
+// *fileserviceconfig.Watcher.
+type ConfigStatus interface {
+	Snapshot() fileserviceconfig.Snapshot
+	LastRejection() *fileserviceconfig.Rejection
+}
+
+type ConfigHandler struct {
+	status ConfigStatus
+}
+
+func NewConfigHandler(status ConfigStatus) *ConfigHandler {
+	return &ConfigHandler{status: status}
+}
+
+type configResponse struct {
+	Version       uint64             `json:"version"`
+	ConsulIndex   uint64             `json:"consul_index"`
+	AppliedAt     time.Time          `json:"applied_at"`
+	Config        activeConfig       `json:"config"`
+	LastRejection *rejectionResponse `json:"last_rejection,omitempty"`
+}
+
+// activeConfig hanya memuat pengaturan yang aman ditampilkan; kredensial
+// storage dari Vault tidak pernah dikirim.
+type activeConfig struct {
+	MaxFileSizeBytes     int64    `json:"max_file_size_bytes"`
+	AllowedMimeTypes     []string `json:"allowed_mime_types"`
+	StorageBackend       string   `json:"storage_backend"`
+	ExtraChecksums       []string `json:"extra_checksums"`
+	VerifyChecksumOnRead bool     `json:"verify_checksum_on_read"`
+}
+
+type rejectionResponse struct {
+	ConsulIndex uint64    `json:"consul_index"`
+	At          time.Time `json:"at"`
+	Error       string    `json:"error"`
+}
+
+// GetConfig menampilkan versi dan ringkasan konfigurasi yang sedang berlaku,
+// beserta pembaruan terakhir yang ditolak.
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	snapshot := h.status.Snapshot()
+	cfg := snapshot.Config
+	mimeTypes := make([]string, 0, len(cfg.AllowedMimeTypesMap))
+	for mimeType, allowed := range cfg.AllowedMimeTypesMap {
+		if allowed {
+			mimeTypes = append(mimeTypes, mimeType)
+		}
+	}
+	sort.Strings(mimeTypes)
+
+	response := configResponse{
+		Version:     snapshot.Version,
+		ConsulIndex: snapshot.ConsulIndex,
+		AppliedAt:   snapshot.AppliedAt,
+		Config: activeConfig{
+			MaxFileSizeBytes:     cfg.MaxFileSizeBytes,
+			AllowedMimeTypes:     mimeTypes,
+			StorageBackend:       cfg.StorageBackend,
+			ExtraChecksums:       append([]string{}, cfg.ExtraChecksums...),
+			VerifyChecksumOnRead: cfg.VerifyChecksumOnRead,
+		},
+	}
+	if rejection := h.status.LastRejection(); rejection != nil {
+		response.LastRejection = &rejectionResponse{ConsulIndex: rejection.ConsulIndex, At: rejection.At, Error: rejection.Err.Error()}
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubConfigStatus struct {
+	snapshot  fileserviceconfig.Snapshot
+	rejection *fileserviceconfig.Rejection
+}
+
+func (s stubConfigStatus) Snapshot() fileserviceconfig.Snapshot {
+	return s.snapshot
+}
+
+func (s stubConfigStatus) LastRejection() *fileserviceconfig.Rejection {
+	return s.rejection
+}
+
+func TestConfigHandler_GetConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	appliedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024,
+		AllowedMimeTypesMap: map[string]bool{"image/png": true, "application/pdf": true},
+		StorageBackend:      "s3",
+		S3Config:            fileserviceconfig.S3Config{SecretKey: "rahasia"},
+	}
+
+	testCases := []struct {
+		name         string
+		status       stubConfigStatus
+		expectedBody string
+	}{
+		{
+			name:         "Initial configuration",
+			status:       stubConfigStatus{snapshot: fileserviceconfig.Snapshot{Config: cfg, Version: 1, AppliedAt: appliedAt}},
+			expectedBody: `{"version":1,"consul_index":0,"applied_at":"2025-06-01T12:00:00Z","config":{"max_file_size_bytes":1024,"allowed_mime_types":["application/pdf","image/png"],"storage_backend":"s3","extra_checksums":[],"verify_checksum_on_read":false}}`,
+		},
+		{
+			name: "Reloaded configuration with a rejected update",
+			status: stubConfigStatus{
+				snapshot:  fileserviceconfig.Snapshot{Config: cfg, Version: 3, ConsulIndex: 42, AppliedAt: appliedAt},
+				rejection: &fileserviceconfig.Rejection{ConsulIndex: 43, At: appliedAt.Add(time.Minute), Err: errors.New("max_size_mb harus lebih dari 0")},
+			},
+			expectedBody: `{"version":3,"consul_index":42,"applied_at":"2025-06-01T12:00:00Z","config":{"max_file_size_bytes":1024,"allowed_mime_types":["application/pdf","image/png"],"storage_backend":"s3","extra_checksums":[],"verify_checksum_on_read":false},"last_rejection":{"consul_index":43,"at":"2025-06-01T12:01:00Z","error":"max_size_mb harus lebih dari 0"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/admin/config", NewConfigHandler(tc.status).GetConfig)
+
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/admin/config", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+			assert.NotContains(t, recorder.Body.String(), "rahasia")
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/dav"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const davRealm = `Basic realm="Prism", charset="UTF-8"`
+
+// DAVAuth mengautentikasi klien WebDAV. Klien drive umumnya hanya mendukung
+// Basic auth, sehingga access key S3 (POST /files/s3-credentials) diterima
+// sebagai app password: username = access key ID, password = secret. Header
+// Bearer diteruskan ke jwtMiddleware.
+func DAVAuth(jwtMiddleware gin.HandlerFunc, credentials service.S3CredentialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessKeyID, secret, ok := c.Request.BasicAuth()
+		if !ok {
+			if c.GetHeader("Authorization") == "" {
+				c.Header("WWW-Authenticate", davRealm)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Autentikasi diperlukan"})
+				return
+			}
+			jwtMiddleware(c)
+			return
+		}
+
+		credential, err := credentials.Lookup(c.Request.Context(), accessKeyID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Error().Err(err).Str("access_key_id", accessKeyID).Msg("Gagal memverifikasi app password WebDAV")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Gagal memverifikasi kredensial"})
+			return
+		}
+		if err != nil || subtle.ConstantTimeCompare([]byte(secret), []byte(credential.SecretAccessKey)) != 1 {
+			c.Header("WWW-Authenticate", davRealm)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Kredensial tidak valid"})
+			return
+		}
+
+		c.Set("user_id", credential.UserID)
+		c.Set("claims", jwt.MapClaims{"sub": credential.UserID, "role": credential.Role})
+		c.Next()
+	}
+}
+
+// DAV meneruskan request ke handler WebDAV dengan klaim pemanggil di context.
+func DAV(h http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := claimsFromContext(c)
+		if !ok {
+			return
+		}
+		h.ServeHTTP(c.Writer, c.Request.WithContext(dav.WithClaims(c.Request.Context(), claims)))
+	}
+}
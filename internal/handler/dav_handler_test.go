@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/dav"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDAVAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	credential := &model.S3Credential{AccessKeyID: "PRSMTEST", SecretAccessKey: "secret", UserID: "user-1", Role: "finance"}
+
+	testCases := []struct {
+		name               string
+		setupRequest       func(req *http.Request)
+		setupMock          func(mockService *MockS3CredentialService)
+		expectedStatusCode int
+		expectedUser       string
+		expectChallenge    bool
+	}{
+		{
+			name:         "Success - App password",
+			setupRequest: func(req *http.Request) { req.SetBasicAuth("PRSMTEST", "secret") },
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("Lookup", mock.Anything, "PRSMTEST").Return(credential, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedUser:       "user-1",
+		},
+		{
+			name:         "Failure - Wrong secret",
+			setupRequest: func(req *http.Request) { req.SetBasicAuth("PRSMTEST", "salah") },
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("Lookup", mock.Anything, "PRSMTEST").Return(credential, nil).Once()
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectChallenge:    true,
+		},
+		{
+			name:         "Failure - Unknown access key",
+			setupRequest: func(req *http.Request) { req.SetBasicAuth("PRSMNONE", "secret") },
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("Lookup", mock.Anything, "PRSMNONE").Return(nil, repository.ErrNotFound).Once()
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectChallenge:    true,
+		},
+		{
+			name:               "Failure - No credentials",
+			setupRequest:       func(req *http.Request) {},
+			setupMock:          func(mockService *MockS3CredentialService) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectChallenge:    true,
+		},
+		{
+			name:               "Success - Bearer delegated to JWT middleware",
+			setupRequest:       func(req *http.Request) { req.Header.Set("Authorization", "Bearer token") },
+			setupMock:          func(mockService *MockS3CredentialService) {},
+			expectedStatusCode: http.StatusOK,
+			expectedUser:       "jwt-user",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockS3CredentialService)
+			tc.setupMock(mockService)
+
+			jwtMiddleware := func(c *gin.Context) {
+				c.Set("user_id", "jwt-user")
+				c.Set("claims", jwt.MapClaims{"sub": "jwt-user", "role": "user"})
+				c.Next()
+			}
+			router := gin.New()
+			router.GET("/files/dav/*path", DAVAuth(jwtMiddleware, mockService), func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString("user_id"))
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/files/dav/laporan.txt", nil)
+			tc.setupRequest(req)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			if tc.expectedUser != "" {
+				assert.Equal(t, tc.expectedUser, w.Body.String())
+			}
+			assert.Equal(t, tc.expectChallenge, w.Header().Get("WWW-Authenticate") != "")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDAVRoutes_CoexistWithFileRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	davHandler := DAV(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+	}))
+	setClaims := func(c *gin.Context) {
+		c.Set("claims", jwt.MapClaims{"sub": "user-1", "role": "user"})
+	}
+
+	router := gin.New()
+	fileRoutes := router.Group("/files")
+	davRoutes := fileRoutes.Group("/dav", setClaims)
+	for _, method := range dav.Methods {
+		davRoutes.Handle(method, "", davHandler)
+		davRoutes.Handle(method, "/*path", davHandler)
+	}
+	fileRoutes.GET("/:id", func(c *gin.Context) { c.String(http.StatusOK, c.Param("id")) })
+	fileRoutes.PUT("/:id/tags/:tag", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"PROPFIND", "/files/dav", http.StatusMultiStatus},
+		{"PROPFIND", "/files/dav/", http.StatusMultiStatus},
+		{"PROPFIND", "/files/dav/keuangan/pajak", http.StatusMultiStatus},
+		{http.MethodGet, "/files/abc-123", http.StatusOK},
+		{http.MethodPut, "/files/abc-123/tags/pajak", http.StatusNoContent},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code, "%s %s", tc.method, tc.path)
+	}
+}
@@ -2,14 +2,25 @@
 package handler
 
 import (
+	"context"
 	"errors" // BARU: Import errors
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	commonjwt "github.com/Lumina-Enterprise-Solutions/prism-common-libs/auth"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/integrity"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
@@ -23,6 +34,28 @@ func NewFileHandler(fs service.FileService) *FileHandler {
 	return &FileHandler{fileService: fs}
 }
 
+// NewRouter membuat router gin dengan logger dan recovery bawaan yang hanya
+// mempercaya X-Forwarded-For dan X-Real-IP dari trustedProxies (IP atau
+// CIDR). Tanpa proxy tepercaya, c.ClientIP() selalu alamat koneksi, sehingga
+// klien tidak dapat memalsukan IP yang dinilai kebijakan otorisasi.
+func NewRouter(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("daftar trusted proxy tidak valid: %w", err)
+	}
+	return router, nil
+}
+
+// PolicyRequestContext menyimpan IP klien dan waktu permintaan di context
+// request agar dapat dipakai oleh kebijakan otorisasi (variabel `request`).
+func PolicyRequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := policy.WithRequest(c.Request.Context(), policy.Request{IP: c.ClientIP(), Time: time.Now()})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
 func (h *FileHandler) UploadFile(c *gin.Context) {
 	userID, err := commonjwt.GetUserID(c)
 	if err != nil {
@@ -30,14 +63,7 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 		return
 	}
 
-	tagsValue := c.PostForm("tags")
-	var tags []string
-	if tagsValue != "" {
-		tags = strings.Split(tagsValue, ",")
-		for i := range tags {
-			tags[i] = strings.TrimSpace(tags[i])
-		}
-	}
+	tags := parseTags(c.PostForm("tags"))
 
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -45,10 +71,10 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 		return
 	}
 
-	metadata, err := h.fileService.UploadFile(c.Request.Context(), userID, file, tags)
+	metadata, err := h.fileService.UploadFile(uploadContext(c), userID, file, tags)
 	if err != nil {
-		if strings.Contains(err.Error(), "exceeds the limit") || strings.Contains(err.Error(), "is not allowed") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		if validationErr, ok := validation.AsError(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "code": validationErr.Code, "details": err.Error()})
 		} else {
 			log.Error().Err(err).Msg("Gagal memproses upload file")
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
@@ -59,17 +85,96 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 	c.JSON(http.StatusOK, metadata)
 }
 
-func (h *FileHandler) DownloadFile(c *gin.Context) {
-	fileID := c.Param("id")
+// UploadBatch menerima banyak file dalam satu permintaan multipart (field
+// "files"), atau satu arsip ZIP (field "archive") yang diekstrak di server.
+// Tag bersama diambil dari field "tags"; tag khusus per file dari field
+// "tags[<nama file>]". Field "purpose" berlaku untuk semua file. Respons berisi hasil per file: 200 jika semuanya
+// berhasil, 207 Multi-Status jika ada yang gagal.
+func (h *FileHandler) UploadBatch(c *gin.Context) {
+	userID, err := commonjwt.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: user ID not found in token"})
+		return
+	}
 
-	claimsVal, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Claims tidak ditemukan"})
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Permintaan multipart tidak valid", "details": err.Error()})
 		return
 	}
-	claims, ok := claimsVal.(jwt.MapClaims)
+	sharedTags := parseTags(c.PostForm("tags"))
+
+	var results []service.BatchUploadResult
+	if archives := form.File["archive"]; len(archives) > 0 {
+		if len(archives) > 1 || len(form.File["files"]) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kirim satu arsip ZIP atau beberapa file, bukan keduanya"})
+			return
+		}
+		results, err = h.fileService.UploadArchive(uploadContext(c), userID, archives[0], sharedTags)
+	} else {
+		items := make([]service.BatchUploadItem, 0, len(form.File["files"]))
+		for _, file := range form.File["files"] {
+			tags := append([]string(nil), sharedTags...)
+			tags = append(tags, parseTags(c.PostForm("tags["+file.Filename+"]"))...)
+			items = append(items, service.BatchUploadItem{File: file, Tags: tags})
+		}
+		results, err = h.fileService.UploadBatch(uploadContext(c), userID, items)
+	}
+
+	if err != nil && len(results) == 0 {
+		if errors.Is(err, service.ErrBatchEmpty) || errors.Is(err, service.ErrBatchTooManyFiles) || errors.Is(err, service.ErrUnsafeArchive) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		} else {
+			log.Error().Err(err).Msg("Gagal memproses upload batch")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process batch"})
+		}
+		return
+	}
+
+	failed := 0
+	for i := range results {
+		if !results[i].Success {
+			failed++
+			if !isValidationError(results[i].Err) {
+				log.Error().Err(results[i].Err).Str("file_name", results[i].FileName).Msg("Gagal memproses file dalam batch")
+				results[i].Error = "Failed to process file"
+			}
+		}
+	}
+
+	response := gin.H{
+		"total":     len(results),
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+		"results":   results,
+	}
+	if err != nil {
+		// Batch berhenti di tengah jalan; file yang sudah diproses tetap dilaporkan.
+		response["error"] = err.Error()
+	}
+
+	status := http.StatusOK
+	if failed > 0 || err != nil {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, response)
+}
+
+// ListUploadPolicies menampilkan kebijakan upload yang berlaku untuk peran
+// pemanggil agar klien dapat memvalidasi file sebelum mengunggah.
+func (h *FileHandler) ListUploadPolicies(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policies": h.fileService.UploadPolicies(claims)})
+}
+
+func (h *FileHandler) DownloadFile(c *gin.Context) {
+	fileID := c.Param("id")
+
+	claims, ok := claimsFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Format claims tidak valid"})
 		return
 	}
 
@@ -84,10 +189,28 @@ func (h *FileHandler) DownloadFile(c *gin.Context) {
 		return
 	}
 
-	fileReader, err := h.fileService.GetFileReader(c.Request.Context(), metadata.StoragePath)
-	if err != nil {
+	if redirect, _ := strconv.ParseBool(c.Query("redirect")); redirect {
+		// Klien boleh mengunduh langsung dari penyedia storage. Jika backend
+		// tidak dapat menandatangani URL, konten dialirkan seperti biasa.
+		url, _, err := h.fileService.SignedDownloadURL(c.Request.Context(), metadata)
+		if err == nil {
+			c.Redirect(http.StatusFound, url)
+			return
+		}
+		if !errors.Is(err, storage.ErrSigningUnsupported) {
+			log.Warn().Err(err).Str("file_id", fileID).Msg("Gagal membuat URL unduhan bertanda tangan, konten dialirkan langsung")
+		}
+	}
+
+	fileReader, err := h.fileService.OpenFile(c.Request.Context(), metadata)
+	if errors.Is(err, storage.ErrNotFound) {
 		log.Error().Err(err).Str("file_id", fileID).Str("storage_path", metadata.StoragePath).Msg("File ada di metadata tapi tidak ditemukan di storage")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "File tidak ditemukan di penyimpanan"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan di penyimpanan"})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("file_id", fileID).Str("storage_path", metadata.StoragePath).Msg("Gagal membuka file dari storage")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file dari penyimpanan"})
 		return
 	}
 	// FIX: Periksa error saat menutup fileReader.
@@ -97,12 +220,252 @@ func (h *FileHandler) DownloadFile(c *gin.Context) {
 		}
 	}()
 
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", metadata.OriginalName))
+	c.Header("Content-Disposition", attachmentDisposition(metadata.OriginalName))
 	c.Header("Content-Type", metadata.MimeType)
 	c.Header("Content-Length", fmt.Sprintf("%d", metadata.SizeBytes))
+	// ETag /files/:id selalu berisi versi, sama seperti GET /:id/metadata,
+	// agar dapat dikirim ulang lewat If-Match pada PATCH. Checksum konten
+	// hanya dikirim lewat Digest dan Content-MD5.
+	c.Header("ETag", versionETag(metadata.Version))
+	if metadata.ChecksumSHA256 != "" {
+		c.Header("Digest", integrity.DigestHeader(metadata.ChecksumSHA256))
+	}
+	if metadata.ChecksumMD5 != "" {
+		c.Header("Content-MD5", integrity.ContentMD5Header(metadata.ChecksumMD5))
+	}
 
+	// Jika checksum tidak cocok, potongan terakhir tidak pernah dikirim sehingga
+	// respons lebih pendek dari Content-Length dan klien melihat unduhan gagal.
 	_, err = io.Copy(c.Writer, fileReader)
-	if err != nil {
+	if errors.Is(err, integrity.ErrChecksumMismatch) {
+		log.Error().Err(err).Str("file_id", fileID).Str("storage_path", metadata.StoragePath).Msg("Konten file rusak, unduhan dibatalkan")
+	} else if err != nil {
 		log.Error().Err(err).Str("file_id", fileID).Msg("Gagal mengirim file ke klien")
 	}
 }
+
+// DownloadArchive mengemas beberapa file menjadi satu arsip ZIP. Dalam mode
+// sinkron arsip di-stream langsung ke respons; dengan "async": true arsip
+// dibangun oleh job build_archive dan respons 202 berisi ID file arsipnya.
+func (h *FileHandler) DownloadArchive(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req service.ArchiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body permintaan tidak valid", "details": err.Error()})
+		return
+	}
+
+	files, err := h.fileService.ResolveArchiveFiles(c.Request.Context(), req, claims)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": err.Error()})
+		case errors.Is(err, service.ErrArchiveEmpty), errors.Is(err, service.ErrArchiveTooManyFiles):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Permintaan arsip tidak valid", "details": err.Error()})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan", "details": err.Error()})
+		}
+		return
+	}
+
+	if req.Async {
+		userID, _ := claims["sub"].(string)
+		archiveID, err := h.fileService.CreateArchiveAsync(c.Request.Context(), userID, files, req)
+		if errors.Is(err, service.ErrArchiveAsyncUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Arsip asinkron tidak tersedia", "details": err.Error()})
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Gagal memulai pembuatan arsip asinkron")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat arsip"})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"archive_id":   archiveID,
+			"status":       model.JobStatusPending,
+			"status_url":   "/files/archive/" + archiveID,
+			"download_url": "/files/" + archiveID,
+			"file_count":   len(files),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", attachmentDisposition(service.ArchiveFileName(req.Name, time.Now())))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+
+	if err := h.fileService.WriteArchive(c.Request.Context(), c.Writer, files, req.Manifest); err != nil {
+		log.Error().Err(err).Int("files", len(files)).Msg("Gagal mengirim arsip ke klien")
+	}
+}
+
+// GetFileInfo mengembalikan metadata file tanpa kontennya. Header ETag berisi
+// versi metadata untuk dipakai sebagai If-Match pada PATCH atau perubahan tag.
+func (h *FileHandler) GetFileInfo(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+
+	metadata, err := h.fileService.GetFileMetadata(c.Request.Context(), c.Param("id"), claims)
+	if err != nil {
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": err.Error()})
+		} else {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan", "details": err.Error()})
+		}
+		return
+	}
+
+	c.Header("ETag", versionETag(metadata.Version))
+	c.JSON(http.StatusOK, metadata)
+}
+
+// UpdateFileMetadata mengganti nama dan/atau seluruh tag file (PATCH /files/:id).
+func (h *FileHandler) UpdateFileMetadata(c *gin.Context) {
+	h.mutateFile(c, func(claims jwt.MapClaims, expectedVersion int64) (*model.FileMetadata, error) {
+		var update repository.MetadataUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			return nil, fmt.Errorf("%w: %v", service.ErrInvalidMetadata, err)
+		}
+		return h.fileService.UpdateFileMetadata(c.Request.Context(), c.Param("id"), update, expectedVersion, claims)
+	})
+}
+
+// AddFileTag menambahkan satu tag (PUT /files/:id/tags/*tag). Bersifat idempoten.
+func (h *FileHandler) AddFileTag(c *gin.Context) {
+	h.mutateFile(c, func(claims jwt.MapClaims, expectedVersion int64) (*model.FileMetadata, error) {
+		return h.fileService.AddFileTag(c.Request.Context(), c.Param("id"), tagParam(c), expectedVersion, claims)
+	})
+}
+
+// RemoveFileTag menghapus satu tag (DELETE /files/:id/tags/*tag). Bersifat idempoten.
+func (h *FileHandler) RemoveFileTag(c *gin.Context) {
+	h.mutateFile(c, func(claims jwt.MapClaims, expectedVersion int64) (*model.FileMetadata, error) {
+		return h.fileService.RemoveFileTag(c.Request.Context(), c.Param("id"), tagParam(c), expectedVersion, claims)
+	})
+}
+
+// tagParam mengambil tag dari parameter catch-all *tag. Tag hierarkis seperti
+// "finance/q4" memuat "/", baik ditulis langsung maupun sebagai %2F.
+func tagParam(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("tag"), "/")
+}
+
+func (h *FileHandler) mutateFile(c *gin.Context, apply func(claims jwt.MapClaims, expectedVersion int64) (*model.FileMetadata, error)) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Header If-Match tidak valid", "details": err.Error()})
+		return
+	}
+
+	metadata, err := apply(claims, expectedVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": err.Error()})
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan"})
+		case errors.Is(err, repository.ErrVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "File telah diubah oleh pihak lain", "details": err.Error()})
+		case errors.Is(err, service.ErrInvalidMetadata):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		default:
+			log.Error().Err(err).Str("file_id", c.Param("id")).Msg("Gagal mengubah metadata file")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah metadata file"})
+		}
+		return
+	}
+
+	c.Header("ETag", versionETag(metadata.Version))
+	c.JSON(http.StatusOK, metadata)
+}
+
+func versionETag(version int64) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// parseIfMatch mengubah header If-Match menjadi versi yang diharapkan.
+// Header kosong atau "*" berarti tanpa prasyarat (0).
+func parseIfMatch(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("versi '%s' bukan bilangan bulat positif", value)
+	}
+	return version, nil
+}
+
+// claimsFromContext mengambil klaim JWT yang dipasang oleh auth.JWTMiddleware.
+// Jika tidak ada, respons 401 sudah ditulis dan ok bernilai false.
+func claimsFromContext(c *gin.Context) (jwt.MapClaims, bool) {
+	claimsVal, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Claims tidak ditemukan"})
+		return nil, false
+	}
+	claims, ok := claimsVal.(jwt.MapClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Format claims tidak valid"})
+		return nil, false
+	}
+	return claims, true
+}
+
+// uploadContext menyimpan purpose (field form "purpose") dan peran pengunggah
+// di context request untuk pemilihan kebijakan upload.
+func uploadContext(c *gin.Context) context.Context {
+	req := uploadpolicy.Request{Purpose: strings.TrimSpace(c.PostForm("purpose"))}
+	if claims, ok := c.Get("claims"); ok {
+		if mapClaims, ok := claims.(jwt.MapClaims); ok {
+			req.Role, _ = mapClaims["role"].(string)
+		}
+	}
+	return uploadpolicy.WithRequest(c.Request.Context(), req)
+}
+
+func parseTags(value string) []string {
+	if value == "" {
+		return nil
+	}
+	tags := strings.Split(value, ",")
+	for i := range tags {
+		tags[i] = strings.TrimSpace(tags[i])
+	}
+	return tags
+}
+
+// isValidationError menandai error yang disebabkan oleh input klien.
+func isValidationError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := validation.AsError(err); ok {
+		return true
+	}
+	return errors.Is(err, service.ErrUnsafeArchive)
+}
+
+// attachmentDisposition membuat header Content-Disposition untuk unduhan.
+// Nama file boleh berisi tanda kutip dan karakter non-ASCII, sehingga nilainya
+// di-escape atau dikodekan RFC 2231 oleh mime.FormatMediaType alih-alih
+// disisipkan mentah.
+func attachmentDisposition(name string) string {
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name}); disposition != "" {
+		return disposition
+	}
+	return "attachment"
+}
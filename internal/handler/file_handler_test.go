@@ -6,19 +6,29 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/model"
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // FIX: Update MockFileService agar sesuai dengan interface baru
@@ -33,6 +43,20 @@ func (m *MockFileService) UploadFile(ctx context.Context, ownerID string, fileHe
 	}
 	return args.Get(0).(*model.FileMetadata), args.Error(1)
 }
+func (m *MockFileService) UploadBatch(ctx context.Context, ownerID string, items []service.BatchUploadItem) ([]service.BatchUploadResult, error) {
+	args := m.Called(ctx, ownerID, items)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.BatchUploadResult), args.Error(1)
+}
+func (m *MockFileService) UploadArchive(ctx context.Context, ownerID string, archive *multipart.FileHeader, tags []string) ([]service.BatchUploadResult, error) {
+	args := m.Called(ctx, ownerID, archive, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.BatchUploadResult), args.Error(1)
+}
 func (m *MockFileService) GetFileMetadata(ctx context.Context, fileID string, claims jwt.MapClaims) (*model.FileMetadata, error) {
 	args := m.Called(ctx, fileID, claims)
 	if args.Get(0) == nil {
@@ -40,14 +64,98 @@ func (m *MockFileService) GetFileMetadata(ctx context.Context, fileID string, cl
 	}
 	return args.Get(0).(*model.FileMetadata), args.Error(1)
 }
-func (m *MockFileService) GetFileReader(ctx context.Context, path string) (io.ReadCloser, error) {
-	args := m.Called(ctx, path)
+func (m *MockFileService) GetFileReader(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error) {
+	args := m.Called(ctx, metadata)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileService) OpenFile(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error) {
+	args := m.Called(ctx, metadata)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(io.ReadCloser), args.Error(1)
 }
 
+func (m *MockFileService) SignedDownloadURL(ctx context.Context, metadata *model.FileMetadata) (string, time.Time, error) {
+	args := m.Called(ctx, metadata)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockFileService) UploadPolicies(claims jwt.MapClaims) []service.UploadPolicyInfo {
+	args := m.Called(claims)
+	return args.Get(0).([]service.UploadPolicyInfo)
+}
+
+func (m *MockFileService) ApplyConfig(cfg *fileserviceconfig.Config) {
+	m.Called(cfg)
+}
+
+func (m *MockFileService) UploadStream(ctx context.Context, ownerID, filename string, content io.Reader, tags []string) (*model.FileMetadata, error) {
+	args := m.Called(ctx, ownerID, filename, content, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileMetadata), args.Error(1)
+}
+
+func (m *MockFileService) ListFiles(ctx context.Context, filter repository.FileFilter, claims jwt.MapClaims) ([]*model.FileMetadata, error) {
+	args := m.Called(ctx, filter, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.FileMetadata), args.Error(1)
+}
+
+func (m *MockFileService) DeleteFile(ctx context.Context, fileID string, claims jwt.MapClaims) error {
+	args := m.Called(ctx, fileID, claims)
+	return args.Error(0)
+}
+
+func (m *MockFileService) ResolveArchiveFiles(ctx context.Context, req service.ArchiveRequest, claims jwt.MapClaims) ([]*model.FileMetadata, error) {
+	args := m.Called(ctx, req, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.FileMetadata), args.Error(1)
+}
+func (m *MockFileService) WriteArchive(ctx context.Context, w io.Writer, files []*model.FileMetadata, withManifest bool) error {
+	args := m.Called(ctx, w, files, withManifest)
+	if _, err := io.WriteString(w, "zip-bytes"); err != nil {
+		return err
+	}
+	return args.Error(0)
+}
+func (m *MockFileService) CreateArchiveAsync(ctx context.Context, ownerID string, files []*model.FileMetadata, req service.ArchiveRequest) (string, error) {
+	args := m.Called(ctx, ownerID, files, req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileService) UpdateFileMetadata(ctx context.Context, fileID string, update repository.MetadataUpdate, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	args := m.Called(ctx, fileID, update, expectedVersion, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileMetadata), args.Error(1)
+}
+func (m *MockFileService) AddFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	args := m.Called(ctx, fileID, tag, expectedVersion, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileMetadata), args.Error(1)
+}
+func (m *MockFileService) RemoveFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	args := m.Called(ctx, fileID, tag, expectedVersion, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileMetadata), args.Error(1)
+}
+
 func createUploadRequest(fileContent string, tags string) (*http.Request, string, error) {
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
@@ -69,6 +177,41 @@ func createUploadRequest(fileContent string, tags string) (*http.Request, string
 	return req, writer.FormDataContentType(), nil
 }
 
+func TestPolicyRequestContext_ClientIP(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		expectedIP     string
+	}{
+		{name: "Spoofed X-Forwarded-For is ignored without trusted proxies", remoteAddr: "203.0.113.7:4321", forwardedFor: "10.1.2.3", expectedIP: "203.0.113.7"},
+		{name: "X-Forwarded-For from untrusted peer is ignored", trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "203.0.113.7:4321", forwardedFor: "10.1.2.3", expectedIP: "203.0.113.7"},
+		{name: "X-Forwarded-For from trusted proxy is used", trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.5:4321", forwardedFor: "198.51.100.9", expectedIP: "198.51.100.9"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, err := NewRouter(tc.trustedProxies)
+			require.NoError(t, err)
+			router.GET("/ip", PolicyRequestContext(), func(c *gin.Context) {
+				c.String(http.StatusOK, policy.RequestFromContext(c.Request.Context()).IP)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tc.remoteAddr
+			req.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedIP, w.Body.String())
+		})
+	}
+
+	_, err := NewRouter([]string{"bukan-ip"})
+	assert.Error(t, err)
+}
+
 func TestFileHandler_UploadFile(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	const userIDContextKey = "user_id"
@@ -104,12 +247,22 @@ func TestFileHandler_UploadFile(t *testing.T) {
 		{
 			name: "Failure - Validation error from service",
 			setupMock: func(mockService *MockFileService) {
-				validationError := errors.New("file size exceeds the limit")
+				validationError := validation.Errorf(validation.CodeFileTooLarge, "file size exceeds the limit")
 				mockService.On("UploadFile", mock.Anything, testUserID, mock.AnythingOfType("*multipart.FileHeader"), mock.Anything).
 					Return(nil, validationError).Once()
 			},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedBody:       `"details":"file size exceeds the limit"`,
+			expectedBody:       `"code":"file_too_large","details":"file size exceeds the limit"`,
+		},
+		{
+			name: "Failure - Rejected by upload policy",
+			setupMock: func(mockService *MockFileService) {
+				policyError := validation.Errorf(validation.CodeUploadPolicy, "%w: kebijakan 'hr-scan' mewajibkan tag hr", service.ErrUploadPolicy)
+				mockService.On("UploadFile", mock.Anything, testUserID, mock.AnythingOfType("*multipart.FileHeader"), mock.Anything).
+					Return(nil, policyError).Once()
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `mewajibkan tag hr`,
 		},
 	}
 
@@ -137,6 +290,153 @@ func TestFileHandler_UploadFile(t *testing.T) {
 		})
 	}
 }
+func TestFileHandler_UploadFile_PurposeAndRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockFileService)
+	handler := NewFileHandler(mockService)
+	router := gin.New()
+	router.POST("/upload", func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("claims", jwt.MapClaims{"sub": "user-1", "role": "hr"})
+		c.Next()
+	}, handler.UploadFile)
+
+	withPolicyRequest := mock.MatchedBy(func(ctx context.Context) bool {
+		req := uploadpolicy.RequestFromContext(ctx)
+		return req.Purpose == "hr-scan" && req.Role == "hr"
+	})
+	mockService.On("UploadFile", withPolicyRequest, "user-1", mock.AnythingOfType("*multipart.FileHeader"), []string(nil)).
+		Return(&model.FileMetadata{ID: "scan-1"}, nil).Once()
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	_, err := writer.CreateFormFile("file", "scan.pdf")
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteField("purpose", " hr-scan "))
+	assert.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestFileHandler_ListUploadPolicies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := jwt.MapClaims{"sub": "user-1", "role": "hr"}
+	mockService := new(MockFileService)
+	mockService.On("UploadPolicies", claims).Return([]service.UploadPolicyInfo{
+		{Name: "hr-scan", Purposes: []string{"hr-scan"}, MaxSizeBytes: 50 << 20, AllowedMimeTypes: []string{"application/pdf"}, DefaultRetentionDays: 3650, Sanitize: true},
+		{Name: "default", MaxSizeBytes: 10 << 20, AllowedMimeTypes: []string{"image/png"}},
+	}).Once()
+	handler := NewFileHandler(mockService)
+	router := gin.New()
+	router.GET("/policies", func(c *gin.Context) {
+		c.Set("claims", claims)
+		c.Next()
+	}, handler.ListUploadPolicies)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/policies", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"policies":[
+		{"name":"hr-scan","purposes":["hr-scan"],"max_size_bytes":52428800,"allowed_mime_types":["application/pdf"],"default_retention_days":3650,"sanitize":true},
+		{"name":"default","max_size_bytes":10485760,"allowed_mime_types":["image/png"],"sanitize":false}
+	]}`, recorder.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestFileHandler_UploadBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testUserID := "user-id-from-jwt"
+
+	mockAuthMiddleware := func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("user_id", testUserID)
+			c.Next()
+		}
+	}
+
+	newBatchRequest := func(t *testing.T, fileField string, fileNames []string, fields map[string]string) *http.Request {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		for _, name := range fileNames {
+			part, err := writer.CreateFormFile(fileField, name)
+			assert.NoError(t, err)
+			_, err = part.Write([]byte("content of " + name))
+			assert.NoError(t, err)
+		}
+		for key, value := range fields {
+			assert.NoError(t, writer.WriteField(key, value))
+		}
+		assert.NoError(t, writer.Close())
+		req, err := http.NewRequest(http.MethodPost, "/upload/batch", body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	t.Run("Success - Shared and per-file tags are merged", func(t *testing.T) {
+		mockService := new(MockFileService)
+		itemsMatcher := mock.MatchedBy(func(items []service.BatchUploadItem) bool {
+			return len(items) == 2 &&
+				assert.ObjectsAreEqual([]string{"import"}, items[0].Tags) &&
+				assert.ObjectsAreEqual([]string{"import", "hr"}, items[1].Tags)
+		})
+		mockService.On("UploadBatch", mock.Anything, testUserID, itemsMatcher).Return([]service.BatchUploadResult{
+			{FileName: "a.pdf", Success: true, File: &model.FileMetadata{ID: "file-a"}},
+			{FileName: "b.pdf", Success: true, File: &model.FileMetadata{ID: "file-b"}},
+		}, nil).Once()
+
+		router := gin.New()
+		router.POST("/upload/batch", mockAuthMiddleware(), NewFileHandler(mockService).UploadBatch)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newBatchRequest(t, "files", []string{"a.pdf", "b.pdf"}, map[string]string{"tags": "import", "tags[b.pdf]": "hr"}))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"succeeded":2`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Partial - Mixed results return 207 and hide internal errors", func(t *testing.T) {
+		mockService := new(MockFileService)
+		mockService.On("UploadBatch", mock.Anything, testUserID, mock.Anything).Return([]service.BatchUploadResult{
+			{FileName: "a.pdf", Success: true, File: &model.FileMetadata{ID: "file-a"}},
+			{FileName: "b.exe", Error: "mime type 'application/x-msdownload' is not allowed", ErrorCode: validation.CodeMimeTypeNotAllowed, Err: validation.Errorf(validation.CodeMimeTypeNotAllowed, "mime type 'application/x-msdownload' is not allowed")},
+			{FileName: "c.pdf", Error: "db down", Err: errors.New("db down")},
+		}, nil).Once()
+
+		router := gin.New()
+		router.POST("/upload/batch", mockAuthMiddleware(), NewFileHandler(mockService).UploadBatch)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newBatchRequest(t, "files", []string{"a.pdf", "b.exe", "c.pdf"}, nil))
+
+		assert.Equal(t, http.StatusMultiStatus, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"failed":2`)
+		assert.Contains(t, recorder.Body.String(), "is not allowed")
+		assert.Contains(t, recorder.Body.String(), `"error_code":"mime_type_not_allowed"`)
+		assert.NotContains(t, recorder.Body.String(), "db down")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Unsafe archive rejected", func(t *testing.T) {
+		mockService := new(MockFileService)
+		mockService.On("UploadArchive", mock.Anything, testUserID, mock.AnythingOfType("*multipart.FileHeader"), []string(nil)).
+			Return(nil, service.ErrUnsafeArchive).Once()
+
+		router := gin.New()
+		router.POST("/upload/batch", mockAuthMiddleware(), NewFileHandler(mockService).UploadBatch)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newBatchRequest(t, "archive", []string{"bundle.zip"}, nil))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
 func TestFileHandler_DownloadFile(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	fileID := "file-abc-123"
@@ -152,9 +452,11 @@ func TestFileHandler_DownloadFile(t *testing.T) {
 
 	testCases := []struct {
 		name               string
+		query              string
 		setupMock          func(mockService *MockFileService)
 		expectedStatusCode int
 		expectedBody       string
+		expectedHeaders    map[string]string
 	}{
 		{
 			name: "Success - File downloaded",
@@ -165,14 +467,60 @@ func TestFileHandler_DownloadFile(t *testing.T) {
 					StoragePath:  "some/path/report.pdf",
 					MimeType:     "application/pdf",
 					SizeBytes:    12345,
+					Version:      7,
+					// SHA-256 dan MD5 dari "pdf content".
+					ChecksumSHA256: "9cca06ce6b093aacad4657a5198cfceb531e04c69d602b30d1d05749173eae5f",
+					ChecksumMD5:    "844861549e91b28a552f1a8c32fbf715",
 				}
 				mockService.On("GetFileMetadata", mock.Anything, fileID, mock.AnythingOfType("jwt.MapClaims")).Return(metadata, nil).Once()
 
 				mockReader := io.NopCloser(strings.NewReader("pdf content"))
-				mockService.On("GetFileReader", mock.Anything, metadata.StoragePath).Return(mockReader, nil).Once()
+				mockService.On("OpenFile", mock.Anything, metadata).Return(mockReader, nil).Once()
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedBody:       "pdf content",
+			expectedHeaders: map[string]string{
+				"Digest": "sha-256=nMoGzmsJOqytRlelGYz861MeBMadYCsw0dBXSRc+rl8=",
+				// Versi, sama dengan ETag metadata, agar dapat dipakai di If-Match.
+				"ETag":        `"7"`,
+				"Content-MD5": "hEhhVJ6RsopVLxqMMvv3FQ==",
+			},
+		},
+		{
+			name: "Success - Quoted name is escaped in Content-Disposition",
+			setupMock: func(mockService *MockFileService) {
+				metadata := &model.FileMetadata{ID: fileID, OriginalName: `laporan "final"; x=1.pdf`, StoragePath: "a", MimeType: "application/pdf", SizeBytes: 3}
+				mockService.On("GetFileMetadata", mock.Anything, fileID, mock.AnythingOfType("jwt.MapClaims")).Return(metadata, nil).Once()
+				mockService.On("OpenFile", mock.Anything, metadata).Return(io.NopCloser(strings.NewReader("pdf")), nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "pdf",
+			expectedHeaders: map[string]string{
+				"Content-Disposition": `attachment; filename="laporan \"final\"; x=1.pdf"`,
+			},
+		},
+		{
+			name:  "Success - Redirect to signed URL",
+			query: "?redirect=true",
+			setupMock: func(mockService *MockFileService) {
+				metadata := &model.FileMetadata{ID: fileID, StoragePath: "a"}
+				mockService.On("GetFileMetadata", mock.Anything, fileID, mock.AnythingOfType("jwt.MapClaims")).Return(metadata, nil).Once()
+				mockService.On("SignedDownloadURL", mock.Anything, metadata).Return("https://blob.example/a?sig=1", time.Now().Add(time.Minute), nil).Once()
+			},
+			expectedStatusCode: http.StatusFound,
+			expectedHeaders:    map[string]string{"Location": "https://blob.example/a?sig=1"},
+		},
+		{
+			name:  "Success - Redirect falls back to streaming when signing is unsupported",
+			query: "?redirect=true",
+			setupMock: func(mockService *MockFileService) {
+				metadata := &model.FileMetadata{ID: fileID, StoragePath: "a", MimeType: "text/plain", SizeBytes: 2}
+				mockService.On("GetFileMetadata", mock.Anything, fileID, mock.AnythingOfType("jwt.MapClaims")).Return(metadata, nil).Once()
+				mockService.On("SignedDownloadURL", mock.Anything, metadata).Return("", time.Time{}, storage.ErrSigningUnsupported).Once()
+				mockService.On("OpenFile", mock.Anything, metadata).Return(io.NopCloser(strings.NewReader("ok")), nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "ok",
 		},
 		{
 			name: "Failure - Access Denied",
@@ -195,11 +543,21 @@ func TestFileHandler_DownloadFile(t *testing.T) {
 			setupMock: func(mockService *MockFileService) {
 				metadata := &model.FileMetadata{StoragePath: "missing/file.txt"}
 				mockService.On("GetFileMetadata", mock.Anything, fileID, mock.AnythingOfType("jwt.MapClaims")).Return(metadata, nil).Once()
-				mockService.On("GetFileReader", mock.Anything, metadata.StoragePath).Return(nil, errors.New("file not on disk")).Once()
+				mockService.On("OpenFile", mock.Anything, metadata).Return(nil, fmt.Errorf("%w: missing/file.txt", storage.ErrNotFound)).Once()
 			},
-			expectedStatusCode: http.StatusInternalServerError,
+			expectedStatusCode: http.StatusNotFound,
 			expectedBody:       `"error":"File tidak ditemukan di penyimpanan"`,
 		},
+		{
+			name: "Failure - Storage Unavailable",
+			setupMock: func(mockService *MockFileService) {
+				metadata := &model.FileMetadata{StoragePath: "file.txt"}
+				mockService.On("GetFileMetadata", mock.Anything, fileID, mock.AnythingOfType("jwt.MapClaims")).Return(metadata, nil).Once()
+				mockService.On("OpenFile", mock.Anything, metadata).Return(nil, errors.New("connection refused")).Once()
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `"error":"Gagal membaca file dari penyimpanan"`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -212,11 +570,243 @@ func TestFileHandler_DownloadFile(t *testing.T) {
 
 			router.GET("/files/:id", mockAuthMiddleware(), handler.DownloadFile)
 
-			req, _ := http.NewRequest(http.MethodGet, "/files/"+fileID, nil)
+			req, _ := http.NewRequest(http.MethodGet, "/files/"+fileID+tc.query, nil)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			for name, value := range tc.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(name), name)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFileHandler_DownloadArchive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuthMiddleware := func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("claims", jwt.MapClaims{"sub": "user-test", "role": "user"})
+			c.Next()
+		}
+	}
+	files := []*model.FileMetadata{{ID: "file-1", OriginalName: "a.pdf"}}
+
+	testCases := []struct {
+		name               string
+		body               string
+		setupMock          func(mockService *MockFileService)
+		expectedStatusCode int
+		expectedBody       string
+		expectedHeader     string
+	}{
+		{
+			name: "Success - Archive streamed",
+			body: `{"file_ids":["file-1"],"name":"q3-invoices","manifest":true}`,
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("ResolveArchiveFiles", mock.Anything, mock.AnythingOfType("service.ArchiveRequest"), mock.AnythingOfType("jwt.MapClaims")).Return(files, nil).Once()
+				mockService.On("WriteArchive", mock.Anything, mock.Anything, files, true).Return(nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "zip-bytes",
+			expectedHeader:     `attachment; filename=q3-invoices.zip`,
+		},
+		{
+			name: "Success - Async archive accepted",
+			body: `{"tags":["invoice"],"async":true}`,
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("ResolveArchiveFiles", mock.Anything, mock.AnythingOfType("service.ArchiveRequest"), mock.AnythingOfType("jwt.MapClaims")).Return(files, nil).Once()
+				mockService.On("CreateArchiveAsync", mock.Anything, "user-test", files, mock.AnythingOfType("service.ArchiveRequest")).Return("archive-1", nil).Once()
+			},
+			expectedStatusCode: http.StatusAccepted,
+			expectedBody:       `"status_url":"/files/archive/archive-1"`,
+		},
+		{
+			name: "Failure - Async archive without job queue",
+			body: `{"tags":["invoice"],"async":true}`,
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("ResolveArchiveFiles", mock.Anything, mock.AnythingOfType("service.ArchiveRequest"), mock.AnythingOfType("jwt.MapClaims")).Return(files, nil).Once()
+				mockService.On("CreateArchiveAsync", mock.Anything, "user-test", files, mock.AnythingOfType("service.ArchiveRequest")).Return("", service.ErrArchiveAsyncUnavailable).Once()
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `"error":"Arsip asinkron tidak tersedia"`,
+		},
+		{
+			name: "Failure - Access denied to one of the files",
+			body: `{"file_ids":["file-2"]}`,
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("ResolveArchiveFiles", mock.Anything, mock.AnythingOfType("service.ArchiveRequest"), mock.AnythingOfType("jwt.MapClaims")).Return(nil, service.ErrAccessDenied).Once()
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `"error":"Akses ditolak"`,
+		},
+		{
+			name: "Failure - Empty archive request",
+			body: `{}`,
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("ResolveArchiveFiles", mock.Anything, mock.AnythingOfType("service.ArchiveRequest"), mock.AnythingOfType("jwt.MapClaims")).Return(nil, service.ErrArchiveEmpty).Once()
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"error":"Permintaan arsip tidak valid"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router := gin.New()
+			mockService := new(MockFileService)
+			tc.setupMock(mockService)
+			handler := NewFileHandler(mockService)
+
+			router.POST("/files/archive", mockAuthMiddleware(), handler.DownloadArchive)
+
+			req, _ := http.NewRequest(http.MethodPost, "/files/archive", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
 			router.ServeHTTP(recorder, req)
 
 			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
 			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			if tc.expectedHeader != "" {
+				assert.Equal(t, tc.expectedHeader, recorder.Header().Get("Content-Disposition"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttachmentDisposition(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filename string
+	}{
+		{name: "ASCII name", filename: "report.pdf"},
+		{name: "Name with quotes and separators", filename: `laporan "final"; filename=evil.exe`},
+		{name: "UTF-8 name", filename: "Laporan Kuartal – Q3 ✓.pdf"},
+		{name: "Name with backslash", filename: `a\b.txt`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			disposition, params, err := mime.ParseMediaType(attachmentDisposition(tc.filename))
+			require.NoError(t, err)
+			assert.Equal(t, "attachment", disposition)
+			assert.Equal(t, map[string]string{"filename": tc.filename}, params)
+		})
+	}
+}
+
+func TestFileHandler_MetadataMutations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fileID := "file-abc-123"
+
+	mockAuthMiddleware := func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("claims", jwt.MapClaims{"sub": "user-test", "role": "user"})
+			c.Next()
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		ifMatch            string
+		setupMock          func(mockService *MockFileService)
+		expectedStatusCode int
+		expectedETag       string
+	}{
+		{
+			name:    "Success - Rename with If-Match",
+			method:  http.MethodPatch,
+			path:    "/files/" + fileID,
+			body:    `{"original_name":"renamed.pdf"}`,
+			ifMatch: `"4"`,
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("UpdateFileMetadata", mock.Anything, fileID, mock.AnythingOfType("repository.MetadataUpdate"), int64(4), mock.AnythingOfType("jwt.MapClaims")).
+					Return(&model.FileMetadata{ID: fileID, OriginalName: "renamed.pdf", Version: 5}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedETag:       `"5"`,
+		},
+		{
+			name:    "Failure - Stale If-Match",
+			method:  http.MethodPut,
+			path:    "/files/" + fileID + "/tags/finance",
+			ifMatch: `W/"3"`,
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("AddFileTag", mock.Anything, fileID, "finance", int64(3), mock.AnythingOfType("jwt.MapClaims")).
+					Return(nil, repository.ErrVersionConflict).Once()
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "Success - Hierarchical tag with encoded slash",
+			method: http.MethodPut,
+			path:   "/files/" + fileID + "/tags/finance%2Fq4",
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("AddFileTag", mock.Anything, fileID, "finance/q4", int64(0), mock.AnythingOfType("jwt.MapClaims")).
+					Return(&model.FileMetadata{ID: fileID, Tags: []string{"finance/q4"}, Version: 2}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedETag:       `"2"`,
+		},
+		{
+			name:   "Success - Hierarchical tag with plain slash",
+			method: http.MethodDelete,
+			path:   "/files/" + fileID + "/tags/finance/q4",
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("RemoveFileTag", mock.Anything, fileID, "finance/q4", int64(0), mock.AnythingOfType("jwt.MapClaims")).
+					Return(&model.FileMetadata{ID: fileID, Version: 3}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedETag:       `"3"`,
+		},
+		{
+			name:               "Failure - Malformed If-Match",
+			method:             http.MethodDelete,
+			path:               "/files/" + fileID + "/tags/finance",
+			ifMatch:            `"abc"`,
+			setupMock:          func(mockService *MockFileService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Failure - File not found",
+			method: http.MethodDelete,
+			path:   "/files/" + fileID + "/tags/finance",
+			setupMock: func(mockService *MockFileService) {
+				mockService.On("RemoveFileTag", mock.Anything, fileID, "finance", int64(0), mock.AnythingOfType("jwt.MapClaims")).
+					Return(nil, repository.ErrNotFound).Once()
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router := gin.New()
+			mockService := new(MockFileService)
+			tc.setupMock(mockService)
+			handler := NewFileHandler(mockService)
+
+			router.PATCH("/files/:id", mockAuthMiddleware(), handler.UpdateFileMetadata)
+			router.PUT("/files/:id/tags/*tag", mockAuthMiddleware(), handler.AddFileTag)
+			router.DELETE("/files/:id/tags/*tag", mockAuthMiddleware(), handler.RemoveFileTag)
+
+			req, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			if tc.expectedETag != "" {
+				assert.Equal(t, tc.expectedETag, recorder.Header().Get("ETag"))
+			}
 			mockService.AssertExpectations(t)
 		})
 	}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	commonjwt "github.com/Lumina-Enterprise-Solutions/prism-common-libs/auth"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// jobStatuses adalah nilai filter ?status= yang dikenali.
+var jobStatuses = map[string]bool{
+	model.JobStatusPending: true, model.JobStatusRunning: true,
+	model.JobStatusSucceeded: true, model.JobStatusFailed: true,
+}
+
+type JobHandler struct {
+	jobService service.JobService
+}
+
+func NewJobHandler(jobService service.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// ListJobs menangani GET /files/admin/jobs?status=&type=&file_id=&limit=.
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	filter := repository.JobFilter{Status: c.Query("status"), Type: c.Query("type"), FileID: c.Query("file_id")}
+	if filter.Status != "" && !jobStatuses[filter.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter status tidak valid", "details": "status harus pending, running, succeeded atau failed"})
+		return
+	}
+	var err error
+	if filter.Limit, err = queryInt(c, "limit"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter limit tidak valid", "details": err.Error()})
+		return
+	}
+
+	jobs, err := h.jobService.ListJobs(c.Request.Context(), filter)
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// GetJob menangani GET /files/admin/jobs/:id.
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobService.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryJob menangani POST /files/admin/jobs/:id/retry untuk job yang gagal.
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	actorID, _ := commonjwt.GetUserID(c)
+	job, err := h.jobService.RetryJob(c.Request.Context(), c.Param("id"), actorID)
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// ListFileJobs menangani GET /files/:id/jobs: status pemrosesan latar
+// belakang sebuah file untuk pengguna yang dapat membacanya.
+func (h *JobHandler) ListFileJobs(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	jobs, err := h.jobService.ListFileJobs(c.Request.Context(), c.Param("id"), claims)
+	if err != nil {
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": err.Error()})
+		} else {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan", "details": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// GetArchiveStatus menangani GET /files/archive/:id: status pembuatan arsip
+// asinkron untuk pemiliknya. download_url hanya diisi setelah arsip selesai.
+func (h *JobHandler) GetArchiveStatus(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	archiveID := c.Param("id")
+	job, err := h.jobService.GetArchiveJob(c.Request.Context(), archiveID, claims)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": err.Error()})
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Arsip tidak ditemukan"})
+		default:
+			respondJobError(c, err)
+		}
+		return
+	}
+	response := gin.H{"archive_id": archiveID, "status": job.Status, "attempts": job.Attempts}
+	if job.LastError != "" {
+		response["last_error"] = job.LastError
+	}
+	if job.Status == model.JobStatusSucceeded {
+		response["download_url"] = "/files/" + archiveID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func respondJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job tidak ditemukan"})
+	case errors.Is(err, repository.ErrJobNotFailed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Job tidak dapat di-retry", "details": err.Error()})
+	default:
+		log.Error().Err(err).Msg("Gagal memproses job")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses job"})
+	}
+}
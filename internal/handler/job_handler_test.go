@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) ListJobs(ctx context.Context, filter repository.JobFilter) ([]*model.Job, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Job), args.Error(1)
+}
+func (m *MockJobService) GetJob(ctx context.Context, id string) (*model.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Job), args.Error(1)
+}
+func (m *MockJobService) RetryJob(ctx context.Context, id, actorID string) (*model.Job, error) {
+	args := m.Called(ctx, id, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Job), args.Error(1)
+}
+func (m *MockJobService) ListFileJobs(ctx context.Context, fileID string, claims jwt.MapClaims) ([]*model.Job, error) {
+	args := m.Called(ctx, fileID, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Job), args.Error(1)
+}
+func (m *MockJobService) GetArchiveJob(ctx context.Context, archiveID string, claims jwt.MapClaims) (*model.Job, error) {
+	args := m.Called(ctx, archiveID, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Job), args.Error(1)
+}
+
+func TestJobHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authAs := func(role string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("user_id", "user-"+role)
+			c.Set("claims", jwt.MapClaims{"sub": "user-" + role, "role": role})
+			c.Next()
+		}
+	}
+	failedJob := &model.Job{ID: "job-1", Type: model.JobTypeExtractText, Status: model.JobStatusFailed, LastError: "storage mati"}
+
+	testCases := []struct {
+		name               string
+		role               string
+		method             string
+		path               string
+		setupMock          func(mockService *MockJobService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "Failure - Non-admin cannot list jobs",
+			role:               "user",
+			method:             http.MethodGet,
+			path:               "/files/admin/jobs",
+			setupMock:          func(mockService *MockJobService) {},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:   "Success - Admin lists failed jobs",
+			role:   "admin",
+			method: http.MethodGet,
+			path:   "/files/admin/jobs?status=failed&type=extract_text&limit=10",
+			setupMock: func(mockService *MockJobService) {
+				filter := repository.JobFilter{Status: model.JobStatusFailed, Type: model.JobTypeExtractText, Limit: 10}
+				mockService.On("ListJobs", mock.Anything, filter).Return([]*model.Job{failedJob}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"last_error":"storage mati"`,
+		},
+		{
+			name:               "Failure - Unknown status filter",
+			role:               "admin",
+			method:             http.MethodGet,
+			path:               "/files/admin/jobs?status=selesai",
+			setupMock:          func(mockService *MockJobService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "Parameter status tidak valid",
+		},
+		{
+			name:   "Failure - Job not found",
+			role:   "admin",
+			method: http.MethodGet,
+			path:   "/files/admin/jobs/job-x",
+			setupMock: func(mockService *MockJobService) {
+				mockService.On("GetJob", mock.Anything, "job-x").Return(nil, repository.ErrNotFound).Once()
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:   "Success - Retry failed job",
+			role:   "admin",
+			method: http.MethodPost,
+			path:   "/files/admin/jobs/job-1/retry",
+			setupMock: func(mockService *MockJobService) {
+				mockService.On("RetryJob", mock.Anything, "job-1", "user-admin").Return(&model.Job{ID: "job-1", Status: model.JobStatusPending}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"status":"pending"`,
+		},
+		{
+			name:   "Failure - Retry job that has not failed",
+			role:   "admin",
+			method: http.MethodPost,
+			path:   "/files/admin/jobs/job-2/retry",
+			setupMock: func(mockService *MockJobService) {
+				mockService.On("RetryJob", mock.Anything, "job-2", "user-admin").Return(nil, repository.ErrJobNotFailed).Once()
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name:   "Success - Owner sees file jobs",
+			role:   "user",
+			method: http.MethodGet,
+			path:   "/files/file-1/jobs",
+			setupMock: func(mockService *MockJobService) {
+				mockService.On("ListFileJobs", mock.Anything, "file-1", mock.Anything).Return([]*model.Job{{ID: "job-3", Status: model.JobStatusRunning}}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"status":"running"`,
+		},
+		{
+			name:   "Failure - File jobs denied",
+			role:   "user",
+			method: http.MethodGet,
+			path:   "/files/file-2/jobs",
+			setupMock: func(mockService *MockJobService) {
+				mockService.On("ListFileJobs", mock.Anything, "file-2", mock.Anything).Return(nil, service.ErrAccessDenied).Once()
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:   "Success - Finished archive has download URL",
+			role:   "user",
+			method: http.MethodGet,
+			path:   "/files/archive/archive-1",
+			setupMock: func(mockService *MockJobService) {
+				job := &model.Job{ID: "archive-1", Type: model.JobTypeBuildArchive, Status: model.JobStatusSucceeded, Attempts: 1}
+				mockService.On("GetArchiveJob", mock.Anything, "archive-1", mock.Anything).Return(job, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"download_url":"/files/archive-1"`,
+		},
+		{
+			name:   "Success - Pending archive has no download URL",
+			role:   "user",
+			method: http.MethodGet,
+			path:   "/files/archive/archive-2",
+			setupMock: func(mockService *MockJobService) {
+				job := &model.Job{ID: "archive-2", Type: model.JobTypeBuildArchive, Status: model.JobStatusPending}
+				mockService.On("GetArchiveJob", mock.Anything, "archive-2", mock.Anything).Return(job, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"archive_id":"archive-2","attempts":0,"status":"pending"}`,
+		},
+		{
+			name:   "Failure - Unknown archive",
+			role:   "user",
+			method: http.MethodGet,
+			path:   "/files/archive/job-1",
+			setupMock: func(mockService *MockJobService) {
+				mockService.On("GetArchiveJob", mock.Anything, "job-1", mock.Anything).Return(nil, repository.ErrNotFound).Once()
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockJobService)
+			tc.setupMock(mockService)
+			h := NewJobHandler(mockService)
+
+			router := gin.New()
+			files := router.Group("/files", authAs(tc.role))
+			files.GET("/archive/:id", h.GetArchiveStatus)
+			files.GET("/:id/jobs", h.ListFileJobs)
+			admin := files.Group("/admin", RequireRole("admin"))
+			admin.GET("/jobs", h.ListJobs)
+			admin.GET("/jobs/:id", h.GetJob)
+			admin.POST("/jobs/:id/retry", h.RetryJob)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type PDFHandler struct {
+	pdfService service.PDFService
+}
+
+func NewPDFHandler(pdfService service.PDFService) *PDFHandler {
+	return &PDFHandler{pdfService: pdfService}
+}
+
+// Merge menangani POST /files/pdf/merge: menggabungkan PDF yang dapat dibaca
+// pemanggil menjadi file baru milik pemanggil.
+func (h *PDFHandler) Merge(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req service.PDFMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body permintaan tidak valid", "details": err.Error()})
+		return
+	}
+
+	userID, _ := claims["sub"].(string)
+	metadata, err := h.pdfService.Merge(uploadContext(c), userID, req, claims)
+	if err != nil {
+		respondPDFError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, metadata)
+}
+
+// FirstPage menangani GET /files/:id/pdf/first-page dan mengirim PDF satu
+// halaman berisi halaman pertama dokumen untuk pratinjau.
+func (h *PDFHandler) FirstPage(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	preview, err := h.pdfService.FirstPage(c.Request.Context(), c.Param("id"), claims)
+	if err != nil {
+		respondPDFError(c, err)
+		return
+	}
+	defer func() {
+		if err := preview.Content.Close(); err != nil {
+			log.Warn().Err(err).Str("file_id", c.Param("id")).Msg("Gagal menutup pratinjau PDF")
+		}
+	}()
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", preview.ETag)
+	if c.GetHeader("If-None-Match") == preview.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, preview.Size, preview.ContentType, preview.Content, map[string]string{
+		"Content-Disposition": "inline",
+	})
+}
+
+func respondPDFError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPDFMergeTooFewFiles), errors.Is(err, service.ErrPDFMergeTooManyFiles):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Permintaan penggabungan tidak valid", "details": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": err.Error()})
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, storage.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan", "details": err.Error()})
+	case errors.Is(err, service.ErrPDFMergeTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "PDF terlalu besar", "details": err.Error()})
+	case errors.Is(err, service.ErrNotPDF):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "File bukan PDF", "details": err.Error()})
+	case errors.Is(err, pdf.ErrEncrypted), errors.Is(err, pdf.ErrMalformed), errors.Is(err, pdf.ErrNoPages):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "PDF tidak dapat diproses", "details": err.Error()})
+	default:
+		// Hasil penggabungan ditolak validasi unggahan, mis. kebijakan
+		// upload pemanggil tidak mengizinkan PDF.
+		if validationErr, ok := validation.AsError(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "code": validationErr.Code, "details": err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Gagal memproses PDF")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses PDF"})
+	}
+}
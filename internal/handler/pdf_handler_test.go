@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPDFService struct {
+	mock.Mock
+}
+
+func (m *MockPDFService) Merge(ctx context.Context, ownerID string, req service.PDFMergeRequest, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	args := m.Called(ctx, ownerID, req, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileMetadata), args.Error(1)
+}
+
+func (m *MockPDFService) FirstPage(ctx context.Context, fileID string, claims jwt.MapClaims) (*service.Rendition, error) {
+	args := m.Called(ctx, fileID, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.Rendition), args.Error(1)
+}
+
+func TestPDFHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := jwt.MapClaims{"sub": "user-1", "role": "user"}
+	mergeRequest := service.PDFMergeRequest{FileIDs: []string{"a", "b"}, Name: "paket"}
+	preview := func() *service.Rendition {
+		return &service.Rendition{Content: io.NopCloser(strings.NewReader("%PDF-")), ContentType: "application/pdf", Size: 5, ETag: `"a-page-1"`}
+	}
+
+	testCases := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		ifNoneMatch        string
+		setupMock          func(mockService *MockPDFService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:   "Success - Merge",
+			method: http.MethodPost,
+			path:   "/files/pdf/merge",
+			body:   `{"file_ids":["a","b"],"name":"paket"}`,
+			setupMock: func(mockService *MockPDFService) {
+				merged := &model.FileMetadata{ID: "gabungan", OriginalName: "paket.pdf", PDF: &model.PDFInfo{PageCount: 3}}
+				mockService.On("Merge", mock.Anything, "user-1", mergeRequest, claims).Return(merged, nil).Once()
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `"pdf":{"page_count":3}`,
+		},
+		{
+			name:               "Failure - Merge without file_ids",
+			method:             http.MethodPost,
+			path:               "/files/pdf/merge",
+			body:               `{"name":"paket"}`,
+			setupMock:          func(mockService *MockPDFService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Failure - Merge with non-PDF source",
+			method: http.MethodPost,
+			path:   "/files/pdf/merge",
+			body:   `{"file_ids":["a","b"],"name":"paket"}`,
+			setupMock: func(mockService *MockPDFService) {
+				mockService.On("Merge", mock.Anything, "user-1", mergeRequest, claims).Return(nil, fmt.Errorf("%w: b", service.ErrNotPDF)).Once()
+			},
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "Failure - Merge with encrypted source",
+			method: http.MethodPost,
+			path:   "/files/pdf/merge",
+			body:   `{"file_ids":["a","b"],"name":"paket"}`,
+			setupMock: func(mockService *MockPDFService) {
+				mockService.On("Merge", mock.Anything, "user-1", mergeRequest, claims).Return(nil, fmt.Errorf("file b: %w", pdf.ErrEncrypted)).Once()
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "Failure - Merge result rejected by upload policy",
+			method: http.MethodPost,
+			path:   "/files/pdf/merge",
+			body:   `{"file_ids":["a","b"],"name":"paket"}`,
+			setupMock: func(mockService *MockPDFService) {
+				err := validation.Errorf(validation.CodeMimeTypeNotAllowed, "mime type 'application/pdf' is not allowed")
+				mockService.On("Merge", mock.Anything, "user-1", mergeRequest, claims).Return(nil, err).Once()
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       string(validation.CodeMimeTypeNotAllowed),
+		},
+		{
+			name:   "Success - First page",
+			method: http.MethodGet,
+			path:   "/files/a/pdf/first-page",
+			setupMock: func(mockService *MockPDFService) {
+				mockService.On("FirstPage", mock.Anything, "a", claims).Return(preview(), nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "%PDF-",
+		},
+		{
+			name:        "Success - First page not modified",
+			method:      http.MethodGet,
+			path:        "/files/a/pdf/first-page",
+			ifNoneMatch: `"a-page-1"`,
+			setupMock: func(mockService *MockPDFService) {
+				mockService.On("FirstPage", mock.Anything, "a", claims).Return(preview(), nil).Once()
+			},
+			expectedStatusCode: http.StatusNotModified,
+		},
+		{
+			name:   "Failure - First page of missing file",
+			method: http.MethodGet,
+			path:   "/files/a/pdf/first-page",
+			setupMock: func(mockService *MockPDFService) {
+				mockService.On("FirstPage", mock.Anything, "a", claims).Return(nil, repository.ErrNotFound).Once()
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:   "Failure - First page denied",
+			method: http.MethodGet,
+			path:   "/files/a/pdf/first-page",
+			setupMock: func(mockService *MockPDFService) {
+				mockService.On("FirstPage", mock.Anything, "a", claims).Return(nil, service.ErrAccessDenied).Once()
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockPDFService)
+			tc.setupMock(mockService)
+			h := NewPDFHandler(mockService)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set("claims", claims) })
+			router.POST("/files/pdf/merge", h.Merge)
+			router.GET("/files/:id/pdf/first-page", h.FirstPage)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/imaging"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type RenderHandler struct {
+	renderService service.RenderService
+}
+
+func NewRenderHandler(renderService service.RenderService) *RenderHandler {
+	return &RenderHandler{renderService: renderService}
+}
+
+// SignRenderURL menangani GET /files/:id/render-url?w=&h=&fit=&format=&quality=
+// dan mengembalikan URL render bertanda tangan untuk file yang dapat dibaca
+// pemanggil.
+func (h *RenderHandler) SignRenderURL(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	signed, err := h.renderService.SignURL(c.Request.Context(), c.Param("id"), c.Request.URL.Query(), claims)
+	if err != nil {
+		// Seperti GET /:id/metadata, kegagalan membaca metadata dianggap
+		// file tidak ditemukan.
+		if errors.Is(err, imaging.ErrInvalidOptions) || errors.Is(err, service.ErrAccessDenied) || errors.Is(err, service.ErrNotRenderable) {
+			respondRenderError(c, err)
+		} else {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan", "details": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, signed)
+}
+
+// Render menangani GET /files/:id/render. Endpoint ini tidak memerlukan token;
+// aksesnya dijamin oleh tanda tangan dari SignRenderURL.
+func (h *RenderHandler) Render(c *gin.Context) {
+	rendition, err := h.renderService.Render(c.Request.Context(), c.Param("id"), c.Request.URL.Query())
+	if err != nil {
+		respondRenderError(c, err)
+		return
+	}
+	defer func() {
+		if err := rendition.Content.Close(); err != nil {
+			log.Warn().Err(err).Str("file_id", c.Param("id")).Msg("Gagal menutup hasil render")
+		}
+	}()
+
+	maxAge := max(int(time.Until(rendition.ExpiresAt).Seconds()), 0)
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	c.Header("ETag", rendition.ETag)
+	if c.GetHeader("If-None-Match") == rendition.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, rendition.Size, rendition.ContentType, rendition.Content, nil)
+}
+
+func respondRenderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, imaging.ErrInvalidOptions):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter render tidak valid", "details": err.Error()})
+	case errors.Is(err, service.ErrInvalidRenderSignature), errors.Is(err, service.ErrRenderURLExpired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "URL render tidak valid", "details": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak", "details": err.Error()})
+	case errors.Is(err, service.ErrNotRenderable), errors.Is(err, imaging.ErrUnsupported):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "File tidak dapat dirender", "details": err.Error()})
+	case errors.Is(err, imaging.ErrTooLarge), errors.Is(err, imaging.ErrInvalidImage):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Gambar tidak dapat dirender", "details": err.Error()})
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, storage.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan"})
+	default:
+		log.Error().Err(err).Msg("Gagal merender gambar")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal merender gambar"})
+	}
+}
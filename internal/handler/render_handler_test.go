@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/imaging"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRenderService struct {
+	mock.Mock
+}
+
+func (m *MockRenderService) SignURL(ctx context.Context, fileID string, query url.Values, claims jwt.MapClaims) (*service.SignedRenderURL, error) {
+	args := m.Called(ctx, fileID, query, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.SignedRenderURL), args.Error(1)
+}
+
+func (m *MockRenderService) Render(ctx context.Context, fileID string, query url.Values) (*service.Rendition, error) {
+	args := m.Called(ctx, fileID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.Rendition), args.Error(1)
+}
+
+func TestRenderHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := jwt.MapClaims{"sub": "user-1", "role": "user"}
+	rendition := func() *service.Rendition {
+		return &service.Rendition{
+			Content:     io.NopCloser(strings.NewReader("gambar")),
+			ContentType: "image/png",
+			Size:        6,
+			ETag:        `"abc"`,
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		path               string
+		ifNoneMatch        string
+		setupMock          func(mockService *MockRenderService)
+		expectedStatusCode int
+		expectedBody       string
+		expectedHeaders    map[string]string
+	}{
+		{
+			name: "Success - Sign URL",
+			path: "/files/file-1/render-url?w=64",
+			setupMock: func(mockService *MockRenderService) {
+				signed := &service.SignedRenderURL{URL: "/files/file-1/render?w=64&sig=x"}
+				mockService.On("SignURL", mock.Anything, "file-1", url.Values{"w": {"64"}}, claims).Return(signed, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"url":"/files/file-1/render?w=64\u0026sig=x"`,
+		},
+		{
+			name: "Failure - Sign URL for non-image",
+			path: "/files/file-1/render-url?w=64",
+			setupMock: func(mockService *MockRenderService) {
+				mockService.On("SignURL", mock.Anything, "file-1", mock.Anything, claims).Return(nil, service.ErrNotRenderable).Once()
+			},
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "Failure - Sign URL for missing file",
+			path: "/files/file-1/render-url?w=64",
+			setupMock: func(mockService *MockRenderService) {
+				mockService.On("SignURL", mock.Anything, "file-1", mock.Anything, claims).Return(nil, repository.ErrNotFound).Once()
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "Success - Render image",
+			path: "/files/file-1/render?w=64&expires=1&sig=x",
+			setupMock: func(mockService *MockRenderService) {
+				mockService.On("Render", mock.Anything, "file-1", mock.Anything).Return(rendition(), nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "gambar",
+			expectedHeaders:    map[string]string{"Content-Type": "image/png", "Content-Length": "6", "ETag": `"abc"`},
+		},
+		{
+			name:        "Success - Not modified",
+			path:        "/files/file-1/render?w=64&expires=1&sig=x",
+			ifNoneMatch: `"abc"`,
+			setupMock: func(mockService *MockRenderService) {
+				mockService.On("Render", mock.Anything, "file-1", mock.Anything).Return(rendition(), nil).Once()
+			},
+			expectedStatusCode: http.StatusNotModified,
+		},
+		{
+			name: "Failure - Invalid parameters",
+			path: "/files/file-1/render?w=abc",
+			setupMock: func(mockService *MockRenderService) {
+				err := fmt.Errorf("%w: w harus bilangan bulat", imaging.ErrInvalidOptions)
+				mockService.On("Render", mock.Anything, "file-1", mock.Anything).Return(nil, err).Once()
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "Parameter render tidak valid",
+		},
+		{
+			name: "Failure - Bad signature",
+			path: "/files/file-1/render?w=64&expires=1&sig=palsu",
+			setupMock: func(mockService *MockRenderService) {
+				mockService.On("Render", mock.Anything, "file-1", mock.Anything).Return(nil, service.ErrInvalidRenderSignature).Once()
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name: "Failure - Source too large",
+			path: "/files/file-1/render?w=64&expires=1&sig=x",
+			setupMock: func(mockService *MockRenderService) {
+				mockService.On("Render", mock.Anything, "file-1", mock.Anything).Return(nil, imaging.ErrTooLarge).Once()
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockRenderService)
+			tc.setupMock(mockService)
+			h := NewRenderHandler(mockService)
+
+			router := gin.New()
+			router.GET("/files/:id/render", h.Render)
+			router.GET("/files/:id/render-url", func(c *gin.Context) { c.Set("claims", claims) }, h.SignRenderURL)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			for name, value := range tc.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(name))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// S3CredentialHandler mengelola access key milik pengguna untuk gateway S3.
+type S3CredentialHandler struct {
+	credentialService service.S3CredentialService
+}
+
+func NewS3CredentialHandler(cs service.S3CredentialService) *S3CredentialHandler {
+	return &S3CredentialHandler{credentialService: cs}
+}
+
+// CreateCredential menerbitkan access key baru. Secret hanya dikembalikan
+// sekali pada respons ini.
+func (h *S3CredentialHandler) CreateCredential(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+
+	credential, err := h.credentialService.CreateCredential(c.Request.Context(), claims)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyS3Credentials) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Batas access key tercapai", "details": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrS3CredentialTokenInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Token tidak dapat menerbitkan access key", "details": err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Gagal membuat access key S3")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat access key S3"})
+		return
+	}
+	c.JSON(http.StatusCreated, credential)
+}
+
+func (h *S3CredentialHandler) ListCredentials(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	userID, _ := claims["sub"].(string)
+
+	credentials, err := h.credentialService.ListCredentials(c.Request.Context(), userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Gagal memuat access key S3")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memuat access key S3"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"credentials": credentials})
+}
+
+func (h *S3CredentialHandler) DeleteCredential(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	userID, _ := claims["sub"].(string)
+
+	if err := h.credentialService.DeleteCredential(c.Request.Context(), userID, c.Param("accessKeyId")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Access key tidak ditemukan"})
+			return
+		}
+		log.Error().Err(err).Str("user_id", userID).Msg("Gagal mencabut access key S3")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut access key S3"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
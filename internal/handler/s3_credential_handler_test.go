@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockS3CredentialService struct {
+	mock.Mock
+}
+
+func (m *MockS3CredentialService) CreateCredential(ctx context.Context, claims jwt.MapClaims) (*model.S3Credential, error) {
+	args := m.Called(ctx, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.S3Credential), args.Error(1)
+}
+func (m *MockS3CredentialService) ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.S3Credential), args.Error(1)
+}
+func (m *MockS3CredentialService) DeleteCredential(ctx context.Context, userID, accessKeyID string) error {
+	return m.Called(ctx, userID, accessKeyID).Error(0)
+}
+func (m *MockS3CredentialService) Lookup(ctx context.Context, accessKeyID string) (*model.S3Credential, error) {
+	args := m.Called(ctx, accessKeyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.S3Credential), args.Error(1)
+}
+
+func TestS3CredentialHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := jwt.MapClaims{"sub": "user-1", "role": "finance"}
+
+	testCases := []struct {
+		name               string
+		method             string
+		path               string
+		setupMock          func(mockService *MockS3CredentialService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:   "Success - Create returns secret once",
+			method: http.MethodPost,
+			path:   "/files/s3-credentials",
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("CreateCredential", mock.Anything, claims).Return(&model.S3Credential{AccessKeyID: "PRSMTEST", SecretAccessKey: "secret", UserID: "user-1"}, nil).Once()
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `"secret_access_key":"secret"`,
+		},
+		{
+			name:   "Failure - Too many credentials",
+			method: http.MethodPost,
+			path:   "/files/s3-credentials",
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("CreateCredential", mock.Anything, claims).Return(nil, service.ErrTooManyS3Credentials).Once()
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name:   "Failure - Token without exp or jti",
+			method: http.MethodPost,
+			path:   "/files/s3-credentials",
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("CreateCredential", mock.Anything, claims).Return(nil, service.ErrS3CredentialTokenInvalid).Once()
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Success - List own credentials",
+			method: http.MethodGet,
+			path:   "/files/s3-credentials",
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("ListCredentials", mock.Anything, "user-1").Return([]model.S3Credential{{AccessKeyID: "PRSMTEST", UserID: "user-1"}}, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"access_key_id":"PRSMTEST"`,
+		},
+		{
+			name:   "Failure - Delete unknown key",
+			method: http.MethodDelete,
+			path:   "/files/s3-credentials/PRSMOTHER",
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("DeleteCredential", mock.Anything, "user-1", "PRSMOTHER").Return(repository.ErrNotFound).Once()
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:   "Success - Delete own key",
+			method: http.MethodDelete,
+			path:   "/files/s3-credentials/PRSMTEST",
+			setupMock: func(mockService *MockS3CredentialService) {
+				mockService.On("DeleteCredential", mock.Anything, "user-1", "PRSMTEST").Return(nil).Once()
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockS3CredentialService)
+			tc.setupMock(mockService)
+			h := NewS3CredentialHandler(mockService)
+
+			router := gin.New()
+			files := router.Group("/files", func(c *gin.Context) {
+				c.Set("user_id", "user-1")
+				c.Set("claims", claims)
+				c.Next()
+			})
+			files.POST("/s3-credentials", h.CreateCredential)
+			files.GET("/s3-credentials", h.ListCredentials)
+			files.DELETE("/s3-credentials/:accessKeyId", h.DeleteCredential)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// Search menangani GET /files/search?q=...&limit=...&offset=... dan hanya
+// mengembalikan file yang dapat dibaca pemanggil.
+func (h *SearchHandler) Search(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return
+	}
+	query := repository.SearchQuery{Text: c.Query("q")}
+	var err error
+	if query.Limit, err = queryInt(c, "limit"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter limit tidak valid", "details": err.Error()})
+		return
+	}
+	if query.Offset, err = queryInt(c, "offset"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter offset tidak valid", "details": err.Error()})
+		return
+	}
+
+	results, err := h.searchService.Search(c.Request.Context(), query, claims)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptySearchQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter q wajib diisi", "details": err.Error()})
+			return
+		}
+		log.Error().Err(err).Str("query", query.Text).Msg("Gagal menjalankan pencarian file")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menjalankan pencarian"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// queryInt membaca parameter query bilangan bulat non-negatif; parameter yang
+// tidak ada bernilai 0.
+func queryInt(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errors.New(name + " harus bilangan bulat non-negatif")
+	}
+	return n, nil
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchService struct {
+	mock.Mock
+}
+
+func (m *MockSearchService) Search(ctx context.Context, query repository.SearchQuery, claims jwt.MapClaims) ([]service.SearchResult, error) {
+	args := m.Called(ctx, query, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.SearchResult), args.Error(1)
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := jwt.MapClaims{"sub": "user-1", "role": "user"}
+
+	testCases := []struct {
+		name               string
+		path               string
+		setupMock          func(mockService *MockSearchService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "Success - Results with snippet",
+			path: "/files/search?q=anggaran&limit=5&offset=10",
+			setupMock: func(mockService *MockSearchService) {
+				results := []service.SearchResult{{File: &model.FileMetadata{ID: "file-1"}, Rank: 0.5, Snippet: "<mark>anggaran</mark> 2025"}}
+				mockService.On("Search", mock.Anything, repository.SearchQuery{Text: "anggaran", Limit: 5, Offset: 10}, claims).Return(results, nil).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"snippet":"\u003cmark\u003eanggaran\u003c/mark\u003e 2025"`,
+		},
+		{
+			name: "Failure - Empty query",
+			path: "/files/search?q=",
+			setupMock: func(mockService *MockSearchService) {
+				mockService.On("Search", mock.Anything, repository.SearchQuery{}, claims).Return(nil, service.ErrEmptySearchQuery).Once()
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "Parameter q wajib diisi",
+		},
+		{
+			name:               "Failure - Invalid limit",
+			path:               "/files/search?q=anggaran&limit=-1",
+			setupMock:          func(mockService *MockSearchService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "Parameter limit tidak valid",
+		},
+		{
+			name:               "Failure - Invalid offset",
+			path:               "/files/search?q=anggaran&offset=abc",
+			setupMock:          func(mockService *MockSearchService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "Parameter offset tidak valid",
+		},
+		{
+			name: "Failure - Repository error",
+			path: "/files/search?q=anggaran",
+			setupMock: func(mockService *MockSearchService) {
+				mockService.On("Search", mock.Anything, repository.SearchQuery{Text: "anggaran"}, claims).Return(nil, errors.New("db down")).Once()
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "Gagal menjalankan pencarian",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockSearchService)
+			tc.setupMock(mockService)
+			h := NewSearchHandler(mockService)
+
+			router := gin.New()
+			router.GET("/files/search", func(c *gin.Context) {
+				c.Set("user_id", "user-1")
+				c.Set("claims", claims)
+				c.Next()
+			}, h.Search)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Package imaging mengubah ukuran, memotong dan mengonversi format gambar
+// JPEG, PNG dan GIF hanya dengan codec pustaka standar Go.
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// Mode penyesuaian gambar ke kotak Width×Height.
+const (
+	// FitContain memperkecil gambar agar muat di dalam kotak dengan rasio
+	// aspek tetap. Gambar tidak pernah diperbesar.
+	FitContain = "contain"
+	// FitCover mengisi kotak dengan rasio aspek tetap dan memotong bagian
+	// tengah yang berlebih.
+	FitCover = "cover"
+	// FitFill meregangkan gambar tepat ke ukuran kotak.
+	FitFill = "fill"
+)
+
+// Format keluaran.
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+// DefaultQuality adalah kualitas JPEG jika parameter quality tidak diisi.
+const DefaultQuality = 85
+
+var (
+	// ErrInvalidOptions dikembalikan (terbungkus) untuk parameter transformasi
+	// yang tidak valid.
+	ErrInvalidOptions = errors.New("parameter transformasi tidak valid")
+	// ErrUnsupported dikembalikan untuk tipe sumber yang tidak dapat didekode.
+	ErrUnsupported = errors.New("tipe gambar tidak didukung")
+	// ErrTooLarge dikembalikan jika jumlah piksel gambar sumber melebihi batas.
+	ErrTooLarge = errors.New("gambar sumber terlalu besar untuk diproses")
+	// ErrInvalidImage dikembalikan jika gambar sumber tidak dapat didekode.
+	ErrInvalidImage = errors.New("gambar sumber tidak dapat didekode")
+)
+
+var decoders = map[string]struct {
+	config func(io.Reader) (image.Config, error)
+	decode func(io.Reader) (image.Image, error)
+}{
+	"image/jpeg": {jpeg.DecodeConfig, jpeg.Decode},
+	"image/png":  {png.DecodeConfig, png.Decode},
+	"image/gif":  {gif.DecodeConfig, gif.Decode},
+}
+
+// Supports melaporkan apakah gambar bertipe MIME mimeType dapat dirender.
+func Supports(mimeType string) bool {
+	_, ok := decoders[mimeType]
+	return ok
+}
+
+// Options adalah parameter satu transformasi. Width atau Height nol berarti
+// dihitung dari rasio aspek sumber.
+type Options struct {
+	Width  int
+	Height int
+	Fit    string
+	// Format kosong berarti sama dengan sumber (GIF dikeluarkan sebagai PNG).
+	Format string
+	// Quality hanya berlaku untuk JPEG.
+	Quality int
+}
+
+// ParseOptions membaca parameter w, h, fit, format dan quality. Lebar dan
+// tinggi dibatasi maxDimension; minimal salah satunya harus diisi.
+func ParseOptions(query url.Values, maxDimension int) (Options, error) {
+	opts := Options{Fit: FitContain, Format: query.Get("format"), Quality: DefaultQuality}
+	var err error
+	if opts.Width, err = intParam(query, "w", 0, maxDimension); err != nil {
+		return Options{}, err
+	}
+	if opts.Height, err = intParam(query, "h", 0, maxDimension); err != nil {
+		return Options{}, err
+	}
+	if opts.Width == 0 && opts.Height == 0 {
+		return Options{}, fmt.Errorf("%w: w atau h harus diisi", ErrInvalidOptions)
+	}
+	if fit := query.Get("fit"); fit != "" {
+		opts.Fit = fit
+	}
+	switch opts.Fit {
+	case FitContain:
+	case FitCover, FitFill:
+		if opts.Width == 0 || opts.Height == 0 {
+			return Options{}, fmt.Errorf("%w: fit=%s membutuhkan w dan h", ErrInvalidOptions, opts.Fit)
+		}
+	default:
+		return Options{}, fmt.Errorf("%w: fit harus contain, cover atau fill", ErrInvalidOptions)
+	}
+	switch opts.Format {
+	case "", FormatJPEG, FormatPNG:
+	default:
+		return Options{}, fmt.Errorf("%w: format harus jpeg atau png", ErrInvalidOptions)
+	}
+	if query.Has("quality") {
+		if opts.Quality, err = intParam(query, "quality", 1, 100); err != nil {
+			return Options{}, err
+		}
+	}
+	return opts, nil
+}
+
+func intParam(query url.Values, name string, minValue, maxValue int) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < minValue || value > maxValue {
+		return 0, fmt.Errorf("%w: %s harus bilangan bulat %d-%d", ErrInvalidOptions, name, minValue, maxValue)
+	}
+	return value, nil
+}
+
+// Query mengembalikan parameter opts dalam bentuk kanonis: urutan tetap dan
+// tanpa nilai kosong, sehingga permintaan yang setara menghasilkan string
+// yang sama untuk tanda tangan dan kunci cache.
+func (o Options) Query() string {
+	query := url.Values{}
+	if o.Width > 0 {
+		query.Set("w", strconv.Itoa(o.Width))
+	}
+	if o.Height > 0 {
+		query.Set("h", strconv.Itoa(o.Height))
+	}
+	query.Set("fit", o.Fit)
+	if o.Format != "" {
+		query.Set("format", o.Format)
+	}
+	if o.Format != FormatPNG {
+		query.Set("quality", strconv.Itoa(o.Quality))
+	}
+	// url.Values.Encode mengurutkan key sehingga hasilnya kanonis.
+	return query.Encode()
+}
+
+// Resolve mengisi Format kosong berdasarkan tipe MIME sumber dan mengosongkan
+// Quality untuk PNG, sehingga Query menjadi kunci cache hasil render.
+func (o Options) Resolve(sourceMime string) Options {
+	if o.Format == "" {
+		o.Format = FormatPNG
+		if sourceMime == "image/jpeg" {
+			o.Format = FormatJPEG
+		}
+	}
+	if o.Format == FormatPNG {
+		o.Quality = 0
+	}
+	return o
+}
+
+// ContentType mengembalikan tipe MIME keluaran opts yang sudah di-Resolve.
+func (o Options) ContentType() string {
+	if o.Format == FormatJPEG {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// Render mendekode gambar sourceMime dari content, menerapkan opts (yang
+// sudah di-Resolve) dan menulis hasilnya ke dst. Gambar dengan lebih dari
+// maxSourcePixels piksel ditolak sebelum didekode; 0 berarti tanpa batas.
+// Hanya frame pertama GIF yang dipakai.
+func Render(dst io.Writer, content io.ReaderAt, size int64, sourceMime string, opts Options, maxSourcePixels int64) error {
+	codec, ok := decoders[sourceMime]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupported, sourceMime)
+	}
+	config, err := codec.config(io.NewSectionReader(content, 0, size))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return fmt.Errorf("%w: dimensi %dx%d", ErrInvalidImage, config.Width, config.Height)
+	}
+	if maxSourcePixels > 0 && int64(config.Width)*int64(config.Height) > maxSourcePixels {
+		return fmt.Errorf("%w: %dx%d piksel", ErrTooLarge, config.Width, config.Height)
+	}
+	src, err := codec.decode(io.NewSectionReader(content, 0, size))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	out := Transform(src, opts)
+	if opts.Format == FormatJPEG {
+		return jpeg.Encode(dst, flatten(out), &jpeg.Options{Quality: opts.Quality})
+	}
+	return png.Encode(dst, out)
+}
+
+// Transform mengubah ukuran src sesuai Width, Height dan Fit.
+func Transform(src image.Image, opts Options) *image.RGBA {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	crop := bounds
+	w, h := opts.Width, opts.Height
+
+	switch opts.Fit {
+	case FitFill:
+	case FitCover:
+		// Potong bagian tengah sumber dengan rasio aspek kotak, lalu
+		// ubah ukurannya tepat ke kotak.
+		if sw*h > sh*w {
+			cw := max(1, sh*w/h)
+			crop.Min.X += (sw - cw) / 2
+			crop.Max.X = crop.Min.X + cw
+		} else {
+			ch := max(1, sw*h/w)
+			crop.Min.Y += (sh - ch) / 2
+			crop.Max.Y = crop.Min.Y + ch
+		}
+	default:
+		scale := 1.0
+		if w > 0 {
+			scale = min(scale, float64(w)/float64(sw))
+		}
+		if h > 0 {
+			scale = min(scale, float64(h)/float64(sh))
+		}
+		w = max(1, int(float64(sw)*scale+0.5))
+		h = max(1, int(float64(sh)*scale+0.5))
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, crop.Min, draw.Src)
+	if rgba.Bounds().Dx() == w && rgba.Bounds().Dy() == h {
+		return rgba
+	}
+	return resize(rgba, w, h)
+}
+
+// flatten menempatkan gambar di atas latar putih karena JPEG tidak memiliki
+// kanal alfa; tanpa ini piksel transparan menjadi hitam.
+func flatten(img *image.RGBA) *image.RGBA {
+	opaque := true
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xFF {
+			opaque = false
+			break
+		}
+	}
+	if opaque {
+		return img
+	}
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Over)
+	return out
+}
@@ -0,0 +1,250 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expected      Options
+		expectedQuery string
+		expectedError string
+	}{
+		{
+			name:          "Width only uses defaults",
+			query:         "w=64",
+			expected:      Options{Width: 64, Fit: FitContain, Quality: DefaultQuality},
+			expectedQuery: "fit=contain&quality=85&w=64",
+		},
+		{
+			name:          "Cover with format and quality",
+			query:         "quality=70&format=jpeg&fit=cover&h=32&w=32",
+			expected:      Options{Width: 32, Height: 32, Fit: FitCover, Format: FormatJPEG, Quality: 70},
+			expectedQuery: "fit=cover&format=jpeg&h=32&quality=70&w=32",
+		},
+		{
+			name:          "PNG ignores quality in canonical query",
+			query:         "w=256&format=png&quality=10",
+			expected:      Options{Width: 256, Fit: FitContain, Format: FormatPNG, Quality: 10},
+			expectedQuery: "fit=contain&format=png&w=256",
+		},
+		{name: "Missing dimensions", query: "fit=contain", expectedError: "w atau h"},
+		{name: "Dimension above limit", query: "w=5000", expectedError: "w harus"},
+		{name: "Negative dimension", query: "h=-1", expectedError: "h harus"},
+		{name: "Cover needs both dimensions", query: "w=64&fit=cover", expectedError: "membutuhkan w dan h"},
+		{name: "Unknown fit", query: "w=64&fit=stretch", expectedError: "fit harus"},
+		{name: "Unknown format", query: "w=64&format=webp", expectedError: "format harus"},
+		{name: "Quality out of range", query: "w=64&quality=0", expectedError: "quality harus"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+			opts, err := ParseOptions(query, 2048)
+			if tc.expectedError != "" {
+				require.ErrorIs(t, err, ErrInvalidOptions)
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, opts)
+			assert.Equal(t, tc.expectedQuery, opts.Query())
+		})
+	}
+}
+
+func TestOptions_Resolve(t *testing.T) {
+	jpegDefault := Options{Width: 32, Fit: FitContain, Quality: 80}.Resolve("image/jpeg")
+	assert.Equal(t, FormatJPEG, jpegDefault.Format)
+	assert.Equal(t, 80, jpegDefault.Quality)
+	assert.Equal(t, "image/jpeg", jpegDefault.ContentType())
+
+	gifDefault := Options{Width: 32, Fit: FitContain, Quality: 80}.Resolve("image/gif")
+	assert.Equal(t, FormatPNG, gifDefault.Format, "GIF dikeluarkan sebagai PNG")
+	assert.Zero(t, gifDefault.Quality)
+	assert.Equal(t, "image/png", gifDefault.ContentType())
+}
+
+func TestTransform_Dimensions(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected image.Point
+	}{
+		{name: "Contain within box", opts: Options{Width: 100, Height: 100, Fit: FitContain}, expected: image.Pt(100, 50)},
+		{name: "Contain by height", opts: Options{Height: 20, Fit: FitContain}, expected: image.Pt(40, 20)},
+		{name: "Contain never enlarges", opts: Options{Width: 1000, Fit: FitContain}, expected: image.Pt(400, 200)},
+		{name: "Cover crops to box", opts: Options{Width: 64, Height: 64, Fit: FitCover}, expected: image.Pt(64, 64)},
+		{name: "Cover can enlarge", opts: Options{Width: 300, Height: 600, Fit: FitCover}, expected: image.Pt(300, 600)},
+		{name: "Fill stretches", opts: Options{Width: 50, Height: 80, Fit: FitFill}, expected: image.Pt(50, 80)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Transform(src, tc.opts).Bounds().Size())
+		})
+	}
+}
+
+func TestTransform_CoverKeepsCenter(t *testing.T) {
+	// Sisi kiri dan kanan merah, tengah biru: cover persegi hanya menyisakan
+	// bagian tengah.
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := range 100 {
+		for x := range 300 {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 100 && x < 200 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			src.SetRGBA(x, y, c)
+		}
+	}
+	out := Transform(src, Options{Width: 10, Height: 10, Fit: FitCover})
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, out.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, out.RGBAAt(9, 9))
+}
+
+func TestResize_AveragesPixels(t *testing.T) {
+	// Papan catur hitam-putih yang diperkecil harus menjadi abu-abu rata.
+	src := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := range 64 {
+		for x := range 64 {
+			v := uint8(0)
+			if (x+y)%2 == 0 {
+				v = 255
+			}
+			src.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	out := resize(src, 8, 8)
+	for _, c := range []color.RGBA{out.RGBAAt(0, 0), out.RGBAAt(4, 4), out.RGBAAt(7, 7)} {
+		assert.InDelta(t, 127, int(c.R), 3)
+		assert.Equal(t, uint8(255), c.A)
+	}
+}
+
+func encode(t *testing.T, img image.Image, mimeType string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	switch mimeType {
+	case "image/png":
+		require.NoError(t, png.Encode(&buf, img))
+	case "image/jpeg":
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	case "image/gif":
+		require.NoError(t, gif.Encode(&buf, img, nil))
+	}
+	return buf.Bytes()
+}
+
+func TestRender(t *testing.T) {
+	transparent := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	opaque := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 0x80
+	}
+
+	testCases := []struct {
+		name          string
+		content       []byte
+		sourceMime    string
+		opts          Options
+		maxPixels     int64
+		expectedMime  string
+		expectedSize  image.Point
+		expectedError error
+	}{
+		{
+			name:         "PNG to JPEG",
+			content:      encode(t, transparent, "image/png"),
+			sourceMime:   "image/png",
+			opts:         Options{Width: 10, Fit: FitContain, Format: FormatJPEG, Quality: 80},
+			expectedMime: "image/jpeg",
+			expectedSize: image.Pt(10, 5),
+		},
+		{
+			name:         "JPEG keeps format",
+			content:      encode(t, opaque, "image/jpeg"),
+			sourceMime:   "image/jpeg",
+			opts:         Options{Width: 8, Height: 8, Fit: FitCover, Quality: 80},
+			expectedMime: "image/jpeg",
+			expectedSize: image.Pt(8, 8),
+		},
+		{
+			name:         "GIF becomes PNG",
+			content:      encode(t, opaque, "image/gif"),
+			sourceMime:   "image/gif",
+			opts:         Options{Height: 10, Fit: FitContain},
+			expectedMime: "image/png",
+			expectedSize: image.Pt(20, 10),
+		},
+		{
+			name:          "Source over pixel limit",
+			content:       encode(t, opaque, "image/png"),
+			sourceMime:    "image/png",
+			opts:          Options{Width: 10, Fit: FitContain},
+			maxPixels:     100,
+			expectedError: ErrTooLarge,
+		},
+		{
+			name:          "Unsupported source",
+			content:       []byte("%PDF-1.7"),
+			sourceMime:    "application/pdf",
+			opts:          Options{Width: 10, Fit: FitContain},
+			expectedError: ErrUnsupported,
+		},
+		{
+			name:          "Corrupt source",
+			content:       []byte("\x89PNG\r\n\x1a\nrusak"),
+			sourceMime:    "image/png",
+			opts:          Options{Width: 10, Fit: FitContain},
+			expectedError: ErrInvalidImage,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts.Resolve(tc.sourceMime)
+			var out bytes.Buffer
+			err := Render(&out, bytes.NewReader(tc.content), int64(len(tc.content)), tc.sourceMime, opts, tc.maxPixels)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMime, opts.ContentType())
+
+			decoded, format, err := image.Decode(&out)
+			require.NoError(t, err)
+			assert.Equal(t, opts.Format, format)
+			assert.Equal(t, tc.expectedSize, decoded.Bounds().Size())
+		})
+	}
+}
+
+func TestRender_JPEGFlattensTransparency(t *testing.T) {
+	content := encode(t, image.NewNRGBA(image.Rect(0, 0, 4, 4)), "image/png")
+	var out bytes.Buffer
+	opts := Options{Width: 4, Fit: FitContain, Format: FormatJPEG, Quality: 90}
+	require.NoError(t, Render(&out, bytes.NewReader(content), int64(len(content)), "image/png", opts, 0))
+
+	decoded, err := jpeg.Decode(&out)
+	require.NoError(t, err)
+	r, g, b, _ := decoded.At(1, 1).RGBA()
+	assert.Greater(t, r>>8, uint32(240), "Piksel transparan menjadi putih, bukan hitam")
+	assert.Greater(t, g>>8, uint32(240))
+	assert.Greater(t, b>>8, uint32(240))
+}
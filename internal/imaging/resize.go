@@ -0,0 +1,106 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// contribution adalah bobot piksel sumber [start, start+len(weights)) untuk
+// satu piksel tujuan.
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+// contributions menghitung bobot filter segitiga (bilinear) dari srcLen ke
+// dstLen piksel. Saat memperkecil, lebar filter diperbesar sebanding skala
+// sehingga setiap piksel sumber ikut dirata-rata dan hasilnya tidak aliasing.
+func contributions(srcLen, dstLen int) []contribution {
+	scale := float64(srcLen) / float64(dstLen)
+	support := max(scale, 1)
+	out := make([]contribution, dstLen)
+	for i := range out {
+		center := (float64(i) + 0.5) * scale
+		start := max(int(math.Floor(center-support)), 0)
+		end := min(int(math.Ceil(center+support)), srcLen)
+		weights := make([]float32, 0, end-start)
+		var total float32
+		for j := start; j < end; j++ {
+			w := float32(1 - math.Abs(float64(j)+0.5-center)/support)
+			if w < 0 {
+				w = 0
+			}
+			weights = append(weights, w)
+			total += w
+		}
+		if total == 0 {
+			// Tidak terjadi untuk skala yang wajar; ambil piksel terdekat.
+			nearest := min(int(center), srcLen-1)
+			out[i] = contribution{start: nearest, weights: []float32{1}}
+			continue
+		}
+		for k := range weights {
+			weights[k] /= total
+		}
+		out[i] = contribution{start: start, weights: weights}
+	}
+	return out
+}
+
+// resize mengubah ukuran src menjadi w×h dengan dua tahap terpisah
+// (horizontal lalu vertikal). Perhitungan dilakukan pada warna premultiplied
+// agar tepi piksel transparan tidak menimbulkan pinggiran gelap.
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	horizontal := contributions(sw, w)
+	vertical := contributions(sh, h)
+
+	// Tahap horizontal: sh baris × w kolom.
+	tmp := make([]float32, sh*w*4)
+	for y := range sh {
+		row := src.Pix[y*src.Stride : y*src.Stride+sw*4]
+		for x, c := range horizontal {
+			var r, g, b, a float32
+			for k, weight := range c.weights {
+				p := row[(c.start+k)*4:]
+				r += float32(p[0]) * weight
+				g += float32(p[1]) * weight
+				b += float32(p[2]) * weight
+				a += float32(p[3]) * weight
+			}
+			o := (y*w + x) * 4
+			tmp[o], tmp[o+1], tmp[o+2], tmp[o+3] = r, g, b, a
+		}
+	}
+
+	// Tahap vertikal: h baris × w kolom.
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y, c := range vertical {
+		for x := range w {
+			var r, g, b, a float32
+			for k, weight := range c.weights {
+				o := ((c.start+k)*w + x) * 4
+				r += tmp[o] * weight
+				g += tmp[o+1] * weight
+				b += tmp[o+2] * weight
+				a += tmp[o+3] * weight
+			}
+			p := dst.Pix[y*dst.Stride+x*4:]
+			alpha := clamp(a)
+			// Nilai premultiplied tidak boleh melebihi alfanya.
+			p[0], p[1], p[2], p[3] = min(clamp(r), alpha), min(clamp(g), alpha), min(clamp(b), alpha), alpha
+		}
+	}
+	return dst
+}
+
+func clamp(v float32) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
@@ -0,0 +1,190 @@
+// Package integrity menghitung dan memverifikasi checksum konten file agar
+// kerusakan diam-diam di disk atau bucket dapat terdeteksi.
+package integrity
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// Algoritma checksum tambahan yang dapat dihitung selain SHA-256.
+const (
+	MD5    = "md5"
+	CRC32C = "crc32c"
+)
+
+// ErrChecksumMismatch dikembalikan saat konten yang dibaca tidak cocok dengan
+// checksum yang tersimpan.
+var ErrChecksumMismatch = errors.New("checksum konten tidak cocok")
+
+// Sums berisi checksum konten dalam hex. MD5 dan CRC32C kosong jika tidak
+// diminta.
+type Sums struct {
+	SHA256 string
+	MD5    string
+	CRC32C string
+}
+
+// Hasher menghitung SHA-256 dan checksum tambahan sekaligus dari konten yang
+// ditulis kepadanya.
+type Hasher struct {
+	sha256 hash.Hash
+	md5    hash.Hash
+	crc32c hash.Hash
+	w      io.Writer
+}
+
+// NewHasher membuat Hasher. SHA-256 selalu dihitung; extra boleh berisi MD5
+// dan/atau CRC32C. Nama lain ditolak.
+func NewHasher(extra ...string) (*Hasher, error) {
+	h := &Hasher{sha256: sha256.New()}
+	writers := []io.Writer{h.sha256}
+	for _, algorithm := range extra {
+		switch strings.ToLower(strings.TrimSpace(algorithm)) {
+		case MD5:
+			if h.md5 == nil {
+				h.md5 = md5.New()
+				writers = append(writers, h.md5)
+			}
+		case CRC32C:
+			if h.crc32c == nil {
+				h.crc32c = crc32.New(crc32.MakeTable(crc32.Castagnoli))
+				writers = append(writers, h.crc32c)
+			}
+		default:
+			return nil, fmt.Errorf("algoritma checksum tidak didukung: %s", algorithm)
+		}
+	}
+	h.w = io.MultiWriter(writers...)
+	return h, nil
+}
+
+func (h *Hasher) Write(p []byte) (int, error) {
+	return h.w.Write(p)
+}
+
+// Sums mengembalikan checksum dari semua konten yang sudah ditulis.
+func (h *Hasher) Sums() Sums {
+	sums := Sums{SHA256: hex.EncodeToString(h.sha256.Sum(nil))}
+	if h.md5 != nil {
+		sums.MD5 = hex.EncodeToString(h.md5.Sum(nil))
+	}
+	if h.crc32c != nil {
+		sums.CRC32C = hex.EncodeToString(h.crc32c.Sum(nil))
+	}
+	return sums
+}
+
+// Compute membaca seluruh r dan mengembalikan checksum-nya.
+func Compute(r io.Reader, extra ...string) (Sums, error) {
+	h, err := NewHasher(extra...)
+	if err != nil {
+		return Sums{}, err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return Sums{}, err
+	}
+	return h.Sums(), nil
+}
+
+// DigestHeader mengubah SHA-256 hex menjadi nilai header Digest (RFC 3230),
+// mis. "sha-256=X48E9q...".
+func DigestHeader(sha256Hex string) string {
+	return "sha-256=" + hexToBase64(sha256Hex)
+}
+
+// ContentMD5Header mengubah MD5 hex menjadi nilai header Content-MD5 (base64).
+func ContentMD5Header(md5Hex string) string {
+	return hexToBase64(md5Hex)
+}
+
+func hexToBase64(value string) string {
+	raw, err := hex.DecodeString(value)
+	if err != nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// verifyingReader menghitung SHA-256 konten saat dibaca. Bagian terakhir
+// konten baru diserahkan setelah checksum cocok, sehingga penerima konten
+// yang rusak tidak pernah mendapatkannya secara utuh.
+type verifyingReader struct {
+	r        io.ReadCloser
+	hash     hash.Hash
+	expected string
+	size     int64
+	read     int64
+	err      error
+}
+
+// NewVerifyingReader membungkus r yang seharusnya berisi size byte dengan
+// SHA-256 expectedSHA256 (hex). Jika konten lebih panjang, lebih pendek, atau
+// checksum-nya berbeda, Read mengembalikan ErrChecksumMismatch tanpa
+// menyerahkan potongan terakhir konten.
+func NewVerifyingReader(r io.ReadCloser, size int64, expectedSHA256 string) io.ReadCloser {
+	return &verifyingReader{r: r, hash: sha256.New(), expected: strings.ToLower(expectedSHA256), size: size}
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	n, err := v.r.Read(p)
+	v.hash.Write(p[:n])
+	v.read += int64(n)
+
+	switch {
+	case v.read > v.size:
+		v.err = fmt.Errorf("%w: konten lebih panjang dari %d byte", ErrChecksumMismatch, v.size)
+	case v.read == v.size:
+		if actual := hex.EncodeToString(v.hash.Sum(nil)); actual != v.expected {
+			v.err = fmt.Errorf("%w: sha256 %s, seharusnya %s", ErrChecksumMismatch, actual, v.expected)
+		} else if trailing, probeErr := v.probeTrailing(err); probeErr != nil {
+			return n, probeErr
+		} else if trailing {
+			v.err = fmt.Errorf("%w: konten lebih panjang dari %d byte", ErrChecksumMismatch, v.size)
+		} else {
+			v.err = io.EOF
+			return n, nil
+		}
+	case errors.Is(err, io.EOF):
+		v.err = fmt.Errorf("%w: konten terpotong di %d dari %d byte", ErrChecksumMismatch, v.read, v.size)
+	case err != nil:
+		return n, err
+	default:
+		return n, nil
+	}
+	return 0, v.err
+}
+
+// probeTrailing memastikan tidak ada konten setelah size byte sebelum
+// potongan terakhir diserahkan. err adalah error dari Read terakhir.
+func (v *verifyingReader) probeTrailing(err error) (bool, error) {
+	var probe [1]byte
+	for {
+		switch {
+		case errors.Is(err, io.EOF):
+			return false, nil
+		case err != nil:
+			return false, err
+		}
+		var n int
+		n, err = v.r.Read(probe[:])
+		if n > 0 {
+			return true, nil
+		}
+	}
+}
+
+func (v *verifyingReader) Close() error {
+	return v.r.Close()
+}
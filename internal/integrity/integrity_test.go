@@ -0,0 +1,97 @@
+package integrity
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "hello world"
+
+// SHA-256, MD5 dan CRC32C dari content.
+const (
+	contentSHA256 = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	contentMD5    = "5eb63bbbe01eeed093cb22bb8f5acdc3"
+	contentCRC32C = "c99465aa"
+)
+
+func TestCompute(t *testing.T) {
+	testCases := []struct {
+		name     string
+		extra    []string
+		expected Sums
+	}{
+		{name: "SHA-256 only", expected: Sums{SHA256: contentSHA256}},
+		{name: "With MD5", extra: []string{MD5}, expected: Sums{SHA256: contentSHA256, MD5: contentMD5}},
+		{name: "With CRC32C and duplicates", extra: []string{" CRC32C", "crc32c"}, expected: Sums{SHA256: contentSHA256, CRC32C: contentCRC32C}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sums, err := Compute(strings.NewReader(content), tc.extra...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, sums)
+		})
+	}
+
+	t.Run("Unknown algorithm is rejected", func(t *testing.T) {
+		_, err := Compute(strings.NewReader(content), "sha1")
+		assert.ErrorContains(t, err, "sha1")
+	})
+}
+
+func TestHeaders(t *testing.T) {
+	shaSum := sha256.Sum256([]byte(content))
+	md5Sum := md5.Sum([]byte(content))
+	assert.Equal(t, "sha-256=uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", DigestHeader(hex.EncodeToString(shaSum[:])))
+	assert.Equal(t, "XrY7u+Ae7tCTyyK7j1rNww==", ContentMD5Header(hex.EncodeToString(md5Sum[:])))
+	assert.Empty(t, ContentMD5Header("bukan-hex"))
+}
+
+func TestVerifyingReader(t *testing.T) {
+	testCases := []struct {
+		name        string
+		stored      string
+		size        int64
+		checksum    string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "Matching content", stored: content, size: int64(len(content)), expected: content},
+		{name: "Corrupted content withholds the last chunk", stored: "hello wOrld", size: int64(len(content)), expected: "hello wOrl", expectedErr: true},
+		{name: "Truncated content", stored: "hello", size: int64(len(content)), expected: "hello", expectedErr: true},
+		{name: "Longer content", stored: content + "!", size: int64(len(content)), expected: content[:len(content)-1], expectedErr: true},
+		{name: "Empty content", stored: "", size: 0, checksum: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", expected: ""},
+		{name: "Expected checksum in upper case", stored: content, size: int64(len(content)), checksum: strings.ToUpper(contentSHA256), expected: content},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checksum := tc.checksum
+			if checksum == "" {
+				checksum = contentSHA256
+			}
+			// OneByteReader memastikan setiap Read hanya menyerahkan satu byte
+			// sehingga terlihat jelas byte mana yang ditahan.
+			reader := NewVerifyingReader(io.NopCloser(iotest.OneByteReader(strings.NewReader(tc.stored))), tc.size, checksum)
+
+			got, err := io.ReadAll(reader)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, ErrChecksumMismatch)
+				_, again := reader.Read(make([]byte, 1))
+				assert.ErrorIs(t, again, ErrChecksumMismatch, "Error harus tetap dikembalikan pada Read berikutnya")
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, string(got))
+			assert.NoError(t, reader.Close())
+		})
+	}
+}
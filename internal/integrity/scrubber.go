@@ -0,0 +1,111 @@
+package integrity
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// ScrubResult merangkum satu putaran scrubber.
+type ScrubResult struct {
+	Checked   int
+	Corrupted int
+	// Skipped adalah file yang gagal dibaca karena error sementara dan akan
+	// diperiksa lagi pada putaran berikutnya.
+	Skipped int
+}
+
+// Scrubber secara berkala menghitung ulang SHA-256 objek di storage dan
+// membandingkannya dengan checksum yang tersimpan di metadata.
+type Scrubber struct {
+	repo      repository.IntegrityRepository
+	storage   storage.Storage
+	batchSize int
+	// maxAge adalah selang minimal sebelum file yang sama diperiksa ulang.
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+func NewScrubber(repo repository.IntegrityRepository, storage storage.Storage, batchSize int, maxAge time.Duration) *Scrubber {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &Scrubber{repo: repo, storage: storage, batchSize: batchSize, maxAge: maxAge, now: time.Now}
+}
+
+// Run menjalankan ScrubOnce setiap interval sampai ctx dibatalkan.
+func (s *Scrubber) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.ScrubOnce(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Scrubber checksum gagal")
+				continue
+			}
+			if result.Checked > 0 {
+				log.Info().Int("checked", result.Checked).Int("corrupted", result.Corrupted).Int("skipped", result.Skipped).Msg("Scrubber checksum selesai")
+			}
+		}
+	}
+}
+
+// ScrubOnce memeriksa satu batch file yang paling lama belum diperiksa.
+// Objek yang hilang dari storage dicatat sebagai rusak.
+func (s *Scrubber) ScrubOnce(ctx context.Context) (ScrubResult, error) {
+	var result ScrubResult
+	files, err := s.repo.ListForScrub(ctx, s.now().Add(-s.maxAge), s.batchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		ok, err := s.verify(ctx, file)
+		if err != nil {
+			log.Warn().Err(err).Str("file_id", file.ID).Msg("Scrubber gagal membaca file, dicoba lagi nanti")
+			result.Skipped++
+			continue
+		}
+		if !ok {
+			log.Error().Str("file_id", file.ID).Str("storage_path", file.StoragePath).Msg("Konten file rusak atau hilang: checksum tidak cocok")
+			result.Corrupted++
+		}
+		if err := s.repo.RecordScrub(ctx, file.ID, ok, s.now()); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return result, err
+		}
+		result.Checked++
+	}
+	return result, nil
+}
+
+// verify melaporkan apakah konten file cocok dengan checksum-nya. Error hanya
+// dikembalikan untuk kegagalan baca yang bukan bukti kerusakan.
+func (s *Scrubber) verify(ctx context.Context, file *model.FileMetadata) (bool, error) {
+	reader, err := s.storage.Get(ctx, file.StoragePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	_, err = io.Copy(io.Discard, NewVerifyingReader(reader, file.SizeBytes, file.ChecksumSHA256))
+	if errors.Is(err, ErrChecksumMismatch) {
+		return false, nil
+	}
+	return err == nil, err
+}
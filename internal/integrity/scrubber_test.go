@@ -0,0 +1,59 @@
+package integrity
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubber_ScrubOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	store := storage.NewMemoryStorage()
+	size := int64(len(content))
+
+	files := []*model.FileMetadata{
+		{ID: "utuh", StoragePath: "utuh.txt", SizeBytes: size, ChecksumSHA256: contentSHA256},
+		{ID: "rusak", StoragePath: "rusak.txt", SizeBytes: size, ChecksumSHA256: contentSHA256},
+		{ID: "hilang", StoragePath: "hilang.txt", SizeBytes: size, ChecksumSHA256: contentSHA256},
+		{ID: "tanpa-checksum", StoragePath: "tanpa-checksum.txt", SizeBytes: size},
+	}
+	for _, file := range files {
+		require.NoError(t, repo.Create(ctx, file, nil))
+	}
+	require.NoError(t, store.Save(ctx, "utuh.txt", strings.NewReader(content)))
+	require.NoError(t, store.Save(ctx, "rusak.txt", strings.NewReader("hello wOrld")))
+	require.NoError(t, store.Save(ctx, "tanpa-checksum.txt", strings.NewReader(content)))
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	scrubber := NewScrubber(repo, store, 10, 24*time.Hour)
+	scrubber.now = func() time.Time { return now }
+
+	result, err := scrubber.ScrubOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ScrubResult{Checked: 3, Corrupted: 2}, result)
+
+	for id, corrupted := range map[string]bool{"utuh": false, "rusak": true, "hilang": true} {
+		metadata, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, metadata.ChecksumVerifiedAt, id)
+		assert.True(t, metadata.ChecksumVerifiedAt.Equal(now), id)
+		assert.Equal(t, corrupted, metadata.ChecksumMismatchAt != nil, id)
+	}
+
+	result, err = scrubber.ScrubOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, result.Checked, "File yang baru diperiksa tidak boleh diperiksa ulang sebelum maxAge")
+
+	now = now.Add(25 * time.Hour)
+	result, err = scrubber.ScrubOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Checked)
+}
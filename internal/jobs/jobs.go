@@ -0,0 +1,266 @@
+// Package jobs menjalankan job latar belakang dari antrean JobRepository:
+// handler bertipe per jenis job, batas konkurensi per jenis, retry dengan
+// backoff eksponensial, dan pengosongan antrean yang tertib saat shutdown.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// Handler memproses satu job. Error membuat job dicoba lagi dengan backoff
+// sampai Options.MaxAttempts, kecuali dibungkus Permanent. Handler harus
+// berhenti saat ctx dibatalkan (timeout atau shutdown).
+type Handler func(ctx context.Context, job *model.Job) error
+
+// permanentError menandai error yang tidak akan berhasil jika dicoba lagi.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent membungkus err agar job langsung gagal tanpa retry, mis. payload
+// yang tidak valid.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Options mengatur Runner. Nilai nol diganti default.
+type Options struct {
+	// PollInterval adalah jeda antar pengambilan job jika antrean kosong.
+	PollInterval time.Duration
+	// MaxAttempts adalah jumlah percobaan sebelum job dinyatakan failed.
+	MaxAttempts int
+	// RetryDelay adalah jeda sebelum percobaan kedua; berlipat dua setiap
+	// percobaan berikutnya hingga MaxRetryDelay.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// Timeout membatasi durasi satu percobaan.
+	Timeout time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.PollInterval <= 0 {
+		o.PollInterval = 2 * time.Second
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = 30 * time.Second
+	}
+	if o.MaxRetryDelay <= 0 {
+		o.MaxRetryDelay = time.Hour
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 5 * time.Minute
+	}
+	return o
+}
+
+// leaseGrace ditambahkan ke Timeout sebagai lease job yang sedang berjalan.
+// Setelah lease habis, job dianggap ditinggal worker yang mati dan dapat
+// diambil worker lain.
+const leaseGrace = time.Minute
+
+// finishTimeout membatasi pencatatan hasil job, yang tetap dilakukan
+// walaupun context job sudah dibatalkan.
+const finishTimeout = 5 * time.Second
+
+// Runner mengambil job dari repository dan menjalankan handler yang
+// terdaftar untuk tipenya.
+type Runner struct {
+	repo    repository.JobRepository
+	opts    Options
+	workers []*worker
+
+	// stopping ditutup saat Shutdown agar worker berhenti mengambil job baru;
+	// jobCtx dibatalkan jika job yang berjalan tidak selesai sebelum batas
+	// waktu Shutdown.
+	stopping   chan struct{}
+	stopOnce   sync.Once
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	pollers    sync.WaitGroup
+	inflight   sync.WaitGroup
+	now        func() time.Time
+}
+
+type worker struct {
+	jobType string
+	handler Handler
+	// slots berkapasitas konkurensi tipe job; satu slot terisi per job yang
+	// sedang berjalan.
+	slots chan struct{}
+}
+
+func NewRunner(repo repository.JobRepository, opts Options) *Runner {
+	jobCtx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		repo:       repo,
+		opts:       opts.withDefaults(),
+		stopping:   make(chan struct{}),
+		jobCtx:     jobCtx,
+		cancelJobs: cancel,
+		now:        time.Now,
+	}
+}
+
+// Register mendaftarkan handler untuk jobType dengan paling banyak
+// concurrency job berjalan bersamaan di proses ini. Harus dipanggil sebelum
+// Start.
+func (r *Runner) Register(jobType string, concurrency int, handler Handler) {
+	r.workers = append(r.workers, &worker{
+		jobType: jobType,
+		handler: handler,
+		slots:   make(chan struct{}, max(concurrency, 1)),
+	})
+}
+
+// Start menjalankan satu poller per tipe job yang terdaftar.
+func (r *Runner) Start() {
+	for _, w := range r.workers {
+		r.pollers.Add(1)
+		go r.poll(w)
+	}
+}
+
+// Shutdown berhenti mengambil job baru lalu menunggu job yang sedang berjalan
+// selesai. Jika ctx berakhir lebih dulu, job yang tersisa dibatalkan dan
+// dikembalikan ke antrean tanpa dihitung sebagai percobaan.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stopping) })
+	r.pollers.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		r.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		r.cancelJobs()
+		return nil
+	case <-ctx.Done():
+	}
+
+	r.cancelJobs()
+	select {
+	case <-done:
+	case <-time.After(finishTimeout):
+		log.Warn().Msg("Sebagian job tidak berhenti saat dibatalkan; job tersebut diambil ulang setelah lease habis")
+	}
+	return ctx.Err()
+}
+
+func (r *Runner) poll(w *worker) {
+	defer r.pollers.Done()
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		r.claim(w)
+		select {
+		case <-r.stopping:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim mengambil job sebanyak slot yang kosong dan menjalankannya, berulang
+// selama antrean masih menyediakan job penuh satu batch.
+func (r *Runner) claim(w *worker) {
+	for {
+		select {
+		case <-r.stopping:
+			return
+		default:
+		}
+		free := cap(w.slots) - len(w.slots)
+		if free == 0 {
+			return
+		}
+		jobs, err := r.repo.Claim(r.jobCtx, w.jobType, free, r.opts.Timeout+leaseGrace)
+		if err != nil {
+			log.Error().Err(err).Str("job_type", w.jobType).Msg("Gagal mengambil job dari antrean")
+			return
+		}
+		for _, job := range jobs {
+			w.slots <- struct{}{}
+			r.inflight.Add(1)
+			go r.execute(w, job)
+		}
+		if len(jobs) < free {
+			return
+		}
+	}
+}
+
+func (r *Runner) execute(w *worker, job *model.Job) {
+	defer func() {
+		<-w.slots
+		r.inflight.Done()
+	}()
+	ctx, cancel := context.WithTimeout(r.jobCtx, r.opts.Timeout)
+	err := runHandler(ctx, w.handler, job)
+	cancel()
+	r.finish(job, err)
+}
+
+// runHandler menjalankan handler dan mengubah panic menjadi error agar satu
+// job yang rusak tidak mematikan worker.
+func runHandler(ctx context.Context, handler Handler, job *model.Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// finish mencatat hasil percobaan: berhasil, dikembalikan ke antrean karena
+// shutdown, dijadwalkan ulang dengan backoff, atau gagal permanen.
+func (r *Runner) finish(job *model.Job, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+	logger := log.With().Str("job_id", job.ID).Str("job_type", job.Type).Int("attempt", job.Attempts).Logger()
+
+	var recordErr error
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		recordErr = r.repo.Complete(ctx, job.ID)
+	case r.jobCtx.Err() != nil:
+		logger.Info().Msg("Job dihentikan karena shutdown, dikembalikan ke antrean")
+		recordErr = r.repo.Release(ctx, job.ID)
+	case errors.As(err, &permanent) || job.Attempts >= r.opts.MaxAttempts:
+		logger.Error().Err(err).Msg("Job gagal permanen")
+		recordErr = r.repo.Fail(ctx, job.ID, err.Error(), nil)
+	default:
+		retryAt := r.now().Add(backoff(r.opts.RetryDelay, r.opts.MaxRetryDelay, job.Attempts))
+		logger.Warn().Err(err).Time("retry_at", retryAt).Msg("Job gagal, dijadwalkan ulang")
+		recordErr = r.repo.Fail(ctx, job.ID, err.Error(), &retryAt)
+	}
+	if recordErr != nil {
+		logger.Error().Err(recordErr).Msg("Gagal mencatat hasil job")
+	}
+}
+
+// backoff mengembalikan jeda sebelum percobaan setelah percobaan ke-attempt:
+// delay, 2×delay, 4×delay, ... dibatasi maxDelay.
+func backoff(delay, maxDelay time.Duration, attempt int) time.Duration {
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOptions memakai interval pendek agar test tidak menunggu lama.
+var testOptions = Options{PollInterval: 5 * time.Millisecond, MaxAttempts: 3, RetryDelay: time.Millisecond, Timeout: time.Second}
+
+func enqueue(t *testing.T, repo repository.JobRepository, jobType string) *model.Job {
+	t.Helper()
+	job := &model.Job{Type: jobType}
+	require.NoError(t, repo.Enqueue(context.Background(), job))
+	return job
+}
+
+func waitForStatus(t *testing.T, repo repository.JobRepository, id, status string) *model.Job {
+	t.Helper()
+	var job *model.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = repo.GetJob(context.Background(), id)
+		return err == nil && job.Status == status
+	}, 2*time.Second, 5*time.Millisecond, "job harus berstatus %s", status)
+	return job
+}
+
+func TestRunner_RunsHandlers(t *testing.T) {
+	testCases := []struct {
+		name             string
+		handler          Handler
+		expectedStatus   string
+		expectedAttempts int
+		expectedError    string
+	}{
+		{
+			name:             "Success",
+			handler:          func(ctx context.Context, job *model.Job) error { return nil },
+			expectedStatus:   model.JobStatusSucceeded,
+			expectedAttempts: 1,
+		},
+		{
+			name: "Retried until success",
+			handler: func(ctx context.Context, job *model.Job) error {
+				if job.Attempts < 2 {
+					return errors.New("sementara")
+				}
+				return nil
+			},
+			expectedStatus:   model.JobStatusSucceeded,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "Failed after max attempts",
+			handler:          func(ctx context.Context, job *model.Job) error { return errors.New("storage mati") },
+			expectedStatus:   model.JobStatusFailed,
+			expectedAttempts: 3,
+			expectedError:    "storage mati",
+		},
+		{
+			name:             "Permanent error is not retried",
+			handler:          func(ctx context.Context, job *model.Job) error { return Permanent(errors.New("payload rusak")) },
+			expectedStatus:   model.JobStatusFailed,
+			expectedAttempts: 1,
+			expectedError:    "payload rusak",
+		},
+		{
+			name:             "Panic becomes failure",
+			handler:          func(ctx context.Context, job *model.Job) error { panic("boom") },
+			expectedStatus:   model.JobStatusFailed,
+			expectedAttempts: 3,
+			expectedError:    "panic: boom",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := repository.NewMemoryJobRepository()
+			job := enqueue(t, repo, "test")
+			runner := NewRunner(repo, testOptions)
+			runner.Register("test", 1, tc.handler)
+			runner.Start()
+			defer func() { require.NoError(t, runner.Shutdown(context.Background())) }()
+
+			finished := waitForStatus(t, repo, job.ID, tc.expectedStatus)
+			assert.Equal(t, tc.expectedAttempts, finished.Attempts)
+			assert.Equal(t, tc.expectedError, finished.LastError)
+			assert.NotNil(t, finished.CompletedAt)
+		})
+	}
+}
+
+func TestRunner_ConcurrencyPerType(t *testing.T) {
+	repo := repository.NewMemoryJobRepository()
+	for range 6 {
+		enqueue(t, repo, "slow")
+	}
+	other := enqueue(t, repo, "fast")
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	runner := NewRunner(repo, testOptions)
+	runner.Register("slow", 2, func(ctx context.Context, job *model.Job) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := peak.Load()
+			if n <= current || peak.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		<-release
+		return nil
+	})
+	runner.Register("fast", 1, func(ctx context.Context, job *model.Job) error { return nil })
+	runner.Start()
+
+	// Tipe lain tetap berjalan walaupun semua slot "slow" terisi.
+	waitForStatus(t, repo, other.ID, model.JobStatusSucceeded)
+	require.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(2), peak.Load(), "Tidak boleh melebihi konkurensi tipe job")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		jobs, err := repo.ListJobs(context.Background(), repository.JobFilter{Type: "slow", Status: model.JobStatusSucceeded})
+		return err == nil && len(jobs) == 6
+	}, 2*time.Second, 5*time.Millisecond)
+	require.NoError(t, runner.Shutdown(context.Background()))
+}
+
+func TestRunner_ShutdownDrainsRunningJobs(t *testing.T) {
+	repo := repository.NewMemoryJobRepository()
+	job := enqueue(t, repo, "test")
+	started := make(chan struct{})
+	runner := NewRunner(repo, testOptions)
+	runner.Register("test", 1, func(ctx context.Context, job *model.Job) error {
+		close(started)
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+	runner.Start()
+	<-started
+
+	require.NoError(t, runner.Shutdown(context.Background()))
+	finished, err := repo.GetJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusSucceeded, finished.Status, "Job yang berjalan harus diselesaikan sebelum shutdown")
+}
+
+func TestRunner_ShutdownTimeoutReleasesJobs(t *testing.T) {
+	repo := repository.NewMemoryJobRepository()
+	job := enqueue(t, repo, "test")
+	started := make(chan struct{})
+	runner := NewRunner(repo, testOptions)
+	runner.Register("test", 1, func(ctx context.Context, job *model.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	runner.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, runner.Shutdown(ctx), context.DeadlineExceeded)
+
+	released, err := repo.GetJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPending, released.Status)
+	assert.Equal(t, 0, released.Attempts, "Job yang dihentikan shutdown tidak dihitung sebagai percobaan")
+
+	pending := enqueue(t, repo, "test")
+	time.Sleep(20 * time.Millisecond)
+	notClaimed, err := repo.GetJob(context.Background(), pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPending, notClaimed.Status, "Job baru tidak diambil setelah shutdown")
+}
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 10 * time.Minute},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, backoff(30*time.Second, 10*time.Minute, tc.attempt), "attempt %d", tc.attempt)
+	}
+}
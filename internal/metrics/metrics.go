@@ -0,0 +1,169 @@
+// Package metrics mendefinisikan metrik Prometheus domain layanan file:
+// byte yang diunggah dan diunduh, penolakan validasi unggahan, latensi
+// storage dan repository, stream yang sedang berjalan, pemakaian storage,
+// serta kedalaman antrean job. Metrik HTTP generik tetap berasal dari
+// ginprometheus; keduanya diekspor di /metrics lewat registry bawaan.
+package metrics
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "prism_file"
+
+// Nilai label result pada metrik latensi.
+const (
+	resultOK       = "ok"
+	resultNotFound = "not_found"
+	resultError    = "error"
+)
+
+// Nilai label direction pada prism_file_active_streams.
+const (
+	directionUpload   = "upload"
+	directionDownload = "download"
+)
+
+var (
+	uploadedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploaded_bytes_total",
+		Help:      "Byte file yang berhasil diunggah, per tipe MIME dan backend storage.",
+	}, []string{"mime_type", "backend"})
+
+	downloadedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "downloaded_bytes_total",
+		Help:      "Byte konten file yang dibaca dari storage, per tipe MIME dan backend storage.",
+	}, []string{"mime_type", "backend"})
+
+	uploadRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_rejections_total",
+		Help:      "Unggahan yang ditolak validasi, per kode alasan.",
+	}, []string{"reason"})
+
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Durasi operasi storage per backend, operasi dan hasil. Durasi get diukur sampai reader siap dibaca.",
+		// Unggahan besar ke penyedia cloud dapat berlangsung beberapa menit.
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 180},
+	}, []string{"backend", "operation", "result"})
+
+	repositoryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_query_duration_seconds",
+		Help:      "Durasi query repository metadata file per operasi dan hasil.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	activeStreams = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_streams",
+		Help:      "Penyimpanan ke storage (upload) dan reader storage yang belum ditutup (download) yang sedang berjalan.",
+	}, []string{"direction"})
+
+	storedFiles = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stored_files",
+		Help:      "Jumlah file yang belum dihapus.",
+	})
+
+	storedBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stored_bytes",
+		Help:      "Total ukuran file yang belum dihapus, tanpa rendition.",
+	})
+
+	jobQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_queue_depth",
+		Help:      "Job latar belakang yang menunggu (pending) atau sedang berjalan (running), per tipe.",
+	}, []string{"type", "status"})
+)
+
+// Register mendaftarkan semua metrik domain ke reg. Dipanggil sekali saat
+// startup dengan prometheus.DefaultRegisterer agar ikut diekspor ginprometheus.
+func Register(reg prometheus.Registerer) {
+	reg.MustRegister(uploadedBytes, downloadedBytes, uploadRejections, storageDuration,
+		repositoryDuration, activeStreams, storedFiles, storedBytes, jobQueueDepth)
+}
+
+// ObserveUpload mencatat unggahan yang berhasil disimpan ke backend.
+func ObserveUpload(mimeType, backend string, size int64) {
+	uploadedBytes.WithLabelValues(baseMimeType(mimeType), backend).Add(float64(size))
+}
+
+// ObserveUploadRejection mencatat unggahan yang ditolak validasi.
+func ObserveUploadRejection(code validation.Code) {
+	uploadRejections.WithLabelValues(string(code)).Inc()
+}
+
+// CountDownload membungkus reader konten file agar setiap byte yang dibaca
+// dihitung pada prism_file_downloaded_bytes_total. Pembacaan yang berhenti di
+// tengah hanya menghitung byte yang benar-benar dibaca.
+func CountDownload(r io.ReadCloser, mimeType, backend string) io.ReadCloser {
+	return keepSeeker(&countingReader{ReadCloser: r, counter: downloadedBytes.WithLabelValues(baseMimeType(mimeType), backend)}, r)
+}
+
+type countingReader struct {
+	io.ReadCloser
+	counter prometheus.Counter
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.counter.Add(float64(n))
+	}
+	return n, err
+}
+
+// keepSeeker mempertahankan io.Seeker milik original pada wrapped, karena
+// unduhan rentang melompat dengan Seek jika reader mendukungnya. Byte yang
+// dilompati tidak dihitung sebagai byte yang dibaca.
+func keepSeeker(wrapped io.ReadCloser, original io.Reader) io.ReadCloser {
+	if seeker, ok := original.(io.Seeker); ok {
+		return struct {
+			io.ReadCloser
+			io.Seeker
+		}{wrapped, seeker}
+	}
+	return wrapped
+}
+
+// baseMimeType membuang parameter (mis. charset) agar jumlah nilai label
+// tetap terbatas pada tipe MIME yang diizinkan.
+func baseMimeType(mimeType string) string {
+	base := strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	if base == "" {
+		return "unknown"
+	}
+	return base
+}
+
+// result mengelompokkan error operasi untuk label result. Objek atau baris
+// yang tidak ada dipisahkan dari kegagalan sungguhan.
+func result(err error) string {
+	switch {
+	case err == nil:
+		return resultOK
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, repository.ErrNotFound):
+		return resultNotFound
+	default:
+		return resultError
+	}
+}
+
+func since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
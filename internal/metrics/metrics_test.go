@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// observations mengembalikan jumlah sampel histogram dengan label tertentu.
+func observations(t *testing.T, vec *prometheus.HistogramVec, labels ...string) uint64 {
+	t.Helper()
+	var metric dto.Metric
+	require.NoError(t, vec.WithLabelValues(labels...).(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	Register(reg)
+	assert.Panics(t, func() { Register(reg) }, "Metrik hanya boleh didaftarkan sekali")
+
+	ObserveUpload("text/plain", "register", 1)
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.Contains(t, names, "prism_file_uploaded_bytes_total")
+	assert.Contains(t, names, "prism_file_stored_bytes")
+}
+
+func TestObserveUpload(t *testing.T) {
+	ObserveUpload("text/plain; charset=utf-8", "upload-test", 10)
+	ObserveUpload("TEXT/PLAIN", "upload-test", 5)
+	ObserveUpload("", "upload-test", 1)
+
+	assert.Equal(t, float64(15), testutil.ToFloat64(uploadedBytes.WithLabelValues("text/plain", "upload-test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(uploadedBytes.WithLabelValues("unknown", "upload-test")))
+}
+
+func TestObserveUploadRejection(t *testing.T) {
+	before := testutil.ToFloat64(uploadRejections.WithLabelValues(string(validation.CodeOfficeMacroDetected)))
+	ObserveUploadRejection(validation.CodeOfficeMacroDetected)
+	assert.Equal(t, before+1, testutil.ToFloat64(uploadRejections.WithLabelValues(string(validation.CodeOfficeMacroDetected))))
+}
+
+func TestCountDownload(t *testing.T) {
+	counter := downloadedBytes.WithLabelValues("application/pdf", "download-test")
+
+	reader := CountDownload(io.NopCloser(strings.NewReader("isi file")), "application/pdf", "download-test")
+	_, isSeeker := reader.(io.Seeker)
+	assert.False(t, isSeeker, "Reader tanpa Seek tidak boleh tampak dapat di-seek")
+	buf := make([]byte, 3)
+	_, err := io.ReadFull(reader, buf)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), testutil.ToFloat64(counter), "Hanya byte yang dibaca yang dihitung")
+	require.NoError(t, reader.Close())
+
+	store := storage.NewMemoryStorage()
+	require.NoError(t, store.Save(context.Background(), "a.pdf", strings.NewReader("0123456789")))
+	source, err := store.Get(context.Background(), "a.pdf")
+	require.NoError(t, err)
+	reader = CountDownload(source, "application/pdf", "download-test")
+	seeker, ok := reader.(io.Seeker)
+	require.True(t, ok, "Seek dipertahankan untuk unduhan rentang")
+	_, err = seeker.Seek(6, io.SeekStart)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "6789", string(rest))
+	assert.Equal(t, float64(7), testutil.ToFloat64(counter), "Byte yang dilompati tidak dihitung")
+}
+
+func TestResult(t *testing.T) {
+	assert.Equal(t, resultOK, result(nil))
+	assert.Equal(t, resultNotFound, result(fmt.Errorf("%w: a.txt", storage.ErrNotFound)))
+	assert.Equal(t, resultNotFound, result(repository.ErrNotFound))
+	assert.Equal(t, resultError, result(errors.New("koneksi terputus")))
+}
+
+func TestFileRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewFileRepository(repository.NewMemoryFileRepository(nil))
+	createdBefore := observations(t, repositoryDuration, "create", resultOK)
+	missingBefore := observations(t, repositoryDuration, "get_by_id", resultNotFound)
+
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "a", OriginalName: "a.txt"}, []string{"laporan"}))
+	stored, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"laporan"}, stored.Tags, "Hasil repository diteruskan apa adanya")
+	_, err = repo.GetByID(ctx, "tidak-ada")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	assert.Equal(t, createdBefore+1, observations(t, repositoryDuration, "create", resultOK))
+	assert.Equal(t, missingBefore+1, observations(t, repositoryDuration, "get_by_id", resultNotFound))
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+)
+
+// instrumentedFileRepository membungkus FileRepository dan mencatat durasi
+// setiap query pada prism_file_repository_query_duration_seconds.
+type instrumentedFileRepository struct {
+	next repository.FileRepository
+}
+
+func NewFileRepository(next repository.FileRepository) repository.FileRepository {
+	return &instrumentedFileRepository{next: next}
+}
+
+func (r *instrumentedFileRepository) Create(ctx context.Context, metadata *model.FileMetadata, tags []string) (err error) {
+	defer observeQuery("create", time.Now(), &err)
+	return r.next.Create(ctx, metadata, tags)
+}
+
+func (r *instrumentedFileRepository) GetByID(ctx context.Context, id string) (_ *model.FileMetadata, err error) {
+	defer observeQuery("get_by_id", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *instrumentedFileRepository) DeleteByID(ctx context.Context, id string) (err error) {
+	defer observeQuery("delete_by_id", time.Now(), &err)
+	return r.next.DeleteByID(ctx, id)
+}
+
+func (r *instrumentedFileRepository) CheckRoleAccess(ctx context.Context, fileID string, roleName string) (_ bool, err error) {
+	defer observeQuery("check_role_access", time.Now(), &err)
+	return r.next.CheckRoleAccess(ctx, fileID, roleName)
+}
+
+func (r *instrumentedFileRepository) List(ctx context.Context, filter repository.FileFilter) (_ []*model.FileMetadata, err error) {
+	defer observeQuery("list", time.Now(), &err)
+	return r.next.List(ctx, filter)
+}
+
+func (r *instrumentedFileRepository) UpdateMetadata(ctx context.Context, id string, expectedVersion int64, update repository.MetadataUpdate, actorID string) (err error) {
+	defer observeQuery("update_metadata", time.Now(), &err)
+	return r.next.UpdateMetadata(ctx, id, expectedVersion, update, actorID)
+}
+
+func (r *instrumentedFileRepository) AddTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) (err error) {
+	defer observeQuery("add_tag", time.Now(), &err)
+	return r.next.AddTag(ctx, id, tag, expectedVersion, actorID)
+}
+
+func (r *instrumentedFileRepository) RemoveTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) (err error) {
+	defer observeQuery("remove_tag", time.Now(), &err)
+	return r.next.RemoveTag(ctx, id, tag, expectedVersion, actorID)
+}
+
+func observeQuery(operation string, start time.Time, err *error) {
+	repositoryDuration.WithLabelValues(operation, result(*err)).Observe(since(start))
+}
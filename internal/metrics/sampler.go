@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// Sampler secara berkala membaca pemakaian storage dan kedalaman antrean job
+// dari database ke gauge, sehingga scrape Prometheus tidak menjalankan query.
+type Sampler struct {
+	usage repository.UsageRepository
+	// jobs nil jika antrean job tidak aktif; kedalaman antrean tidak dicatat.
+	jobs repository.JobRepository
+	// queued berisi pasangan tipe/status yang pernah dicatat, agar antrean
+	// yang sudah kosong dilaporkan 0, bukan hilang dari hasil scrape.
+	queued map[[2]string]bool
+}
+
+func NewSampler(usage repository.UsageRepository, jobs repository.JobRepository) *Sampler {
+	return &Sampler{usage: usage, jobs: jobs, queued: make(map[[2]string]bool)}
+}
+
+// Run menjalankan Sample segera lalu setiap interval sampai ctx dibatalkan.
+func (s *Sampler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sample(ctx); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("Gagal memperbarui metrik pemakaian storage dan antrean job")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample memperbarui gauge pemakaian storage dan kedalaman antrean job. Gauge
+// yang gagal dibaca mempertahankan nilai sebelumnya. Tidak aman dipanggil
+// bersamaan.
+func (s *Sampler) Sample(ctx context.Context) error {
+	var errs []error
+	usage, err := s.usage.StorageUsage(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("gagal membaca pemakaian storage: %w", err))
+	} else {
+		storedFiles.Set(float64(usage.Files))
+		storedBytes.Set(float64(usage.Bytes))
+	}
+
+	if s.jobs != nil {
+		counts, err := s.jobs.CountQueued(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("gagal menghitung antrean job: %w", err))
+		} else {
+			current := make(map[[2]string]bool, len(counts))
+			for _, count := range counts {
+				key := [2]string{count.Type, count.Status}
+				current[key] = true
+				s.queued[key] = true
+				jobQueueDepth.WithLabelValues(count.Type, count.Status).Set(float64(count.Count))
+			}
+			for key := range s.queued {
+				if !current[key] {
+					jobQueueDepth.WithLabelValues(key[0], key[1]).Set(0)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
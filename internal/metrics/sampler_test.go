@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingUsageRepository struct{}
+
+func (failingUsageRepository) StorageUsage(ctx context.Context) (repository.StorageUsage, error) {
+	return repository.StorageUsage{}, errors.New("database mati")
+}
+
+func TestSampler(t *testing.T) {
+	ctx := context.Background()
+	files := repository.NewMemoryFileRepository(nil)
+	jobs := repository.NewMemoryJobRepository()
+	for id, size := range map[string]int64{"a": 10, "b": 32} {
+		require.NoError(t, files.Create(ctx, &model.FileMetadata{ID: id, SizeBytes: size}, nil))
+	}
+	for range 2 {
+		require.NoError(t, jobs.Enqueue(ctx, &model.Job{Type: "sampler-test"}))
+	}
+	pending := jobQueueDepth.WithLabelValues("sampler-test", model.JobStatusPending)
+	running := jobQueueDepth.WithLabelValues("sampler-test", model.JobStatusRunning)
+
+	sampler := NewSampler(files, jobs)
+	require.NoError(t, sampler.Sample(ctx))
+	assert.Equal(t, float64(2), testutil.ToFloat64(storedFiles))
+	assert.Equal(t, float64(42), testutil.ToFloat64(storedBytes))
+	assert.Equal(t, float64(2), testutil.ToFloat64(pending))
+
+	claimed, err := jobs.Claim(ctx, "sampler-test", 2, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, sampler.Sample(ctx))
+	assert.Zero(t, testutil.ToFloat64(pending), "Antrean yang kosong dilaporkan 0")
+	assert.Equal(t, float64(2), testutil.ToFloat64(running))
+
+	for _, job := range claimed {
+		require.NoError(t, jobs.Complete(ctx, job.ID))
+	}
+	require.NoError(t, sampler.Sample(ctx))
+	assert.Zero(t, testutil.ToFloat64(running))
+
+	err = NewSampler(failingUsageRepository{}, nil).Sample(ctx)
+	assert.ErrorContains(t, err, "database mati")
+	assert.Equal(t, float64(42), testutil.ToFloat64(storedBytes), "Nilai lama dipertahankan saat query gagal")
+}
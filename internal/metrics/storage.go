@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+)
+
+// instrumentedStorage membungkus backend storage dan mencatat durasi setiap
+// operasi serta stream yang sedang berjalan. SaveWithAttributes, SignedURL,
+// dan Close diteruskan ke backend jika didukung.
+type instrumentedStorage struct {
+	next    storage.Storage
+	backend string
+}
+
+// NewStorage membungkus next dengan metrik berlabel backend (nilai
+// storage_backend, mis. "s3").
+func NewStorage(next storage.Storage, backend string) storage.Storage {
+	return &instrumentedStorage{next: next, backend: backend}
+}
+
+func (s *instrumentedStorage) Save(ctx context.Context, path string, content io.Reader) error {
+	start := time.Now()
+	defer trackUpload()()
+	err := s.next.Save(ctx, path, content)
+	s.observe("save", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) SaveWithAttributes(ctx context.Context, path string, content io.Reader, attrs storage.ObjectAttributes) error {
+	start := time.Now()
+	defer trackUpload()()
+	err := storage.SaveWithAttributes(ctx, s.next, path, content, attrs)
+	s.observe("save", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	start := time.Now()
+	reader, err := s.next.Get(ctx, path)
+	s.observe("get", start, err)
+	if err != nil {
+		return nil, err
+	}
+	gauge := activeStreams.WithLabelValues(directionDownload)
+	gauge.Inc()
+	return keepSeeker(&streamReader{ReadCloser: reader, done: gauge.Dec}, reader), nil
+}
+
+func (s *instrumentedStorage) Delete(ctx context.Context, path string) error {
+	start := time.Now()
+	err := s.next.Delete(ctx, path)
+	s.observe("delete", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	start := time.Now()
+	url, err := storage.SignedURL(ctx, s.next, path, expiry)
+	s.observe("signed_url", start, err)
+	return url, err
+}
+
+// Close menutup backend jika backend memegang koneksi atau handle.
+func (s *instrumentedStorage) Close() error {
+	if closer, ok := s.next.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *instrumentedStorage) observe(operation string, start time.Time, err error) {
+	storageDuration.WithLabelValues(s.backend, operation, result(err)).Observe(since(start))
+}
+
+// trackUpload menghitung penyimpanan yang sedang berjalan sampai fungsi yang
+// dikembalikan dipanggil.
+func trackUpload() func() {
+	gauge := activeStreams.WithLabelValues(directionUpload)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// streamReader menurunkan gauge download tepat sekali saat reader ditutup.
+type streamReader struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (r *streamReader) Close() error {
+	r.once.Do(r.done)
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closingStorage mencatat apakah Close diteruskan ke backend.
+type closingStorage struct {
+	storage.Storage
+	closed bool
+}
+
+func (s *closingStorage) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	backend := &closingStorage{Storage: storage.NewMemoryStorage()}
+	store := NewStorage(backend, "storage-test")
+	downloads := activeStreams.WithLabelValues(directionDownload)
+
+	require.NoError(t, store.Save(ctx, "a.txt", strings.NewReader("isi file")))
+	require.NoError(t, storage.SaveWithAttributes(ctx, store, "b.txt", strings.NewReader("isi"), storage.ObjectAttributes{ContentType: "text/plain"}))
+	assert.Equal(t, uint64(2), observations(t, storageDuration, "storage-test", "save", resultOK))
+	assert.Zero(t, testutil.ToFloat64(activeStreams.WithLabelValues(directionUpload)), "Penyimpanan yang selesai tidak lagi dihitung")
+
+	before := testutil.ToFloat64(downloads)
+	reader, err := store.Get(ctx, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(downloads))
+	seeker, ok := reader.(io.Seeker)
+	require.True(t, ok, "Seek dari backend dipertahankan")
+	_, err = seeker.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "file", string(content))
+	require.NoError(t, reader.Close())
+	require.NoError(t, reader.Close())
+	assert.Equal(t, before, testutil.ToFloat64(downloads), "Close berulang hanya menurunkan gauge sekali")
+
+	_, err = store.Get(ctx, "tidak-ada.txt")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.Equal(t, uint64(1), observations(t, storageDuration, "storage-test", "get", resultNotFound))
+	assert.Equal(t, before, testutil.ToFloat64(downloads), "Get yang gagal tidak membuka stream")
+
+	require.NoError(t, store.Delete(ctx, "a.txt"))
+	assert.Equal(t, uint64(1), observations(t, storageDuration, "storage-test", "delete", resultOK))
+
+	closer, ok := store.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+	assert.True(t, backend.closed)
+}
+
+// signingStorage adalah backend tiruan yang mendukung storage.URLSigner.
+type signingStorage struct {
+	storage.Storage
+}
+
+func (s signingStorage) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	return "https://signed.example/" + path, nil
+}
+
+func TestStorage_SignedURL(t *testing.T) {
+	ctx := context.Background()
+	url, err := storage.SignedURL(ctx, NewStorage(signingStorage{Storage: storage.NewMemoryStorage()}, "gcs"), "a.txt", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "https://signed.example/a.txt", url)
+
+	_, err = storage.SignedURL(ctx, NewStorage(storage.NewMemoryStorage(), "local"), "a.txt", time.Minute)
+	assert.ErrorIs(t, err, storage.ErrSigningUnsupported)
+}
@@ -0,0 +1,53 @@
+package model
+
+import "strings"
+
+// AccessRule memberi peran RoleName akses baca ke file yang memiliki tag
+// yang cocok dengan TagName. TagName boleh berupa pola:
+//   - "keuangan"    cocok persis dengan tag "keuangan"
+//   - "keuangan/*"  cocok dengan semua turunan, mis. "keuangan/pajak/2025"
+//     (tetapi tidak dengan "keuangan" itu sendiri)
+//   - "*"           cocok dengan semua tag
+type AccessRule struct {
+	TagName  string `json:"tag_name" binding:"required"`
+	RoleName string `json:"role_name" binding:"required"`
+}
+
+// Matches melaporkan apakah tag file cocok dengan pola TagName. Semantiknya
+// harus sama dengan query CheckRoleAccess di repository.
+func (r AccessRule) Matches(tag string) bool {
+	switch {
+	case r.TagName == "*":
+		return true
+	case strings.HasSuffix(r.TagName, "/*"):
+		return strings.HasPrefix(tag, strings.TrimSuffix(r.TagName, "*"))
+	default:
+		return r.TagName == tag
+	}
+}
+
+// RoleHasTagAccess melaporkan apakah salah satu aturan memberi peran
+// roleName akses ke file dengan tag tags.
+func RoleHasTagAccess(rules []AccessRule, roleName string, tags []string) bool {
+	for _, rule := range rules {
+		if rule.RoleName != roleName {
+			continue
+		}
+		for _, tag := range tags {
+			if rule.Matches(tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsValidPattern memastikan wildcard hanya dipakai sebagai "*" atau akhiran "/*".
+func (r AccessRule) IsValidPattern() bool {
+	pattern := r.TagName
+	if pattern == "*" {
+		return true
+	}
+	pattern = strings.TrimSuffix(pattern, "/*")
+	return pattern != "" && !strings.Contains(pattern, "*") && !strings.HasSuffix(pattern, "/")
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessRule_Matches(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		tag     string
+		want    bool
+	}{
+		{"keuangan", "keuangan", true},
+		{"keuangan", "keuangan/pajak", false},
+		{"keuangan/*", "keuangan/pajak", true},
+		{"keuangan/*", "keuangan/pajak/2025", true},
+		{"keuangan/*", "keuangan", false},
+		{"keuangan/*", "keuangan-lama/pajak", false},
+		{"*", "apa-saja", true},
+	}
+
+	for _, tc := range testCases {
+		rule := AccessRule{TagName: tc.pattern, RoleName: "finance"}
+		assert.Equal(t, tc.want, rule.Matches(tc.tag), "pola %q terhadap tag %q", tc.pattern, tc.tag)
+	}
+}
+
+func TestAccessRule_IsValidPattern(t *testing.T) {
+	valid := []string{"keuangan", "keuangan/*", "keuangan/pajak/*", "*"}
+	invalid := []string{"", "/*", "keu*", "keuangan/*/pajak", "keuangan/", "*/pajak"}
+
+	for _, pattern := range valid {
+		assert.True(t, AccessRule{TagName: pattern}.IsValidPattern(), pattern)
+	}
+	for _, pattern := range invalid {
+		assert.False(t, AccessRule{TagName: pattern}.IsValidPattern(), pattern)
+	}
+}
+
+func TestRoleHasTagAccess(t *testing.T) {
+	rules := []AccessRule{
+		{TagName: "keuangan/*", RoleName: "finance"},
+		{TagName: "hr", RoleName: "hr"},
+	}
+	testCases := []struct {
+		role string
+		tags []string
+		want bool
+	}{
+		{"finance", []string{"umum", "keuangan/pajak"}, true},
+		{"finance", []string{"hr"}, false},
+		{"hr", []string{"hr"}, true},
+		{"auditor", []string{"keuangan/pajak", "hr"}, false},
+		{"finance", nil, false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, RoleHasTagAccess(rules, tc.role, tc.tags), "peran %q dengan tag %v", tc.role, tc.tags)
+	}
+}
@@ -0,0 +1,80 @@
+// Package model berisi model domain milik prism-file-service.
+package model
+
+import "time"
+
+// FileMetadata mencerminkan model.FileMetadata dari prism-common-libs dengan
+// tambahan kolom yang hanya dikelola oleh layanan ini.
+type FileMetadata struct {
+	ID           string    `json:"id"`
+	OriginalName string    `json:"original_name"`
+	StoragePath  string    `json:"-"`
+	MimeType     string    `json:"mime_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	OwnerUserID  *string   `json:"owner_user_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	Tags         []string  `json:"tags,omitempty"`
+	// Version naik setiap kali metadata atau tag berubah; dipakai sebagai ETag
+	// untuk optimistic concurrency (If-Match).
+	Version int64 `json:"version"`
+	// ChecksumSHA256 dihitung saat unggah (hex). ChecksumMD5 dan
+	// ChecksumCRC32C hanya terisi jika diaktifkan lewat extra_checksums.
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
+	ChecksumMD5    string `json:"checksum_md5,omitempty"`
+	ChecksumCRC32C string `json:"checksum_crc32c,omitempty"`
+	// ChecksumVerifiedAt adalah waktu scrubber terakhir memeriksa konten;
+	// ChecksumMismatchAt terisi jika pemeriksaan terakhir menemukan kerusakan.
+	ChecksumVerifiedAt *time.Time `json:"checksum_verified_at,omitempty"`
+	ChecksumMismatchAt *time.Time `json:"checksum_mismatch_at,omitempty"`
+	// RetainUntil adalah akhir masa retensi bawaan dari kebijakan upload yang
+	// dipakai saat unggah; nil jika kebijakannya tidak menentukan retensi.
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+	// SanitizedAt adalah waktu metadata file (EXIF, properti dokumen) dibuang
+	// saat unggah; nil jika file disimpan apa adanya.
+	SanitizedAt *time.Time `json:"sanitized_at,omitempty"`
+	// IndexedAt adalah waktu teks isi file terakhir diekstrak untuk pencarian
+	// full-text; nil jika file belum pernah diindeks.
+	IndexedAt *time.Time `json:"indexed_at,omitempty"`
+	// ContentText adalah teks hasil ekstraksi yang disimpan bersama metadata
+	// saat Create. Repository tidak pernah mengisinya kembali saat membaca.
+	ContentText string `json:"-"`
+	// RenditionPaths adalah path storage hasil render gambar yang tersimpan
+	// sebagai cache; dihapus bersama file.
+	RenditionPaths []string `json:"-"`
+	// PDF berisi informasi dokumen untuk file PDF yang strukturnya dapat
+	// dibaca saat unggah; nil untuk file lain.
+	PDF *PDFInfo `json:"pdf,omitempty"`
+	// Provenance mencatat asal-usul file yang dibuat layanan dari file lain,
+	// mis. hasil penggabungan PDF; nil untuk unggahan biasa.
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// PDFInfo adalah informasi dokumen PDF. Properti dokumen kosong jika PDF
+// terenkripsi atau dibersihkan saat unggah.
+type PDFInfo struct {
+	PageCount int    `json:"page_count"`
+	Encrypted bool   `json:"encrypted,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	Creator   string `json:"creator,omitempty"`
+	Producer  string `json:"producer,omitempty"`
+}
+
+// ProvenancePDFMerge adalah operasi penggabungan beberapa PDF.
+const ProvenancePDFMerge = "pdf_merge"
+
+// Provenance mencatat operasi dan file sumber pembentuk sebuah file.
+type Provenance struct {
+	Operation string             `json:"operation"`
+	Sources   []ProvenanceSource `json:"sources"`
+}
+
+// ProvenanceSource adalah salinan ringkas metadata file sumber pada saat
+// operasi dijalankan, sehingga tetap bermakna jika sumbernya dihapus.
+type ProvenanceSource struct {
+	FileID         string `json:"file_id"`
+	OriginalName   string `json:"original_name"`
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
+	PageCount      int    `json:"page_count,omitempty"`
+}
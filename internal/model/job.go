@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipe job latar belakang yang dikenali worker.
+const (
+	// JobTypeExtractText mengekstrak teks isi file untuk pencarian full-text.
+	JobTypeExtractText = "extract_text"
+	// JobTypeBuildArchive membangun arsip ZIP asinkron; ID job sama dengan
+	// ID file arsip yang dihasilkan.
+	JobTypeBuildArchive = "build_archive"
+)
+
+// Status job latar belakang.
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	// JobStatusFailed adalah status akhir job yang gagal pada percobaan
+	// terakhirnya; job hanya dijalankan lagi jika di-retry oleh admin.
+	JobStatusFailed = "failed"
+)
+
+// Job adalah pekerjaan latar belakang yang disimpan di antrean, mis.
+// ekstraksi teks setelah file diunggah.
+type Job struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// FileID adalah file yang diproses job; nil untuk job yang tidak terkait
+	// satu file.
+	FileID  *string         `json:"file_id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Status  string          `json:"status"`
+	// Attempts adalah jumlah percobaan yang sudah dimulai, termasuk yang
+	// sedang berjalan.
+	Attempts int `json:"attempts"`
+	// RunAt adalah waktu paling awal job boleh diambil worker; diundur
+	// dengan backoff setelah percobaan gagal.
+	RunAt       time.Time  `json:"run_at"`
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// S3Credential adalah access key SigV4 milik seorang pengguna Prism. Secret
+// tidak disimpan; nilainya diturunkan dari kunci server dan hanya dikembalikan
+// sekali saat credential dibuat.
+type S3Credential struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	UserID          string `json:"user_id"`
+	// Role disalin dari token penerbit. Karena itu credential hanya berlaku
+	// selama token tersebut berlaku: sampai ExpiresAt (klaim exp) dan selama
+	// TokenID (klaim jti) belum dicabut.
+	Role      string    `json:"role"`
+	TokenID   string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// S3Object memetakan key objek dalam sebuah bucket ke file Prism.
+type S3Object struct {
+	Bucket string
+	Key    string
+	FileID string
+	ETag   string
+}
+
+// S3MultipartUpload adalah multipart upload yang belum diselesaikan.
+type S3MultipartUpload struct {
+	UploadID  string
+	Bucket    string
+	Key       string
+	UserID    string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+// S3MultipartPart adalah satu bagian multipart upload yang sudah diterima.
+type S3MultipartPart struct {
+	UploadID   string
+	PartNumber int
+	ETag       string
+	SizeBytes  int64
+}
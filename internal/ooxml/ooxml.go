@@ -0,0 +1,109 @@
+// Package ooxml membaca paket dokumen Office Open XML (DOCX, XLSX, PPTX):
+// arsip ZIP berisi bagian-bagian XML. Validasi unggahan, pembersihan metadata
+// dan ekstraksi teks memakai pembaca yang sama sehingga nama bagian dicocokkan
+// dengan cara yang sama.
+package ooxml
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrMalformed dikembalikan jika content bukan arsip ZIP yang dapat dibaca.
+var ErrMalformed = errors.New("paket OOXML tidak valid")
+
+// maxContentTypesBytes membatasi ukuran [Content_Types].xml yang dibaca.
+const maxContentTypesBytes = 1 << 20
+
+// Package adalah paket OOXML yang sudah dibuka.
+type Package struct {
+	files []*zip.File
+}
+
+// Open membaca direktori arsip ZIP di content.
+func Open(content io.ReaderAt, size int64) (*Package, error) {
+	r, err := zip.NewReader(content, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	return &Package{files: r.File}, nil
+}
+
+// PartName menormalkan nama entri arsip menjadi nama bagian: huruf kecil
+// tanpa garis miring awal. Aplikasi Office menulis nama bagian dengan huruf
+// besar-kecil yang berbeda, sedangkan pembacanya tidak membedakannya.
+func PartName(entry string) string {
+	return strings.ToLower(strings.TrimPrefix(entry, "/"))
+}
+
+// Walk memanggil fn untuk setiap bagian sesuai urutan di arsip dengan nama
+// yang sudah dinormalkan PartName. Walk berhenti pada error pertama dari fn.
+func (p *Package) Walk(fn func(name string, part *zip.File) error) error {
+	for _, f := range p.files {
+		if err := fn(PartName(f.Name), f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasMacros melaporkan apakah paket memuat proyek VBA, baik sebagai bagian
+// vbaProject.bin/vbaData.xml maupun tipe konten macro-enabled di
+// [Content_Types].xml. Berkas .docm/.xlsm/.pptm terdeteksi sebagai tipe
+// biasa sehingga ikut diperiksa di sini.
+func (p *Package) HasMacros() (bool, error) {
+	found := false
+	err := p.Walk(func(name string, part *zip.File) error {
+		if base := path.Base(name); base == "vbaproject.bin" || base == "vbadata.xml" {
+			found = true
+		}
+		if found || name != "[content_types].xml" {
+			return nil
+		}
+		types, err := readPart(part, maxContentTypesBytes)
+		if err != nil {
+			return fmt.Errorf("%w: [Content_Types].xml tidak dapat dibaca: %w", ErrMalformed, err)
+		}
+		types = bytes.ToLower(types)
+		found = bytes.Contains(types, []byte("macroenabled")) || bytes.Contains(types, []byte("vbaproject"))
+		return nil
+	})
+	return found, err
+}
+
+// Rewrite menulis ulang paket ke dst dengan isi bagian di replacements
+// (kunci berupa nama hasil PartName) diganti. Bagian lain disalin tanpa
+// dikompresi ulang dengan urutan yang sama.
+func (p *Package) Rewrite(dst io.Writer, replacements map[string]string) error {
+	w := zip.NewWriter(dst)
+	err := p.Walk(func(name string, part *zip.File) error {
+		replacement, ok := replacements[name]
+		if !ok {
+			return w.Copy(part)
+		}
+		out, err := w.CreateHeader(&zip.FileHeader{Name: part.Name, Method: zip.Deflate, Modified: part.Modified})
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(out, replacement)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func readPart(part *zip.File, limit int64) ([]byte, error) {
+	rc, err := part.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
@@ -0,0 +1,87 @@
+package ooxml
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct{ name, body string }
+
+func zipBytes(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		f, err := w.Create(e.name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func open(t *testing.T, content []byte) *Package {
+	t.Helper()
+	pkg, err := Open(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	return pkg
+}
+
+func TestOpen_Malformed(t *testing.T) {
+	content := []byte("PK\x03\x04bukan arsip")
+	_, err := Open(bytes.NewReader(content), int64(len(content)))
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestPartName(t *testing.T) {
+	assert.Equal(t, "xl/sharedstrings.xml", PartName("xl/sharedStrings.xml"))
+	assert.Equal(t, "[content_types].xml", PartName("/[Content_Types].xml"))
+}
+
+func TestPackage_HasMacros(t *testing.T) {
+	document := entry{"word/document.xml", "<w:document/>"}
+	testCases := []struct {
+		name     string
+		entries  []entry
+		expected bool
+	}{
+		{name: "Plain document", entries: []entry{{"[Content_Types].xml", "<Types/>"}, document}},
+		{name: "VBA project part", entries: []entry{document, {"word/vbaProject.bin", "\x00"}}, expected: true},
+		{name: "VBA data part with upper-case name", entries: []entry{document, {"Word/VBADATA.xml", "<wne:vbaSuppData/>"}}, expected: true},
+		{name: "Macro-enabled content type", entries: []entry{{"[Content_Types].xml", `<Types><Override ContentType="application/vnd.ms-word.document.macroEnabled.main+xml"/></Types>`}, document}, expected: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			macros, err := open(t, zipBytes(t, tc.entries...)).HasMacros()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, macros)
+		})
+	}
+}
+
+func TestPackage_Rewrite(t *testing.T) {
+	pkg := open(t, zipBytes(t, entry{"word/document.xml", "isi"}, entry{"docProps/core.xml", "rahasia"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, pkg.Rewrite(&buf, map[string]string{"docprops/core.xml": "<kosong/>"}))
+
+	var names, bodies []string
+	require.NoError(t, open(t, buf.Bytes()).Walk(func(name string, part *zip.File) error {
+		rc, err := part.Open()
+		require.NoError(t, err)
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		names = append(names, part.Name)
+		bodies = append(bodies, string(body))
+		return nil
+	}))
+	assert.Equal(t, []string{"word/document.xml", "docProps/core.xml"}, names, "Nama asli dan urutan dipertahankan")
+	assert.Equal(t, []string{"isi", "<kosong/>"}, bodies)
+}
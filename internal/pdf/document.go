@@ -0,0 +1,626 @@
+// Package pdf membaca struktur dokumen PDF (tabel xref, objek dan pohon
+// halaman) tanpa dependensi di luar library standar, serta menulis dokumen
+// baru dari halaman dokumen lain (Merge). Isi halaman tidak ditafsirkan;
+// stream disalin apa adanya. Lexer membaca token PDF secara berurutan untuk
+// pemindaian file besar tanpa memuatnya ke memori.
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrMalformed dikembalikan jika struktur dokumen tidak dapat dibaca.
+	ErrMalformed = errors.New("struktur PDF tidak valid")
+	// ErrEncrypted dikembalikan jika operasi memerlukan isi dokumen yang
+	// terenkripsi.
+	ErrEncrypted = errors.New("PDF terenkripsi")
+)
+
+const (
+	// maxPages membatasi jumlah halaman yang dibaca dari pohon halaman.
+	maxPages = 100000
+	// maxTreeDepth membatasi kedalaman pohon halaman.
+	maxTreeDepth = 64
+)
+
+// xrefEntry menunjukkan lokasi objek: offset di file (inStream false) atau
+// indeks di object stream nomor container.
+type xrefEntry struct {
+	inStream  bool
+	offset    int
+	container int
+	index     int
+}
+
+// page adalah halaman beserta atribut yang diwarisi dari node Pages di
+// atasnya (Resources, MediaBox, CropBox, Rotate).
+type page struct {
+	ref       ref
+	inherited dict
+}
+
+// Document adalah dokumen PDF yang sudah dibaca strukturnya. Objek dibaca saat
+// dibutuhkan dan disimpan di cache; Document tidak aman dipakai bersamaan
+// dari beberapa goroutine.
+type Document struct {
+	data    []byte
+	xref    map[int]xrefEntry
+	trailer dict
+	pages   []page
+
+	cache   map[int]object
+	loading map[int]bool
+	objStms map[int]*objectStream
+}
+
+// objectStream adalah isi object stream (/Type /ObjStm) yang sudah
+// didekompresi beserta nomor dan offset objek di dalamnya.
+type objectStream struct {
+	data    []byte
+	members []int
+	offsets []int
+}
+
+// Info adalah properti dokumen dari dictionary Info. Kosong untuk dokumen
+// terenkripsi karena string-nya ikut terenkripsi.
+type Info struct {
+	Title    string
+	Author   string
+	Subject  string
+	Creator  string
+	Producer string
+}
+
+// Open membaca struktur dokumen PDF di data. Tabel xref yang rusak atau
+// offset yang meleset ditangani dengan memindai ulang objek di file. data
+// tidak boleh diubah selama Document dipakai.
+func Open(data []byte) (*Document, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: header %%PDF- tidak ditemukan", ErrMalformed)
+	}
+	d := &Document{data: data}
+	d.reset()
+	err := d.readXref()
+	if err == nil {
+		err = d.loadPages()
+	}
+	if err != nil {
+		if rebuildErr := d.rebuild(); rebuildErr != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		if err := d.loadPages(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+	}
+	return d, nil
+}
+
+func (d *Document) reset() {
+	d.xref = make(map[int]xrefEntry)
+	d.trailer = nil
+	d.cache = make(map[int]object)
+	d.loading = make(map[int]bool)
+	d.objStms = make(map[int]*objectStream)
+}
+
+// NumPages mengembalikan jumlah halaman dokumen.
+func (d *Document) NumPages() int { return len(d.pages) }
+
+// Encrypted melaporkan apakah dokumen memakai enkripsi (termasuk enkripsi
+// dengan kata sandi pemilik kosong).
+func (d *Document) Encrypted() bool { return d.trailer["Encrypt"] != nil }
+
+// Info mengembalikan properti dokumen.
+func (d *Document) Info() Info {
+	if d.Encrypted() {
+		return Info{}
+	}
+	info := d.dictOf(d.trailer["Info"])
+	return Info{
+		Title:    d.text(info["Title"]),
+		Author:   d.text(info["Author"]),
+		Subject:  d.text(info["Subject"]),
+		Creator:  d.text(info["Creator"]),
+		Producer: d.text(info["Producer"]),
+	}
+}
+
+// readXref membaca rantai xref dari startxref terakhir mengikuti /Prev.
+// Entri dari bagian yang lebih baru (dibaca lebih dulu) diutamakan.
+func (d *Document) readXref() error {
+	tail := max(len(d.data)-2048, 0)
+	i := bytes.LastIndex(d.data[tail:], []byte("startxref"))
+	if i < 0 {
+		return errors.New("startxref tidak ditemukan")
+	}
+	p := &parser{data: d.data, pos: tail + i + len("startxref")}
+	offset, ok := p.integer()
+	seen := make(map[int64]bool)
+	for ok {
+		if seen[offset] || offset >= int64(len(d.data)) {
+			return fmt.Errorf("offset xref %d tidak valid", offset)
+		}
+		seen[offset] = true
+		trailer, err := d.readXrefSection(int(offset))
+		if err != nil {
+			return err
+		}
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+		// File hybrid menyimpan objek terkompresi di xref stream terpisah.
+		if stm, isInt := trailer["XRefStm"].(int64); isInt && !seen[stm] && stm < int64(len(d.data)) {
+			seen[stm] = true
+			if _, err := d.readXrefSection(int(stm)); err != nil {
+				return err
+			}
+		}
+		offset, ok = trailer["Prev"].(int64)
+	}
+	if d.trailer == nil || d.trailer["Root"] == nil {
+		return errors.New("trailer tanpa Root")
+	}
+	return nil
+}
+
+func (d *Document) readXrefSection(offset int) (dict, error) {
+	p := &parser{data: d.data, pos: offset}
+	if p.keyword("xref") {
+		return d.readXrefTable(p)
+	}
+	return d.readXrefStream(offset)
+}
+
+func (d *Document) readXrefTable(p *parser) (dict, error) {
+	for {
+		if p.keyword("trailer") {
+			obj, err := p.object(0)
+			if err != nil {
+				return nil, err
+			}
+			trailer, ok := obj.(dict)
+			if !ok {
+				return nil, errors.New("trailer bukan dictionary")
+			}
+			return trailer, nil
+		}
+		start, okStart := p.integer()
+		count, okCount := p.integer()
+		if !okStart || !okCount || count > int64(len(d.data)/18) {
+			return nil, errors.New("subbagian xref tidak valid")
+		}
+		for i := range int(count) {
+			offset, okOffset := p.integer()
+			_, okGen := p.integer()
+			kind := p.token(false)
+			if !okOffset || !okGen || (kind != "n" && kind != "f") {
+				return nil, errors.New("entri xref tidak valid")
+			}
+			// Entri bebas dilewati agar tidak menutupi entri dari
+			// xref stream pada file hybrid.
+			d.addEntry(int(start)+i, kind == "n", xrefEntry{offset: int(offset)})
+		}
+	}
+}
+
+func (d *Document) readXrefStream(offset int) (dict, error) {
+	obj, err := d.parseIndirect(offset, -1)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(*stream)
+	if !ok || s.dict["Type"] != name("XRef") {
+		return nil, errors.New("xref stream tidak ditemukan")
+	}
+	data, err := d.decode(s)
+	if err != nil {
+		return nil, err
+	}
+	widths, _ := s.dict["W"].(array)
+	if len(widths) != 3 {
+		return nil, errors.New("W xref stream tidak valid")
+	}
+	var w [3]int
+	for i := range w {
+		v, ok := widths[i].(int64)
+		if !ok || v < 0 || v > 8 {
+			return nil, errors.New("W xref stream tidak valid")
+		}
+		w[i] = int(v)
+	}
+	entryLen := w[0] + w[1] + w[2]
+	if entryLen == 0 {
+		return nil, errors.New("W xref stream tidak valid")
+	}
+	size, _ := s.dict["Size"].(int64)
+	index, _ := s.dict["Index"].(array)
+	if index == nil {
+		index = array{int64(0), size}
+	}
+	for i := 0; i+1 < len(index); i += 2 {
+		start, okStart := index[i].(int64)
+		count, okCount := index[i+1].(int64)
+		if !okStart || !okCount || count < 0 {
+			return nil, errors.New("Index xref stream tidak valid")
+		}
+		for j := range int(count) {
+			if len(data) < entryLen {
+				return s.dict, nil
+			}
+			kind := int64(1)
+			if w[0] > 0 {
+				kind = beInt(data[:w[0]])
+			}
+			f2, f3 := beInt(data[w[0]:w[0]+w[1]]), beInt(data[w[0]+w[1]:entryLen])
+			data = data[entryLen:]
+			num := int(start) + j
+			switch kind {
+			case 1:
+				d.addEntry(num, true, xrefEntry{offset: int(f2)})
+			case 2:
+				d.addEntry(num, true, xrefEntry{inStream: true, container: int(f2), index: int(f3)})
+			}
+		}
+	}
+	return s.dict, nil
+}
+
+func (d *Document) addEntry(num int, inUse bool, entry xrefEntry) {
+	if _, exists := d.xref[num]; !exists && inUse && num > 0 {
+		d.xref[num] = entry
+	}
+}
+
+func beInt(b []byte) int64 {
+	var v int64
+	for _, c := range b {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
+
+// rebuild menyusun ulang xref dengan memindai semua "n g obj" di file dan
+// isi object stream, lalu mencari trailer atau katalog dokumen.
+func (d *Document) rebuild() error {
+	d.reset()
+	offsets := scanObjects(d.data)
+	for num, offset := range offsets {
+		d.xref[num] = xrefEntry{offset: offset}
+	}
+	for num := range offsets {
+		s, ok := d.load(num).(*stream)
+		if !ok || s.dict["Type"] != name("ObjStm") {
+			continue
+		}
+		objStm, err := d.objectStream(num)
+		if err != nil {
+			continue
+		}
+		for i := range objStm.offsets {
+			member := objStm.members[i]
+			if _, exists := d.xref[member]; !exists {
+				d.xref[member] = xrefEntry{inStream: true, container: num, index: i}
+			}
+		}
+	}
+
+	if i := bytes.LastIndex(d.data, []byte("trailer")); i >= 0 {
+		p := &parser{data: d.data, pos: i + len("trailer")}
+		if obj, err := p.object(0); err == nil {
+			d.trailer, _ = obj.(dict)
+		}
+	}
+	if d.trailer == nil || d.trailer["Root"] == nil {
+		d.trailer = dict{}
+		for num := range d.xref {
+			obj := d.load(num)
+			if s, ok := obj.(*stream); ok && s.dict["Type"] == name("XRef") && s.dict["Root"] != nil {
+				d.trailer = s.dict
+				break
+			}
+			if dct, ok := obj.(dict); ok && dct["Type"] == name("Catalog") {
+				d.trailer["Root"] = ref{num: num}
+			}
+		}
+	}
+	if d.trailer["Root"] == nil {
+		return errors.New("katalog dokumen tidak ditemukan")
+	}
+	return nil
+}
+
+// scanObjects mencari semua header objek "n g obj". Objek yang muncul lebih
+// akhir (pembaruan inkremental) menggantikan yang lebih awal.
+func scanObjects(data []byte) map[int]int {
+	offsets := make(map[int]int)
+	for i := 0; ; {
+		j := bytes.Index(data[i:], []byte("obj"))
+		if j < 0 {
+			return offsets
+		}
+		at := i + j
+		i = at + 3
+		if i < len(data) && !isSpace(data[i]) && !isDelimiter(data[i]) {
+			continue
+		}
+		// Mundur melewati "gen" dan "num" beserta spasi di antaranya.
+		k := at
+		fields := [2]int{}
+		ok := true
+		for f := range fields {
+			end := k
+			for end > 0 && isSpace(data[end-1]) {
+				end--
+			}
+			start := end
+			for start > 0 && data[start-1] >= '0' && data[start-1] <= '9' {
+				start--
+			}
+			if start == end || end == k {
+				ok = false
+				break
+			}
+			v, err := strconv.Atoi(string(data[start:end]))
+			if err != nil {
+				ok = false
+				break
+			}
+			fields[f] = v
+			k = start
+		}
+		if ok && (k == 0 || isSpace(data[k-1]) || isDelimiter(data[k-1])) && fields[1] > 0 {
+			offsets[fields[1]] = k
+		}
+	}
+}
+
+// resolve mengikuti referensi sampai mendapat objek langsung. Referensi ke
+// objek yang tidak ada bernilai null.
+func (d *Document) resolve(obj object) object {
+	for range 8 {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = d.load(r.num)
+	}
+	return nil
+}
+
+func (d *Document) dictOf(obj object) dict {
+	switch v := d.resolve(obj).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.dict
+	}
+	return nil
+}
+
+func (d *Document) arrayOf(obj object) array {
+	a, _ := d.resolve(obj).(array)
+	return a
+}
+
+func (d *Document) intOr(obj object, fallback int) int {
+	if v, ok := d.resolve(obj).(int64); ok {
+		return int(v)
+	}
+	return fallback
+}
+
+func (d *Document) number(obj object) (float64, bool) {
+	switch v := d.resolve(obj).(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// load membaca objek nomor num. Objek yang gagal dibaca bernilai null,
+// sesuai perlakuan PDF terhadap referensi yang tidak valid.
+func (d *Document) load(num int) object {
+	if v, ok := d.cache[num]; ok {
+		return v
+	}
+	entry, ok := d.xref[num]
+	if !ok || d.loading[num] {
+		return nil
+	}
+	d.loading[num] = true
+	defer delete(d.loading, num)
+
+	var (
+		v   object
+		err error
+	)
+	if entry.inStream {
+		v, err = d.loadFromStream(entry)
+	} else {
+		v, err = d.parseIndirect(entry.offset, num)
+	}
+	if err != nil {
+		v = nil
+	}
+	d.cache[num] = v
+	return v
+}
+
+// parseIndirect membaca objek "num gen obj ... endobj" di offset. want
+// bernilai -1 jika nomor objek tidak perlu dicocokkan.
+func (d *Document) parseIndirect(offset, want int) (object, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return nil, fmt.Errorf("offset objek %d di luar file", offset)
+	}
+	p := &parser{data: d.data, pos: offset}
+	num, okNum := p.integer()
+	_, okGen := p.integer()
+	if !okNum || !okGen || !p.keyword("obj") || (want >= 0 && int(num) != want) {
+		return nil, fmt.Errorf("header objek di offset %d tidak valid", offset)
+	}
+	obj, err := p.object(0)
+	if err != nil {
+		return nil, err
+	}
+	header, isDict := obj.(dict)
+	if !isDict || !p.keyword("stream") {
+		return obj, nil
+	}
+	// Data stream dimulai setelah EOL (CRLF atau LF) kata kunci stream.
+	if p.pos < len(d.data) && d.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(d.data) && d.data[p.pos] == '\n' {
+		p.pos++
+	}
+	start := p.pos
+	length := -1
+	if v, ok := d.resolve(header["Length"]).(int64); ok && v >= 0 && int64(start)+v <= int64(len(d.data)) {
+		length = int(v)
+		end := &parser{data: d.data, pos: start + length}
+		if !end.keyword("endstream") {
+			length = -1
+		}
+	}
+	if length < 0 {
+		// Length salah: cari endstream dan buang EOL sebelumnya.
+		i := bytes.Index(d.data[start:], []byte("endstream"))
+		if i < 0 {
+			return nil, errors.New("endstream tidak ditemukan")
+		}
+		length = i
+		if length > 0 && d.data[start+length-1] == '\n' {
+			length--
+		}
+		if length > 0 && d.data[start+length-1] == '\r' {
+			length--
+		}
+	}
+	return &stream{dict: header, data: d.data[start : start+length]}, nil
+}
+
+func (d *Document) loadFromStream(entry xrefEntry) (object, error) {
+	objStm, err := d.objectStream(entry.container)
+	if err != nil {
+		return nil, err
+	}
+	if entry.index < 0 || entry.index >= len(objStm.offsets) {
+		return nil, errors.New("indeks object stream di luar batas")
+	}
+	p := &parser{data: objStm.data, pos: objStm.offsets[entry.index]}
+	return p.object(0)
+}
+
+func (d *Document) objectStream(num int) (*objectStream, error) {
+	if objStm, ok := d.objStms[num]; ok {
+		return objStm, nil
+	}
+	s, ok := d.load(num).(*stream)
+	if !ok || s.dict["Type"] != name("ObjStm") {
+		return nil, fmt.Errorf("objek %d bukan object stream", num)
+	}
+	data, err := d.decode(s)
+	if err != nil {
+		return nil, err
+	}
+	n := d.intOr(s.dict["N"], 0)
+	first := d.intOr(s.dict["First"], 0)
+	if n < 0 || n > len(data) || first < 0 || first > len(data) {
+		return nil, errors.New("header object stream tidak valid")
+	}
+	objStm := &objectStream{data: data, offsets: make([]int, n), members: make([]int, n)}
+	p := &parser{data: data[:first]}
+	for i := range n {
+		member, okMember := p.integer()
+		offset, okOffset := p.integer()
+		if !okMember || !okOffset || first+int(offset) > len(data) {
+			return nil, errors.New("header object stream tidak valid")
+		}
+		objStm.members[i] = int(member)
+		objStm.offsets[i] = first + int(offset)
+	}
+	d.objStms[num] = objStm
+	return objStm, nil
+}
+
+// loadPages menelusuri pohon halaman dari katalog dokumen.
+func (d *Document) loadPages() error {
+	d.pages = nil
+	root := d.dictOf(d.trailer["Root"])
+	if root == nil {
+		return errors.New("katalog dokumen tidak ditemukan")
+	}
+	return d.walkPages(root["Pages"], dict{}, 0, make(map[int]bool))
+}
+
+// inheritableKeys adalah atribut halaman yang dapat diwarisi dari node Pages.
+var inheritableKeys = []name{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+func (d *Document) walkPages(node object, inherited dict, depth int, visited map[int]bool) error {
+	r, isRef := node.(ref)
+	if !isRef {
+		return errors.New("node pohon halaman bukan referensi")
+	}
+	if visited[r.num] || depth > maxTreeDepth {
+		return errors.New("pohon halaman berputar atau terlalu dalam")
+	}
+	visited[r.num] = true
+	n := d.dictOf(r)
+	if n == nil {
+		return fmt.Errorf("node halaman %d tidak ditemukan", r.num)
+	}
+	kids, hasKids := d.resolve(n["Kids"]).(array)
+	if n["Type"] == name("Page") || (n["Type"] == nil && !hasKids) {
+		if len(d.pages) >= maxPages {
+			return fmt.Errorf("dokumen melebihi %d halaman", maxPages)
+		}
+		d.pages = append(d.pages, page{ref: r, inherited: inherited})
+		return nil
+	}
+	next := make(dict, len(inherited))
+	for k, v := range inherited {
+		next[k] = v
+	}
+	for _, key := range inheritableKeys {
+		if v, ok := n[key]; ok {
+			next[key] = v
+		}
+	}
+	for _, kid := range kids {
+		if err := d.walkPages(kid, next, depth+1, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pageDict mengembalikan dictionary halaman i dengan atribut warisan yang
+// belum ditetapkan halaman itu sendiri.
+func (d *Document) pageDict(i int) dict {
+	p := d.pages[i]
+	own := d.dictOf(p.ref)
+	merged := make(dict, len(own)+len(p.inherited))
+	for k, v := range p.inherited {
+		merged[k] = v
+	}
+	for k, v := range own {
+		merged[k] = v
+	}
+	return merged
+}
+
+// text mendekode text string PDF: UTF-16BE atau UTF-8 dengan BOM, selain itu
+// PDFDocEncoding (diperlakukan sebagai Latin-1).
+func (d *Document) text(obj object) string {
+	s, ok := d.resolve(obj).(pdfString)
+	if !ok {
+		return ""
+	}
+	return decodeText(s)
+}
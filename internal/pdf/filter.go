@@ -0,0 +1,134 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxDecodedBytes membatasi hasil dekompresi satu stream struktur (xref atau
+// object stream) agar zip bomb tidak menghabiskan memori.
+const maxDecodedBytes = 64 << 20
+
+var errUnsupportedFilter = errors.New("filter stream PDF tidak didukung")
+
+// decode mengembalikan data stream setelah melalui filternya. Hanya
+// FlateDecode (dengan predictor PNG) yang didukung; stream seperti ini
+// cukup untuk xref stream dan object stream.
+func (d *Document) decode(s *stream) ([]byte, error) {
+	filters := d.resolve(s.dict["Filter"])
+	params := d.resolve(s.dict["DecodeParms"])
+	switch f := filters.(type) {
+	case nil:
+		return s.data, nil
+	case name:
+		return d.applyFilter(f, d.dictOf(params), s.data)
+	case array:
+		data := s.data
+		paramList, _ := params.(array)
+		for i, item := range f {
+			fn, _ := d.resolve(item).(name)
+			var p dict
+			if i < len(paramList) {
+				p = d.dictOf(paramList[i])
+			}
+			var err error
+			if data, err = d.applyFilter(fn, p, data); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("%w: %v", errUnsupportedFilter, filters)
+}
+
+func (d *Document) applyFilter(filter name, params dict, data []byte) ([]byte, error) {
+	if filter != "FlateDecode" && filter != "Fl" {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedFilter, filter)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("stream FlateDecode rusak: %w", err)
+	}
+	out, err := io.ReadAll(io.LimitReader(zr, maxDecodedBytes+1))
+	// Stream yang terpotong di akhir masih sering dapat dipakai.
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("stream FlateDecode rusak: %w", err)
+	}
+	if len(out) > maxDecodedBytes {
+		return nil, fmt.Errorf("stream melebihi batas dekompresi %d byte", maxDecodedBytes)
+	}
+	predictor, _ := d.resolve(params["Predictor"]).(int64)
+	if predictor < 10 {
+		if predictor > 1 {
+			return nil, fmt.Errorf("%w: predictor %d", errUnsupportedFilter, predictor)
+		}
+		return out, nil
+	}
+	columns := d.intOr(params["Columns"], 1)
+	colors := d.intOr(params["Colors"], 1)
+	bpc := d.intOr(params["BitsPerComponent"], 8)
+	return unpredictPNG(out, columns, colors, bpc)
+}
+
+// unpredictPNG membalik predictor PNG (Predictor >= 10): setiap baris diawali
+// satu byte jenis filter PNG.
+func unpredictPNG(data []byte, columns, colors, bpc int) ([]byte, error) {
+	if columns <= 0 || colors <= 0 || bpc <= 0 || columns*colors*bpc > 1<<20 {
+		return nil, fmt.Errorf("%w: parameter predictor tidak valid", errUnsupportedFilter)
+	}
+	bpp := max(colors*bpc/8, 1)
+	rowLen := (columns*colors*bpc + 7) / 8
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for len(data) > rowLen {
+		kind, row := data[0], data[1:rowLen+1]
+		data = data[rowLen+1:]
+		cur := make([]byte, rowLen)
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = cur[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+			switch kind {
+			case 0:
+				cur[i] = row[i]
+			case 1:
+				cur[i] = row[i] + left
+			case 2:
+				cur[i] = row[i] + up
+			case 3:
+				cur[i] = row[i] + byte((int(left)+int(up))/2)
+			case 4:
+				cur[i] = row[i] + paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("%w: jenis predictor PNG %d", errUnsupportedFilter, kind)
+			}
+		}
+		out = append(out, cur...)
+		prev = cur
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
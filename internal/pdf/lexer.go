@@ -0,0 +1,384 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// TokenKind adalah jenis token yang dihasilkan Lexer.
+type TokenKind int
+
+const (
+	// TokenName adalah nama (/Nama) dengan escape #xx sudah didekode.
+	TokenName TokenKind = iota
+	// TokenKeyword adalah token reguler: kata kunci, operator atau angka.
+	TokenKeyword
+	// TokenString adalah string literal atau hex.
+	TokenString
+	// TokenDelimiter adalah "[", "]", "<<", ">>", "{" atau "}".
+	TokenDelimiter
+	// TokenStream menandai awal data stream setelah kata kunci stream.
+	TokenStream
+	// TokenEndStream menandai akhir data stream (kata kunci endstream).
+	TokenEndStream
+)
+
+const (
+	// maxTokenBytes membatasi panjang teks nama dan kata kunci yang disimpan.
+	maxTokenBytes = 128
+	// maxStringBytes membatasi string yang didekode ke Token.Value. String
+	// yang lebih panjang tetap dilewati dengan rentang yang benar.
+	maxStringBytes = 64 << 10
+)
+
+// Token adalah satu token PDF beserta rentang byte [Start, End) di input.
+// Untuk TokenString rentang tersebut adalah isi string tanpa pembatasnya.
+// TokenStream hanya memiliki Start (awal data); TokenEndStream membawa
+// rentang data stream tanpa EOL sebelum endstream.
+type Token struct {
+	Kind       TokenKind
+	Text       string
+	Value      []byte
+	Start, End int64
+}
+
+// Lexer memecah PDF menjadi token secara berurutan tanpa memuat seluruh file
+// ke memori. Validasi unggahan, pembersihan metadata dan ekstraksi teks
+// memakai Lexer yang sama sehingga batas string, komentar dan stream dibaca
+// dengan cara yang sama. Lexer juga dapat membaca content stream halaman.
+type Lexer struct {
+	r      *bufio.Reader
+	pos    int64
+	stream *streamReader
+}
+
+// NewLexer membuat Lexer yang membaca dari r.
+func NewLexer(r io.Reader) *Lexer {
+	return &Lexer{r: bufio.NewReader(r)}
+}
+
+// Next mengembalikan token berikutnya, atau io.EOF di akhir input. Input yang
+// berakhir di tengah string atau stream menghasilkan error yang membungkus
+// ErrMalformed. Data stream yang tidak dibaca lewat StreamData dilewati.
+func (l *Lexer) Next() (Token, error) {
+	if s := l.stream; s != nil {
+		l.stream = nil
+		if _, err := io.Copy(io.Discard, s); err != nil {
+			return Token{}, err
+		}
+		return Token{Kind: TokenEndStream, Text: "endstream", Start: s.start, End: s.end}, nil
+	}
+	for {
+		start := l.pos
+		b, err := l.readByte()
+		if err != nil {
+			return Token{}, err
+		}
+		switch {
+		case isSpace(b):
+			continue
+		case b == '%':
+			if err := l.skipLine(); err != nil {
+				return Token{}, err
+			}
+			continue
+		case b == '/':
+			return l.name(start)
+		case b == '(':
+			return l.literalString()
+		case b == '<':
+			if next, err := l.readByte(); err == nil && next == '<' {
+				return Token{Kind: TokenDelimiter, Text: "<<", Start: start, End: l.pos}, nil
+			} else if err == nil {
+				l.unreadByte()
+			}
+			return l.hexString()
+		case b == '>':
+			if next, err := l.readByte(); err == nil && next != '>' {
+				l.unreadByte()
+			}
+			return Token{Kind: TokenDelimiter, Text: ">>", Start: start, End: l.pos}, nil
+		case isDelimiter(b):
+			return Token{Kind: TokenDelimiter, Text: string(b), Start: start, End: l.pos}, nil
+		default:
+			l.unreadByte()
+			return l.keyword(start)
+		}
+	}
+}
+
+// StreamData mengembalikan data mentah stream setelah Next menghasilkan
+// TokenStream, sampai sebelum EOL dan kata kunci endstream. Di luar itu
+// StreamData mengembalikan reader kosong.
+func (l *Lexer) StreamData() io.Reader {
+	if l.stream == nil {
+		return bytes.NewReader(nil)
+	}
+	return l.stream
+}
+
+// SkipInlineImage melewati data gambar inline setelah operator ID pada
+// content stream sampai operator EI.
+func (l *Lexer) SkipInlineImage() error {
+	var window [3]byte
+	for {
+		b, err := l.readByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		window[0], window[1], window[2] = window[1], window[2], b
+		if !isSpace(window[0]) || window[1] != 'E' || window[2] != 'I' {
+			continue
+		}
+		if next, err := l.r.Peek(1); err != nil || isSpace(next[0]) || isDelimiter(next[0]) {
+			return nil
+		}
+	}
+}
+
+func (l *Lexer) readByte() (byte, error) {
+	b, err := l.r.ReadByte()
+	if err == nil {
+		l.pos++
+	}
+	return b, err
+}
+
+func (l *Lexer) unreadByte() {
+	_ = l.r.UnreadByte()
+	l.pos--
+}
+
+func (l *Lexer) skipLine() error {
+	for {
+		b, err := l.readByte()
+		if err != nil {
+			return err
+		}
+		if b == '\r' || b == '\n' {
+			return nil
+		}
+	}
+}
+
+func (l *Lexer) name(start int64) (Token, error) {
+	var text []byte
+	for {
+		b, err := l.readByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Token{}, err
+		}
+		if isSpace(b) || isDelimiter(b) {
+			l.unreadByte()
+			break
+		}
+		if b == '#' {
+			if digits, err := l.r.Peek(2); err == nil {
+				hi, okHi := unhex(digits[0])
+				lo, okLo := unhex(digits[1])
+				if okHi && okLo {
+					_, _ = l.readByte()
+					_, _ = l.readByte()
+					b = hi<<4 | lo
+				}
+			}
+		}
+		if len(text) < maxTokenBytes {
+			text = append(text, b)
+		}
+	}
+	return Token{Kind: TokenName, Text: string(text), Start: start, End: l.pos}, nil
+}
+
+func (l *Lexer) keyword(start int64) (Token, error) {
+	var text []byte
+	for {
+		b, err := l.readByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Token{}, err
+		}
+		if isSpace(b) || isDelimiter(b) {
+			l.unreadByte()
+			break
+		}
+		if len(text) < maxTokenBytes {
+			text = append(text, b)
+		}
+	}
+	if string(text) == "stream" {
+		return l.startStream()
+	}
+	return Token{Kind: TokenKeyword, Text: string(text), Start: start, End: l.pos}, nil
+}
+
+// literalString membaca string literal dan mendekodenya dengan aturan escape
+// yang sama seperti parser objek.
+func (l *Lexer) literalString() (Token, error) {
+	token := Token{Kind: TokenString, Start: l.pos}
+	raw := []byte{'('}
+	depth, escape := 1, false
+	for {
+		b, err := l.readByte()
+		if err != nil {
+			return Token{}, fmt.Errorf("%w: string tidak ditutup", ErrMalformed)
+		}
+		if len(raw) <= maxStringBytes {
+			raw = append(raw, b)
+		}
+		switch {
+		case escape:
+			escape = false
+		case b == '\\':
+			escape = true
+		case b == '(':
+			depth++
+		case b == ')':
+			if depth--; depth > 0 {
+				continue
+			}
+			token.End = l.pos - 1
+			if len(raw) <= maxStringBytes {
+				token.Value, _ = (&parser{data: raw}).literalString()
+			}
+			return token, nil
+		}
+	}
+}
+
+func (l *Lexer) hexString() (Token, error) {
+	token := Token{Kind: TokenString, Start: l.pos}
+	raw := []byte{'<'}
+	for {
+		b, err := l.readByte()
+		if err != nil {
+			return Token{}, fmt.Errorf("%w: string hex tidak ditutup", ErrMalformed)
+		}
+		if len(raw) <= maxStringBytes {
+			raw = append(raw, b)
+		}
+		if b != '>' {
+			continue
+		}
+		token.End = l.pos - 1
+		if len(raw) <= maxStringBytes {
+			token.Value, _ = (&parser{data: raw}).hexString()
+		}
+		return token, nil
+	}
+}
+
+// startStream melewati EOL setelah kata kunci stream dan menyiapkan reader
+// data stream.
+func (l *Lexer) startStream() (Token, error) {
+	b, err := l.readByte()
+	if err != nil {
+		return Token{}, fmt.Errorf("%w: stream terpotong", ErrMalformed)
+	}
+	if b == '\r' {
+		if b, err = l.readByte(); err == nil && b != '\n' {
+			l.unreadByte()
+		}
+	} else if b != '\n' {
+		l.unreadByte()
+	}
+	l.stream = &streamReader{l: l, start: l.pos}
+	return Token{Kind: TokenStream, Text: "stream", Start: l.pos}, nil
+}
+
+// endStreamMarker mengakhiri data stream. Seperti pembaca PDF yang toleran,
+// Length diabaikan karena sering salah pada file yang diunggah.
+var endStreamMarker = []byte("endstream")
+
+// streamReader membaca data stream sampai endstream. Beberapa byte terakhir
+// ditahan agar EOL sebelum endstream tidak ikut dikembalikan.
+type streamReader struct {
+	l          *Lexer
+	start, end int64
+	held       []byte
+	ready      []byte
+	done       bool
+	err        error
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.ready) > 0 {
+			c := copy(p[n:], s.ready)
+			s.ready = s.ready[c:]
+			n += c
+			continue
+		}
+		if s.done || s.err != nil {
+			break
+		}
+		b, err := s.l.readByte()
+		if err == io.EOF {
+			s.err = fmt.Errorf("%w: endstream tidak ditemukan", ErrMalformed)
+			break
+		}
+		if err != nil {
+			s.err = err
+			break
+		}
+		s.held = append(s.held, b)
+		if bytes.HasSuffix(s.held, endStreamMarker) {
+			data := s.held[:len(s.held)-len(endStreamMarker)]
+			s.end = s.l.pos - int64(len(endStreamMarker))
+			switch {
+			case bytes.HasSuffix(data, []byte("\r\n")):
+				data = data[:len(data)-2]
+			case bytes.HasSuffix(data, []byte("\n")), bytes.HasSuffix(data, []byte("\r")):
+				data = data[:len(data)-1]
+			}
+			s.end -= int64(len(s.held) - len(endStreamMarker) - len(data))
+			s.ready, s.done = data, true
+			continue
+		}
+		if len(s.held) > len(endStreamMarker)+2 {
+			p[n] = s.held[0]
+			n++
+			s.held = append(s.held[:0], s.held[1:]...)
+		}
+	}
+	if n > 0 {
+		return n, nil
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+	return 0, io.EOF
+}
+
+// Inflate mengembalikan data stream yang didekompresi jika r diawali header
+// zlib (FlateDecode), dan melaporkan apakah dekompresi dilakukan. Data lain
+// dikembalikan apa adanya. Header zlib yang rusak menghasilkan reader kosong.
+func Inflate(r io.Reader) (io.Reader, bool) {
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(2); !isZlibHeader(head) {
+		return br, false
+	}
+	// bufio.Reader adalah io.ByteReader sehingga zlib tidak membaca melewati
+	// akhir data terkompresi.
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return bytes.NewReader(nil), true
+	}
+	return zr, true
+}
+
+func isZlibHeader(head []byte) bool {
+	return len(head) == 2 && head[0]&0x0F == 8 && (uint16(head[0])<<8|uint16(head[1]))%31 == 0
+}
@@ -0,0 +1,255 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// ErrNoPages dikembalikan Merge jika tidak ada halaman yang dapat ditulis.
+var ErrNoPages = errors.New("tidak ada halaman PDF untuk ditulis")
+
+// Part adalah halaman dari satu dokumen sumber untuk Merge.
+type Part struct {
+	Doc *Document
+	// Pages berisi indeks halaman (mulai 0) tanpa duplikat; nil berarti
+	// semua halaman.
+	Pages []int
+}
+
+// Merge menulis dokumen PDF baru berisi halaman dari setiap part secara
+// berurutan. Objek yang dipakai halaman (resources, font, gambar, anotasi)
+// disalin dengan nomor baru; struktur tingkat dokumen seperti bookmark,
+// formulir (AcroForm) dan tag aksesibilitas tidak ikut disalin. Dokumen
+// terenkripsi ditolak dengan ErrEncrypted.
+func Merge(dst io.Writer, parts []Part) error {
+	w := &writer{out: bufio.NewWriter(dst)}
+	w.raw("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n")
+	catalog, pages := w.alloc(), w.alloc()
+
+	var kids array
+	for i, part := range parts {
+		if part.Doc.Encrypted() {
+			return fmt.Errorf("dokumen ke-%d: %w", i+1, ErrEncrypted)
+		}
+		selected := part.Pages
+		if selected == nil {
+			selected = make([]int, part.Doc.NumPages())
+			for j := range selected {
+				selected[j] = j
+			}
+		}
+		c := newCopier(part.Doc, w)
+		for _, index := range selected {
+			if index < 0 || index >= part.Doc.NumPages() {
+				return fmt.Errorf("dokumen ke-%d tidak memiliki halaman %d", i+1, index+1)
+			}
+			c.pages[part.Doc.pages[index].ref.num] = w.alloc()
+		}
+		for _, index := range selected {
+			pageDict := part.Doc.pageDict(index)
+			delete(pageDict, "Parent")
+			delete(pageDict, "B")
+			copied := c.remap(pageDict).(dict)
+			copied["Parent"] = ref{num: pages}
+			num := c.pages[part.Doc.pages[index].ref.num]
+			w.object(num, copied)
+			kids = append(kids, ref{num: num})
+		}
+		c.flush()
+	}
+	if len(kids) == 0 {
+		return ErrNoPages
+	}
+
+	w.object(pages, dict{"Type": name("Pages"), "Kids": kids, "Count": int64(len(kids))})
+	w.object(catalog, dict{"Type": name("Catalog"), "Pages": ref{num: pages}})
+	w.finish(catalog)
+	return w.err
+}
+
+// copier menyalin objek dari satu dokumen sumber dengan nomor objek baru.
+type copier struct {
+	doc    *Document
+	w      *writer
+	mapped map[int]int
+	// pages memetakan nomor objek semua halaman sumber ke nomor barunya; 0
+	// untuk halaman yang tidak disalin sehingga referensinya menjadi null.
+	pages map[int]int
+	queue []int
+}
+
+func newCopier(doc *Document, w *writer) *copier {
+	c := &copier{doc: doc, w: w, mapped: make(map[int]int), pages: make(map[int]int, len(doc.pages))}
+	for _, p := range doc.pages {
+		c.pages[p.ref.num] = 0
+	}
+	return c
+}
+
+// remap mengganti referensi di obj dengan nomor baru. Objek yang dirujuk
+// dimasukkan antrean dan ditulis oleh flush.
+func (c *copier) remap(obj object) object {
+	switch v := obj.(type) {
+	case ref:
+		if num, isPage := c.pages[v.num]; isPage {
+			if num == 0 {
+				return nil
+			}
+			return ref{num: num}
+		}
+		if num, ok := c.mapped[v.num]; ok {
+			return ref{num: num}
+		}
+		num := c.w.alloc()
+		c.mapped[v.num] = num
+		c.queue = append(c.queue, v.num)
+		return ref{num: num}
+	case array:
+		out := make(array, len(v))
+		for i, item := range v {
+			out[i] = c.remap(item)
+		}
+		return out
+	case dict:
+		out := make(dict, len(v))
+		for k, item := range v {
+			out[k] = c.remap(item)
+		}
+		return out
+	case *stream:
+		header := c.remap(v.dict).(dict)
+		header["Length"] = int64(len(v.data))
+		return &stream{dict: header, data: v.data}
+	}
+	return obj
+}
+
+// flush menulis semua objek di antrean. Node pohon halaman dan katalog
+// sumber ditulis sebagai null agar dokumen asal tidak ikut tersalin lewat
+// referensi balik.
+func (c *copier) flush() {
+	for len(c.queue) > 0 {
+		old := c.queue[0]
+		c.queue = c.queue[1:]
+		obj := c.doc.load(old)
+		if d := c.doc.dictOf(obj); d != nil && (d["Type"] == name("Pages") || d["Type"] == name("Catalog")) {
+			obj = nil
+		}
+		c.w.object(c.mapped[old], c.remap(obj))
+	}
+}
+
+// writer menulis objek PDF berurutan dan mencatat offset-nya untuk tabel
+// xref. Error pertama disimpan di err.
+type writer struct {
+	out     *bufio.Writer
+	offset  int64
+	offsets []int64
+	err     error
+}
+
+func (w *writer) alloc() int {
+	w.offsets = append(w.offsets, -1)
+	return len(w.offsets)
+}
+
+func (w *writer) raw(s string) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.out.WriteString(s)
+	w.offset += int64(n)
+	w.err = err
+}
+
+func (w *writer) object(num int, obj object) {
+	w.offsets[num-1] = w.offset
+	var b bytes.Buffer
+	b.WriteString(strconv.Itoa(num))
+	b.WriteString(" 0 obj\n")
+	if s, ok := obj.(*stream); ok {
+		writeValue(&b, s.dict)
+		b.WriteString("\nstream\n")
+		b.Write(s.data)
+		b.WriteString("\nendstream")
+	} else {
+		writeValue(&b, obj)
+	}
+	b.WriteString("\nendobj\n")
+	w.raw(b.String())
+}
+
+// finish menulis tabel xref dan trailer.
+func (w *writer) finish(root int) {
+	start := w.offset
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f\r\n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		if offset < 0 {
+			w.err = errors.New("objek PDF dialokasikan tetapi tidak ditulis")
+			return
+		}
+		fmt.Fprintf(&b, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, root, start)
+	w.raw(b.String())
+	if w.err == nil {
+		w.err = w.out.Flush()
+	}
+}
+
+func writeValue(b *bytes.Buffer, obj object) {
+	switch v := obj.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case name:
+		b.WriteByte('/')
+		for i := 0; i < len(v); i++ {
+			c := v[i]
+			if c < 0x21 || c > 0x7E || c == '#' || isDelimiter(c) {
+				fmt.Fprintf(b, "#%02X", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	case pdfString:
+		b.WriteByte('<')
+		fmt.Fprintf(b, "%X", []byte(v))
+		b.WriteByte('>')
+	case ref:
+		fmt.Fprintf(b, "%d %d R", v.num, v.gen)
+	case array:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			writeValue(b, item)
+		}
+		b.WriteByte(']')
+	case dict:
+		keys := make([]name, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		b.WriteString("<<")
+		for _, k := range keys {
+			writeValue(b, k)
+			b.WriteByte(' ')
+			writeValue(b, v[k])
+		}
+		b.WriteString(">>")
+	}
+}
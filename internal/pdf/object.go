@@ -0,0 +1,325 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Tipe objek PDF. Nilai objek berupa nil (null), bool, int64, float64,
+// pdfString, name, array, dict, ref atau *stream.
+type (
+	object    any
+	name      string
+	pdfString []byte
+	array     []object
+	dict      map[name]object
+	ref       struct{ num, gen int }
+)
+
+// stream adalah objek stream dengan data yang masih terkode (belum melalui
+// filter).
+type stream struct {
+	dict dict
+	data []byte
+}
+
+// maxNesting membatasi kedalaman array dan dictionary bersarang agar dokumen
+// berbahaya tidak menghabiskan stack.
+const maxNesting = 64
+
+var errSyntax = errors.New("sintaks objek PDF tidak valid")
+
+// parser membaca objek PDF dari data mulai posisi pos.
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.data) }
+
+// skipSpace melewati spasi dan komentar.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch b := p.data[p.pos]; {
+		case isSpace(b):
+			p.pos++
+		case b == '%':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token membaca satu token reguler (kata kunci atau angka) tanpa
+// mengonsumsinya jika peek bernilai true.
+func (p *parser) token(peek bool) string {
+	p.skipSpace()
+	end := p.pos
+	for end < len(p.data) && !isSpace(p.data[end]) && !isDelimiter(p.data[end]) {
+		end++
+	}
+	tok := string(p.data[p.pos:end])
+	if !peek {
+		p.pos = end
+	}
+	return tok
+}
+
+// keyword mengonsumsi kata kunci kw jika token berikutnya sama persis.
+func (p *parser) keyword(kw string) bool {
+	if p.token(true) != kw {
+		return false
+	}
+	p.token(false)
+	return true
+}
+
+// integer membaca bilangan bulat non-negatif.
+func (p *parser) integer() (int64, bool) {
+	start := p.pos
+	n, err := strconv.ParseInt(p.token(false), 10, 64)
+	if err != nil || n < 0 {
+		p.pos = start
+		return 0, false
+	}
+	return n, true
+}
+
+// object membaca satu objek langsung. Referensi "n g R" dikenali dengan
+// melihat dua token berikutnya.
+func (p *parser) object(depth int) (object, error) {
+	if depth > maxNesting {
+		return nil, fmt.Errorf("%w: objek bersarang terlalu dalam", errSyntax)
+	}
+	p.skipSpace()
+	if p.eof() {
+		return nil, fmt.Errorf("%w: akhir data tak terduga", errSyntax)
+	}
+	switch b := p.data[p.pos]; {
+	case b == '/':
+		return p.name(), nil
+	case b == '(':
+		return p.literalString()
+	case b == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		return p.dict(depth)
+	case b == '<':
+		return p.hexString()
+	case b == '[':
+		return p.array(depth)
+	case b == '+' || b == '-' || b == '.' || (b >= '0' && b <= '9'):
+		return p.number()
+	}
+	switch tok := p.token(false); tok {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: token %q tak terduga", errSyntax, tok)
+	}
+}
+
+func (p *parser) name() name {
+	p.pos++ // '/'
+	var buf []byte
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		b := p.data[p.pos]
+		if b == '#' && p.pos+2 < len(p.data) {
+			hi, okHi := unhex(p.data[p.pos+1])
+			lo, okLo := unhex(p.data[p.pos+2])
+			if okHi && okLo {
+				buf = append(buf, hi<<4|lo)
+				p.pos += 3
+				continue
+			}
+		}
+		buf = append(buf, b)
+		p.pos++
+	}
+	return name(buf)
+}
+
+func (p *parser) literalString() (pdfString, error) {
+	p.pos++ // '('
+	var buf []byte
+	level := 1
+	for p.pos < len(p.data) {
+		b := p.data[p.pos]
+		p.pos++
+		switch b {
+		case '(':
+			level++
+		case ')':
+			level--
+			if level == 0 {
+				return buf, nil
+			}
+		case '\\':
+			if p.eof() {
+				return nil, fmt.Errorf("%w: string tidak ditutup", errSyntax)
+			}
+			b = p.data[p.pos]
+			p.pos++
+			switch b {
+			case 'n':
+				b = '\n'
+			case 'r':
+				b = '\r'
+			case 't':
+				b = '\t'
+			case 'b':
+				b = '\b'
+			case 'f':
+				b = '\f'
+			case '\r':
+				if !p.eof() && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if b >= '0' && b <= '7' {
+					v := int(b - '0')
+					for i := 0; i < 2 && !p.eof() && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					b = byte(v)
+				}
+			}
+		}
+		buf = append(buf, b)
+	}
+	return nil, fmt.Errorf("%w: string tidak ditutup", errSyntax)
+}
+
+func (p *parser) hexString() (pdfString, error) {
+	p.pos++ // '<'
+	var digits []byte
+	for p.pos < len(p.data) {
+		b := p.data[p.pos]
+		p.pos++
+		if b == '>' {
+			if len(digits)%2 == 1 {
+				digits = append(digits, 0)
+			}
+			buf := make([]byte, len(digits)/2)
+			for i := range buf {
+				buf[i] = digits[2*i]<<4 | digits[2*i+1]
+			}
+			return buf, nil
+		}
+		if isSpace(b) {
+			continue
+		}
+		v, ok := unhex(b)
+		if !ok {
+			return nil, fmt.Errorf("%w: karakter hex %q tidak valid", errSyntax, b)
+		}
+		digits = append(digits, v)
+	}
+	return nil, fmt.Errorf("%w: string hex tidak ditutup", errSyntax)
+}
+
+func (p *parser) array(depth int) (array, error) {
+	p.pos++ // '['
+	arr := array{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, fmt.Errorf("%w: array tidak ditutup", errSyntax)
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return arr, nil
+		}
+		v, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+}
+
+func (p *parser) dict(depth int) (dict, error) {
+	p.pos += 2 // "<<"
+	d := dict{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, fmt.Errorf("%w: dictionary tidak ditutup", errSyntax)
+		}
+		if bytes.HasPrefix(p.data[p.pos:], []byte(">>")) {
+			p.pos += 2
+			return d, nil
+		}
+		if p.data[p.pos] != '/' {
+			return nil, fmt.Errorf("%w: kunci dictionary bukan name", errSyntax)
+		}
+		key := p.name()
+		v, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		// Nilai null sama dengan kunci yang tidak ada.
+		if v != nil {
+			d[key] = v
+		}
+	}
+}
+
+func (p *parser) number() (object, error) {
+	tok := p.token(false)
+	if n, err := strconv.ParseInt(tok, 10, 64); err == nil {
+		// Bilangan bulat non-negatif mungkin awal referensi "n g R".
+		if n >= 0 && tok[0] != '+' && tok[0] != '-' {
+			save := p.pos
+			if gen, ok := p.integer(); ok && p.keyword("R") {
+				return ref{num: int(n), gen: int(gen)}, nil
+			}
+			p.pos = save
+		}
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(tok, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: angka %q tidak valid", errSyntax, tok)
+	}
+	return f, nil
+}
+
+func isSpace(b byte) bool {
+	switch b {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(b byte) bool {
+	switch b {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func unhex(b byte) (byte, bool) {
+	switch {
+	case b >= '0' && b <= '9':
+		return b - '0', true
+	case b >= 'a' && b <= 'f':
+		return b - 'a' + 10, true
+	case b >= 'A' && b <= 'F':
+		return b - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,359 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF menyusun PDF dengan tabel xref yang benar. objects[i] adalah isi
+// objek nomor i+1 (tanpa "obj"/"endobj").
+func buildPDF(trailer string, objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	start := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, start)
+	return b.Bytes()
+}
+
+func streamObject(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(t *testing.T, data []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+// twoPageDoc memiliki MediaBox dan Resources yang diwarisi dari node Pages,
+// serta tautan dari halaman 1 ke halaman 2.
+func twoPageDoc() []byte {
+	return buildPDF("/Root 1 0 R /Info 7 0 R",
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 8 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] /Resources << /Font << /F1 6 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R /Annots [<< /Type /Annot /Subtype /Link /Dest [4 0 R /Fit] >>] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R /Rotate 90 >>",
+		streamObject("", "BT /F1 12 Tf (Halo) Tj ET"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Title (Laporan \\(final\\)) /Author <FEFF0041006E00690020> /Producer (Pembuat\\351) >>",
+		"<< /Type /Outlines /Count 0 >>",
+	)
+}
+
+func TestOpen(t *testing.T) {
+	doc, err := Open(twoPageDoc())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, doc.NumPages())
+	assert.False(t, doc.Encrypted())
+	assert.Equal(t, Info{Title: "Laporan (final)", Author: "Ani", Producer: "Pembuaté"}, doc.Info())
+
+	first := doc.pageDict(0)
+	assert.Equal(t, array{int64(0), int64(0), int64(612), int64(792)}, first["MediaBox"], "MediaBox diwarisi dari node Pages")
+	assert.NotNil(t, first["Resources"])
+	assert.Equal(t, int64(90), doc.pageDict(1)["Rotate"])
+}
+
+func TestOpen_XrefStreamAndObjectStream(t *testing.T) {
+	// Objek 2 (Pages) dan 3 (Page) berada di object stream nomor 4.
+	pagesDict := "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"
+	members := pagesDict + " << /Type /Page /Parent 2 0 R /MediaBox [0 0 100 100] >>"
+	header := fmt.Sprintf("2 0 3 %d ", len(pagesDict)+1)
+	objStm := deflate(t, []byte(header+members))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+	catalogOffset := b.Len()
+	b.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	objStmOffset := b.Len()
+	fmt.Fprintf(&b, "4 0 obj\n<< /Type /ObjStm /N 2 /First %d /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(header), len(objStm), objStm)
+	xrefOffset := b.Len()
+
+	// Baris xref stream dengan W [1 2 1] dan predictor PNG Up.
+	rows := [][]byte{
+		{0, 0, 0, 0},
+		{1, byte(catalogOffset >> 8), byte(catalogOffset), 0},
+		{2, 0, 4, 0},
+		{2, 0, 4, 1},
+		{1, byte(objStmOffset >> 8), byte(objStmOffset), 0},
+		{1, byte(xrefOffset >> 8), byte(xrefOffset), 0},
+	}
+	var predicted []byte
+	prev := make([]byte, 4)
+	for _, row := range rows {
+		predicted = append(predicted, 2)
+		for i := range row {
+			predicted = append(predicted, row[i]-prev[i])
+		}
+		prev = row
+	}
+	xrefData := deflate(t, predicted)
+	fmt.Fprintf(&b, "5 0 obj\n<< /Type /XRef /Size 6 /Root 1 0 R /W [1 2 1] /Filter /FlateDecode /DecodeParms << /Predictor 12 /Columns 4 >> /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(xrefData), xrefData)
+	fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	doc, err := Open(b.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 1, doc.NumPages())
+	assert.Equal(t, 4, doc.xref[2].container, "Xref harus dibaca dari stream, bukan dipindai ulang")
+	width, height, ok := doc.pageSize(doc.pageDict(0))
+	require.True(t, ok)
+	assert.Equal(t, []float64{100, 100}, []float64{width, height})
+}
+
+func TestOpen_RebuildsBrokenXref(t *testing.T) {
+	content := twoPageDoc()
+	// Offset startxref yang salah memaksa xref disusun ulang.
+	i := bytes.LastIndex(content, []byte("startxref\n"))
+	broken := append(append([]byte{}, content[:i]...), []byte("startxref\n3\n%%EOF\n")...)
+
+	doc, err := Open(broken)
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.NumPages())
+	assert.Equal(t, "Ani", doc.Info().Author)
+}
+
+func TestOpen_Rejects(t *testing.T) {
+	testCases := map[string][]byte{
+		"Not a PDF":  []byte("bukan pdf"),
+		"No catalog": []byte("%PDF-1.4\n1 0 obj\n<< /Type /Font >>\nendobj\n"),
+		"Page tree cycle": buildPDF("/Root 1 0 R",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] >>",
+			"<< /Type /Pages /Kids [2 0 R] >>"),
+	}
+	for name, content := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Open(content)
+			assert.ErrorIs(t, err, ErrMalformed)
+		})
+	}
+}
+
+func TestOpen_Encrypted(t *testing.T) {
+	doc, err := Open(buildPDF("/Root 1 0 R /Encrypt 4 0 R /Info 5 0 R",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Filter /Standard /V 2 >>",
+		"<< /Title <8F3A> >>"))
+	require.NoError(t, err)
+	assert.True(t, doc.Encrypted())
+	assert.Equal(t, 1, doc.NumPages())
+	assert.Equal(t, Info{}, doc.Info())
+
+	err = Merge(&bytes.Buffer{}, []Part{{Doc: doc}})
+	assert.ErrorIs(t, err, ErrEncrypted)
+}
+
+func TestMerge(t *testing.T) {
+	first, err := Open(twoPageDoc())
+	require.NoError(t, err)
+	second, err := Open(buildPDF("/Root 1 0 R",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 300 300] /Contents 4 0 R >>",
+		streamObject("/Filter /FlateDecode", "data-biner-apa-adanya")))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, Merge(&out, []Part{{Doc: first}, {Doc: second}, {Doc: first, Pages: []int{1}}}))
+
+	merged, err := Open(out.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 4, merged.NumPages())
+	assert.Equal(t, 0, strings.Count(out.String(), "/Outlines"), "Struktur tingkat dokumen tidak disalin")
+	assert.Equal(t, 2, strings.Count(out.String(), "/BaseFont /Helvetica"), "Font bersama disalin sekali per part")
+	assert.Contains(t, out.String(), "stream\ndata-biner-apa-adanya\nendstream", "Stream disalin tanpa dikodekan ulang")
+
+	page1 := merged.pageDict(0)
+	assert.Equal(t, array{int64(0), int64(0), int64(612), int64(792)}, page1["MediaBox"], "Atribut warisan disalin ke halaman")
+	assert.Equal(t, name("Pages"), merged.dictOf(page1["Parent"])["Type"])
+	link := merged.dictOf(merged.arrayOf(page1["Annots"])[0])
+	assert.Equal(t, merged.pages[1].ref, merged.arrayOf(link["Dest"])[0], "Tautan ke halaman yang ikut disalin dipertahankan")
+	assert.Equal(t, int64(90), merged.pageDict(1)["Rotate"])
+	assert.Equal(t, array{int64(0), int64(0), int64(300), int64(300)}, merged.pageDict(2)["MediaBox"])
+	assert.Equal(t, int64(90), merged.pageDict(3)["Rotate"])
+
+	var single bytes.Buffer
+	require.NoError(t, Merge(&single, []Part{{Doc: first, Pages: []int{0}}}))
+	onePage, err := Open(single.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 1, onePage.NumPages())
+	link = onePage.dictOf(onePage.arrayOf(onePage.pageDict(0)["Annots"])[0])
+	assert.Nil(t, onePage.arrayOf(link["Dest"])[0], "Tautan ke halaman yang tidak disalin menjadi null")
+
+	assert.ErrorIs(t, Merge(&bytes.Buffer{}, nil), ErrNoPages)
+	assert.Error(t, Merge(&bytes.Buffer{}, []Part{{Doc: first, Pages: []int{5}}}))
+}
+
+func TestPageImage(t *testing.T) {
+	var scan bytes.Buffer
+	require.NoError(t, jpeg.Encode(&scan, image.NewGray(image.Rect(0, 0, 850, 1100)), nil))
+	var logo bytes.Buffer
+	require.NoError(t, jpeg.Encode(&logo, image.NewGray(image.Rect(0, 0, 400, 100)), nil))
+	imageObject := func(width, height int, data string) string {
+		return streamObject(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode", width, height), data)
+	}
+	doc := func(pageExtra string, images ...string) *Document {
+		objects := []string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		}
+		var xobjects []string
+		for i := range images {
+			xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i, i+4))
+		}
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << %s >> >> %s >>", strings.Join(xobjects, " "), pageExtra))
+		objects = append(objects, images...)
+		d, err := Open(buildPDF("/Root 1 0 R", objects...))
+		require.NoError(t, err)
+		return d
+	}
+
+	got, ok := doc("", imageObject(400, 100, logo.String()), imageObject(850, 1100, scan.String())).PageImage(0)
+	require.True(t, ok)
+	assert.Equal(t, scan.Bytes(), got)
+
+	_, ok = doc("", imageObject(400, 100, logo.String())).PageImage(0)
+	assert.False(t, ok, "Logo kecil bukan pindaian halaman")
+	_, ok = doc("/Rotate 90", imageObject(850, 1100, scan.String())).PageImage(0)
+	assert.False(t, ok, "Halaman diputar dilewati")
+	_, ok = doc("").PageImage(0)
+	assert.False(t, ok)
+	_, ok = doc("").PageImage(3)
+	assert.False(t, ok)
+}
+
+// lexAll mengembalikan semua token input beserta data setiap stream.
+func lexAll(t *testing.T, input string) ([]Token, []string, error) {
+	t.Helper()
+	lexer := NewLexer(strings.NewReader(input))
+	var (
+		tokens  []Token
+		streams []string
+	)
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			return tokens, streams, nil
+		}
+		if err != nil {
+			return tokens, streams, err
+		}
+		if token.Kind == TokenStream {
+			data, err := io.ReadAll(lexer.StreamData())
+			if err != nil {
+				return tokens, streams, err
+			}
+			streams = append(streams, string(data))
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestLexer(t *testing.T) {
+	input := "1 0 obj % komentar /JS\n<< /S /J#61vaScript /T (a \\) (b)) /H <48 49> >>\nstream\r\ndata endstre4m\r\nendstream\nendobj"
+	tokens, streams, err := lexAll(t, input)
+	require.NoError(t, err)
+
+	var kinds []TokenKind
+	var texts []string
+	for _, token := range tokens {
+		kinds = append(kinds, token.Kind)
+		texts = append(texts, token.Text)
+	}
+	assert.Equal(t, []TokenKind{
+		TokenKeyword, TokenKeyword, TokenKeyword, TokenDelimiter,
+		TokenName, TokenName, TokenName, TokenString, TokenName, TokenString,
+		TokenDelimiter, TokenStream, TokenEndStream, TokenKeyword,
+	}, kinds)
+	assert.Equal(t, []string{"1", "0", "obj", "<<", "S", "JavaScript", "T", "", "H", "", ">>", "stream", "endstream", "endobj"}, texts)
+	assert.Equal(t, []string{"data endstre4m"}, streams, "Awal marker yang tidak berlanjut tetap menjadi data")
+
+	literal, hex := tokens[7], tokens[9]
+	assert.Equal(t, "a ) (b)", string(literal.Value))
+	assert.Equal(t, `a \) (b)`, input[literal.Start:literal.End], "Rentang string tanpa pembatas")
+	assert.Equal(t, "HI", string(hex.Value))
+
+	end := tokens[12]
+	assert.Equal(t, "data endstre4m", input[end.Start:end.End], "Rentang stream tanpa EOL penutup")
+	assert.Equal(t, tokens[11].Start, end.Start)
+}
+
+func TestLexer_SkipsUnreadStreams(t *testing.T) {
+	lexer := NewLexer(strings.NewReader("stream\n((( /JS\nendstream /Launch"))
+	var names []string
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if token.Kind == TokenName {
+			names = append(names, token.Text)
+		}
+	}
+	assert.Equal(t, []string{"Launch"}, names)
+}
+
+func TestLexer_Malformed(t *testing.T) {
+	for _, input := range []string{"(tidak ditutup", "<414243", "stream\ntanpa akhir"} {
+		t.Run(input, func(t *testing.T) {
+			_, _, err := lexAll(t, input)
+			assert.ErrorIs(t, err, ErrMalformed)
+		})
+	}
+}
+
+func TestLexer_SkipInlineImage(t *testing.T) {
+	lexer := NewLexer(strings.NewReader("BI /W 2 ID \x00EIx\xff EI (teks) Tj"))
+	var texts []string
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		texts = append(texts, token.Text)
+		if token.Text == "ID" {
+			require.NoError(t, lexer.SkipInlineImage())
+		}
+	}
+	assert.Equal(t, []string{"BI", "W", "2", "ID", "", "Tj"}, texts)
+}
+
+func TestInflate(t *testing.T) {
+	r, compressed := Inflate(strings.NewReader(deflate(t, []byte("isi stream"))))
+	require.True(t, compressed)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "isi stream", string(data))
+
+	r, compressed = Inflate(strings.NewReader("BT (teks) Tj ET"))
+	assert.False(t, compressed)
+	data, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "BT (teks) Tj ET", string(data))
+}
@@ -0,0 +1,96 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+)
+
+const (
+	// minScanSide adalah lebar/tinggi terkecil (piksel) gambar yang dianggap
+	// hasil pindaian halaman.
+	minScanSide = 200
+	// scanAspectTolerance adalah selisih rasio aspek gambar terhadap halaman
+	// yang masih dianggap menutupi seluruh halaman.
+	scanAspectTolerance = 0.05
+)
+
+// PageImage mengembalikan JPEG hasil pindaian yang menutupi halaman i, yaitu
+// image XObject DCTDecode terbesar di resources halaman dengan rasio aspek
+// yang sama dengan halaman. Halaman berisi teks atau grafik vektor tidak
+// dapat dipratinjau tanpa menafsirkan content stream, sehingga ok bernilai
+// false. Halaman yang diputar (/Rotate) dan gambar CMYK juga dilewati.
+func (d *Document) PageImage(i int) (jpeg []byte, ok bool) {
+	if i < 0 || i >= len(d.pages) || d.Encrypted() {
+		return nil, false
+	}
+	p := d.pageDict(i)
+	if d.intOr(p["Rotate"], 0)%360 != 0 {
+		return nil, false
+	}
+	pageWidth, pageHeight, ok := d.pageSize(p)
+	if !ok {
+		return nil, false
+	}
+
+	var best *stream
+	bestArea := 0
+	for _, obj := range d.dictOf(d.dictOf(p["Resources"])["XObject"]) {
+		s, isStream := d.resolve(obj).(*stream)
+		if !isStream || !d.isScanImage(s) {
+			continue
+		}
+		width, height := d.intOr(s.dict["Width"], 0), d.intOr(s.dict["Height"], 0)
+		if width < minScanSide || height < minScanSide {
+			continue
+		}
+		aspect := float64(width) / float64(height)
+		if math.Abs(aspect-pageWidth/pageHeight)/(pageWidth/pageHeight) > scanAspectTolerance {
+			continue
+		}
+		if area := width * height; area > bestArea {
+			best, bestArea = s, area
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+	return best.data, true
+}
+
+// isScanImage melaporkan apakah s adalah gambar JPEG (hanya DCTDecode) yang
+// dapat dipakai apa adanya.
+func (d *Document) isScanImage(s *stream) bool {
+	if s.dict["Subtype"] != name("Image") || s.dict["ImageMask"] == true || s.dict["Decode"] != nil {
+		return false
+	}
+	filter := d.resolve(s.dict["Filter"])
+	if filters, isArray := filter.(array); isArray && len(filters) == 1 {
+		filter = d.resolve(filters[0])
+	}
+	if filter != name("DCTDecode") && filter != name("DCT") {
+		return false
+	}
+	if d.resolve(s.dict["ColorSpace"]) == name("DeviceCMYK") {
+		return false
+	}
+	return bytes.HasPrefix(s.data, []byte{0xFF, 0xD8})
+}
+
+// pageSize mengembalikan ukuran halaman dari CropBox atau MediaBox.
+func (d *Document) pageSize(p dict) (width, height float64, ok bool) {
+	box := d.arrayOf(p["CropBox"])
+	if len(box) != 4 {
+		box = d.arrayOf(p["MediaBox"])
+	}
+	if len(box) != 4 {
+		return 0, 0, false
+	}
+	var v [4]float64
+	for i := range v {
+		if v[i], ok = d.number(box[i]); !ok {
+			return 0, 0, false
+		}
+	}
+	width, height = math.Abs(v[2]-v[0]), math.Abs(v[3]-v[1])
+	return width, height, width > 0 && height > 0
+}
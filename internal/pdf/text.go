@@ -0,0 +1,45 @@
+package pdf
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxTextRunes membatasi panjang properti dokumen yang dikembalikan Info.
+const maxTextRunes = 1000
+
+func decodeText(s []byte) string {
+	var text string
+	switch {
+	case len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF:
+		units := make([]uint16, 0, (len(s)-2)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		text = string(utf16.Decode(units))
+	case len(s) >= 3 && s[0] == 0xEF && s[1] == 0xBB && s[2] == 0xBF:
+		text = strings.ToValidUTF8(string(s[3:]), "")
+	default:
+		runes := make([]rune, len(s))
+		for i, b := range s {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+
+	var b strings.Builder
+	count := 0
+	for _, r := range strings.TrimSpace(text) {
+		if count == maxTextRunes {
+			break
+		}
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			continue
+		}
+		b.WriteRune(r)
+		count++
+	}
+	return b.String()
+}
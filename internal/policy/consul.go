@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"context"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultConsulKey adalah key Consul KV tempat dokumen kebijakan disimpan.
+const DefaultConsulKey = "config/prism-file-service/authorization_policies"
+
+// KV adalah bagian dari *consulapi.KV yang dipakai watcher.
+type KV interface {
+	Get(key string, q *consulapi.QueryOptions) (*consulapi.KVPair, *consulapi.QueryMeta, error)
+}
+
+// ConsulWatcher memuat kebijakan dari Consul KV dan memuat ulang engine setiap
+// kali key berubah, memakai blocking query agar perubahan diterapkan segera.
+type ConsulWatcher struct {
+	kv       KV
+	key      string
+	engine   *Engine
+	waitTime time.Duration
+	retry    time.Duration
+}
+
+func NewConsulWatcher(kv KV, key string, engine *Engine) *ConsulWatcher {
+	return &ConsulWatcher{
+		kv:       kv,
+		key:      key,
+		engine:   engine,
+		waitTime: 5 * time.Minute,
+		retry:    5 * time.Second,
+	}
+}
+
+// Load membaca kebijakan sekali. Jika key tidak ada, DefaultPolicies dipakai. Index Consul dikembalikan untuk blocking query berikutnya.
+func (w *ConsulWatcher) Load(ctx context.Context) (uint64, error) {
+	return w.fetch(ctx, 0)
+}
+
+// Watch memuat ulang kebijakan sampai ctx dibatalkan. Dokumen yang tidak valid
+// dicatat dan diabaikan sehingga kebijakan terakhir yang valid tetap berlaku.
+func (w *ConsulWatcher) Watch(ctx context.Context, lastIndex uint64) {
+	for ctx.Err() == nil {
+		index, err := w.fetch(ctx, lastIndex)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn().Err(err).Str("key", w.key).Msg("Gagal memuat ulang kebijakan otorisasi dari Consul")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.retry):
+			}
+		}
+		// Index yang mundur berarti Consul di-reset; mulai ulang dari awal.
+		if index < lastIndex {
+			index = 0
+		}
+		lastIndex = index
+	}
+}
+
+func (w *ConsulWatcher) fetch(ctx context.Context, waitIndex uint64) (uint64, error) {
+	opts := (&consulapi.QueryOptions{WaitIndex: waitIndex, WaitTime: w.waitTime}).WithContext(ctx)
+	pair, meta, err := w.kv.Get(w.key, opts)
+	if err != nil {
+		return waitIndex, err
+	}
+	index := meta.LastIndex
+	if waitIndex != 0 && index == waitIndex {
+		// Blocking query habis waktu tanpa perubahan.
+		return index, nil
+	}
+	if pair == nil {
+		log.Info().Str("key", w.key).Msg("Kebijakan otorisasi tidak ditemukan di Consul, memakai kebijakan bawaan")
+		return index, w.engine.Reload(DefaultPolicies())
+	}
+	policies, err := ParseDocument(pair.Value)
+	if err == nil {
+		err = w.engine.Reload(policies)
+	}
+	if err != nil {
+		return index, err
+	}
+	log.Info().Str("key", w.key).Uint64("index", pair.ModifyIndex).Int("policies", len(policies)).Msg("Kebijakan otorisasi dimuat")
+	return index, nil
+}
@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Suite adalah kumpulan kasus uji bagi penulis kebijakan. Jika Policies kosong,
+// kasus diuji terhadap DefaultPolicies.
+type Suite struct {
+	Policies []Policy `json:"policies,omitempty"`
+	Cases    []Case   `json:"cases"`
+}
+
+// Case menjelaskan satu permintaan akses dan keputusan yang diharapkan.
+// TagAccess menggantikan hasil pemeriksaan aturan tag di database.
+type Case struct {
+	Name         string         `json:"name"`
+	Subject      map[string]any `json:"subject"`
+	File         File           `json:"file"`
+	Request      Request        `json:"request"`
+	TagAccess    bool           `json:"tag_access"`
+	Expect       Effect         `json:"expect"`
+	ExpectPolicy string         `json:"expect_policy,omitempty"`
+}
+
+// CaseResult adalah hasil menjalankan satu Case.
+type CaseResult struct {
+	Case     Case
+	Decision Decision
+	Passed   bool
+	Message  string
+}
+
+// LoadSuite membaca suite berformat JSON dari file.
+func LoadSuite(path string) (Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Suite{}, err
+	}
+	var suite Suite
+	if err := json.Unmarshal(data, &suite); err != nil {
+		return Suite{}, fmt.Errorf("gagal membaca suite %s: %w", path, err)
+	}
+	return suite, nil
+}
+
+// RunSuite mengompilasi kebijakan suite lalu menjalankan semua kasusnya.
+// Error hanya dikembalikan jika kebijakan tidak valid; kasus yang gagal
+// dilaporkan lewat CaseResult.Passed.
+func RunSuite(ctx context.Context, suite Suite) ([]CaseResult, error) {
+	policies := suite.Policies
+	if len(policies) == 0 {
+		policies = DefaultPolicies()
+	}
+	engine, err := NewEngine(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CaseResult, 0, len(suite.Cases))
+	for _, tc := range suite.Cases {
+		results = append(results, runCase(ctx, engine, tc))
+	}
+	return results, nil
+}
+
+func runCase(ctx context.Context, engine *Engine, tc Case) CaseResult {
+	tagAccess := tc.TagAccess
+	decision := engine.Evaluate(ctx, Input{
+		Subject: tc.Subject,
+		File:    tc.File,
+		Request: tc.Request,
+		TagAccess: func(context.Context) (bool, error) {
+			return tagAccess, nil
+		},
+	})
+
+	result := CaseResult{Case: tc, Decision: decision, Passed: true}
+	got := EffectDeny
+	if decision.Allowed {
+		got = EffectAllow
+	}
+	switch {
+	case tc.Expect != EffectAllow && tc.Expect != EffectDeny:
+		result.Passed = false
+		result.Message = fmt.Sprintf("expect harus allow atau deny, bukan %q", tc.Expect)
+	case got != tc.Expect:
+		result.Passed = false
+		result.Message = fmt.Sprintf("diharapkan %s, didapat %s oleh kebijakan %s", tc.Expect, got, decision.Policy)
+	case tc.ExpectPolicy != "" && tc.ExpectPolicy != decision.Policy:
+		result.Passed = false
+		result.Message = fmt.Sprintf("diharapkan diputuskan oleh %s, didapat %s", tc.ExpectPolicy, decision.Policy)
+	}
+	return result
+}
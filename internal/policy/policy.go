@@ -0,0 +1,384 @@
+// Package policy mengevaluasi otorisasi akses file dengan kebijakan deklaratif
+// berbasis CEL (Common Expression Language). Setiap kebijakan adalah ekspresi
+// boolean atas klaim JWT (subject), metadata file (file), konteks permintaan
+// (request) dan hasil aturan tag (tag_access).
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+)
+
+// Effect menentukan apa yang terjadi jika ekspresi kebijakan bernilai true.
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// ActionRead adalah aksi untuk mengunduh file, membaca metadata atau
+// memasukkan file ke arsip.
+const ActionRead = "read"
+
+// DefaultDenyPolicy adalah nama yang dilaporkan jika tidak ada kebijakan yang cocok.
+const DefaultDenyPolicy = "default-deny"
+
+var ErrInvalidPolicy = errors.New("kebijakan tidak valid")
+
+// Policy adalah satu aturan otorisasi. Kebijakan deny selalu dievaluasi lebih
+// dulu dan menang atas allow; di antara kebijakan allow, yang pertama cocok
+// (sesuai urutan) menjadi aturan yang dilaporkan.
+type Policy struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Effect      Effect `json:"effect"`
+	Expression  string `json:"expression"`
+}
+
+// Document adalah format kebijakan yang disimpan di Consul KV.
+type Document struct {
+	Policies []Policy `json:"policies"`
+}
+
+// ParseDocument membaca dokumen kebijakan berformat JSON.
+func ParseDocument(data []byte) ([]Policy, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	return doc.Policies, nil
+}
+
+// DefaultPolicies mereproduksi aturan akses bawaan: pemilik file, peran admin,
+// atau peran yang diizinkan oleh aturan tag.
+func DefaultPolicies() []Policy {
+	return []Policy{
+		{
+			Name:        "owner",
+			Description: "Pemilik file selalu boleh mengakses file miliknya",
+			Effect:      EffectAllow,
+			Expression:  `file.owner_user_id != "" && file.owner_user_id == subject.sub`,
+		},
+		{
+			Name:        "admin",
+			Description: "Peran admin boleh mengakses semua file",
+			Effect:      EffectAllow,
+			Expression:  `subject.role == "admin"`,
+		},
+		{
+			Name:        "tag-rule",
+			Description: "Peran yang diizinkan oleh aturan tag boleh mengakses file bertag",
+			Effect:      EffectAllow,
+			Expression:  `size(file.tags) > 0 && tag_access`,
+		},
+	}
+}
+
+// File adalah metadata file yang tersedia bagi ekspresi sebagai variabel `file`.
+type File struct {
+	ID           string    `json:"id"`
+	OwnerUserID  string    `json:"owner_user_id"`
+	OriginalName string    `json:"original_name"`
+	MimeType     string    `json:"mime_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	Tags         []string  `json:"tags"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Request adalah konteks permintaan yang tersedia sebagai variabel `request`.
+type Request struct {
+	Action string    `json:"action"`
+	IP     string    `json:"ip"`
+	Time   time.Time `json:"time"`
+}
+
+type requestContextKey struct{}
+
+// WithRequest menyimpan konteks permintaan HTTP (IP, waktu) di ctx agar dapat
+// dibaca saat otorisasi tanpa mengubah signature layer service.
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, req)
+}
+
+// RequestFromContext mengembalikan konteks permintaan yang disimpan WithRequest.
+func RequestFromContext(ctx context.Context) Request {
+	req, _ := ctx.Value(requestContextKey{}).(Request)
+	return req
+}
+
+// Input adalah semua data yang dievaluasi oleh kebijakan. TagAccess hanya
+// dipanggil jika ada kebijakan yang benar-benar membaca `tag_access`.
+type Input struct {
+	Subject   map[string]any
+	File      File
+	Request   Request
+	TagAccess func(ctx context.Context) (bool, error)
+}
+
+// Decision adalah hasil evaluasi beserta kebijakan yang menentukannya.
+type Decision struct {
+	Allowed bool
+	Policy  string
+	Effect  Effect
+	// Errors berisi kebijakan yang gagal dievaluasi; kebijakan allow yang gagal
+	// dianggap tidak cocok, kebijakan deny yang gagal dianggap cocok (fail-closed).
+	Errors []error
+}
+
+type compiledPolicy struct {
+	Policy
+	program cel.Program
+}
+
+type ruleset struct {
+	deny  []compiledPolicy
+	allow []compiledPolicy
+}
+
+// Engine menyimpan kebijakan yang sudah dikompilasi. Aman dipakai bersamaan
+// dan dapat dimuat ulang tanpa menghentikan evaluasi yang sedang berjalan.
+type Engine struct {
+	env     *cel.Env
+	current atomic.Pointer[ruleset]
+}
+
+// NewEngine mengompilasi kebijakan. Kebijakan yang tidak valid membuat seluruh
+// set ditolak.
+func NewEngine(policies []Policy) (*Engine, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	e := &Engine{env: env}
+	if err := e.Reload(policies); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// NewDefaultEngine membuat engine dengan DefaultPolicies.
+func NewDefaultEngine() *Engine {
+	e, err := NewEngine(DefaultPolicies())
+	if err != nil {
+		panic(fmt.Sprintf("kebijakan bawaan tidak valid: %v", err))
+	}
+	return e
+}
+
+// Reload mengganti set kebijakan secara atomik. Jika ada kebijakan yang gagal
+// dikompilasi, set lama tetap dipakai dan error dikembalikan.
+func (e *Engine) Reload(policies []Policy) error {
+	rs, err := e.compile(policies)
+	if err != nil {
+		return err
+	}
+	e.current.Store(rs)
+	return nil
+}
+
+// Policies mengembalikan salinan kebijakan yang sedang aktif.
+func (e *Engine) Policies() []Policy {
+	rs := e.current.Load()
+	policies := make([]Policy, 0, len(rs.deny)+len(rs.allow))
+	for _, p := range rs.deny {
+		policies = append(policies, p.Policy)
+	}
+	for _, p := range rs.allow {
+		policies = append(policies, p.Policy)
+	}
+	return policies
+}
+
+// Validate memeriksa kebijakan tanpa memuatnya ke engine.
+func Validate(policies []Policy) error {
+	env, err := newEnv()
+	if err != nil {
+		return err
+	}
+	_, err = (&Engine{env: env}).compile(policies)
+	return err
+}
+
+func (e *Engine) compile(policies []Policy) (*ruleset, error) {
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("%w: set kebijakan kosong", ErrInvalidPolicy)
+	}
+	rs := &ruleset{}
+	names := make(map[string]bool, len(policies))
+	for _, p := range policies {
+		if p.Name == "" {
+			return nil, fmt.Errorf("%w: nama kebijakan wajib diisi", ErrInvalidPolicy)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("%w: nama kebijakan %q duplikat", ErrInvalidPolicy, p.Name)
+		}
+		names[p.Name] = true
+
+		ast, issues := e.env.Compile(p.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, p.Name, issues.Err())
+		}
+		if out := ast.OutputType(); !out.IsExactType(cel.BoolType) && !out.IsExactType(cel.DynType) {
+			return nil, fmt.Errorf("%w: %s: ekspresi harus bernilai bool, bukan %s", ErrInvalidPolicy, p.Name, ast.OutputType())
+		}
+		program, err := e.env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, p.Name, err)
+		}
+
+		compiled := compiledPolicy{Policy: p, program: program}
+		switch p.Effect {
+		case EffectAllow:
+			rs.allow = append(rs.allow, compiled)
+		case EffectDeny:
+			rs.deny = append(rs.deny, compiled)
+		default:
+			return nil, fmt.Errorf("%w: %s: effect %q harus allow atau deny", ErrInvalidPolicy, p.Name, p.Effect)
+		}
+	}
+	return rs, nil
+}
+
+// Evaluate menjalankan kebijakan aktif terhadap input.
+func (e *Engine) Evaluate(ctx context.Context, in Input) Decision {
+	rs := e.current.Load()
+	activation := in.activation(ctx)
+
+	var evalErrs []error
+	for _, p := range rs.deny {
+		matched, err := p.eval(ctx, activation)
+		if err != nil {
+			return Decision{Allowed: false, Policy: p.Name, Effect: EffectDeny, Errors: append(evalErrs, err)}
+		}
+		if matched {
+			return Decision{Allowed: false, Policy: p.Name, Effect: EffectDeny, Errors: evalErrs}
+		}
+	}
+	for _, p := range rs.allow {
+		matched, err := p.eval(ctx, activation)
+		if err != nil {
+			evalErrs = append(evalErrs, err)
+			continue
+		}
+		if matched {
+			return Decision{Allowed: true, Policy: p.Name, Effect: EffectAllow, Errors: evalErrs}
+		}
+	}
+	return Decision{Allowed: false, Policy: DefaultDenyPolicy, Effect: EffectDeny, Errors: evalErrs}
+}
+
+func (p compiledPolicy) eval(ctx context.Context, activation map[string]any) (bool, error) {
+	out, _, err := p.program.ContextEval(ctx, activation)
+	if err != nil {
+		return false, fmt.Errorf("kebijakan %s: %w", p.Name, err)
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("kebijakan %s: hasil %v bukan bool", p.Name, out.Value())
+	}
+	return matched, nil
+}
+
+func (in Input) activation(ctx context.Context) map[string]any {
+	subject := make(map[string]any, len(in.Subject)+2)
+	// sub dan role selalu ada agar kebijakan tidak gagal karena klaim yang hilang.
+	subject["sub"] = ""
+	subject["role"] = ""
+	for k, v := range in.Subject {
+		subject[k] = v
+	}
+
+	requestTime := in.Request.Time
+	if requestTime.IsZero() {
+		requestTime = time.Now()
+	}
+	tags := in.File.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var once sync.Once
+	var tagAccess ref.Val
+	return map[string]any{
+		"subject": subject,
+		"file": map[string]any{
+			"id":            in.File.ID,
+			"owner_user_id": in.File.OwnerUserID,
+			"original_name": in.File.OriginalName,
+			"mime_type":     in.File.MimeType,
+			"size_bytes":    in.File.SizeBytes,
+			"tags":          tags,
+			"created_at":    in.File.CreatedAt,
+		},
+		"request": map[string]any{
+			"action": in.Request.Action,
+			"ip":     in.Request.IP,
+			"time":   requestTime,
+		},
+		"tag_access": func() ref.Val {
+			once.Do(func() {
+				if in.TagAccess == nil {
+					tagAccess = types.False
+					return
+				}
+				ok, err := in.TagAccess(ctx)
+				if err != nil {
+					tagAccess = types.NewErr("gagal memeriksa aturan tag: %v", err)
+					return
+				}
+				tagAccess = types.Bool(ok)
+			})
+			return tagAccess
+		},
+	}
+}
+
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("subject", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("file", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("tag_access", cel.BoolType),
+		ext.Strings(),
+		cel.Function("ip_in_cidr",
+			cel.Overload("ip_in_cidr_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(ipInCIDR),
+			),
+		),
+	)
+}
+
+// ipInCIDR mengimplementasikan fungsi CEL ip_in_cidr(ip, cidr). Alamat yang
+// tidak valid menghasilkan false, bukan error, agar klien tanpa IP tidak
+// menggagalkan evaluasi kebijakan lain.
+func ipInCIDR(ipVal, cidrVal ref.Val) ref.Val {
+	ip, ok := ipVal.Value().(string)
+	if !ok {
+		return types.NoSuchOverloadErr()
+	}
+	cidr, ok := cidrVal.Value().(string)
+	if !ok {
+		return types.NoSuchOverloadErr()
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return types.NewErr("CIDR tidak valid %q: %v", cidr, err)
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return types.False
+	}
+	return types.Bool(prefix.Contains(addr.Unmap()))
+}
@@ -0,0 +1,192 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuites(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			suite, err := LoadSuite(path)
+			require.NoError(t, err)
+			results, err := RunSuite(context.Background(), suite)
+			require.NoError(t, err)
+			for _, result := range results {
+				assert.True(t, result.Passed, "%s: %s", result.Case.Name, result.Message)
+			}
+		})
+	}
+}
+
+func TestEngine_Compile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policies []Policy
+	}{
+		{name: "Empty set", policies: nil},
+		{name: "Missing name", policies: []Policy{{Effect: EffectAllow, Expression: "true"}}},
+		{name: "Duplicate name", policies: []Policy{{Name: "a", Effect: EffectAllow, Expression: "true"}, {Name: "a", Effect: EffectDeny, Expression: "false"}}},
+		{name: "Syntax error", policies: []Policy{{Name: "a", Effect: EffectAllow, Expression: "subject.role =="}}},
+		{name: "Unknown variable", policies: []Policy{{Name: "a", Effect: EffectAllow, Expression: "user.role == 'admin'"}}},
+		{name: "Non-bool expression", policies: []Policy{{Name: "a", Effect: EffectAllow, Expression: "size(file.tags)"}}},
+		{name: "Unknown effect", policies: []Policy{{Name: "a", Effect: "maybe", Expression: "true"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewEngine(tc.policies)
+			assert.ErrorIs(t, err, ErrInvalidPolicy)
+		})
+	}
+}
+
+func TestEngine_ReloadKeepsPreviousPoliciesOnError(t *testing.T) {
+	engine := NewDefaultEngine()
+	err := engine.Reload([]Policy{{Name: "broken", Effect: EffectAllow, Expression: "("}})
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+	assert.Equal(t, DefaultPolicies(), engine.Policies())
+}
+
+func TestEngine_TagAccessIsLazy(t *testing.T) {
+	engine := NewDefaultEngine()
+	calls := 0
+	in := Input{
+		Subject: map[string]any{"sub": "user-1", "role": "user"},
+		File:    File{ID: "file-1", OwnerUserID: "user-1", Tags: []string{"keuangan"}},
+		TagAccess: func(context.Context) (bool, error) {
+			calls++
+			return true, nil
+		},
+	}
+
+	decision := engine.Evaluate(context.Background(), in)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "owner", decision.Policy)
+	assert.Zero(t, calls, "aturan tag tidak boleh diperiksa jika kebijakan owner sudah cocok")
+
+	in.Subject = map[string]any{"sub": "user-2", "role": "finance"}
+	decision = engine.Evaluate(context.Background(), in)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "tag-rule", decision.Policy)
+	assert.Equal(t, 1, calls)
+}
+
+func TestEngine_EvaluationErrors(t *testing.T) {
+	t.Run("Failed allow policy does not match", func(t *testing.T) {
+		engine := NewDefaultEngine()
+		decision := engine.Evaluate(context.Background(), Input{
+			Subject: map[string]any{"sub": "user-2", "role": "finance"},
+			File:    File{ID: "file-1", Tags: []string{"keuangan"}},
+			TagAccess: func(context.Context) (bool, error) {
+				return false, errors.New("db down")
+			},
+		})
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, DefaultDenyPolicy, decision.Policy)
+		assert.Len(t, decision.Errors, 1)
+	})
+
+	t.Run("Failed deny policy denies", func(t *testing.T) {
+		engine, err := NewEngine([]Policy{
+			{Name: "block-clearance", Effect: EffectDeny, Expression: "subject.clearance < 3"},
+			{Name: "everyone", Effect: EffectAllow, Expression: "true"},
+		})
+		require.NoError(t, err)
+		decision := engine.Evaluate(context.Background(), Input{Subject: map[string]any{"sub": "user-1"}})
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "block-clearance", decision.Policy)
+		assert.Len(t, decision.Errors, 1)
+	})
+}
+
+func TestIPInCIDR(t *testing.T) {
+	engine, err := NewEngine([]Policy{{Name: "office", Effect: EffectAllow, Expression: `ip_in_cidr(request.ip, "10.0.0.0/8")`}})
+	require.NoError(t, err)
+
+	for ip, want := range map[string]bool{
+		"10.1.2.3":        true,
+		"::ffff:10.1.2.3": true,
+		"192.168.1.1":     false,
+		"":                false,
+		"not-an-ip":       false,
+	} {
+		decision := engine.Evaluate(context.Background(), Input{Request: Request{IP: ip}})
+		assert.Equal(t, want, decision.Allowed, ip)
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	ctx := WithRequest(context.Background(), Request{IP: "10.0.0.1"})
+	assert.Equal(t, "10.0.0.1", RequestFromContext(ctx).IP)
+	assert.Equal(t, Request{}, RequestFromContext(context.Background()))
+}
+
+type fakeKV struct {
+	mu      sync.Mutex
+	pair    *consulapi.KVPair
+	index   uint64
+	queries []uint64
+}
+
+func (f *fakeKV) Get(key string, q *consulapi.QueryOptions) (*consulapi.KVPair, *consulapi.QueryMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, q.WaitIndex)
+	return f.pair, &consulapi.QueryMeta{LastIndex: f.index}, nil
+}
+
+func (f *fakeKV) set(value string, index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pair = &consulapi.KVPair{Key: DefaultConsulKey, Value: []byte(value), ModifyIndex: index}
+	f.index = index
+}
+
+func TestConsulWatcher(t *testing.T) {
+	ctx := context.Background()
+	kv := &fakeKV{index: 1}
+	engine := NewDefaultEngine()
+	watcher := NewConsulWatcher(kv, DefaultConsulKey, engine)
+
+	t.Run("Missing key keeps default policies", func(t *testing.T) {
+		index, err := watcher.Load(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), index)
+		assert.Equal(t, DefaultPolicies(), engine.Policies())
+	})
+
+	t.Run("Changed key reloads policies", func(t *testing.T) {
+		kv.set(`{"policies":[{"name":"nobody","effect":"deny","expression":"true"}]}`, 5)
+		index, err := watcher.fetch(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), index)
+		require.Len(t, engine.Policies(), 1)
+		assert.Equal(t, "nobody", engine.Policies()[0].Name)
+	})
+
+	t.Run("Invalid document keeps last valid policies", func(t *testing.T) {
+		kv.set(`{"policies":[{"name":"broken","effect":"allow","expression":"("}]}`, 6)
+		index, err := watcher.fetch(ctx, 5)
+		require.ErrorIs(t, err, ErrInvalidPolicy)
+		assert.Equal(t, uint64(6), index, "index tetap maju agar dokumen yang sama tidak dimuat berulang")
+		assert.Equal(t, "nobody", engine.Policies()[0].Name)
+	})
+
+	t.Run("Watch stops when context is cancelled", func(t *testing.T) {
+		watchCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		watcher.Watch(watchCtx, 6)
+	})
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrRuleExists dikembalikan saat membuat aturan yang sudah ada.
+var ErrRuleExists = errors.New("aturan akses sudah ada")
+
+// accessRulesCacheKey adalah key Redis untuk daftar lengkap aturan akses.
+const accessRulesCacheKey = "prism-file-service:access-rules"
+
+// AccessRuleRepository mengelola tabel file_access_rules.
+type AccessRuleRepository interface {
+	ListRules(ctx context.Context) ([]model.AccessRule, error)
+	CreateRule(ctx context.Context, rule model.AccessRule) error
+	DeleteRule(ctx context.Context, rule model.AccessRule) error
+}
+
+type postgresAccessRuleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresAccessRuleRepository(db *pgxpool.Pool) AccessRuleRepository {
+	return &postgresAccessRuleRepository{db: db}
+}
+
+func (r *postgresAccessRuleRepository) ListRules(ctx context.Context) ([]model.AccessRule, error) {
+	rows, err := r.db.Query(ctx, `SELECT tag_name, role_name FROM file_access_rules ORDER BY tag_name, role_name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.AccessRule{}
+	for rows.Next() {
+		var rule model.AccessRule
+		if err := rows.Scan(&rule.TagName, &rule.RoleName); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *postgresAccessRuleRepository) CreateRule(ctx context.Context, rule model.AccessRule) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO file_access_rules (tag_name, role_name) VALUES ($1, $2);`, rule.TagName, rule.RoleName)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrRuleExists
+	}
+	return err
+}
+
+func (r *postgresAccessRuleRepository) DeleteRule(ctx context.Context, rule model.AccessRule) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM file_access_rules WHERE tag_name = $1 AND role_name = $2;`, rule.TagName, rule.RoleName)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// cachedAccessRuleRepository menyimpan daftar aturan di Redis dan
+// menghapusnya setiap kali aturan berubah. Jika Redis tidak tersedia,
+// permintaan diteruskan langsung ke repository di bawahnya.
+type cachedAccessRuleRepository struct {
+	next  AccessRuleRepository
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+func NewCachedAccessRuleRepository(next AccessRuleRepository, redisClient *redis.Client, ttl time.Duration) AccessRuleRepository {
+	return &cachedAccessRuleRepository{next: next, redis: redisClient, ttl: ttl}
+}
+
+func (r *cachedAccessRuleRepository) ListRules(ctx context.Context) ([]model.AccessRule, error) {
+	cached, err := r.redis.Get(ctx, accessRulesCacheKey).Bytes()
+	if err == nil {
+		var rules []model.AccessRule
+		if err := json.Unmarshal(cached, &rules); err == nil {
+			return rules, nil
+		}
+		log.Warn().Msg("Cache aturan akses rusak, memuat ulang dari database")
+	} else if !errors.Is(err, redis.Nil) {
+		log.Warn().Err(err).Msg("Gagal membaca cache aturan akses dari Redis")
+	}
+
+	rules, err := r.next.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if payload, err := json.Marshal(rules); err == nil {
+		if err := r.redis.Set(ctx, accessRulesCacheKey, payload, r.ttl).Err(); err != nil {
+			log.Warn().Err(err).Msg("Gagal menyimpan cache aturan akses ke Redis")
+		}
+	}
+	return rules, nil
+}
+
+func (r *cachedAccessRuleRepository) CreateRule(ctx context.Context, rule model.AccessRule) error {
+	if err := r.next.CreateRule(ctx, rule); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *cachedAccessRuleRepository) DeleteRule(ctx context.Context, rule model.AccessRule) error {
+	if err := r.next.DeleteRule(ctx, rule); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *cachedAccessRuleRepository) invalidate(ctx context.Context) {
+	if err := r.redis.Del(ctx, accessRulesCacheKey).Err(); err != nil {
+		log.Error().Err(err).Msg("Gagal menghapus cache aturan akses; perubahan baru terlihat setelah TTL habis")
+	}
+}
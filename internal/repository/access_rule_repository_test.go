@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccessRuleRepository menghitung berapa kali ListRules mencapai "database".
+type fakeAccessRuleRepository struct {
+	rules     []model.AccessRule
+	listCalls int
+}
+
+func (f *fakeAccessRuleRepository) ListRules(ctx context.Context) ([]model.AccessRule, error) {
+	f.listCalls++
+	return append([]model.AccessRule(nil), f.rules...), nil
+}
+
+func (f *fakeAccessRuleRepository) CreateRule(ctx context.Context, rule model.AccessRule) error {
+	f.rules = append(f.rules, rule)
+	return nil
+}
+
+func (f *fakeAccessRuleRepository) DeleteRule(ctx context.Context, rule model.AccessRule) error {
+	for i, r := range f.rules {
+		if r == rule {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func TestCachedAccessRuleRepository(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	inner := &fakeAccessRuleRepository{rules: []model.AccessRule{{TagName: "keuangan/*", RoleName: "finance"}}}
+	repo := NewCachedAccessRuleRepository(inner, redisClient, time.Minute)
+
+	rules, err := repo.ListRules(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+
+	_, err = repo.ListRules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.listCalls, "Panggilan kedua harus dilayani dari Redis")
+
+	require.NoError(t, repo.CreateRule(ctx, model.AccessRule{TagName: "hr", RoleName: "hr"}))
+	assert.False(t, mr.Exists(accessRulesCacheKey), "Cache harus dihapus setelah aturan berubah")
+
+	rules, err = repo.ListRules(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.Equal(t, 2, inner.listCalls)
+
+	require.NoError(t, repo.DeleteRule(ctx, model.AccessRule{TagName: "hr", RoleName: "hr"}))
+	assert.False(t, mr.Exists(accessRulesCacheKey))
+}
+
+func TestCachedAccessRuleRepository_RedisUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+	mr.Close()
+
+	inner := &fakeAccessRuleRepository{rules: []model.AccessRule{{TagName: "hr", RoleName: "hr"}}}
+	repo := NewCachedAccessRuleRepository(inner, redisClient, time.Minute)
+
+	rules, err := repo.ListRules(context.Background())
+	require.NoError(t, err, "Redis yang mati tidak boleh menggagalkan pembacaan aturan")
+	assert.Len(t, rules, 1)
+}
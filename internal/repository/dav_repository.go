@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// DAVCollectionRepository menyimpan koleksi WebDAV kosong milik pengguna.
+// Path koleksi memakai format tag hierarkis ("keuangan/pajak").
+type DAVCollectionRepository interface {
+	// ListCollections mengembalikan path yang sama dengan prefix atau berada di
+	// bawahnya; prefix kosong berarti semua koleksi pengguna.
+	ListCollections(ctx context.Context, userID, prefix string) ([]string, error)
+	CreateCollection(ctx context.Context, userID, path string) error
+	// DeleteCollections menghapus koleksi prefix beserta turunannya.
+	DeleteCollections(ctx context.Context, userID, prefix string) error
+	// RenameCollections memindahkan koleksi oldPrefix beserta turunannya ke newPrefix.
+	RenameCollections(ctx context.Context, userID, oldPrefix, newPrefix string) error
+}
+
+type postgresDAVCollectionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresDAVCollectionRepository(db *pgxpool.Pool) DAVCollectionRepository {
+	return &postgresDAVCollectionRepository{db: db}
+}
+
+func (r *postgresDAVCollectionRepository) ListCollections(ctx context.Context, userID, prefix string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT path FROM dav_collections
+            WHERE user_id = $1 AND ($2 = '' OR path = $2 OR starts_with(path, $2 || '/'))
+            ORDER BY path;`, userID, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+func (r *postgresDAVCollectionRepository) CreateCollection(ctx context.Context, userID, path string) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO dav_collections (user_id, path) VALUES ($1, $2) ON CONFLICT DO NOTHING;`, userID, path)
+	return err
+}
+
+func (r *postgresDAVCollectionRepository) DeleteCollections(ctx context.Context, userID, prefix string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM dav_collections WHERE user_id = $1 AND (path = $2 OR starts_with(path, $2 || '/'));`, userID, prefix)
+	return err
+}
+
+func (r *postgresDAVCollectionRepository) RenameCollections(ctx context.Context, userID, oldPrefix, newPrefix string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Gagal melakukan rollback pada transaksi RenameCollections")
+		}
+	}()
+
+	// Hapus dulu path tujuan yang bentrok agar UPDATE tidak melanggar primary key.
+	_, err = tx.Exec(ctx, `DELETE FROM dav_collections d WHERE user_id = $1 AND EXISTS (
+            SELECT 1 FROM dav_collections s
+            WHERE s.user_id = $1 AND (s.path = $2 OR starts_with(s.path, $2 || '/'))
+              AND d.path = $3 || substr(s.path, length($2) + 1));`, userID, oldPrefix, newPrefix)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE dav_collections SET path = $3 || substr(path, length($2) + 1)
+            WHERE user_id = $1 AND (path = $2 OR starts_with(path, $2 || '/'));`, userID, oldPrefix, newPrefix)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -2,9 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -22,6 +27,53 @@ type FileRepository interface {
 	GetByID(ctx context.Context, id string) (*model.FileMetadata, error)
 	DeleteByID(ctx context.Context, id string) error
 	CheckRoleAccess(ctx context.Context, fileID string, roleName string) (bool, error)
+	List(ctx context.Context, filter FileFilter) ([]*model.FileMetadata, error)
+	UpdateMetadata(ctx context.Context, id string, expectedVersion int64, update MetadataUpdate, actorID string) error
+	AddTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error
+	RemoveTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error
+}
+
+var (
+	// ErrNotFound dikembalikan jika file tidak ada atau sudah dihapus.
+	ErrNotFound = pgx.ErrNoRows
+	// ErrVersionConflict dikembalikan jika versi yang diharapkan pemanggil
+	// (If-Match) tidak sama dengan versi file saat ini.
+	ErrVersionConflict = errors.New("versi file tidak cocok")
+)
+
+// MetadataUpdate berisi perubahan metadata parsial. Field nil tidak diubah;
+// Tags yang tidak nil menggantikan seluruh tag file.
+type MetadataUpdate struct {
+	OriginalName *string   `json:"original_name"`
+	Tags         *[]string `json:"tags"`
+}
+
+// FileFilter membatasi hasil List. Field kosong berarti tidak difilter.
+type FileFilter struct {
+	// IDs membatasi hasil pada file dengan ID tertentu.
+	IDs []string
+	// Tags mensyaratkan file memiliki SEMUA tag yang disebutkan.
+	Tags []string
+	// TagPrefix mensyaratkan file memiliki tag TagPrefix atau tag turunannya
+	// dalam hierarki (TagPrefix + "/...").
+	TagPrefix string
+	// Limit membatasi jumlah baris yang dikembalikan (0 = tanpa batas).
+	Limit int
+	// After melanjutkan daftar tepat setelah file ini, untuk membaca hasil
+	// per halaman tanpa OFFSET.
+	After *ListCursor
+}
+
+// ListCursor menandai posisi sebuah file dalam urutan List: created_at
+// terbaru lebih dulu, lalu ID menurun untuk waktu yang sama.
+type ListCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorOf mengembalikan posisi metadata dalam urutan List.
+func CursorOf(metadata *model.FileMetadata) *ListCursor {
+	return &ListCursor{CreatedAt: metadata.CreatedAt, ID: metadata.ID}
 }
 
 type postgresFileRepository struct {
@@ -43,9 +95,22 @@ func (r *postgresFileRepository) Create(ctx context.Context, metadata *model.Fil
 		}
 	}()
 
-	sqlInsertFile := `INSERT INTO files (id, original_name, storage_path, mime_type, size_bytes, owner_user_id)
-                      VALUES ($1, $2, $3, $4, $5, $6);`
-	_, err = tx.Exec(ctx, sqlInsertFile, metadata.ID, metadata.OriginalName, metadata.StoragePath, metadata.MimeType, metadata.SizeBytes, metadata.OwnerUserID)
+	pdfInfo, err := nullableJSON(metadata.PDF)
+	if err != nil {
+		return err
+	}
+	provenance, err := nullableJSON(metadata.Provenance)
+	if err != nil {
+		return err
+	}
+
+	sqlInsertFile := `INSERT INTO files (id, original_name, storage_path, mime_type, size_bytes, owner_user_id,
+                          checksum_sha256, checksum_md5, checksum_crc32c, retain_until, sanitized_at,
+                          content_text, content_indexed_at, pdf_info, provenance)
+                      VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10, $11, NULLIF($12, ''), $13, $14, $15);`
+	_, err = tx.Exec(ctx, sqlInsertFile, metadata.ID, metadata.OriginalName, metadata.StoragePath, metadata.MimeType, metadata.SizeBytes, metadata.OwnerUserID,
+		metadata.ChecksumSHA256, metadata.ChecksumMD5, metadata.ChecksumCRC32C, metadata.RetainUntil, metadata.SanitizedAt,
+		metadata.ContentText, metadata.IndexedAt, pdfInfo, provenance)
 	if err != nil {
 		return err
 	}
@@ -67,7 +132,10 @@ func (r *postgresFileRepository) Create(ctx context.Context, metadata *model.Fil
 
 func (r *postgresFileRepository) GetByID(ctx context.Context, id string) (*model.FileMetadata, error) {
 	var metadata model.FileMetadata
-	sql := `SELECT f.id, f.original_name, f.storage_path, f.mime_type, f.size_bytes, f.owner_user_id, f.created_at,
+	sql := `SELECT f.id, f.original_name, f.storage_path, f.mime_type, f.size_bytes, f.owner_user_id, f.created_at, f.version,
+             COALESCE(f.checksum_sha256, ''), COALESCE(f.checksum_md5, ''), COALESCE(f.checksum_crc32c, ''),
+             f.checksum_verified_at, f.checksum_mismatch_at, f.retain_until, f.sanitized_at, f.content_indexed_at, f.rendition_paths,
+             f.pdf_info, f.provenance,
              COALESCE(array_agg(ft.tag_name) FILTER (WHERE ft.tag_name IS NOT NULL), '{}') as tags
             FROM files f
             LEFT JOIN file_tags ft ON f.id = ft.file_id
@@ -76,7 +144,10 @@ func (r *postgresFileRepository) GetByID(ctx context.Context, id string) (*model
 
 	err := r.db.QueryRow(ctx, sql, id).Scan(
 		&metadata.ID, &metadata.OriginalName, &metadata.StoragePath, &metadata.MimeType,
-		&metadata.SizeBytes, &metadata.OwnerUserID, &metadata.CreatedAt, &metadata.Tags,
+		&metadata.SizeBytes, &metadata.OwnerUserID, &metadata.CreatedAt, &metadata.Version,
+		&metadata.ChecksumSHA256, &metadata.ChecksumMD5, &metadata.ChecksumCRC32C,
+		&metadata.ChecksumVerifiedAt, &metadata.ChecksumMismatchAt, &metadata.RetainUntil, &metadata.SanitizedAt, &metadata.IndexedAt,
+		&metadata.RenditionPaths, &metadata.PDF, &metadata.Provenance, &metadata.Tags,
 	)
 	if err != nil {
 		return nil, err
@@ -92,10 +163,16 @@ func (r *postgresFileRepository) DeleteByID(ctx context.Context, id string) erro
 
 func (r *postgresFileRepository) CheckRoleAccess(ctx context.Context, fileID string, roleName string) (bool, error) {
 	var hasAccess bool
+	// Pola tag mengikuti model.AccessRule.Matches: cocok persis, "*" untuk semua
+	// tag, atau akhiran "/*" untuk semua turunan dalam hierarki tag.
 	sql := `SELECT EXISTS (
                 SELECT 1
                 FROM file_tags ft
-                JOIN file_access_rules far ON ft.tag_name = far.tag_name
+                JOIN file_access_rules far ON (
+                    ft.tag_name = far.tag_name
+                    OR far.tag_name = '*'
+                    OR (right(far.tag_name, 2) = '/*' AND starts_with(ft.tag_name, left(far.tag_name, -1)))
+                )
                 WHERE ft.file_id = $1 AND far.role_name = $2
             );`
 	err := r.db.QueryRow(ctx, sql, fileID, roleName).Scan(&hasAccess)
@@ -104,3 +181,205 @@ func (r *postgresFileRepository) CheckRoleAccess(ctx context.Context, fileID str
 	}
 	return hasAccess, nil
 }
+
+func (r *postgresFileRepository) List(ctx context.Context, filter FileFilter) ([]*model.FileMetadata, error) {
+	var (
+		conditions = []string{"f.deleted_at IS NULL"}
+		args       []interface{}
+	)
+	if len(filter.IDs) > 0 {
+		args = append(args, filter.IDs)
+		conditions = append(conditions, fmt.Sprintf("f.id = ANY($%d::uuid[])", len(args)))
+	}
+	if len(filter.Tags) > 0 {
+		args = append(args, filter.Tags)
+		conditions = append(conditions, fmt.Sprintf(`f.id IN (
+                SELECT file_id FROM file_tags WHERE tag_name = ANY($%[1]d)
+                GROUP BY file_id HAVING COUNT(DISTINCT tag_name) = cardinality($%[1]d::text[]))`, len(args)))
+	}
+	if filter.TagPrefix != "" {
+		args = append(args, filter.TagPrefix)
+		conditions = append(conditions, fmt.Sprintf(`f.id IN (
+                SELECT file_id FROM file_tags WHERE tag_name = $%[1]d OR starts_with(tag_name, $%[1]d || '/'))`, len(args)))
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt, filter.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(f.created_at, f.id) < ($%d, $%d::uuid)", len(args)-1, len(args)))
+	}
+
+	sql := `SELECT f.id, f.original_name, f.storage_path, f.mime_type, f.size_bytes, f.owner_user_id, f.created_at, f.version,
+             COALESCE(f.checksum_sha256, ''), COALESCE(f.checksum_md5, ''), COALESCE(f.checksum_crc32c, ''),
+             f.checksum_verified_at, f.checksum_mismatch_at, f.retain_until, f.sanitized_at, f.content_indexed_at,
+             f.pdf_info, f.provenance,
+             COALESCE(array_agg(ft.tag_name) FILTER (WHERE ft.tag_name IS NOT NULL), '{}') as tags
+            FROM files f
+            LEFT JOIN file_tags ft ON f.id = ft.file_id
+            WHERE ` + strings.Join(conditions, " AND ") + `
+            GROUP BY f.id
+            ORDER BY f.created_at DESC, f.id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		sql += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*model.FileMetadata
+	for rows.Next() {
+		var metadata model.FileMetadata
+		if err := rows.Scan(
+			&metadata.ID, &metadata.OriginalName, &metadata.StoragePath, &metadata.MimeType,
+			&metadata.SizeBytes, &metadata.OwnerUserID, &metadata.CreatedAt, &metadata.Version,
+			&metadata.ChecksumSHA256, &metadata.ChecksumMD5, &metadata.ChecksumCRC32C,
+			&metadata.ChecksumVerifiedAt, &metadata.ChecksumMismatchAt, &metadata.RetainUntil, &metadata.SanitizedAt, &metadata.IndexedAt,
+			&metadata.PDF, &metadata.Provenance, &metadata.Tags,
+		); err != nil {
+			return nil, err
+		}
+		files = append(files, &metadata)
+	}
+	return files, rows.Err()
+}
+
+func (r *postgresFileRepository) UpdateMetadata(ctx context.Context, id string, expectedVersion int64, update MetadataUpdate, actorID string) error {
+	return r.mutate(ctx, id, expectedVersion, actorID, "update_metadata", func(tx pgx.Tx, current *fileState) (map[string]interface{}, error) {
+		changes := make(map[string]interface{})
+		if update.OriginalName != nil && *update.OriginalName != current.originalName {
+			if _, err := tx.Exec(ctx, `UPDATE files SET original_name = $2 WHERE id = $1;`, id, *update.OriginalName); err != nil {
+				return nil, err
+			}
+			changes["original_name"] = map[string]string{"from": current.originalName, "to": *update.OriginalName}
+		}
+		if update.Tags != nil {
+			newTags := dedupeTags(*update.Tags)
+			if !sameTags(current.tags, newTags) {
+				if _, err := tx.Exec(ctx, `DELETE FROM file_tags WHERE file_id = $1;`, id); err != nil {
+					return nil, err
+				}
+				for _, tag := range newTags {
+					if _, err := tx.Exec(ctx, `INSERT INTO file_tags (file_id, tag_name) VALUES ($1, $2);`, id, tag); err != nil {
+						return nil, err
+					}
+				}
+				changes["tags"] = map[string][]string{"from": current.tags, "to": newTags}
+			}
+		}
+		return changes, nil
+	})
+}
+
+func (r *postgresFileRepository) AddTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error {
+	return r.mutate(ctx, id, expectedVersion, actorID, "add_tag", func(tx pgx.Tx, current *fileState) (map[string]interface{}, error) {
+		if slices.Contains(current.tags, tag) {
+			return nil, nil
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO file_tags (file_id, tag_name) VALUES ($1, $2);`, id, tag); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"tag": tag}, nil
+	})
+}
+
+func (r *postgresFileRepository) RemoveTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error {
+	return r.mutate(ctx, id, expectedVersion, actorID, "remove_tag", func(tx pgx.Tx, current *fileState) (map[string]interface{}, error) {
+		if !slices.Contains(current.tags, tag) {
+			return nil, nil
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM file_tags WHERE file_id = $1 AND tag_name = $2;`, id, tag); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"tag": tag}, nil
+	})
+}
+
+// fileState adalah snapshot baris file yang dikunci selama mutasi.
+type fileState struct {
+	originalName string
+	version      int64
+	tags         []string
+}
+
+// mutate menjalankan perubahan metadata dalam satu transaksi: mengunci baris
+// file, memeriksa versi yang diharapkan (0 = tanpa prasyarat), menjalankan
+// apply, lalu menaikkan versi dan mencatat audit log. Jika apply tidak
+// menghasilkan perubahan, transaksi dibatalkan sehingga versi tidak berubah.
+func (r *postgresFileRepository) mutate(ctx context.Context, id string, expectedVersion int64, actorID, action string,
+	apply func(tx pgx.Tx, current *fileState) (map[string]interface{}, error)) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Str("action", action).Msg("Gagal melakukan rollback pada transaksi mutasi file")
+		}
+	}()
+
+	var current fileState
+	sqlLock := `SELECT f.original_name, f.version,
+                 COALESCE((SELECT array_agg(tag_name ORDER BY tag_name) FROM file_tags WHERE file_id = f.id), '{}')
+                FROM files f
+                WHERE f.id = $1 AND f.deleted_at IS NULL
+                FOR UPDATE;`
+	if err := tx.QueryRow(ctx, sqlLock, id).Scan(&current.originalName, &current.version, &current.tags); err != nil {
+		return err
+	}
+	if expectedVersion != 0 && current.version != expectedVersion {
+		return ErrVersionConflict
+	}
+
+	changes, err := apply(tx, &current)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE files SET version = version + 1, updated_at = NOW() WHERE id = $1;`, id); err != nil {
+		return err
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("gagal membuat payload audit: %w", err)
+	}
+	sqlAudit := `INSERT INTO file_audit_log (file_id, actor_user_id, action, changes, version)
+                 VALUES ($1, $2, $3, $4, $5);`
+	if _, err := tx.Exec(ctx, sqlAudit, id, actorID, action, changesJSON, current.version+1); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func dedupeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+func sameTags(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// nullableJSON mengodekan v untuk kolom JSONB; nil disimpan sebagai NULL,
+// bukan JSON null.
+func nullableJSON[T any](v *T) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
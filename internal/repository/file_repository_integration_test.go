@@ -8,10 +8,11 @@ package repository
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -31,7 +32,7 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 
 	// Skema sederhana untuk tes file repository
 	createTablesSQL := `
-    DROP TABLE IF EXISTS file_tags, file_access_rules, files CASCADE;
+    DROP TABLE IF EXISTS jobs, dav_collections, s3_multipart_parts, s3_multipart_uploads, s3_objects, s3_credentials, file_audit_log, file_tags, file_access_rules, files CASCADE;
     CREATE TABLE IF NOT EXISTS files (
         id UUID PRIMARY KEY,
         original_name VARCHAR(255) NOT NULL,
@@ -40,7 +41,27 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
         size_bytes BIGINT NOT NULL,
         owner_user_id VARCHAR(36),
         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-        deleted_at TIMESTAMPTZ
+        deleted_at TIMESTAMPTZ,
+        version BIGINT NOT NULL DEFAULT 1,
+        updated_at TIMESTAMPTZ,
+        checksum_sha256 CHAR(64),
+        checksum_md5 CHAR(32),
+        checksum_crc32c CHAR(8),
+        checksum_verified_at TIMESTAMPTZ,
+        checksum_mismatch_at TIMESTAMPTZ,
+        retain_until TIMESTAMPTZ,
+        sanitized_at TIMESTAMPTZ,
+        content_text TEXT,
+        content_indexed_at TIMESTAMPTZ,
+        search_vector tsvector GENERATED ALWAYS AS (
+            setweight(to_tsvector('indonesian'::regconfig, coalesce(original_name, '')), 'A') ||
+            setweight(to_tsvector('english'::regconfig, coalesce(original_name, '')), 'A') ||
+            setweight(to_tsvector('indonesian'::regconfig, coalesce(content_text, '')), 'B') ||
+            setweight(to_tsvector('english'::regconfig, coalesce(content_text, '')), 'B')
+        ) STORED,
+        rendition_paths TEXT[] NOT NULL DEFAULT '{}',
+        pdf_info JSONB,
+        provenance JSONB
     );
     CREATE TABLE IF NOT EXISTS file_tags (
         file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
@@ -51,13 +72,71 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
         tag_name VARCHAR(100) NOT NULL,
         role_name VARCHAR(100) NOT NULL,
         PRIMARY KEY (tag_name, role_name)
+    );
+    CREATE TABLE IF NOT EXISTS file_audit_log (
+        id BIGSERIAL PRIMARY KEY,
+        file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+        actor_user_id VARCHAR(36),
+        action VARCHAR(50) NOT NULL,
+        changes JSONB NOT NULL DEFAULT '{}',
+        version BIGINT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+    CREATE TABLE IF NOT EXISTS dav_collections (
+        user_id VARCHAR(36) NOT NULL,
+        path VARCHAR(100) COLLATE "C" NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (user_id, path)
+    );
+    CREATE TABLE IF NOT EXISTS s3_credentials (
+        access_key_id VARCHAR(32) PRIMARY KEY,
+        user_id VARCHAR(36) NOT NULL,
+        role_name VARCHAR(50) NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+    CREATE TABLE IF NOT EXISTS s3_objects (
+        bucket VARCHAR(63) NOT NULL,
+        object_key VARCHAR(1024) COLLATE "C" NOT NULL,
+        file_id UUID NOT NULL UNIQUE REFERENCES files(id) ON DELETE CASCADE,
+        etag VARCHAR(64) NOT NULL,
+        PRIMARY KEY (bucket, object_key)
+    );
+    CREATE TABLE IF NOT EXISTS s3_multipart_uploads (
+        upload_id UUID PRIMARY KEY,
+        bucket VARCHAR(63) NOT NULL,
+        object_key VARCHAR(1024) NOT NULL,
+        user_id VARCHAR(36) NOT NULL,
+        tags TEXT[] NOT NULL DEFAULT '{}',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+    CREATE TABLE IF NOT EXISTS s3_multipart_parts (
+        upload_id UUID NOT NULL REFERENCES s3_multipart_uploads(upload_id) ON DELETE CASCADE,
+        part_number INT NOT NULL,
+        etag VARCHAR(64) NOT NULL,
+        size_bytes BIGINT NOT NULL,
+        PRIMARY KEY (upload_id, part_number)
+    );
+    CREATE TABLE IF NOT EXISTS jobs (
+        id UUID PRIMARY KEY,
+        type VARCHAR(50) NOT NULL,
+        file_id UUID REFERENCES files(id) ON DELETE CASCADE,
+        payload JSONB,
+        status VARCHAR(20) NOT NULL DEFAULT 'pending'
+            CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+        attempts INT NOT NULL DEFAULT 0,
+        run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        locked_until TIMESTAMPTZ,
+        last_error TEXT,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        completed_at TIMESTAMPTZ
     );`
 	_, err = pool.Exec(context.Background(), createTablesSQL)
 	require.NoError(t, err, "Failed to create test tables")
 
 	teardown := func() {
 		// Bersihkan tabel setelah tes selesai
-		_, err := pool.Exec(context.Background(), "DROP TABLE IF EXISTS file_tags, file_access_rules, files CASCADE;")
+		_, err := pool.Exec(context.Background(), "DROP TABLE IF EXISTS jobs, dav_collections, s3_multipart_parts, s3_multipart_uploads, s3_objects, s3_credentials, file_audit_log, file_tags, file_access_rules, files CASCADE;")
 		if err != nil {
 			t.Logf("Warning: failed to drop tables on teardown: %v", err)
 		}
@@ -78,6 +157,8 @@ func TestPostgresFileRepository_Integration(t *testing.T) {
 
 	ownerID := uuid.New().String()
 	tags := []string{"invoice", "q1_2025"}
+	retainUntil := time.Now().AddDate(10, 0, 0).UTC().Truncate(time.Second)
+	sanitizedAt := time.Now().UTC().Truncate(time.Second)
 	metadata := &model.FileMetadata{
 		ID:           uuid.New().String(),
 		OriginalName: "invoice_2025.pdf",
@@ -85,6 +166,12 @@ func TestPostgresFileRepository_Integration(t *testing.T) {
 		MimeType:     "application/pdf",
 		SizeBytes:    123456,
 		OwnerUserID:  &ownerID,
+		RetainUntil:  &retainUntil,
+		SanitizedAt:  &sanitizedAt,
+		PDF:          &model.PDFInfo{PageCount: 3, Title: "Invoice"},
+		Provenance: &model.Provenance{Operation: model.ProvenancePDFMerge, Sources: []model.ProvenanceSource{
+			{FileID: uuid.New().String(), OriginalName: "a.pdf", PageCount: 3},
+		}},
 	}
 
 	// 1. Test Create
@@ -103,6 +190,12 @@ func TestPostgresFileRepository_Integration(t *testing.T) {
 	assert.Equal(t, *metadata.OwnerUserID, *retrieved.OwnerUserID)
 	assert.ElementsMatch(t, tags, retrieved.Tags, "Tags should match")
 	assert.WithinDuration(t, time.Now(), retrieved.CreatedAt, 2*time.Second)
+	require.NotNil(t, retrieved.RetainUntil)
+	assert.True(t, retainUntil.Equal(*retrieved.RetainUntil))
+	require.NotNil(t, retrieved.SanitizedAt)
+	assert.True(t, sanitizedAt.Equal(*retrieved.SanitizedAt))
+	assert.Equal(t, metadata.PDF, retrieved.PDF)
+	assert.Equal(t, metadata.Provenance, retrieved.Provenance)
 
 	// 3. Test CheckRoleAccess (kasus gagal)
 	hasAccess, err := repo.CheckRoleAccess(ctx, metadata.ID, "finance")
@@ -118,12 +211,243 @@ func TestPostgresFileRepository_Integration(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, hasAccess, "Role 'finance' sekarang seharusnya memiliki akses")
 
-	// 5. Test DeleteByID
+	// Aturan wildcard hierarkis: "arsip/*" cocok dengan turunan, bukan dengan "arsip" itu sendiri
+	require.NoError(t, repo.AddTag(ctx, metadata.ID, "arsip/2025/q1", 0, ownerID))
+	ruleRepo := NewPostgresAccessRuleRepository(dbpool)
+	require.NoError(t, ruleRepo.CreateRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}))
+	assert.ErrorIs(t, ruleRepo.CreateRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}), ErrRuleExists)
+	hasAccess, err = repo.CheckRoleAccess(ctx, metadata.ID, "auditor")
+	require.NoError(t, err)
+	assert.True(t, hasAccess, "Pola 'arsip/*' seharusnya cocok dengan tag 'arsip/2025/q1'")
+	require.NoError(t, ruleRepo.DeleteRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}))
+	assert.ErrorIs(t, ruleRepo.DeleteRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}), ErrNotFound)
+	require.NoError(t, repo.RemoveTag(ctx, metadata.ID, "arsip/2025/q1", 0, ownerID))
+
+	// 5. Test List dengan filter tag dan ID
+	listed, err := repo.List(ctx, FileFilter{Tags: []string{"invoice", "q1_2025"}})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, metadata.ID, listed[0].ID)
+
+	listed, err = repo.List(ctx, FileFilter{Tags: []string{"invoice", "q2_2025"}})
+	require.NoError(t, err)
+	assert.Empty(t, listed, "File harus memiliki semua tag yang diminta")
+
+	listed, err = repo.List(ctx, FileFilter{IDs: []string{metadata.ID}, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	listed, err = repo.List(ctx, FileFilter{TagPrefix: "invoice"})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	listed, err = repo.List(ctx, FileFilter{TagPrefix: "invoice", After: CursorOf(listed[0])})
+	require.NoError(t, err)
+	assert.Empty(t, listed, "Halaman setelah file terakhir harus kosong")
+	listed, err = repo.List(ctx, FileFilter{TagPrefix: "inv"})
+	require.NoError(t, err)
+	assert.Empty(t, listed, "TagPrefix hanya cocok per segmen hierarki")
+
+	// 6. Test mutasi metadata dengan optimistic concurrency
+	assert.Equal(t, int64(1), retrieved.Version)
+	newName := "invoice_2025_final.pdf"
+	err = repo.UpdateMetadata(ctx, metadata.ID, 3, MetadataUpdate{OriginalName: &newName}, ownerID)
+	require.NoError(t, err)
+
+	err = repo.UpdateMetadata(ctx, metadata.ID, 3, MetadataUpdate{OriginalName: &newName}, ownerID)
+	assert.ErrorIs(t, err, ErrVersionConflict, "Versi lama harus ditolak")
+
+	require.NoError(t, repo.AddTag(ctx, metadata.ID, "audited", 4, ownerID))
+	require.NoError(t, repo.AddTag(ctx, metadata.ID, "audited", 0, ownerID), "Menambah tag yang sudah ada bersifat idempoten")
+	require.NoError(t, repo.RemoveTag(ctx, metadata.ID, "q1_2025", 5, ownerID))
+
+	retrieved, err = repo.GetByID(ctx, metadata.ID)
+	require.NoError(t, err)
+	assert.Equal(t, newName, retrieved.OriginalName)
+	assert.Equal(t, int64(6), retrieved.Version)
+	assert.ElementsMatch(t, []string{"invoice", "audited"}, retrieved.Tags)
+
+	var auditCount int
+	require.NoError(t, dbpool.QueryRow(ctx, "SELECT COUNT(*) FROM file_audit_log WHERE file_id = $1", metadata.ID).Scan(&auditCount))
+	assert.Equal(t, 5, auditCount)
+
+	// 7. Test DeleteByID
 	err = repo.DeleteByID(ctx, metadata.ID)
 	require.NoError(t, err, "DeleteByID should not return an error")
 
-	// 6. Verify Deletion
+	// 8. Verify Deletion
 	_, err = repo.GetByID(ctx, metadata.ID)
 	require.Error(t, err, "GetByID should return an error for a deleted record")
 	assert.ErrorIs(t, err, pgx.ErrNoRows, "The error should be pgx.ErrNoRows")
 }
+
+func TestPostgresIntegrityRepository_Integration(t *testing.T) {
+	dbpool, teardown := setupTestDB(t)
+	defer teardown()
+
+	fileRepo := NewPostgresFileRepository(dbpool)
+	repo := NewPostgresIntegrityRepository(dbpool)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	newFile := func(checksum string) *model.FileMetadata {
+		metadata := &model.FileMetadata{
+			ID:             uuid.New().String(),
+			OriginalName:   "a.txt",
+			StoragePath:    "a.txt",
+			MimeType:       "text/plain",
+			SizeBytes:      11,
+			ChecksumSHA256: checksum,
+		}
+		require.NoError(t, fileRepo.Create(ctx, metadata, nil))
+		return metadata
+	}
+	withoutChecksum := newFile("")
+	old := newFile(strings.Repeat("a", 64))
+	fresh := newFile(strings.Repeat("b", 64))
+
+	retrieved, err := fileRepo.GetByID(ctx, withoutChecksum.ID)
+	require.NoError(t, err)
+	assert.Empty(t, retrieved.ChecksumSHA256)
+
+	require.NoError(t, repo.RecordScrub(ctx, old.ID, true, now.Add(-48*time.Hour)))
+	assert.ErrorIs(t, repo.RecordScrub(ctx, uuid.New().String(), true, now), ErrNotFound)
+
+	due, err := repo.ListForScrub(ctx, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, fresh.ID, due[0].ID, "File yang belum pernah diperiksa harus lebih dulu")
+	assert.Equal(t, fresh.ChecksumSHA256, due[0].ChecksumSHA256)
+	assert.Equal(t, old.ID, due[1].ID)
+
+	require.NoError(t, repo.RecordScrub(ctx, fresh.ID, false, now))
+	retrieved, err = fileRepo.GetByID(ctx, fresh.ID)
+	require.NoError(t, err)
+	require.NotNil(t, retrieved.ChecksumMismatchAt)
+	assert.True(t, retrieved.ChecksumMismatchAt.Equal(now))
+	assert.Equal(t, int64(1), retrieved.Version, "RecordScrub tidak boleh menaikkan Version")
+
+	require.NoError(t, repo.RecordScrub(ctx, fresh.ID, true, now))
+	retrieved, err = fileRepo.GetByID(ctx, fresh.ID)
+	require.NoError(t, err)
+	assert.Nil(t, retrieved.ChecksumMismatchAt)
+}
+
+func TestPostgresSearchRepository_Integration(t *testing.T) {
+	dbpool, teardown := setupTestDB(t)
+	defer teardown()
+
+	fileRepo := NewPostgresFileRepository(dbpool)
+	repo := NewPostgresSearchRepository(dbpool)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	newFile := func(name, text string) *model.FileMetadata {
+		metadata := &model.FileMetadata{
+			ID:           uuid.New().String(),
+			OriginalName: name,
+			StoragePath:  name,
+			MimeType:     "text/plain",
+			SizeBytes:    int64(len(text)),
+			ContentText:  text,
+		}
+		if text != "" {
+			metadata.IndexedAt = &now
+		}
+		require.NoError(t, fileRepo.Create(ctx, metadata, nil))
+		return metadata
+	}
+	budget := newFile("notulen.txt", "Rapat membahas anggaran pembangunan <script>alert(1)</script> tahun depan")
+	report := newFile("annual-reports.txt", "The committee approved the reports")
+	unindexed := newFile("lama.pdf", "")
+
+	hits, err := repo.Search(ctx, SearchQuery{Text: "anggaran", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, budget.ID, hits[0].ID)
+	assert.Contains(t, hits[0].Snippet, "<mark>anggaran</mark>")
+	assert.Contains(t, hits[0].Snippet, "&lt;script&gt;", "Snippet harus di-escape")
+
+	hits, err = repo.Search(ctx, SearchQuery{Text: "report", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1, "Kata dasar bahasa Inggris harus cocok")
+	assert.Equal(t, report.ID, hits[0].ID)
+
+	pending, err := repo.ListForIndex(ctx, "", true, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, unindexed.ID, pending[0].ID)
+
+	require.NoError(t, repo.RecordContent(ctx, unindexed.ID, "Kontrak sewa gedung", now))
+	assert.ErrorIs(t, repo.RecordContent(ctx, uuid.New().String(), "x", now), ErrNotFound)
+	hits, err = repo.Search(ctx, SearchQuery{Text: "sewa gedung", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, unindexed.ID, hits[0].ID)
+
+	retrieved, err := fileRepo.GetByID(ctx, unindexed.ID)
+	require.NoError(t, err)
+	require.NotNil(t, retrieved.IndexedAt)
+	assert.Equal(t, int64(1), retrieved.Version, "RecordContent tidak boleh menaikkan Version")
+
+	all, err := repo.ListForIndex(ctx, "", false, 2)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	rest, err := repo.ListForIndex(ctx, all[1].ID, false, 2)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Greater(t, rest[0].ID, all[1].ID)
+}
+
+func TestPostgresRenditionRepository_Integration(t *testing.T) {
+	dbpool, teardown := setupTestDB(t)
+	defer teardown()
+
+	fileRepo := NewPostgresFileRepository(dbpool)
+	repo := NewPostgresRenditionRepository(dbpool)
+	ctx := context.Background()
+
+	metadata := &model.FileMetadata{ID: uuid.New().String(), OriginalName: "avatar.png", StoragePath: "avatar.png", MimeType: "image/png", SizeBytes: 10}
+	require.NoError(t, fileRepo.Create(ctx, metadata, nil))
+
+	retrieved, err := fileRepo.GetByID(ctx, metadata.ID)
+	require.NoError(t, err)
+	assert.Empty(t, retrieved.RenditionPaths)
+	assert.Nil(t, retrieved.PDF, "Kolom JSONB NULL dibaca sebagai nil")
+	assert.Nil(t, retrieved.Provenance)
+
+	require.NoError(t, repo.RecordRendition(ctx, metadata.ID, "renditions/a/1.png"))
+	require.NoError(t, repo.RecordRendition(ctx, metadata.ID, "renditions/a/2.jpg"))
+	require.NoError(t, repo.RecordRendition(ctx, metadata.ID, "renditions/a/1.png"))
+	assert.ErrorIs(t, repo.RecordRendition(ctx, uuid.New().String(), "x"), ErrNotFound)
+
+	retrieved, err = fileRepo.GetByID(ctx, metadata.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"renditions/a/1.png", "renditions/a/2.jpg"}, retrieved.RenditionPaths)
+	assert.Equal(t, int64(1), retrieved.Version, "RecordRendition tidak boleh menaikkan Version")
+}
+
+func TestPostgresUsageRepository_Integration(t *testing.T) {
+	dbpool, teardown := setupTestDB(t)
+	defer teardown()
+
+	fileRepo := NewPostgresFileRepository(dbpool)
+	repo := NewPostgresUsageRepository(dbpool)
+	ctx := context.Background()
+
+	usage, err := repo.StorageUsage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, StorageUsage{}, usage)
+
+	for _, size := range []int64{10, 32} {
+		metadata := &model.FileMetadata{ID: uuid.New().String(), OriginalName: "a.txt", StoragePath: "a.txt", MimeType: "text/plain", SizeBytes: size}
+		require.NoError(t, fileRepo.Create(ctx, metadata, nil))
+	}
+	deleted := &model.FileMetadata{ID: uuid.New().String(), OriginalName: "b.txt", StoragePath: "b.txt", MimeType: "text/plain", SizeBytes: 100}
+	require.NoError(t, fileRepo.Create(ctx, deleted, nil))
+	_, err = dbpool.Exec(ctx, `UPDATE files SET deleted_at = now() WHERE id = $1`, deleted.ID)
+	require.NoError(t, err)
+
+	usage, err = repo.StorageUsage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, StorageUsage{Files: 2, Bytes: 42}, usage, "File terhapus tidak dihitung")
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IntegrityRepository menyimpan hasil pemeriksaan checksum oleh scrubber.
+type IntegrityRepository interface {
+	// ListForScrub mengembalikan hingga limit file aktif yang memiliki
+	// checksum dan belum diperiksa sejak verifiedBefore, yang belum pernah
+	// diperiksa lebih dulu.
+	ListForScrub(ctx context.Context, verifiedBefore time.Time, limit int) ([]*model.FileMetadata, error)
+	// RecordScrub mencatat waktu pemeriksaan file dan apakah kontennya cocok.
+	// Tidak menaikkan Version karena metadata file tidak berubah.
+	RecordScrub(ctx context.Context, id string, ok bool, at time.Time) error
+}
+
+type postgresIntegrityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresIntegrityRepository(db *pgxpool.Pool) IntegrityRepository {
+	return &postgresIntegrityRepository{db: db}
+}
+
+func (r *postgresIntegrityRepository) ListForScrub(ctx context.Context, verifiedBefore time.Time, limit int) ([]*model.FileMetadata, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, storage_path, size_bytes, checksum_sha256
+            FROM files
+            WHERE deleted_at IS NULL AND checksum_sha256 IS NOT NULL
+              AND (checksum_verified_at IS NULL OR checksum_verified_at < $1)
+            ORDER BY checksum_verified_at NULLS FIRST, created_at
+            LIMIT $2;`, verifiedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*model.FileMetadata
+	for rows.Next() {
+		var metadata model.FileMetadata
+		if err := rows.Scan(&metadata.ID, &metadata.StoragePath, &metadata.SizeBytes, &metadata.ChecksumSHA256); err != nil {
+			return nil, err
+		}
+		files = append(files, &metadata)
+	}
+	return files, rows.Err()
+}
+
+func (r *postgresIntegrityRepository) RecordScrub(ctx context.Context, id string, ok bool, at time.Time) error {
+	tag, err := r.db.Exec(ctx, `UPDATE files
+            SET checksum_verified_at = $2,
+                checksum_mismatch_at = CASE WHEN $3 THEN NULL ELSE $2 END
+            WHERE id = $1 AND deleted_at IS NULL;`, id, at, ok)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrJobNotFailed dikembalikan saat me-retry job yang statusnya bukan failed.
+var ErrJobNotFailed = errors.New("hanya job yang gagal yang dapat di-retry")
+
+// JobRepository menyimpan antrean job latar belakang.
+type JobRepository interface {
+	// Enqueue menyimpan job baru berstatus pending. ID, RunAt dan CreatedAt
+	// diisi jika kosong.
+	Enqueue(ctx context.Context, job *model.Job) error
+	// Claim mengambil hingga limit job bertipe jobType yang sudah waktunya
+	// berjalan, mengubahnya menjadi running dan menaikkan Attempts. Job
+	// running yang lease-nya habis (worker mati) ikut diambil. Job yang
+	// sedang diambil worker lain dilewati.
+	Claim(ctx context.Context, jobType string, limit int, lease time.Duration) ([]*model.Job, error)
+	// Complete menandai job berhasil.
+	Complete(ctx context.Context, id string) error
+	// Fail mencatat error percobaan terakhir. Jika retryAt nil job menjadi
+	// failed; jika tidak, job kembali pending dan dijalankan pada retryAt.
+	Fail(ctx context.Context, id, message string, retryAt *time.Time) error
+	// Release mengembalikan job yang dihentikan sebelum selesai (mis. saat
+	// shutdown) ke pending tanpa menghitungnya sebagai percobaan.
+	Release(ctx context.Context, id string) error
+	// Retry menjadwalkan ulang job failed dengan Attempts direset. Mengembalikan
+	// ErrNotFound jika job tidak ada atau ErrJobNotFailed jika statusnya lain.
+	Retry(ctx context.Context, id string) error
+	GetJob(ctx context.Context, id string) (*model.Job, error)
+	// ListJobs mengembalikan job yang cocok dengan filter, terbaru lebih dulu.
+	ListJobs(ctx context.Context, filter JobFilter) ([]*model.Job, error)
+	// CountQueued mengembalikan jumlah job pending dan running per tipe,
+	// diurutkan menurut tipe lalu status.
+	CountQueued(ctx context.Context) ([]JobCount, error)
+}
+
+// JobCount adalah jumlah job satu tipe dengan status tertentu.
+type JobCount struct {
+	Type   string
+	Status string
+	Count  int
+}
+
+// JobFilter membatasi hasil ListJobs. Field kosong tidak membatasi.
+type JobFilter struct {
+	Status string
+	Type   string
+	FileID string
+	// Limit membatasi jumlah baris yang dikembalikan (0 = tanpa batas).
+	Limit int
+}
+
+// prepareJob melengkapi job baru sebelum disimpan.
+func prepareJob(job *model.Job, now time.Time) {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	job.Status = model.JobStatusPending
+	job.Attempts = 0
+	job.CreatedAt = now
+	job.UpdatedAt = now
+}
+
+type postgresJobRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresJobRepository(db *pgxpool.Pool) JobRepository {
+	return &postgresJobRepository{db: db}
+}
+
+const jobColumns = `id, type, file_id, payload, status, attempts, run_at, COALESCE(last_error, ''), created_at, updated_at, completed_at`
+
+func scanJob(row pgx.Row) (*model.Job, error) {
+	var job model.Job
+	if err := row.Scan(&job.ID, &job.Type, &job.FileID, &job.Payload, &job.Status, &job.Attempts, &job.RunAt,
+		&job.LastError, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *postgresJobRepository) Enqueue(ctx context.Context, job *model.Job) error {
+	prepareJob(job, time.Now())
+	var payload []byte
+	if len(job.Payload) > 0 {
+		payload = job.Payload
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO jobs (id, type, file_id, payload, status, attempts, run_at, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $7);`,
+		job.ID, job.Type, job.FileID, payload, job.Status, job.RunAt, job.CreatedAt)
+	return err
+}
+
+func (r *postgresJobRepository) Claim(ctx context.Context, jobType string, limit int, lease time.Duration) ([]*model.Job, error) {
+	rows, err := r.db.Query(ctx, `UPDATE jobs
+            SET status = 'running', attempts = attempts + 1,
+                locked_until = now() + $3::bigint * interval '1 millisecond', updated_at = now()
+            WHERE id IN (
+                SELECT id FROM jobs
+                WHERE type = $1
+                  AND ((status = 'pending' AND run_at <= now())
+                       OR (status = 'running' AND locked_until < now()))
+                ORDER BY run_at
+                LIMIT $2
+                FOR UPDATE SKIP LOCKED
+            )
+            RETURNING `+jobColumns+`;`, jobType, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*model.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *postgresJobRepository) Complete(ctx context.Context, id string) error {
+	return r.execJob(ctx, `UPDATE jobs
+            SET status = 'succeeded', locked_until = NULL, last_error = NULL,
+                completed_at = now(), updated_at = now()
+            WHERE id = $1;`, id)
+}
+
+func (r *postgresJobRepository) Fail(ctx context.Context, id, message string, retryAt *time.Time) error {
+	return r.execJob(ctx, `UPDATE jobs
+            SET status = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+                run_at = COALESCE($3, run_at), locked_until = NULL, last_error = $2,
+                completed_at = CASE WHEN $3::timestamptz IS NULL THEN now() END, updated_at = now()
+            WHERE id = $1;`, id, message, retryAt)
+}
+
+func (r *postgresJobRepository) Release(ctx context.Context, id string) error {
+	return r.execJob(ctx, `UPDATE jobs
+            SET status = 'pending', attempts = GREATEST(attempts - 1, 0), run_at = now(),
+                locked_until = NULL, updated_at = now()
+            WHERE id = $1 AND status = 'running';`, id)
+}
+
+func (r *postgresJobRepository) Retry(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, `UPDATE jobs
+            SET status = 'pending', attempts = 0, run_at = now(), last_error = NULL,
+                completed_at = NULL, updated_at = now()
+            WHERE id = $1 AND status = 'failed';`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+	if _, err := r.GetJob(ctx, id); err != nil {
+		return err
+	}
+	return ErrJobNotFailed
+}
+
+func (r *postgresJobRepository) execJob(ctx context.Context, sql string, args ...interface{}) error {
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresJobRepository) GetJob(ctx context.Context, id string) (*model.Job, error) {
+	if uuid.Validate(id) != nil {
+		return nil, ErrNotFound
+	}
+	return scanJob(r.db.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1;`, id))
+}
+
+func (r *postgresJobRepository) ListJobs(ctx context.Context, filter JobFilter) ([]*model.Job, error) {
+	var (
+		conditions = []string{"TRUE"}
+		args       []interface{}
+	)
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if filter.FileID != "" {
+		if uuid.Validate(filter.FileID) != nil {
+			return []*model.Job{}, nil
+		}
+		args = append(args, filter.FileID)
+		conditions = append(conditions, fmt.Sprintf("file_id = $%d", len(args)))
+	}
+	sql := `SELECT ` + jobColumns + ` FROM jobs WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY created_at DESC, id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		sql += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*model.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *postgresJobRepository) CountQueued(ctx context.Context) ([]JobCount, error) {
+	rows, err := r.db.Query(ctx, `SELECT type, status, count(*) FROM jobs
+            WHERE status IN ($1, $2)
+            GROUP BY type, status
+            ORDER BY type, status;`, model.JobStatusPending, model.JobStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []JobCount{}
+	for rows.Next() {
+		var count JobCount
+		if err := rows.Scan(&count.Type, &count.Status, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
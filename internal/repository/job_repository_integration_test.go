@@ -0,0 +1,136 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresJobRepository_Integration(t *testing.T) {
+	dbpool, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := context.Background()
+	files := NewPostgresFileRepository(dbpool)
+	repo := NewPostgresJobRepository(dbpool)
+
+	fileID := uuid.New().String()
+	require.NoError(t, files.Create(ctx, &model.FileMetadata{ID: fileID, OriginalName: "a.txt", StoragePath: "a.txt", MimeType: "text/plain"}, nil))
+
+	job := &model.Job{Type: model.JobTypeExtractText, FileID: &fileID, Payload: json.RawMessage(`{"force":true}`)}
+	require.NoError(t, repo.Enqueue(ctx, job))
+	future := &model.Job{Type: model.JobTypeExtractText, RunAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.Enqueue(ctx, future))
+
+	t.Run("Claim skips locked and future jobs", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			claimed []*model.Job
+			wg      sync.WaitGroup
+		)
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				jobs, err := repo.Claim(ctx, model.JobTypeExtractText, 10, time.Minute)
+				assert.NoError(t, err)
+				mu.Lock()
+				claimed = append(claimed, jobs...)
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		require.Len(t, claimed, 1, "Satu job hanya boleh diambil satu worker")
+		assert.Equal(t, job.ID, claimed[0].ID)
+		assert.Equal(t, model.JobStatusRunning, claimed[0].Status)
+		assert.Equal(t, 1, claimed[0].Attempts)
+		assert.JSONEq(t, `{"force":true}`, string(claimed[0].Payload))
+	})
+
+	t.Run("Fail with retry, fail permanently and retry", func(t *testing.T) {
+		retryAt := time.Now().Add(-time.Second)
+		require.NoError(t, repo.Fail(ctx, job.ID, "storage mati", &retryAt))
+		stored, err := repo.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.JobStatusPending, stored.Status)
+		assert.Equal(t, "storage mati", stored.LastError)
+
+		claimed, err := repo.Claim(ctx, model.JobTypeExtractText, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, 2, claimed[0].Attempts)
+
+		require.NoError(t, repo.Fail(ctx, job.ID, "masih mati", nil))
+		failed, err := repo.ListJobs(ctx, JobFilter{Status: model.JobStatusFailed})
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		assert.NotNil(t, failed[0].CompletedAt)
+
+		assert.ErrorIs(t, repo.Retry(ctx, future.ID), ErrJobNotFailed)
+		assert.ErrorIs(t, repo.Retry(ctx, uuid.New().String()), ErrNotFound)
+		require.NoError(t, repo.Retry(ctx, job.ID))
+		stored, err = repo.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.JobStatusPending, stored.Status)
+		assert.Zero(t, stored.Attempts)
+		assert.Nil(t, stored.CompletedAt)
+	})
+
+	t.Run("Release and complete", func(t *testing.T) {
+		claimed, err := repo.Claim(ctx, model.JobTypeExtractText, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		require.NoError(t, repo.Release(ctx, job.ID))
+		assert.ErrorIs(t, repo.Release(ctx, job.ID), ErrNotFound)
+
+		claimed, err = repo.Claim(ctx, model.JobTypeExtractText, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, 1, claimed[0].Attempts, "Release tidak menghitung percobaan")
+		require.NoError(t, repo.Complete(ctx, job.ID))
+
+		jobs, err := repo.ListJobs(ctx, JobFilter{FileID: fileID})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		assert.Equal(t, model.JobStatusSucceeded, jobs[0].Status)
+		assert.Empty(t, jobs[0].LastError)
+
+		counts, err := repo.CountQueued(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []JobCount{{Type: model.JobTypeExtractText, Status: model.JobStatusPending, Count: 1}}, counts, "Hanya job future yang masih mengantre")
+	})
+
+	t.Run("Expired lease is reclaimed", func(t *testing.T) {
+		expiring := &model.Job{Type: "thumbnail"}
+		require.NoError(t, repo.Enqueue(ctx, expiring))
+		claimed, err := repo.Claim(ctx, "thumbnail", 1, time.Millisecond)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		time.Sleep(10 * time.Millisecond)
+
+		claimed, err = repo.Claim(ctx, "thumbnail", 1, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, 2, claimed[0].Attempts)
+	})
+
+	t.Run("Jobs are removed with their file", func(t *testing.T) {
+		_, err := dbpool.Exec(ctx, "DELETE FROM files WHERE id = $1", fileID)
+		require.NoError(t, err)
+		_, err = repo.GetJob(ctx, job.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		jobs, err := repo.ListJobs(ctx, JobFilter{FileID: "bukan-uuid"})
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+}
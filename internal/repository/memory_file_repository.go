@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+)
+
+// MemoryFileRepository adalah FileRepository, IntegrityRepository sekaligus
+// SearchRepository di memori untuk unit test dan mode dev. Semantiknya mengikuti postgresFileRepository: file yang sudah
+// di-soft-delete tidak terlihat, List terurut dari yang terbaru, setiap
+// perubahan menaikkan versi, dan CheckRoleAccess memakai pola aturan yang
+// sama dengan model.AccessRule.Matches. Audit log tidak disimpan.
+type MemoryFileRepository struct {
+	mu    sync.RWMutex
+	files map[string]*memoryFile
+	seq   int64
+	rules AccessRuleRepository
+	now   func() time.Time
+}
+
+type memoryFile struct {
+	metadata model.FileMetadata
+	// content adalah teks hasil ekstraksi; metadata.ContentText selalu kosong.
+	content   string
+	seq       int64
+	deletedAt *time.Time
+}
+
+// NewMemoryFileRepository membuat repository kosong. rules dipakai oleh
+// CheckRoleAccess; nil berarti tidak ada aturan akses.
+func NewMemoryFileRepository(rules AccessRuleRepository) *MemoryFileRepository {
+	return &MemoryFileRepository{files: make(map[string]*memoryFile), rules: rules, now: time.Now}
+}
+
+func (r *MemoryFileRepository) Create(ctx context.Context, metadata *model.FileMetadata, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.files[metadata.ID]; exists {
+		return fmt.Errorf("file dengan id %s sudah ada", metadata.ID)
+	}
+
+	stored := *metadata
+	stored.OwnerUserID = clonePtr(metadata.OwnerUserID)
+	stored.RetainUntil = clonePtr(metadata.RetainUntil)
+	stored.SanitizedAt = clonePtr(metadata.SanitizedAt)
+	stored.IndexedAt = clonePtr(metadata.IndexedAt)
+	stored.PDF = clonePtr(metadata.PDF)
+	stored.Provenance = cloneProvenance(metadata.Provenance)
+	stored.ContentText = ""
+	stored.RenditionPaths = nil
+	stored.Tags = dedupeTags(tags)
+	stored.CreatedAt = r.now()
+	stored.Version = 1
+	stored.ChecksumVerifiedAt, stored.ChecksumMismatchAt = nil, nil
+	r.seq++
+	r.files[metadata.ID] = &memoryFile{metadata: stored, content: metadata.ContentText, seq: r.seq}
+	return nil
+}
+
+func (r *MemoryFileRepository) GetByID(ctx context.Context, id string) (*model.FileMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	file, ok := r.live(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return file.snapshot(), nil
+}
+
+func (r *MemoryFileRepository) DeleteByID(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.files, id)
+	return nil
+}
+
+// SoftDelete menandai file sebagai terhapus tanpa membuang datanya, setara
+// dengan mengisi kolom deleted_at di Postgres.
+func (r *MemoryFileRepository) SoftDelete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, ok := r.live(id)
+	if !ok {
+		return ErrNotFound
+	}
+	deletedAt := r.now()
+	file.deletedAt = &deletedAt
+	return nil
+}
+
+func (r *MemoryFileRepository) CheckRoleAccess(ctx context.Context, fileID string, roleName string) (bool, error) {
+	r.mu.RLock()
+	file, ok := r.files[fileID]
+	var tags []string
+	if ok {
+		tags = slices.Clone(file.metadata.Tags)
+	}
+	r.mu.RUnlock()
+	if !ok || len(tags) == 0 || r.rules == nil {
+		return false, nil
+	}
+
+	rules, err := r.rules.ListRules(ctx)
+	if err != nil {
+		return false, err
+	}
+	return model.RoleHasTagAccess(rules, roleName, tags), nil
+}
+
+func (r *MemoryFileRepository) List(ctx context.Context, filter FileFilter) ([]*model.FileMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*memoryFile
+	for _, file := range r.files {
+		if file.deletedAt == nil && filter.matches(&file.metadata) && (filter.After == nil || filter.After.before(&file.metadata)) {
+			matched = append(matched, file)
+		}
+	}
+	// Urutan sama dengan Postgres: created_at lalu ID, keduanya menurun.
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if !a.metadata.CreatedAt.Equal(b.metadata.CreatedAt) {
+			return a.metadata.CreatedAt.After(b.metadata.CreatedAt)
+		}
+		return a.metadata.ID > b.metadata.ID
+	})
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+
+	files := make([]*model.FileMetadata, 0, len(matched))
+	for _, file := range matched {
+		files = append(files, file.snapshot())
+	}
+	return files, nil
+}
+
+// before melaporkan apakah metadata terletak setelah cursor dalam urutan List.
+func (c *ListCursor) before(metadata *model.FileMetadata) bool {
+	if !metadata.CreatedAt.Equal(c.CreatedAt) {
+		return metadata.CreatedAt.Before(c.CreatedAt)
+	}
+	return metadata.ID < c.ID
+}
+
+func (f FileFilter) matches(metadata *model.FileMetadata) bool {
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, metadata.ID) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(metadata.Tags, tag) {
+			return false
+		}
+	}
+	if f.TagPrefix != "" && !slices.ContainsFunc(metadata.Tags, func(tag string) bool { return underPrefix(tag, f.TagPrefix) }) {
+		return false
+	}
+	return true
+}
+
+func (r *MemoryFileRepository) UpdateMetadata(ctx context.Context, id string, expectedVersion int64, update MetadataUpdate, actorID string) error {
+	return r.mutate(id, expectedVersion, func(metadata *model.FileMetadata) bool {
+		changed := false
+		if update.OriginalName != nil && *update.OriginalName != metadata.OriginalName {
+			metadata.OriginalName = *update.OriginalName
+			changed = true
+		}
+		if update.Tags != nil {
+			newTags := dedupeTags(*update.Tags)
+			if !sameTags(metadata.Tags, newTags) {
+				metadata.Tags = newTags
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+func (r *MemoryFileRepository) AddTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error {
+	return r.mutate(id, expectedVersion, func(metadata *model.FileMetadata) bool {
+		if slices.Contains(metadata.Tags, tag) {
+			return false
+		}
+		metadata.Tags = append(metadata.Tags, tag)
+		return true
+	})
+}
+
+func (r *MemoryFileRepository) RemoveTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error {
+	return r.mutate(id, expectedVersion, func(metadata *model.FileMetadata) bool {
+		index := slices.Index(metadata.Tags, tag)
+		if index < 0 {
+			return false
+		}
+		metadata.Tags = slices.Delete(metadata.Tags, index, index+1)
+		return true
+	})
+}
+
+// mutate menerapkan perubahan pada salinan metadata dan hanya menyimpannya
+// (sambil menaikkan versi) jika apply melaporkan ada perubahan.
+func (r *MemoryFileRepository) mutate(id string, expectedVersion int64, apply func(metadata *model.FileMetadata) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, ok := r.live(id)
+	if !ok {
+		return ErrNotFound
+	}
+	if expectedVersion != 0 && file.metadata.Version != expectedVersion {
+		return ErrVersionConflict
+	}
+
+	updated := *file.snapshot()
+	if !apply(&updated) {
+		return nil
+	}
+	updated.Version++
+	file.metadata = updated
+	return nil
+}
+
+func (r *MemoryFileRepository) ListForScrub(ctx context.Context, verifiedBefore time.Time, limit int) ([]*model.FileMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var due []*memoryFile
+	for _, file := range r.files {
+		verifiedAt := file.metadata.ChecksumVerifiedAt
+		if file.deletedAt == nil && file.metadata.ChecksumSHA256 != "" && (verifiedAt == nil || verifiedAt.Before(verifiedBefore)) {
+			due = append(due, file)
+		}
+	}
+	// Urutan sama dengan Postgres: belum pernah diperiksa lebih dulu, lalu
+	// yang paling lama diperiksa, lalu yang paling lama dibuat.
+	sort.Slice(due, func(i, j int) bool {
+		a, b := due[i].metadata, due[j].metadata
+		switch {
+		case (a.ChecksumVerifiedAt == nil) != (b.ChecksumVerifiedAt == nil):
+			return a.ChecksumVerifiedAt == nil
+		case a.ChecksumVerifiedAt != nil && !a.ChecksumVerifiedAt.Equal(*b.ChecksumVerifiedAt):
+			return a.ChecksumVerifiedAt.Before(*b.ChecksumVerifiedAt)
+		case !a.CreatedAt.Equal(b.CreatedAt):
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return due[i].seq < due[j].seq
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	files := make([]*model.FileMetadata, 0, len(due))
+	for _, file := range due {
+		files = append(files, file.snapshot())
+	}
+	return files, nil
+}
+
+func (r *MemoryFileRepository) RecordScrub(ctx context.Context, id string, ok bool, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, found := r.live(id)
+	if !found {
+		return ErrNotFound
+	}
+	file.metadata.ChecksumVerifiedAt = &at
+	file.metadata.ChecksumMismatchAt = nil
+	if !ok {
+		file.metadata.ChecksumMismatchAt = &at
+	}
+	return nil
+}
+
+// Search mencocokkan setiap kata pada query (tanpa membedakan huruf besar)
+// dengan nama dan teks file. Tidak ada stemming; peringkat adalah jumlah
+// kemunculan kata. Cukup untuk unit test dan mode dev.
+func (r *MemoryFileRepository) Search(ctx context.Context, query SearchQuery) ([]SearchHit, error) {
+	terms := strings.Fields(strings.ToLower(strings.NewReplacer(`"`, " ", "-", " ").Replace(query.Text)))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type match struct {
+		hit SearchHit
+		seq int64
+	}
+	var matches []match
+	for id, file := range r.files {
+		if file.deletedAt != nil || len(terms) == 0 {
+			continue
+		}
+		source := file.content
+		if source == "" {
+			source = file.metadata.OriginalName
+		}
+		haystack := strings.ToLower(file.metadata.OriginalName + " " + file.content)
+		rank := 0
+		for _, term := range terms {
+			count := strings.Count(haystack, term)
+			if count == 0 {
+				rank = 0
+				break
+			}
+			rank += count
+		}
+		if rank > 0 {
+			matches = append(matches, match{hit: SearchHit{ID: id, Rank: float64(rank), Snippet: memorySnippet(source, terms)}, seq: file.seq})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].hit.Rank != matches[j].hit.Rank {
+			return matches[i].hit.Rank > matches[j].hit.Rank
+		}
+		return matches[i].seq > matches[j].seq
+	})
+
+	hits := make([]SearchHit, 0, len(matches))
+	for i, m := range matches {
+		if i < query.Offset || (query.Limit > 0 && len(hits) == query.Limit) {
+			continue
+		}
+		hits = append(hits, m.hit)
+	}
+	return hits, nil
+}
+
+// memorySnippet mengambil hingga 200 karakter di sekitar kata pertama yang
+// cocok dan menandai semua kata yang cocok seperti ts_headline.
+func memorySnippet(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		lower = runes
+	}
+	start := 0
+	if index := strings.Index(string(lower), terms[0]); index >= 0 {
+		start = max(len([]rune(string(lower)[:index]))-50, 0)
+	}
+	end := min(start+200, len(runes))
+
+	var b strings.Builder
+	for i := start; i < end; {
+		matched := 0
+		for _, term := range terms {
+			n := len([]rune(term))
+			if i+n <= end && string(lower[i:i+n]) == term {
+				matched = n
+				break
+			}
+		}
+		if matched == 0 {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		b.WriteString(highlightStart + string(runes[i:i+matched]) + highlightStop)
+		i += matched
+	}
+	return highlightSnippet(b.String())
+}
+
+func (r *MemoryFileRepository) ListForIndex(ctx context.Context, afterID string, onlyMissing bool, limit int) ([]*model.FileMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var files []*model.FileMetadata
+	for id, file := range r.files {
+		if file.deletedAt == nil && id > afterID && (!onlyMissing || file.metadata.IndexedAt == nil) {
+			files = append(files, file.snapshot())
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ID < files[j].ID })
+	if limit > 0 && len(files) > limit {
+		files = files[:limit]
+	}
+	return files, nil
+}
+
+func (r *MemoryFileRepository) RecordContent(ctx context.Context, id, text string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, found := r.live(id)
+	if !found {
+		return ErrNotFound
+	}
+	file.content = text
+	file.metadata.IndexedAt = &at
+	return nil
+}
+
+func (r *MemoryFileRepository) RecordRendition(ctx context.Context, id, path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, found := r.live(id)
+	if !found {
+		return ErrNotFound
+	}
+	if !slices.Contains(file.metadata.RenditionPaths, path) {
+		file.metadata.RenditionPaths = append(file.metadata.RenditionPaths, path)
+	}
+	return nil
+}
+
+func (r *MemoryFileRepository) StorageUsage(ctx context.Context) (StorageUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var usage StorageUsage
+	for _, f := range r.files {
+		if f.deletedAt == nil {
+			usage.Files++
+			usage.Bytes += f.metadata.SizeBytes
+		}
+	}
+	return usage, nil
+}
+
+func (r *MemoryFileRepository) live(id string) (*memoryFile, bool) {
+	file, ok := r.files[id]
+	if !ok || file.deletedAt != nil {
+		return nil, false
+	}
+	return file, true
+}
+
+// snapshot mengembalikan salinan metadata agar pemanggil tidak dapat
+// mengubah state repository.
+func (f *memoryFile) snapshot() *model.FileMetadata {
+	metadata := f.metadata
+	metadata.OwnerUserID = clonePtr(f.metadata.OwnerUserID)
+	metadata.ChecksumVerifiedAt = clonePtr(f.metadata.ChecksumVerifiedAt)
+	metadata.ChecksumMismatchAt = clonePtr(f.metadata.ChecksumMismatchAt)
+	metadata.RetainUntil = clonePtr(f.metadata.RetainUntil)
+	metadata.SanitizedAt = clonePtr(f.metadata.SanitizedAt)
+	metadata.IndexedAt = clonePtr(f.metadata.IndexedAt)
+	metadata.RenditionPaths = slices.Clone(f.metadata.RenditionPaths)
+	metadata.PDF = clonePtr(f.metadata.PDF)
+	metadata.Provenance = cloneProvenance(f.metadata.Provenance)
+	metadata.Tags = slices.Clone(f.metadata.Tags)
+	if metadata.Tags == nil {
+		metadata.Tags = []string{}
+	}
+	return &metadata
+}
+
+func cloneProvenance(p *model.Provenance) *model.Provenance {
+	clone := clonePtr(p)
+	if clone != nil {
+		clone.Sources = slices.Clone(p.Sources)
+	}
+	return clone
+}
+
+func clonePtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	clone := *v
+	return &clone
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryFileRepository mengikuti skenario TestPostgresFileRepository_Integration
+// agar kedua implementasi dijamin berperilaku sama.
+func TestMemoryFileRepository(t *testing.T) {
+	ruleRepo := NewMemoryAccessRuleRepository()
+	repo := NewMemoryFileRepository(ruleRepo)
+	ctx := context.Background()
+
+	ownerID := uuid.New().String()
+	tags := []string{"invoice", "q1_2025"}
+	metadata := &model.FileMetadata{
+		ID:           uuid.New().String(),
+		OriginalName: "invoice_2025.pdf",
+		StoragePath:  "/storage/invoice_2025.pdf",
+		MimeType:     "application/pdf",
+		SizeBytes:    123456,
+		OwnerUserID:  &ownerID,
+	}
+	require.NoError(t, repo.Create(ctx, metadata, tags))
+	assert.Error(t, repo.Create(ctx, metadata, tags), "ID ganda harus ditolak")
+
+	retrieved, err := repo.GetByID(ctx, metadata.ID)
+	require.NoError(t, err)
+	assert.Equal(t, metadata.OriginalName, retrieved.OriginalName)
+	assert.Equal(t, ownerID, *retrieved.OwnerUserID)
+	assert.ElementsMatch(t, tags, retrieved.Tags)
+	assert.WithinDuration(t, time.Now(), retrieved.CreatedAt, 2*time.Second)
+	assert.Equal(t, int64(1), retrieved.Version)
+
+	hasAccess, err := repo.CheckRoleAccess(ctx, metadata.ID, "finance")
+	require.NoError(t, err)
+	assert.False(t, hasAccess)
+	require.NoError(t, ruleRepo.CreateRule(ctx, model.AccessRule{TagName: "invoice", RoleName: "finance"}))
+	hasAccess, err = repo.CheckRoleAccess(ctx, metadata.ID, "finance")
+	require.NoError(t, err)
+	assert.True(t, hasAccess)
+
+	require.NoError(t, repo.AddTag(ctx, metadata.ID, "arsip/2025/q1", 0, ownerID))
+	require.NoError(t, ruleRepo.CreateRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}))
+	assert.ErrorIs(t, ruleRepo.CreateRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}), ErrRuleExists)
+	hasAccess, err = repo.CheckRoleAccess(ctx, metadata.ID, "auditor")
+	require.NoError(t, err)
+	assert.True(t, hasAccess, "Pola 'arsip/*' seharusnya cocok dengan tag 'arsip/2025/q1'")
+	require.NoError(t, ruleRepo.DeleteRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}))
+	assert.ErrorIs(t, ruleRepo.DeleteRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}), ErrNotFound)
+	require.NoError(t, repo.RemoveTag(ctx, metadata.ID, "arsip/2025/q1", 0, ownerID))
+
+	listed, err := repo.List(ctx, FileFilter{Tags: []string{"invoice", "q1_2025"}})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	listed, err = repo.List(ctx, FileFilter{Tags: []string{"invoice", "q2_2025"}})
+	require.NoError(t, err)
+	assert.Empty(t, listed, "File harus memiliki semua tag yang diminta")
+	listed, err = repo.List(ctx, FileFilter{TagPrefix: "inv"})
+	require.NoError(t, err)
+	assert.Empty(t, listed, "TagPrefix hanya cocok per segmen hierarki")
+
+	newName := "invoice_2025_final.pdf"
+	require.NoError(t, repo.UpdateMetadata(ctx, metadata.ID, 3, MetadataUpdate{OriginalName: &newName}, ownerID))
+	assert.ErrorIs(t, repo.UpdateMetadata(ctx, metadata.ID, 3, MetadataUpdate{OriginalName: &newName}, ownerID), ErrVersionConflict)
+	require.NoError(t, repo.AddTag(ctx, metadata.ID, "audited", 4, ownerID))
+	require.NoError(t, repo.AddTag(ctx, metadata.ID, "audited", 0, ownerID), "Menambah tag yang sudah ada bersifat idempoten")
+	require.NoError(t, repo.RemoveTag(ctx, metadata.ID, "q1_2025", 5, ownerID))
+
+	retrieved, err = repo.GetByID(ctx, metadata.ID)
+	require.NoError(t, err)
+	assert.Equal(t, newName, retrieved.OriginalName)
+	assert.Equal(t, int64(6), retrieved.Version)
+	assert.ElementsMatch(t, []string{"invoice", "audited"}, retrieved.Tags)
+
+	require.NoError(t, repo.DeleteByID(ctx, metadata.ID))
+	_, err = repo.GetByID(ctx, metadata.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryFileRepository_SoftDelete(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "a", SizeBytes: 10}, []string{"laporan"}))
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "b", SizeBytes: 32}, []string{"laporan"}))
+
+	require.NoError(t, repo.SoftDelete(ctx, "a"))
+	assert.ErrorIs(t, repo.SoftDelete(ctx, "a"), ErrNotFound)
+
+	usage, err := repo.StorageUsage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, StorageUsage{Files: 1, Bytes: 32}, usage, "File terhapus tidak dihitung")
+
+	_, err = repo.GetByID(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.AddTag(ctx, "a", "baru", 0, ""), ErrNotFound)
+	listed, err := repo.List(ctx, FileFilter{Tags: []string{"laporan"}})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "b", listed[0].ID)
+}
+
+func TestMemoryFileRepository_ListOrderAndLimit(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	for i := range 3 {
+		require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: fmt.Sprintf("file-%d", i)}, nil))
+	}
+
+	listed, err := repo.List(ctx, FileFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, "file-2", listed[0].ID, "File terbaru harus muncul lebih dulu")
+	assert.Equal(t, "file-1", listed[1].ID)
+	assert.NotNil(t, listed[0].Tags)
+
+	listed, err = repo.List(ctx, FileFilter{Limit: 2, After: CursorOf(listed[1])})
+	require.NoError(t, err)
+	require.Len(t, listed, 1, "Halaman berikutnya dimulai setelah cursor")
+	assert.Equal(t, "file-0", listed[0].ID)
+
+	listed, err = repo.List(ctx, FileFilter{IDs: []string{"file-0", "tidak-ada"}})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "file-0", listed[0].ID)
+}
+
+func TestMemoryFileRepository_ReturnsCopies(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	owner := "user-1"
+	input := &model.FileMetadata{ID: "a", OwnerUserID: &owner}
+	tags := []string{"x"}
+	require.NoError(t, repo.Create(ctx, input, tags))
+	owner = "diubah"
+	tags[0] = "diubah"
+
+	retrieved, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	retrieved.Tags[0] = "dimutasi"
+	*retrieved.OwnerUserID = "dimutasi"
+
+	again, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x"}, again.Tags)
+	assert.Equal(t, "user-1", *again.OwnerUserID)
+}
+
+func TestMemoryFileRepository_ConcurrentMutations(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "a"}, nil))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, repo.AddTag(ctx, "a", fmt.Sprintf("tag-%d", i), 0, ""))
+			_, _ = repo.List(ctx, FileFilter{})
+		}()
+	}
+	wg.Wait()
+
+	retrieved, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Len(t, retrieved.Tags, 50)
+	assert.Equal(t, int64(51), retrieved.Version)
+}
+
+func TestMemoryFileRepository_Scrub(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "tanpa-checksum"}, nil))
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "lama", ChecksumSHA256: "aa"}, nil))
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "baru", ChecksumSHA256: "bb"}, nil))
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "dihapus", ChecksumSHA256: "cc"}, nil))
+	require.NoError(t, repo.SoftDelete(ctx, "dihapus"))
+
+	require.NoError(t, repo.RecordScrub(ctx, "lama", true, now.Add(-48*time.Hour)))
+	assert.ErrorIs(t, repo.RecordScrub(ctx, "dihapus", true, now), ErrNotFound)
+
+	due, err := repo.ListForScrub(ctx, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, "baru", due[0].ID, "File yang belum pernah diperiksa harus lebih dulu")
+	assert.Equal(t, "lama", due[1].ID)
+
+	require.NoError(t, repo.RecordScrub(ctx, "baru", false, now))
+	retrieved, err := repo.GetByID(ctx, "baru")
+	require.NoError(t, err)
+	require.NotNil(t, retrieved.ChecksumMismatchAt)
+	assert.True(t, retrieved.ChecksumMismatchAt.Equal(now))
+
+	require.NoError(t, repo.RecordScrub(ctx, "baru", true, now))
+	retrieved, err = repo.GetByID(ctx, "baru")
+	require.NoError(t, err)
+	assert.Nil(t, retrieved.ChecksumMismatchAt, "Pemeriksaan yang cocok harus menghapus tanda rusak")
+
+	due, err = repo.ListForScrub(ctx, now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "lama", due[0].ID)
+}
+
+func TestMemoryFileRepository_Search(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "a", OriginalName: "notulen.txt", ContentText: "Rapat membahas Anggaran <b>tahun</b> depan"}, nil))
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "b", OriginalName: "anggaran-2025.xlsx"}, nil))
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "c", OriginalName: "foto.png"}, nil))
+
+	hits, err := repo.Search(ctx, SearchQuery{Text: "anggaran", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "b", hits[0].ID, "Peringkat sama: file terbaru lebih dulu")
+	assert.Equal(t, "<mark>anggaran</mark>-2025.xlsx", hits[0].Snippet)
+	assert.Equal(t, "Rapat membahas <mark>Anggaran</mark> &lt;b&gt;tahun&lt;/b&gt; depan", hits[1].Snippet)
+
+	hits, err = repo.Search(ctx, SearchQuery{Text: `"anggaran tahun"`, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1, "Semua kata harus cocok")
+
+	hits, err = repo.Search(ctx, SearchQuery{Text: "anggaran", Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "a", hits[0].ID)
+
+	metadata, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Empty(t, metadata.ContentText, "Teks isi tidak dikembalikan saat membaca metadata")
+}
+
+func TestMemoryFileRepository_RecordContent(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"c", "a", "b"} {
+		require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: id, OriginalName: id + ".txt"}, nil))
+	}
+	require.NoError(t, repo.RecordContent(ctx, "b", "kontrak sewa", now))
+	assert.ErrorIs(t, repo.RecordContent(ctx, "tidak-ada", "x", now), ErrNotFound)
+
+	pending, err := repo.ListForIndex(ctx, "", true, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "a", pending[0].ID)
+	assert.Equal(t, "c", pending[1].ID)
+
+	page, err := repo.ListForIndex(ctx, "a", false, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "b", page[0].ID)
+	require.NotNil(t, page[0].IndexedAt)
+
+	hits, err := repo.Search(ctx, SearchQuery{Text: "sewa"})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "b", hits[0].ID)
+}
+
+func TestMemoryFileRepository_RecordRendition(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "a", OriginalName: "a.png"}, nil))
+
+	require.NoError(t, repo.RecordRendition(ctx, "a", "renditions/a/1.png"))
+	require.NoError(t, repo.RecordRendition(ctx, "a", "renditions/a/2.jpg"))
+	require.NoError(t, repo.RecordRendition(ctx, "a", "renditions/a/1.png"))
+	assert.ErrorIs(t, repo.RecordRendition(ctx, "tidak-ada", "x"), ErrNotFound)
+
+	metadata, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"renditions/a/1.png", "renditions/a/2.jpg"}, metadata.RenditionPaths)
+	assert.Equal(t, int64(1), metadata.Version, "RecordRendition tidak boleh menaikkan Version")
+}
+
+func TestMemoryFileRepository_PDFAndProvenance(t *testing.T) {
+	repo := NewMemoryFileRepository(nil)
+	ctx := context.Background()
+	metadata := &model.FileMetadata{
+		ID:           "gabungan",
+		OriginalName: "gabungan.pdf",
+		PDF:          &model.PDFInfo{PageCount: 3},
+		Provenance: &model.Provenance{Operation: model.ProvenancePDFMerge, Sources: []model.ProvenanceSource{
+			{FileID: "a", OriginalName: "a.pdf", PageCount: 1},
+		}},
+	}
+	require.NoError(t, repo.Create(ctx, metadata, nil))
+	metadata.PDF.PageCount = 99
+	metadata.Provenance.Sources[0].FileID = "diubah"
+
+	retrieved, err := repo.GetByID(ctx, "gabungan")
+	require.NoError(t, err)
+	assert.Equal(t, &model.PDFInfo{PageCount: 3}, retrieved.PDF)
+	assert.Equal(t, "a", retrieved.Provenance.Sources[0].FileID, "Repository menyimpan salinan, bukan pointer pemanggil")
+
+	retrieved.Provenance.Sources[0].FileID = "diubah"
+	again, err := repo.GetByID(ctx, "gabungan")
+	require.NoError(t, err)
+	assert.Equal(t, "a", again.Provenance.Sources[0].FileID)
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+)
+
+// MemoryJobRepository adalah JobRepository di memori untuk unit test dan mode
+// dev.
+type MemoryJobRepository struct {
+	mu   sync.Mutex
+	jobs []*memoryJob
+	now  func() time.Time
+}
+
+type memoryJob struct {
+	job         model.Job
+	lockedUntil time.Time
+}
+
+func NewMemoryJobRepository() *MemoryJobRepository {
+	return &MemoryJobRepository{now: time.Now}
+}
+
+func (j *memoryJob) snapshot() *model.Job {
+	job := j.job
+	job.FileID = clonePtr(job.FileID)
+	job.Payload = slices.Clone(job.Payload)
+	job.CompletedAt = clonePtr(job.CompletedAt)
+	return &job
+}
+
+func (r *MemoryJobRepository) Enqueue(ctx context.Context, job *model.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prepareJob(job, r.now())
+	stored := &memoryJob{job: *job}
+	stored.job.FileID = clonePtr(job.FileID)
+	stored.job.Payload = slices.Clone(job.Payload)
+	r.jobs = append(r.jobs, stored)
+	return nil
+}
+
+func (r *MemoryJobRepository) Claim(ctx context.Context, jobType string, limit int, lease time.Duration) ([]*model.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+
+	var ready []*memoryJob
+	for _, j := range r.jobs {
+		if j.job.Type != jobType {
+			continue
+		}
+		due := j.job.Status == model.JobStatusPending && !j.job.RunAt.After(now)
+		expired := j.job.Status == model.JobStatusRunning && j.lockedUntil.Before(now)
+		if due || expired {
+			ready = append(ready, j)
+		}
+	}
+	sort.SliceStable(ready, func(a, b int) bool { return ready[a].job.RunAt.Before(ready[b].job.RunAt) })
+	if len(ready) > limit {
+		ready = ready[:limit]
+	}
+
+	claimed := make([]*model.Job, 0, len(ready))
+	for _, j := range ready {
+		j.job.Status = model.JobStatusRunning
+		j.job.Attempts++
+		j.job.UpdatedAt = now
+		j.lockedUntil = now.Add(lease)
+		claimed = append(claimed, j.snapshot())
+	}
+	return claimed, nil
+}
+
+func (r *MemoryJobRepository) Complete(ctx context.Context, id string) error {
+	return r.update(id, func(j *memoryJob, now time.Time) error {
+		j.job.Status = model.JobStatusSucceeded
+		j.job.LastError = ""
+		j.job.CompletedAt = &now
+		return nil
+	})
+}
+
+func (r *MemoryJobRepository) Fail(ctx context.Context, id, message string, retryAt *time.Time) error {
+	return r.update(id, func(j *memoryJob, now time.Time) error {
+		j.job.LastError = message
+		if retryAt == nil {
+			j.job.Status = model.JobStatusFailed
+			j.job.CompletedAt = &now
+			return nil
+		}
+		j.job.Status = model.JobStatusPending
+		j.job.RunAt = *retryAt
+		return nil
+	})
+}
+
+func (r *MemoryJobRepository) Release(ctx context.Context, id string) error {
+	return r.update(id, func(j *memoryJob, now time.Time) error {
+		if j.job.Status != model.JobStatusRunning {
+			return ErrNotFound
+		}
+		j.job.Status = model.JobStatusPending
+		j.job.Attempts = max(j.job.Attempts-1, 0)
+		j.job.RunAt = now
+		return nil
+	})
+}
+
+func (r *MemoryJobRepository) Retry(ctx context.Context, id string) error {
+	return r.update(id, func(j *memoryJob, now time.Time) error {
+		if j.job.Status != model.JobStatusFailed {
+			return ErrJobNotFailed
+		}
+		j.job.Status = model.JobStatusPending
+		j.job.Attempts = 0
+		j.job.RunAt = now
+		j.job.LastError = ""
+		j.job.CompletedAt = nil
+		return nil
+	})
+}
+
+// update menjalankan fn pada job dengan ID id di bawah lock, lalu mengosongkan
+// lease dan memperbarui UpdatedAt jika fn berhasil.
+func (r *MemoryJobRepository) update(id string, fn func(j *memoryJob, now time.Time) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.jobs {
+		if j.job.ID != id {
+			continue
+		}
+		now := r.now()
+		if err := fn(j, now); err != nil {
+			return err
+		}
+		j.lockedUntil = time.Time{}
+		j.job.UpdatedAt = now
+		return nil
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryJobRepository) GetJob(ctx context.Context, id string) (*model.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.jobs {
+		if j.job.ID == id {
+			return j.snapshot(), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryJobRepository) ListJobs(ctx context.Context, filter JobFilter) ([]*model.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobs := []*model.Job{}
+	// Urutan sisip sama dengan ORDER BY created_at DESC di Postgres.
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		j := r.jobs[i]
+		if filter.Status != "" && j.job.Status != filter.Status {
+			continue
+		}
+		if filter.Type != "" && j.job.Type != filter.Type {
+			continue
+		}
+		if filter.FileID != "" && (j.job.FileID == nil || *j.job.FileID != filter.FileID) {
+			continue
+		}
+		jobs = append(jobs, j.snapshot())
+		if filter.Limit > 0 && len(jobs) == filter.Limit {
+			break
+		}
+	}
+	return jobs, nil
+}
+
+func (r *MemoryJobRepository) CountQueued(ctx context.Context) ([]JobCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[JobCount]int)
+	for _, j := range r.jobs {
+		if j.job.Status == model.JobStatusPending || j.job.Status == model.JobStatusRunning {
+			counts[JobCount{Type: j.job.Type, Status: j.job.Status}]++
+		}
+	}
+	result := []JobCount{}
+	for key, n := range counts {
+		key.Count = n
+		result = append(result, key)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Type != result[b].Type {
+			return result[a].Type < result[b].Type
+		}
+		return result[a].Status < result[b].Status
+	})
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryJobRepository_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryJobRepository()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	fileID := "file-1"
+	first := &model.Job{Type: model.JobTypeExtractText, FileID: &fileID}
+	later := &model.Job{Type: model.JobTypeExtractText, RunAt: now.Add(time.Minute)}
+	other := &model.Job{Type: "thumbnail"}
+	for _, job := range []*model.Job{first, later, other} {
+		require.NoError(t, repo.Enqueue(ctx, job))
+	}
+	assert.NotEmpty(t, first.ID)
+	assert.Equal(t, model.JobStatusPending, first.Status)
+
+	claimed, err := repo.Claim(ctx, model.JobTypeExtractText, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "Job yang belum waktunya dan tipe lain tidak diambil")
+	assert.Equal(t, first.ID, claimed[0].ID)
+	assert.Equal(t, model.JobStatusRunning, claimed[0].Status)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	claimed, err = repo.Claim(ctx, model.JobTypeExtractText, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "Job yang sedang berjalan tidak diambil dua kali")
+
+	now = now.Add(2 * time.Minute)
+	claimed, err = repo.Claim(ctx, model.JobTypeExtractText, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "Limit dipatuhi")
+	assert.Equal(t, first.ID, claimed[0].ID, "Lease yang habis diambil ulang lebih dulu karena run_at lebih awal")
+	assert.Equal(t, 2, claimed[0].Attempts)
+
+	retryAt := now.Add(time.Hour)
+	require.NoError(t, repo.Fail(ctx, first.ID, "storage mati", &retryAt))
+	job, err := repo.GetJob(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPending, job.Status)
+	assert.Equal(t, retryAt, job.RunAt)
+	assert.Equal(t, "storage mati", job.LastError)
+
+	require.NoError(t, repo.Fail(ctx, first.ID, "masih mati", nil))
+	assert.ErrorIs(t, repo.Retry(ctx, later.ID), ErrJobNotFailed)
+	assert.ErrorIs(t, repo.Retry(ctx, "tidak-ada"), ErrNotFound)
+	require.NoError(t, repo.Retry(ctx, first.ID))
+	job, err = repo.GetJob(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPending, job.Status)
+	assert.Zero(t, job.Attempts)
+	assert.Empty(t, job.LastError)
+	assert.Nil(t, job.CompletedAt)
+
+	claimed, err = repo.Claim(ctx, model.JobTypeExtractText, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.NoError(t, repo.Release(ctx, later.ID))
+	require.NoError(t, repo.Complete(ctx, first.ID))
+	assert.ErrorIs(t, repo.Release(ctx, first.ID), ErrNotFound, "Hanya job yang berjalan yang dapat dikembalikan")
+
+	job, err = repo.GetJob(ctx, later.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPending, job.Status)
+	assert.Zero(t, job.Attempts)
+
+	counts, err := repo.CountQueued(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []JobCount{
+		{Type: model.JobTypeExtractText, Status: model.JobStatusPending, Count: 1},
+		{Type: "thumbnail", Status: model.JobStatusPending, Count: 1},
+	}, counts, "Job yang selesai tidak dihitung")
+
+	jobs, err := repo.ListJobs(ctx, JobFilter{FileID: fileID})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, model.JobStatusSucceeded, jobs[0].Status)
+	require.NotNil(t, jobs[0].CompletedAt)
+
+	jobs, err = repo.ListJobs(ctx, JobFilter{Type: model.JobTypeExtractText, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, later.ID, jobs[0].ID, "Job terbaru lebih dulu")
+
+	jobs, err = repo.ListJobs(ctx, JobFilter{Status: model.JobStatusFailed})
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+)
+
+// MemoryAccessRuleRepository adalah AccessRuleRepository di memori untuk unit
+// test dan mode dev.
+type MemoryAccessRuleRepository struct {
+	mu    sync.RWMutex
+	rules []model.AccessRule
+}
+
+func NewMemoryAccessRuleRepository() *MemoryAccessRuleRepository {
+	return &MemoryAccessRuleRepository{}
+}
+
+func (r *MemoryAccessRuleRepository) ListRules(ctx context.Context) ([]model.AccessRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := slices.Clone(r.rules)
+	if rules == nil {
+		rules = []model.AccessRule{}
+	}
+	return rules, nil
+}
+
+func (r *MemoryAccessRuleRepository) CreateRule(ctx context.Context, rule model.AccessRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Contains(r.rules, rule) {
+		return ErrRuleExists
+	}
+	r.rules = append(r.rules, rule)
+	// Urutan sama dengan ORDER BY tag_name, role_name di Postgres.
+	sort.Slice(r.rules, func(i, j int) bool {
+		if r.rules[i].TagName != r.rules[j].TagName {
+			return r.rules[i].TagName < r.rules[j].TagName
+		}
+		return r.rules[i].RoleName < r.rules[j].RoleName
+	})
+	return nil
+}
+
+func (r *MemoryAccessRuleRepository) DeleteRule(ctx context.Context, rule model.AccessRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index := slices.Index(r.rules, rule)
+	if index < 0 {
+		return ErrNotFound
+	}
+	r.rules = slices.Delete(r.rules, index, index+1)
+	return nil
+}
+
+// MemoryS3Repository adalah S3Repository di memori untuk unit test dan mode
+// dev. Jika dibuat dengan MemoryFileRepository, pemetaan key ke file yang
+// sudah dihapus permanen ikut hilang, meniru ON DELETE CASCADE di Postgres.
+type MemoryS3Repository struct {
+	mu          sync.RWMutex
+	files       *MemoryFileRepository
+	credentials map[string]model.S3Credential
+	objects     map[string]map[string]model.S3Object
+	uploads     map[string]model.S3MultipartUpload
+	parts       map[string]map[int]model.S3MultipartPart
+}
+
+func NewMemoryS3Repository(files *MemoryFileRepository) *MemoryS3Repository {
+	return &MemoryS3Repository{
+		files:       files,
+		credentials: make(map[string]model.S3Credential),
+		objects:     make(map[string]map[string]model.S3Object),
+		uploads:     make(map[string]model.S3MultipartUpload),
+		parts:       make(map[string]map[int]model.S3MultipartPart),
+	}
+}
+
+func (r *MemoryS3Repository) GetCredential(ctx context.Context, accessKeyID string) (*model.S3Credential, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	credential, ok := r.credentials[accessKeyID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &credential, nil
+}
+
+func (r *MemoryS3Repository) ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	credentials := []model.S3Credential{}
+	for _, credential := range r.credentials {
+		if credential.UserID == userID {
+			credentials = append(credentials, credential)
+		}
+	}
+	sort.Slice(credentials, func(i, j int) bool { return credentials[i].CreatedAt.Before(credentials[j].CreatedAt) })
+	return credentials, nil
+}
+
+func (r *MemoryS3Repository) CreateCredential(ctx context.Context, credential *model.S3Credential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	credential.CreatedAt = time.Now()
+	stored := *credential
+	// Secret tidak pernah disimpan, sama seperti di Postgres.
+	stored.SecretAccessKey = ""
+	r.credentials[credential.AccessKeyID] = stored
+	return nil
+}
+
+func (r *MemoryS3Repository) DeleteCredential(ctx context.Context, userID, accessKeyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	credential, ok := r.credentials[accessKeyID]
+	if !ok || credential.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.credentials, accessKeyID)
+	return nil
+}
+
+func (r *MemoryS3Repository) GetObject(ctx context.Context, bucket, key string) (*model.S3Object, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	object, ok := r.objects[bucket][key]
+	if !ok || !r.fileExists(object.FileID) {
+		return nil, ErrNotFound
+	}
+	return &object, nil
+}
+
+func (r *MemoryS3Repository) PutObject(ctx context.Context, object model.S3Object) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.objects[object.Bucket] == nil {
+		r.objects[object.Bucket] = make(map[string]model.S3Object)
+	}
+	previous := r.objects[object.Bucket][object.Key].FileID
+	if !r.fileExists(previous) {
+		previous = ""
+	}
+	r.objects[object.Bucket][object.Key] = object
+	return previous, nil
+}
+
+func (r *MemoryS3Repository) ListObjects(ctx context.Context, bucket, prefix, startAfter string, limit int) ([]model.S3Object, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var objects []model.S3Object
+	for key, object := range r.objects[bucket] {
+		if strings.HasPrefix(key, prefix) && key > startAfter && r.fileExists(object.FileID) {
+			objects = append(objects, object)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	if len(objects) > limit {
+		objects = objects[:limit]
+	}
+	return objects, nil
+}
+
+func (r *MemoryS3Repository) fileExists(id string) bool {
+	if r.files == nil || id == "" {
+		return id != ""
+	}
+	r.files.mu.RLock()
+	defer r.files.mu.RUnlock()
+	_, ok := r.files.files[id]
+	return ok
+}
+
+func (r *MemoryS3Repository) CreateUpload(ctx context.Context, upload model.S3MultipartUpload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload.Tags = slices.Clone(upload.Tags)
+	if upload.Tags == nil {
+		upload.Tags = []string{}
+	}
+	upload.CreatedAt = time.Now()
+	r.uploads[upload.UploadID] = upload
+	return nil
+}
+
+func (r *MemoryS3Repository) GetUpload(ctx context.Context, uploadID string) (*model.S3MultipartUpload, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	upload, ok := r.uploads[uploadID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	upload.Tags = slices.Clone(upload.Tags)
+	return &upload, nil
+}
+
+func (r *MemoryS3Repository) PutPart(ctx context.Context, part model.S3MultipartPart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.parts[part.UploadID] == nil {
+		r.parts[part.UploadID] = make(map[int]model.S3MultipartPart)
+	}
+	r.parts[part.UploadID][part.PartNumber] = part
+	return nil
+}
+
+func (r *MemoryS3Repository) ListParts(ctx context.Context, uploadID string) ([]model.S3MultipartPart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var parts []model.S3MultipartPart
+	for _, part := range r.parts[uploadID] {
+		parts = append(parts, part)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (r *MemoryS3Repository) DeleteUpload(ctx context.Context, uploadID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.uploads, uploadID)
+	delete(r.parts, uploadID)
+	return nil
+}
+
+// MemoryDAVCollectionRepository adalah DAVCollectionRepository di memori
+// untuk unit test dan mode dev.
+type MemoryDAVCollectionRepository struct {
+	mu          sync.RWMutex
+	collections map[string]map[string]struct{}
+}
+
+func NewMemoryDAVCollectionRepository() *MemoryDAVCollectionRepository {
+	return &MemoryDAVCollectionRepository{collections: make(map[string]map[string]struct{})}
+}
+
+func (r *MemoryDAVCollectionRepository) ListCollections(ctx context.Context, userID, prefix string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var paths []string
+	for path := range r.collections[userID] {
+		if prefix == "" || underPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+func (r *MemoryDAVCollectionRepository) CreateCollection(ctx context.Context, userID, path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.collections[userID] == nil {
+		r.collections[userID] = make(map[string]struct{})
+	}
+	r.collections[userID][path] = struct{}{}
+	return nil
+}
+
+func (r *MemoryDAVCollectionRepository) DeleteCollections(ctx context.Context, userID, prefix string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for path := range r.collections[userID] {
+		if underPrefix(path, prefix) {
+			delete(r.collections[userID], path)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryDAVCollectionRepository) RenameCollections(ctx context.Context, userID, oldPrefix, newPrefix string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var moved []string
+	for path := range r.collections[userID] {
+		if underPrefix(path, oldPrefix) {
+			moved = append(moved, path)
+		}
+	}
+	for _, path := range moved {
+		delete(r.collections[userID], path)
+	}
+	for _, path := range moved {
+		r.collections[userID][newPrefix+strings.TrimPrefix(path, oldPrefix)] = struct{}{}
+	}
+	return nil
+}
+
+func underPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
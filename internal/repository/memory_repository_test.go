@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAccessRuleRepository_SortedLikePostgres(t *testing.T) {
+	repo := NewMemoryAccessRuleRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.CreateRule(ctx, model.AccessRule{TagName: "pajak", RoleName: "finance"}))
+	require.NoError(t, repo.CreateRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "finance"}))
+	require.NoError(t, repo.CreateRule(ctx, model.AccessRule{TagName: "arsip/*", RoleName: "auditor"}))
+
+	rules, err := repo.ListRules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []model.AccessRule{
+		{TagName: "arsip/*", RoleName: "auditor"},
+		{TagName: "arsip/*", RoleName: "finance"},
+		{TagName: "pajak", RoleName: "finance"},
+	}, rules)
+}
+
+// TestMemoryS3Repository mengikuti skenario TestPostgresS3Repository_Integration.
+func TestMemoryS3Repository(t *testing.T) {
+	ctx := context.Background()
+	files := NewMemoryFileRepository(nil)
+	repo := NewMemoryS3Repository(files)
+
+	ownerID := uuid.New().String()
+	newFile := func() string {
+		metadata := &model.FileMetadata{ID: uuid.New().String(), OriginalName: "laporan.txt", OwnerUserID: &ownerID}
+		require.NoError(t, files.Create(ctx, metadata, nil))
+		return metadata.ID
+	}
+
+	t.Run("Credentials", func(t *testing.T) {
+		credential := &model.S3Credential{AccessKeyID: "PRSMMEMORY0000000001", SecretAccessKey: "rahasia", UserID: ownerID, Role: "finance"}
+		require.NoError(t, repo.CreateCredential(ctx, credential))
+		assert.False(t, credential.CreatedAt.IsZero())
+
+		found, err := repo.GetCredential(ctx, credential.AccessKeyID)
+		require.NoError(t, err)
+		assert.Equal(t, "finance", found.Role)
+		assert.Empty(t, found.SecretAccessKey, "Secret tidak boleh disimpan")
+
+		assert.ErrorIs(t, repo.DeleteCredential(ctx, "user-lain", credential.AccessKeyID), ErrNotFound)
+		require.NoError(t, repo.DeleteCredential(ctx, ownerID, credential.AccessKeyID))
+		_, err = repo.GetCredential(ctx, credential.AccessKeyID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Objects", func(t *testing.T) {
+		first, second := newFile(), newFile()
+		previous, err := repo.PutObject(ctx, model.S3Object{Bucket: "laporan", Key: "a/b.txt", FileID: first, ETag: "e1"})
+		require.NoError(t, err)
+		assert.Empty(t, previous)
+
+		previous, err = repo.PutObject(ctx, model.S3Object{Bucket: "laporan", Key: "a/b.txt", FileID: second, ETag: "e2"})
+		require.NoError(t, err)
+		assert.Equal(t, first, previous)
+
+		_, err = repo.PutObject(ctx, model.S3Object{Bucket: "laporan", Key: "a/c.txt", FileID: newFile(), ETag: "e3"})
+		require.NoError(t, err)
+
+		objects, err := repo.ListObjects(ctx, "laporan", "a/", "a/b.txt", 10)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		assert.Equal(t, "a/c.txt", objects[0].Key)
+
+		// Menghapus file ikut menghapus pemetaan key-nya.
+		require.NoError(t, files.DeleteByID(ctx, second))
+		_, err = repo.GetObject(ctx, "laporan", "a/b.txt")
+		assert.ErrorIs(t, err, ErrNotFound)
+		objects, err = repo.ListObjects(ctx, "laporan", "", "", 10)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+	})
+
+	t.Run("Multipart uploads", func(t *testing.T) {
+		uploadID := uuid.New().String()
+		require.NoError(t, repo.CreateUpload(ctx, model.S3MultipartUpload{UploadID: uploadID, Bucket: "laporan", Key: "besar.txt", UserID: ownerID}))
+		require.NoError(t, repo.PutPart(ctx, model.S3MultipartPart{UploadID: uploadID, PartNumber: 2, ETag: "p2", SizeBytes: 5}))
+		require.NoError(t, repo.PutPart(ctx, model.S3MultipartPart{UploadID: uploadID, PartNumber: 1, ETag: "p1", SizeBytes: 5}))
+		require.NoError(t, repo.PutPart(ctx, model.S3MultipartPart{UploadID: uploadID, PartNumber: 1, ETag: "p1b", SizeBytes: 6}))
+
+		upload, err := repo.GetUpload(ctx, uploadID)
+		require.NoError(t, err)
+		assert.Empty(t, upload.Tags)
+
+		parts, err := repo.ListParts(ctx, uploadID)
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		assert.Equal(t, "p1b", parts[0].ETag)
+
+		require.NoError(t, repo.DeleteUpload(ctx, uploadID))
+		parts, err = repo.ListParts(ctx, uploadID)
+		require.NoError(t, err)
+		assert.Empty(t, parts)
+		_, err = repo.GetUpload(ctx, uploadID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestMemoryDAVCollectionRepository(t *testing.T) {
+	repo := NewMemoryDAVCollectionRepository()
+	ctx := context.Background()
+	for _, path := range []string{"proyek", "proyek/a", "proyek/a/b", "proyek-lain"} {
+		require.NoError(t, repo.CreateCollection(ctx, "user-1", path))
+	}
+	require.NoError(t, repo.CreateCollection(ctx, "user-2", "proyek"))
+
+	paths, err := repo.ListCollections(ctx, "user-1", "proyek")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"proyek", "proyek/a", "proyek/a/b"}, paths)
+
+	require.NoError(t, repo.CreateCollection(ctx, "user-1", "baru/a"))
+	require.NoError(t, repo.RenameCollections(ctx, "user-1", "proyek/a", "baru/a"))
+	paths, err = repo.ListCollections(ctx, "user-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baru/a", "baru/a/b", "proyek", "proyek-lain"}, paths)
+
+	require.NoError(t, repo.DeleteCollections(ctx, "user-1", "proyek"))
+	paths, err = repo.ListCollections(ctx, "user-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baru/a", "baru/a/b", "proyek-lain"}, paths)
+
+	paths, err = repo.ListCollections(ctx, "user-2", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"proyek"}, paths, "Koleksi pengguna lain tidak terpengaruh")
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RenditionRepository mencatat hasil render gambar yang disimpan sebagai
+// cache di storage. Path yang tercatat dikembalikan lewat
+// model.FileMetadata.RenditionPaths agar dapat dihapus bersama file.
+type RenditionRepository interface {
+	// RecordRendition menambahkan path ke daftar rendition file jika belum
+	// ada. Tidak menaikkan Version karena metadata file tidak berubah.
+	RecordRendition(ctx context.Context, id, path string) error
+}
+
+type postgresRenditionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresRenditionRepository(db *pgxpool.Pool) RenditionRepository {
+	return &postgresRenditionRepository{db: db}
+}
+
+func (r *postgresRenditionRepository) RecordRendition(ctx context.Context, id, path string) error {
+	tag, err := r.db.Exec(ctx, `UPDATE files
+            SET rendition_paths = CASE WHEN $2 = ANY(rendition_paths) THEN rendition_paths
+                                       ELSE array_append(rendition_paths, $2) END
+            WHERE id = $1 AND deleted_at IS NULL;`, id, path)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
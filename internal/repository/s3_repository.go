@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// S3Repository menyimpan state gateway S3: credential, pemetaan bucket/key ke
+// file, dan multipart upload yang sedang berjalan.
+type S3Repository interface {
+	GetCredential(ctx context.Context, accessKeyID string) (*model.S3Credential, error)
+	ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error)
+	CreateCredential(ctx context.Context, credential *model.S3Credential) error
+	DeleteCredential(ctx context.Context, userID, accessKeyID string) error
+
+	GetObject(ctx context.Context, bucket, key string) (*model.S3Object, error)
+	// PutObject membuat atau mengganti pemetaan key. ID file yang sebelumnya
+	// dipetakan ke key tersebut dikembalikan (kosong jika key baru).
+	PutObject(ctx context.Context, object model.S3Object) (string, error)
+	// ListObjects mengembalikan objek dengan prefix tertentu yang key-nya lebih
+	// besar dari startAfter, terurut berdasarkan byte key.
+	ListObjects(ctx context.Context, bucket, prefix, startAfter string, limit int) ([]model.S3Object, error)
+
+	CreateUpload(ctx context.Context, upload model.S3MultipartUpload) error
+	GetUpload(ctx context.Context, uploadID string) (*model.S3MultipartUpload, error)
+	PutPart(ctx context.Context, part model.S3MultipartPart) error
+	ListParts(ctx context.Context, uploadID string) ([]model.S3MultipartPart, error)
+	DeleteUpload(ctx context.Context, uploadID string) error
+}
+
+type postgresS3Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresS3Repository(db *pgxpool.Pool) S3Repository {
+	return &postgresS3Repository{db: db}
+}
+
+func (r *postgresS3Repository) GetCredential(ctx context.Context, accessKeyID string) (*model.S3Credential, error) {
+	var credential model.S3Credential
+	err := r.db.QueryRow(ctx, `SELECT access_key_id, user_id, role_name, token_id, expires_at, created_at FROM s3_credentials WHERE access_key_id = $1;`, accessKeyID).
+		Scan(&credential.AccessKeyID, &credential.UserID, &credential.Role, &credential.TokenID, &credential.ExpiresAt, &credential.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *postgresS3Repository) ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error) {
+	rows, err := r.db.Query(ctx, `SELECT access_key_id, user_id, role_name, token_id, expires_at, created_at FROM s3_credentials WHERE user_id = $1 ORDER BY created_at;`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []model.S3Credential{}
+	for rows.Next() {
+		var credential model.S3Credential
+		if err := rows.Scan(&credential.AccessKeyID, &credential.UserID, &credential.Role, &credential.TokenID, &credential.ExpiresAt, &credential.CreatedAt); err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials, rows.Err()
+}
+
+func (r *postgresS3Repository) CreateCredential(ctx context.Context, credential *model.S3Credential) error {
+	return r.db.QueryRow(ctx, `INSERT INTO s3_credentials (access_key_id, user_id, role_name, token_id, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING created_at;`,
+		credential.AccessKeyID, credential.UserID, credential.Role, credential.TokenID, credential.ExpiresAt).Scan(&credential.CreatedAt)
+}
+
+func (r *postgresS3Repository) DeleteCredential(ctx context.Context, userID, accessKeyID string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM s3_credentials WHERE user_id = $1 AND access_key_id = $2;`, userID, accessKeyID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresS3Repository) GetObject(ctx context.Context, bucket, key string) (*model.S3Object, error) {
+	object := model.S3Object{Bucket: bucket, Key: key}
+	err := r.db.QueryRow(ctx, `SELECT file_id, etag FROM s3_objects WHERE bucket = $1 AND object_key = $2;`, bucket, key).
+		Scan(&object.FileID, &object.ETag)
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (r *postgresS3Repository) PutObject(ctx context.Context, object model.S3Object) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Gagal melakukan rollback pada transaksi PutObject S3")
+		}
+	}()
+
+	var previous string
+	err = tx.QueryRow(ctx, `SELECT file_id FROM s3_objects WHERE bucket = $1 AND object_key = $2 FOR UPDATE;`, object.Bucket, object.Key).Scan(&previous)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO s3_objects (bucket, object_key, file_id, etag) VALUES ($1, $2, $3, $4)
+            ON CONFLICT (bucket, object_key) DO UPDATE SET file_id = EXCLUDED.file_id, etag = EXCLUDED.etag;`,
+		object.Bucket, object.Key, object.FileID, object.ETag)
+	if err != nil {
+		return "", err
+	}
+	return previous, tx.Commit(ctx)
+}
+
+func (r *postgresS3Repository) ListObjects(ctx context.Context, bucket, prefix, startAfter string, limit int) ([]model.S3Object, error) {
+	rows, err := r.db.Query(ctx, `SELECT object_key, file_id, etag FROM s3_objects
+            WHERE bucket = $1 AND starts_with(object_key, $2) AND object_key > $3
+            ORDER BY object_key
+            LIMIT $4;`, bucket, prefix, startAfter, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []model.S3Object
+	for rows.Next() {
+		object := model.S3Object{Bucket: bucket}
+		if err := rows.Scan(&object.Key, &object.FileID, &object.ETag); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
+
+func (r *postgresS3Repository) CreateUpload(ctx context.Context, upload model.S3MultipartUpload) error {
+	tags := upload.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO s3_multipart_uploads (upload_id, bucket, object_key, user_id, tags) VALUES ($1, $2, $3, $4, $5);`,
+		upload.UploadID, upload.Bucket, upload.Key, upload.UserID, tags)
+	return err
+}
+
+func (r *postgresS3Repository) GetUpload(ctx context.Context, uploadID string) (*model.S3MultipartUpload, error) {
+	upload := model.S3MultipartUpload{UploadID: uploadID}
+	err := r.db.QueryRow(ctx, `SELECT bucket, object_key, user_id, tags, created_at FROM s3_multipart_uploads WHERE upload_id = $1;`, uploadID).
+		Scan(&upload.Bucket, &upload.Key, &upload.UserID, &upload.Tags, &upload.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *postgresS3Repository) PutPart(ctx context.Context, part model.S3MultipartPart) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO s3_multipart_parts (upload_id, part_number, etag, size_bytes) VALUES ($1, $2, $3, $4)
+            ON CONFLICT (upload_id, part_number) DO UPDATE SET etag = EXCLUDED.etag, size_bytes = EXCLUDED.size_bytes;`,
+		part.UploadID, part.PartNumber, part.ETag, part.SizeBytes)
+	return err
+}
+
+func (r *postgresS3Repository) ListParts(ctx context.Context, uploadID string) ([]model.S3MultipartPart, error) {
+	rows, err := r.db.Query(ctx, `SELECT part_number, etag, size_bytes FROM s3_multipart_parts WHERE upload_id = $1 ORDER BY part_number;`, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parts []model.S3MultipartPart
+	for rows.Next() {
+		part := model.S3MultipartPart{UploadID: uploadID}
+		if err := rows.Scan(&part.PartNumber, &part.ETag, &part.SizeBytes); err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, rows.Err()
+}
+
+func (r *postgresS3Repository) DeleteUpload(ctx context.Context, uploadID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM s3_multipart_uploads WHERE upload_id = $1;`, uploadID)
+	return err
+}
@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresS3Repository_Integration(t *testing.T) {
+	dbpool, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := context.Background()
+	files := NewPostgresFileRepository(dbpool)
+	repo := NewPostgresS3Repository(dbpool)
+
+	ownerID := uuid.New().String()
+	newFile := func() string {
+		metadata := &model.FileMetadata{
+			ID:           uuid.New().String(),
+			OriginalName: "laporan.txt",
+			StoragePath:  "laporan.txt",
+			MimeType:     "text/plain",
+			SizeBytes:    10,
+			OwnerUserID:  &ownerID,
+		}
+		require.NoError(t, files.Create(ctx, metadata, nil))
+		return metadata.ID
+	}
+
+	t.Run("Credentials", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		credential := &model.S3Credential{AccessKeyID: "PRSMINTEGRATION00001", UserID: ownerID, Role: "finance", TokenID: "token-1", ExpiresAt: expiresAt}
+		require.NoError(t, repo.CreateCredential(ctx, credential))
+		assert.False(t, credential.CreatedAt.IsZero())
+
+		found, err := repo.GetCredential(ctx, credential.AccessKeyID)
+		require.NoError(t, err)
+		assert.Equal(t, "finance", found.Role)
+		assert.Equal(t, "token-1", found.TokenID)
+		assert.True(t, expiresAt.Equal(found.ExpiresAt))
+
+		assert.ErrorIs(t, repo.DeleteCredential(ctx, "user-lain", credential.AccessKeyID), ErrNotFound)
+		require.NoError(t, repo.DeleteCredential(ctx, ownerID, credential.AccessKeyID))
+		_, err = repo.GetCredential(ctx, credential.AccessKeyID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Objects", func(t *testing.T) {
+		first, second := newFile(), newFile()
+		previous, err := repo.PutObject(ctx, model.S3Object{Bucket: "laporan", Key: "a/b.txt", FileID: first, ETag: "e1"})
+		require.NoError(t, err)
+		assert.Empty(t, previous)
+
+		previous, err = repo.PutObject(ctx, model.S3Object{Bucket: "laporan", Key: "a/b.txt", FileID: second, ETag: "e2"})
+		require.NoError(t, err)
+		assert.Equal(t, first, previous)
+
+		_, err = repo.PutObject(ctx, model.S3Object{Bucket: "laporan", Key: "a/c.txt", FileID: newFile(), ETag: "e3"})
+		require.NoError(t, err)
+
+		objects, err := repo.ListObjects(ctx, "laporan", "a/", "a/b.txt", 10)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		assert.Equal(t, "a/c.txt", objects[0].Key)
+
+		// Menghapus file ikut menghapus pemetaan key-nya.
+		require.NoError(t, files.DeleteByID(ctx, second))
+		_, err = repo.GetObject(ctx, "laporan", "a/b.txt")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Multipart uploads", func(t *testing.T) {
+		uploadID := uuid.New().String()
+		require.NoError(t, repo.CreateUpload(ctx, model.S3MultipartUpload{UploadID: uploadID, Bucket: "laporan", Key: "besar.txt", UserID: ownerID}))
+		require.NoError(t, repo.PutPart(ctx, model.S3MultipartPart{UploadID: uploadID, PartNumber: 2, ETag: "p2", SizeBytes: 5}))
+		require.NoError(t, repo.PutPart(ctx, model.S3MultipartPart{UploadID: uploadID, PartNumber: 1, ETag: "p1", SizeBytes: 5}))
+		require.NoError(t, repo.PutPart(ctx, model.S3MultipartPart{UploadID: uploadID, PartNumber: 1, ETag: "p1b", SizeBytes: 6}))
+
+		upload, err := repo.GetUpload(ctx, uploadID)
+		require.NoError(t, err)
+		assert.Empty(t, upload.Tags)
+
+		parts, err := repo.ListParts(ctx, uploadID)
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		assert.Equal(t, "p1b", parts[0].ETag)
+
+		require.NoError(t, repo.DeleteUpload(ctx, uploadID))
+		parts, err = repo.ListParts(ctx, uploadID)
+		require.NoError(t, err)
+		assert.Empty(t, parts)
+	})
+}
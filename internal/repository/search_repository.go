@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SearchRepository menyimpan teks isi file dan menjalankan pencarian
+// full-text atas nama dan teks tersebut.
+type SearchRepository interface {
+	// Search mengembalikan file aktif yang cocok dengan query, terurut dari
+	// peringkat tertinggi. Hasil belum difilter berdasarkan hak akses.
+	Search(ctx context.Context, query SearchQuery) ([]SearchHit, error)
+	// ListForIndex mengembalikan hingga limit file aktif dengan ID setelah
+	// afterID, terurut berdasarkan ID. Jika onlyMissing, hanya file yang
+	// belum pernah diindeks.
+	ListForIndex(ctx context.Context, afterID string, onlyMissing bool, limit int) ([]*model.FileMetadata, error)
+	// RecordContent menyimpan teks hasil ekstraksi dan waktu pengindeksan.
+	// Tidak menaikkan Version karena metadata file tidak berubah.
+	RecordContent(ctx context.Context, id, text string, at time.Time) error
+}
+
+// SearchQuery adalah kata kunci pencarian dalam sintaks websearch Postgres
+// (kata, "frasa", OR, -pengecualian) beserta paginasinya.
+type SearchQuery struct {
+	Text   string
+	Limit  int
+	Offset int
+}
+
+// SearchHit adalah satu file yang cocok dengan pencarian. Snippet adalah
+// potongan teks (atau nama file) yang sudah di-escape untuk HTML, dengan kata
+// yang cocok dibungkus <mark>.
+type SearchHit struct {
+	ID      string
+	Rank    float64
+	Snippet string
+}
+
+// Penanda sementara kata yang cocok pada snippet dari Postgres. Karakter
+// Private Use dipakai agar penanda tetap dapat dibedakan setelah teks
+// di-escape.
+const (
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+)
+
+// highlightSnippet meng-escape snippet mentah lalu mengganti penanda kata
+// yang cocok dengan <mark>.
+func highlightSnippet(raw string) string {
+	escaped := html.EscapeString(raw)
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(escaped)
+}
+
+type postgresSearchRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresSearchRepository(db *pgxpool.Pool) SearchRepository {
+	return &postgresSearchRepository{db: db}
+}
+
+func (r *postgresSearchRepository) Search(ctx context.Context, query SearchQuery) ([]SearchHit, error) {
+	// Query dari kedua konfigurasi digabung agar kata dasar bahasa Indonesia
+	// maupun Inggris cocok dengan search_vector.
+	rows, err := r.db.Query(ctx, `WITH q AS (
+                SELECT websearch_to_tsquery('indonesian', $1) || websearch_to_tsquery('english', $1) AS query
+            )
+            SELECT f.id, ts_rank_cd(f.search_vector, q.query) AS rank,
+                   ts_headline('indonesian', COALESCE(NULLIF(f.content_text, ''), f.original_name), q.query,
+                               'StartSel=`+highlightStart+`, StopSel=`+highlightStop+`, MaxWords=30, MinWords=10, MaxFragments=2')
+            FROM files f, q
+            WHERE f.deleted_at IS NULL AND f.search_vector @@ q.query
+            ORDER BY rank DESC, f.created_at DESC
+            LIMIT $2 OFFSET $3;`, query.Text, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []SearchHit
+	for rows.Next() {
+		var hit SearchHit
+		if err := rows.Scan(&hit.ID, &hit.Rank, &hit.Snippet); err != nil {
+			return nil, err
+		}
+		hit.Snippet = highlightSnippet(hit.Snippet)
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+func (r *postgresSearchRepository) ListForIndex(ctx context.Context, afterID string, onlyMissing bool, limit int) ([]*model.FileMetadata, error) {
+	var after *string
+	if afterID != "" {
+		after = &afterID
+	}
+	rows, err := r.db.Query(ctx, `SELECT id, original_name, storage_path, mime_type, size_bytes
+            FROM files
+            WHERE deleted_at IS NULL AND ($1::uuid IS NULL OR id > $1::uuid)
+              AND (NOT $2::boolean OR content_indexed_at IS NULL)
+            ORDER BY id
+            LIMIT $3;`, after, onlyMissing, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*model.FileMetadata
+	for rows.Next() {
+		var metadata model.FileMetadata
+		if err := rows.Scan(&metadata.ID, &metadata.OriginalName, &metadata.StoragePath, &metadata.MimeType, &metadata.SizeBytes); err != nil {
+			return nil, err
+		}
+		files = append(files, &metadata)
+	}
+	return files, rows.Err()
+}
+
+func (r *postgresSearchRepository) RecordContent(ctx context.Context, id, text string, at time.Time) error {
+	tag, err := r.db.Exec(ctx, `UPDATE files
+            SET content_text = NULLIF($2, ''), content_indexed_at = $3
+            WHERE id = $1 AND deleted_at IS NULL;`, id, text, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
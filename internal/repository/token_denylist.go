@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenDenylist memeriksa apakah sebuah token JWT sudah dicabut. Layanan auth
+// mencabut token (misalnya saat peran pengguna berubah) dengan menulis kunci
+// Redis bernama jti token tersebut; aturan yang sama dipakai auth.JWTMiddleware.
+type TokenDenylist interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type redisTokenDenylist struct {
+	client *redis.Client
+}
+
+func NewRedisTokenDenylist(client *redis.Client) TokenDenylist {
+	return &redisTokenDenylist{client: client}
+}
+
+func (d *redisTokenDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	err := d.client.Get(ctx, jti).Err()
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, redis.Nil):
+		return false, nil
+	default:
+		return false, fmt.Errorf("gagal memeriksa denylist token: %w", err)
+	}
+}
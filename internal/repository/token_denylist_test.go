@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTokenDenylist(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+	require.NoError(t, mr.Set("jti-dicabut", "revoked"))
+
+	denylist := NewRedisTokenDenylist(redisClient)
+
+	testCases := []struct {
+		name    string
+		jti     string
+		revoked bool
+	}{
+		{name: "Token dicabut", jti: "jti-dicabut", revoked: true},
+		{name: "Token aktif", jti: "jti-aktif", revoked: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			revoked, err := denylist.IsRevoked(ctx, tc.jti)
+			require.NoError(t, err)
+			assert.Equal(t, tc.revoked, revoked)
+		})
+	}
+
+	t.Run("Redis tidak tersedia", func(t *testing.T) {
+		mr.Close()
+		_, err := denylist.IsRevoked(ctx, "jti-aktif")
+		assert.Error(t, err)
+	})
+}
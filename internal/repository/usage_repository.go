@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UsageRepository menghitung pemakaian storage oleh file yang tersimpan,
+// untuk metrik Prometheus.
+type UsageRepository interface {
+	// StorageUsage mengembalikan jumlah dan total ukuran file yang belum
+	// dihapus. Rendition tidak dihitung.
+	StorageUsage(ctx context.Context) (StorageUsage, error)
+}
+
+// StorageUsage adalah pemakaian storage seluruh file.
+type StorageUsage struct {
+	Files int64
+	Bytes int64
+}
+
+type postgresUsageRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresUsageRepository(db *pgxpool.Pool) UsageRepository {
+	return &postgresUsageRepository{db: db}
+}
+
+func (r *postgresUsageRepository) StorageUsage(ctx context.Context) (StorageUsage, error) {
+	var usage StorageUsage
+	err := r.db.QueryRow(ctx, `SELECT count(*), COALESCE(sum(size_bytes), 0)
+            FROM files WHERE deleted_at IS NULL;`).Scan(&usage.Files, &usage.Bytes)
+	return usage, err
+}
@@ -0,0 +1,83 @@
+package s3gateway
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// s3Error adalah error dengan kode dan status HTTP sesuai API S3.
+type s3Error struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e *s3Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func (e *s3Error) withMessage(message string) *s3Error {
+	clone := *e
+	clone.Message = message
+	return &clone
+}
+
+var (
+	errAccessDenied                      = &s3Error{"AccessDenied", "Access Denied", http.StatusForbidden}
+	errAuthorizationHeaderMalformed      = &s3Error{"AuthorizationHeaderMalformed", "The authorization header is malformed", http.StatusBadRequest}
+	errAuthorizationQueryParametersError = &s3Error{"AuthorizationQueryParametersError", "Query-string authentication parameters are invalid", http.StatusBadRequest}
+	errBadDigest                         = &s3Error{"BadDigest", "The Content-MD5 you specified did not match what we received", http.StatusBadRequest}
+	errBucketNotEmpty                    = &s3Error{"BucketNotEmpty", "The bucket you tried to delete is not empty", http.StatusConflict}
+	errEntityTooLarge                    = &s3Error{"EntityTooLarge", "Your proposed upload exceeds the maximum allowed object size", http.StatusBadRequest}
+	errInternal                          = &s3Error{"InternalError", "We encountered an internal error. Please try again.", http.StatusInternalServerError}
+	errInvalidAccessKeyID                = &s3Error{"InvalidAccessKeyId", "The access key ID you provided does not exist in our records", http.StatusForbidden}
+	errInvalidArgument                   = &s3Error{"InvalidArgument", "Invalid argument", http.StatusBadRequest}
+	errInvalidBucketName                 = &s3Error{"InvalidBucketName", "The specified bucket is not valid", http.StatusBadRequest}
+	errInvalidPart                       = &s3Error{"InvalidPart", "One or more of the specified parts could not be found", http.StatusBadRequest}
+	errInvalidPartOrder                  = &s3Error{"InvalidPartOrder", "The list of parts was not in ascending order", http.StatusBadRequest}
+	errInvalidRange                      = &s3Error{"InvalidRange", "The requested range is not satisfiable", http.StatusRequestedRangeNotSatisfiable}
+	errMalformedXML                      = &s3Error{"MalformedXML", "The XML you provided was not well-formed", http.StatusBadRequest}
+	errMethodNotAllowed                  = &s3Error{"MethodNotAllowed", "The specified method is not allowed against this resource", http.StatusMethodNotAllowed}
+	errNoSuchKey                         = &s3Error{"NoSuchKey", "The specified key does not exist", http.StatusNotFound}
+	errNoSuchUpload                      = &s3Error{"NoSuchUpload", "The specified multipart upload does not exist", http.StatusNotFound}
+	errNotImplemented                    = &s3Error{"NotImplemented", "A header or operation you provided is not implemented", http.StatusNotImplemented}
+	errRequestTimeTooSkewed              = &s3Error{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large", http.StatusForbidden}
+	errSignatureDoesNotMatch             = &s3Error{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided", http.StatusForbidden}
+	errXAmzContentSHA256Mismatch         = &s3Error{"XAmzContentSHA256Mismatch", "The provided x-amz-content-sha256 header does not match what was computed", http.StatusBadRequest}
+)
+
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, s3err *s3Error) {
+	if s3err.Status >= http.StatusInternalServerError {
+		log.Error().Str("code", s3err.Code).Str("path", r.URL.Path).Msg("Request gateway S3 gagal")
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(s3err.Status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	writeXMLBody(w, errorResponse{Code: s3err.Code, Message: s3err.Message, Resource: r.URL.Path})
+}
+
+func writeXML(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	writeXMLBody(w, body)
+}
+
+func writeXMLBody(w http.ResponseWriter, body any) {
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(body); err != nil {
+		log.Warn().Err(err).Msg("Gagal menulis respons XML gateway S3")
+	}
+}
@@ -0,0 +1,450 @@
+// Package s3gateway menyajikan subset API S3 (path-style) di atas FileService
+// sehingga alat pihak ketiga yang hanya mendukung S3 dapat memakai Prism.
+// Setiap request diautentikasi dengan SigV4 memakai access key milik pengguna
+// Prism, lalu diteruskan ke FileService agar validasi, kebijakan otorisasi dan
+// audit tetap berlaku.
+package s3gateway
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// TagsHeader berisi tag Prism (dipisahkan koma) untuk PutObject dan
+// CreateMultipartUpload, dan dikembalikan pada GetObject/HeadObject.
+const TagsHeader = "X-Amz-Meta-Prism-Tags"
+
+const fileIDHeader = "X-Amz-Meta-Prism-File-Id"
+
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// Gateway adalah http.Handler untuk API S3.
+type Gateway struct {
+	files         service.FileService
+	objects       repository.S3Repository
+	credentials   service.S3CredentialService
+	storage       storage.Storage
+	maxObjectSize atomic.Int64
+	now           func() time.Time
+}
+
+// NewGateway membuat Gateway. storage dipakai untuk menampung bagian multipart
+// upload sebelum digabung; maxObjectSize membatasi ukuran tiap bagian dan total
+// objek multipart.
+func NewGateway(files service.FileService, objects repository.S3Repository, credentials service.S3CredentialService, storage storage.Storage, maxObjectSize int64) *Gateway {
+	g := &Gateway{
+		files:       files,
+		objects:     objects,
+		credentials: credentials,
+		storage:     storage,
+		now:         time.Now,
+	}
+	g.maxObjectSize.Store(maxObjectSize)
+	return g
+}
+
+// SetMaxObjectSize mengganti batas ukuran bagian dan objek multipart untuk
+// permintaan berikutnya, mis. saat max_size_mb berubah di Consul.
+func (g *Gateway) SetMaxObjectSize(maxObjectSize int64) {
+	g.maxObjectSize.Store(maxObjectSize)
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	credential, s3err := g.authenticate(r)
+	if s3err != nil {
+		writeError(w, r, s3err)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket == "" {
+		writeError(w, r, errNotImplemented.withMessage("ListBuckets tidak didukung; bucket dibuat otomatis"))
+		return
+	}
+	if !bucketNamePattern.MatchString(bucket) {
+		writeError(w, r, errInvalidBucketName)
+		return
+	}
+
+	req := &request{
+		w:          w,
+		r:          r,
+		bucket:     bucket,
+		key:        key,
+		credential: credential,
+		claims:     jwt.MapClaims{"sub": credential.UserID, "role": credential.Role},
+	}
+	query := r.URL.Query()
+
+	var err *s3Error
+	if key == "" {
+		err = g.serveBucket(req, query)
+	} else {
+		err = g.serveObject(req, query)
+	}
+	if err != nil {
+		writeError(w, r, err)
+	}
+}
+
+// request mengumpulkan data yang dibutuhkan setiap operasi.
+type request struct {
+	w          http.ResponseWriter
+	r          *http.Request
+	bucket     string
+	key        string
+	credential *model.S3Credential
+	claims     jwt.MapClaims
+}
+
+// uploadContext menandai konteks permintaan dengan peran pemanggil agar
+// kebijakan upload per peran berlaku sama seperti di REST, gRPC, dan WebDAV.
+func (req *request) uploadContext() context.Context {
+	role, _ := req.claims["role"].(string)
+	return uploadpolicy.WithRequest(req.r.Context(), uploadpolicy.Request{Role: role})
+}
+
+func (g *Gateway) serveBucket(req *request, query map[string][]string) *s3Error {
+	_, hasLocation := query["location"]
+	switch {
+	case req.r.Method == http.MethodGet && hasLocation:
+		writeXML(req.w, http.StatusOK, locationConstraint{})
+		return nil
+	case req.r.Method == http.MethodGet && len(query["list-type"]) > 0 && query["list-type"][0] == "2":
+		return g.listObjectsV2(req)
+	case req.r.Method == http.MethodGet:
+		return errNotImplemented.withMessage("hanya ListObjectsV2 (list-type=2) yang didukung")
+	case req.r.Method == http.MethodHead, req.r.Method == http.MethodPut:
+		// Bucket bersifat implisit: selalu ada dan "dibuat" tanpa efek.
+		req.w.WriteHeader(http.StatusOK)
+		return nil
+	case req.r.Method == http.MethodDelete:
+		return g.deleteBucket(req)
+	default:
+		return errMethodNotAllowed
+	}
+}
+
+func (g *Gateway) serveObject(req *request, query map[string][]string) *s3Error {
+	_, hasUploads := query["uploads"]
+	uploadID := ""
+	if values := query["uploadId"]; len(values) > 0 {
+		uploadID = values[0]
+	}
+
+	switch req.r.Method {
+	case http.MethodPut:
+		if req.r.Header.Get("X-Amz-Copy-Source") != "" {
+			return errNotImplemented.withMessage("CopyObject tidak didukung")
+		}
+		if uploadID != "" {
+			return g.uploadPart(req, uploadID)
+		}
+		return g.putObject(req)
+	case http.MethodGet, http.MethodHead:
+		if uploadID != "" {
+			return errNotImplemented.withMessage("ListParts tidak didukung")
+		}
+		return g.getObject(req)
+	case http.MethodDelete:
+		if uploadID != "" {
+			return g.abortMultipartUpload(req, uploadID)
+		}
+		return g.deleteObject(req)
+	case http.MethodPost:
+		if hasUploads {
+			return g.createMultipartUpload(req)
+		}
+		if uploadID != "" {
+			return g.completeMultipartUpload(req, uploadID)
+		}
+		return errNotImplemented
+	default:
+		return errMethodNotAllowed
+	}
+}
+
+func (g *Gateway) putObject(req *request) *s3Error {
+	if s3err := g.checkReplaceable(req); s3err != nil {
+		return s3err
+	}
+
+	digest := md5.New()
+	metadata, err := g.files.UploadStream(req.uploadContext(), req.credential.UserID, objectFileName(req.key), io.TeeReader(req.r.Body, digest), parseTags(req.r.Header.Get(TagsHeader)))
+	if err != nil {
+		return toS3Error(err)
+	}
+	sum := digest.Sum(nil)
+	if contentMD5 := req.r.Header.Get("Content-Md5"); contentMD5 != "" && contentMD5 != base64.StdEncoding.EncodeToString(sum) {
+		g.discardFile(req, metadata.ID)
+		return errBadDigest
+	}
+
+	etag := hex.EncodeToString(sum)
+	if s3err := g.publish(req, metadata.ID, etag); s3err != nil {
+		return s3err
+	}
+	req.w.Header().Set("ETag", quoteETag(etag))
+	req.w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (g *Gateway) getObject(req *request) *s3Error {
+	object, metadata, s3err := g.resolveObject(req)
+	if s3err != nil {
+		return s3err
+	}
+
+	offset, length := int64(0), metadata.SizeBytes
+	status := http.StatusOK
+	if rangeHeader := req.r.Header.Get("Range"); rangeHeader != "" {
+		var ok bool
+		offset, length, ok = parseRange(rangeHeader, metadata.SizeBytes)
+		if !ok {
+			req.w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", metadata.SizeBytes))
+			return errInvalidRange
+		}
+		status = http.StatusPartialContent
+		req.w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, metadata.SizeBytes))
+	}
+
+	header := req.w.Header()
+	header.Set("ETag", quoteETag(object.ETag))
+	header.Set("Last-Modified", metadata.CreatedAt.UTC().Format(http.TimeFormat))
+	header.Set("Content-Type", metadata.MimeType)
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+	header.Set("Accept-Ranges", "bytes")
+	header.Set(fileIDHeader, metadata.ID)
+	if len(metadata.Tags) > 0 {
+		header.Set(TagsHeader, strings.Join(metadata.Tags, ","))
+	}
+
+	if req.r.Method == http.MethodHead {
+		req.w.WriteHeader(status)
+		return nil
+	}
+
+	reader, err := g.files.GetFileReader(req.r.Context(), metadata)
+	if errors.Is(err, storage.ErrNotFound) {
+		log.Error().Err(err).Str("file_id", metadata.ID).Str("storage_path", metadata.StoragePath).Msg("File ada di metadata tapi tidak ditemukan di storage")
+		return errNoSuchKey
+	}
+	if err != nil {
+		log.Error().Err(err).Str("file_id", metadata.ID).Str("storage_path", metadata.StoragePath).Msg("Gagal membuka file dari storage")
+		return errInternal
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Warn().Err(err).Str("file_id", metadata.ID).Msg("Gagal menutup file reader setelah GetObject")
+		}
+	}()
+	if err := storage.Skip(reader, offset); err != nil {
+		log.Error().Err(err).Str("file_id", metadata.ID).Msg("Gagal melompati awal rentang GetObject")
+		return errInternal
+	}
+
+	req.w.WriteHeader(status)
+	if _, err := io.CopyN(req.w, reader, length); err != nil {
+		log.Error().Err(err).Str("file_id", metadata.ID).Msg("Gagal mengirim objek ke klien S3")
+	}
+	return nil
+}
+
+func (g *Gateway) deleteObject(req *request) *s3Error {
+	object, err := g.objects.GetObject(req.r.Context(), req.bucket, req.key)
+	if errors.Is(err, repository.ErrNotFound) {
+		// DeleteObject bersifat idempoten di S3.
+		req.w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	if err != nil {
+		return toS3Error(err)
+	}
+
+	// Pemetaan key ikut terhapus lewat ON DELETE CASCADE.
+	if err := g.files.DeleteFile(req.r.Context(), object.FileID, req.claims); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return toS3Error(err)
+	}
+	req.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (g *Gateway) deleteBucket(req *request) *s3Error {
+	objects, err := g.objects.ListObjects(req.r.Context(), req.bucket, "", "", 1)
+	if err != nil {
+		return toS3Error(err)
+	}
+	if len(objects) > 0 {
+		return errBucketNotEmpty
+	}
+	req.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// resolveObject mencari file di balik key dan memeriksa akses baca lewat
+// FileService.GetFileMetadata.
+func (g *Gateway) resolveObject(req *request) (*model.S3Object, *model.FileMetadata, *s3Error) {
+	object, err := g.objects.GetObject(req.r.Context(), req.bucket, req.key)
+	if err != nil {
+		return nil, nil, toS3Error(err)
+	}
+	metadata, err := g.files.GetFileMetadata(req.r.Context(), object.FileID, req.claims)
+	if err != nil {
+		return nil, nil, toS3Error(err)
+	}
+	return object, metadata, nil
+}
+
+// checkReplaceable memastikan pemanggil boleh menimpa key yang sudah ada,
+// dengan aturan yang sama seperti menghapus file: pemilik atau admin.
+func (g *Gateway) checkReplaceable(req *request) *s3Error {
+	_, metadata, s3err := g.resolveObject(req)
+	if s3err == errNoSuchKey {
+		return nil
+	}
+	if s3err != nil {
+		return s3err
+	}
+	isOwner := metadata.OwnerUserID != nil && *metadata.OwnerUserID == req.credential.UserID
+	if !isOwner && req.credential.Role != "admin" {
+		return errAccessDenied
+	}
+	return nil
+}
+
+// publish memetakan key ke file baru, lalu menghapus file lama yang digantikan.
+func (g *Gateway) publish(req *request, fileID, etag string) *s3Error {
+	previous, err := g.objects.PutObject(req.r.Context(), model.S3Object{Bucket: req.bucket, Key: req.key, FileID: fileID, ETag: etag})
+	if err != nil {
+		g.discardFile(req, fileID)
+		return toS3Error(err)
+	}
+	if previous != "" && previous != fileID {
+		if err := g.files.DeleteFile(req.r.Context(), previous, req.claims); err != nil {
+			log.Warn().Err(err).Str("file_id", previous).Str("bucket", req.bucket).Str("key", req.key).Msg("Gagal menghapus file lama setelah objek S3 ditimpa")
+		}
+	}
+	return nil
+}
+
+// discardFile menghapus file yang sudah tersimpan tetapi tidak jadi dipublikasikan.
+func (g *Gateway) discardFile(req *request, fileID string) {
+	if err := g.files.DeleteFile(req.r.Context(), fileID, req.claims); err != nil {
+		log.Warn().Err(err).Str("file_id", fileID).Msg("Gagal menghapus file S3 yang dibatalkan")
+	}
+}
+
+// toS3Error memetakan error service/repository ke error S3.
+func toS3Error(err error) *s3Error {
+	var s3err *s3Error
+	if validationErr, ok := validation.AsError(err); ok {
+		if validationErr.Code == validation.CodeFileTooLarge {
+			return errEntityTooLarge
+		}
+		return errInvalidArgument.withMessage(err.Error())
+	}
+	switch {
+	case errors.As(err, &s3err):
+		return s3err
+	case errors.Is(err, errContentSHA256Mismatch):
+		return errXAmzContentSHA256Mismatch
+	case errors.Is(err, service.ErrAccessDenied):
+		return errAccessDenied
+	case errors.Is(err, repository.ErrNotFound):
+		return errNoSuchKey
+	case errors.Is(err, service.ErrInvalidMetadata):
+		return errInvalidArgument.withMessage(err.Error())
+	default:
+		log.Error().Err(err).Msg("Operasi gateway S3 gagal")
+		return errInternal
+	}
+}
+
+// objectFileName mengambil komponen terakhir key sebagai nama file asli.
+func objectFileName(key string) string {
+	name := path.Base(key)
+	if name == "." || name == "/" {
+		return key
+	}
+	return name
+}
+
+func parseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func quoteETag(etag string) string {
+	return `"` + etag + `"`
+}
+
+// parseRange mendukung satu rentang "bytes=a-b", "bytes=a-" atau "bytes=-n".
+func parseRange(header string, size int64) (offset, length int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	startStr, endStr, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	if startStr == "" {
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		suffix = min(suffix, size)
+		return size - suffix, suffix, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end - start + 1, true
+}
+
+// md5Hex mengembalikan digest MD5 dalam heksadesimal.
+func md5Hex(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type locationConstraint struct {
+	XMLName struct{} `xml:"LocationConstraint"`
+}
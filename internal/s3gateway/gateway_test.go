@@ -0,0 +1,701 @@
+package s3gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBucket = "laporan"
+
+// memFileRepository hanya mengimplementasikan metode yang dipakai FileService
+// pada alur gateway; metode lain memanggil interface nil dan akan panic.
+type memFileRepository struct {
+	repository.FileRepository
+	mu    sync.Mutex
+	files map[string]*model.FileMetadata
+}
+
+func (r *memFileRepository) Create(ctx context.Context, metadata *model.FileMetadata, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *metadata
+	stored.Tags = tags
+	stored.CreatedAt = time.Now()
+	r.files[metadata.ID] = &stored
+	return nil
+}
+
+func (r *memFileRepository) GetByID(ctx context.Context, id string) (*model.FileMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	metadata, ok := r.files[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	clone := *metadata
+	return &clone, nil
+}
+
+func (r *memFileRepository) DeleteByID(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.files[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.files, id)
+	return nil
+}
+
+func (r *memFileRepository) CheckRoleAccess(ctx context.Context, fileID string, roleName string) (bool, error) {
+	return false, nil
+}
+
+func (r *memFileRepository) List(ctx context.Context, filter repository.FileFilter) ([]*model.FileMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var files []*model.FileMetadata
+	for _, id := range filter.IDs {
+		if metadata, ok := r.files[id]; ok {
+			clone := *metadata
+			files = append(files, &clone)
+		}
+	}
+	return files, nil
+}
+
+// memS3Repository meniru postgresS3Repository, termasuk ON DELETE CASCADE
+// dari files ke s3_objects.
+type memS3Repository struct {
+	mu          sync.Mutex
+	files       *memFileRepository
+	credentials map[string]model.S3Credential
+	objects     map[string]model.S3Object
+	uploads     map[string]model.S3MultipartUpload
+	parts       map[string]map[int]model.S3MultipartPart
+}
+
+func (r *memS3Repository) GetCredential(ctx context.Context, accessKeyID string) (*model.S3Credential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	credential, ok := r.credentials[accessKeyID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &credential, nil
+}
+
+func (r *memS3Repository) ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var credentials []model.S3Credential
+	for _, credential := range r.credentials {
+		if credential.UserID == userID {
+			credentials = append(credentials, credential)
+		}
+	}
+	return credentials, nil
+}
+
+func (r *memS3Repository) CreateCredential(ctx context.Context, credential *model.S3Credential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	credential.CreatedAt = time.Now()
+	r.credentials[credential.AccessKeyID] = *credential
+	return nil
+}
+
+func (r *memS3Repository) DeleteCredential(ctx context.Context, userID, accessKeyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if credential, ok := r.credentials[accessKeyID]; !ok || credential.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(r.credentials, accessKeyID)
+	return nil
+}
+
+// liveObjects mengembalikan objek yang file-nya masih ada. Harus dipanggil
+// dengan r.mu terkunci.
+func (r *memS3Repository) liveObjects() []model.S3Object {
+	var objects []model.S3Object
+	for id, object := range r.objects {
+		if _, err := r.files.GetByID(context.Background(), object.FileID); err != nil {
+			delete(r.objects, id)
+			continue
+		}
+		objects = append(objects, object)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects
+}
+
+func (r *memS3Repository) GetObject(ctx context.Context, bucket, key string) (*model.S3Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, object := range r.liveObjects() {
+		if object.Bucket == bucket && object.Key == key {
+			return &object, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memS3Repository) PutObject(ctx context.Context, object model.S3Object) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveObjects()
+	id := object.Bucket + "/" + object.Key
+	previous := r.objects[id].FileID
+	r.objects[id] = object
+	return previous, nil
+}
+
+func (r *memS3Repository) ListObjects(ctx context.Context, bucket, prefix, startAfter string, limit int) ([]model.S3Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var objects []model.S3Object
+	for _, object := range r.liveObjects() {
+		if object.Bucket == bucket && strings.HasPrefix(object.Key, prefix) && object.Key > startAfter && len(objects) < limit {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+func (r *memS3Repository) CreateUpload(ctx context.Context, upload model.S3MultipartUpload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.uploads[upload.UploadID] = upload
+	r.parts[upload.UploadID] = map[int]model.S3MultipartPart{}
+	return nil
+}
+
+func (r *memS3Repository) GetUpload(ctx context.Context, uploadID string) (*model.S3MultipartUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload, ok := r.uploads[uploadID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &upload, nil
+}
+
+func (r *memS3Repository) PutPart(ctx context.Context, part model.S3MultipartPart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parts[part.UploadID][part.PartNumber] = part
+	return nil
+}
+
+func (r *memS3Repository) ListParts(ctx context.Context, uploadID string) ([]model.S3MultipartPart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var parts []model.S3MultipartPart
+	for _, part := range r.parts[uploadID] {
+		parts = append(parts, part)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (r *memS3Repository) DeleteUpload(ctx context.Context, uploadID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.uploads, uploadID)
+	delete(r.parts, uploadID)
+	return nil
+}
+
+type testEnv struct {
+	server      *httptest.Server
+	gateway     *Gateway
+	files       *memFileRepository
+	credentials service.S3CredentialService
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	return newTestEnvWithConfig(t, &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1 << 20,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true},
+	})
+}
+
+func newTestEnvWithConfig(t *testing.T, cfg *fileserviceconfig.Config) *testEnv {
+	t.Helper()
+	files := &memFileRepository{files: map[string]*model.FileMetadata{}}
+	objects := &memS3Repository{
+		files:       files,
+		credentials: map[string]model.S3Credential{},
+		objects:     map[string]model.S3Object{},
+		uploads:     map[string]model.S3MultipartUpload{},
+		parts:       map[string]map[int]model.S3MultipartPart{},
+	}
+	fileStorage, err := storage.NewLocalStorage(t.TempDir(), storage.LocalOptions{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = fileStorage.Close() })
+	credentials := service.NewS3CredentialService(objects, []byte("server-key"), nil)
+	gateway := NewGateway(service.NewFileService(files, fileStorage, cfg, nil, nil, nil), objects, credentials, fileStorage, cfg.MaxFileSizeBytes)
+
+	server := httptest.NewServer(gateway)
+	t.Cleanup(server.Close)
+	return &testEnv{server: server, gateway: gateway, files: files, credentials: credentials}
+}
+
+// client membuat klien S3 untuk pengguna baru dengan credential yang
+// diterbitkan S3CredentialService.
+func (e *testEnv) client(t *testing.T, userID, role string) *s3.Client {
+	t.Helper()
+	credential, err := e.credentials.CreateCredential(context.Background(), tokenClaims(userID, role))
+	require.NoError(t, err)
+	return e.clientWithSecret(credential.AccessKeyID, credential.SecretAccessKey)
+}
+
+// tokenClaims meniru klaim JWT dari layanan auth yang menerbitkan access key.
+func tokenClaims(userID, role string) jwt.MapClaims {
+	return jwt.MapClaims{"sub": userID, "role": role, "jti": uuid.NewString(), "exp": float64(time.Now().Add(time.Hour).Unix())}
+}
+
+func (e *testEnv) clientWithSecret(accessKeyID, secret string) *s3.Client {
+	return s3.New(s3.Options{
+		Region:                     "us-east-1",
+		BaseEndpoint:               aws.String(e.server.URL),
+		Credentials:                credentials.NewStaticCredentialsProvider(accessKeyID, secret, ""),
+		UsePathStyle:               true,
+		RetryMaxAttempts:           1,
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+}
+
+func putText(t *testing.T, client *s3.Client, key, content string) *s3.PutObjectOutput {
+	t.Helper()
+	out, err := client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(testBucket),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(content),
+	})
+	require.NoError(t, err)
+	return out
+}
+
+func getText(t *testing.T, client *s3.Client, key string) string {
+	t.Helper()
+	out, err := client.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String(key)})
+	require.NoError(t, err)
+	defer out.Body.Close()
+	body, err := io.ReadAll(out.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func assertErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var apiErr smithy.APIError
+	require.True(t, errors.As(err, &apiErr), "error bukan error API S3: %v", err)
+	assert.Equal(t, code, apiErr.ErrorCode())
+}
+
+func TestGateway_ObjectLifecycle(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	client := env.client(t, "user-1", "user")
+
+	put, err := client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:   aws.String(testBucket),
+		Key:      aws.String("2025/q1/ringkasan.txt"),
+		Body:     strings.NewReader("laporan kuartal pertama"),
+		Metadata: map[string]string{"prism-tags": "keuangan, q1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`"%x"`, md5.Sum([]byte("laporan kuartal pertama"))), aws.ToString(put.ETag))
+
+	assert.Equal(t, "laporan kuartal pertama", getText(t, client, "2025/q1/ringkasan.txt"))
+
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(testBucket), Key: aws.String("2025/q1/ringkasan.txt")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(23), aws.ToInt64(head.ContentLength))
+	assert.Equal(t, put.ETag, head.ETag)
+	assert.Equal(t, "keuangan,q1", head.Metadata["prism-tags"])
+	fileID := head.Metadata["prism-file-id"]
+	metadata, err := env.files.GetByID(ctx, fileID)
+	require.NoError(t, err)
+	assert.Equal(t, "ringkasan.txt", metadata.OriginalName)
+	assert.Equal(t, []string{"keuangan", "q1"}, metadata.Tags)
+
+	t.Run("Range", func(t *testing.T) {
+		for rangeHeader, want := range map[string]string{
+			"bytes=0-6":  "laporan",
+			"bytes=16-":  "pertama",
+			"bytes=-7":   "pertama",
+			"bytes=8-99": "kuartal pertama",
+		} {
+			out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("2025/q1/ringkasan.txt"), Range: aws.String(rangeHeader)})
+			require.NoError(t, err, rangeHeader)
+			body, _ := io.ReadAll(out.Body)
+			out.Body.Close()
+			assert.Equal(t, want, string(body), rangeHeader)
+			assert.Contains(t, aws.ToString(out.ContentRange), "/23", rangeHeader)
+		}
+
+		_, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("2025/q1/ringkasan.txt"), Range: aws.String("bytes=50-")})
+		assertErrorCode(t, err, "InvalidRange")
+	})
+
+	t.Run("Overwrite replaces the previous file", func(t *testing.T) {
+		putText(t, client, "2025/q1/ringkasan.txt", "revisi")
+		assert.Equal(t, "revisi", getText(t, client, "2025/q1/ringkasan.txt"))
+		_, err := env.files.GetByID(ctx, fileID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("Delete is idempotent", func(t *testing.T) {
+		for range 2 {
+			_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(testBucket), Key: aws.String("2025/q1/ringkasan.txt")})
+			require.NoError(t, err)
+		}
+		_, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("2025/q1/ringkasan.txt")})
+		var noSuchKey *types.NoSuchKey
+		assert.ErrorAs(t, err, &noSuchKey)
+	})
+
+	t.Run("Disallowed content is rejected", func(t *testing.T) {
+		_, err := client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(testBucket),
+			Key:    aws.String("gambar.png"),
+			Body:   bytes.NewReader([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")),
+		})
+		assertErrorCode(t, err, "InvalidArgument")
+	})
+}
+
+func TestGateway_ListObjectsV2(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	owner := env.client(t, "user-1", "user")
+	other := env.client(t, "user-2", "user")
+
+	for _, key := range []string{"a.txt", "b/1.txt", "b/2.txt", "c.txt", "d/1.txt", "e.txt"} {
+		putText(t, owner, key, "isi "+key)
+	}
+	putText(t, other, "rahasia/x.txt", "milik user-2")
+	putText(t, other, "f.txt", "milik user-2")
+
+	t.Run("Delimiter groups common prefixes across pages", func(t *testing.T) {
+		var keys, prefixes []string
+		paginator := s3.NewListObjectsV2Paginator(owner, &s3.ListObjectsV2Input{
+			Bucket:    aws.String(testBucket),
+			Delimiter: aws.String("/"),
+			MaxKeys:   aws.Int32(2),
+		})
+		pages := 0
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			require.NoError(t, err)
+			pages++
+			for _, object := range page.Contents {
+				keys = append(keys, aws.ToString(object.Key))
+			}
+			for _, prefix := range page.CommonPrefixes {
+				prefixes = append(prefixes, aws.ToString(prefix.Prefix))
+			}
+		}
+		assert.Equal(t, []string{"a.txt", "c.txt", "e.txt"}, keys)
+		assert.Equal(t, []string{"b/", "d/"}, prefixes, "prefix milik pengguna lain tidak boleh terlihat")
+		assert.Equal(t, 3, pages)
+	})
+
+	t.Run("Prefix without delimiter", func(t *testing.T) {
+		out, err := owner.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: aws.String(testBucket), Prefix: aws.String("b/")})
+		require.NoError(t, err)
+		require.Len(t, out.Contents, 2)
+		assert.Equal(t, "b/1.txt", aws.ToString(out.Contents[0].Key))
+		assert.Equal(t, int64(len("isi b/1.txt")), aws.ToInt64(out.Contents[0].Size))
+		assert.False(t, aws.ToBool(out.IsTruncated))
+	})
+
+	t.Run("Bucket with objects cannot be deleted", func(t *testing.T) {
+		_, err := owner.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: aws.String(testBucket)})
+		assertErrorCode(t, err, "BucketNotEmpty")
+	})
+}
+
+func TestGateway_MultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	client := env.client(t, "user-1", "user")
+	key := aws.String("besar/gabungan.txt")
+
+	created, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: aws.String(testBucket), Key: key})
+	require.NoError(t, err)
+
+	var completed []types.CompletedPart
+	for i, content := range []string{"bagian pertama, ", "bagian kedua"} {
+		part, err := client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(testBucket),
+			Key:        key,
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(int32(i + 1)),
+			Body:       strings.NewReader(content),
+		})
+		require.NoError(t, err)
+		completed = append(completed, types.CompletedPart{ETag: part.ETag, PartNumber: aws.Int32(int32(i + 1))})
+	}
+
+	t.Run("Parts above a reloaded size limit are rejected", func(t *testing.T) {
+		env.gateway.SetMaxObjectSize(4)
+		defer env.gateway.SetMaxObjectSize(1 << 20)
+		_, err := client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(testBucket),
+			Key:        key,
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(3),
+			Body:       strings.NewReader("terlalu besar"),
+		})
+		assertErrorCode(t, err, "EntityTooLarge")
+	})
+
+	t.Run("Out of order parts are rejected", func(t *testing.T) {
+		_, err := client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(testBucket),
+			Key:             key,
+			UploadId:        created.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: []types.CompletedPart{completed[1], completed[0]}},
+		})
+		assertErrorCode(t, err, "InvalidPartOrder")
+	})
+
+	t.Run("Other users cannot complete the upload", func(t *testing.T) {
+		_, err := env.client(t, "user-2", "user").CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(testBucket),
+			Key:             key,
+			UploadId:        created.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+		})
+		assertErrorCode(t, err, "AccessDenied")
+	})
+
+	out, err := client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(testBucket),
+		Key:             key,
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(aws.ToString(out.ETag), `-2"`), aws.ToString(out.ETag))
+	assert.Equal(t, "bagian pertama, bagian kedua", getText(t, client, "besar/gabungan.txt"))
+
+	_, err = client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{Bucket: aws.String(testBucket), Key: key, UploadId: created.UploadId})
+	assertErrorCode(t, err, "NoSuchUpload")
+}
+
+func TestGateway_UploadPolicy(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnvWithConfig(t, &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1 << 20,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true},
+		UploadPolicies: []uploadpolicy.Policy{
+			{Name: "intern-log", Match: uploadpolicy.Match{Roles: []string{"intern"}}, AllowedExtensions: []string{".log"}},
+		},
+	})
+	intern := env.client(t, "intern-1", "intern")
+
+	t.Run("Put object follows the role policy", func(t *testing.T) {
+		_, err := intern.PutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(testBucket), Key: aws.String("catatan.txt"), Body: strings.NewReader("halo")})
+		assertErrorCode(t, err, "InvalidArgument")
+		putText(t, intern, "catatan.log", "halo")
+		putText(t, env.client(t, "user-1", "user"), "catatan.txt", "halo")
+	})
+
+	t.Run("Multipart completion follows the role policy", func(t *testing.T) {
+		key := aws.String("besar/catatan.txt")
+		created, err := intern.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: aws.String(testBucket), Key: key})
+		require.NoError(t, err)
+		part, err := intern.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(testBucket),
+			Key:        key,
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(1),
+			Body:       strings.NewReader("halo"),
+		})
+		require.NoError(t, err)
+
+		_, err = intern.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(testBucket),
+			Key:             key,
+			UploadId:        created.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: []types.CompletedPart{{ETag: part.ETag, PartNumber: aws.Int32(1)}}},
+		})
+		assertErrorCode(t, err, "InvalidArgument")
+	})
+}
+
+func TestGateway_Authorization(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	owner := env.client(t, "user-1", "user")
+	putText(t, owner, "pribadi.txt", "hanya untuk user-1")
+
+	t.Run("Other user cannot read or overwrite", func(t *testing.T) {
+		other := env.client(t, "user-2", "user")
+		_, err := other.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("pribadi.txt")})
+		assertErrorCode(t, err, "AccessDenied")
+
+		_, err = other.PutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(testBucket), Key: aws.String("pribadi.txt"), Body: strings.NewReader("ditimpa")})
+		assertErrorCode(t, err, "AccessDenied")
+		assert.Equal(t, "hanya untuk user-1", getText(t, owner, "pribadi.txt"))
+	})
+
+	t.Run("Admin can read", func(t *testing.T) {
+		assert.Equal(t, "hanya untuk user-1", getText(t, env.client(t, "admin-1", "admin"), "pribadi.txt"))
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		credential, err := env.credentials.CreateCredential(ctx, tokenClaims("user-1", "user"))
+		require.NoError(t, err)
+		_, err = env.clientWithSecret(credential.AccessKeyID, "salah").GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("pribadi.txt")})
+		assertErrorCode(t, err, "SignatureDoesNotMatch")
+	})
+
+	t.Run("Unknown access key", func(t *testing.T) {
+		_, err := env.clientWithSecret("PRSMUNKNOWN", "salah").GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("pribadi.txt")})
+		assertErrorCode(t, err, "InvalidAccessKeyId")
+	})
+
+	t.Run("Access key outlived its issuing token", func(t *testing.T) {
+		claims := tokenClaims("user-1", "user")
+		claims["exp"] = float64(time.Now().Add(-time.Minute).Unix())
+		credential, err := env.credentials.CreateCredential(ctx, claims)
+		require.NoError(t, err)
+		_, err = env.clientWithSecret(credential.AccessKeyID, credential.SecretAccessKey).GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("pribadi.txt")})
+		assertErrorCode(t, err, "InvalidAccessKeyId")
+	})
+
+	t.Run("Unsigned request", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/%s/pribadi.txt", env.server.URL, testBucket))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Presigned GET", func(t *testing.T) {
+		presigned, err := s3.NewPresignClient(owner).PresignGetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("pribadi.txt")}, s3.WithPresignExpires(time.Minute))
+		require.NoError(t, err)
+		resp, err := http.Get(presigned.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hanya untuk user-1", string(body))
+
+		resp, err = http.Get(strings.Replace(presigned.URL, "pribadi.txt", "lain.txt", 1))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Payload hash mismatch", func(t *testing.T) {
+		resp := signedRequest(t, env, http.MethodPut, "/"+testBucket+"/palsu.txt", "isi lain", sha256Hex("isi asli"), time.Now())
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, readBody(t, resp), "XAmzContentSHA256Mismatch")
+		_, err := owner.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(testBucket), Key: aws.String("palsu.txt")})
+		assert.Error(t, err)
+	})
+
+	t.Run("Request time too skewed", func(t *testing.T) {
+		resp := signedRequest(t, env, http.MethodGet, "/"+testBucket+"/pribadi.txt", "", sha256Hex(""), time.Now().Add(-time.Hour))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Contains(t, readBody(t, resp), "RequestTimeTooSkewed")
+	})
+}
+
+// signedRequest mengirim request mentah yang ditandatangani SigV4 dengan hash
+// payload dan waktu pilihan pemanggil.
+func signedRequest(t *testing.T, env *testEnv, method, path, body, payloadHash string, signedAt time.Time) *http.Response {
+	t.Helper()
+	credential, err := env.credentials.CreateCredential(context.Background(), tokenClaims("user-1", "user"))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(method, env.server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	creds := aws.Credentials{AccessKeyID: credential.AccessKeyID, SecretAccessKey: credential.SecretAccessKey}
+	require.NoError(t, v4.NewSigner().SignHTTP(context.Background(), creds, req, payloadHash, "s3", "us-east-1", signedAt))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		header         string
+		size           int64
+		offset, length int64
+		ok             bool
+	}{
+		{"bytes=0-0", 10, 0, 1, true},
+		{"bytes=5-", 10, 5, 5, true},
+		{"bytes=-3", 10, 7, 3, true},
+		{"bytes=-30", 10, 0, 10, true},
+		{"bytes=2-100", 10, 2, 8, true},
+		{"bytes=10-", 10, 0, 0, false},
+		{"bytes=5-2", 10, 0, 0, false},
+		{"bytes=0-1,3-4", 10, 0, 0, false},
+		{"items=0-1", 10, 0, 0, false},
+		{"bytes=-0", 10, 0, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			offset, length, ok := parseRange(tc.header, tc.size)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.offset, offset)
+				assert.Equal(t, tc.length, length)
+			}
+		})
+	}
+}
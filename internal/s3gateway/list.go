@@ -0,0 +1,195 @@
+package s3gateway
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+)
+
+const (
+	maxListKeys   = 1000
+	listBatchSize = 1000
+	// afterPrefix ditambahkan ke common prefix agar pemindaian berikutnya
+	// melompati semua key di bawah prefix tersebut.
+	afterPrefix = "\U0010FFFF"
+)
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int            `xml:"KeyCount"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	Contents              []listObject   `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type listObject struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// listEntry adalah satu baris hasil listing: objek atau common prefix.
+type listEntry struct {
+	object   *listObject
+	prefix   string
+	resumeAt string
+}
+
+// listObjectsV2 hanya menampilkan objek yang boleh dibaca pemanggil menurut
+// kebijakan otorisasi. Common prefix hanya muncul jika ada minimal satu objek
+// yang dapat diakses di bawahnya, agar struktur folder pengguna lain tidak bocor.
+func (g *Gateway) listObjectsV2(req *request) *s3Error {
+	query := req.r.URL.Query()
+	result := listBucketResult{
+		Name:              req.bucket,
+		Prefix:            query.Get("prefix"),
+		Delimiter:         query.Get("delimiter"),
+		StartAfter:        query.Get("start-after"),
+		ContinuationToken: query.Get("continuation-token"),
+		MaxKeys:           maxListKeys,
+		EncodingType:      query.Get("encoding-type"),
+	}
+	if result.EncodingType != "" && result.EncodingType != "url" {
+		return errInvalidArgument.withMessage("encoding-type tidak valid")
+	}
+	if value := query.Get("max-keys"); value != "" {
+		maxKeys, err := strconv.Atoi(value)
+		if err != nil || maxKeys < 0 {
+			return errInvalidArgument.withMessage("max-keys tidak valid")
+		}
+		result.MaxKeys = min(maxKeys, maxListKeys)
+	}
+
+	marker := result.StartAfter
+	if result.ContinuationToken != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(result.ContinuationToken)
+		if err != nil {
+			return errInvalidArgument.withMessage("continuation-token tidak valid")
+		}
+		marker = max(marker, string(decoded))
+	}
+
+	// Ambil satu entri lebih banyak dari max-keys untuk mengetahui apakah hasil
+	// terpotong.
+	entries, err := g.collectEntries(req, result.Prefix, result.Delimiter, marker, result.MaxKeys+1)
+	if err != nil {
+		return toS3Error(err)
+	}
+	if len(entries) > result.MaxKeys {
+		entries = entries[:result.MaxKeys]
+		result.IsTruncated = true
+		result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(entries[len(entries)-1].resumeAt))
+	}
+
+	encode := func(s string) string { return s }
+	if result.EncodingType == "url" {
+		encode = func(s string) string { return uriEncode(s, false) }
+		result.Prefix, result.Delimiter, result.StartAfter = encode(result.Prefix), encode(result.Delimiter), encode(result.StartAfter)
+	}
+	for _, entry := range entries {
+		if entry.object != nil {
+			object := *entry.object
+			object.Key = encode(object.Key)
+			result.Contents = append(result.Contents, object)
+		} else {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: encode(entry.prefix)})
+		}
+	}
+	result.KeyCount = len(entries)
+
+	writeXML(req.w, http.StatusOK, result)
+	return nil
+}
+
+func (g *Gateway) collectEntries(req *request, prefix, delimiter, marker string, limit int) ([]listEntry, error) {
+	var entries []listEntry
+	for len(entries) < limit {
+		objects, err := g.objects.ListObjects(req.r.Context(), req.bucket, prefix, marker, listBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(objects) == 0 {
+			break
+		}
+		accessible, err := g.accessibleFiles(req, objects)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range objects {
+			if len(entries) == limit {
+				break
+			}
+			if object.Key <= marker {
+				// Sudah tercakup oleh common prefix sebelumnya.
+				continue
+			}
+			marker = object.Key
+			metadata, ok := accessible[object.FileID]
+			if !ok {
+				continue
+			}
+
+			if delimiter != "" {
+				if i := strings.Index(object.Key[len(prefix):], delimiter); i >= 0 {
+					common := object.Key[:len(prefix)+i+len(delimiter)]
+					marker = common + afterPrefix
+					entries = append(entries, listEntry{prefix: common, resumeAt: marker})
+					continue
+				}
+			}
+			entries = append(entries, listEntry{
+				object: &listObject{
+					Key:          object.Key,
+					LastModified: metadata.CreatedAt.UTC().Format(time.RFC3339),
+					ETag:         quoteETag(object.ETag),
+					Size:         metadata.SizeBytes,
+					StorageClass: "STANDARD",
+				},
+				resumeAt: object.Key,
+			})
+		}
+		if len(objects) < listBatchSize {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// accessibleFiles mengembalikan metadata file yang boleh dibaca pemanggil,
+// diindeks berdasarkan ID.
+func (g *Gateway) accessibleFiles(req *request, objects []model.S3Object) (map[string]*model.FileMetadata, error) {
+	ids := make([]string, len(objects))
+	for i, object := range objects {
+		ids[i] = object.FileID
+	}
+	files, err := g.files.ListFiles(req.r.Context(), repository.FileFilter{IDs: ids}, req.claims)
+	if err != nil {
+		return nil, err
+	}
+	accessible := make(map[string]*model.FileMetadata, len(files))
+	for _, file := range files {
+		accessible[file.ID] = file
+	}
+	return accessible, nil
+}
@@ -0,0 +1,270 @@
+package s3gateway
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const maxPartNumber = 10000
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
+
+func (g *Gateway) createMultipartUpload(req *request) *s3Error {
+	if s3err := g.checkReplaceable(req); s3err != nil {
+		return s3err
+	}
+	upload := model.S3MultipartUpload{
+		UploadID: uuid.New().String(),
+		Bucket:   req.bucket,
+		Key:      req.key,
+		UserID:   req.credential.UserID,
+		Tags:     parseTags(req.r.Header.Get(TagsHeader)),
+	}
+	if err := g.objects.CreateUpload(req.r.Context(), upload); err != nil {
+		return toS3Error(err)
+	}
+	writeXML(req.w, http.StatusOK, initiateMultipartUploadResult{Bucket: req.bucket, Key: req.key, UploadID: upload.UploadID})
+	return nil
+}
+
+func (g *Gateway) uploadPart(req *request, uploadID string) *s3Error {
+	partNumber, err := strconv.Atoi(req.r.URL.Query().Get("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > maxPartNumber {
+		return errInvalidArgument.withMessage(fmt.Sprintf("partNumber harus antara 1 dan %d", maxPartNumber))
+	}
+	if _, s3err := g.ownUpload(req, uploadID); s3err != nil {
+		return s3err
+	}
+	maxObjectSize := g.maxObjectSize.Load()
+	if req.r.ContentLength > maxObjectSize {
+		return errEntityTooLarge
+	}
+
+	digest := md5.New()
+	counter := &countingReader{r: io.LimitReader(req.r.Body, maxObjectSize+1)}
+	partPath := partStoragePath(uploadID, partNumber)
+	if err := g.storage.Save(req.r.Context(), partPath, io.TeeReader(counter, digest)); err != nil {
+		g.deletePart(req, partPath)
+		if errors.Is(err, errContentSHA256Mismatch) {
+			return errXAmzContentSHA256Mismatch
+		}
+		log.Error().Err(err).Str("upload_id", uploadID).Int("part_number", partNumber).Msg("Gagal menyimpan bagian multipart upload")
+		return errInternal
+	}
+	if counter.n > maxObjectSize {
+		g.deletePart(req, partPath)
+		return errEntityTooLarge
+	}
+
+	etag := md5Hex(digest)
+	part := model.S3MultipartPart{UploadID: uploadID, PartNumber: partNumber, ETag: etag, SizeBytes: counter.n}
+	if err := g.objects.PutPart(req.r.Context(), part); err != nil {
+		return toS3Error(err)
+	}
+	req.w.Header().Set("ETag", quoteETag(etag))
+	req.w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (g *Gateway) completeMultipartUpload(req *request, uploadID string) *s3Error {
+	upload, s3err := g.ownUpload(req, uploadID)
+	if s3err != nil {
+		return s3err
+	}
+
+	var body completeMultipartUpload
+	if err := xml.NewDecoder(io.LimitReader(req.r.Body, 1<<20)).Decode(&body); err != nil || len(body.Parts) == 0 {
+		return errMalformedXML
+	}
+
+	stored, err := g.objects.ListParts(req.r.Context(), uploadID)
+	if err != nil {
+		return toS3Error(err)
+	}
+	byNumber := make(map[int]model.S3MultipartPart, len(stored))
+	for _, part := range stored {
+		byNumber[part.PartNumber] = part
+	}
+
+	// ETag objek multipart mengikuti S3: md5 dari gabungan md5 tiap bagian,
+	// diikuti jumlah bagian.
+	combined := md5.New()
+	parts := make([]model.S3MultipartPart, 0, len(body.Parts))
+	var total int64
+	for i, requested := range body.Parts {
+		if i > 0 && requested.PartNumber <= body.Parts[i-1].PartNumber {
+			return errInvalidPartOrder
+		}
+		part, ok := byNumber[requested.PartNumber]
+		if !ok || strings.Trim(requested.ETag, `"`) != part.ETag {
+			return errInvalidPart
+		}
+		sum, err := hex.DecodeString(part.ETag)
+		if err != nil {
+			return errInvalidPart
+		}
+		combined.Write(sum)
+		total += part.SizeBytes
+		parts = append(parts, part)
+	}
+	if total > g.maxObjectSize.Load() {
+		return errEntityTooLarge
+	}
+	if s3err := g.checkReplaceable(req); s3err != nil {
+		return s3err
+	}
+
+	reader := &partsReader{gateway: g, req: req, parts: parts}
+	defer reader.Close()
+	metadata, err := g.files.UploadStream(req.uploadContext(), upload.UserID, objectFileName(upload.Key), reader, upload.Tags)
+	if err != nil {
+		return toS3Error(err)
+	}
+
+	etag := fmt.Sprintf("%s-%d", md5Hex(combined), len(parts))
+	if s3err := g.publish(req, metadata.ID, etag); s3err != nil {
+		return s3err
+	}
+	g.cleanupUpload(req, uploadID, stored)
+
+	writeXML(req.w, http.StatusOK, completeMultipartUploadResult{Bucket: req.bucket, Key: req.key, ETag: quoteETag(etag)})
+	return nil
+}
+
+func (g *Gateway) abortMultipartUpload(req *request, uploadID string) *s3Error {
+	if _, s3err := g.ownUpload(req, uploadID); s3err != nil {
+		return s3err
+	}
+	parts, err := g.objects.ListParts(req.r.Context(), uploadID)
+	if err != nil {
+		return toS3Error(err)
+	}
+	g.cleanupUpload(req, uploadID, parts)
+	req.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// ownUpload mengambil multipart upload dan memastikan upload tersebut milik
+// pemanggil serta ditujukan ke bucket/key yang sama.
+func (g *Gateway) ownUpload(req *request, uploadID string) (*model.S3MultipartUpload, *s3Error) {
+	upload, err := g.objects.GetUpload(req.r.Context(), uploadID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errNoSuchUpload
+	}
+	if err != nil {
+		return nil, toS3Error(err)
+	}
+	if upload.Bucket != req.bucket || upload.Key != req.key {
+		return nil, errNoSuchUpload
+	}
+	if upload.UserID != req.credential.UserID {
+		return nil, errAccessDenied
+	}
+	return upload, nil
+}
+
+// cleanupUpload menghapus bagian-bagian dari storage lalu catatan upload-nya.
+func (g *Gateway) cleanupUpload(req *request, uploadID string, parts []model.S3MultipartPart) {
+	for _, part := range parts {
+		g.deletePart(req, partStoragePath(uploadID, part.PartNumber))
+	}
+	if err := g.objects.DeleteUpload(req.r.Context(), uploadID); err != nil {
+		log.Warn().Err(err).Str("upload_id", uploadID).Msg("Gagal menghapus catatan multipart upload")
+	}
+}
+
+func (g *Gateway) deletePart(req *request, partPath string) {
+	if err := g.storage.Delete(req.r.Context(), partPath); err != nil {
+		log.Warn().Err(err).Str("storage_path", partPath).Msg("Gagal menghapus bagian multipart upload dari storage")
+	}
+}
+
+func partStoragePath(uploadID string, partNumber int) string {
+	return fmt.Sprintf("s3-multipart/%s/%05d", uploadID, partNumber)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// partsReader membaca bagian-bagian multipart secara berurutan, membuka tiap
+// bagian dari storage hanya saat dibutuhkan.
+type partsReader struct {
+	gateway *Gateway
+	req     *request
+	parts   []model.S3MultipartPart
+	current io.ReadCloser
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.current == nil {
+			if len(p.parts) == 0 {
+				return 0, io.EOF
+			}
+			reader, err := p.gateway.storage.Get(p.req.r.Context(), partStoragePath(p.parts[0].UploadID, p.parts[0].PartNumber))
+			if err != nil {
+				return 0, fmt.Errorf("gagal membuka bagian %d: %w", p.parts[0].PartNumber, err)
+			}
+			p.current = reader
+			p.parts = p.parts[1:]
+		}
+		n, err := p.current.Read(b)
+		if errors.Is(err, io.EOF) {
+			err = p.Close()
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() error {
+	if p.current == nil {
+		return nil
+	}
+	err := p.current.Close()
+	p.current = nil
+	return err
+}
@@ -0,0 +1,306 @@
+package s3gateway
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	maxClockSkew     = 15 * time.Minute
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// errContentSHA256Mismatch dikembalikan oleh body request jika isi yang
+// diterima tidak cocok dengan header X-Amz-Content-Sha256.
+var errContentSHA256Mismatch = errors.New("x-amz-content-sha256 tidak cocok dengan isi request")
+
+// sigV4Request adalah elemen tanda tangan SigV4 dari header Authorization atau
+// query string presigned URL.
+type sigV4Request struct {
+	accessKeyID   string
+	date          string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+	amzDate       time.Time
+	expires       time.Duration
+	payloadHash   string
+	presigned     bool
+}
+
+// authenticate memverifikasi tanda tangan SigV4 dan mengembalikan credential
+// pemilik access key. Jika payload ditandatangani, body request dibungkus agar
+// hash-nya diverifikasi saat dibaca.
+func (g *Gateway) authenticate(r *http.Request) (*model.S3Credential, *s3Error) {
+	req, s3err := parseSigV4(r)
+	if s3err != nil {
+		return nil, s3err
+	}
+
+	now := g.now()
+	if req.presigned {
+		if now.After(req.amzDate.Add(req.expires)) {
+			return nil, errAccessDenied.withMessage("presigned URL sudah kedaluwarsa")
+		}
+		if req.amzDate.After(now.Add(maxClockSkew)) {
+			return nil, errRequestTimeTooSkewed
+		}
+	} else if req.amzDate.Before(now.Add(-maxClockSkew)) || req.amzDate.After(now.Add(maxClockSkew)) {
+		return nil, errRequestTimeTooSkewed
+	}
+
+	credential, err := g.credentials.Lookup(r.Context(), req.accessKeyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errInvalidAccessKeyID
+		}
+		log.Error().Err(err).Str("access_key_id", req.accessKeyID).Msg("Gagal mengambil access key S3")
+		return nil, errInternal
+	}
+
+	expected := signature(credential.SecretAccessKey, req, stringToSign(req, canonicalRequest(r, req)))
+	if !hmac.Equal([]byte(expected), []byte(req.signature)) {
+		return nil, errSignatureDoesNotMatch
+	}
+
+	if req.payloadHash != unsignedPayload && r.Body != nil {
+		expectedHash, err := hex.DecodeString(req.payloadHash)
+		if err != nil || len(expectedHash) != sha256.Size {
+			return nil, errInvalidArgument.withMessage("x-amz-content-sha256 tidak valid")
+		}
+		r.Body = &verifyingBody{body: r.Body, hash: sha256.New(), expected: expectedHash}
+	}
+	return credential, nil
+}
+
+func parseSigV4(r *http.Request) (*sigV4Request, *s3Error) {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != "" {
+		return parsePresigned(query)
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, errAccessDenied.withMessage("request tidak ditandatangani")
+	}
+	if !strings.HasPrefix(authHeader, sigV4Algorithm+" ") {
+		return nil, errInvalidArgument.withMessage("hanya " + sigV4Algorithm + " yang didukung")
+	}
+
+	req := &sigV4Request{}
+	for _, part := range strings.Split(strings.TrimPrefix(authHeader, sigV4Algorithm+" "), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "Credential":
+			if s3err := req.parseCredential(value); s3err != nil {
+				return nil, s3err
+			}
+		case "SignedHeaders":
+			req.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			req.signature = value
+		}
+	}
+	if req.accessKeyID == "" || len(req.signedHeaders) == 0 || req.signature == "" {
+		return nil, errAuthorizationHeaderMalformed
+	}
+
+	req.payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+	if req.payloadHash == "" {
+		return nil, errInvalidArgument.withMessage("header x-amz-content-sha256 wajib diisi")
+	}
+	if strings.HasPrefix(req.payloadHash, "STREAMING-") {
+		return nil, errNotImplemented.withMessage("payload aws-chunked belum didukung; nonaktifkan checksum streaming di klien")
+	}
+	return req, req.parseDate(r.Header.Get("X-Amz-Date"))
+}
+
+func parsePresigned(query url.Values) (*sigV4Request, *s3Error) {
+	if query.Get("X-Amz-Algorithm") != sigV4Algorithm {
+		return nil, errInvalidArgument.withMessage("hanya " + sigV4Algorithm + " yang didukung")
+	}
+	req := &sigV4Request{
+		presigned:     true,
+		signedHeaders: strings.Split(query.Get("X-Amz-SignedHeaders"), ";"),
+		signature:     query.Get("X-Amz-Signature"),
+		payloadHash:   unsignedPayload,
+	}
+	if hash := query.Get("X-Amz-Content-Sha256"); hash != "" {
+		req.payloadHash = hash
+	}
+	if s3err := req.parseCredential(query.Get("X-Amz-Credential")); s3err != nil {
+		return nil, s3err
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires <= 0 || time.Duration(expires)*time.Second > maxPresignExpiry {
+		return nil, errAuthorizationQueryParametersError
+	}
+	req.expires = time.Duration(expires) * time.Second
+	if req.signature == "" {
+		return nil, errAuthorizationQueryParametersError
+	}
+	return req, req.parseDate(query.Get("X-Amz-Date"))
+}
+
+// parseCredential membaca scope "AKID/20250101/us-east-1/s3/aws4_request".
+func (req *sigV4Request) parseCredential(value string) *s3Error {
+	parts := strings.Split(value, "/")
+	if len(parts) != 5 || parts[3] != "s3" || parts[4] != "aws4_request" {
+		return errAuthorizationHeaderMalformed
+	}
+	req.accessKeyID, req.date, req.region, req.service = parts[0], parts[1], parts[2], parts[3]
+	return nil
+}
+
+func (req *sigV4Request) parseDate(value string) *s3Error {
+	amzDate, err := time.Parse(amzDateFormat, value)
+	if err != nil || !strings.HasPrefix(value, req.date) {
+		return errAccessDenied.withMessage("X-Amz-Date tidak valid atau tidak cocok dengan scope credential")
+	}
+	req.amzDate = amzDate
+	return nil
+}
+
+func canonicalRequest(r *http.Request, req *sigV4Request) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+	b.WriteString(uriEncode(r.URL.Path, false))
+	b.WriteByte('\n')
+	b.WriteString(canonicalQuery(r.URL.Query()))
+	b.WriteByte('\n')
+	for _, name := range req.signedHeaders {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(canonicalHeaderValue(r, name))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	b.WriteString(strings.Join(req.signedHeaders, ";"))
+	b.WriteByte('\n')
+	b.WriteString(req.payloadHash)
+	return b.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	type pair struct{ key, value string }
+	pairs := make([]pair, 0, len(query))
+	for key, values := range query {
+		if key == "X-Amz-Signature" {
+			continue
+		}
+		for _, value := range values {
+			pairs = append(pairs, pair{uriEncode(key, true), uriEncode(value, true)})
+		}
+	}
+	// Urutkan berdasarkan key lalu value, bukan string "key=value" utuh.
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p.key + "=" + p.value
+	}
+	return strings.Join(encoded, "&")
+}
+
+func canonicalHeaderValue(r *http.Request, name string) string {
+	switch name {
+	case "host":
+		return r.Host
+	case "content-length":
+		// net/http memindahkan Content-Length dari r.Header ke r.ContentLength.
+		return strconv.FormatInt(r.ContentLength, 10)
+	}
+	var values []string
+	for _, value := range r.Header.Values(name) {
+		values = append(values, strings.Join(strings.Fields(value), " "))
+	}
+	return strings.Join(values, ",")
+}
+
+func stringToSign(req *sigV4Request, canonical string) string {
+	hashed := sha256.Sum256([]byte(canonical))
+	return strings.Join([]string{
+		sigV4Algorithm,
+		req.amzDate.Format(amzDateFormat),
+		strings.Join([]string{req.date, req.region, req.service, "aws4_request"}, "/"),
+		hex.EncodeToString(hashed[:]),
+	}, "\n")
+}
+
+func signature(secret string, req *sigV4Request, toSign string) string {
+	key := hmacSHA256([]byte("AWS4"+secret), req.date)
+	key = hmacSHA256(key, req.region)
+	key = hmacSHA256(key, req.service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode mengikuti aturan URI encoding SigV4: hanya karakter unreserved
+// (A-Z, a-z, 0-9, '-', '_', '.', '~') yang tidak di-encode.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// verifyingBody menghitung SHA-256 isi request dan mengembalikan
+// errContentSHA256Mismatch di akhir stream jika tidak cocok.
+type verifyingBody struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	expected []byte
+}
+
+func (v *verifyingBody) Read(p []byte) (int, error) {
+	n, err := v.body.Read(p)
+	v.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) && !bytes.Equal(v.hash.Sum(nil), v.expected) {
+		return n, errContentSHA256Mismatch
+	}
+	return n, err
+}
+
+func (v *verifyingBody) Close() error {
+	return v.body.Close()
+}
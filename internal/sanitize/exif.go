@@ -0,0 +1,79 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const orientationTag = 0x0112
+
+// exifHeader mengawali data EXIF di segmen APP1 JPEG dan sebagian chunk EXIF
+// WebP.
+var exifHeader = []byte("Exif\x00\x00")
+
+// exifOrientation membaca tag Orientation (1–8) dari IFD0 data EXIF berformat
+// TIFF, dengan atau tanpa exifHeader. Mengembalikan 0 jika tidak ada atau
+// datanya tidak valid.
+func exifOrientation(data []byte) int {
+	data = bytes.TrimPrefix(data, exifHeader)
+	if len(data) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(data[2:4]) != 42 {
+		return 0
+	}
+	ifd := int64(order.Uint32(data[4:8]))
+	if ifd+2 > int64(len(data)) {
+		return 0
+	}
+	count := int64(order.Uint16(data[ifd : ifd+2]))
+	for i := int64(0); i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > int64(len(data)) {
+			return 0
+		}
+		if order.Uint16(data[entry:entry+2]) != orientationTag {
+			continue
+		}
+		// Tipe SHORT (3) dengan satu nilai disimpan di awal field nilai.
+		if order.Uint16(data[entry+2:entry+4]) != 3 {
+			return 0
+		}
+		if v := int(order.Uint16(data[entry+8 : entry+10])); v >= 1 && v <= 8 {
+			return v
+		}
+		return 0
+	}
+	return 0
+}
+
+// orientationOnlyEXIF membuat data EXIF berformat TIFF yang hanya berisi tag
+// Orientation, agar gambar tetap ditampilkan dengan arah yang benar setelah
+// metadata lain dibuang.
+func orientationOnlyEXIF(orientation int) []byte {
+	tiff := make([]byte, 26)
+	copy(tiff, "MM\x00\x2a")
+	binary.BigEndian.PutUint32(tiff[4:], 8)
+	binary.BigEndian.PutUint16(tiff[8:], 1)
+	binary.BigEndian.PutUint16(tiff[10:], orientationTag)
+	binary.BigEndian.PutUint16(tiff[12:], 3)
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], uint16(orientation))
+	// Offset IFD berikutnya (4 byte terakhir) bernilai 0.
+	return tiff
+}
+
+// keepsOrientation melaporkan apakah orientasi perlu dipertahankan, yaitu
+// gambar tidak ditampilkan apa adanya.
+func keepsOrientation(orientation int) bool {
+	return orientation > 1
+}
@@ -0,0 +1,271 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// sanitizeJPEG membuang segmen APP1 (EXIF, XMP), APP13 (IPTC), segmen APPn
+// lain yang tidak dibutuhkan dekoder dan komentar. JFIF (APP0), profil warna
+// ICC (APP2) dan Adobe (APP14) dipertahankan. Segmen setelah SOS disalin
+// apa adanya.
+func sanitizeJPEG(dst io.Writer, content io.ReaderAt, size int64) error {
+	r := io.NewSectionReader(content, 0, size)
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return fmt.Errorf("%w: penanda SOI JPEG tidak ditemukan", ErrMalformed)
+	}
+
+	type segment struct {
+		marker byte
+		data   []byte
+	}
+	var head, rest []segment
+	orientation := 0
+	for {
+		var marker [2]byte
+		if _, err := io.ReadFull(r, marker[:]); err != nil {
+			return fmt.Errorf("%w: segmen JPEG terpotong", ErrMalformed)
+		}
+		for marker[0] == 0xFF && marker[1] == 0xFF {
+			// Byte pengisi sebelum penanda.
+			if _, err := io.ReadFull(r, marker[1:]); err != nil {
+				return fmt.Errorf("%w: segmen JPEG terpotong", ErrMalformed)
+			}
+		}
+		if marker[0] != 0xFF {
+			return fmt.Errorf("%w: penanda segmen JPEG tidak valid", ErrMalformed)
+		}
+		if marker[1] == 0xDA {
+			// SOS: sisa file adalah data gambar.
+			break
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil || binary.BigEndian.Uint16(length[:]) < 2 {
+			return fmt.Errorf("%w: panjang segmen JPEG tidak valid", ErrMalformed)
+		}
+		data := make([]byte, binary.BigEndian.Uint16(length[:])-2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("%w: segmen JPEG terpotong", ErrMalformed)
+		}
+
+		switch m := marker[1]; {
+		case m == 0xE0:
+			head = append(head, segment{m, data})
+		case m == 0xE1:
+			if bytes.HasPrefix(data, exifHeader) && orientation == 0 {
+				orientation = exifOrientation(data)
+			}
+		case m == 0xE2 && bytes.HasPrefix(data, []byte("ICC_PROFILE\x00")), m == 0xEE:
+			rest = append(rest, segment{m, data})
+		case m >= 0xE0 && m <= 0xEF, m == 0xFE:
+			// Metadata lain: IPTC, MPF, FlashPix, komentar.
+		default:
+			rest = append(rest, segment{m, data})
+		}
+	}
+
+	if keepsOrientation(orientation) {
+		exif := append(append([]byte{}, exifHeader...), orientationOnlyEXIF(orientation)...)
+		head = append(head, segment{0xE1, exif})
+	}
+	if _, err := dst.Write([]byte{0xFF, 0xD8}); err != nil {
+		return err
+	}
+	for _, s := range append(head, rest...) {
+		header := []byte{0xFF, s.marker, 0, 0}
+		binary.BigEndian.PutUint16(header[2:], uint16(len(s.data)+2))
+		if _, err := dst.Write(header); err != nil {
+			return err
+		}
+		if _, err := dst.Write(s.data); err != nil {
+			return err
+		}
+	}
+	if _, err := dst.Write([]byte{0xFF, 0xDA}); err != nil {
+		return err
+	}
+	_, err := io.Copy(dst, r)
+	return err
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks adalah chunk PNG yang dibuang: EXIF, teks (termasuk XMP
+// di iTXt dan IPTC di zTXt) serta waktu modifikasi.
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// chunkSpan adalah letak satu chunk PNG atau RIFF di file, termasuk header
+// dan CRC atau padding-nya.
+type chunkSpan struct {
+	kind   string
+	offset int64
+	length int64
+}
+
+// sanitizePNG membuang chunk metadata dan menyalin chunk lain apa adanya.
+// Jika orientasi EXIF perlu dipertahankan, chunk eXIf baru yang hanya berisi
+// orientasi ditulis sebelum IDAT pertama.
+func sanitizePNG(dst io.Writer, content io.ReaderAt, size int64) error {
+	signature := make([]byte, len(pngSignature))
+	if _, err := content.ReadAt(signature, 0); err != nil || !bytes.Equal(signature, pngSignature) {
+		return fmt.Errorf("%w: signature PNG tidak ditemukan", ErrMalformed)
+	}
+
+	var chunks []chunkSpan
+	orientation := 0
+	for offset := int64(len(pngSignature)); offset < size; {
+		var header [8]byte
+		if _, err := content.ReadAt(header[:], offset); err != nil {
+			return fmt.Errorf("%w: chunk PNG terpotong", ErrMalformed)
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		chunk := chunkSpan{kind: string(header[4:]), offset: offset, length: 12 + length}
+		if offset+chunk.length > size {
+			return fmt.Errorf("%w: chunk PNG %q terpotong", ErrMalformed, chunk.kind)
+		}
+		if chunk.kind == "eXIf" && orientation == 0 && length <= 1<<20 {
+			data := make([]byte, length)
+			if _, err := content.ReadAt(data, offset+8); err != nil {
+				return err
+			}
+			orientation = exifOrientation(data)
+		}
+		chunks = append(chunks, chunk)
+		offset += chunk.length
+		if chunk.kind == "IEND" {
+			break
+		}
+	}
+
+	if _, err := dst.Write(pngSignature); err != nil {
+		return err
+	}
+	wroteOrientation := !keepsOrientation(orientation)
+	for _, chunk := range chunks {
+		if pngMetadataChunks[chunk.kind] {
+			continue
+		}
+		if chunk.kind == "IDAT" && !wroteOrientation {
+			if err := writePNGChunk(dst, "eXIf", orientationOnlyEXIF(orientation)); err != nil {
+				return err
+			}
+			wroteOrientation = true
+		}
+		if _, err := io.Copy(dst, io.NewSectionReader(content, chunk.offset, chunk.length)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePNGChunk(dst io.Writer, kind string, data []byte) error {
+	buf := make([]byte, 0, 12+len(data))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, kind...)
+	buf = append(buf, data...)
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf[4:]))
+	_, err := dst.Write(buf)
+	return err
+}
+
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// sanitizeWebP membuang chunk EXIF dan XMP dari WebP extended (VP8X) lalu
+// memperbarui flag VP8X dan ukuran RIFF. WebP sederhana (VP8/VP8L) tidak
+// memiliki metadata dan disalin apa adanya.
+func sanitizeWebP(dst io.Writer, content io.ReaderAt, size int64) error {
+	var header [12]byte
+	if _, err := content.ReadAt(header[:], 0); err != nil || string(header[:4]) != "RIFF" || string(header[8:]) != "WEBP" {
+		return fmt.Errorf("%w: header RIFF WebP tidak ditemukan", ErrMalformed)
+	}
+	end := min(size, 8+int64(binary.LittleEndian.Uint32(header[4:8])))
+
+	var chunks []chunkSpan
+	orientation := 0
+	for offset := int64(12); offset+8 <= end; {
+		var chunkHeader [8]byte
+		if _, err := content.ReadAt(chunkHeader[:], offset); err != nil {
+			return fmt.Errorf("%w: chunk WebP terpotong", ErrMalformed)
+		}
+		length := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+		chunk := chunkSpan{kind: string(chunkHeader[:4]), offset: offset, length: 8 + length + length%2}
+		if offset+8+length > end {
+			return fmt.Errorf("%w: chunk WebP %q terpotong", ErrMalformed, chunk.kind)
+		}
+		chunk.length = min(chunk.length, end-offset)
+		if chunk.kind == "EXIF" && length <= 1<<20 {
+			data := make([]byte, length)
+			if _, err := content.ReadAt(data, offset+8); err != nil {
+				return err
+			}
+			orientation = exifOrientation(data)
+		}
+		chunks = append(chunks, chunk)
+		offset += chunk.length
+	}
+	if len(chunks) == 0 || chunks[0].kind != "VP8X" {
+		_, err := io.Copy(dst, io.NewSectionReader(content, 0, size))
+		return err
+	}
+
+	var exif []byte
+	if keepsOrientation(orientation) {
+		exif = orientationOnlyEXIF(orientation)
+	}
+	riffSize := int64(4)
+	for _, chunk := range chunks {
+		if chunk.kind != "EXIF" && chunk.kind != "XMP " {
+			riffSize += chunk.length
+		}
+	}
+	if exif != nil {
+		riffSize += 8 + int64(len(exif))
+	}
+
+	out := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(out[4:], uint32(riffSize))
+	out = append(out, "WEBP"...)
+	if _, err := dst.Write(out); err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		switch chunk.kind {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			data := make([]byte, chunk.length)
+			if _, err := content.ReadAt(data, chunk.offset); err != nil {
+				return err
+			}
+			if len(data) > 8 {
+				data[8] &^= webpFlagEXIF | webpFlagXMP
+				if exif != nil {
+					data[8] |= webpFlagEXIF
+				}
+			}
+			if _, err := dst.Write(data); err != nil {
+				return err
+			}
+		default:
+			if _, err := io.Copy(dst, io.NewSectionReader(content, chunk.offset, chunk.length)); err != nil {
+				return err
+			}
+		}
+	}
+	if exif != nil {
+		// Chunk EXIF ditulis setelah data gambar sesuai urutan spesifikasi.
+		exifChunk := append([]byte("EXIF"), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(exifChunk[4:], uint32(len(exif)))
+		if _, err := dst.Write(append(exifChunk, exif...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
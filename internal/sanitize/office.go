@@ -0,0 +1,32 @@
+package sanitize
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/ooxml"
+)
+
+// ooxmlProperties adalah isi pengganti bagian properti dokumen OOXML. Bagian
+// tetap ada (dengan elemen akar kosong) agar relasi dan [Content_Types].xml
+// tidak perlu diubah.
+var ooxmlProperties = map[string]string{
+	"docprops/core.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:dcmitype="http://purl.org/dc/dcmitype/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"/>`,
+	"docprops/app.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"/>`,
+	"docprops/custom.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"/>`,
+}
+
+// sanitizeOOXML menulis ulang arsip DOCX/XLSX/PPTX dengan properti inti
+// (penulis, tanggal, revisi), properti aplikasi (perusahaan, manajer) dan
+// properti kustom yang dikosongkan. Entri lain disalin tanpa dikompresi ulang
+// dengan urutan yang sama.
+func sanitizeOOXML(dst io.Writer, content io.ReaderAt, size int64) error {
+	pkg, err := ooxml.Open(content, size)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return pkg.Rewrite(dst, ooxmlProperties)
+}
@@ -0,0 +1,54 @@
+package sanitize
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeOOXML(t *testing.T) {
+	entries := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`},
+		{"_rels/.rels", `<Relationships/>`},
+		{"word/document.xml", `<w:document>isi dokumen</w:document>`},
+		{"docProps/core.xml", `<cp:coreProperties><dc:creator>` + secret + `</dc:creator></cp:coreProperties>`},
+		{"docProps/app.xml", `<Properties><Company>` + secret + `</Company></Properties>`},
+		{"docProps/custom.xml", `<Properties><property name="klien">` + secret + `</property></Properties>`},
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		f, err := w.Create(e.name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	out := sanitizeBytes(t, buf.Bytes())
+
+	r, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+	require.Len(t, r.File, len(entries))
+	for i, f := range r.File {
+		assert.Equal(t, entries[i].name, f.Name, "Urutan entri dipertahankan")
+		rc, err := f.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		assert.NotContains(t, string(body), secret)
+		if f.Name == "word/document.xml" {
+			assert.Equal(t, entries[i].body, string(body))
+		}
+	}
+}
+
+func TestSanitizeOOXML_Malformed(t *testing.T) {
+	content := []byte("PK\x03\x04bukan arsip")
+	assert.ErrorIs(t, sanitizeOOXML(io.Discard, bytes.NewReader(content), int64(len(content))), ErrMalformed)
+}
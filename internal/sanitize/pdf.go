@@ -0,0 +1,254 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/adler32"
+	"io"
+	"strconv"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+)
+
+// sanitizePDF mengosongkan metadata dokumen PDF tanpa mengubah offset byte
+// objek, sehingga tabel xref tetap berlaku:
+//   - string di kamus Info (Title, Author, Creator, Producer, tanggal, ...)
+//     diganti spasi di antara pembatasnya;
+//   - isi stream XMP (/Type /Metadata) diganti spasi, atau stream Flate
+//     berisi spasi dengan panjang yang sama jika terkompresi.
+//
+// PDF terenkripsi, kamus Info di dalam object stream terkompresi dan stream
+// XMP dengan filter selain FlateDecode ditolak dengan ErrUnsupported karena
+// tidak dapat dibersihkan di tempat.
+func sanitizePDF(dst io.Writer, content io.ReaderAt, size int64) error {
+	refs := pdfInfoRefs{objects: map[int]bool{}}
+	if err := lexPDF(content, size, refs.observe); err != nil {
+		return err
+	}
+	if refs.encrypted {
+		return fmt.Errorf("%w: PDF terenkripsi", ErrUnsupported)
+	}
+
+	collector := pdfEditCollector{info: refs.objects, found: map[int]bool{}}
+	if err := lexPDF(content, size, collector.observe); err != nil {
+		return err
+	}
+	if collector.unsupported {
+		return fmt.Errorf("%w: filter stream metadata PDF tidak didukung", ErrUnsupported)
+	}
+	for object := range refs.objects {
+		if !collector.found[object] {
+			return fmt.Errorf("%w: kamus Info PDF berada di object stream terkompresi", ErrUnsupported)
+		}
+	}
+	return writeWithEdits(dst, content, size, collector.edits)
+}
+
+// lexPDF memecah content menjadi token dengan pdf.Lexer dan memanggil
+// observe untuk setiap token. Data stream dilewati sampai endstream.
+func lexPDF(content io.ReaderAt, size int64, observe func(pdf.Token)) error {
+	lexer := pdf.NewLexer(io.NewSectionReader(content, 0, size))
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, pdf.ErrMalformed) {
+			return fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		if err != nil {
+			return err
+		}
+		observe(token)
+	}
+}
+
+// pdfInfoRefs mencatat nomor objek kamus Info dari trailer atau kamus xref
+// stream (/Info N G R) dan keberadaan /Encrypt.
+type pdfInfoRefs struct {
+	objects   map[int]bool
+	encrypted bool
+	recent    []pdf.Token
+}
+
+func (r *pdfInfoRefs) observe(token pdf.Token) {
+	if token.Kind == pdf.TokenName && token.Text == "Encrypt" {
+		r.encrypted = true
+	}
+	r.recent = append(r.recent, token)
+	if len(r.recent) > 4 {
+		r.recent = r.recent[1:]
+	}
+	if len(r.recent) == 4 && r.recent[0].Kind == pdf.TokenName && r.recent[0].Text == "Info" && isReference(r.recent[1:]) {
+		if object, err := strconv.Atoi(r.recent[1].Text); err == nil {
+			r.objects[object] = true
+		}
+	}
+}
+
+// isReference melaporkan apakah tiga token membentuk referensi "N G R".
+func isReference(tokens []pdf.Token) bool {
+	return len(tokens) == 3 && isInteger(tokens[0]) && isInteger(tokens[1]) &&
+		tokens[2].Kind == pdf.TokenKeyword && tokens[2].Text == "R"
+}
+
+func isInteger(token pdf.Token) bool {
+	if token.Kind != pdf.TokenKeyword || token.Text == "" {
+		return false
+	}
+	_, err := strconv.Atoi(token.Text)
+	return err == nil
+}
+
+// pdfEdit mengganti rentang [start, end) content. Jika flate true, rentang
+// diganti stream zlib berisi spasi dengan panjang yang sama; jika tidak,
+// setiap byte diganti spasi.
+type pdfEdit struct {
+	start, end int64
+	flate      bool
+}
+
+// pdfEditCollector mengumpulkan rentang yang harus dikosongkan: string di
+// objek Info dan data stream metadata.
+type pdfEditCollector struct {
+	info  map[int]bool
+	found map[int]bool
+	edits []pdfEdit
+
+	object   int
+	inObject bool
+	recent   []pdf.Token
+	// metadata menandai objek saat ini adalah stream /Type /Metadata.
+	metadata bool
+	// filters adalah filter stream objek saat ini; filterArray menandai
+	// daftar /Filter [...] yang sedang dibaca.
+	filters      []string
+	filterArray  bool
+	decodeParams bool
+	// unsupported menandai stream metadata yang filternya tidak dapat
+	// diganti di tempat.
+	unsupported bool
+}
+
+func (c *pdfEditCollector) observe(token pdf.Token) {
+	var previous pdf.Token
+	if n := len(c.recent); n > 0 {
+		previous = c.recent[n-1]
+	}
+	switch {
+	case token.Kind == pdf.TokenKeyword && token.Text == "obj":
+		if n := len(c.recent); n >= 2 && isInteger(c.recent[n-2]) && isInteger(c.recent[n-1]) {
+			c.object, _ = strconv.Atoi(c.recent[n-2].Text)
+			c.inObject = true
+			c.metadata, c.filters, c.filterArray, c.decodeParams = false, nil, false, false
+			if c.info[c.object] {
+				c.found[c.object] = true
+			}
+		}
+	case token.Kind == pdf.TokenKeyword && token.Text == "endobj":
+		c.inObject = false
+	case !c.inObject:
+	case token.Kind == pdf.TokenName:
+		switch {
+		case c.filterArray:
+			c.filters = append(c.filters, token.Text)
+		case previous.Kind == pdf.TokenName && previous.Text == "Type" && token.Text == "Metadata":
+			c.metadata = true
+		case previous.Kind == pdf.TokenName && previous.Text == "Filter":
+			c.filters = []string{token.Text}
+		case token.Text == "DecodeParms":
+			c.decodeParams = true
+		}
+	case token.Kind == pdf.TokenDelimiter && token.Text == "[":
+		c.filterArray = previous.Kind == pdf.TokenName && previous.Text == "Filter"
+	case token.Kind == pdf.TokenDelimiter && token.Text == "]":
+		c.filterArray = false
+	case token.Kind == pdf.TokenString && c.info[c.object]:
+		c.edits = append(c.edits, pdfEdit{start: token.Start, end: token.End})
+	case token.Kind == pdf.TokenEndStream && c.metadata:
+		c.addMetadataEdit(token)
+	}
+	c.recent = append(c.recent, token)
+	if len(c.recent) > 3 {
+		c.recent = c.recent[1:]
+	}
+}
+
+// addMetadataEdit mengosongkan data stream XMP. Stream tanpa filter diganti
+// spasi; stream FlateDecode tanpa DecodeParms diganti stream zlib berisi
+// spasi. Filter lain tidak dapat diganti dengan panjang yang sama.
+func (c *pdfEditCollector) addMetadataEdit(token pdf.Token) {
+	switch {
+	case len(c.filters) == 0:
+		c.edits = append(c.edits, pdfEdit{start: token.Start, end: token.End})
+	case len(c.filters) == 1 && !c.decodeParams && (c.filters[0] == "FlateDecode" || c.filters[0] == "Fl"):
+		if token.End-token.Start >= minStoredZlib {
+			c.edits = append(c.edits, pdfEdit{start: token.Start, end: token.End, flate: true})
+		} else {
+			c.unsupported = true
+		}
+	default:
+		c.unsupported = true
+	}
+}
+
+// writeWithEdits menyalin content ke dst sambil menerapkan edits, yang
+// terurut sesuai posisi dan tidak saling tumpang tindih.
+func writeWithEdits(dst io.Writer, content io.ReaderAt, size int64, edits []pdfEdit) error {
+	offset := int64(0)
+	for _, edit := range edits {
+		if _, err := io.Copy(dst, io.NewSectionReader(content, offset, edit.start-offset)); err != nil {
+			return err
+		}
+		length := edit.end - edit.start
+		var replacement []byte
+		if edit.flate {
+			replacement = storedZlibSpaces(length)
+		} else {
+			replacement = bytes.Repeat([]byte{' '}, int(length))
+		}
+		if _, err := dst.Write(replacement); err != nil {
+			return err
+		}
+		offset = edit.end
+	}
+	_, err := io.Copy(dst, io.NewSectionReader(content, offset, size-offset))
+	return err
+}
+
+const (
+	// minStoredZlib adalah panjang stream zlib terkecil: header 2 byte, satu
+	// blok stored tanpa isi (5 byte) dan checksum Adler-32 (4 byte).
+	minStoredZlib  = 11
+	maxStoredBlock = 65535
+)
+
+// storedZlibSpaces membuat stream zlib tanpa kompresi (blok stored) berisi
+// spasi dengan panjang total tepat length byte.
+func storedZlibSpaces(length int64) []byte {
+	blocks := int64(1)
+	for 6+5*blocks+maxStoredBlock*blocks < length {
+		blocks++
+	}
+	payload := length - 6 - 5*blocks
+	out := make([]byte, 0, length)
+	out = append(out, 0x78, 0x01)
+	spaces := bytes.Repeat([]byte{' '}, maxStoredBlock)
+	checksum := adler32.New()
+	for i := int64(0); i < blocks; i++ {
+		n := min(payload, maxStoredBlock)
+		payload -= n
+		final := byte(0)
+		if i == blocks-1 {
+			final = 1
+		}
+		out = append(out, final)
+		out = binary.LittleEndian.AppendUint16(out, uint16(n))
+		out = binary.LittleEndian.AppendUint16(out, ^uint16(n))
+		out = append(out, spaces[:n]...)
+		_, _ = checksum.Write(spaces[:n])
+	}
+	return binary.BigEndian.AppendUint32(out, checksum.Sum32())
+}
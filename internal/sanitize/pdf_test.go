@@ -0,0 +1,98 @@
+package sanitize
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pdfWithObjects menyusun PDF dengan objects dan trailer yang merujuk Info
+// ke objek 3.
+func pdfWithObjects(objects ...string) []byte {
+	return []byte("%PDF-1.7\n" + strings.Join(objects, "") +
+		"trailer\n<< /Root 1 0 R /Info 3 0 R /Size 5 >>\nstartxref\n9\n%%EOF\n")
+}
+
+func metadataStream(t *testing.T, compress bool) string {
+	t.Helper()
+	xmp := "<x:xmpmeta><dc:creator>" + secret + "</dc:creator></x:xmpmeta>"
+	if !compress {
+		return fmt.Sprintf("4 0 obj\n<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(xmp), xmp)
+	}
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write([]byte(xmp))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return fmt.Sprintf("4 0 obj\n<< /Type /Metadata /Subtype /XML /Filter [/FlateDecode] /Length %d >>\nstream\r\n%s\r\nendstream\nendobj\n", buf.Len(), buf.String())
+}
+
+const (
+	pdfCatalog = "1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Metadata 4 0 R >>\nendobj\n"
+	pdfPages   = "2 0 obj\n<< /Type /Pages /Kids [] /Count 0 /Title (Outline tetap) >>\nendobj\n"
+	pdfInfo    = "3 0 obj\n<< /Author (" + secret + " \\) (nested)) /Producer <4750532D534543524554> /Trapped /False >>\nendobj\n"
+)
+
+func TestSanitizePDF(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compressed metadata %t", compress), func(t *testing.T) {
+			content := pdfWithObjects(pdfCatalog, pdfPages, pdfInfo, metadataStream(t, compress))
+
+			out := sanitizeBytes(t, content)
+
+			require.Len(t, out, len(content), "Offset objek harus tetap sama")
+			assert.NotContains(t, string(out), secret)
+			assert.NotContains(t, string(out), "4750532D534543524554")
+			assert.Contains(t, string(out), "/Author (")
+			assert.Contains(t, string(out), "(Outline tetap)", "String di luar kamus Info tidak diubah")
+			assert.Contains(t, string(out), "/Trapped /False")
+			assert.Equal(t, content[:bytes.Index(content, []byte("3 0 obj"))], out[:bytes.Index(out, []byte("3 0 obj"))])
+
+			if compress {
+				start := bytes.Index(out, []byte("stream\r\n")) + len("stream\r\n")
+				end := bytes.Index(out, []byte("\r\nendstream"))
+				zr, err := zlib.NewReader(bytes.NewReader(out[start:end]))
+				require.NoError(t, err)
+				inflated, err := io.ReadAll(zr)
+				require.NoError(t, err, "Stream pengganti harus zlib yang valid")
+				assert.Empty(t, strings.TrimSpace(string(inflated)))
+			}
+		})
+	}
+}
+
+func TestSanitizePDF_Unsupported(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "Encrypted document", content: []byte("%PDF-1.7\n" + pdfCatalog + "trailer\n<< /Root 1 0 R /Encrypt 9 0 R >>\n%%EOF\n")},
+		{name: "Info inside object stream", content: pdfWithObjects(pdfCatalog, pdfPages)},
+		{name: "Metadata with unsupported filter", content: pdfWithObjects(pdfCatalog, pdfInfo, "4 0 obj\n<< /Type /Metadata /Filter /ASCIIHexDecode /Length 4 >>\nstream\nABCD\nendstream\nendobj\n")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := sanitizePDF(io.Discard, bytes.NewReader(tc.content), int64(len(tc.content)))
+			assert.ErrorIs(t, err, ErrUnsupported)
+		})
+	}
+}
+
+func TestStoredZlibSpaces(t *testing.T) {
+	for _, length := range []int64{11, 12, 1000, 65546, 65547, 200000} {
+		data := storedZlibSpaces(length)
+		require.Len(t, data, int(length))
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		inflated, err := io.ReadAll(zr)
+		require.NoError(t, err, "panjang %d", length)
+		assert.Empty(t, strings.TrimSpace(string(inflated)))
+	}
+}
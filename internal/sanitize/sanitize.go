@@ -0,0 +1,66 @@
+// Package sanitize menghapus metadata yang dapat membocorkan informasi
+// pribadi dari file yang diunggah: EXIF, XMP dan IPTC pada gambar (lokasi GPS,
+// kamera, waktu), serta informasi dokumen pada PDF dan Office (penulis,
+// perusahaan, riwayat). Isi visual file tidak diubah; orientasi gambar tetap
+// dipertahankan.
+package sanitize
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	// ErrUnsupported dikembalikan untuk tipe file yang tidak memiliki
+	// sanitizer atau struktur yang tidak dapat dibersihkan tanpa menulis ulang
+	// seluruh file (mis. PDF terenkripsi).
+	ErrUnsupported = errors.New("metadata file tidak dapat dibersihkan")
+	// ErrMalformed dikembalikan jika struktur file rusak.
+	ErrMalformed = errors.New("struktur file tidak valid")
+)
+
+// Sanitizer menulis salinan content tanpa metadata ke dst.
+type Sanitizer func(dst io.Writer, content io.ReaderAt, size int64) error
+
+// sanitizers dipilih berdasarkan tipe MIME hasil deteksi atau leluhurnya.
+var sanitizers = []struct {
+	mimeType string
+	sanitize Sanitizer
+}{
+	{"image/jpeg", sanitizeJPEG},
+	{"image/png", sanitizePNG},
+	{"image/webp", sanitizeWebP},
+	{"application/pdf", sanitizePDF},
+	{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", sanitizeOOXML},
+	{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", sanitizeOOXML},
+	{"application/vnd.openxmlformats-officedocument.presentationml.presentation", sanitizeOOXML},
+}
+
+// Supports melaporkan apakah metadata file bertipe detected dapat dibersihkan.
+func Supports(detected *mimetype.MIME) bool {
+	return sanitizerFor(detected) != nil
+}
+
+// Sanitize menulis salinan content (size byte dari offset 0) tanpa metadata ke
+// dst. Error yang membungkus ErrMalformed atau ErrUnsupported disebabkan isi
+// file; error lain berasal dari content atau dst.
+func Sanitize(dst io.Writer, content io.ReaderAt, size int64, detected *mimetype.MIME) error {
+	sanitize := sanitizerFor(detected)
+	if sanitize == nil {
+		return ErrUnsupported
+	}
+	return sanitize(dst, content, size)
+}
+
+func sanitizerFor(detected *mimetype.MIME) Sanitizer {
+	for m := detected; m != nil; m = m.Parent() {
+		for _, s := range sanitizers {
+			if m.Is(s.mimeType) {
+				return s.sanitize
+			}
+		}
+	}
+	return nil
+}
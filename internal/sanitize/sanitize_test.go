@@ -0,0 +1,180 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "GPS-SECRET"
+
+// tiffWithOrientation membuat data EXIF little-endian berisi Orientation dan
+// tag Make berisi secret.
+func tiffWithOrientation(orientation int) []byte {
+	tiff := []byte("II\x2a\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	// Make (ASCII) menunjuk ke string setelah IFD.
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x010F)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(len(secret)+1))
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8+2+2*12+4)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	return append(tiff, secret+"\x00"...)
+}
+
+func jpegSegment(marker byte, data []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(data)+2))
+	return append(segment, data...)
+}
+
+func sanitizeBytes(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, Sanitize(&out, bytes.NewReader(content), int64(len(content)), mimetype.Detect(content)))
+	return out.Bytes()
+}
+
+func TestExifOrientation(t *testing.T) {
+	assert.Equal(t, 6, exifOrientation(tiffWithOrientation(6)))
+	assert.Equal(t, 6, exifOrientation(append([]byte("Exif\x00\x00"), tiffWithOrientation(6)...)))
+	assert.Equal(t, 3, exifOrientation(orientationOnlyEXIF(3)))
+	assert.Equal(t, 0, exifOrientation(tiffWithOrientation(9)))
+	assert.Equal(t, 0, exifOrientation([]byte("MM\x00\x2a\x00\x00\x00\xff")))
+	assert.Equal(t, 0, exifOrientation(nil))
+}
+
+func TestSanitizeJPEG(t *testing.T) {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 8)), nil))
+	original := encoded.Bytes()
+
+	var content []byte
+	content = append(content, original[:2]...)
+	content = append(content, jpegSegment(0xE1, append([]byte("Exif\x00\x00"), tiffWithOrientation(6)...))...)
+	content = append(content, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>"+secret+"</x:xmpmeta>"))...)
+	content = append(content, jpegSegment(0xED, []byte("Photoshop 3.0\x008BIM"+secret))...)
+	content = append(content, jpegSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01profile"))...)
+	content = append(content, jpegSegment(0xFE, []byte(secret))...)
+	content = append(content, original[2:]...)
+
+	out := sanitizeBytes(t, content)
+
+	assert.NotContains(t, string(out), secret)
+	assert.Contains(t, string(out), "ICC_PROFILE", "Profil warna dipertahankan")
+	_, err := jpeg.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+
+	exifStart := bytes.Index(out, []byte("Exif\x00\x00"))
+	require.Positive(t, exifStart, "Orientasi dipertahankan dalam EXIF minimal")
+	assert.Equal(t, 6, exifOrientation(out[exifStart:exifStart+6+26]))
+}
+
+func TestSanitizeJPEG_WithoutOrientation(t *testing.T) {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 8)), nil))
+	original := encoded.Bytes()
+	content := append(append(append([]byte{}, original[:2]...), jpegSegment(0xE1, append([]byte("Exif\x00\x00"), tiffWithOrientation(1)...))...), original[2:]...)
+
+	out := sanitizeBytes(t, content)
+
+	assert.NotContains(t, string(out), "Exif")
+	assert.Equal(t, original, out, "Tanpa metadata, hasilnya sama dengan hasil encoder")
+}
+
+func TestSanitizePNG(t *testing.T) {
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 4, 4))))
+	original := encoded.Bytes()
+	idat := bytes.Index(original, []byte("IDAT")) - 4
+
+	var metadata bytes.Buffer
+	require.NoError(t, writePNGChunk(&metadata, "tEXt", []byte("Author\x00"+secret)))
+	require.NoError(t, writePNGChunk(&metadata, "iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"+secret)))
+	require.NoError(t, writePNGChunk(&metadata, "eXIf", tiffWithOrientation(3)))
+	content := append(append(append([]byte{}, original[:idat]...), metadata.Bytes()...), original[idat:]...)
+
+	out := sanitizeBytes(t, content)
+
+	assert.NotContains(t, string(out), secret)
+	decoded, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 4, 4), decoded.Bounds())
+
+	exif := bytes.Index(out, []byte("eXIf"))
+	require.Positive(t, exif)
+	assert.Less(t, exif, bytes.Index(out, []byte("IDAT")), "eXIf harus sebelum IDAT")
+	assert.Equal(t, 3, exifOrientation(out[exif+4:exif+4+26]))
+}
+
+func riffChunk(kind string, data []byte) []byte {
+	chunk := append([]byte(kind), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+	out := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+	return append(out, body...)
+}
+
+func TestSanitizeWebP(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpFlagEXIF | webpFlagXMP
+	content := webpFile(
+		riffChunk("VP8X", vp8x),
+		riffChunk("VP8L", []byte("\x2f\x00\x00\x00\x00")),
+		riffChunk("EXIF", tiffWithOrientation(8)),
+		riffChunk("XMP ", []byte("<x:xmpmeta>"+secret+"</x:xmpmeta>")),
+	)
+
+	out := sanitizeBytes(t, content)
+
+	assert.NotContains(t, string(out), secret)
+	assert.Equal(t, uint32(len(out)-8), binary.LittleEndian.Uint32(out[4:8]), "Ukuran RIFF diperbarui")
+	assert.Equal(t, byte(webpFlagEXIF), out[20], "Flag XMP dihapus, flag EXIF untuk orientasi dipertahankan")
+	assert.Contains(t, string(out), "VP8L")
+	exif := bytes.Index(out, []byte("EXIF"))
+	require.Positive(t, exif)
+	assert.Equal(t, 8, exifOrientation(out[exif+8:]))
+}
+
+func TestSanitizeWebP_Simple(t *testing.T) {
+	content := webpFile(riffChunk("VP8L", []byte("\x2f\x00\x00\x00\x00")))
+	assert.Equal(t, content, sanitizeBytes(t, content))
+}
+
+func TestSanitize_Errors(t *testing.T) {
+	var out bytes.Buffer
+	text := []byte("hanya teks")
+	assert.False(t, Supports(mimetype.Detect(text)))
+	assert.ErrorIs(t, Sanitize(&out, bytes.NewReader(text), int64(len(text)), mimetype.Detect(text)), ErrUnsupported)
+
+	truncated := []byte("\xff\xd8\xff\xe1\x00\x40Exif")
+	assert.ErrorIs(t, sanitizeJPEG(&out, bytes.NewReader(truncated), int64(len(truncated))), ErrMalformed)
+
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 1, 1))))
+	brokenPNG := encoded.Bytes()[:20]
+	assert.ErrorIs(t, sanitizePNG(&out, bytes.NewReader(brokenPNG), int64(len(brokenPNG))), ErrMalformed)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidAccessRule menandai pola tag atau nama peran yang tidak valid.
+var ErrInvalidAccessRule = errors.New("aturan akses tidak valid")
+
+// AccessDecision adalah keputusan kebijakan aktif untuk satu subjek: pemilik
+// file (UserID) atau satu peran (Role).
+type AccessDecision struct {
+	UserID  string `json:"user_id,omitempty"`
+	Role    string `json:"role,omitempty"`
+	Allowed bool   `json:"allowed"`
+	// Policy adalah nama kebijakan yang menentukan keputusan, atau
+	// policy.DefaultDenyPolicy jika tidak ada yang cocok.
+	Policy string `json:"policy"`
+}
+
+// AccessExplanation menjelaskan siapa saja yang dapat mengunduh sebuah file
+// menurut kebijakan otorisasi yang sama dengan GetFileMetadata.
+type AccessExplanation struct {
+	FileID    string           `json:"file_id"`
+	Tags      []string         `json:"tags"`
+	Decisions []AccessDecision `json:"decisions"`
+	// Role, Allowed, dan Policy hanya diisi jika penjelasan diminta untuk
+	// satu peran.
+	Role    string `json:"role,omitempty"`
+	Allowed *bool  `json:"allowed,omitempty"`
+	Policy  string `json:"policy,omitempty"`
+}
+
+type AccessRuleService interface {
+	ListRules(ctx context.Context) ([]model.AccessRule, error)
+	CreateRule(ctx context.Context, rule model.AccessRule, actorID string) error
+	DeleteRule(ctx context.Context, rule model.AccessRule, actorID string) error
+	ExplainAccess(ctx context.Context, fileID string, role string) (*AccessExplanation, error)
+}
+
+type accessRuleService struct {
+	rules    repository.AccessRuleRepository
+	files    repository.FileRepository
+	policies *policy.Engine
+}
+
+// NewAccessRuleService membuat AccessRuleService. policies harus engine yang
+// sama dengan FileService agar penjelasan akses sesuai keputusan sebenarnya;
+// jika nil, dipakai kebijakan bawaan.
+func NewAccessRuleService(rules repository.AccessRuleRepository, files repository.FileRepository, policies *policy.Engine) AccessRuleService {
+	if policies == nil {
+		policies = policy.NewDefaultEngine()
+	}
+	return &accessRuleService{rules: rules, files: files, policies: policies}
+}
+
+func (s *accessRuleService) ListRules(ctx context.Context) ([]model.AccessRule, error) {
+	return s.rules.ListRules(ctx)
+}
+
+func (s *accessRuleService) CreateRule(ctx context.Context, rule model.AccessRule, actorID string) error {
+	rule, err := normalizeAccessRule(rule)
+	if err != nil {
+		return err
+	}
+	if err := s.rules.CreateRule(ctx, rule); err != nil {
+		return err
+	}
+	log.Info().Ctx(ctx).Str("actor_user_id", actorID).Str("tag", rule.TagName).Str("role", rule.RoleName).Msg("Aturan akses file dibuat")
+	return nil
+}
+
+func (s *accessRuleService) DeleteRule(ctx context.Context, rule model.AccessRule, actorID string) error {
+	rule, err := normalizeAccessRule(rule)
+	if err != nil {
+		return err
+	}
+	if err := s.rules.DeleteRule(ctx, rule); err != nil {
+		return err
+	}
+	log.Info().Ctx(ctx).Str("actor_user_id", actorID).Str("tag", rule.TagName).Str("role", rule.RoleName).Msg("Aturan akses file dihapus")
+	return nil
+}
+
+// ExplainAccess mengevaluasi kebijakan aktif untuk pemilik file, peran admin,
+// setiap peran yang disebut aturan akses, dan role jika diisi. Kebijakan yang
+// bergantung pada request (IP, waktu) dievaluasi dengan request pemanggil.
+// ExplainAccess tidak memeriksa hak pemanggil; endpoint-nya khusus admin.
+func (s *accessRuleService) ExplainAccess(ctx context.Context, fileID string, role string) (*AccessExplanation, error) {
+	metadata, err := s.files.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.rules.ListRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memuat aturan akses: %w", err)
+	}
+
+	explanation := &AccessExplanation{FileID: metadata.ID, Tags: metadata.Tags, Decisions: []AccessDecision{}}
+	if metadata.OwnerUserID != nil {
+		decision := s.evaluate(ctx, metadata, rules, jwt.MapClaims{"sub": *metadata.OwnerUserID})
+		explanation.Decisions = append(explanation.Decisions, AccessDecision{UserID: *metadata.OwnerUserID, Allowed: decision.Allowed, Policy: decision.Policy})
+	}
+
+	roles := []string{"admin"}
+	for _, rule := range rules {
+		if !slices.Contains(roles, rule.RoleName) {
+			roles = append(roles, rule.RoleName)
+		}
+	}
+	if role != "" && !slices.Contains(roles, role) {
+		roles = append(roles, role)
+	}
+	for _, r := range roles {
+		decision := s.evaluate(ctx, metadata, rules, jwt.MapClaims{"role": r})
+		explanation.Decisions = append(explanation.Decisions, AccessDecision{Role: r, Allowed: decision.Allowed, Policy: decision.Policy})
+		if r == role {
+			explanation.Role = role
+			explanation.Allowed = &decision.Allowed
+			explanation.Policy = decision.Policy
+		}
+	}
+	return explanation, nil
+}
+
+func (s *accessRuleService) evaluate(ctx context.Context, metadata *model.FileMetadata, rules []model.AccessRule, subject jwt.MapClaims) policy.Decision {
+	request := policy.RequestFromContext(ctx)
+	request.Action = policy.ActionRead
+	role, _ := subject["role"].(string)
+	decision := s.policies.Evaluate(ctx, policy.Input{
+		Subject: subject,
+		File:    policyFile(metadata),
+		Request: request,
+		TagAccess: func(context.Context) (bool, error) {
+			return model.RoleHasTagAccess(rules, role, metadata.Tags), nil
+		},
+	})
+	for _, err := range decision.Errors {
+		log.Error().Ctx(ctx).Err(err).Str("file_id", metadata.ID).Str("role", role).Msg("Gagal mengevaluasi kebijakan otorisasi")
+	}
+	return decision
+}
+
+func normalizeAccessRule(rule model.AccessRule) (model.AccessRule, error) {
+	rule.TagName = strings.TrimSpace(rule.TagName)
+	rule.RoleName = strings.TrimSpace(rule.RoleName)
+	if rule.RoleName == "" {
+		return rule, fmt.Errorf("%w: role_name wajib diisi", ErrInvalidAccessRule)
+	}
+	if !rule.IsValidPattern() {
+		return rule, fmt.Errorf("%w: pola tag '%s' tidak valid; wildcard hanya boleh '*' atau akhiran '/*'", ErrInvalidAccessRule, rule.TagName)
+	}
+	return rule, nil
+}
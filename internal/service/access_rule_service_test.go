@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAccessRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccessRuleRepository) ListRules(ctx context.Context) ([]model.AccessRule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AccessRule), args.Error(1)
+}
+
+func (m *MockAccessRuleRepository) CreateRule(ctx context.Context, rule model.AccessRule) error {
+	return m.Called(ctx, rule).Error(0)
+}
+
+func (m *MockAccessRuleRepository) DeleteRule(ctx context.Context, rule model.AccessRule) error {
+	return m.Called(ctx, rule).Error(0)
+}
+
+func TestAccessRuleService_CreateRule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Pattern is trimmed and stored", func(t *testing.T) {
+		mockRules := new(MockAccessRuleRepository)
+		mockRules.On("CreateRule", ctx, model.AccessRule{TagName: "keuangan/*", RoleName: "finance"}).Return(nil).Once()
+
+		svc := NewAccessRuleService(mockRules, nil, nil)
+		require.NoError(t, svc.CreateRule(ctx, model.AccessRule{TagName: " keuangan/* ", RoleName: "finance"}, "admin-1"))
+		mockRules.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Wildcard in the middle", func(t *testing.T) {
+		svc := NewAccessRuleService(new(MockAccessRuleRepository), nil, nil)
+		err := svc.CreateRule(ctx, model.AccessRule{TagName: "keuangan/*/pajak", RoleName: "finance"}, "admin-1")
+		assert.ErrorIs(t, err, ErrInvalidAccessRule)
+	})
+}
+
+func TestAccessRuleService_ExplainAccess(t *testing.T) {
+	ctx := context.Background()
+	ownerID := "user-owner-1"
+	fileID := "file-abc-123"
+	file := &model.FileMetadata{ID: fileID, OwnerUserID: &ownerID, MimeType: "application/pdf", Tags: []string{"keuangan/pajak"}}
+	rules := []model.AccessRule{
+		{TagName: "keuangan/*", RoleName: "finance"},
+		{TagName: "hr", RoleName: "hr"},
+	}
+
+	newService := func(policies *policy.Engine) AccessRuleService {
+		mockRepo := new(MockFileRepository)
+		mockRepo.On("GetByID", ctx, fileID).Return(file, nil)
+		mockRules := new(MockAccessRuleRepository)
+		mockRules.On("ListRules", ctx).Return(rules, nil)
+		return NewAccessRuleService(mockRules, mockRepo, policies)
+	}
+
+	t.Run("Success - Default policies decide each subject", func(t *testing.T) {
+		svc := newService(nil)
+
+		explanation, err := svc.ExplainAccess(ctx, fileID, "")
+		require.NoError(t, err)
+		assert.Equal(t, []AccessDecision{
+			{UserID: ownerID, Allowed: true, Policy: "owner"},
+			{Role: "admin", Allowed: true, Policy: "admin"},
+			{Role: "finance", Allowed: true, Policy: "tag-rule"},
+			{Role: "hr", Allowed: false, Policy: policy.DefaultDenyPolicy},
+		}, explanation.Decisions)
+		assert.Nil(t, explanation.Allowed)
+
+		explanation, err = svc.ExplainAccess(ctx, fileID, "hr")
+		require.NoError(t, err)
+		require.NotNil(t, explanation.Allowed)
+		assert.False(t, *explanation.Allowed)
+		assert.Equal(t, policy.DefaultDenyPolicy, explanation.Policy)
+
+		explanation, err = svc.ExplainAccess(ctx, fileID, "marketing")
+		require.NoError(t, err)
+		assert.False(t, *explanation.Allowed)
+		assert.Equal(t, AccessDecision{Role: "marketing", Allowed: false, Policy: policy.DefaultDenyPolicy}, explanation.Decisions[len(explanation.Decisions)-1])
+	})
+
+	t.Run("Success - Custom deny policy is reported", func(t *testing.T) {
+		policies, err := policy.NewEngine(append([]policy.Policy{{
+			Name:       "no-pdf-for-finance",
+			Effect:     policy.EffectDeny,
+			Expression: `subject.role == "finance" && file.mime_type == "application/pdf"`,
+		}}, policy.DefaultPolicies()...))
+		require.NoError(t, err)
+		svc := newService(policies)
+
+		explanation, err := svc.ExplainAccess(ctx, fileID, "finance")
+		require.NoError(t, err)
+		assert.Equal(t, "finance", explanation.Role)
+		assert.False(t, *explanation.Allowed)
+		assert.Equal(t, "no-pdf-for-finance", explanation.Policy)
+	})
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/integrity"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/jobs"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxArchiveFiles adalah jumlah maksimum file dalam satu arsip ZIP.
+const MaxArchiveFiles = 1000
+
+const archiveManifestName = "manifest.csv"
+
+var (
+	ErrArchiveEmpty        = errors.New("arsip tidak berisi file apa pun")
+	ErrArchiveTooManyFiles = fmt.Errorf("arsip melebihi batas %d file", MaxArchiveFiles)
+	// ErrArchiveAsyncUnavailable dikembalikan untuk arsip asinkron saat
+	// antrean job dinonaktifkan (jobs_enabled=false).
+	ErrArchiveAsyncUnavailable = errors.New("arsip asinkron membutuhkan antrean job")
+)
+
+// ArchiveRequest menjelaskan file apa saja yang dimasukkan ke arsip ZIP.
+// FileIDs dan Tags boleh dipakai bersamaan; hasilnya digabung tanpa duplikat.
+type ArchiveRequest struct {
+	FileIDs  []string `json:"file_ids"`
+	Tags     []string `json:"tags"`
+	Name     string   `json:"name"`
+	Manifest bool     `json:"manifest"`
+	Async    bool     `json:"async"`
+}
+
+// ResolveArchiveFiles mengumpulkan metadata file untuk arsip. File yang diminta
+// lewat ID harus dapat diakses oleh pemanggil (jika tidak, seluruh permintaan
+// gagal), sedangkan hasil query tag yang tidak dapat diakses dilewati saja.
+func (s *fileService) ResolveArchiveFiles(ctx context.Context, req ArchiveRequest, claims jwt.MapClaims) ([]*model.FileMetadata, error) {
+	if len(req.FileIDs) == 0 && len(req.Tags) == 0 {
+		return nil, ErrArchiveEmpty
+	}
+	if len(req.FileIDs) > MaxArchiveFiles {
+		return nil, ErrArchiveTooManyFiles
+	}
+
+	seen := make(map[string]bool)
+	var files []*model.FileMetadata
+	for _, id := range req.FileIDs {
+		if seen[id] {
+			continue
+		}
+		metadata, err := s.GetFileMetadata(ctx, id, claims)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", id, err)
+		}
+		seen[id] = true
+		files = append(files, metadata)
+	}
+
+	if len(req.Tags) > 0 {
+		candidates, err := s.repo.List(ctx, repository.FileFilter{Tags: req.Tags, Limit: MaxArchiveFiles + 1})
+		if err != nil {
+			return nil, fmt.Errorf("gagal mencari file berdasarkan tag: %w", err)
+		}
+		if len(candidates) > MaxArchiveFiles {
+			return nil, ErrArchiveTooManyFiles
+		}
+		for _, metadata := range candidates {
+			if seen[metadata.ID] {
+				continue
+			}
+			if err := s.authorize(ctx, metadata, claims); err != nil {
+				continue
+			}
+			seen[metadata.ID] = true
+			files = append(files, metadata)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, ErrArchiveEmpty
+	}
+	if len(files) > MaxArchiveFiles {
+		return nil, ErrArchiveTooManyFiles
+	}
+	return files, nil
+}
+
+// WriteArchive menulis arsip ZIP langsung ke w, membaca tiap file dari storage
+// secara streaming tanpa menampung seluruh arsip di memori. Ekstensi ZIP64
+// dipakai otomatis oleh archive/zip untuk entri atau arsip di atas 4 GiB.
+// Jika terjadi error, central directory tidak ditulis sehingga klien menerima
+// arsip yang tidak valid alih-alih arsip yang diam-diam tidak lengkap.
+func (s *fileService) WriteArchive(ctx context.Context, w io.Writer, files []*model.FileMetadata, withManifest bool) error {
+	zw := zip.NewWriter(w)
+	names := map[string]bool{archiveManifestName: withManifest}
+	entryNames := make([]string, len(files))
+
+	for i, metadata := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entryNames[i] = uniqueArchiveName(names, metadata)
+		if err := s.writeArchiveEntry(ctx, zw, entryNames[i], metadata); err != nil {
+			return fmt.Errorf("gagal menulis file %s ke arsip: %w", metadata.ID, err)
+		}
+	}
+
+	if withManifest {
+		if err := writeArchiveManifest(zw, files, entryNames); err != nil {
+			return fmt.Errorf("gagal menulis manifest arsip: %w", err)
+		}
+	}
+	return zw.Close()
+}
+
+func (s *fileService) writeArchiveEntry(ctx context.Context, zw *zip.Writer, name string, metadata *model.FileMetadata) error {
+	entry, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   archiveCompressionMethod(metadata.MimeType),
+		Modified: metadata.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.openContent(ctx, metadata)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			log.Warn().Ctx(ctx).Err(closeErr).Str("file_id", metadata.ID).Msg("Gagal menutup file reader setelah diarsipkan")
+		}
+	}()
+
+	_, err = io.Copy(entry, reader)
+	return err
+}
+
+func writeArchiveManifest(zw *zip.Writer, files []*model.FileMetadata, entryNames []string) error {
+	entry, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     archiveManifestName,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(entry)
+	if err := cw.Write([]string{"path", "id", "original_name", "mime_type", "size_bytes", "owner_user_id", "created_at", "tags"}); err != nil {
+		return err
+	}
+	for i, metadata := range files {
+		owner := ""
+		if metadata.OwnerUserID != nil {
+			owner = *metadata.OwnerUserID
+		}
+		if err := cw.Write([]string{
+			entryNames[i],
+			metadata.ID,
+			metadata.OriginalName,
+			metadata.MimeType,
+			strconv.FormatInt(metadata.SizeBytes, 10),
+			owner,
+			metadata.CreatedAt.UTC().Format(time.RFC3339),
+			strings.Join(metadata.Tags, ";"),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// archiveJobPayload adalah payload job build_archive. ID job sama dengan ID
+// file arsip yang dihasilkan.
+type archiveJobPayload struct {
+	OwnerUserID string   `json:"owner_user_id"`
+	FileIDs     []string `json:"file_ids"`
+	Name        string   `json:"name"`
+	Manifest    bool     `json:"manifest"`
+}
+
+// CreateArchiveAsync mengantrekan job build_archive yang menyimpan arsip
+// sebagai file biasa milik ownerID. ID yang dikembalikan adalah ID job
+// sekaligus ID file arsip: statusnya dapat dipantau lewat
+// GET /files/archive/:id dan arsipnya diunduh lewat GET /files/:id setelah
+// job selesai. File sudah diotorisasi oleh ResolveArchiveFiles.
+func (s *fileService) CreateArchiveAsync(ctx context.Context, ownerID string, files []*model.FileMetadata, req ArchiveRequest) (string, error) {
+	if len(files) == 0 {
+		return "", ErrArchiveEmpty
+	}
+	if s.jobs == nil {
+		return "", ErrArchiveAsyncUnavailable
+	}
+	payload := archiveJobPayload{OwnerUserID: ownerID, Name: ArchiveFileName(req.Name, time.Now()), Manifest: req.Manifest}
+	for _, metadata := range files {
+		payload.FileIDs = append(payload.FileIDs, metadata.ID)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	job := &model.Job{ID: uuid.New().String(), Type: model.JobTypeBuildArchive, Payload: raw}
+	if err := s.jobs.Enqueue(ctx, job); err != nil {
+		return "", fmt.Errorf("gagal mengantrekan pembuatan arsip: %w", err)
+	}
+	return job.ID, nil
+}
+
+// BuildArchiveJob membuat handler job build_archive. File sumber yang sudah
+// dihapus sejak job diantrekan dilewati; job yang diulang setelah metadata
+// arsip tersimpan tidak membangun arsip kedua.
+func BuildArchiveJob(repo repository.FileRepository, store storage.Storage, cfg func() *fileserviceconfig.Config) jobs.Handler {
+	return func(ctx context.Context, job *model.Job) error {
+		var payload archiveJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return jobs.Permanent(fmt.Errorf("payload job build_archive tidak valid: %w", err))
+		}
+		if _, err := repo.GetByID(ctx, job.ID); err == nil {
+			return nil
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		files, err := repo.List(ctx, repository.FileFilter{IDs: payload.FileIDs})
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return jobs.Permanent(ErrArchiveEmpty)
+		}
+		s := &fileService{repo: repo, storage: store}
+		s.config.Store(cfg())
+		return s.buildArchive(ctx, job.ID, payload, files)
+	}
+}
+
+func (s *fileService) buildArchive(ctx context.Context, archiveID string, payload archiveJobPayload, files []*model.FileMetadata) error {
+	storagePath := archiveID + ".zip"
+	metadata := &model.FileMetadata{
+		ID:           archiveID,
+		OriginalName: payload.Name,
+		StoragePath:  storagePath,
+		MimeType:     "application/zip",
+		OwnerUserID:  &payload.OwnerUserID,
+		Version:      1,
+	}
+	hasher, err := integrity.NewHasher(s.cfg().ExtraChecksums...)
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("gagal menyiapkan checksum arsip: %w", err))
+	}
+	pr, pw := io.Pipe()
+	counter := &countingWriter{w: io.MultiWriter(pw, hasher)}
+	go func() {
+		pw.CloseWithError(s.WriteArchive(ctx, counter, files, payload.Manifest))
+	}()
+
+	if err := storage.SaveWithAttributes(ctx, s.storage, storagePath, pr, storageAttributes(metadata)); err != nil {
+		pr.CloseWithError(err)
+		s.discardArchive(ctx, archiveID, storagePath)
+		return fmt.Errorf("gagal membangun arsip: %w", err)
+	}
+
+	metadata.SizeBytes = counter.n
+	sums := hasher.Sums()
+	metadata.ChecksumSHA256, metadata.ChecksumMD5, metadata.ChecksumCRC32C = sums.SHA256, sums.MD5, sums.CRC32C
+	if err := s.repo.Create(ctx, metadata, nil); err != nil {
+		s.discardArchive(ctx, archiveID, storagePath)
+		return fmt.Errorf("gagal menyimpan metadata arsip: %w", err)
+	}
+	log.Info().Ctx(ctx).Str("archive_id", archiveID).Int("files", len(files)).Int64("size_bytes", counter.n).Msg("Arsip asinkron selesai dibuat")
+	return nil
+}
+
+// discardArchive membuang arsip yang tidak lengkap; context job mungkin
+// sudah dibatalkan sehingga penghapusan memakai context tersendiri.
+func (s *fileService) discardArchive(ctx context.Context, archiveID, storagePath string) {
+	deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := s.storage.Delete(deleteCtx, storagePath); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("archive_id", archiveID).Msg("Gagal membersihkan arsip yang tidak lengkap")
+	}
+}
+
+// ArchiveFileName mengembalikan nama file arsip yang aman dengan ekstensi .zip.
+func ArchiveFileName(requested string, now time.Time) string {
+	name := sanitizeArchiveName(requested)
+	if name == "" {
+		name = "archive-" + now.UTC().Format("20060102-150405")
+	}
+	if !strings.EqualFold(path.Ext(name), ".zip") {
+		name += ".zip"
+	}
+	return name
+}
+
+// uniqueArchiveName menghindari entri ganda dengan menambahkan sufiks " (n)".
+func uniqueArchiveName(used map[string]bool, metadata *model.FileMetadata) string {
+	name := sanitizeArchiveName(metadata.OriginalName)
+	if name == "" {
+		name = metadata.ID
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for n := 1; used[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, n, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// sanitizeArchiveName membuang komponen direktori agar entri tidak bisa keluar
+// dari folder ekstraksi (zip slip).
+func sanitizeArchiveName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_", "\x00", "").Replace(strings.TrimSpace(name))
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
+
+// archiveCompressionMethod tidak mengompres ulang format yang sudah terkompresi.
+func archiveCompressionMethod(mimeType string) uint16 {
+	base := strings.Split(mimeType, ";")[0]
+	switch {
+	case strings.HasPrefix(base, "image/"), strings.HasPrefix(base, "video/"), strings.HasPrefix(base, "audio/"),
+		base == "application/pdf", base == "application/zip", base == "application/gzip":
+		return zip.Store
+	default:
+		return zip.Deflate
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
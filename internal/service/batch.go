@@ -0,0 +1,203 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MaxBatchFiles adalah jumlah maksimum file dalam satu permintaan batch,
+	// baik sebagai part multipart maupun entri di dalam arsip ZIP.
+	MaxBatchFiles = 100
+	// maxArchiveCompressionRatio membatasi rasio ukuran asli terhadap ukuran
+	// terkompresi per entri ZIP untuk menangkal zip bomb.
+	maxArchiveCompressionRatio = 100
+)
+
+var (
+	ErrBatchEmpty        = errors.New("batch tidak berisi file apa pun")
+	ErrBatchTooManyFiles = fmt.Errorf("batch melebihi batas %d file", MaxBatchFiles)
+	ErrUnsafeArchive     = errors.New("arsip ZIP ditolak")
+)
+
+// BatchUploadItem adalah satu file dalam upload batch beserta tag-nya.
+type BatchUploadItem struct {
+	File *multipart.FileHeader
+	Tags []string
+}
+
+// BatchUploadResult melaporkan hasil per file. Kegagalan satu file tidak
+// membatalkan file lain; file yang berhasil tetap tersimpan.
+type BatchUploadResult struct {
+	FileName string              `json:"file_name"`
+	Success  bool                `json:"success"`
+	File     *model.FileMetadata `json:"file,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	// ErrorCode adalah kode penolakan validasi, jika file ditolak karena isinya.
+	ErrorCode validation.Code `json:"error_code,omitempty"`
+	// Err adalah error asli untuk klasifikasi di handler.
+	Err error `json:"-"`
+}
+
+func newBatchResult(name string, metadata *model.FileMetadata, err error) BatchUploadResult {
+	if err != nil {
+		result := BatchUploadResult{FileName: name, Error: err.Error(), Err: err}
+		if validationErr, ok := validation.AsError(err); ok {
+			result.ErrorCode = validationErr.Code
+		}
+		return result
+	}
+	return BatchUploadResult{FileName: name, Success: true, File: metadata}
+}
+
+func (s *fileService) UploadBatch(ctx context.Context, ownerID string, items []BatchUploadItem) ([]BatchUploadResult, error) {
+	if len(items) == 0 {
+		return nil, ErrBatchEmpty
+	}
+	if len(items) > MaxBatchFiles {
+		return nil, ErrBatchTooManyFiles
+	}
+
+	results := make([]BatchUploadResult, 0, len(items))
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		metadata, err := s.UploadFile(ctx, ownerID, item.File, item.Tags)
+		results = append(results, newBatchResult(item.File.Filename, metadata, err))
+	}
+	return results, nil
+}
+
+// UploadArchive mengekstrak arsip ZIP di server dan mengunggah setiap entrinya
+// melalui validasi yang sama dengan UploadFile. Ukuran entri dihitung dari byte
+// yang benar-benar dibaca, bukan dari header ZIP yang bisa dipalsukan.
+func (s *fileService) UploadArchive(ctx context.Context, ownerID string, archive *multipart.FileHeader, tags []string) (results []BatchUploadResult, err error) {
+	file, err := archive.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			log.Warn().Ctx(ctx).Err(closeErr).Msg("Gagal menutup arsip multipart")
+		}
+	}()
+
+	zr, err := zip.NewReader(file, archive.Size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bukan arsip ZIP yang valid: %v", ErrUnsafeArchive, err)
+	}
+
+	var entries []*zip.File
+	for _, entry := range zr.File {
+		if isArchiveEntryIgnored(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, ErrBatchEmpty
+	}
+	if len(entries) > MaxBatchFiles {
+		return nil, ErrBatchTooManyFiles
+	}
+
+	// Total byte yang boleh diekstrak untuk seluruh arsip.
+	remaining := s.cfg().MaxFileSizeBytes * MaxBatchFiles
+	results = make([]BatchUploadResult, 0, len(entries))
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		name := path.Base(strings.ReplaceAll(entry.Name, "\\", "/"))
+		metadata, n, err := s.uploadArchiveEntry(ctx, ownerID, name, entry, tags, remaining)
+		remaining -= n
+		results = append(results, newBatchResult(name, metadata, err))
+		// Sisa 0 berarti anggaran terpakai tepat habis; baru error jika entri
+		// membaca melebihi anggaran.
+		if remaining < 0 {
+			return results, fmt.Errorf("%w: total ukuran hasil ekstraksi melebihi batas", ErrUnsafeArchive)
+		}
+	}
+	return results, nil
+}
+
+// uploadArchiveEntry menampung satu entri ke file sementara agar dapat dibaca
+// ulang (deteksi MIME lalu simpan), lalu mengunggahnya. Mengembalikan jumlah
+// byte yang diekstrak untuk perhitungan anggaran arsip.
+func (s *fileService) uploadArchiveEntry(ctx context.Context, ownerID, name string, entry *zip.File, tags []string, budget int64) (*model.FileMetadata, int64, error) {
+	// Batas kebijakan yang cocok diperiksa lagi oleh storeUpload.
+	if maxSize := s.cfg().UploadSizeLimit(); entry.UncompressedSize64 > uint64(maxSize) {
+		return nil, 0, validation.Errorf(validation.CodeFileTooLarge, "file size (%d bytes) exceeds the limit of %d bytes", entry.UncompressedSize64, maxSize)
+	}
+	if entry.CompressedSize64 > 0 && entry.UncompressedSize64/entry.CompressedSize64 > maxArchiveCompressionRatio {
+		return nil, 0, fmt.Errorf("%w: rasio kompresi entri terlalu tinggi", ErrUnsafeArchive)
+	}
+
+	limit := min(int64(entry.UncompressedSize64), budget)
+	tmp, n, err := spoolArchiveEntry(entry, limit)
+	if err != nil {
+		return nil, n, err
+	}
+	defer func() {
+		if closeErr := tmp.Close(); closeErr != nil {
+			log.Warn().Ctx(ctx).Err(closeErr).Str("path", tmp.Name()).Msg("Gagal menutup file sementara batch")
+		}
+		if removeErr := os.Remove(tmp.Name()); removeErr != nil {
+			log.Warn().Ctx(ctx).Err(removeErr).Str("path", tmp.Name()).Msg("Gagal menghapus file sementara batch")
+		}
+	}()
+
+	metadata, err := s.storeUpload(ctx, ownerID, name, n, tmp, tags)
+	return metadata, n, err
+}
+
+func spoolArchiveEntry(entry *zip.File, limit int64) (*os.File, int64, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal membuka entri arsip: %w", err)
+	}
+	defer func() {
+		if closeErr := rc.Close(); closeErr != nil {
+			log.Warn().Err(closeErr).Str("entry", entry.Name).Msg("Gagal menutup entri arsip")
+		}
+	}()
+
+	tmp, err := os.CreateTemp("", "prism-batch-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal membuat file sementara: %w", err)
+	}
+	n, err := io.Copy(tmp, io.LimitReader(rc, limit+1))
+	if err == nil && n > limit {
+		err = fmt.Errorf("%w: ukuran entri melebihi ukuran yang dideklarasikan atau sisa kuota arsip", ErrUnsafeArchive)
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, n, err
+	}
+	return tmp, n, nil
+}
+
+// isArchiveEntryIgnored melewati direktori, symlink, metadata macOS, dan file tersembunyi.
+func isArchiveEntryIgnored(entry *zip.File) bool {
+	name := strings.ReplaceAll(entry.Name, "\\", "/")
+	if !entry.FileInfo().Mode().IsRegular() || strings.HasPrefix(name, "__MACOSX/") {
+		return true
+	}
+	return strings.HasPrefix(path.Base(name), ".")
+}
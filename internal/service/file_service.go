@@ -4,14 +4,26 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync/atomic"
+	"time"
 
-	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/model"
 	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/fulltext"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/integrity"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/metrics"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/sanitize"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/tracing"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -22,31 +34,73 @@ var (
 	ErrAccessDenied = fmt.Errorf("akses ditolak")
 )
 
+var safeExtension = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
 type FileService interface {
 	UploadFile(ctx context.Context, ownerID string, fileHeader *multipart.FileHeader, tags []string) (*model.FileMetadata, error)
+	UploadBatch(ctx context.Context, ownerID string, items []BatchUploadItem) ([]BatchUploadResult, error)
+	UploadArchive(ctx context.Context, ownerID string, archive *multipart.FileHeader, tags []string) ([]BatchUploadResult, error)
+	UploadStream(ctx context.Context, ownerID, filename string, content io.Reader, tags []string) (*model.FileMetadata, error)
 	GetFileMetadata(ctx context.Context, fileID string, claims jwt.MapClaims) (*model.FileMetadata, error)
-	GetFileReader(ctx context.Context, path string) (io.ReadCloser, error)
+	GetFileReader(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error)
+	OpenFile(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error)
+	SignedDownloadURL(ctx context.Context, metadata *model.FileMetadata) (string, time.Time, error)
+	ListFiles(ctx context.Context, filter repository.FileFilter, claims jwt.MapClaims) ([]*model.FileMetadata, error)
+	DeleteFile(ctx context.Context, fileID string, claims jwt.MapClaims) error
+	UpdateFileMetadata(ctx context.Context, fileID string, update repository.MetadataUpdate, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error)
+	AddFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error)
+	RemoveFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error)
+	ResolveArchiveFiles(ctx context.Context, req ArchiveRequest, claims jwt.MapClaims) ([]*model.FileMetadata, error)
+	WriteArchive(ctx context.Context, w io.Writer, files []*model.FileMetadata, withManifest bool) error
+	CreateArchiveAsync(ctx context.Context, ownerID string, files []*model.FileMetadata, req ArchiveRequest) (string, error)
+	UploadPolicies(claims jwt.MapClaims) []UploadPolicyInfo
+	ApplyConfig(cfg *fileserviceconfig.Config)
 }
 
 type fileService struct {
 	repo    repository.FileRepository
 	storage storage.Storage
-	cfg     *fileserviceconfig.Config
+	// config diganti secara atomik oleh ApplyConfig saat konfigurasi dimuat
+	// ulang; baca lewat cfg.
+	config   atomic.Pointer[fileserviceconfig.Config]
+	policies *policy.Engine
+	jobs     repository.JobRepository
+	rules    repository.AccessRuleRepository
 }
 
-func NewFileService(repo repository.FileRepository, storage storage.Storage, cfg *fileserviceconfig.Config) FileService {
-	return &fileService{
-		repo:    repo,
-		storage: storage,
-		cfg:     cfg,
+// NewFileService membuat FileService. Jika policies nil, otorisasi memakai
+// policy.DefaultPolicies. Jika jobs nil, pekerjaan pasca-unggah (ekstraksi
+// teks) dijalankan langsung saat unggah, bukan lewat antrean job. Jika rules
+// diisi, aturan akses tag dicocokkan dengan tag file yang sudah dimuat
+// sehingga otorisasi cukup membaca daftar aturan (yang di-cache); jika nil,
+// otorisasi memakai repo.CheckRoleAccess.
+func NewFileService(repo repository.FileRepository, storage storage.Storage, cfg *fileserviceconfig.Config, policies *policy.Engine, jobs repository.JobRepository, rules repository.AccessRuleRepository) FileService {
+	if policies == nil {
+		policies = policy.NewDefaultEngine()
+	}
+	s := &fileService{
+		repo:     repo,
+		storage:  storage,
+		policies: policies,
+		jobs:     jobs,
+		rules:    rules,
 	}
+	s.config.Store(cfg)
+	return s
 }
 
-func (s *fileService) UploadFile(ctx context.Context, ownerID string, fileHeader *multipart.FileHeader, tags []string) (metadata *model.FileMetadata, err error) {
-	if fileHeader.Size > s.cfg.MaxFileSizeBytes {
-		return nil, fmt.Errorf("file size (%d bytes) exceeds the limit of %d bytes", fileHeader.Size, s.cfg.MaxFileSizeBytes)
-	}
+// ApplyConfig mengganti konfigurasi (batas ukuran, tipe MIME, checksum) yang
+// dipakai permintaan berikutnya. Permintaan yang sedang berjalan tetap memakai
+// konfigurasi lama.
+func (s *fileService) ApplyConfig(cfg *fileserviceconfig.Config) {
+	s.config.Store(cfg)
+}
+
+func (s *fileService) cfg() *fileserviceconfig.Config {
+	return s.config.Load()
+}
 
+func (s *fileService) UploadFile(ctx context.Context, ownerID string, fileHeader *multipart.FileHeader, tags []string) (metadata *model.FileMetadata, err error) {
 	file, err := fileHeader.Open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file for validation: %w", err)
@@ -58,32 +112,134 @@ func (s *fileService) UploadFile(ctx context.Context, ownerID string, fileHeader
 				err = fmt.Errorf("gagal menutup file multipart: %w", closeErr)
 			} else {
 				// Jika sudah ada error, log ini sebagai peringatan.
-				log.Warn().Err(closeErr).Msg("Gagal menutup file multipart setelah error sebelumnya.")
+				log.Warn().Ctx(ctx).Err(closeErr).Msg("Gagal menutup file multipart setelah error sebelumnya.")
 			}
 		}
 	}()
 
-	mime, err := mimetype.DetectReader(file)
+	return s.storeUpload(ctx, ownerID, fileHeader.Filename, fileHeader.Size, file, tags)
+}
+
+// UploadStream mengunggah file yang ukurannya belum diketahui (mis. dari stream
+// gRPC). Isi file ditampung di file sementara, dibatasi batas unggahan terbesar
+// (Config.UploadSizeLimit), agar dapat divalidasi seperti upload multipart.
+func (s *fileService) UploadStream(ctx context.Context, ownerID, filename string, content io.Reader, tags []string) (*model.FileMetadata, error) {
+	tmp, err := os.CreateTemp("", "prism-upload-*")
 	if err != nil {
-		return nil, fmt.Errorf("failed to detect mime type: %w", err)
+		return nil, fmt.Errorf("gagal membuat file sementara: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		if removeErr := os.Remove(tmp.Name()); removeErr != nil {
+			log.Warn().Ctx(ctx).Err(removeErr).Str("path", tmp.Name()).Msg("Gagal menghapus file sementara upload")
+		}
+	}()
+
+	size, err := io.Copy(tmp, io.LimitReader(content, s.cfg().UploadSizeLimit()+1))
+	if err != nil {
+		return nil, fmt.Errorf("gagal menerima isi file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek file to beginning before validation: %w", err)
+	}
+	return s.storeUpload(ctx, ownerID, filename, size, tmp, tags)
+}
+
+// uploadContent adalah isi unggahan yang dapat dibaca ulang: berurutan untuk
+// deteksi MIME, checksum dan penyimpanan, serta acak untuk validasi isi.
+type uploadContent interface {
+	io.ReadSeeker
+	io.ReaderAt
+}
+
+// storeUpload memvalidasi unggahan terhadap kebijakan upload yang cocok (atau
+// batas global) dan validasi isi file, membersihkan metadata file jika
+// diminta kebijakan, lalu menyimpan metadata dan file fisik. Teks untuk
+// pencarian diekstrak langsung, atau lewat job extract_text jika antrean job
+// aktif; jumlah halaman dan properti PDF selalu dibaca langsung. Dipakai
+// bersama oleh upload tunggal maupun batch. Penolakan dikembalikan sebagai
+// *validation.Error, dihitung per kode pada metrik dan dicatat sebagai event
+// upload.rejected pada span aktif.
+func (s *fileService) storeUpload(ctx context.Context, ownerID, filename string, size int64, file uploadContent, tags []string) (_ *model.FileMetadata, err error) {
+	defer func() {
+		if validationErr, ok := validation.AsError(err); ok {
+			metrics.ObserveUploadRejection(validationErr.Code)
+			tracing.RecordUploadRejection(ctx, validationErr)
+		}
+	}()
+	cfg := s.cfg()
+	rules, err := uploadRulesFor(ctx, cfg, filename, tags)
+	if err != nil {
+		return nil, err
+	}
+	if size > rules.maxSizeBytes {
+		return nil, validation.Errorf(validation.CodeFileTooLarge, "file size (%d bytes) exceeds the limit of %d bytes%s", size, rules.maxSizeBytes, rules.describe())
+	}
+
+	mime, err := detectMimeType(ctx, file)
+	if err != nil {
+		return nil, err
 	}
 
 	baseMimeType := strings.Split(mime.String(), ";")[0]
-	if !s.cfg.AllowedMimeTypesMap[baseMimeType] {
-		return nil, fmt.Errorf("mime type '%s' is not allowed", mime.String())
+	if !rules.mimeTypes[baseMimeType] {
+		return nil, validation.Errorf(validation.CodeMimeTypeNotAllowed, "mime type '%s' is not allowed%s", mime.String(), rules.describe())
+	}
+	if err := validation.Validate(file, size, filename, mime, cfg.ContentValidation); err != nil {
+		return nil, err
+	}
+
+	var sanitizedAt *time.Time
+	if rules.sanitize && sanitize.Supports(mime) {
+		clean, cleanSize, err := sanitizeUpload(file, size, mime)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = clean.Close()
+			if removeErr := os.Remove(clean.Name()); removeErr != nil {
+				log.Warn().Ctx(ctx).Err(removeErr).Str("path", clean.Name()).Msg("Gagal menghapus file sementara hasil sanitasi")
+			}
+		}()
+		file, size = clean, cleanSize
+		now := time.Now()
+		sanitizedAt = &now
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek file to beginning before hashing: %w", err)
+	}
+	sums, err := integrity.Compute(file, cfg.ExtraChecksums...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung checksum file: %w", err)
 	}
 
 	fileID := uuid.New().String()
-	fileExtension := filepath.Ext(fileHeader.Filename)
-	storageFileName := fmt.Sprintf("%s%s", fileID, fileExtension)
+	storageFileName := fileID + storageExtension(filename, mime)
 
-	metadata = &model.FileMetadata{
+	metadata := &model.FileMetadata{
 		ID:           fileID,
-		OriginalName: fileHeader.Filename,
+		OriginalName: filename,
 		StoragePath:  storageFileName,
 		MimeType:     mime.String(),
-		SizeBytes:    fileHeader.Size,
+		SizeBytes:    size,
 		OwnerUserID:  &ownerID,
+		Version:      1,
+
+		ChecksumSHA256: sums.SHA256,
+		ChecksumMD5:    sums.MD5,
+		ChecksumCRC32C: sums.CRC32C,
+
+		RetainUntil: rules.retainUntil(time.Now()),
+		SanitizedAt: sanitizedAt,
+		Provenance:  provenanceFromContext(ctx),
+	}
+	if baseMimeType == pdfMimeType {
+		metadata.PDF = inspectPDF(file, size, filename)
+	}
+	// Tipe tanpa extractor langsung ditandai terindeks agar tidak menjadi job.
+	if cfg.SearchMaxTextBytes > 0 && (s.jobs == nil || !fulltext.Supports(mime)) {
+		indexContent(metadata, file, size, mime, cfg.SearchMaxTextBytes)
 	}
 
 	if err = s.repo.Create(ctx, metadata, tags); err != nil {
@@ -94,48 +250,259 @@ func (s *fileService) UploadFile(ctx context.Context, ownerID string, fileHeader
 		return nil, fmt.Errorf("failed to seek file to beginning before saving: %w", err)
 	}
 
-	if err = s.storage.Save(ctx, storageFileName, file); err != nil {
-		log.Error().Err(err).Str("file_id", metadata.ID).Msg("Gagal menyimpan file ke storage. Rollback metadata...")
+	if err = storage.SaveWithAttributes(ctx, s.storage, storageFileName, file, storageAttributes(metadata)); err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("file_id", metadata.ID).Msg("Gagal menyimpan file ke storage. Rollback metadata...")
 		if rollbackErr := s.repo.DeleteByID(context.Background(), metadata.ID); rollbackErr != nil {
-			log.Fatal().Err(rollbackErr).Str("file_id", metadata.ID).Msg("FATAL: METADATA ROLLBACK FAILED.")
+			log.Fatal().Ctx(ctx).Err(rollbackErr).Str("file_id", metadata.ID).Msg("FATAL: METADATA ROLLBACK FAILED.")
 		}
 		return nil, fmt.Errorf("failed to save file content: %w", err)
 	}
 
+	metrics.ObserveUpload(metadata.MimeType, cfg.StorageBackend, size)
+
+	if s.jobs != nil && cfg.SearchMaxTextBytes > 0 && metadata.IndexedAt == nil {
+		s.enqueueJob(ctx, model.JobTypeExtractText, metadata.ID)
+	}
 	return metadata, nil
 }
 
+// detectMimeType mendeteksi tipe MIME dari awal konten dalam span tersendiri,
+// karena deteksi membaca konten unggahan.
+func detectMimeType(ctx context.Context, file io.Reader) (_ *mimetype.MIME, err error) {
+	_, span := tracing.Start(ctx, "detect mime type")
+	defer func() { tracing.End(span, err) }()
+	mime, err := mimetype.DetectReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect mime type: %w", err)
+	}
+	span.SetAttributes(tracing.FileMimeTypeKey.String(mime.String()))
+	return mime, nil
+}
+
+// enqueueJob menambahkan job untuk file yang baru disimpan. Kegagalan hanya
+// dicatat: unggahan tetap berhasil, dan teks file yang belum terindeks dapat
+// diekstrak kemudian dengan perintah reindex -missing.
+func (s *fileService) enqueueJob(ctx context.Context, jobType, fileID string) {
+	job := &model.Job{Type: jobType, FileID: &fileID}
+	if err := s.jobs.Enqueue(ctx, job); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("file_id", fileID).Str("job_type", jobType).Msg("Gagal menambahkan job ke antrean")
+	}
+}
+
+// storageExtension menentukan ekstensi nama file di storage. Ekstensi dari tipe
+// MIME hasil deteksi konten diutamakan; ekstensi dari nama file klien hanya
+// dipakai jika tipe MIME tidak punya ekstensi dan isinya aman (huruf kecil dan
+// angka, maksimal 10 karakter).
+func storageExtension(filename string, mime *mimetype.MIME) string {
+	if ext := mime.Extension(); ext != "" {
+		return ext
+	}
+	ext := strings.ToLower(filepath.Ext(filepath.Base(filename)))
+	if !safeExtension.MatchString(ext) {
+		return ""
+	}
+	return ext
+}
+
+// storageAttributes menyusun atribut objek storage untuk file, agar unduhan
+// langsung dari penyedia storage membawa tipe dan nama file aslinya.
+func storageAttributes(metadata *model.FileMetadata) storage.ObjectAttributes {
+	attrs := storage.ObjectAttributes{
+		ContentType:        metadata.MimeType,
+		ContentDisposition: mime.FormatMediaType("attachment", map[string]string{"filename": metadata.OriginalName}),
+		Metadata:           map[string]string{"file-id": metadata.ID},
+	}
+	if metadata.OwnerUserID != nil {
+		attrs.Metadata["owner-id"] = *metadata.OwnerUserID
+	}
+	return attrs
+}
+
 func (s *fileService) GetFileMetadata(ctx context.Context, fileID string, claims jwt.MapClaims) (*model.FileMetadata, error) {
 	metadata, err := s.repo.GetByID(ctx, fileID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authorize(ctx, metadata, claims); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
 
-	userID := claims["sub"].(string)
+// authorize mengevaluasi kebijakan otorisasi untuk membaca file dan mencatat
+// keputusannya beserta kebijakan yang menentukan. Mengembalikan ErrAccessDenied
+// jika kebijakan menolak.
+func (s *fileService) authorize(ctx context.Context, metadata *model.FileMetadata, claims jwt.MapClaims) error {
 	userRole, _ := claims["role"].(string)
+	request := policy.RequestFromContext(ctx)
+	request.Action = policy.ActionRead
+
+	decision := s.policies.Evaluate(ctx, policy.Input{
+		Subject: claims,
+		File:    policyFile(metadata),
+		Request: request,
+		TagAccess: func(ctx context.Context) (bool, error) {
+			return s.checkTagAccess(ctx, metadata, userRole)
+		},
+	})
+
+	subject, _ := claims["sub"].(string)
+	event := log.Info().Ctx(ctx)
+	if !decision.Allowed {
+		event = log.Warn().Ctx(ctx)
+	}
+	for _, err := range decision.Errors {
+		log.Error().Ctx(ctx).Err(err).Str("file_id", metadata.ID).Str("role", userRole).Msg("Gagal mengevaluasi kebijakan otorisasi")
+	}
+	event.Str("file_id", metadata.ID).
+		Str("user_id", subject).
+		Str("role", userRole).
+		Str("action", request.Action).
+		Str("ip", request.IP).
+		Str("policy", decision.Policy).
+		Bool("allowed", decision.Allowed).
+		Msg("Keputusan otorisasi")
+
+	if !decision.Allowed {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+func (s *fileService) checkTagAccess(ctx context.Context, metadata *model.FileMetadata, role string) (bool, error) {
+	if s.rules == nil {
+		return s.repo.CheckRoleAccess(ctx, metadata.ID, role)
+	}
+	rules, err := s.rules.ListRules(ctx)
+	if err != nil {
+		return false, err
+	}
+	return model.RoleHasTagAccess(rules, role, metadata.Tags), nil
+}
+
+// policyFile memetakan metadata file ke atribut file yang dilihat kebijakan.
+func policyFile(metadata *model.FileMetadata) policy.File {
+	owner := ""
+	if metadata.OwnerUserID != nil {
+		owner = *metadata.OwnerUserID
+	}
+	return policy.File{
+		ID:           metadata.ID,
+		OwnerUserID:  owner,
+		OriginalName: metadata.OriginalName,
+		MimeType:     metadata.MimeType,
+		SizeBytes:    metadata.SizeBytes,
+		Tags:         metadata.Tags,
+		CreatedAt:    metadata.CreatedAt,
+	}
+}
 
-	if metadata.OwnerUserID != nil && *metadata.OwnerUserID == userID {
-		return metadata, nil
+// GetFileReader membuka konten file tanpa verifikasi checksum, untuk
+// pembacaan sebagian (unduhan rentang).
+func (s *fileService) GetFileReader(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error) {
+	return s.openContent(ctx, metadata)
+}
+
+// OpenFile membuka seluruh konten file. Jika verify_checksum_on_read aktif dan
+// file memiliki checksum, pembacaan gagal dengan integrity.ErrChecksumMismatch
+// sebelum potongan terakhir konten diserahkan bila kontennya rusak.
+func (s *fileService) OpenFile(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error) {
+	reader, err := s.openContent(ctx, metadata)
+	if err != nil {
+		return nil, err
 	}
+	if !s.cfg().VerifyChecksumOnRead || metadata.ChecksumSHA256 == "" {
+		return reader, nil
+	}
+	return integrity.NewVerifyingReader(reader, metadata.SizeBytes, metadata.ChecksumSHA256), nil
+}
 
-	if userRole == "admin" {
-		return metadata, nil
+// SignedDownloadURLExpiry adalah masa berlaku URL unduhan langsung dari
+// penyedia storage.
+const SignedDownloadURLExpiry = 15 * time.Minute
+
+// SignedDownloadURL membuat URL unduhan langsung dari penyedia storage (SAS
+// Azure atau signed URL GCS) untuk file yang aksesnya sudah diperiksa lewat
+// GetFileMetadata. Backend yang tidak mendukungnya mengembalikan
+// storage.ErrSigningUnsupported.
+func (s *fileService) SignedDownloadURL(ctx context.Context, metadata *model.FileMetadata) (string, time.Time, error) {
+	expiresAt := time.Now().Add(SignedDownloadURLExpiry)
+	url, err := storage.SignedURL(ctx, s.storage, metadata.StoragePath, SignedDownloadURLExpiry)
+	if err != nil {
+		return "", time.Time{}, err
 	}
+	return url, expiresAt, nil
+}
 
-	if len(metadata.Tags) > 0 {
-		hasAccess, err := s.repo.CheckRoleAccess(ctx, fileID, userRole)
+// openContent membuka konten file dari storage; byte yang dibaca dihitung
+// pada metrik unduhan per tipe MIME.
+func (s *fileService) openContent(ctx context.Context, metadata *model.FileMetadata) (io.ReadCloser, error) {
+	reader, err := s.storage.Get(ctx, metadata.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.CountDownload(reader, metadata.MimeType, s.cfg().StorageBackend), nil
+}
+
+// ListFiles mengembalikan file yang cocok dengan filter dan dapat dibaca oleh
+// pemanggil; file yang ditolak kebijakan otorisasi dilewati.
+func (s *fileService) ListFiles(ctx context.Context, filter repository.FileFilter, claims jwt.MapClaims) ([]*model.FileMetadata, error) {
+	// Limit berlaku untuk file yang boleh dibaca, bukan untuk baris database:
+	// kebijakan otorisasi dievaluasi di sini, sehingga repository dibaca per
+	// halaman sampai Limit terpenuhi atau baris habis. Tanpa itu, file milik
+	// pengguna lain yang lebih baru dapat memenuhi LIMIT dan menyembunyikan
+	// file milik pemanggil.
+	limit := filter.Limit
+	files := make([]*model.FileMetadata, 0, limit)
+	for {
+		candidates, err := s.repo.List(ctx, filter)
 		if err != nil {
-			log.Error().Err(err).Str("file_id", fileID).Str("role", userRole).Msg("Gagal memeriksa akses peran")
-			return nil, ErrAccessDenied
+			return nil, fmt.Errorf("gagal mengambil daftar file: %w", err)
 		}
-		if hasAccess {
-			return metadata, nil
+		for _, metadata := range candidates {
+			if err := s.authorize(ctx, metadata, claims); err != nil {
+				continue
+			}
+			files = append(files, metadata)
+			if limit > 0 && len(files) == limit {
+				return files, nil
+			}
+		}
+		if limit == 0 || len(candidates) < filter.Limit {
+			return files, nil
 		}
+		filter.After = repository.CursorOf(candidates[len(candidates)-1])
 	}
-
-	return nil, ErrAccessDenied
 }
 
-func (s *fileService) GetFileReader(ctx context.Context, path string) (io.ReadCloser, error) {
-	return s.storage.Get(ctx, path)
+// DeleteFile menghapus metadata lalu file fisik. Seperti perubahan metadata,
+// hanya pemilik atau admin yang boleh menghapus.
+func (s *fileService) DeleteFile(ctx context.Context, fileID string, claims jwt.MapClaims) error {
+	metadata, err := s.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+
+	userID, _ := claims["sub"].(string)
+	userRole, _ := claims["role"].(string)
+	isOwner := metadata.OwnerUserID != nil && *metadata.OwnerUserID == userID
+	if !isOwner && userRole != "admin" {
+		return ErrAccessDenied
+	}
+
+	if err := s.repo.DeleteByID(ctx, fileID); err != nil {
+		return fmt.Errorf("gagal menghapus metadata file: %w", err)
+	}
+	// Metadata sudah terhapus sehingga file tidak lagi dapat diakses; file fisik
+	// yang gagal dihapus hanya menjadi sampah di storage.
+	if err := s.storage.Delete(ctx, metadata.StoragePath); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("file_id", fileID).Str("storage_path", metadata.StoragePath).Msg("Gagal menghapus file fisik setelah metadata dihapus")
+	}
+	for _, path := range metadata.RenditionPaths {
+		if err := s.storage.Delete(ctx, path); err != nil {
+			log.Warn().Ctx(ctx).Err(err).Str("file_id", fileID).Str("storage_path", path).Msg("Gagal menghapus cache render setelah metadata dihapus")
+		}
+	}
+	log.Info().Ctx(ctx).Str("file_id", fileID).Str("user_id", userID).Msg("File dihapus")
+	return nil
 }
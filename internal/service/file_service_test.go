@@ -3,23 +3,41 @@
 package service
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"mime/multipart"
 	"strings"
 	"testing"
 
-	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/model"
 	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/integrity"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+// testPNG adalah gambar PNG 1x1 yang valid, agar lolos validasi isi file.
+var testPNG = func() string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}()
+
 // --- Mock untuk FileRepository ---
 type MockFileRepository struct {
 	mock.Mock
@@ -49,6 +67,29 @@ func (m *MockFileRepository) CheckRoleAccess(ctx context.Context, fileID string,
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockFileRepository) List(ctx context.Context, filter repository.FileFilter) ([]*model.FileMetadata, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.FileMetadata), args.Error(1)
+}
+
+func (m *MockFileRepository) UpdateMetadata(ctx context.Context, id string, expectedVersion int64, update repository.MetadataUpdate, actorID string) error {
+	args := m.Called(ctx, id, expectedVersion, update, actorID)
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) AddTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error {
+	args := m.Called(ctx, id, tag, expectedVersion, actorID)
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) RemoveTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) error {
+	args := m.Called(ctx, id, tag, expectedVersion, actorID)
+	return args.Error(0)
+}
+
 // --- Mock untuk Storage ---
 type MockStorage struct {
 	mock.Mock
@@ -117,7 +158,7 @@ func TestFileService_UploadFile(t *testing.T) {
 	}{
 		{
 			name:        "Success - Valid PNG file with tags",
-			fileContent: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR...",
+			fileContent: testPNG,
 			fileName:    "test.png",
 			tags:        []string{"avatar", "profile"},
 			config:      testConfig,
@@ -130,7 +171,7 @@ func TestFileService_UploadFile(t *testing.T) {
 		// ... (Kasus uji lain tetap sama, hanya perlu menambahkan `tags`)
 		{
 			name:        "Error - Database fails to save metadata",
-			fileContent: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR...",
+			fileContent: testPNG,
 			fileName:    "test.png",
 			tags:        nil,
 			config:      testConfig,
@@ -154,11 +195,7 @@ func TestFileService_UploadFile(t *testing.T) {
 			}
 
 			// FIX: Inisialisasi service dengan field `storage` yang baru
-			service := &fileService{
-				repo:    mockRepo,
-				storage: mockStore,
-				cfg:     tc.config,
-			}
+			service := NewFileService(mockRepo, mockStore, tc.config, nil, nil, nil)
 
 			fileHeader, err := createTestFileHeader(tc.fileContent, tc.fileName)
 			assert.NoError(t, err)
@@ -181,6 +218,46 @@ func TestFileService_UploadFile(t *testing.T) {
 		})
 	}
 }
+
+func TestFileService_UploadFile_ContentValidation(t *testing.T) {
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024,
+		AllowedMimeTypesMap: map[string]bool{"image/png": true, "text/plain": true},
+		ContentValidation:   validation.DefaultOptions(),
+	}
+
+	testCases := []struct {
+		name         string
+		content      string
+		fileName     string
+		expectedCode validation.Code
+	}{
+		{name: "Extension does not match content", content: testPNG, fileName: "photo.jpg", expectedCode: validation.CodeExtensionMismatch},
+		{name: "Corrupt image", content: testPNG[:len(testPNG)-16], fileName: "photo.png", expectedCode: validation.CodeImageInvalid},
+		{name: "Data appended after image", content: testPNG + "<?php system($_GET['c']); ?>", fileName: "photo.png", expectedCode: validation.CodeImageTrailingData},
+		{name: "File too large", content: strings.Repeat("a", 2048), fileName: "notes.txt", expectedCode: validation.CodeFileTooLarge},
+		{name: "MIME type not allowed", content: "%PDF-1.4\n%%EOF", fileName: "doc.pdf", expectedCode: validation.CodeMimeTypeNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockFileRepository)
+			mockStore := new(MockStorage)
+			svc := NewFileService(mockRepo, mockStore, cfg, nil, nil, nil)
+
+			fileHeader, err := createTestFileHeader(tc.content, tc.fileName)
+			require.NoError(t, err)
+
+			metadata, err := svc.UploadFile(context.Background(), "owner-1", fileHeader, nil)
+			assert.Nil(t, metadata)
+			validationErr, ok := validation.AsError(err)
+			require.True(t, ok, "error harus bertipe *validation.Error: %v", err)
+			assert.Equal(t, tc.expectedCode, validationErr.Code)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestFileService_GetFileMetadata(t *testing.T) {
 	ctx := context.Background()
 	fileID := "file-abc-123"
@@ -267,7 +344,7 @@ func TestFileService_GetFileMetadata(t *testing.T) {
 			mockStore := new(MockStorage) // Diperlukan untuk inisialisasi service
 			tc.setupMock(mockRepo)
 
-			svc := NewFileService(mockRepo, mockStore, &fileserviceconfig.Config{})
+			svc := NewFileService(mockRepo, mockStore, &fileserviceconfig.Config{}, nil, nil, nil)
 			metadata, err := svc.GetFileMetadata(ctx, fileID, tc.claims)
 
 			if tc.expectError {
@@ -283,17 +360,49 @@ func TestFileService_GetFileMetadata(t *testing.T) {
 	}
 }
 
+func TestFileService_GetFileMetadata_CachedRules(t *testing.T) {
+	ctx := context.Background()
+	ownerID := "user-owner-1"
+	fileID := "file-abc-123"
+	file := &model.FileMetadata{ID: fileID, OwnerUserID: &ownerID, Tags: []string{"keuangan/pajak"}}
+
+	testCases := []struct {
+		name          string
+		role          string
+		expectedError error
+	}{
+		{name: "Success - Rule matches file tag", role: "finance"},
+		{name: "Failure - No rule for role", role: "hr", expectedError: ErrAccessDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockFileRepository)
+			mockRepo.On("GetByID", ctx, fileID).Return(file, nil).Once()
+			mockRules := new(MockAccessRuleRepository)
+			mockRules.On("ListRules", ctx).Return([]model.AccessRule{{TagName: "keuangan/*", RoleName: "finance"}}, nil).Once()
+			svc := NewFileService(mockRepo, new(MockStorage), &fileserviceconfig.Config{}, nil, nil, mockRules)
+
+			_, err := svc.GetFileMetadata(ctx, fileID, jwt.MapClaims{"sub": "user-2", "role": tc.role})
+			assert.Equal(t, tc.expectedError, err)
+			mockRepo.AssertNotCalled(t, "CheckRoleAccess", mock.Anything, mock.Anything, mock.Anything)
+			mockRepo.AssertExpectations(t)
+			mockRules.AssertExpectations(t)
+		})
+	}
+}
+
 // BARU: Tambahkan tes untuk GetFileReader
 func TestFileService_GetFileReader(t *testing.T) {
 	mockStore := new(MockStorage)
-	svc := NewFileService(nil, mockStore, &fileserviceconfig.Config{})
+	svc := NewFileService(nil, mockStore, &fileserviceconfig.Config{}, nil, nil, nil)
 	path := "test/file.txt"
 
 	// Mock akan mengembalikan reader string dan tidak ada error
 	mockReader := io.NopCloser(strings.NewReader("file content"))
 	mockStore.On("Get", context.Background(), path).Return(mockReader, nil).Once()
 
-	reader, err := svc.GetFileReader(context.Background(), path)
+	reader, err := svc.GetFileReader(context.Background(), &model.FileMetadata{StoragePath: path, MimeType: "text/plain"})
 	require.NoError(t, err)
 	require.NotNil(t, reader)
 
@@ -304,3 +413,521 @@ func TestFileService_GetFileReader(t *testing.T) {
 
 	mockStore.AssertExpectations(t)
 }
+
+func TestFileService_OpenFile(t *testing.T) {
+	ctx := context.Background()
+	content := "file content"
+	metadata := &model.FileMetadata{
+		StoragePath: "test/file.txt",
+		SizeBytes:   int64(len(content)),
+		// SHA-256 dari "file content".
+		ChecksumSHA256: "e0ac3601005dfa1864f5392aabaf7d898b1b5bab854f1acb4491bcd806b76b0c",
+	}
+
+	testCases := []struct {
+		name          string
+		verify        bool
+		stored        string
+		expectedError error
+	}{
+		{name: "Verification disabled returns content as is", stored: "corrupted!!!"},
+		{name: "Verified content matches", verify: true, stored: content},
+		{name: "Corrupted content is rejected", verify: true, stored: "corrupted!!!", expectedError: integrity.ErrChecksumMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStore := new(MockStorage)
+			mockStore.On("Get", ctx, metadata.StoragePath).Return(io.NopCloser(strings.NewReader(tc.stored)), nil).Once()
+			svc := NewFileService(nil, mockStore, &fileserviceconfig.Config{VerifyChecksumOnRead: tc.verify}, nil, nil, nil)
+
+			reader, err := svc.OpenFile(ctx, metadata)
+			require.NoError(t, err)
+			got, err := io.ReadAll(reader)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.stored, string(got))
+			}
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFileService_ResolveArchiveFiles(t *testing.T) {
+	ctx := context.Background()
+	ownerID := "user-owner-1"
+	otherID := "user-other-2"
+	ownerClaims := jwt.MapClaims{"sub": ownerID, "role": "user"}
+
+	ownFile := &model.FileMetadata{ID: "file-1", OriginalName: "a.pdf", OwnerUserID: &ownerID}
+	otherFile := &model.FileMetadata{ID: "file-2", OriginalName: "b.pdf", OwnerUserID: &otherID}
+
+	t.Run("Success - IDs and tag query are merged without duplicates", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockRepo.On("GetByID", ctx, "file-1").Return(ownFile, nil).Once()
+		mockRepo.On("List", ctx, repository.FileFilter{Tags: []string{"invoice"}, Limit: MaxArchiveFiles + 1}).
+			Return([]*model.FileMetadata{ownFile, otherFile}, nil).Once()
+
+		svc := NewFileService(mockRepo, new(MockStorage), &fileserviceconfig.Config{}, nil, nil, nil)
+		files, err := svc.ResolveArchiveFiles(ctx, ArchiveRequest{FileIDs: []string{"file-1", "file-1"}, Tags: []string{"invoice"}}, ownerClaims)
+
+		require.NoError(t, err)
+		require.Len(t, files, 1, "File milik orang lain dari query tag harus dilewati")
+		assert.Equal(t, "file-1", files[0].ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Explicit ID without access denies the whole archive", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockRepo.On("GetByID", ctx, "file-2").Return(otherFile, nil).Once()
+
+		svc := NewFileService(mockRepo, new(MockStorage), &fileserviceconfig.Config{}, nil, nil, nil)
+		files, err := svc.ResolveArchiveFiles(ctx, ArchiveRequest{FileIDs: []string{"file-2"}}, ownerClaims)
+
+		require.ErrorIs(t, err, ErrAccessDenied)
+		assert.Nil(t, files)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Empty request", func(t *testing.T) {
+		svc := NewFileService(new(MockFileRepository), new(MockStorage), &fileserviceconfig.Config{}, nil, nil, nil)
+		_, err := svc.ResolveArchiveFiles(ctx, ArchiveRequest{}, ownerClaims)
+		require.ErrorIs(t, err, ErrArchiveEmpty)
+	})
+}
+
+func TestFileService_WriteArchive(t *testing.T) {
+	ctx := context.Background()
+	files := []*model.FileMetadata{
+		{ID: "file-1", OriginalName: "invoice.pdf", StoragePath: "file-1.pdf", MimeType: "application/pdf", SizeBytes: 3},
+		{ID: "file-2", OriginalName: "invoice.pdf", StoragePath: "file-2.pdf", MimeType: "application/pdf", SizeBytes: 3},
+		{ID: "file-3", OriginalName: "../notes.txt", StoragePath: "file-3.txt", MimeType: "text/plain", SizeBytes: 5},
+	}
+
+	mockStore := new(MockStorage)
+	mockStore.On("Get", ctx, "file-1.pdf").Return(io.NopCloser(strings.NewReader("one")), nil).Once()
+	mockStore.On("Get", ctx, "file-2.pdf").Return(io.NopCloser(strings.NewReader("two")), nil).Once()
+	mockStore.On("Get", ctx, "file-3.txt").Return(io.NopCloser(strings.NewReader("three")), nil).Once()
+
+	svc := NewFileService(nil, mockStore, &fileserviceconfig.Config{}, nil, nil, nil)
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteArchive(ctx, &buf, files, true))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		contents[f.Name] = string(data)
+	}
+
+	assert.Equal(t, "one", contents["invoice.pdf"])
+	assert.Equal(t, "two", contents["invoice (1).pdf"])
+	assert.Equal(t, "three", contents[".._notes.txt"])
+	assert.Contains(t, contents["manifest.csv"], "invoice (1).pdf,file-2,invoice.pdf,application/pdf,3")
+	mockStore.AssertExpectations(t)
+}
+
+func createTestZipHeader(t *testing.T, entries map[string][]byte) *multipart.FileHeader {
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	header, err := createTestFileHeader(zipBuf.String(), "bundle.zip")
+	require.NoError(t, err)
+	return header
+}
+
+func TestFileService_UploadArchive(t *testing.T) {
+	ctx := context.Background()
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024 * 1024,
+		AllowedMimeTypesMap: map[string]bool{"image/png": true, "text/plain": true},
+	}
+
+	t.Run("Partial success - invalid entries are reported per file", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStore := new(MockStorage)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.FileMetadata"), []string{"import"}).Return(nil).Once()
+		mockStore.On("Save", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+
+		header := createTestZipHeader(t, map[string][]byte{
+			"docs/avatar.png":   []byte(testPNG),
+			"docs/program.exe":  []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"),
+			"__MACOSX/._avatar": []byte("ignored"),
+			"docs/":             nil,
+		})
+
+		svc := NewFileService(mockRepo, mockStore, cfg, nil, nil, nil)
+		results, err := svc.UploadArchive(ctx, "owner-1", header, []string{"import"})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		byName := map[string]BatchUploadResult{}
+		for _, r := range results {
+			byName[r.FileName] = r
+		}
+		assert.True(t, byName["avatar.png"].Success)
+		assert.False(t, byName["program.exe"].Success)
+		assert.Contains(t, byName["program.exe"].Error, "is not allowed")
+		mockRepo.AssertExpectations(t)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Highly compressed entry is rejected as zip bomb", func(t *testing.T) {
+		header := createTestZipHeader(t, map[string][]byte{
+			"bomb.txt": bytes.Repeat([]byte{'A'}, 512*1024),
+		})
+
+		svc := NewFileService(new(MockFileRepository), new(MockStorage), cfg, nil, nil, nil)
+		results, err := svc.UploadArchive(ctx, "owner-1", header, nil)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].Success)
+		assert.ErrorIs(t, results[0].Err, ErrUnsafeArchive)
+	})
+
+	t.Run("Success - Total size exactly equal to the archive budget", func(t *testing.T) {
+		// Anggaran arsip adalah MaxFileSizeBytes * MaxBatchFiles = 800 byte.
+		budgetCfg := &fileserviceconfig.Config{
+			MaxFileSizeBytes:    8,
+			AllowedMimeTypesMap: map[string]bool{"text/plain": true},
+		}
+		entries := make(map[string][]byte, MaxBatchFiles)
+		for i := range MaxBatchFiles {
+			entries[fmt.Sprintf("note-%03d.txt", i)] = []byte(fmt.Sprintf("note%04d", i))
+		}
+		mockRepo := new(MockFileRepository)
+		mockStore := new(MockStorage)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.FileMetadata"), mock.Anything).Return(nil).Times(MaxBatchFiles)
+		mockStore.On("Save", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil).Times(MaxBatchFiles)
+
+		svc := NewFileService(mockRepo, mockStore, budgetCfg, nil, nil, nil)
+		results, err := svc.UploadArchive(ctx, "owner-1", createTestZipHeader(t, entries), nil)
+		require.NoError(t, err)
+		require.Len(t, results, MaxBatchFiles)
+		for _, r := range results {
+			assert.True(t, r.Success, "%s: %s", r.FileName, r.Error)
+		}
+		mockRepo.AssertExpectations(t)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Not a zip archive", func(t *testing.T) {
+		header, err := createTestFileHeader("definitely not a zip", "bundle.zip")
+		require.NoError(t, err)
+
+		svc := NewFileService(new(MockFileRepository), new(MockStorage), cfg, nil, nil, nil)
+		_, err = svc.UploadArchive(ctx, "owner-1", header, nil)
+		assert.ErrorIs(t, err, ErrUnsafeArchive)
+	})
+}
+
+func TestFileService_UpdateFileMetadata(t *testing.T) {
+	ctx := context.Background()
+	ownerID := "user-owner-1"
+	fileID := "file-abc-123"
+	current := &model.FileMetadata{ID: fileID, OriginalName: "old.pdf", OwnerUserID: &ownerID, Version: 2}
+	renamed := &model.FileMetadata{ID: fileID, OriginalName: "new.pdf", OwnerUserID: &ownerID, Version: 3}
+	newName := "  new.pdf "
+	badName := "../etc/passwd"
+
+	testCases := []struct {
+		name          string
+		claims        jwt.MapClaims
+		update        repository.MetadataUpdate
+		setupMock     func(mockRepo *MockFileRepository)
+		expectedError error
+	}{
+		{
+			name:   "Success - Owner renames file with matching version",
+			claims: jwt.MapClaims{"sub": ownerID, "role": "user"},
+			update: repository.MetadataUpdate{OriginalName: &newName},
+			setupMock: func(mockRepo *MockFileRepository) {
+				mockRepo.On("GetByID", ctx, fileID).Return(current, nil).Once()
+				mockRepo.On("UpdateMetadata", ctx, fileID, int64(2), mock.MatchedBy(func(u repository.MetadataUpdate) bool {
+					return u.OriginalName != nil && *u.OriginalName == "new.pdf"
+				}), ownerID).Return(nil).Once()
+				mockRepo.On("GetByID", ctx, fileID).Return(renamed, nil).Once()
+			},
+		},
+		{
+			name:   "Failure - Tag-based reader cannot modify",
+			claims: jwt.MapClaims{"sub": "user-finance", "role": "finance"},
+			update: repository.MetadataUpdate{OriginalName: &newName},
+			setupMock: func(mockRepo *MockFileRepository) {
+				mockRepo.On("GetByID", ctx, fileID).Return(current, nil).Once()
+			},
+			expectedError: ErrAccessDenied,
+		},
+		{
+			name:          "Failure - Name with path separator",
+			claims:        jwt.MapClaims{"sub": ownerID, "role": "user"},
+			update:        repository.MetadataUpdate{OriginalName: &badName},
+			setupMock:     func(mockRepo *MockFileRepository) {},
+			expectedError: ErrInvalidMetadata,
+		},
+		{
+			name:   "Failure - Stale version",
+			claims: jwt.MapClaims{"sub": "admin-1", "role": "admin"},
+			update: repository.MetadataUpdate{OriginalName: &newName},
+			setupMock: func(mockRepo *MockFileRepository) {
+				mockRepo.On("GetByID", ctx, fileID).Return(current, nil).Once()
+				mockRepo.On("UpdateMetadata", ctx, fileID, int64(2), mock.Anything, "admin-1").Return(repository.ErrVersionConflict).Once()
+			},
+			expectedError: repository.ErrVersionConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockFileRepository)
+			tc.setupMock(mockRepo)
+
+			svc := NewFileService(mockRepo, new(MockStorage), &fileserviceconfig.Config{}, nil, nil, nil)
+			metadata, err := svc.UpdateFileMetadata(ctx, fileID, tc.update, 2, tc.claims)
+
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, metadata)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "new.pdf", metadata.OriginalName)
+				assert.Equal(t, int64(3), metadata.Version)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFileService_GetFileMetadata_CustomPolicies(t *testing.T) {
+	ownerID := "user-owner-1"
+	file := &model.FileMetadata{ID: "file-abc-123", OwnerUserID: &ownerID, Tags: []string{"rahasia"}}
+	claims := jwt.MapClaims{"sub": ownerID, "role": "user"}
+
+	engine, err := policy.NewEngine([]policy.Policy{
+		{Name: "office-only", Effect: policy.EffectDeny, Expression: `"rahasia" in file.tags && !ip_in_cidr(request.ip, "10.0.0.0/8")`},
+		{Name: "owner", Effect: policy.EffectAllow, Expression: `file.owner_user_id == subject.sub`},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		ip          string
+		expectError error
+	}{
+		{name: "Success - Owner inside office network", ip: "10.0.0.5"},
+		{name: "Failure - Deny policy overrides owner", ip: "203.0.113.7", expectError: ErrAccessDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := policy.WithRequest(context.Background(), policy.Request{IP: tc.ip})
+			mockRepo := new(MockFileRepository)
+			mockRepo.On("GetByID", ctx, file.ID).Return(file, nil).Once()
+
+			svc := NewFileService(mockRepo, new(MockStorage), &fileserviceconfig.Config{}, engine, nil, nil)
+			_, err := svc.GetFileMetadata(ctx, file.ID, claims)
+
+			assert.Equal(t, tc.expectError, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFileService_UploadStream(t *testing.T) {
+	ctx := context.Background()
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    16,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true},
+	}
+
+	t.Run("Success - Stream of unknown size", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStore := new(MockStorage)
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(m *model.FileMetadata) bool {
+			// SHA-256 dari "hello world".
+			return m.SizeBytes == 11 && m.ChecksumSHA256 == "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+		}), []string{"a"}).Return(nil).Once()
+		mockStore.On("Save", ctx, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+
+		svc := NewFileService(mockRepo, mockStore, cfg, nil, nil, nil)
+		metadata, err := svc.UploadStream(ctx, "owner-1", "note.txt", strings.NewReader("hello world"), []string{"a"})
+		require.NoError(t, err)
+		assert.Equal(t, "note.txt", metadata.OriginalName)
+		assert.Equal(t, metadata.ID+".txt", metadata.StoragePath)
+		mockRepo.AssertExpectations(t)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Stream larger than the limit", func(t *testing.T) {
+		svc := NewFileService(new(MockFileRepository), new(MockStorage), cfg, nil, nil, nil)
+		_, err := svc.UploadStream(ctx, "owner-1", "note.txt", strings.NewReader(strings.Repeat("x", 100)), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the limit")
+	})
+}
+
+func TestFileService_ApplyConfig(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockFileRepository)
+	mockStore := new(MockStorage)
+	svc := NewFileService(mockRepo, mockStore, &fileserviceconfig.Config{
+		MaxFileSizeBytes:    4,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true},
+	}, nil, nil, nil)
+
+	_, err := svc.UploadStream(ctx, "owner-1", "note.txt", strings.NewReader("hello world"), nil)
+	require.ErrorContains(t, err, "exceeds the limit")
+
+	svc.ApplyConfig(&fileserviceconfig.Config{
+		MaxFileSizeBytes:    16,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true},
+	})
+	mockRepo.On("Create", ctx, mock.Anything, []string(nil)).Return(nil).Once()
+	mockStore.On("Save", ctx, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+	_, err = svc.UploadStream(ctx, "owner-1", "note.txt", strings.NewReader("hello world"), nil)
+	require.NoError(t, err, "Batas baru harus berlaku untuk unggahan berikutnya")
+	mockRepo.AssertExpectations(t)
+	mockStore.AssertExpectations(t)
+}
+
+func TestStorageExtension(t *testing.T) {
+	pngType := mimetype.Detect([]byte(testPNG))
+	binary := mimetype.Detect([]byte{0x00, 0x01, 0x02, 0xfe})
+
+	testCases := []struct {
+		name     string
+		filename string
+		mime     *mimetype.MIME
+		expected string
+	}{
+		{"Detected type wins over client extension", "avatar.php", pngType, ".png"},
+		{"Detected type normalises case", "AVATAR.PNG", pngType, ".png"},
+		{"Unknown type keeps safe client extension", "data.BIN", binary, ".bin"},
+		{"Unknown type drops unsafe extension", "data.b$n", binary, ""},
+		{"Unknown type drops overly long extension", "data.abcdefghijk", binary, ""},
+		{"Unknown type drops path components", "../../etc/passwd", binary, ""},
+		{"No extension", "README", binary, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, storageExtension(tc.filename, tc.mime))
+		})
+	}
+}
+
+func TestStorageAttributes(t *testing.T) {
+	ownerID := "owner-1"
+	attrs := storageAttributes(&model.FileMetadata{ID: "file-1", OriginalName: "laporan kuartal.pdf", MimeType: "application/pdf", OwnerUserID: &ownerID})
+
+	assert.Equal(t, "application/pdf", attrs.ContentType)
+	assert.Equal(t, `attachment; filename="laporan kuartal.pdf"`, attrs.ContentDisposition)
+	assert.Equal(t, map[string]string{"file-id": "file-1", "owner-id": "owner-1"}, attrs.Metadata)
+
+	attrs = storageAttributes(&model.FileMetadata{ID: "file-2", OriginalName: "résumé.pdf", MimeType: "application/pdf"})
+	assert.Equal(t, "attachment; filename*=utf-8''r%C3%A9sum%C3%A9.pdf", attrs.ContentDisposition, "nama non-ASCII dikodekan RFC 2231")
+	assert.Equal(t, map[string]string{"file-id": "file-2"}, attrs.Metadata)
+}
+
+func TestFileService_ListFiles(t *testing.T) {
+	ctx := context.Background()
+	ownerID, otherID := "user-owner-1", "user-other-2"
+	filter := repository.FileFilter{Limit: 10}
+	mockRepo := new(MockFileRepository)
+	mockRepo.On("List", ctx, filter).Return([]*model.FileMetadata{
+		{ID: "mine", OwnerUserID: &ownerID},
+		{ID: "theirs", OwnerUserID: &otherID},
+	}, nil).Once()
+
+	svc := NewFileService(mockRepo, new(MockStorage), &fileserviceconfig.Config{}, nil, nil, nil)
+	files, err := svc.ListFiles(ctx, filter, jwt.MapClaims{"sub": ownerID, "role": "user"})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "mine", files[0].ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFileService_ListFiles_LimitCountsVisibleFiles(t *testing.T) {
+	ctx := context.Background()
+	ownerID, otherID := "user-owner-1", "user-other-2"
+	repo := repository.NewMemoryFileRepository(nil)
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "a-mine", OwnerUserID: &ownerID}, nil))
+	for i := range 5 {
+		require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: fmt.Sprintf("z-theirs-%d", i), OwnerUserID: &otherID}, nil))
+	}
+
+	svc := NewFileService(repo, storage.NewMemoryStorage(), &fileserviceconfig.Config{}, nil, nil, nil)
+	files, err := svc.ListFiles(ctx, repository.FileFilter{Limit: 2}, jwt.MapClaims{"sub": ownerID, "role": "user"})
+	require.NoError(t, err)
+	require.Len(t, files, 1, "File pengguna lain yang lebih baru tidak boleh menyembunyikan file milik pemanggil")
+	assert.Equal(t, "a-mine", files[0].ID)
+
+	files, err = svc.ListFiles(ctx, repository.FileFilter{Limit: 2}, jwt.MapClaims{"sub": "admin", "role": "admin"})
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestFileService_DeleteFile(t *testing.T) {
+	ctx := context.Background()
+	ownerID := "user-owner-1"
+	file := &model.FileMetadata{ID: "file-1", StoragePath: "file-1.pdf", OwnerUserID: &ownerID}
+
+	testCases := []struct {
+		name        string
+		claims      jwt.MapClaims
+		setupMock   func(*MockFileRepository, *MockStorage)
+		expectedErr error
+	}{
+		{
+			name:   "Success - Owner deletes file",
+			claims: jwt.MapClaims{"sub": ownerID, "role": "user"},
+			setupMock: func(repo *MockFileRepository, store *MockStorage) {
+				repo.On("DeleteByID", ctx, "file-1").Return(nil).Once()
+				store.On("Delete", ctx, "file-1.pdf").Return(nil).Once()
+			},
+		},
+		{
+			name:   "Success - Storage failure after metadata deletion is only logged",
+			claims: jwt.MapClaims{"sub": "user-admin", "role": "admin"},
+			setupMock: func(repo *MockFileRepository, store *MockStorage) {
+				repo.On("DeleteByID", ctx, "file-1").Return(nil).Once()
+				store.On("Delete", ctx, "file-1.pdf").Return(errors.New("disk error")).Once()
+			},
+		},
+		{
+			name:        "Failure - Tag access does not allow deletion",
+			claims:      jwt.MapClaims{"sub": "user-finance", "role": "finance"},
+			setupMock:   func(*MockFileRepository, *MockStorage) {},
+			expectedErr: ErrAccessDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockFileRepository)
+			mockStore := new(MockStorage)
+			mockRepo.On("GetByID", ctx, "file-1").Return(file, nil).Once()
+			tc.setupMock(mockRepo, mockStore)
+
+			svc := NewFileService(mockRepo, mockStore, &fileserviceconfig.Config{}, nil, nil, nil)
+			err := svc.DeleteFile(ctx, "file-1", tc.claims)
+			assert.Equal(t, tc.expectedErr, err)
+			mockRepo.AssertExpectations(t)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Batas jumlah job per halaman daftar job.
+const (
+	DefaultJobListLimit = 50
+	MaxJobListLimit     = 500
+)
+
+type JobService interface {
+	ListJobs(ctx context.Context, filter repository.JobFilter) ([]*model.Job, error)
+	GetJob(ctx context.Context, id string) (*model.Job, error)
+	// RetryJob menjadwalkan ulang job yang gagal dan mengembalikan job
+	// setelah dijadwalkan.
+	RetryJob(ctx context.Context, id, actorID string) (*model.Job, error)
+	// ListFileJobs mengembalikan job milik file yang dapat dibaca pemanggil.
+	ListFileJobs(ctx context.Context, fileID string, claims jwt.MapClaims) ([]*model.Job, error)
+	// GetArchiveJob mengembalikan job pembuatan arsip asinkron archiveID
+	// untuk pemilik arsip atau admin.
+	GetArchiveJob(ctx context.Context, archiveID string, claims jwt.MapClaims) (*model.Job, error)
+}
+
+type jobService struct {
+	repo  repository.JobRepository
+	files FileService
+}
+
+func NewJobService(repo repository.JobRepository, files FileService) JobService {
+	return &jobService{repo: repo, files: files}
+}
+
+func (s *jobService) ListJobs(ctx context.Context, filter repository.JobFilter) ([]*model.Job, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultJobListLimit
+	}
+	filter.Limit = min(filter.Limit, MaxJobListLimit)
+	return s.repo.ListJobs(ctx, filter)
+}
+
+func (s *jobService) GetJob(ctx context.Context, id string) (*model.Job, error) {
+	return s.repo.GetJob(ctx, id)
+}
+
+func (s *jobService) RetryJob(ctx context.Context, id, actorID string) (*model.Job, error) {
+	if err := s.repo.Retry(ctx, id); err != nil {
+		return nil, err
+	}
+	log.Info().Ctx(ctx).Str("job_id", id).Str("actor_id", actorID).Msg("Job yang gagal dijadwalkan ulang")
+	return s.repo.GetJob(ctx, id)
+}
+
+// ListFileJobs memakai otorisasi yang sama seperti GetFileMetadata.
+func (s *jobService) ListFileJobs(ctx context.Context, fileID string, claims jwt.MapClaims) ([]*model.Job, error) {
+	if _, err := s.files.GetFileMetadata(ctx, fileID, claims); err != nil {
+		return nil, err
+	}
+	return s.repo.ListJobs(ctx, repository.JobFilter{FileID: fileID, Limit: MaxJobListLimit})
+}
+
+// GetArchiveJob menyembunyikan job tipe lain di balik repository.ErrNotFound
+// agar endpoint status arsip tidak dapat dipakai membaca job sembarang.
+func (s *jobService) GetArchiveJob(ctx context.Context, archiveID string, claims jwt.MapClaims) (*model.Job, error) {
+	job, err := s.repo.GetJob(ctx, archiveID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Type != model.JobTypeBuildArchive {
+		return nil, repository.ErrNotFound
+	}
+	var payload archiveJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("payload job build_archive tidak valid: %w", err)
+	}
+	userID, _ := claims["sub"].(string)
+	role, _ := claims["role"].(string)
+	if payload.OwnerUserID != userID && role != "admin" {
+		return nil, ErrAccessDenied
+	}
+	return job, nil
+}
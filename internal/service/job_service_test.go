@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileService_UploadFile_EnqueuesTextExtraction(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	jobRepo := repository.NewMemoryJobRepository()
+	store := storage.NewMemoryStorage()
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true, "image/png": true},
+		SearchMaxTextBytes:  1024,
+	}
+	svc := NewFileService(repo, store, cfg, nil, jobRepo, nil)
+
+	header, err := createTestFileHeader("Laporan keuangan triwulan", "laporan.txt")
+	require.NoError(t, err)
+	text, err := svc.UploadFile(ctx, "user-1", header, nil)
+	require.NoError(t, err)
+	assert.Nil(t, text.IndexedAt, "Teks diekstrak oleh job, bukan saat unggah")
+
+	header, err = createTestFileHeader(testPNG, "foto.png")
+	require.NoError(t, err)
+	image, err := svc.UploadFile(ctx, "user-1", header, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, image.IndexedAt, "Tipe tanpa extractor tidak perlu job")
+
+	jobs, err := jobRepo.ListJobs(ctx, repository.JobFilter{})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, model.JobTypeExtractText, jobs[0].Type)
+	assert.Equal(t, text.ID, *jobs[0].FileID)
+
+	handler := ExtractTextJob(repo, repo, store, func() *fileserviceconfig.Config { return cfg })
+	require.NoError(t, handler(ctx, jobs[0]))
+	results, err := NewSearchService(repo, svc).Search(ctx, repository.SearchQuery{Text: "triwulan"}, jwt.MapClaims{"sub": "user-1"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.NotNil(t, results[0].File.IndexedAt)
+}
+
+func TestExtractTextJob(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	store := storage.NewMemoryStorage()
+	cfg := &fileserviceconfig.Config{SearchMaxTextBytes: 1024}
+	handler := ExtractTextJob(repo, repo, store, func() *fileserviceconfig.Config { return cfg })
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "hilang", OriginalName: "a.txt", StoragePath: "a.txt"}, nil))
+	fileID := func(id string) *string { return &id }
+
+	testCases := []struct {
+		name        string
+		job         *model.Job
+		expectError string
+	}{
+		{name: "Deleted file is skipped", job: &model.Job{FileID: fileID("tidak-ada")}},
+		{name: "Missing content is retried", job: &model.Job{FileID: fileID("hilang")}, expectError: "storage"},
+		{name: "Job without file fails permanently", job: &model.Job{}, expectError: "file_id"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := handler(ctx, tc.job)
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectError)
+		})
+	}
+}
+
+func TestJobService(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	jobRepo := repository.NewMemoryJobRepository()
+	ownerID := "user-1"
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "file-1", OriginalName: "a.txt", OwnerUserID: &ownerID}, nil))
+	fileID := "file-1"
+	job := &model.Job{Type: model.JobTypeExtractText, FileID: &fileID}
+	require.NoError(t, jobRepo.Enqueue(ctx, job))
+	svc := NewJobService(jobRepo, NewFileService(repo, nil, &fileserviceconfig.Config{}, nil, nil, nil))
+
+	t.Run("Owner sees file jobs", func(t *testing.T) {
+		jobs, err := svc.ListFileJobs(ctx, fileID, jwt.MapClaims{"sub": ownerID, "role": "user"})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		assert.Equal(t, job.ID, jobs[0].ID)
+	})
+
+	t.Run("Other user is denied", func(t *testing.T) {
+		_, err := svc.ListFileJobs(ctx, fileID, jwt.MapClaims{"sub": "user-2", "role": "user"})
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("Retry failed job", func(t *testing.T) {
+		_, err := svc.RetryJob(ctx, job.ID, "admin-1")
+		assert.ErrorIs(t, err, repository.ErrJobNotFailed)
+
+		_, err = jobRepo.Claim(ctx, model.JobTypeExtractText, 1, 0)
+		require.NoError(t, err)
+		require.NoError(t, jobRepo.Fail(ctx, job.ID, "rusak", nil))
+		retried, err := svc.RetryJob(ctx, job.ID, "admin-1")
+		require.NoError(t, err)
+		assert.Equal(t, model.JobStatusPending, retried.Status)
+		assert.Zero(t, retried.Attempts)
+	})
+
+	t.Run("Unknown job", func(t *testing.T) {
+		_, err := svc.RetryJob(ctx, "tidak-ada", "admin-1")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestBuildArchiveJob(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	jobRepo := repository.NewMemoryJobRepository()
+	store := storage.NewMemoryStorage()
+	cfg := &fileserviceconfig.Config{}
+	ownerID := "user-1"
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "file-1", OriginalName: "a.txt", StoragePath: "file-1.txt", MimeType: "text/plain", OwnerUserID: &ownerID}, nil))
+	require.NoError(t, store.Save(ctx, "file-1.txt", strings.NewReader("isi")))
+	files, err := repo.List(ctx, repository.FileFilter{IDs: []string{"file-1"}})
+	require.NoError(t, err)
+
+	t.Run("Async archive requires the job queue", func(t *testing.T) {
+		_, err := NewFileService(repo, store, cfg, nil, nil, nil).CreateArchiveAsync(ctx, ownerID, files, ArchiveRequest{Async: true})
+		assert.ErrorIs(t, err, ErrArchiveAsyncUnavailable)
+	})
+
+	svc := NewFileService(repo, store, cfg, nil, jobRepo, nil)
+	archiveID, err := svc.CreateArchiveAsync(ctx, ownerID, files, ArchiveRequest{Name: "laporan", Async: true})
+	require.NoError(t, err)
+	jobService := NewJobService(jobRepo, svc)
+
+	t.Run("Archive status is visible to its owner only", func(t *testing.T) {
+		job, err := jobService.GetArchiveJob(ctx, archiveID, jwt.MapClaims{"sub": ownerID, "role": "user"})
+		require.NoError(t, err)
+		assert.Equal(t, model.JobStatusPending, job.Status)
+
+		_, err = jobService.GetArchiveJob(ctx, archiveID, jwt.MapClaims{"sub": "user-2", "role": "user"})
+		assert.ErrorIs(t, err, ErrAccessDenied)
+		_, err = jobService.GetArchiveJob(ctx, archiveID, jwt.MapClaims{"sub": "admin-1", "role": "admin"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Job stores the archive as a file", func(t *testing.T) {
+		jobs, err := jobRepo.Claim(ctx, model.JobTypeBuildArchive, 1, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		handler := BuildArchiveJob(repo, store, func() *fileserviceconfig.Config { return cfg })
+		require.NoError(t, handler(ctx, jobs[0]))
+
+		archive, err := repo.GetByID(ctx, archiveID)
+		require.NoError(t, err)
+		assert.Equal(t, "laporan.zip", archive.OriginalName)
+		assert.Equal(t, ownerID, *archive.OwnerUserID)
+		assert.Positive(t, archive.SizeBytes)
+		assert.NotEmpty(t, archive.ChecksumSHA256)
+
+		require.NoError(t, handler(ctx, jobs[0]), "Percobaan ulang tidak membangun arsip kedua")
+	})
+
+	t.Run("Other job types are not archives", func(t *testing.T) {
+		fileID := "file-1"
+		job := &model.Job{Type: model.JobTypeExtractText, FileID: &fileID}
+		require.NoError(t, jobRepo.Enqueue(ctx, job))
+		_, err := jobService.GetArchiveJob(ctx, job.ID, jwt.MapClaims{"sub": ownerID, "role": "admin"})
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("Deleted sources fail permanently", func(t *testing.T) {
+		handler := BuildArchiveJob(repo, store, func() *fileserviceconfig.Config { return cfg })
+		err := handler(ctx, &model.Job{ID: "archive-2", Type: model.JobTypeBuildArchive, Payload: []byte(`{"owner_user_id":"user-1","file_ids":["tidak-ada"]}`)})
+		assert.ErrorIs(t, err, ErrArchiveEmpty)
+	})
+}
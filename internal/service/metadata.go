@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	maxOriginalNameLength = 255
+	maxTagLength          = 100
+)
+
+// ErrInvalidMetadata menandai input rename/tag yang tidak valid.
+var ErrInvalidMetadata = errors.New("metadata tidak valid")
+
+func (s *fileService) UpdateFileMetadata(ctx context.Context, fileID string, update repository.MetadataUpdate, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	if update.OriginalName != nil {
+		name := strings.TrimSpace(*update.OriginalName)
+		if err := validateOriginalName(name); err != nil {
+			return nil, err
+		}
+		update.OriginalName = &name
+	}
+	if update.Tags != nil {
+		tags := make([]string, 0, len(*update.Tags))
+		for _, tag := range *update.Tags {
+			tag = strings.TrimSpace(tag)
+			if err := ValidateTag(tag); err != nil {
+				return nil, err
+			}
+			tags = append(tags, tag)
+		}
+		update.Tags = &tags
+	}
+
+	return s.mutateFile(ctx, fileID, claims, func(actorID string) error {
+		return s.repo.UpdateMetadata(ctx, fileID, expectedVersion, update, actorID)
+	})
+}
+
+func (s *fileService) AddFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	tag = strings.TrimSpace(tag)
+	if err := ValidateTag(tag); err != nil {
+		return nil, err
+	}
+	return s.mutateFile(ctx, fileID, claims, func(actorID string) error {
+		return s.repo.AddTag(ctx, fileID, tag, expectedVersion, actorID)
+	})
+}
+
+func (s *fileService) RemoveFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	return s.mutateFile(ctx, fileID, claims, func(actorID string) error {
+		return s.repo.RemoveTag(ctx, fileID, strings.TrimSpace(tag), expectedVersion, actorID)
+	})
+}
+
+// mutateFile memastikan pemanggil boleh mengubah file (hanya pemilik atau
+// admin; akses baca lewat aturan tag tidak cukup), menjalankan perubahan, lalu
+// mengembalikan metadata terbaru beserta versinya.
+func (s *fileService) mutateFile(ctx context.Context, fileID string, claims jwt.MapClaims, apply func(actorID string) error) (*model.FileMetadata, error) {
+	metadata, err := s.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _ := claims["sub"].(string)
+	userRole, _ := claims["role"].(string)
+	isOwner := metadata.OwnerUserID != nil && *metadata.OwnerUserID == userID
+	if !isOwner && userRole != "admin" {
+		return nil, ErrAccessDenied
+	}
+
+	if err := apply(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, fileID)
+}
+
+func validateOriginalName(name string) error {
+	if name == "" || len(name) > maxOriginalNameLength {
+		return fmt.Errorf("%w: nama file harus 1-%d karakter", ErrInvalidMetadata, maxOriginalNameLength)
+	}
+	if strings.ContainsAny(name, `/\`) || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: nama file mengandung karakter terlarang", ErrInvalidMetadata)
+	}
+	return nil
+}
+
+// ValidateTag memeriksa panjang dan karakter sebuah tag; dipakai juga oleh
+// antarmuka lain (mis. WebDAV) yang menurunkan tag dari input pengguna.
+func ValidateTag(tag string) error {
+	if tag == "" || len(tag) > maxTagLength {
+		return fmt.Errorf("%w: tag harus 1-%d karakter", ErrInvalidMetadata, maxTagLength)
+	}
+	if strings.ContainsAny(tag, ",") || strings.IndexFunc(tag, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: tag mengandung karakter terlarang", ErrInvalidMetadata)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	pdfMimeType = "application/pdf"
+	// MaxPDFMergeFiles adalah jumlah maksimum file dalam satu penggabungan PDF.
+	MaxPDFMergeFiles = 50
+	// defaultPDFMergeName dipakai jika permintaan penggabungan tanpa nama.
+	defaultPDFMergeName = "gabungan.pdf"
+)
+
+var (
+	ErrPDFMergeTooFewFiles  = errors.New("penggabungan PDF memerlukan minimal 2 file")
+	ErrPDFMergeTooManyFiles = fmt.Errorf("penggabungan PDF melebihi batas %d file", MaxPDFMergeFiles)
+	// ErrPDFMergeTooLarge dikembalikan jika total ukuran file sumber melebihi
+	// batas unggahan, karena hasilnya juga disimpan sebagai unggahan.
+	ErrPDFMergeTooLarge = errors.New("total ukuran PDF sumber melebihi batas unggahan")
+	ErrNotPDF           = errors.New("file bukan PDF")
+)
+
+// PDFMergeRequest adalah body POST /files/pdf/merge. Halaman digabung sesuai
+// urutan FileIDs; ID yang sama boleh muncul lebih dari sekali.
+type PDFMergeRequest struct {
+	FileIDs []string `json:"file_ids" binding:"required"`
+	// Name adalah nama file hasil; ekstensi .pdf ditambahkan jika belum ada.
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// PDFService menyediakan operasi atas dokumen PDF yang tersimpan.
+type PDFService interface {
+	// Merge menggabungkan PDF yang dapat dibaca pemanggil menjadi file baru
+	// milik ownerID. Hasilnya melewati validasi unggahan biasa, dan file
+	// sumbernya dicatat di Provenance.
+	Merge(ctx context.Context, ownerID string, req PDFMergeRequest, claims jwt.MapClaims) (*model.FileMetadata, error)
+	// FirstPage mengembalikan PDF satu halaman berisi halaman pertama file,
+	// untuk pratinjau tanpa mengunduh seluruh dokumen. Hasilnya disimpan di
+	// storage sebagai cache.
+	FirstPage(ctx context.Context, fileID string, claims jwt.MapClaims) (*Rendition, error)
+}
+
+type pdfService struct {
+	files FileService
+	cache renditionCache
+	cfg   func() *fileserviceconfig.Config
+}
+
+// NewPDFService membuat PDFService. cfg dibaca setiap permintaan agar batas
+// unggahan yang dimuat ulang ikut berlaku.
+func NewPDFService(files FileService, renditions repository.RenditionRepository, store storage.Storage, cfg func() *fileserviceconfig.Config) PDFService {
+	return &pdfService{
+		files: files,
+		cache: renditionCache{renditions: renditions, storage: store},
+		cfg:   cfg,
+	}
+}
+
+func (s *pdfService) Merge(ctx context.Context, ownerID string, req PDFMergeRequest, claims jwt.MapClaims) (*model.FileMetadata, error) {
+	switch {
+	case len(req.FileIDs) < 2:
+		return nil, ErrPDFMergeTooFewFiles
+	case len(req.FileIDs) > MaxPDFMergeFiles:
+		return nil, ErrPDFMergeTooManyFiles
+	}
+
+	limit := s.cfg().UploadSizeLimit()
+	var total int64
+	parts := make([]pdf.Part, 0, len(req.FileIDs))
+	provenance := &model.Provenance{Operation: model.ProvenancePDFMerge}
+	for _, id := range req.FileIDs {
+		metadata, err := s.files.GetFileMetadata(ctx, id, claims)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", id, err)
+		}
+		if !isPDF(metadata.MimeType) {
+			return nil, fmt.Errorf("%w: %s (%s)", ErrNotPDF, id, metadata.MimeType)
+		}
+		if total += metadata.SizeBytes; total > limit {
+			return nil, fmt.Errorf("%w (%d byte)", ErrPDFMergeTooLarge, limit)
+		}
+		doc, err := s.openPDF(ctx, metadata)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", id, err)
+		}
+		if doc.Encrypted() {
+			return nil, fmt.Errorf("file %s: %w", id, pdf.ErrEncrypted)
+		}
+		parts = append(parts, pdf.Part{Doc: doc})
+		provenance.Sources = append(provenance.Sources, model.ProvenanceSource{
+			FileID:         metadata.ID,
+			OriginalName:   metadata.OriginalName,
+			ChecksumSHA256: metadata.ChecksumSHA256,
+			PageCount:      doc.NumPages(),
+		})
+	}
+
+	var merged bytes.Buffer
+	if err := pdf.Merge(&merged, parts); err != nil {
+		return nil, err
+	}
+	metadata, err := s.files.UploadStream(withProvenance(ctx, provenance), ownerID, mergeFileName(req.Name), &merged, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Ctx(ctx).Str("file_id", metadata.ID).Strs("source_ids", req.FileIDs).Str("owner_id", ownerID).Msg("PDF berhasil digabung")
+	return metadata, nil
+}
+
+func (s *pdfService) FirstPage(ctx context.Context, fileID string, claims jwt.MapClaims) (*Rendition, error) {
+	metadata, err := s.files.GetFileMetadata(ctx, fileID, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !isPDF(metadata.MimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrNotPDF, metadata.MimeType)
+	}
+	path := renditionPrefix + metadata.ID + "/page-1.pdf"
+	// Isi file tidak pernah berubah setelah diunggah, jadi ID file cukup
+	// sebagai ETag.
+	rendition := &Rendition{ContentType: pdfMimeType, Size: -1, ETag: `"` + metadata.ID + `-page-1"`}
+	if cached, ok := s.cache.get(ctx, metadata.ID, path); ok {
+		rendition.Content = cached
+		return rendition, nil
+	}
+
+	doc, err := s.openPDF(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := pdf.Merge(&out, []pdf.Part{{Doc: doc, Pages: []int{0}}}); err != nil {
+		return nil, err
+	}
+	s.cache.put(ctx, metadata.ID, path, out.Bytes(), pdfMimeType)
+
+	rendition.Content = io.NopCloser(&out)
+	rendition.Size = int64(out.Len())
+	return rendition, nil
+}
+
+func (s *pdfService) openPDF(ctx context.Context, metadata *model.FileMetadata) (*pdf.Document, error) {
+	content, err := readFile(ctx, s.files, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return pdf.Open(content)
+}
+
+// mergeFileName menormalkan nama file hasil penggabungan.
+func mergeFileName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return defaultPDFMergeName
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".pdf") {
+		name += ".pdf"
+	}
+	return name
+}
+
+func isPDF(mimeType string) bool {
+	return strings.TrimSpace(strings.Split(mimeType, ";")[0]) == pdfMimeType
+}
+
+// inspectPDF membaca jumlah halaman dan properti dokumen PDF yang diunggah.
+// Kegagalan hanya dicatat: PDF yang lolos validasi tetap disimpan tanpa
+// informasi dokumen.
+func inspectPDF(content io.ReaderAt, size int64, filename string) *model.PDFInfo {
+	data, err := io.ReadAll(io.NewSectionReader(content, 0, size))
+	if err != nil {
+		log.Warn().Err(err).Str("file_name", filename).Msg("Gagal membaca PDF untuk informasi dokumen")
+		return nil
+	}
+	doc, err := pdf.Open(data)
+	if err != nil {
+		log.Warn().Err(err).Str("file_name", filename).Msg("Struktur PDF tidak dapat dibaca, informasi dokumen dilewati")
+		return nil
+	}
+	info := doc.Info()
+	return &model.PDFInfo{
+		PageCount: doc.NumPages(),
+		Encrypted: doc.Encrypted(),
+		Title:     info.Title,
+		Author:    info.Author,
+		Subject:   info.Subject,
+		Creator:   info.Creator,
+		Producer:  info.Producer,
+	}
+}
+
+type provenanceKey struct{}
+
+// withProvenance menitipkan asal-usul file ke storeUpload lewat context,
+// seperti uploadpolicy.WithRequest untuk kebijakan upload.
+func withProvenance(ctx context.Context, provenance *model.Provenance) context.Context {
+	return context.WithValue(ctx, provenanceKey{}, provenance)
+}
+
+func provenanceFromContext(ctx context.Context) *model.Provenance {
+	provenance, _ := ctx.Value(provenanceKey{}).(*model.Provenance)
+	return provenance
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTestPDF menyusun PDF dengan tabel xref yang benar. objects[i] adalah
+// isi objek nomor i+1.
+func buildTestPDF(trailer string, objects ...string) string {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	start := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, start)
+	return b.String()
+}
+
+// testPDF menyusun PDF berjudul title dengan pages halaman kosong.
+func testPDF(title string, pages int, trailer string) string {
+	kids := ""
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", "", fmt.Sprintf("<< /Title (%s) >>", title)}
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", i+4)
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>")
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, pages)
+	return buildTestPDF("/Root 1 0 R /Info 3 0 R "+trailer, objects...)
+}
+
+type pdfFixture struct {
+	repo    *repository.MemoryFileRepository
+	store   *storage.MemoryStorage
+	files   FileService
+	service PDFService
+	claims  jwt.MapClaims
+	// uploaded berisi metadata hasil unggah per nama file.
+	uploaded map[string]*model.FileMetadata
+}
+
+func newPDFFixture(t *testing.T) *pdfFixture {
+	t.Helper()
+	repo := repository.NewMemoryFileRepository(nil)
+	store := storage.NewMemoryStorage()
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1 << 20,
+		AllowedMimeTypesMap: map[string]bool{"application/pdf": true, "text/plain": true},
+	}
+	files := NewFileService(repo, store, cfg, nil, nil, nil)
+	f := &pdfFixture{
+		repo:     repo,
+		store:    store,
+		files:    files,
+		service:  NewPDFService(files, repo, store, func() *fileserviceconfig.Config { return cfg }),
+		claims:   jwt.MapClaims{"sub": "user-1", "role": "user"},
+		uploaded: make(map[string]*model.FileMetadata),
+	}
+	for _, upload := range []struct{ owner, name, content string }{
+		{"user-1", "surat.pdf", testPDF("Surat", 2, "")},
+		{"user-1", "lampiran.pdf", testPDF("Lampiran", 1, "")},
+		{"user-1", "catatan.txt", "bukan pdf"},
+		{"user-1", "rahasia.pdf", testPDF("Rahasia", 1, "/Encrypt << /Filter /Standard >>")},
+		{"user-2", "milik-lain.pdf", testPDF("Lain", 1, "")},
+	} {
+		header, err := createTestFileHeader(upload.content, upload.name)
+		require.NoError(t, err)
+		metadata, err := files.UploadFile(context.Background(), upload.owner, header, nil)
+		require.NoError(t, err)
+		f.uploaded[upload.name] = metadata
+	}
+	return f
+}
+
+func (f *pdfFixture) id(name string) string { return f.uploaded[name].ID }
+
+func TestFileService_UploadFile_ReadsPDFInfo(t *testing.T) {
+	f := newPDFFixture(t)
+
+	assert.Equal(t, &model.PDFInfo{PageCount: 2, Title: "Surat"}, f.uploaded["surat.pdf"].PDF)
+	assert.Equal(t, &model.PDFInfo{PageCount: 1, Encrypted: true}, f.uploaded["rahasia.pdf"].PDF)
+	assert.Nil(t, f.uploaded["catatan.txt"].PDF)
+	assert.Nil(t, f.uploaded["surat.pdf"].Provenance)
+
+	stored, err := f.repo.GetByID(context.Background(), f.id("surat.pdf"))
+	require.NoError(t, err)
+	assert.Equal(t, f.uploaded["surat.pdf"].PDF, stored.PDF)
+}
+
+func TestPDFService_Merge(t *testing.T) {
+	ctx := context.Background()
+	f := newPDFFixture(t)
+	req := PDFMergeRequest{FileIDs: []string{f.id("surat.pdf"), f.id("lampiran.pdf"), f.id("surat.pdf")}, Name: "paket", Tags: []string{"persetujuan"}}
+
+	merged, err := f.service.Merge(ctx, "user-1", req, f.claims)
+	require.NoError(t, err)
+
+	assert.Equal(t, "paket.pdf", merged.OriginalName)
+	assert.Equal(t, "user-1", *merged.OwnerUserID)
+	require.NotNil(t, merged.PDF)
+	assert.Equal(t, 5, merged.PDF.PageCount)
+	require.NotNil(t, merged.Provenance)
+	assert.Equal(t, model.ProvenancePDFMerge, merged.Provenance.Operation)
+	require.Len(t, merged.Provenance.Sources, 3)
+	assert.Equal(t, model.ProvenanceSource{
+		FileID:         f.id("lampiran.pdf"),
+		OriginalName:   "lampiran.pdf",
+		ChecksumSHA256: f.uploaded["lampiran.pdf"].ChecksumSHA256,
+		PageCount:      1,
+	}, merged.Provenance.Sources[1])
+
+	stored, err := f.repo.GetByID(ctx, merged.ID)
+	require.NoError(t, err)
+	assert.Equal(t, merged.Provenance, stored.Provenance)
+	assert.Equal(t, []string{"persetujuan"}, stored.Tags)
+
+	content, err := readFile(ctx, f.files, stored)
+	require.NoError(t, err)
+	doc, err := pdf.Open(content)
+	require.NoError(t, err)
+	assert.Equal(t, 5, doc.NumPages())
+}
+
+func TestPDFService_Merge_Rejects(t *testing.T) {
+	f := newPDFFixture(t)
+	otherIDs := func(names ...string) []string {
+		ids := []string{f.id("surat.pdf")}
+		for _, name := range names {
+			ids = append(ids, f.id(name))
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name          string
+		fileIDs       []string
+		expectedError error
+	}{
+		{name: "Single file", fileIDs: otherIDs(), expectedError: ErrPDFMergeTooFewFiles},
+		{name: "Too many files", fileIDs: make([]string, MaxPDFMergeFiles+1), expectedError: ErrPDFMergeTooManyFiles},
+		{name: "Not a PDF", fileIDs: otherIDs("catatan.txt"), expectedError: ErrNotPDF},
+		{name: "Encrypted PDF", fileIDs: otherIDs("rahasia.pdf"), expectedError: pdf.ErrEncrypted},
+		{name: "File of another user", fileIDs: otherIDs("milik-lain.pdf"), expectedError: ErrAccessDenied},
+		{name: "Missing file", fileIDs: []string{f.id("surat.pdf"), "tidak-ada"}, expectedError: repository.ErrNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := f.service.Merge(context.Background(), "user-1", PDFMergeRequest{FileIDs: tc.fileIDs}, f.claims)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestPDFService_FirstPage(t *testing.T) {
+	ctx := context.Background()
+	f := newPDFFixture(t)
+	surat := f.uploaded["surat.pdf"]
+
+	first, err := f.service.FirstPage(ctx, surat.ID, f.claims)
+	require.NoError(t, err)
+	content, err := io.ReadAll(first.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", first.ContentType)
+	assert.Equal(t, int64(len(content)), first.Size)
+	doc, err := pdf.Open(content)
+	require.NoError(t, err)
+	assert.Equal(t, 1, doc.NumPages())
+
+	// Permintaan kedua dilayani dari cache walaupun sumbernya hilang.
+	require.NoError(t, f.store.Delete(ctx, surat.StoragePath))
+	second, err := f.service.FirstPage(ctx, surat.ID, f.claims)
+	require.NoError(t, err)
+	cached, err := io.ReadAll(second.Content)
+	require.NoError(t, err)
+	assert.Equal(t, content, cached)
+	assert.Equal(t, first.ETag, second.ETag)
+
+	_, err = f.service.FirstPage(ctx, f.id("catatan.txt"), f.claims)
+	assert.ErrorIs(t, err, ErrNotPDF)
+	_, err = f.service.FirstPage(ctx, f.id("rahasia.pdf"), f.claims)
+	assert.ErrorIs(t, err, pdf.ErrEncrypted)
+	_, err = f.service.FirstPage(ctx, f.id("milik-lain.pdf"), f.claims)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestMergeFileName(t *testing.T) {
+	assert.Equal(t, "gabungan.pdf", mergeFileName("  "))
+	assert.Equal(t, "paket.pdf", mergeFileName("paket"))
+	assert.Equal(t, "Paket.PDF", mergeFileName("Paket.PDF"))
+	assert.Equal(t, "paket.v2.pdf", mergeFileName("paket.v2"))
+}
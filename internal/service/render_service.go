@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/imaging"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrInvalidRenderSignature dikembalikan jika parameter URL render tidak
+	// cocok dengan tanda tangannya.
+	ErrInvalidRenderSignature = errors.New("tanda tangan URL render tidak valid")
+	// ErrRenderURLExpired dikembalikan jika URL render sudah melewati expires.
+	ErrRenderURLExpired = errors.New("URL render sudah kedaluwarsa")
+	// ErrNotRenderable dikembalikan untuk file yang bukan gambar JPEG, PNG
+	// atau GIF, atau PDF yang halaman pertamanya bukan gambar pindaian.
+	ErrNotRenderable = errors.New("file bukan gambar yang dapat dirender")
+)
+
+// renditionPrefix adalah awalan path storage hasil render.
+const renditionPrefix = "renditions/"
+
+// SignedRenderURL adalah URL render bertanda tangan yang dapat dipakai tanpa
+// token, mis. sebagai src elemen <img>.
+type SignedRenderURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Rendition adalah turunan file: gambar hasil render atau pratinjau PDF. Size
+// bernilai -1 jika tidak diketahui (diambil dari cache storage). Pemanggil
+// wajib menutup Content.
+type Rendition struct {
+	Content     io.ReadCloser
+	ContentType string
+	Size        int64
+	// ETag stabil untuk file dan parameter transformasi yang sama.
+	ETag string
+	// ExpiresAt adalah masa berlaku URL render yang dipakai; nol untuk
+	// turunan yang diambil dengan token.
+	ExpiresAt time.Time
+}
+
+// RenderService mengubah ukuran dan format gambar sesuai parameter w, h, fit,
+// format dan quality (lihat imaging.ParseOptions). PDF hasil pindaian dirender
+// dari gambar halaman pertamanya (lihat pdf.Document.PageImage). Hasil render
+// disimpan di storage dan dipakai ulang untuk file dan parameter yang sama.
+type RenderService interface {
+	// SignURL membuat URL render untuk file yang dapat dibaca pemanggil.
+	SignURL(ctx context.Context, fileID string, query url.Values, claims jwt.MapClaims) (*SignedRenderURL, error)
+	// Render memeriksa tanda tangan query (expires dan sig) lalu
+	// mengembalikan gambar hasil render.
+	Render(ctx context.Context, fileID string, query url.Values) (*Rendition, error)
+}
+
+type renderService struct {
+	files      FileService
+	repo       repository.FileRepository
+	cache      renditionCache
+	signingKey []byte
+	cfg        func() *fileserviceconfig.Config
+	now        func() time.Time
+}
+
+// NewRenderService membuat RenderService. serverKey dipakai untuk menurunkan
+// kunci tanda tangan URL render; cfg dibaca setiap permintaan agar batas
+// dimensi dan masa berlaku URL dapat dimuat ulang.
+func NewRenderService(files FileService, repo repository.FileRepository, renditions repository.RenditionRepository, store storage.Storage, serverKey []byte, cfg func() *fileserviceconfig.Config) RenderService {
+	mac := hmac.New(sha256.New, serverKey)
+	mac.Write([]byte("prism-render-key"))
+	return &renderService{
+		files:      files,
+		repo:       repo,
+		cache:      renditionCache{renditions: renditions, storage: store},
+		signingKey: mac.Sum(nil),
+		cfg:        cfg,
+		now:        time.Now,
+	}
+}
+
+func (s *renderService) SignURL(ctx context.Context, fileID string, query url.Values, claims jwt.MapClaims) (*SignedRenderURL, error) {
+	opts, err := imaging.ParseOptions(query, s.cfg().RenderMaxDimension)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := s.files.GetFileMetadata(ctx, fileID, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !renderable(metadata.MimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrNotRenderable, metadata.MimeType)
+	}
+
+	expiresAt := s.now().Add(s.cfg().RenderURLTTL).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	canonical := opts.Query()
+	signed := url.Values{"expires": {expires}, "sig": {s.sign(metadata.ID, canonical, expires)}}
+	return &SignedRenderURL{
+		URL:       "/files/" + url.PathEscape(metadata.ID) + "/render?" + canonical + "&" + signed.Encode(),
+		ExpiresAt: expiresAt.UTC(),
+	}, nil
+}
+
+// sign menghitung tanda tangan atas ID file, parameter kanonis dan expires.
+func (s *renderService) sign(fileID, canonical, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(fileID + "\n" + canonical + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *renderService) Render(ctx context.Context, fileID string, query url.Values) (*Rendition, error) {
+	cfg := s.cfg()
+	opts, err := imaging.ParseOptions(query, cfg.RenderMaxDimension)
+	if err != nil {
+		return nil, err
+	}
+	expires := query.Get("expires")
+	expected := s.sign(fileID, opts.Query(), expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("sig"))) {
+		return nil, ErrInvalidRenderSignature
+	}
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidRenderSignature
+	}
+	expiresAt := time.Unix(expiresUnix, 0)
+	if !s.now().Before(expiresAt) {
+		return nil, ErrRenderURLExpired
+	}
+
+	metadata, err := s.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if !renderable(metadata.MimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrNotRenderable, metadata.MimeType)
+	}
+	sourceMime := metadata.MimeType
+	if isPDF(sourceMime) {
+		sourceMime = "image/jpeg"
+	}
+	opts = opts.Resolve(sourceMime)
+	sum := sha256.Sum256([]byte(opts.Query()))
+	key := hex.EncodeToString(sum[:16])
+	path := renditionPrefix + metadata.ID + "/" + key + "." + opts.Format
+	rendition := &Rendition{ContentType: opts.ContentType(), Size: -1, ETag: `"` + key + `"`, ExpiresAt: expiresAt}
+	if cached, ok := s.cache.get(ctx, metadata.ID, path); ok {
+		rendition.Content = cached
+		return rendition, nil
+	}
+
+	content, err := readFile(ctx, s.files, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if isPDF(metadata.MimeType) {
+		if content, err = scannedFirstPage(content); err != nil {
+			return nil, err
+		}
+	}
+	var out bytes.Buffer
+	if err := imaging.Render(&out, bytes.NewReader(content), int64(len(content)), sourceMime, opts, cfg.ContentValidation.MaxImagePixels); err != nil {
+		return nil, err
+	}
+	s.cache.put(ctx, metadata.ID, path, out.Bytes(), rendition.ContentType)
+
+	rendition.Content = io.NopCloser(&out)
+	rendition.Size = int64(out.Len())
+	return rendition, nil
+}
+
+func renderable(mimeType string) bool {
+	return imaging.Supports(mimeType) || isPDF(mimeType)
+}
+
+// scannedFirstPage mengembalikan JPEG hasil pindaian di halaman pertama PDF.
+func scannedFirstPage(content []byte) ([]byte, error) {
+	doc, err := pdf.Open(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", imaging.ErrInvalidImage, err)
+	}
+	page, ok := doc.PageImage(0)
+	if !ok {
+		return nil, fmt.Errorf("%w: halaman pertama PDF bukan gambar pindaian", ErrNotRenderable)
+	}
+	return page, nil
+}
+
+// readFile membaca seluruh isi file lewat FileService.OpenFile sehingga
+// checksum-nya ikut diverifikasi.
+func readFile(ctx context.Context, files FileService, metadata *model.FileMetadata) ([]byte, error) {
+	source, err := files.OpenFile(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(source)
+	if closeErr := source.Close(); closeErr != nil {
+		log.Warn().Ctx(ctx).Err(closeErr).Str("file_id", metadata.ID).Msg("Gagal menutup file sumber")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file sumber: %w", err)
+	}
+	return content, nil
+}
+
+// renditionCache menyimpan turunan file (hasil render, pratinjau PDF) di
+// storage. Path dicatat di metadata file sebelum disimpan agar cache tidak
+// tertinggal jika file dihapus; menghapus path yang belum tersimpan bukan
+// error.
+type renditionCache struct {
+	renditions repository.RenditionRepository
+	storage    storage.Storage
+}
+
+func (c renditionCache) get(ctx context.Context, fileID, path string) (io.ReadCloser, bool) {
+	cached, err := c.storage.Get(ctx, path)
+	if err == nil {
+		return cached, true
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		log.Warn().Ctx(ctx).Err(err).Str("file_id", fileID).Str("storage_path", path).Msg("Gagal membaca cache turunan file, turunan dibuat ulang")
+	}
+	return nil, false
+}
+
+func (c renditionCache) put(ctx context.Context, fileID, path string, content []byte, contentType string) {
+	if err := c.renditions.RecordRendition(ctx, fileID, path); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("file_id", fileID).Msg("Gagal mencatat cache turunan file, hasil tidak disimpan")
+	} else if err := storage.SaveWithAttributes(ctx, c.storage, path, bytes.NewReader(content), storage.ObjectAttributes{ContentType: contentType}); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("file_id", fileID).Str("storage_path", path).Msg("Gagal menyimpan cache turunan file")
+	}
+}
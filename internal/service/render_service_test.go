@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/imaging"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type renderFixture struct {
+	repo    *repository.MemoryFileRepository
+	store   *storage.MemoryStorage
+	files   FileService
+	service *renderService
+	avatar  *model.FileMetadata
+}
+
+func newRenderFixture(t *testing.T) *renderFixture {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	store := storage.NewMemoryStorage()
+	cfg := &fileserviceconfig.Config{RenderMaxDimension: 512, RenderURLTTL: time.Hour}
+	files := NewFileService(repo, store, cfg, nil, nil, nil)
+	svc := NewRenderService(files, repo, repo, store, []byte("rahasia"), func() *fileserviceconfig.Config { return cfg }).(*renderService)
+
+	var content bytes.Buffer
+	require.NoError(t, png.Encode(&content, image.NewRGBA(image.Rect(0, 0, 200, 100))))
+	owner := "user-1"
+	avatar := &model.FileMetadata{ID: "avatar", OriginalName: "avatar.png", StoragePath: "avatar.png", MimeType: "image/png", SizeBytes: int64(content.Len()), OwnerUserID: &owner}
+	require.NoError(t, repo.Create(ctx, avatar, nil))
+	require.NoError(t, store.Save(ctx, avatar.StoragePath, &content))
+	document := &model.FileMetadata{ID: "dokumen", OriginalName: "a.txt", StoragePath: "a.txt", MimeType: "text/plain", OwnerUserID: &owner}
+	require.NoError(t, repo.Create(ctx, document, nil))
+
+	return &renderFixture{repo: repo, store: store, files: files, service: svc, avatar: avatar}
+}
+
+// signedQuery membuat URL render lalu mengembalikan query-nya.
+func (f *renderFixture) signedQuery(t *testing.T, rawQuery string) url.Values {
+	t.Helper()
+	query, err := url.ParseQuery(rawQuery)
+	require.NoError(t, err)
+	signed, err := f.service.SignURL(context.Background(), f.avatar.ID, query, jwt.MapClaims{"sub": "user-1"})
+	require.NoError(t, err)
+	parsed, err := url.Parse(signed.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "/files/avatar/render", parsed.Path)
+	return parsed.Query()
+}
+
+func TestRenderService_RenderAndCache(t *testing.T) {
+	ctx := context.Background()
+	f := newRenderFixture(t)
+	query := f.signedQuery(t, "w=64&h=64&fit=cover&format=jpeg")
+
+	first, err := f.service.Render(ctx, f.avatar.ID, query)
+	require.NoError(t, err)
+	rendered, err := io.ReadAll(first.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", first.ContentType)
+	assert.Equal(t, int64(len(rendered)), first.Size)
+	decoded, _, err := image.Decode(bytes.NewReader(rendered))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(64, 64), decoded.Bounds().Size())
+
+	metadata, err := f.repo.GetByID(ctx, f.avatar.ID)
+	require.NoError(t, err)
+	require.Len(t, metadata.RenditionPaths, 1)
+	assert.True(t, strings.HasPrefix(metadata.RenditionPaths[0], "renditions/avatar/"))
+
+	// Permintaan kedua dilayani dari cache storage walaupun sumbernya hilang.
+	require.NoError(t, f.store.Delete(ctx, f.avatar.StoragePath))
+	second, err := f.service.Render(ctx, f.avatar.ID, query)
+	require.NoError(t, err)
+	cached, err := io.ReadAll(second.Content)
+	require.NoError(t, err)
+	assert.Equal(t, rendered, cached)
+	assert.Equal(t, int64(-1), second.Size)
+	assert.Equal(t, first.ETag, second.ETag)
+}
+
+func TestRenderService_RenderScannedPDF(t *testing.T) {
+	ctx := context.Background()
+	f := newRenderFixture(t)
+	var scan bytes.Buffer
+	require.NoError(t, jpeg.Encode(&scan, image.NewGray(image.Rect(0, 0, 850, 1100)), nil))
+	owner := "user-1"
+	store := func(id, content string) {
+		metadata := &model.FileMetadata{ID: id, OriginalName: id + ".pdf", StoragePath: id + ".pdf", MimeType: "application/pdf", SizeBytes: int64(len(content)), OwnerUserID: &owner}
+		require.NoError(t, f.repo.Create(ctx, metadata, nil))
+		require.NoError(t, f.store.Save(ctx, metadata.StoragePath, strings.NewReader(content)))
+	}
+	store("pindaian", buildTestPDF("/Root 1 0 R",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im0 4 0 R >> >> >>",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width 850 /Height 1100 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream", scan.Len(), scan.String())))
+	store("teks", testPDF("Teks", 1, ""))
+
+	claims := jwt.MapClaims{"sub": "user-1"}
+	signed, err := f.service.SignURL(ctx, "pindaian", url.Values{"w": {"85"}}, claims)
+	require.NoError(t, err)
+	parsed, err := url.Parse(signed.URL)
+	require.NoError(t, err)
+	rendition, err := f.service.Render(ctx, "pindaian", parsed.Query())
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", rendition.ContentType)
+	decoded, _, err := image.Decode(rendition.Content)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(85, 110), decoded.Bounds().Size())
+
+	signed, err = f.service.SignURL(ctx, "teks", url.Values{"w": {"85"}}, claims)
+	require.NoError(t, err)
+	parsed, err = url.Parse(signed.URL)
+	require.NoError(t, err)
+	_, err = f.service.Render(ctx, "teks", parsed.Query())
+	assert.ErrorIs(t, err, ErrNotRenderable, "PDF tanpa gambar pindaian tidak dapat dirender")
+}
+
+func TestRenderService_DeleteFileRemovesRenditions(t *testing.T) {
+	ctx := context.Background()
+	f := newRenderFixture(t)
+	_, err := f.service.Render(ctx, f.avatar.ID, f.signedQuery(t, "w=32"))
+	require.NoError(t, err)
+	metadata, err := f.repo.GetByID(ctx, f.avatar.ID)
+	require.NoError(t, err)
+	require.Len(t, metadata.RenditionPaths, 1)
+
+	require.NoError(t, f.files.DeleteFile(ctx, f.avatar.ID, jwt.MapClaims{"sub": "user-1"}))
+	_, err = f.store.Get(ctx, metadata.RenditionPaths[0])
+	assert.ErrorIs(t, err, storage.ErrNotFound, "Cache render harus ikut terhapus")
+}
+
+func TestRenderService_Render_Rejects(t *testing.T) {
+	f := newRenderFixture(t)
+	valid := f.signedQuery(t, "w=64")
+
+	testCases := []struct {
+		name          string
+		fileID        string
+		query         func() url.Values
+		now           time.Time
+		expectedError error
+	}{
+		{
+			name:   "Tampered dimensions",
+			fileID: f.avatar.ID,
+			query: func() url.Values {
+				q := url.Values{}
+				for k, v := range valid {
+					q[k] = v
+				}
+				q.Set("w", "512")
+				return q
+			},
+			expectedError: ErrInvalidRenderSignature,
+		},
+		{
+			name:          "Signature for another file",
+			fileID:        "dokumen",
+			query:         func() url.Values { return valid },
+			expectedError: ErrInvalidRenderSignature,
+		},
+		{
+			name:          "Missing signature",
+			fileID:        f.avatar.ID,
+			query:         func() url.Values { return url.Values{"w": {"64"}} },
+			expectedError: ErrInvalidRenderSignature,
+		},
+		{
+			name:          "Expired URL",
+			fileID:        f.avatar.ID,
+			query:         func() url.Values { return valid },
+			now:           time.Now().Add(2 * time.Hour),
+			expectedError: ErrRenderURLExpired,
+		},
+		{
+			name:          "Dimension above limit",
+			fileID:        f.avatar.ID,
+			query:         func() url.Values { return url.Values{"w": {"4096"}} },
+			expectedError: imaging.ErrInvalidOptions,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f.service.now = time.Now
+			if !tc.now.IsZero() {
+				f.service.now = func() time.Time { return tc.now }
+			}
+			_, err := f.service.Render(context.Background(), tc.fileID, tc.query())
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRenderService_SignURL_Rejects(t *testing.T) {
+	f := newRenderFixture(t)
+	query := url.Values{"w": {"64"}}
+
+	_, err := f.service.SignURL(context.Background(), f.avatar.ID, query, jwt.MapClaims{"sub": "user-2", "role": "user"})
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	_, err = f.service.SignURL(context.Background(), "dokumen", query, jwt.MapClaims{"sub": "user-1"})
+	assert.ErrorIs(t, err, ErrNotRenderable)
+
+	_, err = f.service.SignURL(context.Background(), f.avatar.ID, url.Values{}, jwt.MapClaims{"sub": "user-1"})
+	assert.ErrorIs(t, err, imaging.ErrInvalidOptions)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxS3CredentialsPerUser membatasi jumlah access key aktif per pengguna.
+const MaxS3CredentialsPerUser = 10
+
+var ErrTooManyS3Credentials = fmt.Errorf("maksimal %d access key S3 per pengguna", MaxS3CredentialsPerUser)
+
+// ErrS3CredentialTokenInvalid dikembalikan jika JWT penerbit tidak membawa
+// klaim exp dan jti, sehingga masa berlaku access key tidak dapat diikat.
+var ErrS3CredentialTokenInvalid = errors.New("token harus memiliki klaim exp dan jti untuk menerbitkan access key S3")
+
+// ErrS3CredentialExpired dikembalikan Lookup jika token penerbit sudah
+// kedaluwarsa atau dicabut. Error ini membungkus repository.ErrNotFound agar
+// gateway dan DAV memperlakukannya seperti access key yang tidak dikenal.
+var ErrS3CredentialExpired = fmt.Errorf("access key S3 sudah tidak berlaku: %w", repository.ErrNotFound)
+
+// S3CredentialService menerbitkan access key SigV4 untuk gateway S3. Peran
+// pada credential diambil dari JWT saat credential dibuat, sehingga access key
+// hanya berlaku selama JWT tersebut berlaku: sampai klaim exp dan selama jti
+// tidak dicabut. Perubahan peran mencabut token lama dan ikut mematikan access
+// key yang diterbitkannya.
+type S3CredentialService interface {
+	CreateCredential(ctx context.Context, claims jwt.MapClaims) (*model.S3Credential, error)
+	ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error)
+	DeleteCredential(ctx context.Context, userID, accessKeyID string) error
+	// Lookup mengembalikan credential beserta secret-nya untuk verifikasi SigV4.
+	Lookup(ctx context.Context, accessKeyID string) (*model.S3Credential, error)
+}
+
+type s3CredentialService struct {
+	repo      repository.S3Repository
+	serverKey []byte
+	denylist  repository.TokenDenylist
+	now       func() time.Time
+}
+
+// NewS3CredentialService membuat S3CredentialService. serverKey dipakai untuk
+// menurunkan secret setiap access key sehingga secret tidak perlu disimpan.
+// denylist boleh nil; jika nil, pencabutan token tidak diperiksa dan access key
+// hanya berakhir saat token penerbitnya kedaluwarsa.
+func NewS3CredentialService(repo repository.S3Repository, serverKey []byte, denylist repository.TokenDenylist) S3CredentialService {
+	return &s3CredentialService{repo: repo, serverKey: serverKey, denylist: denylist, now: time.Now}
+}
+
+func (s *s3CredentialService) CreateCredential(ctx context.Context, claims jwt.MapClaims) (*model.S3Credential, error) {
+	userID, _ := claims["sub"].(string)
+	role, _ := claims["role"].(string)
+	tokenID, _ := claims["jti"].(string)
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil || tokenID == "" {
+		return nil, ErrS3CredentialTokenInvalid
+	}
+
+	existing, err := s.repo.ListCredentials(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil daftar access key: %w", err)
+	}
+	active := 0
+	for _, credential := range existing {
+		if !s.expired(credential) {
+			active++
+			continue
+		}
+		// Access key yang kedaluwarsa tidak ikut dihitung dalam batas dan
+		// dibersihkan di sini agar tabel tidak tumbuh tanpa batas.
+		if err := s.repo.DeleteCredential(ctx, userID, credential.AccessKeyID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("gagal menghapus access key kedaluwarsa: %w", err)
+		}
+	}
+	if active >= MaxS3CredentialsPerUser {
+		return nil, ErrTooManyS3Credentials
+	}
+
+	accessKeyID, err := newAccessKeyID()
+	if err != nil {
+		return nil, err
+	}
+	credential := &model.S3Credential{
+		AccessKeyID: accessKeyID,
+		UserID:      userID,
+		Role:        role,
+		TokenID:     tokenID,
+		ExpiresAt:   expiresAt.Time,
+	}
+	if err := s.repo.CreateCredential(ctx, credential); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan access key: %w", err)
+	}
+	credential.SecretAccessKey = DeriveS3Secret(s.serverKey, accessKeyID)
+	log.Info().Ctx(ctx).Str("user_id", userID).Str("access_key_id", accessKeyID).Msg("Access key S3 dibuat")
+	return credential, nil
+}
+
+func (s *s3CredentialService) ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error) {
+	return s.repo.ListCredentials(ctx, userID)
+}
+
+func (s *s3CredentialService) DeleteCredential(ctx context.Context, userID, accessKeyID string) error {
+	if err := s.repo.DeleteCredential(ctx, userID, accessKeyID); err != nil {
+		return err
+	}
+	log.Info().Ctx(ctx).Str("user_id", userID).Str("access_key_id", accessKeyID).Msg("Access key S3 dicabut")
+	return nil
+}
+
+func (s *s3CredentialService) Lookup(ctx context.Context, accessKeyID string) (*model.S3Credential, error) {
+	credential, err := s.repo.GetCredential(ctx, accessKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if s.expired(*credential) {
+		return nil, ErrS3CredentialExpired
+	}
+	if s.denylist != nil {
+		revoked, err := s.denylist.IsRevoked(ctx, credential.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrS3CredentialExpired
+		}
+	}
+	credential.SecretAccessKey = DeriveS3Secret(s.serverKey, accessKeyID)
+	return credential, nil
+}
+
+// expired melaporkan apakah token penerbit credential sudah kedaluwarsa.
+func (s *s3CredentialService) expired(credential model.S3Credential) bool {
+	return !s.now().Before(credential.ExpiresAt)
+}
+
+// DeriveS3Secret menurunkan secret access key dari kunci server dengan HMAC.
+func DeriveS3Secret(serverKey []byte, accessKeyID string) string {
+	mac := hmac.New(sha256.New, serverKey)
+	mac.Write([]byte("prism-s3-secret:" + accessKeyID))
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil)[:30])
+}
+
+const accessKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+// newAccessKeyID menghasilkan ID 20 karakter berawalan PRSM, mirip format AWS.
+func newAccessKeyID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.New("gagal membuat access key acak")
+	}
+	id := []byte("PRSM")
+	for _, b := range buf {
+		id = append(id, accessKeyAlphabet[int(b)%len(accessKeyAlphabet)])
+	}
+	return string(id), nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockS3Repository hanya mengimplementasikan metode credential; metode lain
+// memanggil interface nil dan akan panic jika tersentuh.
+type MockS3Repository struct {
+	repository.S3Repository
+	mock.Mock
+}
+
+func (m *MockS3Repository) GetCredential(ctx context.Context, accessKeyID string) (*model.S3Credential, error) {
+	args := m.Called(ctx, accessKeyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.S3Credential), args.Error(1)
+}
+
+func (m *MockS3Repository) ListCredentials(ctx context.Context, userID string) ([]model.S3Credential, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.S3Credential), args.Error(1)
+}
+
+func (m *MockS3Repository) CreateCredential(ctx context.Context, credential *model.S3Credential) error {
+	return m.Called(ctx, credential).Error(0)
+}
+
+func (m *MockS3Repository) DeleteCredential(ctx context.Context, userID, accessKeyID string) error {
+	return m.Called(ctx, userID, accessKeyID).Error(0)
+}
+
+// fakeTokenDenylist mencabut jti yang terdaftar di revoked.
+type fakeTokenDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f *fakeTokenDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return f.revoked[jti], f.err
+}
+
+func TestS3CredentialService_CreateCredential(t *testing.T) {
+	ctx := context.Background()
+	serverKey := []byte("server-key")
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	claims := jwt.MapClaims{"sub": "user-1", "role": "finance", "jti": "token-1", "exp": float64(expiresAt.Unix())}
+
+	t.Run("Success - Secret is derived from the access key", func(t *testing.T) {
+		mockRepo := new(MockS3Repository)
+		mockRepo.On("ListCredentials", ctx, "user-1").Return([]model.S3Credential{}, nil).Once()
+		mockRepo.On("CreateCredential", ctx, mock.MatchedBy(func(c *model.S3Credential) bool {
+			return c.UserID == "user-1" && c.Role == "finance" && c.SecretAccessKey == ""
+		})).Return(nil).Once()
+
+		svc := NewS3CredentialService(mockRepo, serverKey, nil)
+		credential, err := svc.CreateCredential(ctx, claims)
+		require.NoError(t, err)
+		assert.Regexp(t, `^PRSM[A-Z2-7]{16}$`, credential.AccessKeyID)
+		assert.Equal(t, DeriveS3Secret(serverKey, credential.AccessKeyID), credential.SecretAccessKey)
+		assert.Len(t, credential.SecretAccessKey, 40)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Credential is bound to the issuing token", func(t *testing.T) {
+		mockRepo := new(MockS3Repository)
+		mockRepo.On("ListCredentials", ctx, "user-1").Return([]model.S3Credential{}, nil).Once()
+		mockRepo.On("CreateCredential", ctx, mock.Anything).Return(nil).Once()
+
+		credential, err := NewS3CredentialService(mockRepo, serverKey, nil).CreateCredential(ctx, claims)
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", credential.TokenID)
+		assert.True(t, expiresAt.Equal(credential.ExpiresAt))
+	})
+
+	t.Run("Success - Expired credentials are pruned and not counted", func(t *testing.T) {
+		existing := make([]model.S3Credential, MaxS3CredentialsPerUser)
+		for i := range existing {
+			existing[i] = model.S3Credential{AccessKeyID: fmt.Sprintf("PRSMOLD%d", i), UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}
+		}
+		existing[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+		mockRepo := new(MockS3Repository)
+		mockRepo.On("ListCredentials", ctx, "user-1").Return(existing, nil).Once()
+		mockRepo.On("DeleteCredential", ctx, "user-1", "PRSMOLD0").Return(nil).Once()
+		mockRepo.On("CreateCredential", ctx, mock.Anything).Return(nil).Once()
+
+		_, err := NewS3CredentialService(mockRepo, serverKey, nil).CreateCredential(ctx, claims)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Too many credentials", func(t *testing.T) {
+		existing := make([]model.S3Credential, MaxS3CredentialsPerUser)
+		for i := range existing {
+			existing[i].ExpiresAt = time.Now().Add(time.Hour)
+		}
+		mockRepo := new(MockS3Repository)
+		mockRepo.On("ListCredentials", ctx, "user-1").Return(existing, nil).Once()
+
+		_, err := NewS3CredentialService(mockRepo, serverKey, nil).CreateCredential(ctx, claims)
+		assert.ErrorIs(t, err, ErrTooManyS3Credentials)
+		mockRepo.AssertExpectations(t)
+	})
+
+	testCases := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{name: "Failure - Token without exp", claims: jwt.MapClaims{"sub": "user-1", "role": "finance", "jti": "token-1"}},
+		{name: "Failure - Token without jti", claims: jwt.MapClaims{"sub": "user-1", "role": "finance", "exp": float64(expiresAt.Unix())}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockS3Repository)
+			_, err := NewS3CredentialService(mockRepo, serverKey, nil).CreateCredential(ctx, tc.claims)
+			assert.ErrorIs(t, err, ErrS3CredentialTokenInvalid)
+			mockRepo.AssertNotCalled(t, "CreateCredential", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestS3CredentialService_Lookup(t *testing.T) {
+	ctx := context.Background()
+	active := &model.S3Credential{AccessKeyID: "PRSMTEST", UserID: "user-1", TokenID: "token-1", ExpiresAt: time.Now().Add(time.Hour)}
+	expired := &model.S3Credential{AccessKeyID: "PRSMTEST", UserID: "user-1", TokenID: "token-1", ExpiresAt: time.Now().Add(-time.Minute)}
+
+	testCases := []struct {
+		name        string
+		credential  *model.S3Credential
+		denylist    *fakeTokenDenylist
+		expectedErr error
+	}{
+		{name: "Success - Active credential", credential: active, denylist: &fakeTokenDenylist{}},
+		{name: "Success - Without denylist", credential: active},
+		{name: "Failure - Issuing token expired", credential: expired, denylist: &fakeTokenDenylist{}, expectedErr: ErrS3CredentialExpired},
+		{name: "Failure - Issuing token revoked", credential: active, denylist: &fakeTokenDenylist{revoked: map[string]bool{"token-1": true}}, expectedErr: ErrS3CredentialExpired},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockS3Repository)
+			found := *tc.credential
+			mockRepo.On("GetCredential", ctx, "PRSMTEST").Return(&found, nil).Once()
+
+			var denylist repository.TokenDenylist
+			if tc.denylist != nil {
+				denylist = tc.denylist
+			}
+			credential, err := NewS3CredentialService(mockRepo, []byte("server-key"), denylist).Lookup(ctx, "PRSMTEST")
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorIs(t, err, repository.ErrNotFound, "Gateway harus memperlakukannya sebagai access key tidak dikenal")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, DeriveS3Secret([]byte("server-key"), "PRSMTEST"), credential.SecretAccessKey)
+			assert.NotEqual(t, DeriveS3Secret([]byte("kunci-lain"), "PRSMTEST"), credential.SecretAccessKey)
+		})
+	}
+}
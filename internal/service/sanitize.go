@@ -0,0 +1,44 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/sanitize"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// sanitizeUpload menulis salinan content tanpa metadata ke file sementara dan
+// mengembalikannya dalam posisi awal beserta ukurannya. Pemanggil wajib
+// menutup dan menghapus file tersebut. File yang strukturnya tidak dapat
+// dibersihkan ditolak dengan validation.CodeSanitizationFailed, karena
+// kebijakan yang berlaku mewajibkan metadata dibuang.
+func sanitizeUpload(content io.ReaderAt, size int64, mime *mimetype.MIME) (*os.File, int64, error) {
+	tmp, err := os.CreateTemp("", "prism-sanitize-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal membuat file sementara: %w", err)
+	}
+	fail := func(err error) (*os.File, int64, error) {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, 0, err
+	}
+
+	if err := sanitize.Sanitize(tmp, content, size, mime); err != nil {
+		if errors.Is(err, sanitize.ErrMalformed) || errors.Is(err, sanitize.ErrUnsupported) {
+			return fail(validation.Errorf(validation.CodeSanitizationFailed, "file metadata cannot be removed: %v", err))
+		}
+		return fail(fmt.Errorf("gagal membersihkan metadata file: %w", err))
+	}
+	cleanSize, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fail(err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+	return tmp, cleanSize, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// pngWithText menyisipkan chunk tEXt berisi text tepat setelah IHDR testPNG.
+func pngWithText(text string) string {
+	data := []byte("tEXt" + "Author\x00" + text)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)-4))
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(data))
+	const ihdrEnd = 8 + 25
+	return testPNG[:ihdrEnd] + string(chunk) + testPNG[ihdrEnd:]
+}
+
+func sanitizeConfig(global bool) *fileserviceconfig.Config {
+	enabled := true
+	return &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024,
+		AllowedMimeTypesMap: map[string]bool{"image/png": true, "application/pdf": true},
+		SanitizeUploads:     global,
+		UploadPolicies: []uploadpolicy.Policy{
+			{Name: "avatar", Match: uploadpolicy.Match{Tags: []string{"avatar"}}, AllowedMimeTypes: []string{"image/png"}, Sanitize: &enabled},
+		},
+	}
+}
+
+func TestFileService_UploadFile_Sanitize(t *testing.T) {
+	content := pngWithText("GPS-SECRET")
+
+	testCases := []struct {
+		name          string
+		global        bool
+		tags          []string
+		expectCleaned bool
+	}{
+		{name: "Policy enables sanitisation", tags: []string{"avatar"}, expectCleaned: true},
+		{name: "Global setting enables sanitisation", global: true, expectCleaned: true},
+		{name: "Sanitisation disabled keeps content untouched"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockFileRepository)
+			mockStore := new(MockStorage)
+			var created *model.FileMetadata
+			var saved []byte
+			mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.FileMetadata"), tc.tags).
+				Run(func(args mock.Arguments) { created = args.Get(1).(*model.FileMetadata) }).Return(nil).Once()
+			mockStore.On("Save", mock.Anything, mock.AnythingOfType("string"), mock.Anything).
+				Run(func(args mock.Arguments) {
+					var err error
+					saved, err = io.ReadAll(args.Get(2).(io.Reader))
+					require.NoError(t, err)
+				}).Return(nil).Once()
+			svc := NewFileService(mockRepo, mockStore, sanitizeConfig(tc.global), nil, nil, nil)
+			fileHeader, err := createTestFileHeader(content, "avatar.png")
+			require.NoError(t, err)
+
+			metadata, err := svc.UploadFile(context.Background(), "user-1", fileHeader, tc.tags)
+
+			require.NoError(t, err)
+			require.NotNil(t, created)
+			if tc.expectCleaned {
+				assert.NotContains(t, string(saved), "GPS-SECRET")
+				require.NotNil(t, created.SanitizedAt)
+				assert.Equal(t, int64(len(saved)), metadata.SizeBytes, "Ukuran dan checksum mengikuti isi yang sudah dibersihkan")
+			} else {
+				assert.Equal(t, content, string(saved))
+				assert.Nil(t, created.SanitizedAt)
+			}
+		})
+	}
+}
+
+func TestFileService_UploadFile_SanitizeFailure(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	svc := NewFileService(mockRepo, new(MockStorage), sanitizeConfig(true), nil, nil, nil)
+	encrypted := "%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R /Encrypt 9 0 R >>\nstartxref\n9\n%%EOF\n"
+	fileHeader, err := createTestFileHeader(encrypted, "secret.pdf")
+	require.NoError(t, err)
+
+	_, err = svc.UploadFile(context.Background(), "user-1", fileHeader, nil)
+
+	validationErr, ok := validation.AsError(err)
+	require.True(t, ok, "error harus bertipe *validation.Error: %v", err)
+	assert.Equal(t, validation.CodeSanitizationFailed, validationErr.Code)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFileService_UploadPolicies_Sanitize(t *testing.T) {
+	svc := NewFileService(nil, nil, sanitizeConfig(false), nil, nil, nil)
+
+	infos := svc.UploadPolicies(jwt.MapClaims{"role": "user"})
+
+	require.Len(t, infos, 2)
+	assert.True(t, infos[0].Sanitize)
+	assert.False(t, infos[1].Sanitize)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/fulltext"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/jobs"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrEmptySearchQuery dikembalikan jika kata kunci pencarian kosong.
+var ErrEmptySearchQuery = errors.New("kata kunci pencarian tidak boleh kosong")
+
+// Batas jumlah hasil per halaman pencarian.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchResult adalah file yang cocok dengan pencarian beserta peringkat dan
+// potongan teks yang menyorot kata yang cocok (HTML, kata dibungkus <mark>).
+type SearchResult struct {
+	File    *model.FileMetadata `json:"file"`
+	Rank    float64             `json:"rank"`
+	Snippet string              `json:"snippet"`
+}
+
+type SearchService interface {
+	Search(ctx context.Context, query repository.SearchQuery, claims jwt.MapClaims) ([]SearchResult, error)
+}
+
+type searchService struct {
+	repo  repository.SearchRepository
+	files FileService
+}
+
+func NewSearchService(repo repository.SearchRepository, files FileService) SearchService {
+	return &searchService{repo: repo, files: files}
+}
+
+// Search menjalankan pencarian full-text lalu hanya mengembalikan file yang
+// dapat dibaca pemanggil, dengan otorisasi yang sama seperti GetFileMetadata.
+// File yang ditolak dibuang setelah paginasi sehingga satu halaman dapat
+// berisi kurang dari Limit hasil.
+func (s *searchService) Search(ctx context.Context, query repository.SearchQuery, claims jwt.MapClaims) ([]SearchResult, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchLimit
+	}
+	query.Limit = min(query.Limit, MaxSearchLimit)
+	query.Offset = max(query.Offset, 0)
+
+	hits, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menjalankan pencarian: %w", err)
+	}
+	if len(hits) == 0 {
+		return []SearchResult{}, nil
+	}
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	files, err := s.files.ListFiles(ctx, repository.FileFilter{IDs: ids}, claims)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]*model.FileMetadata, len(files))
+	for _, file := range files {
+		allowed[file.ID] = file
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if file, ok := allowed[hit.ID]; ok {
+			results = append(results, SearchResult{File: file, Rank: hit.Rank, Snippet: hit.Snippet})
+		}
+	}
+	return results, nil
+}
+
+// indexContent mengisi teks isi unggahan untuk pencarian full-text.
+// Kegagalan ekstraksi tidak membatalkan unggahan: file tetap tersimpan tanpa
+// IndexedAt sehingga dapat diproses lagi oleh perintah reindex. Tipe tanpa
+// extractor ditandai sudah diindeks dengan teks kosong.
+func indexContent(metadata *model.FileMetadata, content io.ReaderAt, size int64, mime *mimetype.MIME, limit int) {
+	text, err := fulltext.Extract(content, size, mime, limit)
+	if err != nil && !errors.Is(err, fulltext.ErrUnsupported) {
+		log.Warn().Err(err).Str("file_id", metadata.ID).Msg("Gagal mengekstrak teks file untuk pencarian")
+		return
+	}
+	now := time.Now()
+	metadata.ContentText = text
+	metadata.IndexedAt = &now
+}
+
+// ExtractTextJob mengembalikan handler job extract_text yang mengekstrak
+// teks file setelah diunggah, dengan batas teks dari konfigurasi terkini.
+// File yang sudah dihapus, atau ekstraksi yang sudah dinonaktifkan,
+// dilewati.
+func ExtractTextJob(files repository.FileRepository, search repository.SearchRepository, store storage.Storage, cfg func() *fileserviceconfig.Config) jobs.Handler {
+	return func(ctx context.Context, job *model.Job) error {
+		if job.FileID == nil {
+			return jobs.Permanent(errors.New("job extract_text tidak memiliki file_id"))
+		}
+		limit := cfg().SearchMaxTextBytes
+		if limit <= 0 {
+			return nil
+		}
+		metadata, err := files.GetByID(ctx, *job.FileID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		indexer := fulltext.NewIndexer(search, store, limit, 0)
+		if err := indexer.IndexFile(ctx, metadata); err != nil && !errors.Is(err, fulltext.ErrUnsupported) {
+			return err
+		}
+		return nil
+	}
+}
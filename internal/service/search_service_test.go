@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	ownerID, otherID := "user-1", "user-2"
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "own", OriginalName: "anggaran.txt", OwnerUserID: &ownerID}, nil))
+	require.NoError(t, repo.Create(ctx, &model.FileMetadata{ID: "other", OriginalName: "anggaran-rahasia.txt", OwnerUserID: &otherID}, nil))
+	files := NewFileService(repo, nil, &fileserviceconfig.Config{}, nil, nil, nil)
+	svc := NewSearchService(repo, files)
+
+	testCases := []struct {
+		name        string
+		query       repository.SearchQuery
+		claims      jwt.MapClaims
+		expectedIDs []string
+		expectedErr error
+	}{
+		{
+			name:        "Owner hanya melihat file yang dapat diaksesnya",
+			query:       repository.SearchQuery{Text: "anggaran"},
+			claims:      jwt.MapClaims{"sub": ownerID, "role": "user"},
+			expectedIDs: []string{"own"},
+		},
+		{
+			name:        "Admin melihat semua file dengan urutan peringkat",
+			query:       repository.SearchQuery{Text: "  anggaran  "},
+			claims:      jwt.MapClaims{"sub": "admin-1", "role": "admin"},
+			expectedIDs: []string{"other", "own"},
+		},
+		{
+			name:        "Tidak ada hasil",
+			query:       repository.SearchQuery{Text: "kontrak"},
+			claims:      jwt.MapClaims{"sub": "admin-1", "role": "admin"},
+			expectedIDs: []string{},
+		},
+		{
+			name:        "Query kosong ditolak",
+			query:       repository.SearchQuery{Text: "   "},
+			claims:      jwt.MapClaims{"sub": ownerID, "role": "user"},
+			expectedErr: ErrEmptySearchQuery,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := svc.Search(ctx, tc.query, tc.claims)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			ids := make([]string, 0, len(results))
+			for _, result := range results {
+				ids = append(ids, result.File.ID)
+				assert.Contains(t, result.Snippet, "<mark>anggaran</mark>")
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+// recordingSearchRepository mencatat query yang diterima repository.
+type recordingSearchRepository struct {
+	repository.SearchRepository
+	query repository.SearchQuery
+}
+
+func (r *recordingSearchRepository) Search(ctx context.Context, query repository.SearchQuery) ([]repository.SearchHit, error) {
+	r.query = query
+	return nil, nil
+}
+
+func TestSearchService_Search_ClampsPagination(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    repository.SearchQuery
+		expected repository.SearchQuery
+	}{
+		{"Default limit", repository.SearchQuery{Text: "a"}, repository.SearchQuery{Text: "a", Limit: DefaultSearchLimit}},
+		{"Limit maksimum", repository.SearchQuery{Text: "a", Limit: 1000, Offset: 5}, repository.SearchQuery{Text: "a", Limit: MaxSearchLimit, Offset: 5}},
+		{"Offset negatif", repository.SearchQuery{Text: "a", Limit: 10, Offset: -3}, repository.SearchQuery{Text: "a", Limit: 10}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &recordingSearchRepository{}
+			svc := NewSearchService(repo, nil)
+			results, err := svc.Search(context.Background(), tc.query, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Empty(t, results)
+			assert.Equal(t, tc.expected, repo.query)
+		})
+	}
+}
+
+func TestFileService_UploadFile_IndexesContent(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFileRepository(nil)
+	store := storage.NewMemoryStorage()
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024,
+		AllowedMimeTypesMap: map[string]bool{"text/plain": true, "image/png": true},
+		SearchMaxTextBytes:  1024,
+	}
+	svc := NewFileService(repo, store, cfg, nil, nil, nil)
+	claims := jwt.MapClaims{"sub": "user-1", "role": "user"}
+
+	header, err := createTestFileHeader("Laporan   keuangan\ntriwulan pertama", "laporan.txt")
+	require.NoError(t, err)
+	text, err := svc.UploadFile(ctx, "user-1", header, nil)
+	require.NoError(t, err)
+	require.NotNil(t, text.IndexedAt)
+
+	header, err = createTestFileHeader(testPNG, "foto.png")
+	require.NoError(t, err)
+	image, err := svc.UploadFile(ctx, "user-1", header, nil)
+	require.NoError(t, err)
+	require.NotNil(t, image.IndexedAt, "Tipe tanpa extractor tetap ditandai sudah diindeks")
+
+	results, err := NewSearchService(repo, svc).Search(ctx, repository.SearchQuery{Text: "keuangan triwulan"}, claims)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, text.ID, results[0].File.ID)
+	assert.Equal(t, "Laporan <mark>keuangan</mark> <mark>triwulan</mark> pertama", results[0].Snippet)
+}
+
+func TestIndexContent_Failure(t *testing.T) {
+	metadata := &model.FileMetadata{ID: "rusak"}
+	content := strings.NewReader("PK\x03\x04 bukan arsip")
+	indexContent(metadata, content, content.Size(), mimetype.Lookup("application/vnd.openxmlformats-officedocument.wordprocessingml.document"), 1024)
+	assert.Nil(t, metadata.IndexedAt, "File yang gagal diekstrak harus tetap menunggu reindex")
+	assert.Empty(t, metadata.ContentText)
+}
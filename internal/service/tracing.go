@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/tracing"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedFileService membungkus FileService dan membuat span untuk setiap
+// operasi, sebagai induk span repository, storage dan query di bawahnya.
+// Penolakan validasi dicatat storeUpload sebagai event pada span ini.
+type tracedFileService struct {
+	next FileService
+}
+
+// NewTracedFileService membungkus next dengan span OpenTelemetry per operasi.
+func NewTracedFileService(next FileService) FileService {
+	return &tracedFileService{next: next}
+}
+
+func (s *tracedFileService) UploadFile(ctx context.Context, ownerID string, fileHeader *multipart.FileHeader, tags []string) (metadata *model.FileMetadata, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.UploadFile", trace.WithAttributes(
+		tracing.FileNameKey.String(fileHeader.Filename),
+		tracing.FileSizeKey.Int64(fileHeader.Size),
+	))
+	defer func() { endWithFile(span, metadata, err) }()
+	return s.next.UploadFile(ctx, ownerID, fileHeader, tags)
+}
+
+func (s *tracedFileService) UploadBatch(ctx context.Context, ownerID string, items []BatchUploadItem) (results []BatchUploadResult, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.UploadBatch", trace.WithAttributes(tracing.FileCountKey.Int(len(items))))
+	defer func() { endWithResults(span, results, err) }()
+	return s.next.UploadBatch(ctx, ownerID, items)
+}
+
+func (s *tracedFileService) UploadArchive(ctx context.Context, ownerID string, archive *multipart.FileHeader, tags []string) (results []BatchUploadResult, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.UploadArchive", trace.WithAttributes(
+		tracing.FileNameKey.String(archive.Filename),
+		tracing.FileSizeKey.Int64(archive.Size),
+	))
+	defer func() { endWithResults(span, results, err) }()
+	return s.next.UploadArchive(ctx, ownerID, archive, tags)
+}
+
+func (s *tracedFileService) UploadStream(ctx context.Context, ownerID, filename string, content io.Reader, tags []string) (metadata *model.FileMetadata, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.UploadStream", trace.WithAttributes(tracing.FileNameKey.String(filename)))
+	defer func() { endWithFile(span, metadata, err) }()
+	return s.next.UploadStream(ctx, ownerID, filename, content, tags)
+}
+
+func (s *tracedFileService) GetFileMetadata(ctx context.Context, fileID string, claims jwt.MapClaims) (metadata *model.FileMetadata, err error) {
+	ctx, span := startFile(ctx, "FileService.GetFileMetadata", fileID)
+	defer func() { endWithFile(span, metadata, err) }()
+	return s.next.GetFileMetadata(ctx, fileID, claims)
+}
+
+// GetFileReader dan OpenFile hanya mencakup pembukaan reader; transfer
+// konten tercatat pada span storage.get.
+func (s *tracedFileService) GetFileReader(ctx context.Context, metadata *model.FileMetadata) (_ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.GetFileReader", trace.WithAttributes(fileAttributes(metadata)...))
+	defer func() { tracing.End(span, err) }()
+	return s.next.GetFileReader(ctx, metadata)
+}
+
+func (s *tracedFileService) OpenFile(ctx context.Context, metadata *model.FileMetadata) (_ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.OpenFile", trace.WithAttributes(fileAttributes(metadata)...))
+	defer func() { tracing.End(span, err) }()
+	return s.next.OpenFile(ctx, metadata)
+}
+
+func (s *tracedFileService) SignedDownloadURL(ctx context.Context, metadata *model.FileMetadata) (_ string, _ time.Time, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.SignedDownloadURL", trace.WithAttributes(fileAttributes(metadata)...))
+	defer func() { tracing.End(span, err) }()
+	return s.next.SignedDownloadURL(ctx, metadata)
+}
+
+func (s *tracedFileService) ListFiles(ctx context.Context, filter repository.FileFilter, claims jwt.MapClaims) (files []*model.FileMetadata, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.ListFiles")
+	defer func() {
+		span.SetAttributes(tracing.FileCountKey.Int(len(files)))
+		tracing.End(span, err)
+	}()
+	return s.next.ListFiles(ctx, filter, claims)
+}
+
+func (s *tracedFileService) DeleteFile(ctx context.Context, fileID string, claims jwt.MapClaims) (err error) {
+	ctx, span := startFile(ctx, "FileService.DeleteFile", fileID)
+	defer func() { tracing.End(span, err) }()
+	return s.next.DeleteFile(ctx, fileID, claims)
+}
+
+func (s *tracedFileService) UpdateFileMetadata(ctx context.Context, fileID string, update repository.MetadataUpdate, expectedVersion int64, claims jwt.MapClaims) (metadata *model.FileMetadata, err error) {
+	ctx, span := startFile(ctx, "FileService.UpdateFileMetadata", fileID)
+	defer func() { endWithFile(span, metadata, err) }()
+	return s.next.UpdateFileMetadata(ctx, fileID, update, expectedVersion, claims)
+}
+
+func (s *tracedFileService) AddFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (metadata *model.FileMetadata, err error) {
+	ctx, span := startFile(ctx, "FileService.AddFileTag", fileID)
+	defer func() { endWithFile(span, metadata, err) }()
+	return s.next.AddFileTag(ctx, fileID, tag, expectedVersion, claims)
+}
+
+func (s *tracedFileService) RemoveFileTag(ctx context.Context, fileID, tag string, expectedVersion int64, claims jwt.MapClaims) (metadata *model.FileMetadata, err error) {
+	ctx, span := startFile(ctx, "FileService.RemoveFileTag", fileID)
+	defer func() { endWithFile(span, metadata, err) }()
+	return s.next.RemoveFileTag(ctx, fileID, tag, expectedVersion, claims)
+}
+
+func (s *tracedFileService) ResolveArchiveFiles(ctx context.Context, req ArchiveRequest, claims jwt.MapClaims) (files []*model.FileMetadata, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.ResolveArchiveFiles")
+	defer func() {
+		span.SetAttributes(tracing.FileCountKey.Int(len(files)))
+		tracing.End(span, err)
+	}()
+	return s.next.ResolveArchiveFiles(ctx, req, claims)
+}
+
+// WriteArchive mencatat byte arsip yang benar-benar ditulis ke w.
+func (s *tracedFileService) WriteArchive(ctx context.Context, w io.Writer, files []*model.FileMetadata, withManifest bool) (err error) {
+	ctx, span := tracing.Start(ctx, "FileService.WriteArchive", trace.WithAttributes(tracing.FileCountKey.Int(len(files))))
+	counter := &countingWriter{w: w}
+	defer func() {
+		span.SetAttributes(tracing.BytesTransferredKey.Int64(counter.n))
+		tracing.End(span, err)
+	}()
+	return s.next.WriteArchive(ctx, counter, files, withManifest)
+}
+
+func (s *tracedFileService) CreateArchiveAsync(ctx context.Context, ownerID string, files []*model.FileMetadata, req ArchiveRequest) (archiveID string, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.CreateArchiveAsync", trace.WithAttributes(tracing.FileCountKey.Int(len(files))))
+	defer func() {
+		span.SetAttributes(tracing.FileIDKey.String(archiveID))
+		tracing.End(span, err)
+	}()
+	return s.next.CreateArchiveAsync(ctx, ownerID, files, req)
+}
+
+func (s *tracedFileService) UploadPolicies(claims jwt.MapClaims) []UploadPolicyInfo {
+	return s.next.UploadPolicies(claims)
+}
+
+func (s *tracedFileService) ApplyConfig(cfg *fileserviceconfig.Config) {
+	s.next.ApplyConfig(cfg)
+}
+
+func startFile(ctx context.Context, name, fileID string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name, trace.WithAttributes(tracing.FileIDKey.String(fileID)))
+}
+
+// fileAttributes mengembalikan atribut span untuk file yang metadatanya sudah
+// diketahui.
+func fileAttributes(metadata *model.FileMetadata) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		tracing.FileIDKey.String(metadata.ID),
+		tracing.FileSizeKey.Int64(metadata.SizeBytes),
+		tracing.FileMimeTypeKey.String(metadata.MimeType),
+	}
+}
+
+// endWithFile menambahkan atribut file hasil operasi, jika ada, lalu menutup span.
+func endWithFile(span trace.Span, metadata *model.FileMetadata, err error) {
+	if metadata != nil {
+		span.SetAttributes(fileAttributes(metadata)...)
+	}
+	tracing.End(span, err)
+}
+
+// endWithResults mencatat jumlah file yang gagal pada unggahan batch atau
+// arsip, lalu menutup span. Penolakan per file tercatat sebagai event.
+func endWithResults(span trace.Span, results []BatchUploadResult, err error) {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	span.SetAttributes(tracing.FileCountKey.Int(len(results)), attribute.Int("file.failed_count", failed))
+	tracing.End(span, err)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/tracing"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newSpanRecorder memasang tracer provider global yang merekam span selesai
+// selama test berjalan.
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// endedSpan mengembalikan span selesai dengan nama name.
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	require.Failf(t, "span tidak ditemukan", "span %q tidak direkam", name)
+	return nil
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracedFileService_UploadFile(t *testing.T) {
+	cfg := &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024,
+		AllowedMimeTypesMap: map[string]bool{"image/png": true},
+		ContentValidation:   validation.DefaultOptions(),
+	}
+
+	t.Run("Success - Span carries uploaded file attributes", func(t *testing.T) {
+		recorder := newSpanRecorder(t)
+		mockRepo := new(MockFileRepository)
+		mockStore := new(MockStorage)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.FileMetadata"), mock.Anything).Return(nil).Once()
+		mockStore.On("Save", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+		svc := NewTracedFileService(NewFileService(mockRepo, mockStore, cfg, nil, nil, nil))
+
+		fileHeader, err := createTestFileHeader(testPNG, "avatar.png")
+		require.NoError(t, err)
+		metadata, err := svc.UploadFile(context.Background(), "owner-1", fileHeader, nil)
+		require.NoError(t, err)
+
+		span := endedSpan(t, recorder, "FileService.UploadFile")
+		assert.Equal(t, metadata.ID, spanAttribute(span, tracing.FileIDKey).AsString())
+		assert.Equal(t, "avatar.png", spanAttribute(span, tracing.FileNameKey).AsString())
+		assert.Equal(t, int64(len(testPNG)), spanAttribute(span, tracing.FileSizeKey).AsInt64())
+		assert.Equal(t, "image/png", spanAttribute(span, tracing.FileMimeTypeKey).AsString())
+		assert.Empty(t, span.Events())
+
+		detect := endedSpan(t, recorder, "detect mime type")
+		assert.Equal(t, span.SpanContext().SpanID(), detect.Parent().SpanID())
+		assert.Equal(t, "image/png", spanAttribute(detect, tracing.FileMimeTypeKey).AsString())
+	})
+
+	t.Run("Failure - Validation rejection is recorded as span event", func(t *testing.T) {
+		recorder := newSpanRecorder(t)
+		svc := NewTracedFileService(NewFileService(new(MockFileRepository), new(MockStorage), cfg, nil, nil, nil))
+
+		fileHeader, err := createTestFileHeader(testPNG, "avatar.jpg")
+		require.NoError(t, err)
+		_, err = svc.UploadFile(context.Background(), "owner-1", fileHeader, nil)
+		require.Error(t, err)
+
+		span := endedSpan(t, recorder, "FileService.UploadFile")
+		require.Len(t, span.Events(), 1)
+		assert.Equal(t, tracing.EventUploadRejected, span.Events()[0].Name)
+		assert.Contains(t, span.Events()[0].Attributes, tracing.ValidationCodeKey.String(string(validation.CodeExtensionMismatch)))
+		assert.Equal(t, "validation", spanAttribute(span, "error.type").AsString())
+	})
+}
+
+func TestTracedFileService_WriteArchive(t *testing.T) {
+	ctx := context.Background()
+	recorder := newSpanRecorder(t)
+	files := []*model.FileMetadata{{ID: "file-1", OriginalName: "a.txt", StoragePath: "file-1.txt", MimeType: "text/plain", SizeBytes: 3}}
+	mockStore := new(MockStorage)
+	mockStore.On("Get", mock.Anything, "file-1.txt").Return(io.NopCloser(strings.NewReader("one")), nil).Once()
+	svc := NewTracedFileService(NewFileService(nil, mockStore, &fileserviceconfig.Config{}, nil, nil, nil))
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteArchive(ctx, &buf, files, false))
+
+	span := endedSpan(t, recorder, "FileService.WriteArchive")
+	assert.Equal(t, int64(1), spanAttribute(span, tracing.FileCountKey).AsInt64())
+	assert.Equal(t, int64(buf.Len()), spanAttribute(span, tracing.BytesTransferredKey).AsInt64())
+	mockStore.AssertExpectations(t)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUploadPolicy menandai unggahan yang ditolak kebijakan upload karena
+// purpose, ekstensi atau tag wajibnya. Error tersebut dibungkus
+// *validation.Error dengan kode validation.CodeUploadPolicy. Pelanggaran ukuran dan tipe MIME
+// dilaporkan dengan error yang sama seperti batas global.
+var ErrUploadPolicy = errors.New("unggahan ditolak kebijakan upload")
+
+// UploadPolicyInfo adalah kebijakan upload beserta batas efektifnya, seperti
+// yang ditampilkan ke klien lewat GET /files/policies.
+type UploadPolicyInfo struct {
+	Name                 string   `json:"name"`
+	Description          string   `json:"description,omitempty"`
+	Purposes             []string `json:"purposes,omitempty"`
+	Roles                []string `json:"roles,omitempty"`
+	Tags                 []string `json:"tags,omitempty"`
+	MaxSizeBytes         int64    `json:"max_size_bytes"`
+	AllowedMimeTypes     []string `json:"allowed_mime_types"`
+	AllowedExtensions    []string `json:"allowed_extensions,omitempty"`
+	RequiredTags         []string `json:"required_tags,omitempty"`
+	DefaultRetentionDays int      `json:"default_retention_days,omitempty"`
+	Sanitize             bool     `json:"sanitize"`
+}
+
+// uploadRules adalah batasan yang berlaku untuk satu unggahan: dari kebijakan
+// yang terpilih, atau batas global jika policy nil.
+type uploadRules struct {
+	policy       *uploadpolicy.Policy
+	maxSizeBytes int64
+	mimeTypes    map[string]bool
+	// sanitize menandai bahwa metadata file harus dibersihkan sebelum disimpan.
+	sanitize bool
+}
+
+// uploadRulesFor memilih kebijakan upload untuk unggahan dengan nama file dan
+// tag tersebut, lalu memeriksa batasan yang tidak memerlukan isi file.
+// Purpose dan peran pengunggah dibaca dari ctx (uploadpolicy.WithRequest).
+func uploadRulesFor(ctx context.Context, cfg *fileserviceconfig.Config, filename string, tags []string) (uploadRules, error) {
+	req := uploadpolicy.RequestFromContext(ctx)
+	req.Tags = tags
+	p := uploadpolicy.Select(cfg.UploadPolicies, req)
+	if p == nil {
+		if req.Purpose != "" {
+			return uploadRules{}, validation.Errorf(validation.CodeUploadPolicy, "%w: purpose '%s' tidak dikenal", ErrUploadPolicy, req.Purpose)
+		}
+		return uploadRules{maxSizeBytes: cfg.MaxFileSizeBytes, mimeTypes: cfg.AllowedMimeTypesMap, sanitize: cfg.SanitizeUploads}, nil
+	}
+
+	if ext := strings.ToLower(filepath.Ext(filename)); !p.AllowsExtension(ext) {
+		return uploadRules{}, validation.Errorf(validation.CodeUploadPolicy, "%w: ekstensi '%s' tidak diizinkan oleh kebijakan '%s'", ErrUploadPolicy, ext, p.Name)
+	}
+	if missing := p.MissingTags(tags); len(missing) > 0 {
+		return uploadRules{}, validation.Errorf(validation.CodeUploadPolicy, "%w: kebijakan '%s' mewajibkan tag %s", ErrUploadPolicy, p.Name, strings.Join(missing, ", "))
+	}
+
+	rules := uploadRules{
+		policy:       p,
+		maxSizeBytes: p.MaxSizeBytes(cfg.MaxFileSizeBytes),
+		mimeTypes:    cfg.AllowedMimeTypesMap,
+		sanitize:     p.SanitizeEnabled(cfg.SanitizeUploads),
+	}
+	if len(p.AllowedMimeTypes) > 0 {
+		rules.mimeTypes = make(map[string]bool, len(p.AllowedMimeTypes))
+		for _, mimeType := range p.AllowedMimeTypes {
+			rules.mimeTypes[mimeType] = true
+		}
+	}
+	return rules, nil
+}
+
+// describe menambahkan nama kebijakan ke pesan error agar klien tahu batas
+// mana yang dilanggar.
+func (r uploadRules) describe() string {
+	if r.policy == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (upload policy '%s')", r.policy.Name)
+}
+
+// retainUntil mengembalikan akhir masa retensi bawaan untuk file yang
+// diunggah pada now.
+func (r uploadRules) retainUntil(now time.Time) *time.Time {
+	if r.policy == nil {
+		return nil
+	}
+	return r.policy.RetainUntil(now)
+}
+
+// UploadPolicies mengembalikan kebijakan upload yang dapat dipakai peran
+// pemanggil, diikuti batas global dengan nama uploadpolicy.DefaultPolicyName.
+func (s *fileService) UploadPolicies(claims jwt.MapClaims) []UploadPolicyInfo {
+	cfg := s.cfg()
+	role, _ := claims["role"].(string)
+	global := sortedMimeTypes(cfg.AllowedMimeTypesMap)
+
+	infos := make([]UploadPolicyInfo, 0, len(cfg.UploadPolicies)+1)
+	for i := range cfg.UploadPolicies {
+		p := &cfg.UploadPolicies[i]
+		if !p.AppliesToRole(role) {
+			continue
+		}
+		mimeTypes := global
+		if len(p.AllowedMimeTypes) > 0 {
+			mimeTypes = append([]string{}, p.AllowedMimeTypes...)
+		}
+		infos = append(infos, UploadPolicyInfo{
+			Name:                 p.Name,
+			Description:          p.Description,
+			Purposes:             p.Match.Purposes,
+			Roles:                p.Match.Roles,
+			Tags:                 p.Match.Tags,
+			MaxSizeBytes:         p.MaxSizeBytes(cfg.MaxFileSizeBytes),
+			AllowedMimeTypes:     mimeTypes,
+			AllowedExtensions:    p.AllowedExtensions,
+			RequiredTags:         p.RequiredTags,
+			DefaultRetentionDays: p.DefaultRetentionDays,
+			Sanitize:             p.SanitizeEnabled(cfg.SanitizeUploads),
+		})
+	}
+	return append(infos, UploadPolicyInfo{
+		Name:             uploadpolicy.DefaultPolicyName,
+		Description:      "Batas global untuk unggahan yang tidak cocok dengan kebijakan lain",
+		MaxSizeBytes:     cfg.MaxFileSizeBytes,
+		AllowedMimeTypes: global,
+		Sanitize:         cfg.SanitizeUploads,
+	})
+}
+
+func sortedMimeTypes(allowed map[string]bool) []string {
+	mimeTypes := make([]string, 0, len(allowed))
+	for mimeType, ok := range allowed {
+		if ok {
+			mimeTypes = append(mimeTypes, mimeType)
+		}
+	}
+	sort.Strings(mimeTypes)
+	return mimeTypes
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/uploadpolicy"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func uploadPolicyConfig() *fileserviceconfig.Config {
+	return &fileserviceconfig.Config{
+		MaxFileSizeBytes:    1024,
+		AllowedMimeTypesMap: map[string]bool{"image/png": true, "text/plain": true},
+		UploadPolicies: []uploadpolicy.Policy{
+			{
+				Name:                 "hr-scan",
+				Match:                uploadpolicy.Match{Purposes: []string{"hr-scan"}, Roles: []string{"hr"}},
+				MaxSizeMB:            50,
+				AllowedMimeTypes:     []string{"text/plain"},
+				AllowedExtensions:    []string{".txt"},
+				RequiredTags:         []string{"hr"},
+				DefaultRetentionDays: 30,
+			},
+			{
+				Name:              "avatar",
+				Match:             uploadpolicy.Match{Tags: []string{"avatar"}},
+				MaxSizeMB:         2,
+				AllowedMimeTypes:  []string{"image/png"},
+				AllowedExtensions: []string{".png"},
+			},
+		},
+	}
+}
+
+func TestFileService_UploadPolicy(t *testing.T) {
+	testCases := []struct {
+		name          string
+		request       uploadpolicy.Request
+		fileName      string
+		content       string
+		tags          []string
+		expectedError string
+		expectRetain  bool
+	}{
+		{name: "Purpose policy allows larger file and sets retention", request: uploadpolicy.Request{Purpose: "hr-scan", Role: "hr"}, fileName: "scan.txt", content: strings.Repeat("a", 2048), tags: []string{"hr"}, expectRetain: true},
+		{name: "Without policy the global limit applies", fileName: "notes.txt", content: strings.Repeat("a", 2048), expectedError: "exceeds the limit of 1024 bytes"},
+		{name: "Unknown purpose", request: uploadpolicy.Request{Purpose: "payroll"}, fileName: "notes.txt", content: "hello", expectedError: "purpose 'payroll' tidak dikenal"},
+		{name: "Purpose for another role", request: uploadpolicy.Request{Purpose: "hr-scan", Role: "staff"}, fileName: "scan.txt", content: "hello", tags: []string{"hr"}, expectedError: "tidak dikenal"},
+		{name: "Missing required tag", request: uploadpolicy.Request{Purpose: "hr-scan", Role: "hr"}, fileName: "scan.txt", content: "hello", expectedError: "mewajibkan tag hr"},
+		{name: "Extension not allowed", request: uploadpolicy.Request{Purpose: "hr-scan", Role: "hr"}, fileName: "scan.PDF", content: "hello", tags: []string{"hr"}, expectedError: "ekstensi '.pdf'"},
+		{name: "Tag policy restricts MIME type", fileName: "avatar.png", content: "hello", tags: []string{"avatar"}, expectedError: "is not allowed (upload policy 'avatar')"},
+		{name: "Tag policy accepts matching file", fileName: "avatar.png", content: testPNG, tags: []string{"avatar"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockFileRepository)
+			mockStore := new(MockStorage)
+			var created *model.FileMetadata
+			if tc.expectedError == "" {
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.FileMetadata"), tc.tags).
+					Run(func(args mock.Arguments) { created = args.Get(1).(*model.FileMetadata) }).Return(nil).Once()
+				mockStore.On("Save", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+			}
+			svc := NewFileService(mockRepo, mockStore, uploadPolicyConfig(), nil, nil, nil)
+			fileHeader, err := createTestFileHeader(tc.content, tc.fileName)
+			require.NoError(t, err)
+
+			ctx := uploadpolicy.WithRequest(context.Background(), tc.request)
+			metadata, err := svc.UploadFile(ctx, "user-1", fileHeader, tc.tags)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, metadata)
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, created)
+			if tc.expectRetain {
+				require.NotNil(t, created.RetainUntil)
+				assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), *created.RetainUntil, time.Minute)
+			} else {
+				assert.Nil(t, created.RetainUntil)
+			}
+			mockRepo.AssertExpectations(t)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFileService_UploadPolicy_ErrorKinds(t *testing.T) {
+	svc := NewFileService(new(MockFileRepository), new(MockStorage), uploadPolicyConfig(), nil, nil, nil)
+	fileHeader, err := createTestFileHeader("hello", "notes.txt")
+	require.NoError(t, err)
+
+	ctx := uploadpolicy.WithRequest(context.Background(), uploadpolicy.Request{Purpose: "payroll"})
+	_, err = svc.UploadFile(ctx, "user-1", fileHeader, nil)
+	assert.ErrorIs(t, err, ErrUploadPolicy)
+	validationErr, ok := validation.AsError(err)
+	require.True(t, ok)
+	assert.Equal(t, validation.CodeUploadPolicy, validationErr.Code)
+}
+
+func TestFileService_UploadStream_PolicyLimit(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	mockStore := new(MockStorage)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.FileMetadata"), []string{"hr"}).Return(nil).Once()
+	mockStore.On("Save", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+	svc := NewFileService(mockRepo, mockStore, uploadPolicyConfig(), nil, nil, nil)
+
+	ctx := uploadpolicy.WithRequest(context.Background(), uploadpolicy.Request{Purpose: "hr-scan", Role: "hr"})
+	content := strings.Repeat("a", 4096)
+	metadata, err := svc.UploadStream(ctx, "user-1", "scan.txt", strings.NewReader(content), []string{"hr"})
+
+	require.NoError(t, err, "Stream tidak boleh dipotong pada batas global jika kebijakan mengizinkan lebih besar")
+	assert.Equal(t, int64(4096), metadata.SizeBytes)
+}
+
+func TestFileService_UploadPolicies(t *testing.T) {
+	svc := NewFileService(nil, nil, uploadPolicyConfig(), nil, nil, nil)
+
+	t.Run("Role sees its own policies and the global default", func(t *testing.T) {
+		infos := svc.UploadPolicies(jwt.MapClaims{"role": "hr"})
+		require.Len(t, infos, 3)
+		assert.Equal(t, "hr-scan", infos[0].Name)
+		assert.Equal(t, int64(50*1024*1024), infos[0].MaxSizeBytes)
+		assert.Equal(t, []string{"hr"}, infos[0].RequiredTags)
+		assert.Equal(t, 30, infos[0].DefaultRetentionDays)
+		assert.Equal(t, "avatar", infos[1].Name)
+		assert.Equal(t, uploadpolicy.DefaultPolicyName, infos[2].Name)
+		assert.Equal(t, int64(1024), infos[2].MaxSizeBytes)
+		assert.Equal(t, []string{"image/png", "text/plain"}, infos[2].AllowedMimeTypes)
+	})
+
+	t.Run("Role-restricted policies are hidden from other roles", func(t *testing.T) {
+		infos := svc.UploadPolicies(jwt.MapClaims{"role": "staff"})
+		require.Len(t, infos, 2)
+		assert.Equal(t, "avatar", infos[0].Name)
+	})
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+)
+
+// azureBlockSize adalah ukuran blok saat mengunggah block blob secara bertahap.
+const azureBlockSize = 4 * 1024 * 1024
+
+// AzureBlobStorage adalah implementasi Storage untuk Azure Blob Storage. File
+// disimpan sebagai block blob yang diunggah per blok lalu di-commit.
+type AzureBlobStorage struct {
+	client    *azblob.Client
+	container string
+	canSign   bool
+}
+
+// NewAzureBlobStorage membuat klien untuk container di akun penyimpanan.
+// Autentikasi memakai accountKey (shared key) atau, jika kosong, sasToken.
+// endpoint opsional, mis. "http://127.0.0.1:10000/devstoreaccount1" untuk
+// Azurite; jika kosong dipakai endpoint publik akun tersebut.
+func NewAzureBlobStorage(accountName, accountKey, sasToken, endpoint, container string) (*AzureBlobStorage, error) {
+	if accountName == "" || container == "" {
+		return nil, errors.New("nama akun dan container Azure wajib diisi")
+	}
+	serviceURL := endpoint
+	if serviceURL == "" {
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
+	}
+
+	var (
+		client *azblob.Client
+		err    error
+	)
+	switch {
+	case accountKey != "":
+		credential, credErr := azblob.NewSharedKeyCredential(accountName, accountKey)
+		if credErr != nil {
+			return nil, fmt.Errorf("gagal membuat kredensial shared key Azure: %w", credErr)
+		}
+		client, err = azblob.NewClientWithSharedKeyCredential(serviceURL, credential, nil)
+	case sasToken != "":
+		client, err = azblob.NewClientWithNoCredential(strings.TrimSuffix(serviceURL, "?")+"?"+strings.TrimPrefix(sasToken, "?"), nil)
+	default:
+		return nil, errors.New("account key atau SAS token Azure wajib diisi")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat klien Azure Blob: %w", err)
+	}
+
+	log.Printf("Azure Blob Storage client berhasil diinisialisasi untuk container '%s'", container)
+	return &AzureBlobStorage{client: client, container: container, canSign: accountKey != ""}, nil
+}
+
+func (a *AzureBlobStorage) Save(ctx context.Context, path string, content io.Reader) error {
+	_, err := a.client.UploadStream(ctx, a.container, path, content, &azblob.UploadStreamOptions{
+		BlockSize:   azureBlockSize,
+		Concurrency: 2,
+	})
+	return err
+}
+
+func (a *AzureBlobStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := a.client.DownloadStream(ctx, a.container, path, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil, notFound(path, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (a *AzureBlobStorage) Delete(ctx context.Context, path string) error {
+	_, err := a.client.DeleteBlob(ctx, a.container, path, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil
+	}
+	return err
+}
+
+// SignedURL membuat URL SAS baca-saja. Hanya tersedia jika storage dibuat
+// dengan account key, karena SAS harus ditandatangani dengan key akun.
+func (a *AzureBlobStorage) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	if !a.canSign {
+		return "", fmt.Errorf("%w: SAS hanya dapat dibuat dengan account key Azure", ErrSigningUnsupported)
+	}
+	blobClient := a.client.ServiceClient().NewContainerClient(a.container).NewBlobClient(path)
+	// Waktu mulai dimundurkan sedikit untuk menoleransi selisih jam dengan Azure.
+	start := time.Now().Add(-5 * time.Minute)
+	return blobClient.GetSASURL(sas.BlobPermissions{Read: true}, time.Now().Add(expiry), &blob.GetSASURLOptions{StartTime: &start})
+}
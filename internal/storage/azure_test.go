@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	azuriteAccount = "devstoreaccount1"
+	// azuriteKey adalah account key bawaan Azurite yang terdokumentasi publik.
+	azuriteKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// fakeAzureBlobServer meniru subset REST API Blob Storage (Put Blob, Put
+// Block, Put Block List, Get Blob, Delete Blob) yang dipakai AzureBlobStorage.
+type fakeAzureBlobServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	blocks map[string][]byte
+	blobs  map[string][]byte
+	// blockCommits menghitung Put Block List untuk memastikan unggahan per blok.
+	blockCommits int
+}
+
+func newFakeAzureBlobServer(t *testing.T) *fakeAzureBlobServer {
+	t.Helper()
+	fake := &fakeAzureBlobServer{blocks: map[string][]byte{}, blobs: map[string][]byte{}}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (f *fakeAzureBlobServer) handle(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey "+azuriteAccount+":") && query.Get("sig") == "" {
+		azureError(w, http.StatusForbidden, "AuthenticationFailed")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/"+azuriteAccount+"/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		data, _ := io.ReadAll(r.Body)
+		f.blocks[key+"|"+query.Get("blockid")] = data
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		var list struct {
+			Latest []string `xml:"Latest"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&list); err != nil {
+			azureError(w, http.StatusBadRequest, "InvalidXmlDocument")
+			return
+		}
+		var data []byte
+		for _, id := range list.Latest {
+			block, ok := f.blocks[key+"|"+id]
+			if !ok {
+				azureError(w, http.StatusBadRequest, "InvalidBlockList")
+				return
+			}
+			data = append(data, block...)
+		}
+		f.blobs[key] = data
+		f.blockCommits++
+		w.Header().Set("ETag", `"0x1"`)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.Header.Get("x-ms-blob-type") == "BlockBlob":
+		// Konten yang muat dalam satu blok diunggah langsung dengan Put Blob.
+		data, _ := io.ReadAll(r.Body)
+		f.blobs[key] = data
+		w.Header().Set("ETag", `"0x1"`)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet:
+		data, ok := f.blobs[key]
+		if !ok {
+			azureError(w, http.StatusNotFound, string(bloberror.BlobNotFound))
+			return
+		}
+		w.Header().Set("x-ms-blob-type", "BlockBlob")
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[key]; !ok {
+			azureError(w, http.StatusNotFound, string(bloberror.BlobNotFound))
+			return
+		}
+		delete(f.blobs, key)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		azureError(w, http.StatusMethodNotAllowed, "UnsupportedHttpVerb")
+	}
+}
+
+func azureError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("x-ms-error-code", code)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, `<?xml version="1.0" encoding="utf-8"?><Error><Code>`+code+`</Code><Message>fake</Message></Error>`)
+}
+
+func TestAzureBlobStorage_SharedKey(t *testing.T) {
+	fake := newFakeAzureBlobServer(t)
+	store, err := NewAzureBlobStorage(azuriteAccount, azuriteKey, "", fake.URL+"/"+azuriteAccount, "prism")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	content := strings.Repeat("prism", azureBlockSize/5+100) // lebih dari satu blok
+	require.NoError(t, store.Save(ctx, "2025/laporan.pdf", strings.NewReader(content)))
+	assert.Equal(t, content, readAll(t, store, "2025/laporan.pdf"))
+	assert.Equal(t, 1, fake.blockCommits)
+
+	signed, err := store.SignedURL(ctx, "2025/laporan.pdf", time.Hour)
+	require.NoError(t, err)
+	parsed, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/"+azuriteAccount+"/prism/2025/laporan.pdf", parsed.Path)
+	assert.Equal(t, "r", parsed.Query().Get("sp"))
+	assert.NotEmpty(t, parsed.Query().Get("sig"))
+
+	require.NoError(t, store.Delete(ctx, "2025/laporan.pdf"))
+	_, err = store.Get(ctx, "2025/laporan.pdf")
+	assert.True(t, bloberror.HasCode(err, bloberror.BlobNotFound))
+}
+
+func TestAzureBlobStorage_SASToken(t *testing.T) {
+	fake := newFakeAzureBlobServer(t)
+	sasToken := "sv=2023-11-03&ss=b&srt=co&sp=rwdlc&se=2099-01-01T00:00:00Z&sig=" + url.QueryEscape(base64.StdEncoding.EncodeToString([]byte("fake")))
+	store, err := NewAzureBlobStorage(azuriteAccount, "", sasToken, fake.URL+"/"+azuriteAccount, "prism")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save(context.Background(), "a.txt", strings.NewReader("dengan sas")))
+	assert.Equal(t, "dengan sas", readAll(t, store, "a.txt"))
+
+	_, err = store.SignedURL(context.Background(), "a.txt", time.Hour)
+	assert.Error(t, err, "SAS tidak dapat dibuat tanpa account key")
+}
+
+func TestNewAzureBlobStorage_RequiresCredentials(t *testing.T) {
+	_, err := NewAzureBlobStorage(azuriteAccount, "", "", "", "prism")
+	assert.Error(t, err)
+	_, err = NewAzureBlobStorage("", azuriteKey, "", "", "prism")
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage/storagetest"
+)
+
+func TestStorageConformance(t *testing.T) {
+	backends := []struct {
+		name    string
+		factory storagetest.Factory
+	}{
+		{"memory", func(t *testing.T) storage.Storage { return storage.NewMemoryStorage() }},
+		{"local", newLocalStorage},
+		{"s3", storage.NewTestS3Storage},
+		{"sftp", storage.NewTestSFTPStorage},
+		{"azure", storage.NewTestAzureBlobStorage},
+		{"gcs", storage.NewTestGCSStorage},
+		{"switch", func(t *testing.T) storage.Storage { return storage.NewSwitch(storage.NewMemoryStorage()) }},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			storagetest.Run(t, backend.factory)
+		})
+	}
+}
+
+func newLocalStorage(t *testing.T) storage.Storage {
+	store, err := storage.NewLocalStorage(t.TempDir(), storage.LocalOptions{ShardDepth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
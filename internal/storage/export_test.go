@@ -0,0 +1,35 @@
+package storage
+
+import "testing"
+
+// Backend yang membutuhkan server palsu dari test internal diekspor untuk
+// conformance_test.go (package storage_test), yang tidak dapat berada di
+// package ini karena storagetest mengimpor storage.
+
+func NewTestSFTPStorage(t *testing.T) Storage {
+	server := newTestSFTPServer(t, nil)
+	store, err := NewSFTPStorage(server.options(t, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func NewTestAzureBlobStorage(t *testing.T) Storage {
+	fake := newFakeAzureBlobServer(t)
+	store, err := NewAzureBlobStorage(azuriteAccount, azuriteKey, "", fake.URL+"/"+azuriteAccount, "prism")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func NewTestGCSStorage(t *testing.T) Storage {
+	_, store := newFakeGCS(t)
+	return store
+}
+
+func NewTestS3Storage(t *testing.T) Storage {
+	return newFakeS3Storage(t)
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// gcsChunkSize adalah ukuran chunk unggahan resumable. Konten yang lebih besar
+// dikirim bertahap sehingga kegagalan jaringan hanya mengulang satu chunk.
+const gcsChunkSize = 8 * 1024 * 1024
+
+// GCSStorage adalah implementasi Storage untuk Google Cloud Storage.
+type GCSStorage struct {
+	client *storage.Client
+	bucket string
+}
+
+// NewGCSStorage membuat klien GCS untuk bucket. credentialsJSON adalah key
+// service account; jika kosong dipakai Application Default Credentials.
+// endpoint opsional, mis. "http://fake-gcs:4443/storage/v1/" untuk
+// fake-gcs-server; endpoint kustom dipakai tanpa autentikasi.
+func NewGCSStorage(ctx context.Context, credentialsJSON, endpoint, bucket string) (*GCSStorage, error) {
+	if bucket == "" {
+		return nil, errors.New("bucket GCS wajib diisi")
+	}
+	var opts []option.ClientOption
+	if credentialsJSON != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentialsJSON)))
+	}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint), option.WithoutAuthentication())
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat klien GCS: %w", err)
+	}
+
+	log.Printf("GCS Storage client berhasil diinisialisasi untuk bucket '%s'", bucket)
+	return &GCSStorage{client: client, bucket: bucket}, nil
+}
+
+func (g *GCSStorage) Save(ctx context.Context, path string, content io.Reader) error {
+	// Membatalkan context adalah satu-satunya cara menggagalkan unggahan GCS;
+	// tanpa itu Close akan meng-commit konten yang terpotong.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := g.client.Bucket(g.bucket).Object(path).NewWriter(ctx)
+	writer.ChunkSize = gcsChunkSize
+	if _, err := io.Copy(writer, content); err != nil {
+		cancel()
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (g *GCSStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	reader, err := g.client.Bucket(g.bucket).Object(path).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, notFound(path, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (g *GCSStorage) Delete(ctx context.Context, path string) error {
+	err := g.client.Bucket(g.bucket).Object(path).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// SignedURL membuat signed URL V4 untuk GET. Key service account diambil dari
+// credentialsJSON; tanpa itu penandatanganan memakai IAM signBlob.
+func (g *GCSStorage) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	return g.client.Bucket(g.bucket).SignedURL(path, &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(expiry),
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
+
+// Close menutup klien GCS.
+func (g *GCSStorage) Close() error {
+	return g.client.Close()
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeGCS(t *testing.T) (*fakestorage.Server, *GCSStorage) {
+	t.Helper()
+	server, err := fakestorage.NewServerWithOptions(fakestorage.Options{Scheme: "http", Host: "127.0.0.1", PublicHost: "127.0.0.1"})
+	require.NoError(t, err)
+	t.Cleanup(server.Stop)
+	server.CreateBucketWithOpts(fakestorage.CreateBucketOpts{Name: "prism"})
+
+	store, err := NewGCSStorage(context.Background(), "", server.URL()+"/storage/v1/", "prism")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return server, store
+}
+
+func TestGCSStorage_SaveGetDelete(t *testing.T) {
+	server, store := newFakeGCS(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, "2025/kecil.txt", strings.NewReader("isi kecil")))
+	assert.Equal(t, "isi kecil", readAll(t, store, "2025/kecil.txt"))
+
+	// Lebih besar dari satu chunk sehingga diunggah secara resumable.
+	large := bytes.Repeat([]byte("prism"), gcsChunkSize/5+1000)
+	require.NoError(t, store.Save(ctx, "2025/besar.bin", bytes.NewReader(large)))
+	object, err := server.GetObject("prism", "2025/besar.bin")
+	require.NoError(t, err)
+	assert.Equal(t, len(large), len(object.Content))
+
+	require.NoError(t, store.Delete(ctx, "2025/kecil.txt"))
+	_, err = store.Get(ctx, "2025/kecil.txt")
+	assert.ErrorIs(t, err, storage.ErrObjectNotExist)
+}
+
+func TestGCSStorage_FailedContentIsNotCommitted(t *testing.T) {
+	server, store := newFakeGCS(t)
+
+	contentErr := errors.New("koneksi klien terputus")
+	err := store.Save(context.Background(), "gagal.txt", failingReader{err: contentErr})
+	assert.ErrorIs(t, err, contentErr)
+
+	_, err = server.GetObject("prism", "gagal.txt")
+	assert.Error(t, err, "objek yang gagal diunggah tidak boleh tersimpan")
+}
+
+func TestGCSStorage_SignedURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	credentials, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "prism-file@proyek.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, key)})),
+		"token_uri":    "http://127.0.0.1:1/token",
+	})
+	require.NoError(t, err)
+
+	store, err := NewGCSStorage(context.Background(), string(credentials), "", "prism")
+	require.NoError(t, err)
+	signed, err := store.SignedURL(context.Background(), "2025/laporan.pdf", 15*time.Minute)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/prism/2025/laporan.pdf", parsed.Path)
+	assert.Equal(t, "GOOG4-RSA-SHA256", parsed.Query().Get("X-Goog-Algorithm"))
+	expires, err := strconv.Atoi(parsed.Query().Get("X-Goog-Expires"))
+	require.NoError(t, err)
+	assert.InDelta(t, 900, expires, 2)
+	assert.NotEmpty(t, parsed.Query().Get("X-Goog-Signature"))
+}
+
+func mustPKCS8(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return der
+}
@@ -2,49 +2,306 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 )
 
-// LocalStorage adalah implementasi Storage untuk disk lokal.
+// maxShardDepth membatasi tingkat fan-out. Bawaan dua tingkat (65.536
+// direktori) sudah cukup untuk ratusan juta file; tingkat tambahan hanya
+// berguna pada filesystem yang lambat dengan direktori berisi ribuan entri.
+// Di atas empat tingkat (2^32 direktori daun) hampir setiap file berada di
+// direktori sendiri, sehingga setiap tingkat lagi hanya menambah lookup dan
+// inode direktori tanpa mengurangi isi direktori.
+const maxShardDepth = 4
+
+// LocalOptions berisi pengaturan LocalStorage.
+type LocalOptions struct {
+	// FileMode adalah izin file konten yang ditulis. Bawaan 0o640.
+	FileMode fs.FileMode
+	// DirMode adalah izin direktori yang dibuat. Bawaan 0o750.
+	DirMode fs.FileMode
+	// ShardDepth adalah jumlah tingkat direktori fan-out (masing-masing dua
+	// karakter hex dari hash path) di depan setiap path, agar satu direktori
+	// tidak berisi jutaan file. 0 menonaktifkan sharding.
+	ShardDepth int
+}
+
+// LocalStorage adalah implementasi Storage untuk disk lokal. Semua akses
+// berjalan lewat os.Root sehingga path tidak dapat keluar dari basePath;
+// symlink dan file selain file biasa (device, FIFO, socket) ditolak.
 type LocalStorage struct {
 	basePath string
+	root     *os.Root
+	opts     LocalOptions
 }
 
-func NewLocalStorage(basePath string) *LocalStorage {
-	return &LocalStorage{basePath: basePath}
+func NewLocalStorage(basePath string, opts LocalOptions) (*LocalStorage, error) {
+	if opts.FileMode == 0 {
+		opts.FileMode = 0o640
+	}
+	if opts.DirMode == 0 {
+		opts.DirMode = 0o750
+	}
+	if opts.ShardDepth < 0 || opts.ShardDepth > maxShardDepth {
+		return nil, fmt.Errorf("shard depth storage lokal harus antara 0 dan %d", maxShardDepth)
+	}
+	if err := os.MkdirAll(basePath, opts.DirMode); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori storage lokal: %w", err)
+	}
+	root, err := os.OpenRoot(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka direktori storage lokal: %w", err)
+	}
+
+	log.Printf("Local Storage diinisialisasi di %s (shard depth %d)", basePath, opts.ShardDepth)
+	return &LocalStorage{basePath: basePath, root: root, opts: opts}, nil
 }
 
-func (l *LocalStorage) Save(ctx context.Context, path string, content io.Reader) error {
-	fullPath := filepath.Join(l.basePath, path)
+// Close melepaskan descriptor direktori basePath.
+func (l *LocalStorage) Close() error {
+	return l.root.Close()
+}
 
-	// Pastikan direktori ada
-	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+// Save menulis konten ke file sementara di direktori yang sama, melakukan
+// fsync, lalu me-rename-nya ke path tujuan, sehingga pembaca hanya pernah
+// melihat konten lama atau konten baru yang utuh, termasuk setelah crash.
+func (l *LocalStorage) Save(ctx context.Context, key string, content io.Reader) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	target, err := l.shardedPath(key)
+	if err != nil {
+		return err
+	}
+	dir, base := filepath.Dir(target), filepath.Base(target)
+	if err := l.mkdirAll(dir); err != nil {
 		return fmt.Errorf("gagal membuat direktori storage lokal: %w", err)
 	}
+	if _, err := l.inspect(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
 
-	dst, err := os.Create(fullPath)
+	tmpName := "." + base + "." + randomSuffix() + ".tmp"
+	tmpPath := filepath.Join(dir, tmpName)
+	dst, err := l.root.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, l.opts.FileMode)
 	if err != nil {
 		return fmt.Errorf("gagal membuat file tujuan di disk: %w", err)
 	}
 	defer func() {
-		if closeErr := dst.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("gagal menutup file tujuan: %w", closeErr)
+		if err != nil {
+			_ = dst.Close()
+			_ = l.root.Remove(tmpPath)
 		}
 	}()
 
-	_, err = io.Copy(dst, content)
-	return err
+	// Izin saat pembuatan terpotong umask; samakan dengan FileMode.
+	if err = dst.Chmod(l.opts.FileMode); err != nil {
+		return fmt.Errorf("gagal mengatur izin file tujuan: %w", err)
+	}
+	if _, err = io.Copy(dst, contextReader{ctx: ctx, r: content}); err != nil {
+		return err
+	}
+	if err = dst.Sync(); err != nil {
+		return fmt.Errorf("gagal melakukan fsync file tujuan: %w", err)
+	}
+	if err = dst.Close(); err != nil {
+		return fmt.Errorf("gagal menutup file tujuan: %w", err)
+	}
+	if err = l.replace(dir, tmpName, base); err != nil {
+		return fmt.Errorf("gagal memindahkan file sementara: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	target, err := l.locate(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := l.root.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, notFound(key, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Periksa ulang setelah dibuka: path bisa saja diganti di antara inspect dan Open.
+	info, err := file.Stat()
+	if err == nil && !info.Mode().IsRegular() {
+		err = fmt.Errorf("%w: %s bukan file biasa", ErrInvalidPath, key)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
 }
 
-func (l *LocalStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
-	fullPath := filepath.Join(l.basePath, path)
-	return os.Open(fullPath)
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	candidates, err := l.candidates(key)
+	if err != nil {
+		return err
+	}
+	for _, candidate := range candidates {
+		if _, err := l.inspect(candidate); errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := l.root.Remove(candidate); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
 }
 
-func (l *LocalStorage) Delete(ctx context.Context, path string) error {
-	fullPath := filepath.Join(l.basePath, path)
-	return os.Remove(fullPath)
+// shardedPath memvalidasi key lalu mengembalikan path fisiknya relatif
+// terhadap root, diawali direktori fan-out jika sharding aktif.
+func (l *LocalStorage) shardedPath(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	if l.opts.ShardDepth == 0 {
+		return filepath.FromSlash(key), nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	digest := hex.EncodeToString(sum[:l.opts.ShardDepth])
+	parts := make([]string, 0, l.opts.ShardDepth+1)
+	for i := range l.opts.ShardDepth {
+		parts = append(parts, digest[2*i:2*i+2])
+	}
+	return filepath.Join(append(parts, filepath.FromSlash(key))...), nil
+}
+
+// candidates mengembalikan path fisik yang mungkin menyimpan key: lokasi
+// sharded, lalu lokasi datar untuk file yang ditulis sebelum sharding aktif.
+func (l *LocalStorage) candidates(key string) ([]string, error) {
+	target, err := l.shardedPath(key)
+	if err != nil {
+		return nil, err
+	}
+	if l.opts.ShardDepth == 0 {
+		return []string{target}, nil
+	}
+	return []string{target, filepath.FromSlash(key)}, nil
+}
+
+// locate mengembalikan path fisik pertama yang berisi key.
+func (l *LocalStorage) locate(key string) (string, error) {
+	candidates, err := l.candidates(key)
+	if err != nil {
+		return "", err
+	}
+	var missing error
+	for _, candidate := range candidates {
+		_, err := l.inspect(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if missing == nil {
+			missing = err
+		}
+	}
+	return "", notFound(key, missing)
+}
+
+// inspect memeriksa setiap komponen rel tanpa mengikuti symlink: komponen
+// perantara harus direktori dan komponen terakhir harus file biasa.
+// Mengembalikan error fs.ErrNotExist jika salah satu komponen belum ada.
+func (l *LocalStorage) inspect(rel string) (fs.FileInfo, error) {
+	parts := strings.Split(rel, string(filepath.Separator))
+	current := ""
+	for i, part := range parts {
+		current = filepath.Join(current, part)
+		info, err := l.root.Lstat(current)
+		if err != nil {
+			return nil, err
+		}
+		last := i == len(parts)-1
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			return nil, fmt.Errorf("%w: %s adalah symlink", ErrInvalidPath, filepath.ToSlash(current))
+		case !last && !info.IsDir():
+			return nil, fmt.Errorf("%w: %s bukan direktori", ErrInvalidPath, filepath.ToSlash(current))
+		case last && !info.Mode().IsRegular():
+			return nil, fmt.Errorf("%w: %s bukan file biasa", ErrInvalidPath, filepath.ToSlash(current))
+		case last:
+			return info, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: path kosong", ErrInvalidPath)
+}
+
+// mkdirAll membuat dir beserta induknya di dalam root dengan izin DirMode.
+// Komponen yang sudah ada harus berupa direktori sungguhan, bukan symlink.
+func (l *LocalStorage) mkdirAll(dir string) error {
+	if dir == "." {
+		return nil
+	}
+	current := ""
+	for _, part := range strings.Split(dir, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := l.root.Lstat(current)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%w: %s bukan direktori", ErrInvalidPath, filepath.ToSlash(current))
+			}
+			continue
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := l.root.Mkdir(current, l.opts.DirMode); err != nil {
+			// Dibuat bersamaan oleh Save lain; periksa ulang di iterasi berikutnya.
+			if errors.Is(err, fs.ErrExist) {
+				continue
+			}
+			return err
+		}
+		if err := l.chmodDir(current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chmodDir menyamakan izin direktori yang baru dibuat dengan DirMode, karena
+// Mkdir terpotong umask.
+func (l *LocalStorage) chmodDir(dir string) error {
+	d, err := l.root.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+	return d.Chmod(l.opts.DirMode)
+}
+
+// validateKey menolak key yang absolut, mengandung "..", tidak dalam bentuk
+// bersih (mis. "a//b" atau "./a"), atau mengandung backslash maupun NUL.
+func validateKey(key string) error {
+	if key == "" ||
+		strings.ContainsAny(key, "\\\x00") ||
+		path.Clean(key) != key ||
+		!filepath.IsLocal(filepath.FromSlash(key)) {
+		return fmt.Errorf("%w: %q", ErrInvalidPath, key)
+	}
+	return nil
 }
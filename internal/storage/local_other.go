@@ -0,0 +1,15 @@
+//go:build !unix
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// replace me-rename from menjadi to di dalam dir. Tanpa renameat, path
+// disusun dari basePath; dir sudah diperiksa oleh inspect dan mkdirAll.
+func (l *LocalStorage) replace(dir, from, to string) error {
+	base := filepath.Join(l.basePath, dir)
+	return os.Rename(filepath.Join(base, from), filepath.Join(base, to))
+}
@@ -0,0 +1,133 @@
+//go:build unix
+
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalStorage(t *testing.T, opts LocalOptions) (*LocalStorage, string) {
+	t.Helper()
+	base := t.TempDir()
+	store, err := NewLocalStorage(base, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store, base
+}
+
+func TestLocalStorage_RejectsInvalidKeys(t *testing.T) {
+	store, _ := newTestLocalStorage(t, LocalOptions{})
+	ctx := context.Background()
+
+	keys := []string{
+		"",
+		"../luar.txt",
+		"a/../../luar.txt",
+		"/etc/passwd",
+		"a//b.txt",
+		"./a.txt",
+		"a/",
+		`a\..\b.txt`,
+		"a\x00.txt",
+	}
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			assert.ErrorIs(t, store.Save(ctx, key, strings.NewReader("x")), ErrInvalidPath)
+			_, err := store.Get(ctx, key)
+			assert.ErrorIs(t, err, ErrInvalidPath)
+			assert.ErrorIs(t, store.Delete(ctx, key), ErrInvalidPath)
+		})
+	}
+}
+
+func TestLocalStorage_RejectsSymlinks(t *testing.T) {
+	store, base := newTestLocalStorage(t, LocalOptions{})
+	ctx := context.Background()
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "rahasia.txt"), []byte("rahasia"), 0o600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(base, "tautan")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "rahasia.txt"), filepath.Join(base, "file.txt")))
+
+	_, err := store.Get(ctx, "tautan/rahasia.txt")
+	assert.ErrorIs(t, err, ErrInvalidPath, "direktori symlink tidak boleh diikuti")
+	_, err = store.Get(ctx, "file.txt")
+	assert.ErrorIs(t, err, ErrInvalidPath, "file symlink tidak boleh diikuti")
+
+	assert.ErrorIs(t, store.Save(ctx, "tautan/baru.txt", strings.NewReader("x")), ErrInvalidPath)
+	assert.NoFileExists(t, filepath.Join(outside, "baru.txt"))
+	assert.ErrorIs(t, store.Save(ctx, "file.txt", strings.NewReader("x")), ErrInvalidPath)
+	assert.ErrorIs(t, store.Delete(ctx, "file.txt"), ErrInvalidPath)
+
+	data, err := os.ReadFile(filepath.Join(outside, "rahasia.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "rahasia", string(data), "file di luar root tidak boleh tersentuh")
+}
+
+func TestLocalStorage_RejectsSpecialFiles(t *testing.T) {
+	store, base := newTestLocalStorage(t, LocalOptions{})
+	require.NoError(t, syscall.Mkfifo(filepath.Join(base, "fifo"), 0o600))
+
+	// Membuka FIFO akan memblokir; Get harus menolak sebelum membuka.
+	_, err := store.Get(context.Background(), "fifo")
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	assert.ErrorIs(t, store.Save(context.Background(), "fifo", strings.NewReader("x")), ErrInvalidPath)
+}
+
+func TestLocalStorage_PermissionsAndSharding(t *testing.T) {
+	store, base := newTestLocalStorage(t, LocalOptions{FileMode: 0o600, DirMode: 0o700, ShardDepth: 2})
+	require.NoError(t, store.Save(context.Background(), "2025/laporan.txt", strings.NewReader("isi")))
+
+	physical, err := store.shardedPath("2025/laporan.txt")
+	require.NoError(t, err)
+	parts := strings.Split(physical, string(filepath.Separator))
+	require.Len(t, parts, 4)
+	assert.Len(t, parts[0], 2)
+	assert.Len(t, parts[1], 2)
+
+	info, err := os.Stat(filepath.Join(base, physical))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+	for _, dir := range []string{parts[0], filepath.Join(parts[0], parts[1]), filepath.Join(parts[0], parts[1], "2025")} {
+		info, err := os.Stat(filepath.Join(base, dir))
+		require.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0o700), info.Mode().Perm(), dir)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(filepath.Join(base, physical)))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "file sementara tidak boleh tertinggal")
+}
+
+func TestLocalStorage_ReadsLegacyFlatLayout(t *testing.T) {
+	store, base := newTestLocalStorage(t, LocalOptions{ShardDepth: 2})
+	ctx := context.Background()
+	require.NoError(t, os.WriteFile(filepath.Join(base, "lama.pdf"), []byte("sebelum sharding"), 0o644))
+
+	reader, err := store.Get(ctx, "lama.pdf")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, "sebelum sharding", string(data))
+
+	require.NoError(t, store.Delete(ctx, "lama.pdf"))
+	assert.NoFileExists(t, filepath.Join(base, "lama.pdf"))
+	_, err = store.Get(ctx, "lama.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNewLocalStorage_InvalidShardDepth(t *testing.T) {
+	_, err := NewLocalStorage(t.TempDir(), LocalOptions{ShardDepth: maxShardDepth + 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package storage
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// replace me-rename from menjadi to di dalam dir lewat renameat terhadap
+// descriptor direktori yang dibuka dari root, lalu melakukan fsync direktori
+// agar rename bertahan setelah crash.
+func (l *LocalStorage) replace(dir, from, to string) error {
+	d, err := l.root.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+
+	fd := int(d.Fd())
+	if err := unix.Renameat(fd, from, fd, to); err != nil {
+		return &os.LinkError{Op: "renameat", Old: from, New: to, Err: err}
+	}
+	return d.Sync()
+}
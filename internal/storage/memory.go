@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// MemoryStorage adalah implementasi Storage di memori untuk unit test dan
+// mode dev. Konten hilang saat proses berhenti.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string][]byte)}
+}
+
+// Save membaca seluruh konten sebelum menyimpannya, sehingga konten yang
+// gagal dibaca tidak pernah terlihat sebagian.
+func (m *MemoryStorage) Save(ctx context.Context, path string, content io.Reader) error {
+	data, err := io.ReadAll(contextReader{ctx: ctx, r: content})
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[path] = data
+	return nil
+}
+
+// Get mengembalikan reader atas konten saat ini; reader juga mendukung Seek
+// dan ReadAt.
+func (m *MemoryStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.objects[path]
+	if !ok {
+		return nil, notFound(path, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist})
+	}
+	// Konten tidak pernah diubah di tempat (Save mengganti slice), jadi reader
+	// aman berbagi slice yang sama.
+	return memoryReader{bytes.NewReader(data)}, nil
+}
+
+func (m *MemoryStorage) Delete(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, path)
+	return nil
+}
+
+// Len mengembalikan jumlah objek yang tersimpan.
+func (m *MemoryStorage) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.objects)
+}
+
+type memoryReader struct {
+	*bytes.Reader
+}
+
+func (memoryReader) Close() error { return nil }
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage_SaveGetDelete(t *testing.T) {
+	store := NewMemoryStorage()
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, "2025/laporan.txt", strings.NewReader("isi awal")))
+	require.NoError(t, store.Save(ctx, "2025/laporan.txt", strings.NewReader("isi baru")))
+	assert.Equal(t, "isi baru", readAll(t, store, "2025/laporan.txt"))
+	assert.Equal(t, 1, store.Len())
+
+	reader, err := store.Get(ctx, "2025/laporan.txt")
+	require.NoError(t, err)
+	seeker, ok := reader.(io.Seeker)
+	require.True(t, ok, "reader memori harus mendukung Seek untuk unduhan rentang")
+	_, err = seeker.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "baru", string(rest))
+	require.NoError(t, reader.Close())
+
+	require.NoError(t, store.Delete(ctx, "2025/laporan.txt"))
+	_, err = store.Get(ctx, "2025/laporan.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Zero(t, store.Len())
+}
+
+func TestMemoryStorage_FailedContentIsNotCommitted(t *testing.T) {
+	store := NewMemoryStorage()
+	ctx := context.Background()
+	require.NoError(t, store.Save(ctx, "a.txt", strings.NewReader("lama")))
+
+	contentErr := errors.New("koneksi klien terputus")
+	assert.ErrorIs(t, store.Save(ctx, "a.txt", failingReader{err: contentErr}), contentErr)
+	assert.Equal(t, "lama", readAll(t, store, "a.txt"))
+}
+
+func TestMemoryStorage_Concurrent(t *testing.T) {
+	store := NewMemoryStorage()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := strings.Repeat("x", i+1)
+			assert.NoError(t, store.Save(ctx, path, strings.NewReader(path)))
+			reader, err := store.Get(ctx, path)
+			if assert.NoError(t, err) {
+				data, _ := io.ReadAll(reader)
+				assert.Equal(t, path, string(data))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 20, store.Len())
+}
@@ -2,28 +2,110 @@ package storage
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
 )
 
+// Mode enkripsi sisi server untuk S3Options.Encryption.
+const (
+	S3EncryptionNone     = ""
+	S3EncryptionS3       = "sse-s3"
+	S3EncryptionKMS      = "sse-kms"
+	S3EncryptionCustomer = "sse-c"
+)
+
+// abortTimeout membatasi AbortMultipartUpload yang dijalankan setelah unggahan gagal.
+const abortTimeout = 30 * time.Second
+
+// S3Options berisi parameter koneksi dan unggahan S3Storage.
+type S3Options struct {
+	Region       string
+	Endpoint     string
+	AccessKey    string
+	SecretKey    string
+	Bucket       string
+	UsePathStyle bool
+	// PartSize adalah ukuran tiap part unggahan multipart dalam byte (minimal
+	// 5 MiB). Konten yang lebih kecil dikirim dengan satu PutObject.
+	PartSize int64
+	// Concurrency adalah jumlah part yang diunggah bersamaan per objek.
+	Concurrency int
+	// ChecksumAlgorithm adalah "SHA256" (bawaan) atau "CRC32C"; dihitung SDK
+	// untuk setiap part dan diverifikasi oleh S3.
+	ChecksumAlgorithm string
+	// Encryption adalah salah satu konstanta S3Encryption*.
+	Encryption string
+	// KMSKeyID adalah key KMS untuk sse-kms; kosong berarti key bawaan akun.
+	KMSKeyID string
+	// CustomerKey adalah key AES-256 (base64, 32 byte) untuk sse-c. Key yang
+	// sama wajib dikirim saat membaca objek.
+	CustomerKey string
+	// APIOptions ditambahkan ke setiap operasi klien S3, mis. middleware
+	// tracing.
+	APIOptions []func(*middleware.Stack) error
+}
+
 // S3Storage adalah implementasi Storage untuk S3-compatible object storage.
+// Konten diunggah lewat multipart upload manager dengan checksum per part.
 type S3Storage struct {
-	client *s3.Client
-	bucket string
+	client   *s3.Client
+	uploader *manager.Uploader
+	bucket   string
+	checksum types.ChecksumAlgorithm
+	opts     S3Options
+	// customerKeyMD5 adalah MD5 (base64) dari CustomerKey untuk header sse-c.
+	customerKeyMD5 string
 }
 
-func NewS3Storage(ctx context.Context, region, endpoint, accessKey, secretKey, bucket string, usePathStyle bool) (*S3Storage, error) {
+func NewS3Storage(ctx context.Context, opts S3Options) (*S3Storage, error) {
+	checksum, err := parseChecksumAlgorithm(opts.ChecksumAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	if opts.PartSize == 0 {
+		opts.PartSize = manager.DefaultUploadPartSize
+	}
+	if opts.PartSize < manager.MinUploadPartSize {
+		return nil, fmt.Errorf("ukuran part S3 minimal %d byte", manager.MinUploadPartSize)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = manager.DefaultUploadConcurrency
+	}
+
+	var customerKeyMD5 string
+	switch opts.Encryption {
+	case S3EncryptionNone, S3EncryptionS3, S3EncryptionKMS:
+	case S3EncryptionCustomer:
+		key, err := base64.StdEncoding.DecodeString(opts.CustomerKey)
+		if err != nil || len(key) != 32 {
+			return nil, errors.New("key sse-c S3 harus berupa 32 byte dalam base64")
+		}
+		sum := md5.Sum(key)
+		customerKeyMD5 = base64.StdEncoding.EncodeToString(sum[:])
+	default:
+		return nil, fmt.Errorf("mode enkripsi S3 tidak dikenal: %s", opts.Encryption)
+	}
+
 	// Muat konfigurasi dasar tanpa endpoint resolver global.
 	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
+		config.WithRegion(opts.Region),
 		config.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+			credentials.NewStaticCredentialsProvider(opts.AccessKey, opts.SecretKey, ""),
 		),
 	)
 	if err != nil {
@@ -33,34 +115,105 @@ func NewS3Storage(ctx context.Context, region, endpoint, accessKey, secretKey, b
 	// Buat klien S3 dengan opsi kustom.
 	// Ini adalah cara yang benar untuk menangani endpoint custom seperti Minio.
 	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.UsePathStyle = usePathStyle
-		if endpoint != "" {
+		o.UsePathStyle = opts.UsePathStyle
+		o.APIOptions = append(o.APIOptions, opts.APIOptions...)
+		if opts.Endpoint != "" {
 			// Suntikkan resolver langsung ke opsi klien S3.
-			o.BaseEndpoint = aws.String(endpoint)
+			o.BaseEndpoint = aws.String(opts.Endpoint)
 		}
 	})
 
-	log.Printf("S3 Storage client berhasil diinisialisasi untuk bucket '%s'", bucket)
+	uploader := manager.NewUploader(s3Client, func(u *manager.Uploader) {
+		u.PartSize = opts.PartSize
+		u.Concurrency = opts.Concurrency
+		// Pembatalan dilakukan sendiri oleh abortUpload dengan context yang
+		// tidak ikut dibatalkan bersama unggahan.
+		u.LeavePartsOnError = true
+	})
+
+	log.Printf("S3 Storage client berhasil diinisialisasi untuk bucket '%s' (part %d byte, checksum %s, enkripsi %q)", opts.Bucket, opts.PartSize, checksum, opts.Encryption)
 	return &S3Storage{
-		client: s3Client,
-		bucket: bucket,
+		client:         s3Client,
+		uploader:       uploader,
+		bucket:         opts.Bucket,
+		checksum:       checksum,
+		opts:           opts,
+		customerKeyMD5: customerKeyMD5,
 	}, nil
 }
 
+func parseChecksumAlgorithm(name string) (types.ChecksumAlgorithm, error) {
+	switch strings.ToUpper(name) {
+	case "", "SHA256":
+		return types.ChecksumAlgorithmSha256, nil
+	case "CRC32C":
+		return types.ChecksumAlgorithmCrc32c, nil
+	default:
+		return "", fmt.Errorf("algoritma checksum S3 tidak didukung: %s", name)
+	}
+}
+
 func (s *S3Storage) Save(ctx context.Context, path string, content io.Reader) error {
-	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(path),
-		Body:   content,
-	})
+	return s.SaveWithAttributes(ctx, path, content, ObjectAttributes{})
+}
+
+// SaveWithAttributes mengunggah konten beserta Content-Type,
+// Content-Disposition dan metadata kustom (x-amz-meta-*). Jika unggahan
+// multipart gagal, part yang sudah terunggah dibatalkan.
+func (s *S3Storage) SaveWithAttributes(ctx context.Context, path string, content io.Reader, attrs ObjectAttributes) error {
+	body := &s3Body{r: contextReader{ctx: ctx, r: content}}
+	input := &s3.PutObjectInput{
+		Bucket:            aws.String(s.bucket),
+		Key:               aws.String(path),
+		Body:              body,
+		ChecksumAlgorithm: s.checksum,
+		Metadata:          attrs.Metadata,
+	}
+	if attrs.ContentType != "" {
+		input.ContentType = aws.String(attrs.ContentType)
+	}
+	if attrs.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(attrs.ContentDisposition)
+	}
+	switch s.opts.Encryption {
+	case S3EncryptionS3:
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+	case S3EncryptionKMS:
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		if s.opts.KMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(s.opts.KMSKeyID)
+		}
+	case S3EncryptionCustomer:
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = s.customerKeyHeaders()
+	}
+
+	_, err := s.uploader.Upload(ctx, input)
+	if err == nil {
+		return nil
+	}
+	var failure manager.MultiUploadFailure
+	if errors.As(err, &failure) && failure.UploadID() != "" {
+		s.abortUpload(ctx, path, failure.UploadID())
+	}
+	if readErr := body.readErr(); readErr != nil {
+		return fmt.Errorf("gagal membaca konten unggahan S3: %w", readErr)
+	}
 	return err
 }
 
 func (s *S3Storage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
-	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(path),
-	})
+	}
+	if s.opts.Encryption == S3EncryptionCustomer {
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = s.customerKeyHeaders()
+	}
+	output, err := s.client.GetObject(ctx, input)
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, notFound(path, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -74,3 +227,84 @@ func (s *S3Storage) Delete(ctx context.Context, path string) error {
 	})
 	return err
 }
+
+// AbortStaleUploads membatalkan unggahan multipart di bucket yang dimulai
+// lebih dari olderThan lalu dan tidak pernah selesai, mis. karena proses mati
+// di tengah unggahan. Mengembalikan jumlah unggahan yang dibatalkan.
+func (s *S3Storage) AbortStaleUploads(ctx context.Context, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	paginator := s3.NewListMultipartUploadsPaginator(s.client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(s.bucket),
+	})
+	aborted := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return aborted, fmt.Errorf("gagal membaca daftar unggahan multipart S3: %w", err)
+		}
+		for _, upload := range page.Uploads {
+			if upload.Initiated == nil || !upload.Initiated.Before(cutoff) {
+				continue
+			}
+			_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(s.bucket),
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+			var noSuchUpload *types.NoSuchUpload
+			if err != nil && !errors.As(err, &noSuchUpload) {
+				return aborted, fmt.Errorf("gagal membatalkan unggahan multipart S3 %s: %w", aws.ToString(upload.UploadId), err)
+			}
+			aborted++
+		}
+	}
+	return aborted, nil
+}
+
+// abortUpload membatalkan unggahan multipart yang gagal agar part-nya tidak
+// tertinggal dan ditagih. Context pemanggil mungkin sudah dibatalkan, jadi
+// pembatalan memakai context terpisah dengan batas waktu sendiri.
+func (s *S3Storage) abortUpload(ctx context.Context, path, uploadID string) {
+	abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+	defer cancel()
+	_, err := s.client.AbortMultipartUpload(abortCtx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(path),
+		UploadId: aws.String(uploadID),
+	})
+	var noSuchUpload *types.NoSuchUpload
+	if err != nil && !errors.As(err, &noSuchUpload) {
+		log.Printf("Gagal membatalkan unggahan multipart S3 %s untuk %s: %v", uploadID, path, err)
+	}
+}
+
+func (s *S3Storage) customerKeyHeaders() (algorithm, key, keyMD5 *string) {
+	return aws.String("AES256"), aws.String(s.opts.CustomerKey), aws.String(s.customerKeyMD5)
+}
+
+// s3Body mencatat error pertama saat membaca konten. SDK memperlakukan error
+// baca body sebagai kegagalan transport, sehingga tanpa ini error asli konten
+// (mis. klien terputus atau context dibatalkan) hilang dari rantai error.
+type s3Body struct {
+	r   io.Reader
+	mu  sync.Mutex
+	err error
+}
+
+func (b *s3Body) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		b.mu.Lock()
+		if b.err == nil {
+			b.err = err
+		}
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+func (b *s3Body) readErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
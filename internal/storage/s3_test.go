@@ -0,0 +1,549 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPartSize = 5 * 1024 * 1024
+
+// fakeS3Server meniru subset REST API S3 path-style yang dipakai S3Storage:
+// PutObject, GetObject, DeleteObject dan unggahan multipart. Server berjalan
+// dengan TLS seperti S3 sungguhan, sehingga SDK mengirim konten sebagai
+// aws-chunked dengan checksum di trailer. Checksum diverifikasi dan header
+// sse-c diwajibkan seperti S3.
+type fakeS3Server struct {
+	*httptest.Server
+	mu      sync.Mutex
+	objects map[string]*fakeS3Object
+	uploads map[string]*fakeS3Upload
+	nextID  int
+	aborted int
+}
+
+type fakeS3Object struct {
+	data   []byte
+	header http.Header
+	// checksums adalah algoritma checksum yang diverifikasi per PutObject/part.
+	checksums []string
+}
+
+type fakeS3Upload struct {
+	key       string
+	header    http.Header
+	initiated time.Time
+	parts     map[int][]byte
+	checksums []string
+}
+
+// storedHeaders adalah header permintaan yang disimpan bersama objek.
+var storedHeaders = []string{
+	"Content-Type",
+	"Content-Disposition",
+	"X-Amz-Server-Side-Encryption",
+	"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id",
+	"X-Amz-Server-Side-Encryption-Customer-Algorithm",
+	"X-Amz-Server-Side-Encryption-Customer-Key-Md5",
+}
+
+func newFakeS3Server(t *testing.T) *fakeS3Server {
+	t.Helper()
+	fake := &fakeS3Server{objects: map[string]*fakeS3Object{}, uploads: map[string]*fakeS3Upload{}}
+	fake.Server = httptest.NewTLSServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.Close)
+
+	// AWS SDK memuat CA tambahan dari AWS_CA_BUNDLE.
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: fake.Certificate().Raw})
+	require.NoError(t, os.WriteFile(bundle, certificate, 0o600))
+	t.Setenv("AWS_CA_BUNDLE", bundle)
+	return fake
+}
+
+func (f *fakeS3Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		s3Error(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/prism"), "/")
+	query := r.URL.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && query.Has("uploads"):
+		f.listUploads(w)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		id := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[id] = &fakeS3Upload{key: key, header: pickHeaders(r.Header), initiated: time.Now(), parts: map[int][]byte{}}
+		writeXML(w, fmt.Sprintf(`<InitiateMultipartUploadResult><Bucket>prism</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, key, id))
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		upload, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		if !sameCustomerKey(upload.header, r.Header) {
+			s3Error(w, http.StatusBadRequest, "InvalidRequest")
+			return
+		}
+		data, algorithm, ok := readVerifiedBody(w, r)
+		if !ok {
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		upload.parts[number] = data
+		upload.checksums = append(upload.checksums, algorithm)
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, number))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		f.completeUpload(w, r, query.Get("uploadId"))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		if _, ok := f.uploads[query.Get("uploadId")]; !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		delete(f.uploads, query.Get("uploadId"))
+		f.aborted++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data, algorithm, ok := readVerifiedBody(w, r)
+		if !ok {
+			return
+		}
+		f.objects[key] = &fakeS3Object{data: data, header: pickHeaders(r.Header), checksums: []string{algorithm}}
+		w.Header().Set("ETag", `"fake"`)
+	case r.Method == http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if !sameCustomerKey(object.header, r.Header) {
+			s3Error(w, http.StatusBadRequest, "InvalidRequest")
+			return
+		}
+		_, _ = w.Write(object.data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (f *fakeS3Server) completeUpload(w http.ResponseWriter, r *http.Request, uploadID string) {
+	upload, ok := f.uploads[uploadID]
+	if !ok {
+		s3Error(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	var request struct {
+		Parts []struct {
+			PartNumber int
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Parts) == 0 {
+		s3Error(w, http.StatusBadRequest, "MalformedXML")
+		return
+	}
+	var data []byte
+	for i, part := range request.Parts {
+		content, ok := upload.parts[part.PartNumber]
+		if !ok || part.PartNumber != i+1 {
+			s3Error(w, http.StatusBadRequest, "InvalidPart")
+			return
+		}
+		data = append(data, content...)
+	}
+	f.objects[upload.key] = &fakeS3Object{data: data, header: upload.header, checksums: upload.checksums}
+	delete(f.uploads, uploadID)
+	writeXML(w, fmt.Sprintf(`<CompleteMultipartUploadResult><Bucket>prism</Bucket><Key>%s</Key><ETag>"fake-%d"</ETag></CompleteMultipartUploadResult>`, upload.key, len(request.Parts)))
+}
+
+func (f *fakeS3Server) listUploads(w http.ResponseWriter) {
+	ids := make([]string, 0, len(f.uploads))
+	for id := range f.uploads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var body strings.Builder
+	body.WriteString(`<ListMultipartUploadsResult><Bucket>prism</Bucket><IsTruncated>false</IsTruncated>`)
+	for _, id := range ids {
+		upload := f.uploads[id]
+		fmt.Fprintf(&body, `<Upload><Key>%s</Key><UploadId>%s</UploadId><Initiated>%s</Initiated></Upload>`,
+			upload.key, id, upload.initiated.UTC().Format("2006-01-02T15:04:05.000Z"))
+	}
+	body.WriteString(`</ListMultipartUploadsResult>`)
+	writeXML(w, body.String())
+}
+
+// pending mengembalikan jumlah unggahan multipart yang belum selesai.
+func (f *fakeS3Server) pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.uploads)
+}
+
+func (f *fakeS3Server) object(key string) *fakeS3Object {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objects[key]
+}
+
+// readVerifiedBody membaca body PutObject/UploadPart dan memverifikasi
+// checksum dari header atau trailer aws-chunked seperti S3. Mengembalikan
+// algoritma checksum yang diverifikasi.
+func readVerifiedBody(w http.ResponseWriter, r *http.Request) ([]byte, string, bool) {
+	checksums := r.Header.Clone()
+	var data []byte
+	var err error
+	if strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		var trailers http.Header
+		data, trailers, err = decodeAWSChunked(r.Body)
+		for name, values := range trailers {
+			checksums[name] = values
+		}
+	} else {
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		s3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return nil, "", false
+	}
+
+	algorithms := map[string]func() hash.Hash{
+		"sha256": sha256.New,
+		"crc32c": func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+		"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	}
+	for algorithm, newHash := range algorithms {
+		expected := checksums.Get("X-Amz-Checksum-" + algorithm)
+		if expected == "" {
+			continue
+		}
+		h := newHash()
+		h.Write(data)
+		if base64.StdEncoding.EncodeToString(h.Sum(nil)) != expected {
+			s3Error(w, http.StatusBadRequest, "BadDigest")
+			return nil, "", false
+		}
+		return data, algorithm, true
+	}
+	return data, "", true
+}
+
+// decodeAWSChunked mendekode body aws-chunked: setiap chunk berformat
+// "<ukuran hex>[;ekstensi]\r\n<data>\r\n", diakhiri chunk berukuran 0 lalu
+// trailer "nama:nilai\r\n" (mis. checksum) dan baris kosong.
+func decodeAWSChunked(body io.Reader) ([]byte, http.Header, error) {
+	reader := bufio.NewReader(body)
+	var data bytes.Buffer
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, nil, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	trailers := http.Header{}
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			trailers.Set(name, value)
+		}
+		if line == "" || err != nil {
+			return data.Bytes(), trailers, nil
+		}
+	}
+}
+
+func pickHeaders(request http.Header) http.Header {
+	picked := http.Header{}
+	for _, name := range storedHeaders {
+		if value := request.Get(name); value != "" {
+			picked.Set(name, value)
+		}
+	}
+	for name, values := range request {
+		if strings.HasPrefix(name, "X-Amz-Meta-") {
+			picked[name] = values
+		}
+	}
+	return picked
+}
+
+// sameCustomerKey memastikan permintaan membawa key sse-c yang sama dengan
+// objek atau unggahan yang dienkripsi dengan sse-c.
+func sameCustomerKey(stored, request http.Header) bool {
+	const name = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+	return stored.Get(name) == request.Get(name)
+}
+
+func writeXML(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>`+body)
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>`+code+`</Code><Message>fake</Message></Error>`)
+}
+
+// newFakeS3 membuat S3Storage yang terhubung ke fakeS3Server. Opsi koneksi
+// diisi otomatis; PartSize bawaan adalah ukuran part minimum agar objek besar
+// diunggah secara multipart.
+func newFakeS3(t *testing.T, opts S3Options) (*fakeS3Server, *S3Storage) {
+	t.Helper()
+	fake := newFakeS3Server(t)
+	opts.Region, opts.Endpoint, opts.AccessKey, opts.SecretKey = "us-east-1", fake.URL, "akses", "rahasia"
+	opts.Bucket, opts.UsePathStyle = "prism", true
+	if opts.PartSize == 0 {
+		opts.PartSize = testPartSize
+	}
+	store, err := NewS3Storage(context.Background(), opts)
+	require.NoError(t, err)
+	return fake, store
+}
+
+func newFakeS3Storage(t *testing.T) *S3Storage {
+	t.Helper()
+	_, store := newFakeS3(t, S3Options{})
+	return store
+}
+
+func TestS3Storage_MissingKeyKeepsSDKError(t *testing.T) {
+	store := newFakeS3Storage(t)
+
+	_, err := store.Get(context.Background(), "tidak-ada.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+	var noSuchKey *types.NoSuchKey
+	assert.ErrorAs(t, err, &noSuchKey, "error asli SDK tetap dapat diperiksa")
+}
+
+func TestS3Storage_AttributesAndChecksums(t *testing.T) {
+	testCases := []struct {
+		name      string
+		checksum  string
+		size      int
+		algorithm string
+		parts     int
+	}{
+		{"Single PutObject with SHA-256", "", 1024, "sha256", 1},
+		{"Multipart with SHA-256", "SHA256", 2*testPartSize + 10, "sha256", 3},
+		{"Multipart with CRC32C", "crc32c", testPartSize + 1, "crc32c", 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake, store := newFakeS3(t, S3Options{ChecksumAlgorithm: tc.checksum, Concurrency: 2})
+			content := bytes.Repeat([]byte("prism"), tc.size/5+1)[:tc.size]
+			attrs := ObjectAttributes{
+				ContentType:        "application/pdf",
+				ContentDisposition: `attachment; filename="laporan.pdf"`,
+				Metadata:           map[string]string{"file-id": "file-1"},
+			}
+
+			require.NoError(t, store.SaveWithAttributes(context.Background(), "laporan.pdf", strings.NewReader(string(content)), attrs))
+
+			object := fake.object("laporan.pdf")
+			require.NotNil(t, object)
+			assert.True(t, bytes.Equal(content, object.data))
+			assert.Equal(t, "application/pdf", object.header.Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="laporan.pdf"`, object.header.Get("Content-Disposition"))
+			assert.Equal(t, "file-1", object.header.Get("X-Amz-Meta-File-Id"))
+			require.Len(t, object.checksums, tc.parts)
+			for _, algorithm := range object.checksums {
+				assert.Equal(t, tc.algorithm, algorithm, "setiap part wajib membawa checksum")
+			}
+			assert.Zero(t, fake.pending())
+		})
+	}
+}
+
+func TestS3Storage_Encryption(t *testing.T) {
+	customerKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+
+	testCases := []struct {
+		name     string
+		opts     S3Options
+		expected map[string]string
+	}{
+		{
+			name:     "SSE-S3",
+			opts:     S3Options{Encryption: S3EncryptionS3},
+			expected: map[string]string{"X-Amz-Server-Side-Encryption": "AES256"},
+		},
+		{
+			name: "SSE-KMS",
+			opts: S3Options{Encryption: S3EncryptionKMS, KMSKeyID: "arn:aws:kms:us-east-1:111122223333:key/prism"},
+			expected: map[string]string{
+				"X-Amz-Server-Side-Encryption":                "aws:kms",
+				"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": "arn:aws:kms:us-east-1:111122223333:key/prism",
+			},
+		},
+		{
+			name:     "SSE-C",
+			opts:     S3Options{Encryption: S3EncryptionCustomer, CustomerKey: customerKey},
+			expected: map[string]string{"X-Amz-Server-Side-Encryption-Customer-Algorithm": "AES256"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake, store := newFakeS3(t, tc.opts)
+			ctx := context.Background()
+			content := bytes.Repeat([]byte{'x'}, testPartSize+1)
+
+			for _, key := range []string{"kecil.txt", "besar.bin"} {
+				data := content
+				if key == "kecil.txt" {
+					data = []byte("kecil")
+				}
+				require.NoError(t, store.Save(ctx, key, bytes.NewReader(data)))
+				object := fake.object(key)
+				require.NotNil(t, object)
+				for name, value := range tc.expected {
+					assert.Equal(t, value, object.header.Get(name), "%s: %s", key, name)
+				}
+
+				reader, err := store.Get(ctx, key)
+				require.NoError(t, err)
+				got, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				require.NoError(t, reader.Close())
+				assert.True(t, bytes.Equal(data, got))
+			}
+		})
+	}
+
+	t.Run("SSE-C object cannot be read without the key", func(t *testing.T) {
+		fake, store := newFakeS3(t, S3Options{Encryption: S3EncryptionCustomer, CustomerKey: customerKey})
+		require.NoError(t, store.Save(context.Background(), "rahasia.txt", strings.NewReader("rahasia")))
+
+		plain, err := NewS3Storage(context.Background(), S3Options{Region: "us-east-1", Endpoint: fake.URL, AccessKey: "akses", SecretKey: "rahasia", Bucket: "prism", UsePathStyle: true})
+		require.NoError(t, err)
+		_, err = plain.Get(context.Background(), "rahasia.txt")
+		assert.Error(t, err)
+	})
+}
+
+func TestS3Storage_AbortsFailedMultipartUpload(t *testing.T) {
+	contentErr := errors.New("koneksi klien terputus")
+
+	t.Run("Content error", func(t *testing.T) {
+		fake, store := newFakeS3(t, S3Options{})
+		content := io.MultiReader(bytes.NewReader(make([]byte, 2*testPartSize)), failingReader{err: contentErr})
+
+		err := store.Save(context.Background(), "gagal.bin", content)
+		assert.ErrorIs(t, err, contentErr)
+		assert.Zero(t, fake.pending(), "part yang sudah terunggah harus dibatalkan")
+		assert.Equal(t, 1, fake.aborted)
+		assert.Nil(t, fake.object("gagal.bin"))
+	})
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		fake, store := newFakeS3(t, S3Options{})
+		ctx, cancel := context.WithCancel(context.Background())
+		content := io.MultiReader(bytes.NewReader(make([]byte, 2*testPartSize)), cancelReader{cancel}, bytes.NewReader(make([]byte, testPartSize)))
+
+		assert.Error(t, store.Save(ctx, "batal.bin", content))
+		assert.Zero(t, fake.pending(), "unggahan tetap dibatalkan walau context pemanggil sudah dibatalkan")
+		assert.Nil(t, fake.object("batal.bin"))
+	})
+}
+
+func TestS3Storage_AbortStaleUploads(t *testing.T) {
+	fake, store := newFakeS3(t, S3Options{})
+	ctx := context.Background()
+
+	for _, key := range []string{"lama.bin", "baru.bin"} {
+		_, err := store.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: aws.String("prism"), Key: aws.String(key)})
+		require.NoError(t, err)
+	}
+	fake.mu.Lock()
+	for _, upload := range fake.uploads {
+		if upload.key == "lama.bin" {
+			upload.initiated = time.Now().Add(-48 * time.Hour)
+		}
+	}
+	fake.mu.Unlock()
+
+	aborted, err := store.AbortStaleUploads(ctx, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, aborted)
+	require.Equal(t, 1, fake.pending())
+	for _, upload := range fake.uploads {
+		assert.Equal(t, "baru.bin", upload.key)
+	}
+}
+
+func TestNewS3Storage_InvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts S3Options
+	}{
+		{"Part size below minimum", S3Options{PartSize: 1024}},
+		{"Unknown checksum", S3Options{ChecksumAlgorithm: "MD5"}},
+		{"Unknown encryption", S3Options{Encryption: "rot13"}},
+		{"SSE-C without key", S3Options{Encryption: S3EncryptionCustomer}},
+		{"SSE-C key too short", S3Options{Encryption: S3EncryptionCustomer, CustomerKey: base64.StdEncoding.EncodeToString([]byte("pendek"))}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.Region, tc.opts.Bucket = "us-east-1", "prism"
+			_, err := NewS3Storage(context.Background(), tc.opts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// cancelReader membatalkan context saat dibaca; pembacaan berlanjut ke reader
+// berikutnya dalam io.MultiReader.
+type cancelReader struct{ cancel context.CancelFunc }
+
+func (r cancelReader) Read([]byte) (int, error) {
+	r.cancel()
+	return 0, io.EOF
+}
@@ -0,0 +1,398 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const posixRenameExtension = "posix-rename@openssh.com"
+
+// SFTPOptions berisi parameter koneksi SFTPStorage.
+type SFTPOptions struct {
+	Host string
+	Port int
+	User string
+	// Password dan/atau PrivateKey (PEM, tanpa passphrase); minimal satu wajib diisi.
+	Password   string
+	PrivateKey string
+	// HostKey adalah public key server dalam format authorized_keys
+	// (mis. "ssh-ed25519 AAAA..."). Wajib, agar koneksi tidak rentan MITM.
+	HostKey string
+	// BasePath adalah direktori di server tempat semua file disimpan.
+	BasePath string
+	// MaxConnections membatasi jumlah koneksi SSH yang dibuka bersamaan.
+	MaxConnections int
+	// MaxRetries adalah jumlah percobaan ulang saat koneksi gagal atau terputus.
+	MaxRetries int
+	// RetryDelay adalah jeda awal antar percobaan (berlipat ganda tiap percobaan).
+	RetryDelay time.Duration
+}
+
+// SFTPStorage adalah implementasi Storage untuk server SFTP. Koneksi SSH
+// dipakai ulang lewat pool, operasi diulang jika koneksi terputus, dan Save
+// menulis ke file sementara lalu me-rename agar pembaca tidak melihat file
+// yang belum lengkap.
+type SFTPStorage struct {
+	opts      SFTPOptions
+	addr      string
+	sshConfig *ssh.ClientConfig
+	// slots membatasi jumlah koneksi aktif; idle menyimpan koneksi yang siap dipakai ulang.
+	slots chan struct{}
+	idle  chan *sftpConn
+}
+
+type sftpConn struct {
+	ssh    *ssh.Client
+	client *sftp.Client
+}
+
+func (c *sftpConn) close() {
+	_ = c.client.Close()
+	_ = c.ssh.Close()
+}
+
+func NewSFTPStorage(opts SFTPOptions) (*SFTPStorage, error) {
+	if opts.Host == "" || opts.User == "" {
+		return nil, errors.New("host dan user SFTP wajib diisi")
+	}
+	if opts.HostKey == "" {
+		return nil, errors.New("host key SFTP wajib diisi")
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(opts.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca host key SFTP: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if opts.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(opts.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca private key SFTP: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if opts.Password != "" {
+		auth = append(auth, ssh.Password(opts.Password))
+	}
+	if len(auth) == 0 {
+		return nil, errors.New("password atau private key SFTP wajib diisi")
+	}
+
+	if opts.Port == 0 {
+		opts.Port = 22
+	}
+	if opts.BasePath == "" {
+		opts.BasePath = "."
+	}
+	if opts.MaxConnections <= 0 {
+		opts.MaxConnections = 4
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 200 * time.Millisecond
+	}
+
+	log.Printf("SFTP Storage diinisialisasi untuk %s@%s:%d%s", opts.User, opts.Host, opts.Port, opts.BasePath)
+	return &SFTPStorage{
+		opts: opts,
+		addr: net.JoinHostPort(opts.Host, strconv.Itoa(opts.Port)),
+		sshConfig: &ssh.ClientConfig{
+			User:            opts.User,
+			Auth:            auth,
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+			Timeout:         10 * time.Second,
+		},
+		slots: make(chan struct{}, opts.MaxConnections),
+		idle:  make(chan *sftpConn, opts.MaxConnections),
+	}, nil
+}
+
+func (s *SFTPStorage) Save(ctx context.Context, p string, content io.Reader) error {
+	target := s.fullPath(p)
+	tracked := &trackingReader{r: contextReader{ctx: ctx, r: content}}
+	seeker, seekable := content.(io.Seeker)
+
+	return s.run(ctx, func(conn *sftpConn) error {
+		// Percobaan ulang hanya aman jika konten belum terbaca atau dapat diulang dari awal.
+		if tracked.consumed {
+			if !seekable {
+				return errors.New("koneksi SFTP terputus saat mengunggah dan konten tidak dapat dibaca ulang")
+			}
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("gagal mengulang konten unggahan: %w", err)
+			}
+		}
+
+		dir := path.Dir(target)
+		if err := conn.client.MkdirAll(dir); err != nil {
+			return fmt.Errorf("gagal membuat direktori SFTP: %w", err)
+		}
+		tmp := path.Join(dir, fmt.Sprintf(".%s.%s.tmp", path.Base(target), randomSuffix()))
+		if err := writeSFTPFile(conn.client, tmp, tracked); err != nil {
+			_ = conn.client.Remove(tmp)
+			return err
+		}
+		if err := renameSFTPFile(conn.client, tmp, target); err != nil {
+			_ = conn.client.Remove(tmp)
+			return fmt.Errorf("gagal memindahkan file sementara SFTP: %w", err)
+		}
+		return nil
+	})
+}
+
+func writeSFTPFile(client *sftp.Client, name string, content io.Reader) error {
+	file, err := client.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return fmt.Errorf("gagal membuat file sementara SFTP: %w", err)
+	}
+	if _, err := file.ReadFrom(content); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("gagal menulis file SFTP: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("gagal menutup file SFTP: %w", err)
+	}
+	return nil
+}
+
+// renameSFTPFile memakai ekstensi posix-rename agar file tujuan diganti secara
+// atomik. Rename SFTP v3 biasa gagal jika tujuan sudah ada, sehingga tanpa
+// ekstensi itu tujuan dihapus lebih dulu.
+func renameSFTPFile(client *sftp.Client, from, to string) error {
+	if _, ok := client.HasExtension(posixRenameExtension); ok {
+		return client.PosixRename(from, to)
+	}
+	if err := client.Remove(to); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return client.Rename(from, to)
+}
+
+func (s *SFTPStorage) Get(ctx context.Context, p string) (io.ReadCloser, error) {
+	var file *sftp.File
+	conn, err := s.withRetry(ctx, func(conn *sftpConn) error {
+		var err error
+		file, err = conn.client.Open(s.fullPath(p))
+		return err
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, notFound(p, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sftpReader{File: file, storage: s, conn: conn}, nil
+}
+
+func (s *SFTPStorage) Delete(ctx context.Context, p string) error {
+	return s.run(ctx, func(conn *sftpConn) error {
+		if err := conn.client.Remove(s.fullPath(p)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Close menutup semua koneksi idle di pool.
+func (s *SFTPStorage) Close() error {
+	for {
+		select {
+		case conn := <-s.idle:
+			conn.close()
+		default:
+			return nil
+		}
+	}
+}
+
+// fullPath menggabungkan path dengan BasePath tanpa membiarkan ".." keluar
+// dari BasePath.
+func (s *SFTPStorage) fullPath(p string) string {
+	return path.Join(s.opts.BasePath, path.Clean("/"+p))
+}
+
+// run menjalankan fn dengan percobaan ulang lalu mengembalikan koneksi ke pool.
+func (s *SFTPStorage) run(ctx context.Context, fn func(conn *sftpConn) error) error {
+	conn, err := s.withRetry(ctx, fn)
+	if err != nil {
+		return err
+	}
+	s.release(conn, nil)
+	return nil
+}
+
+// withRetry menjalankan fn dan mengulanginya dengan koneksi baru selama
+// errornya adalah error koneksi. Jika berhasil, koneksi dikembalikan ke
+// pemanggil yang wajib memanggil release.
+func (s *SFTPStorage) withRetry(ctx context.Context, fn func(conn *sftpConn) error) (*sftpConn, error) {
+	delay := s.opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		conn, err := s.acquire(ctx)
+		if err == nil {
+			if err = fn(conn); err == nil {
+				return conn, nil
+			}
+			s.release(conn, err)
+		}
+		if !isConnectionError(err) || attempt >= s.opts.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		log.Printf("Koneksi SFTP ke %s gagal (percobaan %d/%d): %v", s.addr, attempt+1, s.opts.MaxRetries, err)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *SFTPStorage) acquire(ctx context.Context) (*sftpConn, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case conn := <-s.idle:
+		return conn, nil
+	default:
+	}
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		<-s.slots
+		return nil, err
+	}
+	return conn, nil
+}
+
+// release mengembalikan koneksi ke pool, atau menutupnya jika err
+// menunjukkan koneksi sudah tidak dapat dipakai.
+func (s *SFTPStorage) release(conn *sftpConn, err error) {
+	if isConnectionError(err) {
+		conn.close()
+	} else {
+		select {
+		case s.idle <- conn:
+		default:
+			conn.close()
+		}
+	}
+	<-s.slots
+}
+
+func (s *SFTPStorage) dial(ctx context.Context) (*sftpConn, error) {
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("gagal terhubung ke server SFTP: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, s.addr, s.sshConfig)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("gagal melakukan handshake SSH: %w", err)
+	}
+	_ = netConn.SetDeadline(time.Time{})
+
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, fmt.Errorf("gagal membuka subsistem SFTP: %w", err)
+	}
+	return &sftpConn{ssh: sshClient, client: client}, nil
+}
+
+// isConnectionError melaporkan apakah err berasal dari koneksi yang gagal
+// atau terputus, bukan dari operasi file itu sendiri.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var readErr *contentReadError
+	if errors.As(err, &readErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, sftp.ErrSSHFxConnectionLost) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.As(err, &netErr)
+}
+
+// sftpReader mengembalikan koneksi ke pool saat reader ditutup.
+type sftpReader struct {
+	*sftp.File
+	storage *SFTPStorage
+	conn    *sftpConn
+	readErr error
+}
+
+func (r *sftpReader) Read(p []byte) (int, error) {
+	n, err := r.File.Read(p)
+	if err != nil && err != io.EOF {
+		r.readErr = err
+	}
+	return n, err
+}
+
+func (r *sftpReader) Close() error {
+	err := r.File.Close()
+	connErr := r.readErr
+	if connErr == nil {
+		connErr = err
+	}
+	r.storage.release(r.conn, connErr)
+	return err
+}
+
+// trackingReader mencatat apakah konten sudah mulai dibaca, dan menandai error
+// dari konten agar tidak dianggap sebagai koneksi SFTP yang terputus.
+type trackingReader struct {
+	r        io.Reader
+	consumed bool
+}
+
+func (t *trackingReader) Read(p []byte) (int, error) {
+	t.consumed = true
+	n, err := t.r.Read(p)
+	if err != nil && err != io.EOF {
+		return n, &contentReadError{err: err}
+	}
+	return n, err
+}
+
+type contentReadError struct{ err error }
+
+func (e *contentReadError) Error() string { return e.err.Error() }
+func (e *contentReadError) Unwrap() error { return e.err }
+
+func randomSuffix() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testSFTPServer adalah server SSH in-process dengan subsistem SFTP yang
+// melayani filesystem lokal.
+type testSFTPServer struct {
+	addr    string
+	hostKey string
+
+	mu      sync.Mutex
+	conns   map[net.Conn]bool
+	maxOpen int
+}
+
+func newTestSFTPServer(t *testing.T, clientKey ssh.PublicKey) *testSFTPServer {
+	t.Helper()
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "prism" && string(password) == "rahasia" {
+				return nil, nil
+			}
+			return nil, errors.New("password salah")
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if clientKey != nil && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("key tidak dikenal")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &testSFTPServer{
+		addr:    listener.Addr().String(),
+		hostKey: string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())),
+		conns:   map[net.Conn]bool{},
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		server.dropConnections()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.track(conn, true)
+			go server.serve(conn, config)
+		}
+	}()
+	return server
+}
+
+func (s *testSFTPServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	defer s.track(conn, false)
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "hanya session")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						_ = server.Serve()
+					}
+					_ = channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func (s *testSFTPServer) track(conn net.Conn, open bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if open {
+		s.conns[conn] = true
+		s.maxOpen = max(s.maxOpen, len(s.conns))
+	} else {
+		delete(s.conns, conn)
+	}
+}
+
+// dropConnections memutus semua koneksi dari sisi server.
+func (s *testSFTPServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *testSFTPServer) options(t *testing.T, basePath string) SFTPOptions {
+	host, port, err := net.SplitHostPort(s.addr)
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return SFTPOptions{Host: host, Port: portNumber, User: "prism", Password: "rahasia", HostKey: s.hostKey, BasePath: basePath, MaxRetries: 2, RetryDelay: time.Millisecond}
+}
+
+func readAll(t *testing.T, storage Storage, path string) string {
+	t.Helper()
+	reader, err := storage.Get(context.Background(), path)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSFTPStorage_SaveGetDelete(t *testing.T) {
+	server := newTestSFTPServer(t, nil)
+	base := t.TempDir()
+	store, err := NewSFTPStorage(server.options(t, base))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, "2025/01/laporan.pdf", strings.NewReader("versi 1")))
+	assert.Equal(t, "versi 1", readAll(t, store, "2025/01/laporan.pdf"))
+
+	require.NoError(t, store.Save(ctx, "2025/01/laporan.pdf", strings.NewReader("versi 2")))
+	assert.Equal(t, "versi 2", readAll(t, store, "2025/01/laporan.pdf"))
+
+	entries, err := os.ReadDir(filepath.Join(base, "2025/01"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "file sementara harus sudah di-rename")
+	assert.Equal(t, "laporan.pdf", entries[0].Name())
+
+	require.NoError(t, store.Save(ctx, "../../luar.txt", strings.NewReader("x")))
+	_, err = os.Stat(filepath.Join(base, "luar.txt"))
+	assert.NoError(t, err, "path tidak boleh keluar dari base path")
+
+	require.NoError(t, store.Delete(ctx, "2025/01/laporan.pdf"))
+	_, err = store.Get(ctx, "2025/01/laporan.pdf")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSFTPStorage_PrivateKeyAuth(t *testing.T) {
+	_, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(clientPriv)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	require.NoError(t, err)
+
+	server := newTestSFTPServer(t, signer.PublicKey())
+	opts := server.options(t, t.TempDir())
+	opts.Password = ""
+	opts.PrivateKey = string(pem.EncodeToMemory(block))
+	store, err := NewSFTPStorage(opts)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save(context.Background(), "kunci.txt", strings.NewReader("ok")))
+	assert.Equal(t, "ok", readAll(t, store, "kunci.txt"))
+}
+
+func TestSFTPStorage_RejectsUnknownHostKey(t *testing.T) {
+	server := newTestSFTPServer(t, nil)
+	other := newTestSFTPServer(t, nil)
+	opts := server.options(t, t.TempDir())
+	opts.HostKey = other.hostKey
+	store, err := NewSFTPStorage(opts)
+	require.NoError(t, err)
+
+	err = store.Save(context.Background(), "a.txt", strings.NewReader("x"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handshake")
+}
+
+func TestSFTPStorage_ReconnectsAfterConnectionLoss(t *testing.T) {
+	server := newTestSFTPServer(t, nil)
+	store, err := NewSFTPStorage(server.options(t, t.TempDir()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, "a.txt", strings.NewReader("sebelum")))
+	server.dropConnections()
+
+	// Koneksi idle di pool sudah mati; operasi berikutnya harus membuka koneksi baru.
+	require.NoError(t, store.Save(ctx, "b.txt", bytes.NewReader([]byte("sesudah"))))
+	assert.Equal(t, "sebelum", readAll(t, store, "a.txt"))
+	assert.Equal(t, "sesudah", readAll(t, store, "b.txt"))
+}
+
+func TestSFTPStorage_PoolLimitsConnections(t *testing.T) {
+	server := newTestSFTPServer(t, nil)
+	opts := server.options(t, t.TempDir())
+	opts.MaxConnections = 2
+	store, err := NewSFTPStorage(opts)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.Save(context.Background(), filepath.Join("paralel", string(rune('a'+i))), strings.NewReader("isi")))
+		}()
+	}
+	wg.Wait()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.LessOrEqual(t, server.maxOpen, 2)
+}
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read(p []byte) (int, error) { return 0, r.err }
+
+func TestSFTPStorage_ContentErrorIsNotRetried(t *testing.T) {
+	server := newTestSFTPServer(t, nil)
+	base := t.TempDir()
+	store, err := NewSFTPStorage(server.options(t, base))
+	require.NoError(t, err)
+
+	contentErr := errors.New("ukuran melebihi batas")
+	err = store.Save(context.Background(), "gagal.txt", failingReader{err: contentErr})
+	assert.ErrorIs(t, err, contentErr)
+
+	entries, err := os.ReadDir(base)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "file sementara harus dihapus")
+}
@@ -2,15 +2,114 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"time"
 )
 
+// ErrNotFound dikembalikan (terbungkus) oleh setiap backend saat path tidak
+// ada, sehingga pemanggil dapat memeriksanya dengan errors.Is tanpa mengenal
+// error khas backend. Error asli backend tetap ada dalam rantai error.
+var ErrNotFound = errors.New("objek tidak ditemukan di storage")
+
+// ErrInvalidPath dikembalikan saat path tidak boleh dipakai: keluar dari
+// direktori dasar, melewati symlink, atau menunjuk ke selain file biasa.
+var ErrInvalidPath = errors.New("path storage tidak valid")
+
+// ErrSigningUnsupported dikembalikan SignedURL jika backend tidak dapat
+// membuat URL unduhan bertanda tangan.
+var ErrSigningUnsupported = errors.New("backend storage tidak mendukung URL bertanda tangan")
+
 // Storage mendefinisikan kontrak untuk semua backend penyimpanan file.
 type Storage interface {
-	// Save menyimpan konten dari reader ke path yang diberikan.
+	// Save menyimpan konten dari reader ke path yang diberikan. Konten lama
+	// diganti secara utuh: pembaca tidak pernah melihat konten yang tercampur,
+	// dan konten yang gagal dibaca tidak tersimpan.
 	Save(ctx context.Context, path string, content io.Reader) error
-	// Get mengembalikan reader untuk konten file di path yang diberikan.
+	// Get mengembalikan reader untuk konten file di path yang diberikan, atau
+	// error yang cocok dengan ErrNotFound jika path tidak ada.
 	Get(ctx context.Context, path string) (io.ReadCloser, error)
-	// Delete menghapus file dari path yang diberikan.
+	// Delete menghapus file dari path yang diberikan. Menghapus path yang
+	// tidak ada bukan error.
 	Delete(ctx context.Context, path string) error
 }
+
+// URLSigner diimplementasikan backend yang dapat membuat URL unduhan
+// bertanda tangan (mis. SAS Azure atau signed URL GCS), sehingga klien dapat
+// mengunduh langsung dari penyedia tanpa melewati layanan ini.
+type URLSigner interface {
+	// SignedURL mengembalikan URL baca untuk path yang berlaku selama expiry.
+	SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error)
+}
+
+// SignedURL membuat URL unduhan bertanda tangan jika s mendukungnya, atau
+// mengembalikan ErrSigningUnsupported jika tidak. Pembungkus seperti Switch
+// memakainya agar kemampuan backend tetap terlihat dari luar.
+func SignedURL(ctx context.Context, s Storage, path string, expiry time.Duration) (string, error) {
+	if signer, ok := s.(URLSigner); ok {
+		return signer.SignedURL(ctx, path, expiry)
+	}
+	return "", ErrSigningUnsupported
+}
+
+// ObjectAttributes berisi atribut HTTP dan metadata kustom yang disimpan
+// bersama konten oleh backend yang mendukungnya.
+type ObjectAttributes struct {
+	ContentType        string
+	ContentDisposition string
+	// Metadata disimpan sebagai metadata kustom objek (mis. x-amz-meta-*).
+	Metadata map[string]string
+}
+
+// AttributeSaver diimplementasikan backend yang dapat menyimpan
+// ObjectAttributes bersama konten, sehingga unduhan langsung dari penyedia
+// membawa Content-Type dan nama file yang benar.
+type AttributeSaver interface {
+	SaveWithAttributes(ctx context.Context, path string, content io.Reader, attrs ObjectAttributes) error
+}
+
+// SaveWithAttributes menyimpan konten beserta attrs jika s mendukungnya, dan
+// hanya kontennya jika tidak.
+func SaveWithAttributes(ctx context.Context, s Storage, path string, content io.Reader, attrs ObjectAttributes) error {
+	if saver, ok := s.(AttributeSaver); ok {
+		return saver.SaveWithAttributes(ctx, path, content, attrs)
+	}
+	return s.Save(ctx, path, content)
+}
+
+// Skip melompati n byte pertama r untuk pembacaan rentang, memakai Seek jika
+// reader dari storage mendukungnya.
+func Skip(r io.Reader, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekStart)
+		return err
+	}
+	skipped, err := io.CopyN(io.Discard, r, n)
+	if err != nil {
+		return fmt.Errorf("hanya %d dari %d byte dapat dilompati: %w", skipped, n, err)
+	}
+	return nil
+}
+
+// notFound membungkus error backend untuk path yang tidak ada dengan ErrNotFound.
+func notFound(path string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrNotFound, path, err)
+}
+
+// contextReader menghentikan pembacaan konten begitu ctx dibatalkan, untuk
+// backend yang menyalin konten sendiri tanpa klien yang sadar context.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
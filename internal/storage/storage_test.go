@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkip(t *testing.T) {
+	testCases := []struct {
+		name        string
+		reader      io.Reader
+		n           int64
+		want        string
+		expectError bool
+	}{
+		{name: "Seeker", reader: bytes.NewReader([]byte("0123456789")), n: 4, want: "456789"},
+		{name: "Plain reader", reader: io.MultiReader(strings.NewReader("0123456789")), n: 4, want: "456789"},
+		{name: "Nothing to skip", reader: io.MultiReader(strings.NewReader("0123")), want: "0123"},
+		{name: "Content shorter than offset", reader: io.MultiReader(strings.NewReader("012")), n: 4, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Skip(tc.reader, tc.n)
+			if tc.expectError {
+				assert.ErrorIs(t, err, io.EOF)
+				return
+			}
+			require.NoError(t, err)
+			rest, err := io.ReadAll(tc.reader)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(rest))
+		})
+	}
+}
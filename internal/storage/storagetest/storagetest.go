@@ -0,0 +1,199 @@
+// Package storagetest berisi test kesesuaian yang wajib dilewati setiap
+// implementasi storage.Storage, agar semua backend berperilaku sama di mata
+// service dan handler.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// LargeObjectSize melebihi ukuran blok Azure (4 MiB) dan chunk GCS (8 MiB)
+// sehingga jalur unggahan bertahap setiap backend ikut teruji.
+const LargeObjectSize = 10*1024*1024 + 123
+
+// Factory membuat storage kosong untuk satu subtest. Pembersihan didaftarkan
+// lewat t.Cleanup.
+type Factory func(t *testing.T) storage.Storage
+
+// Run menjalankan seluruh test kesesuaian terhadap storage buatan newStorage.
+func Run(t *testing.T, newStorage Factory) {
+	t.Run("SaveGetDelete", func(t *testing.T) { testSaveGetDelete(t, newStorage(t)) })
+	t.Run("Overwrite", func(t *testing.T) { testOverwrite(t, newStorage(t)) })
+	t.Run("MissingKey", func(t *testing.T) { testMissingKey(t, newStorage(t)) })
+	t.Run("NestedPaths", func(t *testing.T) { testNestedPaths(t, newStorage(t)) })
+	t.Run("EmptyObject", func(t *testing.T) { testEmptyObject(t, newStorage(t)) })
+	t.Run("LargeObject", func(t *testing.T) { testLargeObject(t, newStorage(t)) })
+	t.Run("ContentError", func(t *testing.T) { testContentError(t, newStorage(t)) })
+	t.Run("ContextCancellation", func(t *testing.T) { testContextCancellation(t, newStorage(t)) })
+	t.Run("ConcurrentAccess", func(t *testing.T) { testConcurrentAccess(t, newStorage(t)) })
+}
+
+func testSaveGetDelete(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	require.NoError(t, s.Save(ctx, "laporan.txt", bytes.NewReader([]byte("isi laporan"))))
+	assert.Equal(t, "isi laporan", string(read(t, s, "laporan.txt")))
+
+	require.NoError(t, s.Delete(ctx, "laporan.txt"))
+	assertNotFound(t, s, "laporan.txt")
+}
+
+func testOverwrite(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	require.NoError(t, s.Save(ctx, "a.txt", bytes.NewReader([]byte("konten lama yang lebih panjang"))))
+	require.NoError(t, s.Save(ctx, "a.txt", bytes.NewReader([]byte("baru"))))
+	assert.Equal(t, "baru", string(read(t, s, "a.txt")), "konten lama tidak boleh tersisa")
+}
+
+func testMissingKey(t *testing.T, s storage.Storage) {
+	assertNotFound(t, s, "tidak-ada.txt")
+	assertNotFound(t, s, "folder-tidak-ada/tidak-ada.txt")
+	assert.NoError(t, s.Delete(context.Background(), "tidak-ada.txt"), "menghapus path yang tidak ada bukan error")
+}
+
+func testNestedPaths(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	require.NoError(t, s.Save(ctx, "2025/01/a.txt", bytes.NewReader([]byte("a"))))
+	require.NoError(t, s.Save(ctx, "2025/01/sub/b.txt", bytes.NewReader([]byte("b"))))
+	require.NoError(t, s.Save(ctx, "2025/c.txt", bytes.NewReader([]byte("c"))))
+
+	assert.Equal(t, "a", string(read(t, s, "2025/01/a.txt")))
+	assert.Equal(t, "b", string(read(t, s, "2025/01/sub/b.txt")))
+
+	require.NoError(t, s.Delete(ctx, "2025/01/sub/b.txt"))
+	assertNotFound(t, s, "2025/01/sub/b.txt")
+	assert.Equal(t, "a", string(read(t, s, "2025/01/a.txt")), "menghapus satu path tidak memengaruhi path lain")
+	assert.Equal(t, "c", string(read(t, s, "2025/c.txt")))
+}
+
+func testEmptyObject(t *testing.T, s storage.Storage) {
+	require.NoError(t, s.Save(context.Background(), "kosong.txt", bytes.NewReader(nil)))
+	assert.Empty(t, read(t, s, "kosong.txt"))
+}
+
+func testLargeObject(t *testing.T, s storage.Storage) {
+	content := make([]byte, LargeObjectSize)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Save(context.Background(), "besar.bin", bytes.NewReader(content)))
+	got := read(t, s, "besar.bin")
+	require.Equal(t, len(content), len(got))
+	assert.True(t, bytes.Equal(content, got), "konten objek besar harus identik")
+}
+
+func testContentError(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	require.NoError(t, s.Save(ctx, "a.txt", bytes.NewReader([]byte("lama"))))
+
+	contentErr := errors.New("koneksi klien terputus")
+	err := s.Save(ctx, "a.txt", io.MultiReader(bytes.NewReader([]byte("sebagian")), errReader{contentErr}))
+	assert.ErrorIs(t, err, contentErr)
+	assert.Equal(t, "lama", string(read(t, s, "a.txt")), "konten yang gagal dibaca tidak boleh tersimpan")
+
+	err = s.Save(ctx, "baru.txt", io.MultiReader(bytes.NewReader([]byte("sebagian")), errReader{contentErr}))
+	assert.ErrorIs(t, err, contentErr)
+	assertNotFound(t, s, "baru.txt")
+}
+
+func testContextCancellation(t *testing.T, s storage.Storage) {
+	require.NoError(t, s.Save(context.Background(), "a.txt", bytes.NewReader([]byte("lama"))))
+
+	// Context dibatalkan di tengah pembacaan konten.
+	ctx, cancel := context.WithCancel(context.Background())
+	content := io.MultiReader(bytes.NewReader([]byte("sebagian")), cancelReader{cancel}, bytes.NewReader([]byte("sisa")))
+	assert.Error(t, s.Save(ctx, "a.txt", content))
+	assert.Equal(t, "lama", string(read(t, s, "a.txt")), "unggahan yang dibatalkan tidak boleh tersimpan")
+
+	// Context yang sudah dibatalkan ditolak sebelum menyentuh backend.
+	_, err := s.Get(ctx, "a.txt")
+	assert.Error(t, err)
+	assert.Error(t, s.Save(ctx, "b.txt", bytes.NewReader([]byte("b"))))
+	assertNotFound(t, s, "b.txt")
+}
+
+func testConcurrentAccess(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	const workers = 8
+
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := fmt.Sprintf("paralel/%d.txt", i)
+			content := fmt.Sprintf("konten %d", i)
+			if !assert.NoError(t, s.Save(ctx, path, bytes.NewReader([]byte(content)))) {
+				return
+			}
+			// require tidak boleh dipakai di luar goroutine test.
+			reader, err := s.Get(ctx, path)
+			if assert.NoError(t, err) {
+				data, err := io.ReadAll(reader)
+				assert.NoError(t, err)
+				assert.Equal(t, content, string(data))
+				_ = reader.Close()
+			}
+			assert.NoError(t, s.Delete(ctx, path))
+		}()
+	}
+	wg.Wait()
+
+	// Penulis bersamaan pada path yang sama: hasil akhirnya harus salah satu
+	// konten secara utuh, bukan campuran.
+	contents := make([]string, workers)
+	for i := range workers {
+		contents[i] = string(bytes.Repeat([]byte{byte('a' + i)}, 64*1024))
+	}
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.Save(ctx, "sama.txt", bytes.NewReader([]byte(contents[i]))))
+		}()
+	}
+	wg.Wait()
+	assert.Contains(t, contents, string(read(t, s, "sama.txt")))
+}
+
+func read(t *testing.T, s storage.Storage, path string) []byte {
+	t.Helper()
+	reader, err := s.Get(context.Background(), path)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data
+}
+
+func assertNotFound(t *testing.T, s storage.Storage, path string) {
+	t.Helper()
+	reader, err := s.Get(context.Background(), path)
+	if reader != nil {
+		_ = reader.Close()
+	}
+	assert.ErrorIs(t, err, storage.ErrNotFound, "path %q", path)
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// cancelReader membatalkan context saat dibaca; pembacaan berlanjut ke reader
+// berikutnya dalam io.MultiReader.
+type cancelReader struct{ cancel context.CancelFunc }
+
+func (r cancelReader) Read([]byte) (int, error) {
+	r.cancel()
+	return 0, io.EOF
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Switch meneruskan setiap operasi ke backend aktif yang dapat diganti saat
+// runtime, mis. ketika konfigurasi storage berubah di Consul. Operasi yang
+// sudah berjalan tetap memakai backend lama sampai selesai. Interface
+// opsional AttributeSaver dan URLSigner diteruskan ke backend aktif.
+type Switch struct {
+	current atomic.Pointer[switchEntry]
+}
+
+// switchEntry membungkus Storage karena atomic.Pointer membutuhkan tipe konkret.
+type switchEntry struct {
+	storage Storage
+}
+
+func NewSwitch(initial Storage) *Switch {
+	s := &Switch{}
+	s.current.Store(&switchEntry{storage: initial})
+	return s
+}
+
+// Current mengembalikan backend yang sedang aktif.
+func (s *Switch) Current() Storage {
+	return s.current.Load().storage
+}
+
+// Swap mengaktifkan next dan mengembalikan backend sebelumnya. Pemanggil
+// bertanggung jawab menutup backend lama setelah operasi yang berjalan selesai.
+func (s *Switch) Swap(next Storage) Storage {
+	return s.current.Swap(&switchEntry{storage: next}).storage
+}
+
+func (s *Switch) Save(ctx context.Context, path string, content io.Reader) error {
+	return s.Current().Save(ctx, path, content)
+}
+
+// SaveWithAttributes meneruskan attrs jika backend aktif mendukungnya.
+func (s *Switch) SaveWithAttributes(ctx context.Context, path string, content io.Reader, attrs ObjectAttributes) error {
+	return SaveWithAttributes(ctx, s.Current(), path, content, attrs)
+}
+
+func (s *Switch) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	return s.Current().Get(ctx, path)
+}
+
+func (s *Switch) Delete(ctx context.Context, path string) error {
+	return s.Current().Delete(ctx, path)
+}
+
+// SignedURL meneruskan ke backend aktif, atau mengembalikan
+// ErrSigningUnsupported jika backend tersebut tidak mendukungnya.
+func (s *Switch) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	return SignedURL(ctx, s.Current(), path, expiry)
+}
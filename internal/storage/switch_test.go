@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwitch_Swap(t *testing.T) {
+	ctx := context.Background()
+	first, second := NewMemoryStorage(), NewMemoryStorage()
+	sw := NewSwitch(first)
+
+	require.NoError(t, sw.Save(ctx, "a.txt", strings.NewReader("lama")))
+	reader, err := sw.Get(ctx, "a.txt")
+	require.NoError(t, err)
+
+	assert.Same(t, first, sw.Swap(second))
+	assert.Same(t, second, sw.Current())
+	require.NoError(t, sw.Save(ctx, "b.txt", strings.NewReader("baru")))
+	assert.Equal(t, 1, first.Len(), "Penyimpanan setelah Swap harus masuk ke backend baru")
+	assert.Equal(t, 1, second.Len())
+
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "lama", string(content), "Reader yang sudah dibuka tetap membaca dari backend lama")
+	_, err = sw.Get(ctx, "a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSwitch_SaveWithAttributes(t *testing.T) {
+	ctx := context.Background()
+	sw := NewSwitch(NewMemoryStorage())
+	require.NoError(t, SaveWithAttributes(ctx, sw, "a.txt", strings.NewReader("isi"), ObjectAttributes{ContentType: "text/plain"}))
+	assert.Equal(t, "isi", readAll(t, sw, "a.txt"))
+}
+
+// signingStorage adalah backend tiruan yang mendukung URLSigner.
+type signingStorage struct {
+	Storage
+}
+
+func (s signingStorage) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	return "https://signed.example/" + path + "?expiry=" + expiry.String(), nil
+}
+
+func TestSwitch_SignedURL(t *testing.T) {
+	ctx := context.Background()
+	sw := NewSwitch(NewMemoryStorage())
+
+	_, err := SignedURL(ctx, sw, "a.txt", time.Minute)
+	assert.ErrorIs(t, err, ErrSigningUnsupported, "Backend tanpa URLSigner harus dilaporkan apa adanya")
+
+	sw.Swap(signingStorage{Storage: NewMemoryStorage()})
+	url, err := SignedURL(ctx, sw, "a.txt", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "https://signed.example/a.txt?expiry=1m0s", url)
+}
@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go/middleware"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AWSMiddleware menambahkan span klien untuk setiap operasi AWS SDK v2, mis.
+// "S3.PutObject". Daftarkan pada APIOptions klien agar unggahan multipart
+// juga menghasilkan satu span per part. Span berakhir saat respons selesai
+// didekode; pembacaan body GetObject tercakup span storage.get.
+func AWSMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(awsSpanMiddleware{}, middleware.After)
+}
+
+type awsSpanMiddleware struct{}
+
+func (awsSpanMiddleware) ID() string {
+	return "PrismOTelSpan"
+}
+
+func (awsSpanMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+	ctx, span := Start(ctx, service+"."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.RPCSystemKey.String("aws-api"),
+		semconv.RPCService(service),
+		semconv.RPCMethod(operation),
+		semconv.CloudRegion(awsmiddleware.GetRegion(ctx)),
+	))
+	defer func() { End(span, err) }()
+
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		span.SetAttributes(semconv.AWSRequestID(requestID))
+	}
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(responseErr.HTTPStatusCode()))
+		if requestID := responseErr.ServiceRequestID(); requestID != "" {
+			span.SetAttributes(semconv.AWSRequestID(requestID))
+		}
+	}
+	return out, metadata, err
+}
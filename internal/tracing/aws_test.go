@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAWSMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-amz-request-id", "REQ123")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>tidak ada</Message><RequestId>REQ123</RequestId></Error>`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("akses", "rahasia", ""),
+		APIOptions:   []func(*middleware.Stack) error{AWSMiddleware},
+	})
+	recorder := newRecorder(t)
+	ctx, parent := Start(context.Background(), "storage.get")
+
+	_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String("prism"), Key: aws.String("a.txt")})
+	require.NoError(t, err)
+	_, err = client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String("prism"), Key: aws.String("b.txt")})
+	require.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, span := range spans[:2] {
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+
+	assert.Equal(t, "S3.DeleteObject", spans[0].Name())
+	attrs := attributes(spans[0])
+	assert.Equal(t, "aws-api", attrs[semconv.RPCSystemKey].AsString())
+	assert.Equal(t, "S3", attrs[semconv.RPCServiceKey].AsString())
+	assert.Equal(t, "DeleteObject", attrs[semconv.RPCMethodKey].AsString())
+	assert.Equal(t, "us-east-1", attrs[semconv.CloudRegionKey].AsString())
+	assert.Equal(t, "REQ123", attrs[semconv.AWSRequestIDKey].AsString())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "S3.GetObject", spans[1].Name())
+	attrs = attributes(spans[1])
+	assert.Equal(t, int64(http.StatusNotFound), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	assert.Equal(t, "REQ123", attrs[semconv.AWSRequestIDKey].AsString())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer membuat span klien untuk setiap Query, QueryRow dan Exec pgx.
+// Pasang lewat pgxpool.Config.ConnConfig.Tracer. Hanya teks SQL yang
+// dicatat; argumen query tidak, karena dapat berisi data pengguna.
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(data.SQL),
+	}
+	if conn != nil {
+		attrs = append(attrs, semconv.DBNamespace(conn.Config().Database))
+	}
+	ctx, _ = Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err == nil {
+		span.SetAttributes(semconv.DBResponseReturnedRows(int(data.CommandTag.RowsAffected())))
+	}
+	End(span, data.Err)
+}
+
+// sqlOperation mengembalikan kata kunci pertama SQL (mis. SELECT atau WITH)
+// sebagai nama span, agar nama span tetap sedikit dan tidak memuat nilai.
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "SQL"
+	}
+	return strings.ToUpper(fields[0])
+}
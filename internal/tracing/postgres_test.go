@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSQLOperation(t *testing.T) {
+	testCases := []struct {
+		sql  string
+		want string
+	}{
+		{sql: "SELECT id FROM files WHERE id = $1", want: "SELECT"},
+		{sql: "\n\t  update files SET version = version + 1", want: "UPDATE"},
+		{sql: "WITH deleted AS (DELETE FROM files) SELECT 1", want: "WITH"},
+		{sql: "   ", want: "SQL"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, sqlOperation(tc.sql))
+		})
+	}
+}
+
+func TestQueryTracer(t *testing.T) {
+	recorder := newRecorder(t)
+	tracer := QueryTracer{}
+	sql := "UPDATE files SET deleted_at = now() WHERE id = $1"
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{"rahasia"}})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 3")})
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("koneksi terputus")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "UPDATE", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	attrs := attributes(spans[0])
+	assert.Equal(t, sql, attrs[semconv.DBQueryTextKey].AsString())
+	assert.Equal(t, "postgresql", attrs[semconv.DBSystemNameKey].AsString())
+	assert.Equal(t, int64(3), attrs[semconv.DBResponseReturnedRowsKey].AsInt64())
+	for _, kv := range spans[0].Attributes() {
+		assert.NotContains(t, kv.Value.Emit(), "rahasia", "Argumen query tidak dicatat")
+	}
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
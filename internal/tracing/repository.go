@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedFileRepository membungkus FileRepository dan membuat span untuk
+// setiap operasi. Query SQL di dalamnya menjadi span anak lewat QueryTracer.
+type tracedFileRepository struct {
+	next repository.FileRepository
+}
+
+func NewFileRepository(next repository.FileRepository) repository.FileRepository {
+	return &tracedFileRepository{next: next}
+}
+
+func (r *tracedFileRepository) Create(ctx context.Context, metadata *model.FileMetadata, tags []string) (err error) {
+	ctx, span := Start(ctx, "FileRepository.Create", trace.WithAttributes(
+		FileIDKey.String(metadata.ID),
+		FileSizeKey.Int64(metadata.SizeBytes),
+		FileMimeTypeKey.String(metadata.MimeType),
+	))
+	defer func() { End(span, err) }()
+	return r.next.Create(ctx, metadata, tags)
+}
+
+func (r *tracedFileRepository) GetByID(ctx context.Context, id string) (_ *model.FileMetadata, err error) {
+	ctx, span := startFile(ctx, "FileRepository.GetByID", id)
+	defer func() { End(span, err) }()
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *tracedFileRepository) DeleteByID(ctx context.Context, id string) (err error) {
+	ctx, span := startFile(ctx, "FileRepository.DeleteByID", id)
+	defer func() { End(span, err) }()
+	return r.next.DeleteByID(ctx, id)
+}
+
+func (r *tracedFileRepository) CheckRoleAccess(ctx context.Context, fileID string, roleName string) (_ bool, err error) {
+	ctx, span := startFile(ctx, "FileRepository.CheckRoleAccess", fileID)
+	defer func() { End(span, err) }()
+	return r.next.CheckRoleAccess(ctx, fileID, roleName)
+}
+
+func (r *tracedFileRepository) List(ctx context.Context, filter repository.FileFilter) (files []*model.FileMetadata, err error) {
+	ctx, span := Start(ctx, "FileRepository.List")
+	defer func() {
+		span.SetAttributes(FileCountKey.Int(len(files)))
+		End(span, err)
+	}()
+	return r.next.List(ctx, filter)
+}
+
+func (r *tracedFileRepository) UpdateMetadata(ctx context.Context, id string, expectedVersion int64, update repository.MetadataUpdate, actorID string) (err error) {
+	ctx, span := startFile(ctx, "FileRepository.UpdateMetadata", id)
+	defer func() { End(span, err) }()
+	return r.next.UpdateMetadata(ctx, id, expectedVersion, update, actorID)
+}
+
+func (r *tracedFileRepository) AddTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) (err error) {
+	ctx, span := startFile(ctx, "FileRepository.AddTag", id)
+	defer func() { End(span, err) }()
+	return r.next.AddTag(ctx, id, tag, expectedVersion, actorID)
+}
+
+func (r *tracedFileRepository) RemoveTag(ctx context.Context, id string, tag string, expectedVersion int64, actorID string) (err error) {
+	ctx, span := startFile(ctx, "FileRepository.RemoveTag", id)
+	defer func() { End(span, err) }()
+	return r.next.RemoveTag(ctx, id, tag, expectedVersion, actorID)
+}
+
+// startFile memulai span untuk operasi pada satu file.
+func startFile(ctx context.Context, name, fileID string) (context.Context, trace.Span) {
+	return Start(ctx, name, trace.WithAttributes(FileIDKey.String(fileID)))
+}
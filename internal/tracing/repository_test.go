@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFileRepository(t *testing.T) {
+	ctx := context.Background()
+	recorder := newRecorder(t)
+	repo := NewFileRepository(repository.NewMemoryFileRepository(repository.NewMemoryAccessRuleRepository()))
+
+	parentCtx, parent := Start(ctx, "request")
+	metadata := &model.FileMetadata{ID: "file-1", OriginalName: "a.png", StoragePath: "file-1.png", MimeType: "image/png", SizeBytes: 42, Version: 1}
+	require.NoError(t, repo.Create(parentCtx, metadata, nil))
+	_, err := repo.GetByID(parentCtx, "tidak-ada")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	files, err := repo.List(parentCtx, repository.FileFilter{})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	for _, span := range spans[:3] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), "Span repository menjadi anak span pemanggil")
+		assert.Equal(t, trace.SpanKindInternal, span.SpanKind())
+	}
+
+	assert.Equal(t, "FileRepository.Create", spans[0].Name())
+	attrs := attributes(spans[0])
+	assert.Equal(t, "file-1", attrs[FileIDKey].AsString())
+	assert.Equal(t, int64(42), attrs[FileSizeKey].AsInt64())
+	assert.Equal(t, "image/png", attrs[FileMimeTypeKey].AsString())
+
+	assert.Equal(t, "FileRepository.GetByID", spans[1].Name())
+	assert.Equal(t, "not_found", attributes(spans[1])[errorTypeKey].AsString())
+
+	assert.Equal(t, "FileRepository.List", spans[2].Name())
+	assert.Equal(t, int64(1), attributes(spans[2])[FileCountKey].AsInt64())
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedStorage membungkus backend storage dan membuat span untuk setiap
+// operasi. SaveWithAttributes, SignedURL, dan Close diteruskan ke backend
+// jika didukung.
+type tracedStorage struct {
+	next    storage.Storage
+	backend string
+}
+
+// NewStorage membungkus next dengan span berlabel backend (nilai
+// storage_backend, mis. "s3").
+func NewStorage(next storage.Storage, backend string) storage.Storage {
+	return &tracedStorage{next: next, backend: backend}
+}
+
+func (s *tracedStorage) Save(ctx context.Context, path string, content io.Reader) error {
+	ctx, span := s.start(ctx, "storage.save", path)
+	counter := &countingReader{r: content}
+	err := s.next.Save(ctx, path, counter)
+	span.SetAttributes(BytesTransferredKey.Int64(counter.n.Load()))
+	End(span, err)
+	return err
+}
+
+func (s *tracedStorage) SaveWithAttributes(ctx context.Context, path string, content io.Reader, attrs storage.ObjectAttributes) error {
+	ctx, span := s.start(ctx, "storage.save", path, FileMimeTypeKey.String(attrs.ContentType))
+	counter := &countingReader{r: content}
+	err := storage.SaveWithAttributes(ctx, s.next, path, counter, attrs)
+	span.SetAttributes(BytesTransferredKey.Int64(counter.n.Load()))
+	End(span, err)
+	return err
+}
+
+// Get membuat span yang berlangsung sampai reader ditutup, sehingga durasi
+// span mencakup transfer konten dan bytes.transferred berisi byte yang
+// benar-benar dibaca.
+func (s *tracedStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	ctx, span := s.start(ctx, "storage.get", path)
+	reader, err := s.next.Get(ctx, path)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	traced := &spanReader{ReadCloser: reader, span: span}
+	if seeker, ok := reader.(io.Seeker); ok {
+		// Unduhan rentang melompat dengan Seek; byte yang dilompati tidak
+		// dihitung.
+		return struct {
+			io.ReadCloser
+			io.Seeker
+		}{traced, seeker}, nil
+	}
+	return traced, nil
+}
+
+func (s *tracedStorage) Delete(ctx context.Context, path string) error {
+	ctx, span := s.start(ctx, "storage.delete", path)
+	err := s.next.Delete(ctx, path)
+	End(span, err)
+	return err
+}
+
+func (s *tracedStorage) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	ctx, span := s.start(ctx, "storage.signed_url", path)
+	url, err := storage.SignedURL(ctx, s.next, path, expiry)
+	End(span, err)
+	return url, err
+}
+
+// Close menutup backend jika backend memegang koneksi atau handle.
+func (s *tracedStorage) Close() error {
+	if closer, ok := s.next.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *tracedStorage) start(ctx context.Context, name, path string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, StorageBackendKey.String(s.backend), StoragePathKey.String(path))
+	return Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// countingReader menghitung byte konten yang dibaca backend. Backend dapat
+// membaca dari goroutine lain (mis. unggahan multipart), sehingga hitungan
+// disimpan secara atomik.
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// spanReader menghitung byte yang dibaca dan menutup span tepat sekali saat
+// reader ditutup.
+type spanReader struct {
+	io.ReadCloser
+	span trace.Span
+	n    atomic.Int64
+	once sync.Once
+}
+
+func (r *spanReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n.Add(int64(n))
+	return n, err
+}
+
+func (r *spanReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(func() {
+		r.span.SetAttributes(BytesTransferredKey.Int64(r.n.Load()))
+		End(r.span, err)
+	})
+	return err
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// closingStorage mencatat apakah Close diteruskan ke backend.
+type closingStorage struct {
+	storage.Storage
+	closed bool
+}
+
+func (s *closingStorage) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	recorder := newRecorder(t)
+	backend := &closingStorage{Storage: storage.NewMemoryStorage()}
+	store := NewStorage(backend, "memory")
+
+	require.NoError(t, store.Save(ctx, "a.txt", strings.NewReader("isi file")))
+	require.NoError(t, storage.SaveWithAttributes(ctx, store, "b.txt", strings.NewReader("isi"), storage.ObjectAttributes{ContentType: "text/plain"}))
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "storage.save", spans[0].Name())
+	attrs := attributes(spans[0])
+	assert.Equal(t, "memory", attrs[StorageBackendKey].AsString())
+	assert.Equal(t, "a.txt", attrs[StoragePathKey].AsString())
+	assert.Equal(t, int64(8), attrs[BytesTransferredKey].AsInt64())
+	assert.Equal(t, "text/plain", attributes(spans[1])[FileMimeTypeKey].AsString())
+
+	reader, err := store.Get(ctx, "a.txt")
+	require.NoError(t, err)
+	assert.Len(t, recorder.Ended(), 2, "Span get berakhir saat reader ditutup")
+	seeker, ok := reader.(io.Seeker)
+	require.True(t, ok, "Seek dari backend dipertahankan")
+	_, err = seeker.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "file", string(content))
+	require.NoError(t, reader.Close())
+	require.NoError(t, reader.Close())
+	spans = recorder.Ended()
+	require.Len(t, spans, 3, "Close berulang hanya menutup span sekali")
+	assert.Equal(t, "storage.get", spans[2].Name())
+	assert.Equal(t, int64(4), attributes(spans[2])[BytesTransferredKey].AsInt64(), "Byte yang dilompati tidak dihitung")
+
+	_, err = store.Get(ctx, "tidak-ada.txt")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	spans = recorder.Ended()
+	require.Len(t, spans, 4)
+	assert.Equal(t, codes.Unset, spans[3].Status().Code)
+	assert.Equal(t, "not_found", attributes(spans[3])[errorTypeKey].AsString())
+
+	require.NoError(t, store.Delete(ctx, "a.txt"))
+	spans = recorder.Ended()
+	require.Len(t, spans, 5)
+	assert.Equal(t, "storage.delete", spans[4].Name())
+
+	closer, ok := store.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+	assert.True(t, backend.closed)
+}
+
+// signingStorage adalah backend tiruan yang mendukung storage.URLSigner.
+type signingStorage struct {
+	storage.Storage
+}
+
+func (s signingStorage) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	return "https://signed.example/" + path, nil
+}
+
+func TestStorage_SignedURL(t *testing.T) {
+	ctx := context.Background()
+	url, err := storage.SignedURL(ctx, NewStorage(signingStorage{Storage: storage.NewMemoryStorage()}, "gcs"), "a.txt", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "https://signed.example/a.txt", url)
+
+	_, err = storage.SignedURL(ctx, NewStorage(storage.NewMemoryStorage(), "local"), "a.txt", time.Minute)
+	assert.ErrorIs(t, err, storage.ErrSigningUnsupported)
+}
@@ -0,0 +1,95 @@
+// Package tracing menambahkan span OpenTelemetry di bawah span request
+// otelgin: decorator repository metadata file dan backend storage, tracer
+// query pgx, middleware AWS SDK, event untuk unggahan yang ditolak validasi,
+// serta hook zerolog yang menyisipkan trace_id dan span_id ke log. Span dibuat
+// lewat tracer provider global yang dipasang telemetry.InitTracerProvider;
+// tanpa provider (mode dev) semua span adalah no-op.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName adalah nama instrumentation scope untuk semua span
+// layanan ini.
+const instrumentationName = "github.com/Lumina-Enterprise-Solutions/prism-file-service"
+
+// Atribut span domain file. Atribut standar (db.*, rpc.*, http.*) memakai
+// paket semconv.
+const (
+	FileIDKey       = attribute.Key("file.id")
+	FileNameKey     = attribute.Key("file.name")
+	FileSizeKey     = attribute.Key("file.size")
+	FileMimeTypeKey = attribute.Key("file.mime_type")
+	FileCountKey    = attribute.Key("file.count")
+
+	StorageBackendKey = attribute.Key("storage.backend")
+	StoragePathKey    = attribute.Key("storage.path")
+	// BytesTransferredKey adalah jumlah byte yang benar-benar dibaca atau
+	// ditulis selama span, bukan ukuran file yang tercatat di metadata.
+	BytesTransferredKey = attribute.Key("bytes.transferred")
+
+	ValidationCodeKey = attribute.Key("validation.code")
+	errorTypeKey      = attribute.Key("error.type")
+)
+
+// EventUploadRejected adalah nama event span saat unggahan ditolak validasi.
+const EventUploadRejected = "upload.rejected"
+
+// Start memulai span anak dari span di ctx dengan tracer layanan ini. Tracer
+// diambil dari provider global setiap kali agar provider yang dipasang setelah
+// startup (atau di test) ikut dipakai.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End menutup span dan mencatat err. Objek atau baris yang tidak ada dan
+// penolakan validasi hanya ditandai dengan atribut error.type, bukan status
+// error, agar tidak tampak sebagai kegagalan layanan di Jaeger.
+func End(span trace.Span, err error) {
+	defer span.End()
+	switch {
+	case err == nil:
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, repository.ErrNotFound):
+		span.SetAttributes(errorTypeKey.String("not_found"))
+	default:
+		if _, ok := validation.AsError(err); ok {
+			span.SetAttributes(errorTypeKey.String("validation"))
+			return
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// RecordUploadRejection menambahkan event upload.rejected berisi kode dan
+// pesan penolakan ke span aktif di ctx.
+func RecordUploadRejection(ctx context.Context, err *validation.Error) {
+	trace.SpanFromContext(ctx).AddEvent(EventUploadRejected, trace.WithAttributes(
+		ValidationCodeKey.String(string(err.Code)),
+		attribute.String("validation.message", err.Message),
+	))
+}
+
+// LogHook menambahkan trace_id dan span_id ke setiap event log yang membawa
+// context dengan span aktif, mis. log.Error().Ctx(ctx). Pasang sekali pada
+// logger global saat startup.
+type LogHook struct{}
+
+func (LogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	spanContext := trace.SpanContextFromContext(e.GetCtx())
+	if !spanContext.IsValid() {
+		return
+	}
+	e.Str("trace_id", spanContext.TraceID().String()).Str("span_id", spanContext.SpanID().String())
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/validation"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newRecorder memasang tracer provider global yang merekam span selesai
+// selama test berjalan.
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// attributes mengubah atribut span menjadi map agar mudah dibandingkan.
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	values := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestEnd(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		wantStatus    codes.Code
+		wantErrorType string
+	}{
+		{name: "Success", err: nil, wantStatus: codes.Unset},
+		{name: "Storage object not found", err: fmt.Errorf("get: %w", storage.ErrNotFound), wantStatus: codes.Unset, wantErrorType: "not_found"},
+		{name: "Repository row not found", err: repository.ErrNotFound, wantStatus: codes.Unset, wantErrorType: "not_found"},
+		{name: "Validation rejection", err: validation.Errorf(validation.CodeFileTooLarge, "terlalu besar"), wantStatus: codes.Unset, wantErrorType: "validation"},
+		{name: "Backend failure", err: errors.New("koneksi terputus"), wantStatus: codes.Error},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := newRecorder(t)
+			_, span := Start(context.Background(), "operasi")
+			End(span, tc.err)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.wantStatus, spans[0].Status().Code)
+			errorType, ok := attributes(spans[0])[errorTypeKey]
+			if tc.wantErrorType == "" {
+				assert.False(t, ok)
+			} else {
+				assert.Equal(t, tc.wantErrorType, errorType.AsString())
+			}
+		})
+	}
+}
+
+func TestRecordUploadRejection(t *testing.T) {
+	recorder := newRecorder(t)
+	ctx, span := Start(context.Background(), "upload")
+	RecordUploadRejection(ctx, &validation.Error{Code: validation.CodeMimeTypeNotAllowed, Message: "tipe tidak diizinkan"})
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Events(), 1)
+	event := spans[0].Events()[0]
+	assert.Equal(t, EventUploadRejected, event.Name)
+	assert.Contains(t, event.Attributes, ValidationCodeKey.String(string(validation.CodeMimeTypeNotAllowed)))
+	assert.Contains(t, event.Attributes, attribute.String("validation.message", "tipe tidak diizinkan"))
+}
+
+func TestLogHook(t *testing.T) {
+	newRecorder(t)
+	ctx, span := Start(context.Background(), "request")
+	defer span.End()
+
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		wantTrace bool
+	}{
+		{name: "Context with active span", ctx: ctx, wantTrace: true},
+		{name: "Context without span", ctx: context.Background(), wantTrace: false},
+		{name: "Event without context", ctx: nil, wantTrace: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := zerolog.New(&buf).Hook(LogHook{})
+			event := logger.Info()
+			if tc.ctx != nil {
+				event = event.Ctx(tc.ctx)
+			}
+			event.Msg("pesan")
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			if tc.wantTrace {
+				assert.Equal(t, span.SpanContext().TraceID().String(), entry["trace_id"])
+				assert.Equal(t, span.SpanContext().SpanID().String(), entry["span_id"])
+			} else {
+				assert.NotContains(t, entry, "trace_id")
+				assert.NotContains(t, entry, "span_id")
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package uploadpolicy memilih batasan unggahan (ukuran, tipe MIME, ekstensi,
+// tag wajib, retensi bawaan dan pembersihan metadata) berdasarkan tujuan
+// unggahan (purpose), peran pengunggah dan tag file. Kebijakan disimpan
+// sebagai dokumen JSON di Consul KV; jika tidak ada yang cocok, batas global
+// layanan yang berlaku.
+package uploadpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultPolicyName adalah nama yang dilaporkan untuk batas global; tidak
+// boleh dipakai oleh kebijakan di dokumen.
+const DefaultPolicyName = "default"
+
+var ErrInvalidPolicy = errors.New("kebijakan upload tidak valid")
+
+var extensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// Match menentukan unggahan yang dicakup kebijakan. Setiap daftar yang diisi
+// harus cocok (salah satu nilainya); daftar kosong cocok dengan apa pun,
+// sehingga kebijakan tanpa Match berlaku untuk semua unggahan.
+type Match struct {
+	Purposes []string `json:"purposes,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Policy adalah satu kebijakan upload. MaxSizeMB, AllowedMimeTypes dan
+// Sanitize yang kosong memakai pengaturan global; AllowedExtensions kosong
+// mengizinkan semua ekstensi.
+type Policy struct {
+	Name                 string   `json:"name"`
+	Description          string   `json:"description,omitempty"`
+	Match                Match    `json:"match"`
+	MaxSizeMB            int      `json:"max_size_mb,omitempty"`
+	AllowedMimeTypes     []string `json:"allowed_mime_types,omitempty"`
+	AllowedExtensions    []string `json:"allowed_extensions,omitempty"`
+	RequiredTags         []string `json:"required_tags,omitempty"`
+	DefaultRetentionDays int      `json:"default_retention_days,omitempty"`
+	// Sanitize mengaktifkan atau menonaktifkan pembersihan metadata file
+	// (EXIF, properti dokumen) untuk unggahan yang cocok.
+	Sanitize *bool `json:"sanitize,omitempty"`
+}
+
+// Document adalah format kebijakan upload yang disimpan di Consul KV.
+type Document struct {
+	Policies []Policy `json:"policies"`
+}
+
+// ParseDocument membaca dan memvalidasi dokumen kebijakan berformat JSON.
+// Ekstensi dinormalisasi menjadi huruf kecil dengan awalan titik.
+func ParseDocument(data []byte) ([]Policy, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	for i := range doc.Policies {
+		for j, ext := range doc.Policies[i].AllowedExtensions {
+			ext = strings.ToLower(strings.TrimSpace(ext))
+			if ext != "" && !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			doc.Policies[i].AllowedExtensions[j] = ext
+		}
+	}
+	if err := Validate(doc.Policies); err != nil {
+		return nil, err
+	}
+	return doc.Policies, nil
+}
+
+// Validate memeriksa nama kebijakan unik dan nilai batasnya masuk akal.
+func Validate(policies []Policy) error {
+	var errs []error
+	seen := make(map[string]bool, len(policies))
+	for i, p := range policies {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("kebijakan ke-%d tidak memiliki nama", i+1))
+		case p.Name == DefaultPolicyName:
+			errs = append(errs, fmt.Errorf("nama kebijakan %q dicadangkan untuk batas global", p.Name))
+		case seen[p.Name]:
+			errs = append(errs, fmt.Errorf("nama kebijakan %q dipakai lebih dari sekali", p.Name))
+		}
+		seen[p.Name] = true
+		if p.MaxSizeMB < 0 {
+			errs = append(errs, fmt.Errorf("kebijakan %q: max_size_mb tidak boleh negatif", p.Name))
+		}
+		if p.DefaultRetentionDays < 0 {
+			errs = append(errs, fmt.Errorf("kebijakan %q: default_retention_days tidak boleh negatif", p.Name))
+		}
+		for _, ext := range p.AllowedExtensions {
+			if !extensionPattern.MatchString(ext) {
+				errs = append(errs, fmt.Errorf("kebijakan %q: ekstensi %q tidak valid", p.Name, ext))
+			}
+		}
+		for _, tag := range p.RequiredTags {
+			if strings.TrimSpace(tag) == "" {
+				errs = append(errs, fmt.Errorf("kebijakan %q: required_tags berisi tag kosong", p.Name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidPolicy, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Request adalah unggahan yang sedang dievaluasi.
+type Request struct {
+	Purpose string
+	Role    string
+	Tags    []string
+}
+
+// Matches melaporkan apakah kebijakan mencakup req.
+func (p *Policy) Matches(req Request) bool {
+	if len(p.Match.Purposes) > 0 && !slices.Contains(p.Match.Purposes, req.Purpose) {
+		return false
+	}
+	if len(p.Match.Roles) > 0 && !slices.Contains(p.Match.Roles, req.Role) {
+		return false
+	}
+	if len(p.Match.Tags) > 0 && !slices.ContainsFunc(req.Tags, func(tag string) bool { return slices.Contains(p.Match.Tags, tag) }) {
+		return false
+	}
+	return true
+}
+
+// AppliesToRole melaporkan apakah kebijakan dapat dipakai oleh peran role,
+// tanpa memperhatikan purpose dan tag.
+func (p *Policy) AppliesToRole(role string) bool {
+	return len(p.Match.Roles) == 0 || slices.Contains(p.Match.Roles, role)
+}
+
+// Select mengembalikan kebijakan pertama (sesuai urutan dokumen) yang
+// mencakup req, atau nil jika tidak ada.
+func Select(policies []Policy, req Request) *Policy {
+	for i := range policies {
+		if policies[i].Matches(req) {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// MaxSizeBytes mengembalikan batas ukuran kebijakan, atau fallback jika
+// kebijakan tidak menentukannya.
+func (p *Policy) MaxSizeBytes(fallback int64) int64 {
+	if p.MaxSizeMB == 0 {
+		return fallback
+	}
+	return int64(p.MaxSizeMB) * 1024 * 1024
+}
+
+// AllowsExtension melaporkan apakah ekstensi ext (dengan titik, huruf kecil)
+// diizinkan.
+func (p *Policy) AllowsExtension(ext string) bool {
+	return len(p.AllowedExtensions) == 0 || slices.Contains(p.AllowedExtensions, ext)
+}
+
+// MissingTags mengembalikan tag wajib yang tidak ada di tags.
+func (p *Policy) MissingTags(tags []string) []string {
+	var missing []string
+	for _, required := range p.RequiredTags {
+		if !slices.Contains(tags, required) {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+// SanitizeEnabled melaporkan apakah metadata file perlu dibersihkan, atau
+// fallback jika kebijakan tidak menentukannya.
+func (p *Policy) SanitizeEnabled(fallback bool) bool {
+	if p.Sanitize == nil {
+		return fallback
+	}
+	return *p.Sanitize
+}
+
+// RetainUntil menghitung akhir masa retensi bawaan untuk file yang diunggah
+// pada now, atau nil jika kebijakan tidak menentukan retensi.
+func (p *Policy) RetainUntil(now time.Time) *time.Time {
+	if p.DefaultRetentionDays == 0 {
+		return nil
+	}
+	until := now.AddDate(0, 0, p.DefaultRetentionDays)
+	return &until
+}
+
+type requestContextKey struct{}
+
+// WithRequest menyimpan purpose dan peran pengunggah di ctx agar dapat dibaca
+// layer service tanpa mengubah signature upload. Tag diisi oleh service.
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, req)
+}
+
+// RequestFromContext mengembalikan Request yang disimpan WithRequest.
+func RequestFromContext(ctx context.Context) Request {
+	req, _ := ctx.Value(requestContextKey{}).(Request)
+	return req
+}
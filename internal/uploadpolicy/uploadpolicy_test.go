@@ -0,0 +1,102 @@
+package uploadpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDocument(t *testing.T) {
+	testCases := []struct {
+		name          string
+		document      string
+		expectedError string
+	}{
+		{name: "Valid document", document: `{"policies":[{"name":"hr-scan","match":{"purposes":["hr-scan"]},"max_size_mb":50,"allowed_extensions":["PDF",".Tif"]}]}`},
+		{name: "Malformed JSON", document: `{"policies":[`, expectedError: "unexpected end"},
+		{name: "Missing name", document: `{"policies":[{"max_size_mb":1}]}`, expectedError: "tidak memiliki nama"},
+		{name: "Reserved name", document: `{"policies":[{"name":"default"}]}`, expectedError: "dicadangkan"},
+		{name: "Duplicate name", document: `{"policies":[{"name":"a"},{"name":"a"}]}`, expectedError: "lebih dari sekali"},
+		{name: "Negative size", document: `{"policies":[{"name":"a","max_size_mb":-1}]}`, expectedError: "max_size_mb"},
+		{name: "Negative retention", document: `{"policies":[{"name":"a","default_retention_days":-1}]}`, expectedError: "default_retention_days"},
+		{name: "Invalid extension", document: `{"policies":[{"name":"a","allowed_extensions":["tar.gz"]}]}`, expectedError: "ekstensi"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := ParseDocument([]byte(tc.document))
+			if tc.expectedError != "" {
+				require.ErrorIs(t, err, ErrInvalidPolicy)
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, policies, 1)
+			assert.Equal(t, []string{".pdf", ".tif"}, policies[0].AllowedExtensions, "Ekstensi dinormalisasi")
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	policies := []Policy{
+		{Name: "hr-scan", Match: Match{Purposes: []string{"hr-scan"}, Roles: []string{"hr", "admin"}}},
+		{Name: "avatar", Match: Match{Tags: []string{"avatar", "profile"}}},
+		{Name: "fallback"},
+	}
+
+	testCases := []struct {
+		name     string
+		request  Request
+		expected string
+	}{
+		{name: "Purpose and role match", request: Request{Purpose: "hr-scan", Role: "hr"}, expected: "hr-scan"},
+		{name: "Purpose with wrong role falls through", request: Request{Purpose: "hr-scan", Role: "staff"}, expected: "fallback"},
+		{name: "Any matching tag", request: Request{Tags: []string{"misc", "profile"}}, expected: "avatar"},
+		{name: "Catch-all policy", request: Request{Role: "staff"}, expected: "fallback"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := Select(policies, tc.request)
+			require.NotNil(t, p)
+			assert.Equal(t, tc.expected, p.Name)
+		})
+	}
+
+	assert.Nil(t, Select(policies[:2], Request{Role: "staff"}), "Tanpa kebijakan catch-all tidak ada yang cocok")
+}
+
+func TestPolicy_Limits(t *testing.T) {
+	p := Policy{Name: "hr-scan", MaxSizeMB: 50, AllowedExtensions: []string{".pdf"}, RequiredTags: []string{"hr", "scan"}, DefaultRetentionDays: 10}
+
+	assert.Equal(t, int64(50*1024*1024), p.MaxSizeBytes(1024))
+	assert.Equal(t, int64(1024), (&Policy{}).MaxSizeBytes(1024), "Tanpa max_size_mb memakai batas global")
+	assert.True(t, p.AllowsExtension(".pdf"))
+	assert.False(t, p.AllowsExtension(".exe"))
+	assert.True(t, (&Policy{}).AllowsExtension(".exe"), "Tanpa daftar ekstensi semua diizinkan")
+	assert.Equal(t, []string{"scan"}, p.MissingTags([]string{"hr"}))
+	assert.Empty(t, p.MissingTags([]string{"scan", "hr", "extra"}))
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NotNil(t, p.RetainUntil(now))
+	assert.Equal(t, time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), *p.RetainUntil(now))
+	assert.Nil(t, (&Policy{}).RetainUntil(now))
+}
+
+func TestPolicy_SanitizeEnabled(t *testing.T) {
+	policies, err := ParseDocument([]byte(`{"policies":[{"name":"staff-photo","sanitize":true},{"name":"raw","sanitize":false},{"name":"docs"}]}`))
+	require.NoError(t, err)
+
+	assert.True(t, policies[0].SanitizeEnabled(false))
+	assert.False(t, policies[1].SanitizeEnabled(true))
+	assert.True(t, policies[2].SanitizeEnabled(true), "Tanpa sanitize memakai pengaturan global")
+	assert.False(t, policies[2].SanitizeEnabled(false))
+}
+
+func TestRequestContext(t *testing.T) {
+	assert.Equal(t, Request{}, RequestFromContext(context.Background()))
+	ctx := WithRequest(context.Background(), Request{Purpose: "avatar", Role: "staff"})
+	assert.Equal(t, Request{Purpose: "avatar", Role: "staff"}, RequestFromContext(ctx))
+}
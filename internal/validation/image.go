@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+var (
+	pngTrailer  = []byte{0, 0, 0, 0, 'I', 'E', 'N', 'D', 0xAE, 0x42, 0x60, 0x82}
+	jpegTrailer = []byte{0xFF, 0xD9}
+	gifTrailer  = []byte{0x3B}
+)
+
+func validatePNG(content io.ReaderAt, size int64, opts Options) error {
+	return validateImage(content, size, opts, png.DecodeConfig, png.Decode, trailerIf(opts.RejectImageTrailingData, pngTrailer))
+}
+
+// validateJPEG hanya memeriksa penanda akhir jika RejectJPEGTrailingData
+// aktif: kamera dan editor sering menambahkan padding, gambar MPF, atau
+// metadata vendor setelah EOI pada JPEG yang sah.
+func validateJPEG(content io.ReaderAt, size int64, opts Options) error {
+	return validateImage(content, size, opts, jpeg.DecodeConfig, jpeg.Decode, trailerIf(opts.RejectJPEGTrailingData, jpegTrailer))
+}
+
+func validateGIF(content io.ReaderAt, size int64, opts Options) error {
+	return validateImage(content, size, opts, gif.DecodeConfig, gif.Decode, trailerIf(opts.RejectImageTrailingData, gifTrailer))
+}
+
+func trailerIf(enabled bool, trailer []byte) []byte {
+	if !enabled {
+		return nil
+	}
+	return trailer
+}
+
+// validateImage membaca dimensi gambar dari header dan menolaknya sebelum
+// didekode jika melebihi batas, lalu mendekode seluruh gambar agar data
+// piksel yang rusak atau disisipi ikut terdeteksi. trailer nil berarti data
+// setelah penanda akhir tidak diperiksa.
+func validateImage(content io.ReaderAt, size int64, opts Options,
+	decodeConfig func(io.Reader) (image.Config, error), decode func(io.Reader) (image.Image, error), trailer []byte,
+) error {
+	config, err := decodeConfig(io.NewSectionReader(content, 0, size))
+	if err != nil {
+		return Errorf(CodeImageInvalid, "image header cannot be decoded: %v", err)
+	}
+	if err := checkImageDimensions(config.Width, config.Height, opts); err != nil {
+		return err
+	}
+
+	if _, err := decode(io.NewSectionReader(content, 0, size)); err != nil {
+		return Errorf(CodeImageInvalid, "image data cannot be decoded: %v", err)
+	}
+
+	if trailer != nil {
+		tail := make([]byte, len(trailer))
+		if size < int64(len(tail)) {
+			return Errorf(CodeImageTrailingData, "image is truncated")
+		}
+		if _, err := content.ReadAt(tail, size-int64(len(tail))); err != nil && err != io.EOF {
+			return err
+		}
+		if !bytes.Equal(tail, trailer) {
+			return Errorf(CodeImageTrailingData, "image contains data after its end marker")
+		}
+	}
+	return nil
+}
+
+// checkImageDimensions menolak gambar yang melebihi batas dimensi atau jumlah
+// piksel sebelum datanya didekode.
+func checkImageDimensions(width, height int, opts Options) error {
+	if opts.MaxImageDimension > 0 && (width > opts.MaxImageDimension || height > opts.MaxImageDimension) {
+		return Errorf(CodeImageTooLarge, "image dimensions %dx%d exceed the limit of %d pixels per side", width, height, opts.MaxImageDimension)
+	}
+	if opts.MaxImagePixels > 0 && int64(width)*int64(height) > opts.MaxImagePixels {
+		return Errorf(CodeImageTooLarge, "image dimensions %dx%d exceed the limit of %d pixels", width, height, opts.MaxImagePixels)
+	}
+	return nil
+}
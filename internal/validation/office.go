@@ -0,0 +1,27 @@
+package validation
+
+import (
+	"io"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/ooxml"
+)
+
+// validateOOXML memastikan dokumen Office (DOCX, XLSX, PPTX) adalah arsip ZIP
+// yang dapat dibaca dan, jika dikonfigurasi, tidak memuat makro VBA.
+func validateOOXML(content io.ReaderAt, size int64, opts Options) error {
+	pkg, err := ooxml.Open(content, size)
+	if err != nil {
+		return Errorf(CodeOfficeMalformed, "office document is not a valid archive: %v", err)
+	}
+	if !opts.RejectOfficeMacros {
+		return nil
+	}
+	macros, err := pkg.HasMacros()
+	if err != nil {
+		return Errorf(CodeOfficeMalformed, "office document content types cannot be read: %v", err)
+	}
+	if macros {
+		return Errorf(CodeOfficeMacroDetected, "office document contains macros")
+	}
+	return nil
+}
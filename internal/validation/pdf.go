@@ -0,0 +1,156 @@
+package validation
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/pdf"
+)
+
+const (
+	// pdfEdgeWindow adalah jarak dari awal file tempat header %PDF- boleh
+	// berada, dan dari akhir file tempat startxref dan %%EOF harus berada.
+	pdfEdgeWindow = 1024
+	// maxPDFInflateBytes membatasi total isi object stream terkompresi yang
+	// diperiksa per dokumen. PDF yang melebihinya ditolak karena tidak dapat
+	// dipastikan bebas JavaScript.
+	maxPDFInflateBytes = 256 << 20
+)
+
+// pdfFindings adalah nama PDF berisiko yang ditemukan di dokumen.
+type pdfFindings struct {
+	javaScript bool
+	launch     bool
+	encrypt    bool
+}
+
+func (f *pdfFindings) merge(other pdfFindings) {
+	f.javaScript = f.javaScript || other.javaScript
+	f.launch = f.launch || other.launch
+	f.encrypt = f.encrypt || other.encrypt
+}
+
+// validatePDF memeriksa kerangka dokumen (header, startxref, %%EOF di akhir
+// file sehingga tidak ada data lain yang ditempelkan) lalu memindai seluruh
+// nama PDF, termasuk di dalam object stream terkompresi, untuk aksi
+// JavaScript, Launch dan enkripsi.
+func validatePDF(content io.ReaderAt, size int64, opts Options) error {
+	head := make([]byte, min(size, pdfEdgeWindow))
+	if _, err := content.ReadAt(head, 0); err != nil && err != io.EOF {
+		return err
+	}
+	if !bytes.Contains(head, []byte("%PDF-")) {
+		return Errorf(CodePDFMalformed, "PDF header not found")
+	}
+	tail := make([]byte, min(size, pdfEdgeWindow))
+	if _, err := content.ReadAt(tail, size-int64(len(tail))); err != nil && err != io.EOF {
+		return err
+	}
+	if !bytes.Contains(tail, []byte("startxref")) || !bytes.Contains(tail, []byte("%%EOF")) {
+		return Errorf(CodePDFMalformed, "PDF trailer not found at end of file")
+	}
+
+	budget := int64(maxPDFInflateBytes)
+	found, err := scanPDF(io.NewSectionReader(content, 0, size), &budget, true)
+	if err != nil {
+		return err
+	}
+	switch {
+	case opts.RejectPDFEncryption && found.encrypt:
+		return Errorf(CodePDFEncrypted, "encrypted PDF files are not allowed")
+	case opts.RejectPDFJavaScript && found.javaScript:
+		return Errorf(CodePDFJavaScript, "PDF contains JavaScript")
+	case opts.RejectPDFLaunchActions && found.launch:
+		return Errorf(CodePDFLaunchAction, "PDF contains a launch action")
+	}
+	return nil
+}
+
+// scanPDF memindai token PDF dari r. Jika streams true, isi object stream
+// (/Type /ObjStm) ikut dipindai, didekompresi lebih dulu jika berformat zlib
+// selama budget masih ada. Stream lain (gambar, font, konten halaman) hanya
+// dilewati karena pembaca PDF tidak mengambil kamus objek dari sana.
+func scanPDF(r io.Reader, budget *int64, streams bool) (pdfFindings, error) {
+	var (
+		found        pdfFindings
+		objectStream bool
+	)
+	lexer := pdf.NewLexer(r)
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			return found, nil
+		}
+		if errors.Is(err, pdf.ErrMalformed) {
+			return found, Errorf(CodePDFMalformed, "PDF structure cannot be read: %v", err)
+		}
+		if err != nil {
+			return found, err
+		}
+		switch {
+		case token.Kind == pdf.TokenName:
+			switch token.Text {
+			case "JavaScript", "JS":
+				found.javaScript = true
+			case "Launch":
+				found.launch = true
+			case "Encrypt":
+				found.encrypt = true
+			case "ObjStm":
+				objectStream = true
+			}
+		case token.Kind == pdf.TokenKeyword && token.Text == "obj":
+			objectStream = false
+		case token.Kind == pdf.TokenStream:
+			if streams && objectStream {
+				nested, err := scanObjectStream(lexer.StreamData(), budget)
+				if err != nil {
+					return found, err
+				}
+				found.merge(nested)
+			}
+			objectStream = false
+		}
+	}
+}
+
+// scanObjectStream memindai isi satu object stream. Error sintaks di dalamnya
+// diabaikan karena data terkompresi dapat terpotong oleh budget.
+func scanObjectStream(data io.Reader, budget *int64) (pdfFindings, error) {
+	content, compressed := pdf.Inflate(data)
+	if !compressed {
+		found, _ := scanPDF(content, budget, false)
+		return found, nil
+	}
+	inflated := &countingLimitReader{r: content, remaining: budget}
+	found, _ := scanPDF(inflated, budget, false)
+	if inflated.exceeded {
+		return found, Errorf(CodePDFMalformed, "PDF compressed content exceeds the inspection limit")
+	}
+	return found, nil
+}
+
+// countingLimitReader membaca dari r selama *remaining masih positif dan
+// mencatat jika r masih memiliki data setelah batas itu habis.
+type countingLimitReader struct {
+	r         io.Reader
+	remaining *int64
+	exceeded  bool
+}
+
+func (c *countingLimitReader) Read(p []byte) (int, error) {
+	if *c.remaining <= 0 {
+		var probe [1]byte
+		if n, _ := io.ReadFull(c.r, probe[:]); n > 0 {
+			c.exceeded = true
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > *c.remaining {
+		p = p[:*c.remaining]
+	}
+	n, err := c.r.Read(p)
+	*c.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF menyusun PDF minimal dengan objects sebagai isi di antara header
+// dan trailer.
+func buildPDF(objects string, trailer string) []byte {
+	return []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n" + objects + "\ntrailer\n<< /Root 1 0 R " + trailer + ">>\nstartxref\n9\n%%EOF\n")
+}
+
+// flateStream membuat objek stream terkompresi zlib dengan kamus dict.
+func flateStream(t *testing.T, dict, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return fmt.Sprintf("5 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\r\n%s\nendstream\nendobj\n", dict, buf.Len(), buf.String())
+}
+
+func TestValidatePDF(t *testing.T) {
+	catalog := "1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n"
+	opts := DefaultOptions()
+
+	testCases := []struct {
+		name         string
+		content      []byte
+		opts         Options
+		expectedCode Code
+	}{
+		{name: "Plain document", content: buildPDF(catalog, ""), opts: opts},
+		{name: "JavaScript action", content: buildPDF(catalog+"3 0 obj\n<< /S /JavaScript /JS (app.alert\\(1\\)) >>\nendobj\n", ""), opts: opts, expectedCode: CodePDFJavaScript},
+		{name: "Hex-escaped JavaScript name", content: buildPDF(catalog+"3 0 obj\n<< /S /J#61vaScript >>\nendobj\n", ""), opts: opts, expectedCode: CodePDFJavaScript},
+		{name: "JavaScript allowed when disabled", content: buildPDF(catalog+"3 0 obj\n<< /S /JavaScript >>\nendobj\n", ""), opts: Options{}},
+		{name: "Name inside string literal is ignored", content: buildPDF(catalog+"3 0 obj\n<< /Title (about /JavaScript \\) and (nested)) >>\nendobj\n", ""), opts: opts},
+		{name: "Name inside comment is ignored", content: buildPDF(catalog+"% /JavaScript\n", ""), opts: opts},
+		{name: "Launch action", content: buildPDF(catalog+"3 0 obj\n<< /S /Launch /F (cmd.exe) >>\nendobj\n", ""), opts: opts, expectedCode: CodePDFLaunchAction},
+		{name: "Encrypted document", content: buildPDF(catalog, "/Encrypt 9 0 R "), opts: Options{RejectPDFEncryption: true}, expectedCode: CodePDFEncrypted},
+		{name: "Encryption allowed by default", content: buildPDF(catalog, "/Encrypt 9 0 R "), opts: opts},
+		{name: "JavaScript hidden in compressed object stream", content: buildPDF(catalog+flateStream(t, "/Type /ObjStm /N 1 /First 4", "3 0 << /S /JavaScript /JS 4 0 R >>"), ""), opts: opts, expectedCode: CodePDFJavaScript},
+		{name: "Uncompressed object stream", content: buildPDF(catalog+"5 0 obj\n<< /Type /ObjStm /Length 20 >>\nstream\n3 0 << /S /Launch >>\nendstream\nendobj\n", ""), opts: opts, expectedCode: CodePDFLaunchAction},
+		{name: "Page content stream is not parsed for names", content: buildPDF(catalog+flateStream(t, "", "BT /JS 12 Tf ET"), ""), opts: opts},
+		{name: "Binary stream does not break scanning", content: buildPDF(catalog+"4 0 obj\n<< /Length 4 >>\nstream\n((((\nendstream\nendobj\n3 0 obj\n<< /S /JavaScript >>\nendobj\n", ""), opts: opts, expectedCode: CodePDFJavaScript},
+		{name: "Missing header", content: []byte(strings.Repeat(" ", 2048) + string(buildPDF(catalog, ""))), opts: opts, expectedCode: CodePDFMalformed},
+		{name: "Data appended after trailer", content: append(buildPDF(catalog, ""), bytes.Repeat([]byte("PK"), 1024)...), opts: opts, expectedCode: CodePDFMalformed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePDF(bytes.NewReader(tc.content), int64(len(tc.content)), tc.opts)
+			if tc.expectedCode == "" {
+				require.NoError(t, err)
+				return
+			}
+			validationErr, ok := AsError(err)
+			require.True(t, ok, "error: %v", err)
+			assert.Equal(t, tc.expectedCode, validationErr.Code)
+		})
+	}
+}
+
+func TestValidatePDF_ThroughPipeline(t *testing.T) {
+	content := buildPDF("1 0 obj\n<< /OpenAction << /S /JavaScript >> >>\nendobj\n", "")
+
+	err := validate(content, "invoice.pdf", DefaultOptions())
+	validationErr, ok := AsError(err)
+	require.True(t, ok, "error: %v", err)
+	assert.Equal(t, CodePDFJavaScript, validationErr.Code)
+
+	err = validate(content, "invoice.png", DefaultOptions())
+	validationErr, ok = AsError(err)
+	require.True(t, ok, "error: %v", err)
+	assert.Equal(t, CodeExtensionMismatch, validationErr.Code, "Ekstensi diperiksa sebelum isi")
+}
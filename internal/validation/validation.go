@@ -0,0 +1,179 @@
+// Package validation memeriksa isi file yang diunggah lebih dalam daripada
+// deteksi magic bytes: gambar didekode utuh, struktur PDF dan arsip Office
+// diperiksa, dan ekstensi nama file dicocokkan dengan isinya. Setiap
+// penolakan dikembalikan sebagai *Error dengan Code yang dapat dipetakan ke
+// status HTTP, gRPC, WebDAV maupun S3.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// Code mengelompokkan alasan penolakan unggahan.
+type Code string
+
+const (
+	CodeFileTooLarge        Code = "file_too_large"
+	CodeMimeTypeNotAllowed  Code = "mime_type_not_allowed"
+	CodeUploadPolicy        Code = "upload_policy_violation"
+	CodeExtensionMismatch   Code = "extension_mismatch"
+	CodeImageInvalid        Code = "image_invalid"
+	CodeImageTooLarge       Code = "image_too_large"
+	CodeImageTrailingData   Code = "image_trailing_data"
+	CodePDFMalformed        Code = "pdf_malformed"
+	CodePDFJavaScript       Code = "pdf_javascript"
+	CodePDFLaunchAction     Code = "pdf_launch_action"
+	CodePDFEncrypted        Code = "pdf_encrypted"
+	CodeOfficeMalformed     Code = "office_malformed"
+	CodeOfficeMacroDetected Code = "office_macro"
+	// CodeSanitizationFailed menandai file yang metadatanya wajib dibersihkan
+	// tetapi strukturnya tidak memungkinkan.
+	CodeSanitizationFailed Code = "sanitization_failed"
+)
+
+// Error adalah penolakan unggahan karena isinya tidak memenuhi aturan
+// validasi. Pesannya aman ditampilkan ke klien.
+type Error struct {
+	Code    Code
+	Message string
+	// Err adalah penyebab atau sentinel yang dibungkus, jika ada.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errorf membuat *Error dengan pesan terformat. Verb %w ikut dibungkus seperti
+// pada fmt.Errorf.
+func Errorf(code Code, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Code: code, Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// AsError mengembalikan *Error di dalam rantai err, jika ada.
+func AsError(err error) (*Error, bool) {
+	var validationErr *Error
+	if errors.As(err, &validationErr) {
+		return validationErr, true
+	}
+	return nil, false
+}
+
+// Options mengatur pemeriksaan yang dapat dinonaktifkan atau dibatasi lewat
+// konfigurasi. Nilai batas 0 berarti tanpa batas.
+type Options struct {
+	// MaxImagePixels membatasi lebar×tinggi gambar sebelum didekode, untuk
+	// menangkal decompression bomb.
+	MaxImagePixels    int64
+	MaxImageDimension int
+	// RejectImageTrailingData menolak PNG, GIF, dan WebP dengan data setelah
+	// penanda akhir formatnya, pola umum file polyglot.
+	RejectImageTrailingData bool
+	// RejectJPEGTrailingData menerapkan pemeriksaan yang sama pada JPEG. Tidak
+	// aktif secara bawaan karena banyak JPEG sah membawa data setelah EOI.
+	RejectJPEGTrailingData  bool
+	RejectPDFJavaScript     bool
+	RejectPDFLaunchActions  bool
+	RejectPDFEncryption     bool
+	RejectOfficeMacros      bool
+	RejectExtensionMismatch bool
+}
+
+// DefaultOptions mengembalikan pengaturan bawaan: semua pemeriksaan aktif
+// kecuali penolakan PDF terenkripsi.
+func DefaultOptions() Options {
+	return Options{
+		MaxImagePixels:          40_000_000,
+		MaxImageDimension:       16384,
+		RejectImageTrailingData: true,
+		RejectPDFJavaScript:     true,
+		RejectPDFLaunchActions:  true,
+		RejectOfficeMacros:      true,
+		RejectExtensionMismatch: true,
+	}
+}
+
+// Validator memeriksa isi file dengan tipe MIME tertentu.
+type Validator func(content io.ReaderAt, size int64, opts Options) error
+
+// validators dipilih berdasarkan tipe MIME hasil deteksi atau leluhurnya.
+// Tipe tanpa validator hanya diperiksa ekstensinya.
+var validators = []struct {
+	mimeType string
+	validate Validator
+}{
+	{"image/png", validatePNG},
+	{"image/jpeg", validateJPEG},
+	{"image/gif", validateGIF},
+	{"image/webp", validateWebP},
+	{"application/pdf", validatePDF},
+	{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", validateOOXML},
+	{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", validateOOXML},
+	{"application/vnd.openxmlformats-officedocument.presentationml.presentation", validateOOXML},
+}
+
+// extensionTypes memetakan ekstensi ke tipe MIME yang wajib dimiliki isinya.
+// Hanya format yang deteksinya andal yang dicantumkan; teks terstruktur
+// seperti CSV atau JSON sering terdeteksi sebagai text/plain.
+var extensionTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".pdf":  "application/pdf",
+	".zip":  "application/zip",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".txt":  "text/plain",
+}
+
+// Validate menjalankan pemeriksaan ekstensi lalu validator yang sesuai dengan
+// tipe MIME hasil deteksi. content dibaca dari offset 0 sampai size.
+func Validate(content io.ReaderAt, size int64, filename string, detected *mimetype.MIME, opts Options) error {
+	if opts.RejectExtensionMismatch {
+		if err := checkExtension(filename, detected); err != nil {
+			return err
+		}
+	}
+	for _, v := range validators {
+		if isA(detected, v.mimeType) {
+			if err := v.validate(content, size, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkExtension(filename string, detected *mimetype.MIME) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+	expected, ok := extensionTypes[ext]
+	if !ok || isA(detected, expected) {
+		return nil
+	}
+	return Errorf(CodeExtensionMismatch, "file extension '%s' does not match its content (%s)", ext, detected.String())
+}
+
+// isA melaporkan apakah detected adalah mimeType atau turunannya, mis. DOCX
+// adalah application/zip dan CSV adalah text/plain.
+func isA(detected *mimetype.MIME, mimeType string) bool {
+	for m := detected; m != nil; m = m.Parent() {
+		if m.Is(mimeType) {
+			return true
+		}
+	}
+	return false
+}
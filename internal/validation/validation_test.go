@@ -0,0 +1,175 @@
+package validation
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+// webpFile membangun container RIFF/WEBP dari chunk yang sudah dikodekan.
+func webpFile(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+	return append(binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body))), body...)
+}
+
+func riffChunk(kind string, data []byte) []byte {
+	chunk := binary.LittleEndian.AppendUint32([]byte(kind), uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// vp8lChunk membuat chunk VP8L dengan header dimensi yang sah.
+func vp8lChunk(width, height int) []byte {
+	bits := uint32(width-1) | uint32(height-1)<<14
+	return riffChunk("VP8L", append(binary.LittleEndian.AppendUint32([]byte{0x2f}, bits), 0, 0, 0))
+}
+
+func zipBytes(t *testing.T, entries map[string]string, order ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range order {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(entries[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func docx(t *testing.T, extra ...string) []byte {
+	entries := map[string]string{
+		"[Content_Types].xml": `<Types><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`,
+		"_rels/.rels":         `<Relationships/>`,
+		"word/document.xml":   `<w:document/>`,
+	}
+	order := []string{"[Content_Types].xml", "_rels/.rels", "word/document.xml"}
+	for _, name := range extra {
+		entries[name] = "binary"
+		order = append(order, name)
+	}
+	return zipBytes(t, entries, order...)
+}
+
+func validate(content []byte, filename string, opts Options) error {
+	return Validate(bytes.NewReader(content), int64(len(content)), filename, mimetype.Detect(content), opts)
+}
+
+func TestValidate(t *testing.T) {
+	pngData := encodePNG(t, 2, 2)
+	truncated := pngData[:len(pngData)-20]
+	polyglot := append(append([]byte{}, pngData...), zipBytes(t, map[string]string{"payload.php": "<?php ?>"}, "payload.php")...)
+	macroDocx := docx(t, "word/vbaProject.bin")
+	jpegData := encodeJPEG(t, 2, 2)
+	jpegPadded := append(append([]byte{}, jpegData...), 0, 0, 'M', 'P', 'F', 0)
+	webpData := webpFile(vp8lChunk(2, 2))
+	webpOverflow := append([]byte{}, webpData...)
+	binary.LittleEndian.PutUint32(webpOverflow[16:20], 1000)
+
+	testCases := []struct {
+		name         string
+		content      []byte
+		filename     string
+		opts         Options
+		expectedCode Code
+	}{
+		{name: "Valid PNG", content: pngData, filename: "photo.PNG", opts: DefaultOptions()},
+		{name: "Extension does not match content", content: pngData, filename: "photo.jpg", opts: DefaultOptions(), expectedCode: CodeExtensionMismatch},
+		{name: "Binary content disguised as text", content: pngData, filename: "notes.txt", opts: DefaultOptions(), expectedCode: CodeExtensionMismatch},
+		{name: "Extension mismatch allowed when disabled", content: pngData, filename: "photo.jpg", opts: Options{}},
+		{name: "Unknown extension is not checked", content: pngData, filename: "photo.bin", opts: DefaultOptions()},
+		{name: "Text with structured content", content: []byte("a,b,c\n1,2,3\n4,5,6\n"), filename: "data.txt", opts: DefaultOptions()},
+		{name: "Truncated PNG", content: truncated, filename: "photo.png", opts: Options{}, expectedCode: CodeImageInvalid},
+		{name: "Too many pixels", content: pngData, filename: "photo.png", opts: Options{MaxImagePixels: 3}, expectedCode: CodeImageTooLarge},
+		{name: "Dimension too large", content: pngData, filename: "photo.png", opts: Options{MaxImageDimension: 1}, expectedCode: CodeImageTooLarge},
+		{name: "Data appended after image", content: polyglot, filename: "photo.png", opts: DefaultOptions(), expectedCode: CodeImageTrailingData},
+		{name: "Appended data allowed when disabled", content: polyglot, filename: "photo.png", opts: Options{}},
+		{name: "JPEG with bytes after EOI is accepted by default", content: jpegPadded, filename: "photo.jpg", opts: DefaultOptions()},
+		{name: "JPEG trailer check is opt-in", content: jpegPadded, filename: "photo.jpg", opts: Options{RejectJPEGTrailingData: true}, expectedCode: CodeImageTrailingData},
+		{name: "Valid JPEG with strict trailer", content: jpegData, filename: "photo.jpg", opts: Options{RejectJPEGTrailingData: true}},
+		{name: "Valid WebP", content: webpData, filename: "photo.webp", opts: DefaultOptions()},
+		{name: "Valid extended WebP", content: webpFile(riffChunk("VP8X", []byte{0, 0, 0, 0, 1, 0, 0, 1, 0, 0}), vp8lChunk(2, 2)), filename: "photo.webp", opts: DefaultOptions()},
+		{name: "WebP chunk exceeds RIFF size", content: webpOverflow, filename: "photo.webp", opts: DefaultOptions(), expectedCode: CodeImageInvalid},
+		{name: "WebP RIFF size exceeds file", content: webpData[:len(webpData)-2], filename: "photo.webp", opts: DefaultOptions(), expectedCode: CodeImageInvalid},
+		{name: "WebP without image chunk", content: webpFile(riffChunk("EXIF", []byte("exif"))), filename: "photo.webp", opts: DefaultOptions(), expectedCode: CodeImageInvalid},
+		{name: "WebP dimension too large", content: webpFile(vp8lChunk(300, 2)), filename: "photo.webp", opts: Options{MaxImageDimension: 256}, expectedCode: CodeImageTooLarge},
+		{name: "Data appended after WebP", content: append(append([]byte{}, webpData...), "PK\x03\x04"...), filename: "photo.webp", opts: DefaultOptions(), expectedCode: CodeImageTrailingData},
+		{name: "Office document without macros", content: docx(t), filename: "report.docx", opts: DefaultOptions()},
+		{name: "Office document with macros", content: macroDocx, filename: "report.docx", opts: DefaultOptions(), expectedCode: CodeOfficeMacroDetected},
+		{name: "Office macros allowed when disabled", content: macroDocx, filename: "report.docx", opts: Options{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validate(tc.content, tc.filename, tc.opts)
+			if tc.expectedCode == "" {
+				require.NoError(t, err)
+				return
+			}
+			validationErr, ok := AsError(err)
+			require.True(t, ok, "error harus bertipe *validation.Error: %v", err)
+			assert.Equal(t, tc.expectedCode, validationErr.Code)
+		})
+	}
+}
+
+func TestValidate_OfficeMacroContentType(t *testing.T) {
+	content := zipBytes(t, map[string]string{
+		"[Content_Types].xml": `<Types><Default Extension="bin" ContentType="application/vnd.ms-office.vbaProject"/><Override PartName="/word/document.xml" ContentType="application/vnd.ms-word.document.macroEnabled.main+xml"/></Types>`,
+		"_rels/.rels":         `<Relationships/>`,
+		"word/document.xml":   `<w:document/>`,
+	}, "[Content_Types].xml", "_rels/.rels", "word/document.xml")
+
+	err := validate(content, "report.docm", DefaultOptions())
+	validationErr, ok := AsError(err)
+	require.True(t, ok, "error: %v", err)
+	assert.Equal(t, CodeOfficeMacroDetected, validationErr.Code)
+}
+
+func TestError(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	err := fmt.Errorf("upload: %w", Errorf(CodeUploadPolicy, "ditolak: %w", sentinel))
+
+	validationErr, ok := AsError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeUploadPolicy, validationErr.Code)
+	assert.Equal(t, "ditolak: sentinel", validationErr.Error())
+	assert.ErrorIs(t, err, sentinel)
+
+	_, ok = AsError(errors.New("lain"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// validateWebP memeriksa header RIFF/WEBP, memastikan setiap chunk muat di
+// dalam ukuran RIFF, lalu membaca dimensi kanvas dari chunk pertama (VP8,
+// VP8L, atau VP8X) untuk batas dimensi. Data piksel tidak didekode karena
+// pustaka standar tidak menyediakan decoder WebP.
+func validateWebP(content io.ReaderAt, size int64, opts Options) error {
+	var header [12]byte
+	if size < int64(len(header)) {
+		return Errorf(CodeImageInvalid, "webp header is truncated")
+	}
+	if _, err := content.ReadAt(header[:], 0); err != nil {
+		return err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WEBP" {
+		return Errorf(CodeImageInvalid, "webp does not start with a RIFF/WEBP header")
+	}
+	riffEnd := 8 + int64(binary.LittleEndian.Uint32(header[4:8]))
+	if riffEnd > size {
+		return Errorf(CodeImageInvalid, "webp RIFF size %d exceeds the file size %d", riffEnd-8, size)
+	}
+
+	var width, height int
+	first := ""
+	for offset := int64(len(header)); offset < riffEnd; {
+		if offset+8 > riffEnd {
+			return Errorf(CodeImageInvalid, "webp chunk header at offset %d is truncated", offset)
+		}
+		var chunkHeader [8]byte
+		if _, err := content.ReadAt(chunkHeader[:], offset); err != nil {
+			return err
+		}
+		kind := string(chunkHeader[:4])
+		length := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+		if offset+8+length > riffEnd {
+			return Errorf(CodeImageInvalid, "webp chunk %q length %d exceeds the RIFF size", kind, length)
+		}
+		if first == "" {
+			first = kind
+			data := make([]byte, min(length, 10))
+			if _, err := content.ReadAt(data, offset+8); err != nil {
+				return err
+			}
+			var ok bool
+			if width, height, ok = webpDimensions(kind, data); !ok {
+				return Errorf(CodeImageInvalid, "webp chunk %q does not describe an image", kind)
+			}
+		}
+		// Chunk berukuran ganjil diikuti satu byte padding.
+		offset += 8 + length + length%2
+	}
+	if first == "" {
+		return Errorf(CodeImageInvalid, "webp contains no chunks")
+	}
+	if err := checkImageDimensions(width, height, opts); err != nil {
+		return err
+	}
+	if opts.RejectImageTrailingData && size > riffEnd+riffEnd%2 {
+		return Errorf(CodeImageTrailingData, "image contains data after its end marker")
+	}
+	return nil
+}
+
+// webpDimensions membaca lebar dan tinggi kanvas dari awal data chunk
+// pertama sesuai spesifikasi container WebP.
+func webpDimensions(kind string, data []byte) (width, height int, ok bool) {
+	switch kind {
+	case "VP8X":
+		if len(data) < 10 {
+			return 0, 0, false
+		}
+		width = int(uint32(data[4])|uint32(data[5])<<8|uint32(data[6])<<16) + 1
+		height = int(uint32(data[7])|uint32(data[8])<<8|uint32(data[9])<<16) + 1
+		return width, height, true
+	case "VP8L":
+		if len(data) < 5 || data[0] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(data[1:5])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, true
+	case "VP8 ":
+		if len(data) < 10 || data[3] != 0x9d || data[4] != 0x01 || data[5] != 0x2a {
+			return 0, 0, false
+		}
+		return int(binary.LittleEndian.Uint16(data[6:8]) & 0x3fff), int(binary.LittleEndian.Uint16(data[8:10]) & 0x3fff), true
+	}
+	return 0, 0, false
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,68 +17,176 @@ import (
 	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/enhanced_logger"
 	"github.com/Lumina-Enterprise-Solutions/prism-common-libs/telemetry"
 	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	filev1 "github.com/Lumina-Enterprise-Solutions/prism-file-service/gen/go/prism/file/v1"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/dav"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/grpcapi"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/handler"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/integrity"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/jobs"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/metrics"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/model"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/policy"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/s3gateway"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/service"
 	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/tracing"
 	"github.com/gin-gonic/gin"
+	consulapi "github.com/hashicorp/consul/api"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log" // Import log untuk digunakan di defer
 	ginprometheus "github.com/zsais/go-gin-prometheus"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-func loadSecretsFromVault(vaultAddr, vaultToken string) (fileserviceconfig.S3Config, error) {
+// staleUploadAge adalah umur minimal unggahan multipart S3 yang belum selesai
+// sebelum dianggap tertinggal dan dibatalkan saat startup.
+const staleUploadAge = 24 * time.Hour
+
+// optionalSecretKeys hanya dibutuhkan oleh backend storage tertentu
+// (sftp, azure, gcs); validasinya dilakukan saat storage tersebut dibuat.
+var optionalSecretKeys = []string{
+	"sftp_host", "sftp_port", "sftp_user", "sftp_password", "sftp_private_key", "sftp_host_key",
+	"azure_account_name", "azure_account_key", "azure_sas_token", "azure_container",
+	"gcs_bucket", "gcs_credentials_json",
+	"s3_sse_customer_key",
+}
+
+func loadSecretsFromVault(vaultAddr, vaultToken string) (fileserviceconfig.StorageSecrets, error) {
 	vaultClient, err := client.NewVaultClient(vaultAddr, vaultToken)
 	if err != nil {
-		return fileserviceconfig.S3Config{}, fmt.Errorf("gagal membuat klien Vault: %w", err)
+		return fileserviceconfig.StorageSecrets{}, fmt.Errorf("gagal membuat klien Vault: %w", err)
 	}
 
 	secretPath := "secret/data/prism"
 	requiredSecrets := []string{"database_url", "jwt_secret_key", "s3_region", "s3_endpoint", "s3_access_key", "s3_secret_key", "s3_bucket"}
 	secretsMap, err := vaultClient.ReadMultipleSecrets(secretPath, requiredSecrets...)
 	if err != nil {
-		return fileserviceconfig.S3Config{}, err
+		return fileserviceconfig.StorageSecrets{}, err
+	}
+	for _, key := range optionalSecretKeys {
+		if value, err := vaultClient.ReadSecret(secretPath, key); err == nil {
+			secretsMap[key] = value
+		}
 	}
 
-	s3Config := fileserviceconfig.S3Config{
-		Region:    secretsMap["s3_region"],
-		Endpoint:  secretsMap["s3_endpoint"],
-		AccessKey: secretsMap["s3_access_key"],
-		SecretKey: secretsMap["s3_secret_key"],
-		Bucket:    secretsMap["s3_bucket"],
+	sftpPort := 22
+	if portStr := secretsMap["sftp_port"]; portStr != "" {
+		if sftpPort, err = strconv.Atoi(portStr); err != nil {
+			return fileserviceconfig.StorageSecrets{}, fmt.Errorf("sftp_port tidak valid: %w", err)
+		}
+	}
+
+	secrets := fileserviceconfig.StorageSecrets{
+		S3: fileserviceconfig.S3Config{
+			Region:    secretsMap["s3_region"],
+			Endpoint:  secretsMap["s3_endpoint"],
+			AccessKey: secretsMap["s3_access_key"],
+			SecretKey: secretsMap["s3_secret_key"],
+			Bucket:    secretsMap["s3_bucket"],
+			// Key sse-c adalah rahasia, jadi dibaca dari Vault, bukan Consul.
+			SSECustomerKey: secretsMap["s3_sse_customer_key"],
+		},
+		SFTP: fileserviceconfig.SFTPConfig{
+			Host:       secretsMap["sftp_host"],
+			Port:       sftpPort,
+			User:       secretsMap["sftp_user"],
+			Password:   secretsMap["sftp_password"],
+			PrivateKey: secretsMap["sftp_private_key"],
+			HostKey:    secretsMap["sftp_host_key"],
+		},
+		Azure: fileserviceconfig.AzureConfig{
+			AccountName: secretsMap["azure_account_name"],
+			AccountKey:  secretsMap["azure_account_key"],
+			SASToken:    secretsMap["azure_sas_token"],
+			Container:   secretsMap["azure_container"],
+		},
+		GCS: fileserviceconfig.GCSConfig{
+			Bucket:          secretsMap["gcs_bucket"],
+			CredentialsJSON: secretsMap["gcs_credentials_json"],
+		},
 	}
 
 	// FIX: Periksa error dari os.Setenv
 	if err := os.Setenv("DATABASE_URL", secretsMap["database_url"]); err != nil {
-		return fileserviceconfig.S3Config{}, fmt.Errorf("gagal set env var DATABASE_URL: %w", err)
+		return fileserviceconfig.StorageSecrets{}, fmt.Errorf("gagal set env var DATABASE_URL: %w", err)
 	}
 	if err := os.Setenv("JWT_SECRET_KEY", secretsMap["jwt_secret_key"]); err != nil {
-		return fileserviceconfig.S3Config{}, fmt.Errorf("gagal set env var JWT_SECRET_KEY: %w", err)
+		return fileserviceconfig.StorageSecrets{}, fmt.Errorf("gagal set env var JWT_SECRET_KEY: %w", err)
 	}
 
-	return s3Config, nil
+	return secrets, nil
 }
 
-func setupDependencies(vaultAddr, vaultToken string) (*pgxpool.Pool, fileserviceconfig.S3Config, error) {
-	s3Config, err := loadSecretsFromVault(vaultAddr, vaultToken)
+func setupDependencies(vaultAddr, vaultToken string) (*pgxpool.Pool, fileserviceconfig.StorageSecrets, error) {
+	secrets, err := loadSecretsFromVault(vaultAddr, vaultToken)
+	if err != nil {
+		return nil, fileserviceconfig.StorageSecrets{}, err
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(os.Getenv("DATABASE_URL"))
 	if err != nil {
-		return nil, fileserviceconfig.S3Config{}, err
+		return nil, fileserviceconfig.StorageSecrets{}, fmt.Errorf("gagal membaca DATABASE_URL: %w", err)
 	}
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+	dbpool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fileserviceconfig.StorageSecrets{}, fmt.Errorf("gagal membuat connection pool: %w", err)
+	}
+
+	return dbpool, secrets, nil
+}
 
-	dbpool, err := pgxpool.New(context.Background(), os.Getenv("DATABASE_URL"))
+// newConsulClient membuat klien Consul untuk CONSUL_ADDR.
+func newConsulClient() (*consulapi.Client, error) {
+	consulConfig := consulapi.DefaultConfig()
+	if consulAddr := os.Getenv("CONSUL_ADDR"); consulAddr != "" {
+		consulConfig.Address = consulAddr
+	} else {
+		consulConfig.Address = "http://consul:8500"
+	}
+	return consulapi.NewClient(consulConfig)
+}
+
+// startPolicyWatcher memuat kebijakan otorisasi dari Consul KV lalu memantau
+// perubahannya di background. Jika Consul tidak dapat dibaca, kebijakan bawaan
+// tetap dipakai sampai pemuatan berikutnya berhasil.
+func startPolicyWatcher(ctx context.Context, consulClient *consulapi.Client, engine *policy.Engine) {
+	watcher := policy.NewConsulWatcher(consulClient.KV(), policy.DefaultConsulKey, engine)
+	index, err := watcher.Load(ctx)
 	if err != nil {
-		return nil, fileserviceconfig.S3Config{}, fmt.Errorf("gagal membuat connection pool: %w", err)
+		log.Error().Err(err).Msg("Gagal memuat kebijakan otorisasi dari Consul, memakai kebijakan bawaan")
 	}
+	go watcher.Watch(ctx, index)
+}
 
-	return dbpool, s3Config, nil
+// isDevMode melaporkan apakah service dijalankan dengan argumen "dev" atau
+// PRISM_DEV_MODE=true.
+func isDevMode() bool {
+	if len(os.Args) > 1 && os.Args[1] == "dev" {
+		return true
+	}
+	devMode, _ := strconv.ParseBool(os.Getenv("PRISM_DEV_MODE"))
+	return devMode
 }
 
 func main() {
 	enhanced_logger.Init()
+	// Log yang membawa context request (log.Info().Ctx(ctx)) diberi trace_id.
+	log.Logger = log.Logger.Hook(tracing.LogHook{})
 	serviceLogger := enhanced_logger.WithService("prism-file-service")
 
+	if isDevMode() {
+		runDevMode(serviceLogger)
+		return
+	}
+
 	vaultAddr := os.Getenv("VAULT_ADDR")
 	if vaultAddr == "" {
 		vaultAddr = "http://vault:8200"
@@ -87,17 +196,23 @@ func main() {
 		vaultToken = "root-token-for-dev"
 	}
 
-	dbpool, s3Config, err := setupDependencies(vaultAddr, vaultToken)
+	dbpool, storageSecrets, err := setupDependencies(vaultAddr, vaultToken)
 	if err != nil {
 		serviceLogger.Fatal().Err(err).Msg("Gagal menginisialisasi dependensi dari Vault")
 	}
 	defer dbpool.Close()
 
-	cfg := fileserviceconfig.Load(s3Config)
+	cfg := fileserviceconfig.Load(storageSecrets)
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		runReindex(os.Args[2:], dbpool, cfg, serviceLogger)
+		return
+	}
 
 	enhanced_logger.LogStartup(cfg.ServiceName, cfg.Port, map[string]interface{}{
 		"jaeger_endpoint": cfg.JaegerEndpoint,
 		"storage_backend": cfg.StorageBackend,
+		"grpc_port":       cfg.GRPCPort,
+		"s3_gateway_port": cfg.S3GatewayPort,
 	})
 
 	tp, err := telemetry.InitTracerProvider(cfg.ServiceName, cfg.JaegerEndpoint)
@@ -111,18 +226,12 @@ func main() {
 		}
 	}()
 
-	var fileStorage storage.Storage
-	switch cfg.StorageBackend {
-	case "s3":
-		fileStorage, err = storage.NewS3Storage(context.Background(), cfg.S3Config.Region, cfg.S3Config.Endpoint, cfg.S3Config.AccessKey, cfg.S3Config.SecretKey, cfg.S3Config.Bucket, cfg.S3Config.UsePathStyle)
-		if err != nil {
-			serviceLogger.Fatal().Err(err).Msgf("Gagal inisialisasi S3 storage: %v", err)
-		}
-	case "local":
-		fileStorage = storage.NewLocalStorage("/storage")
-	default:
-		serviceLogger.Fatal().Msgf("Storage backend tidak valid: %s", cfg.StorageBackend)
+	fileStorage, err := openStorage(cfg)
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msgf("Gagal inisialisasi storage %s", cfg.StorageBackend)
 	}
+	storageSwitch := storage.NewSwitch(fileStorage)
+	defer func() { closeStorage(storageSwitch.Current()) }()
 
 	redisAddr := os.Getenv("REDIS_ADDR")
 	if redisAddr == "" {
@@ -136,40 +245,217 @@ func main() {
 		}
 	}()
 
-	fileRepo := repository.NewPostgresFileRepository(dbpool)
-	fileService := service.NewFileService(fileRepo, fileStorage, cfg)
+	deps := dependencies{
+		fileRepo:       tracing.NewFileRepository(metrics.NewFileRepository(repository.NewPostgresFileRepository(dbpool))),
+		accessRuleRepo: repository.NewCachedAccessRuleRepository(repository.NewPostgresAccessRuleRepository(dbpool), redisClient, 5*time.Minute),
+		s3Repo:         repository.NewPostgresS3Repository(dbpool),
+		davRepo:        repository.NewPostgresDAVCollectionRepository(dbpool),
+		integrityRepo:  repository.NewPostgresIntegrityRepository(dbpool),
+		searchRepo:     repository.NewPostgresSearchRepository(dbpool),
+		jobRepo:        repository.NewPostgresJobRepository(dbpool),
+		renditionRepo:  repository.NewPostgresRenditionRepository(dbpool),
+		usageRepo:      repository.NewPostgresUsageRepository(dbpool),
+		fileStorage:    storageSwitch,
+		redisClient:    redisClient,
+	}
+	consulClient, err := newConsulClient()
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msg("Gagal membuat klien Consul")
+	}
+	policyEngine := policy.NewDefaultEngine()
+	policyCtx, stopPolicyWatcher := context.WithCancel(context.Background())
+	defer stopPolicyWatcher()
+	startPolicyWatcher(policyCtx, consulClient, policyEngine)
+
+	configWatcher := fileserviceconfig.NewWatcher(consulClient.KV(), storageSecrets, cfg)
+	configWatcher.Subscribe(storageReloader(storageSwitch))
+
+	regInfo := client.ServiceRegistrationInfo{
+		ServiceName:    cfg.ServiceName,
+		ServiceID:      fmt.Sprintf("%s-%d", cfg.ServiceName, cfg.Port),
+		Port:           cfg.Port,
+		HealthCheckURL: fmt.Sprintf("http://localhost:%d/files/health", cfg.Port),
+	}
+	consul, err := client.RegisterService(regInfo)
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msg("Gagal mendaftarkan service ke Consul")
+	}
+	defer client.DeregisterService(consul, regInfo.ServiceID)
+
+	serve(configWatcher, deps, policyEngine, serviceLogger)
+}
+
+// dependencies berisi repository, storage, dan Redis yang dipakai bersama oleh
+// server HTTP, gRPC, dan gateway S3. Mode normal mengisinya dengan Postgres dan
+// backend storage dari konfigurasi; mode dev dengan implementasi di memori.
+type dependencies struct {
+	fileRepo       repository.FileRepository
+	accessRuleRepo repository.AccessRuleRepository
+	s3Repo         repository.S3Repository
+	davRepo        repository.DAVCollectionRepository
+	integrityRepo  repository.IntegrityRepository
+	searchRepo     repository.SearchRepository
+	jobRepo        repository.JobRepository
+	renditionRepo  repository.RenditionRepository
+	usageRepo      repository.UsageRepository
+	fileStorage    storage.Storage
+	redisClient    *redis.Client
+}
+
+// serve menjalankan server HTTP, gRPC, dan gateway S3, lalu memblokir sampai
+// sinyal shutdown diterima. Perubahan konfigurasi dari configWatcher diteruskan
+// ke service dan gateway S3 tanpa restart.
+func serve(configWatcher *fileserviceconfig.Watcher, deps dependencies, policyEngine *policy.Engine, serviceLogger zerolog.Logger) {
+	cfg := configWatcher.Current()
+	// Tanpa antrean, pekerjaan pasca-unggah dijalankan langsung saat unggah.
+	var jobQueue repository.JobRepository
+	if cfg.JobsEnabled {
+		jobQueue = deps.jobRepo
+	}
+	fileService := service.NewTracedFileService(service.NewFileService(deps.fileRepo, deps.fileStorage, cfg, policyEngine, jobQueue, deps.accessRuleRepo))
 	fileHandler := handler.NewFileHandler(fileService)
+	configHandler := handler.NewConfigHandler(configWatcher)
+
+	searchHandler := handler.NewSearchHandler(service.NewSearchService(deps.searchRepo, fileService))
+	jobHandler := handler.NewJobHandler(service.NewJobService(deps.jobRepo, fileService))
+
+	accessRuleHandler := handler.NewAccessRuleHandler(service.NewAccessRuleService(deps.accessRuleRepo, deps.fileRepo, policyEngine))
+
+	s3CredentialService := service.NewS3CredentialService(deps.s3Repo, []byte(os.Getenv("JWT_SECRET_KEY")), repository.NewRedisTokenDenylist(deps.redisClient))
+	s3CredentialHandler := handler.NewS3CredentialHandler(s3CredentialService)
+
+	renderHandler := handler.NewRenderHandler(service.NewRenderService(fileService, deps.fileRepo, deps.renditionRepo,
+		deps.fileStorage, []byte(os.Getenv("JWT_SECRET_KEY")), configWatcher.Current))
+	pdfHandler := handler.NewPDFHandler(service.NewPDFService(fileService, deps.renditionRepo, deps.fileStorage, configWatcher.Current))
+
+	davHandler := handler.DAV(dav.NewHandler("/files/dav", dav.NewFileSystem(fileService, deps.davRepo)))
 
 	portStr := strconv.Itoa(cfg.Port)
-	router := gin.Default()
+	router, err := handler.NewRouter(cfg.TrustedProxies)
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msg("Gagal membuat router HTTP")
+	}
 	router.Use(otelgin.Middleware(cfg.ServiceName))
 	p := ginprometheus.NewPrometheus("gin")
 	p.Use(router)
+	metrics.Register(prometheus.DefaultRegisterer)
 
 	fileRoutes := router.Group("/files")
 	{
 		fileRoutes.GET("/health", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "healthy"}) })
+		// Render tidak memakai JWT agar dapat dipakai langsung di <img>; akses
+		// dijamin oleh tanda tangan URL dari /:id/render-url.
+		fileRoutes.GET("/:id/render", renderHandler.Render)
+		davRoutes := fileRoutes.Group("/dav", handler.DAVAuth(auth.JWTMiddleware(deps.redisClient), s3CredentialService), handler.PolicyRequestContext())
+		for _, method := range dav.Methods {
+			davRoutes.Handle(method, "", davHandler)
+			davRoutes.Handle(method, "/*path", davHandler)
+		}
+
 		protected := fileRoutes.Group("/")
-		protected.Use(auth.JWTMiddleware(redisClient))
+		protected.Use(auth.JWTMiddleware(deps.redisClient), handler.PolicyRequestContext())
 		{
 			protected.POST("/upload", fileHandler.UploadFile)
+			protected.POST("/upload/batch", fileHandler.UploadBatch)
+			protected.POST("/archive", fileHandler.DownloadArchive)
+			protected.GET("/archive/:id", jobHandler.GetArchiveStatus)
+			protected.GET("/policies", fileHandler.ListUploadPolicies)
+			protected.GET("/search", searchHandler.Search)
+			protected.POST("/pdf/merge", pdfHandler.Merge)
+			protected.POST("/s3-credentials", s3CredentialHandler.CreateCredential)
+			protected.GET("/s3-credentials", s3CredentialHandler.ListCredentials)
+			protected.DELETE("/s3-credentials/:accessKeyId", s3CredentialHandler.DeleteCredential)
 			protected.GET("/:id", fileHandler.DownloadFile)
+			protected.GET("/:id/metadata", fileHandler.GetFileInfo)
+			protected.GET("/:id/jobs", jobHandler.ListFileJobs)
+			protected.GET("/:id/render-url", renderHandler.SignRenderURL)
+			protected.GET("/:id/pdf/first-page", pdfHandler.FirstPage)
+			protected.PATCH("/:id", fileHandler.UpdateFileMetadata)
+			protected.PUT("/:id/tags/*tag", fileHandler.AddFileTag)
+			protected.DELETE("/:id/tags/*tag", fileHandler.RemoveFileTag)
+
+			admin := protected.Group("/admin")
+			admin.Use(handler.RequireRole("admin"))
+			{
+				admin.GET("/access-rules", accessRuleHandler.ListRules)
+				admin.POST("/access-rules", accessRuleHandler.CreateRule)
+				admin.DELETE("/access-rules", accessRuleHandler.DeleteRule)
+				admin.GET("/access-rules/explain/:id", accessRuleHandler.ExplainAccess)
+				admin.GET("/config", configHandler.GetConfig)
+				admin.GET("/jobs", jobHandler.ListJobs)
+				admin.GET("/jobs/:id", jobHandler.GetJob)
+				admin.POST("/jobs/:id/retry", jobHandler.RetryJob)
+			}
 		}
 	}
 
-	regInfo := client.ServiceRegistrationInfo{
-		ServiceName:    cfg.ServiceName,
-		ServiceID:      fmt.Sprintf("%s-%d", cfg.ServiceName, cfg.Port),
-		Port:           cfg.Port,
-		HealthCheckURL: fmt.Sprintf("http://localhost:%d/files/health", cfg.Port),
-	}
-	consul, err := client.RegisterService(regInfo)
+	srv := &http.Server{Addr: ":" + portStr, Handler: router}
+
+	authenticator := grpcapi.NewAuthenticator(deps.redisClient)
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(authenticator.UnaryInterceptor()),
+		grpc.StreamInterceptor(authenticator.StreamInterceptor()),
+	)
+	filev1.RegisterFileServiceServer(grpcServer, grpcapi.NewServer(fileService))
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
 	if err != nil {
-		serviceLogger.Fatal().Err(err).Msg("Gagal mendaftarkan service ke Consul")
+		serviceLogger.Fatal().Err(err).Msgf("Gagal membuka port gRPC %d", cfg.GRPCPort)
 	}
-	defer client.DeregisterService(consul, regInfo.ServiceID)
+	go func() {
+		serviceLogger.Info().Msgf("Memulai server gRPC di port %d", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			serviceLogger.Fatal().Err(err).Msg("Server gRPC gagal berjalan")
+		}
+	}()
 
-	srv := &http.Server{Addr: ":" + portStr, Handler: router}
+	scrubCtx, stopScrubber := context.WithCancel(context.Background())
+	defer stopScrubber()
+	if cfg.ScrubInterval > 0 {
+		scrubber := integrity.NewScrubber(deps.integrityRepo, deps.fileStorage, cfg.ScrubBatchSize, cfg.ScrubMaxAge)
+		go scrubber.Run(scrubCtx, cfg.ScrubInterval)
+		serviceLogger.Info().Msgf("Scrubber checksum berjalan setiap %s", cfg.ScrubInterval)
+	}
+
+	samplerCtx, stopSampler := context.WithCancel(context.Background())
+	defer stopSampler()
+	if cfg.MetricsSampleInterval > 0 {
+		go metrics.NewSampler(deps.usageRepo, jobQueue).Run(samplerCtx, cfg.MetricsSampleInterval)
+	}
+
+	var jobRunner *jobs.Runner
+	if cfg.JobsEnabled {
+		jobRunner = jobs.NewRunner(deps.jobRepo, jobs.Options{
+			PollInterval: cfg.JobPollInterval,
+			MaxAttempts:  cfg.JobMaxAttempts,
+			RetryDelay:   cfg.JobRetryDelay,
+			Timeout:      cfg.JobTimeout,
+		})
+		jobRunner.Register(model.JobTypeExtractText, cfg.JobConcurrencyFor(model.JobTypeExtractText),
+			service.ExtractTextJob(deps.fileRepo, deps.searchRepo, deps.fileStorage, configWatcher.Current))
+		jobRunner.Register(model.JobTypeBuildArchive, cfg.JobConcurrencyFor(model.JobTypeBuildArchive),
+			service.BuildArchiveJob(deps.fileRepo, deps.fileStorage, configWatcher.Current))
+		jobRunner.Start()
+		serviceLogger.Info().Msgf("Worker job berjalan, memeriksa antrean setiap %s", cfg.JobPollInterval)
+	}
+
+	s3Gateway := s3gateway.NewGateway(fileService, deps.s3Repo, s3CredentialService, deps.fileStorage, cfg.UploadSizeLimit())
+
+	configWatcher.Subscribe(func(_, next *fileserviceconfig.Config) {
+		fileService.ApplyConfig(next)
+		s3Gateway.SetMaxObjectSize(next.UploadSizeLimit())
+	})
+	configCtx, stopConfigWatcher := context.WithCancel(context.Background())
+	defer stopConfigWatcher()
+	go configWatcher.Watch(configCtx, 0)
+	s3Srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.S3GatewayPort), Handler: s3Gateway}
+	go func() {
+		serviceLogger.Info().Msgf("Memulai gateway S3 di port %d", cfg.S3GatewayPort)
+		if err := s3Srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serviceLogger.Fatal().Err(err).Msg("Gateway S3 gagal berjalan")
+		}
+	}()
 
 	go func() {
 		serviceLogger.Info().Msgf("Memulai server HTTP di port %s", portStr)
@@ -189,5 +475,19 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		serviceLogger.Fatal().Err(err).Msg("Server terpaksa dimatikan")
 	}
+	if err := s3Srv.Shutdown(ctx); err != nil {
+		serviceLogger.Error().Err(err).Msg("Gateway S3 terpaksa dimatikan")
+	}
+	grpcServer.GracefulStop()
+	// Server sudah berhenti menerima unggahan; job yang sedang berjalan
+	// diberi batas waktunya sendiri (bukan sisa batas waktu server) untuk
+	// selesai sebelum dikembalikan ke antrean.
+	if jobRunner != nil {
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.JobShutdownTimeout)
+		defer cancelDrain()
+		if err := jobRunner.Shutdown(drainCtx); err != nil {
+			serviceLogger.Warn().Err(err).Msg("Sebagian job belum selesai saat shutdown dan dikembalikan ke antrean")
+		}
+	}
 	enhanced_logger.LogShutdown(cfg.ServiceName)
 }
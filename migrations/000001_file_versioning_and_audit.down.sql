@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS file_audit_log;
+
+ALTER TABLE files
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,16 @@
+-- Versi metadata untuk optimistic concurrency (If-Match) dan audit perubahan metadata.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS file_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    actor_user_id VARCHAR(36),
+    action VARCHAR(50) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    version BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_audit_log_file_id ON file_audit_log (file_id, created_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS s3_multipart_parts;
+DROP TABLE IF EXISTS s3_multipart_uploads;
+DROP TABLE IF EXISTS s3_objects;
+DROP TABLE IF EXISTS s3_credentials;
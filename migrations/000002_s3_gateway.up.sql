@@ -0,0 +1,34 @@
+-- Gateway S3: access key SigV4, pemetaan bucket/key ke file, dan multipart upload.
+CREATE TABLE IF NOT EXISTS s3_credentials (
+    access_key_id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    role_name VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_s3_credentials_user_id ON s3_credentials (user_id);
+
+CREATE TABLE IF NOT EXISTS s3_objects (
+    bucket VARCHAR(63) NOT NULL,
+    object_key VARCHAR(1024) COLLATE "C" NOT NULL,
+    file_id UUID NOT NULL UNIQUE REFERENCES files(id) ON DELETE CASCADE,
+    etag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (bucket, object_key)
+);
+
+CREATE TABLE IF NOT EXISTS s3_multipart_uploads (
+    upload_id UUID PRIMARY KEY,
+    bucket VARCHAR(63) NOT NULL,
+    object_key VARCHAR(1024) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS s3_multipart_parts (
+    upload_id UUID NOT NULL REFERENCES s3_multipart_uploads(upload_id) ON DELETE CASCADE,
+    part_number INT NOT NULL,
+    etag VARCHAR(64) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    PRIMARY KEY (upload_id, part_number)
+);
@@ -0,0 +1,8 @@
+-- WebDAV: koleksi kosong yang dibuat lewat MKCOL. Koleksi yang berisi file
+-- berasal dari tag file sehingga tidak perlu disimpan di sini.
+CREATE TABLE IF NOT EXISTS dav_collections (
+    user_id VARCHAR(36) NOT NULL,
+    path VARCHAR(100) COLLATE "C" NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, path)
+);
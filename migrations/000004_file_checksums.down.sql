@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_files_checksum_verified_at;
+
+ALTER TABLE files
+    DROP COLUMN IF EXISTS checksum_sha256,
+    DROP COLUMN IF EXISTS checksum_md5,
+    DROP COLUMN IF EXISTS checksum_crc32c,
+    DROP COLUMN IF EXISTS checksum_verified_at,
+    DROP COLUMN IF EXISTS checksum_mismatch_at;
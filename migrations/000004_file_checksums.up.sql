@@ -0,0 +1,12 @@
+-- Checksum konten file untuk verifikasi unduhan dan scrubber. File lama
+-- tidak memiliki checksum dan dilewati scrubber.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS checksum_sha256 CHAR(64),
+    ADD COLUMN IF NOT EXISTS checksum_md5 CHAR(32),
+    ADD COLUMN IF NOT EXISTS checksum_crc32c CHAR(8),
+    ADD COLUMN IF NOT EXISTS checksum_verified_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS checksum_mismatch_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_files_checksum_verified_at
+    ON files (checksum_verified_at NULLS FIRST)
+    WHERE deleted_at IS NULL AND checksum_sha256 IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Akhir masa retensi bawaan dari kebijakan upload. File lama dan file yang
+-- kebijakannya tidak menentukan retensi bernilai NULL.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS retain_until TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- Waktu metadata file dibersihkan saat unggah. File lama dan file yang
+-- disimpan apa adanya bernilai NULL.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS sanitized_at TIMESTAMPTZ;
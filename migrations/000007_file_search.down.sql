@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_files_search_vector;
+
+ALTER TABLE files
+    DROP COLUMN IF EXISTS search_vector,
+    DROP COLUMN IF EXISTS content_indexed_at,
+    DROP COLUMN IF EXISTS content_text;
@@ -0,0 +1,17 @@
+-- Pencarian full-text atas nama dan teks isi file. search_vector dihitung
+-- ulang otomatis oleh Postgres saat nama atau teks berubah; teks diindeks
+-- dengan konfigurasi bahasa Indonesia dan Inggris sekaligus. File lama belum
+-- memiliki teks sampai perintah reindex dijalankan.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS content_text TEXT,
+    ADD COLUMN IF NOT EXISTS content_indexed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('indonesian'::regconfig, coalesce(original_name, '')), 'A') ||
+        setweight(to_tsvector('english'::regconfig, coalesce(original_name, '')), 'A') ||
+        setweight(to_tsvector('indonesian'::regconfig, coalesce(content_text, '')), 'B') ||
+        setweight(to_tsvector('english'::regconfig, coalesce(content_text, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_files_search_vector
+    ON files USING GIN (search_vector)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,26 @@
+-- Antrean job latar belakang. Worker mengambil job dengan
+-- FOR UPDATE SKIP LOCKED; job berstatus running yang locked_until-nya lewat
+-- (worker mati di tengah jalan) diambil ulang oleh worker lain.
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    file_id UUID REFERENCES files(id) ON DELETE CASCADE,
+    payload JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_claim
+    ON jobs (type, run_at)
+    WHERE status IN ('pending', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_jobs_file_id ON jobs (file_id);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status, updated_at DESC);
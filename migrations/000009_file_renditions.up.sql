@@ -0,0 +1,4 @@
+-- Path storage hasil render gambar (GET /files/:id/render) yang tersimpan
+-- sebagai cache, agar ikut dihapus bersama file aslinya.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS rendition_paths TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+ALTER TABLE files
+    DROP COLUMN IF EXISTS provenance,
+    DROP COLUMN IF EXISTS pdf_info;
@@ -0,0 +1,5 @@
+-- Informasi dokumen PDF (jumlah halaman, properti) yang dibaca saat unggah,
+-- dan asal-usul file turunan seperti hasil penggabungan PDF.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS pdf_info JSONB,
+    ADD COLUMN IF NOT EXISTS provenance JSONB;
@@ -0,0 +1,3 @@
+ALTER TABLE s3_credentials
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS token_id;
@@ -0,0 +1,11 @@
+-- Access key S3 menyalin peran dari token penerbit, sehingga hanya berlaku
+-- selama token tersebut berlaku: sampai expires_at (klaim exp) dan selama
+-- token_id (klaim jti) tidak ada di denylist. Access key lama tidak tahu
+-- token penerbitnya dan langsung kedaluwarsa; pengguna membuat yang baru.
+ALTER TABLE s3_credentials
+    ADD COLUMN IF NOT EXISTS token_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+UPDATE s3_credentials SET expires_at = CURRENT_TIMESTAMP WHERE expires_at IS NULL;
+
+ALTER TABLE s3_credentials ALTER COLUMN expires_at SET NOT NULL;
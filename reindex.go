@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/fulltext"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// runReindex menjalankan perintah reindex: mengekstrak ulang teks isi file
+// untuk pencarian full-text lalu keluar tanpa menjalankan server.
+//
+//	prism-file-service reindex [-missing] [-batch 100]
+//
+// Tanpa -missing semua file aktif diproses, mis. setelah extractor
+// diperbaiki; dengan -missing hanya file yang belum pernah diindeks, mis.
+// file lama setelah migrasi 000007_file_search.
+func runReindex(args []string, dbpool *pgxpool.Pool, cfg *fileserviceconfig.Config, serviceLogger zerolog.Logger) {
+	flags := flag.NewFlagSet("reindex", flag.ExitOnError)
+	onlyMissing := flags.Bool("missing", false, "hanya file yang belum pernah diindeks")
+	batchSize := flags.Int("batch", 100, "jumlah file yang diambil per query")
+	_ = flags.Parse(args)
+
+	if cfg.SearchMaxTextBytes <= 0 {
+		serviceLogger.Fatal().Msg("Ekstraksi teks dinonaktifkan (search_max_text_kb = 0), reindex dibatalkan")
+	}
+	fileStorage, err := openStorage(cfg)
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msgf("Gagal inisialisasi storage %s", cfg.StorageBackend)
+	}
+	defer closeStorage(fileStorage)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	indexer := fulltext.NewIndexer(repository.NewPostgresSearchRepository(dbpool), fileStorage, cfg.SearchMaxTextBytes, *batchSize)
+	result, err := indexer.Reindex(ctx, *onlyMissing)
+	serviceLogger.Info().Int("indexed", result.Indexed).Int("unsupported", result.Unsupported).Int("failed", result.Failed).Msg("Reindex pencarian selesai")
+	if err != nil {
+		serviceLogger.Fatal().Err(err).Msg("Reindex pencarian terhenti sebelum semua file diproses")
+	}
+}
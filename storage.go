@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	fileserviceconfig "github.com/Lumina-Enterprise-Solutions/prism-file-service/config"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/metrics"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/storage"
+	"github.com/Lumina-Enterprise-Solutions/prism-file-service/internal/tracing"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// storageDrainTimeout adalah jeda sebelum backend lama ditutup setelah diganti,
+// agar unggahan dan unduhan yang masih berjalan sempat selesai.
+const storageDrainTimeout = 2 * time.Minute
+
+// openStorage membuat backend storage sesuai cfg.StorageBackend, dibungkus
+// span tracing dan metrik latensi per backend. Backend yang memegang koneksi atau handle
+// ditutup lewat closeStorage.
+func openStorage(cfg *fileserviceconfig.Config) (storage.Storage, error) {
+	backend, err := openBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return tracing.NewStorage(metrics.NewStorage(backend, cfg.StorageBackend), cfg.StorageBackend), nil
+}
+
+func openBackend(cfg *fileserviceconfig.Config) (storage.Storage, error) {
+	switch cfg.StorageBackend {
+	case "s3":
+		s3Storage, err := storage.NewS3Storage(context.Background(), storage.S3Options{
+			Region:            cfg.S3Config.Region,
+			Endpoint:          cfg.S3Config.Endpoint,
+			AccessKey:         cfg.S3Config.AccessKey,
+			SecretKey:         cfg.S3Config.SecretKey,
+			Bucket:            cfg.S3Config.Bucket,
+			UsePathStyle:      cfg.S3Config.UsePathStyle,
+			PartSize:          int64(cfg.S3Config.PartSizeMB) * 1024 * 1024,
+			Concurrency:       cfg.S3Config.UploadConcurrency,
+			ChecksumAlgorithm: cfg.S3Config.ChecksumAlgorithm,
+			Encryption:        cfg.S3Config.Encryption,
+			KMSKeyID:          cfg.S3Config.KMSKeyID,
+			CustomerKey:       cfg.S3Config.SSECustomerKey,
+			APIOptions:        []func(*middleware.Stack) error{tracing.AWSMiddleware},
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Bersihkan unggahan multipart yang tertinggal dari proses sebelumnya.
+		go func() {
+			aborted, err := s3Storage.AbortStaleUploads(context.Background(), staleUploadAge)
+			if err != nil {
+				log.Warn().Err(err).Msg("Gagal membersihkan unggahan multipart S3 yang tertinggal")
+				return
+			}
+			if aborted > 0 {
+				log.Info().Int("aborted", aborted).Msg("Unggahan multipart S3 yang tertinggal dibatalkan")
+			}
+		}()
+		return s3Storage, nil
+	case "sftp":
+		return storage.NewSFTPStorage(storage.SFTPOptions{
+			Host:           cfg.SFTPConfig.Host,
+			Port:           cfg.SFTPConfig.Port,
+			User:           cfg.SFTPConfig.User,
+			Password:       cfg.SFTPConfig.Password,
+			PrivateKey:     cfg.SFTPConfig.PrivateKey,
+			HostKey:        cfg.SFTPConfig.HostKey,
+			BasePath:       cfg.SFTPConfig.BasePath,
+			MaxConnections: cfg.SFTPConfig.MaxConnections,
+			MaxRetries:     cfg.SFTPConfig.MaxRetries,
+		})
+	case "azure":
+		return storage.NewAzureBlobStorage(cfg.AzureConfig.AccountName, cfg.AzureConfig.AccountKey, cfg.AzureConfig.SASToken, cfg.AzureConfig.Endpoint, cfg.AzureConfig.Container)
+	case "gcs":
+		return storage.NewGCSStorage(context.Background(), cfg.GCSConfig.CredentialsJSON, cfg.GCSConfig.Endpoint, cfg.GCSConfig.Bucket)
+	case "local":
+		return storage.NewLocalStorage(cfg.LocalConfig.BasePath, storage.LocalOptions{
+			FileMode:   cfg.LocalConfig.FileMode,
+			DirMode:    cfg.LocalConfig.DirMode,
+			ShardDepth: cfg.LocalConfig.ShardDepth,
+		})
+	default:
+		return nil, fmt.Errorf("storage backend tidak valid: %s", cfg.StorageBackend)
+	}
+}
+
+// closeStorage menutup koneksi atau handle milik backend, jika ada.
+func closeStorage(s storage.Storage) {
+	closer, ok := s.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		log.Error().Err(err).Msg("Gagal menutup backend storage")
+	}
+}
+
+// storageReloader membuat ulang backend storage saat kredensial atau endpoint
+// berubah di Consul dan mengaktifkannya lewat sw. Jenis backend dan lokasi
+// file ditahan Watcher sampai restart. Jika backend baru gagal dibuat, backend
+// lama tetap dipakai.
+func storageReloader(sw *storage.Switch) fileserviceconfig.Subscriber {
+	return func(previous, next *fileserviceconfig.Config) {
+		if !storageSettingsChanged(previous, next) {
+			return
+		}
+		fileStorage, err := openStorage(next)
+		if err != nil {
+			log.Error().Err(err).Str("storage_backend", next.StorageBackend).Msg("Gagal membuat backend storage baru, backend lama tetap dipakai")
+			return
+		}
+		old := sw.Swap(fileStorage)
+		log.Info().Str("storage_backend", next.StorageBackend).Msg("Backend storage diganti sesuai konfigurasi baru")
+		time.AfterFunc(storageDrainTimeout, func() { closeStorage(old) })
+	}
+}
+
+// storageSettingsChanged melaporkan apakah pengaturan backend aktif berubah;
+// pengaturan backend lain diabaikan.
+func storageSettingsChanged(previous, next *fileserviceconfig.Config) bool {
+	switch next.StorageBackend {
+	case "s3":
+		return previous.S3Config != next.S3Config
+	case "sftp":
+		return previous.SFTPConfig != next.SFTPConfig
+	case "azure":
+		return previous.AzureConfig != next.AzureConfig
+	case "gcs":
+		return previous.GCSConfig != next.GCSConfig
+	case "local":
+		return previous.LocalConfig != next.LocalConfig
+	}
+	return false
+}